@@ -10,22 +10,36 @@ import (
 	"time"
 
 	"github.com/dustin/articles-backend/config"
-	"github.com/google/uuid"
 	"github.com/dustin/articles-backend/internal/adapter"
+	"github.com/dustin/articles-backend/internal/admin"
+	"github.com/dustin/articles-backend/internal/aggregate"
 	"github.com/dustin/articles-backend/internal/article"
+	"github.com/dustin/articles-backend/internal/audit"
+	"github.com/dustin/articles-backend/internal/auth/oauth"
+	"github.com/dustin/articles-backend/internal/auth/signing"
+	"github.com/dustin/articles-backend/internal/chaos"
 	"github.com/dustin/articles-backend/internal/classifier"
+	"github.com/dustin/articles-backend/internal/collection"
 	"github.com/dustin/articles-backend/internal/embedding"
+	"github.com/dustin/articles-backend/internal/export"
+	"github.com/dustin/articles-backend/internal/feed"
+	"github.com/dustin/articles-backend/internal/importer"
+	"github.com/dustin/articles-backend/internal/mlexport"
 	"github.com/dustin/articles-backend/internal/rating"
 	"github.com/dustin/articles-backend/internal/recommendation"
 	"github.com/dustin/articles-backend/internal/repository"
+	"github.com/dustin/articles-backend/internal/share"
+	"github.com/dustin/articles-backend/internal/usage"
 	"github.com/dustin/articles-backend/internal/user"
+	"github.com/dustin/articles-backend/internal/utils"
 	"github.com/dustin/articles-backend/internal/worker"
 	"github.com/dustin/articles-backend/pkg/database"
+	"github.com/dustin/articles-backend/pkg/events"
 	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/dustin/articles-backend/pkg/storage"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 func main() {
@@ -47,11 +61,36 @@ func main() {
 
 	appLogger.Info("Database connection established")
 
+	// Bound every statement so a runaway query cannot hold a connection
+	statementTimeouts, err := database.ParseStatementTimeouts(&cfg.Database)
+	if err != nil {
+		appLogger.Fatal("Failed to configure statement timeouts: " + err.Error())
+	}
+	if err := database.RegisterStatementTimeouts(db, statementTimeouts.Default); err != nil {
+		appLogger.Fatal("Failed to register statement timeouts: " + err.Error())
+	}
+
+	// Initialize fault injection for resilience testing (never enabled in production)
+	faultInjector, err := chaos.NewInjector(&cfg.Chaos, cfg.Server.Environment, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize fault injector: " + err.Error())
+	}
+	if faultInjector.Allowed() {
+		if err := faultInjector.RegisterGORMCallbacks(db); err != nil {
+			appLogger.Fatal("Failed to register fault injection callbacks: " + err.Error())
+		}
+	}
+
 	// Run database migrations for all feature models
-	if err := db.AutoMigrate(&user.User{}, &article.Article{}, &rating.Rating{}); err != nil {
+	if err := db.AutoMigrate(&user.User{}, &user.RefreshToken{}, &article.Article{}, &article.Tag{}, &article.Highlight{}, &rating.Rating{}, &rating.Reaction{}, &importer.Job{}, &usage.Counter{}, &collection.Collection{}, &collection.Membership{}, &feed.Feed{}, &feed.SeenEntry{}, &share.Share{}, &recommendation.UserProfile{}, &recommendation.UserInterests{}, &recommendation.StoredList{}, &recommendation.BanditArm{}, &recommendation.Impression{}, &recommendation.Event{}, &aggregate.Bucket{}, &aggregate.Refresh{}, &export.Job{}, &oauth.Identity{}, &audit.Event{}); err != nil {
 		appLogger.Fatal("Failed to migrate database: " + err.Error())
 	}
 
+	// Create indexes GORM tags cannot express
+	if err := repository.MigrateSearchIndexes(db); err != nil {
+		appLogger.Fatal("Failed to create search indexes: " + err.Error())
+	}
+
 	appLogger.Info("Database migration completed")
 
 	// Initialize GORM-based repositories
@@ -62,41 +101,245 @@ func main() {
 	// Initialize recommendation-specific repositories
 	recArticleRepo := repository.NewGORMRecommendationArticleRepository(db, appLogger)
 	recRatingRepo := repository.NewGORMRecommendationRatingRepository(db, appLogger)
+	recProfileRepo := repository.NewGORMRecommendationProfileRepository(db, appLogger)
+	recBanditRepo := repository.NewGORMRecommendationBanditRepository(db, appLogger)
 
 	// Initialize embedding client
 	embeddingServiceURL := os.Getenv("EMBEDDING_SERVICE_URL")
 	if embeddingServiceURL == "" {
 		embeddingServiceURL = "http://localhost:8001"
 	}
-	embeddingClient := embedding.NewClient(embeddingServiceURL)
-	appLogger.Info("Embedding client initialized with URL: " + embeddingServiceURL)
+	embeddingService := embedding.NewClient(embeddingServiceURL)
+	embeddingProvider, err := embedding.NewProvider(&cfg.Embedding, embeddingService)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize embedding provider: " + err.Error())
+	}
+	// The service still classifies content when another provider embeds texts
+	embeddingStore := repository.NewGORMEmbeddingRepository(db, appLogger)
+	embeddingClient := embedding.NewProviderClient(embeddingProvider, embeddingService, embeddingStore, appLogger)
+	if faultInjector.Allowed() {
+		embeddingClient = chaos.NewEmbeddingClient(embeddingClient, faultInjector)
+	}
+	// Retries and the circuit breaker sit outside fault injection, so injected faults exercise them
+	embeddingClient, err = embedding.NewResilientClient(embeddingClient, &cfg.Embedding, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize embedding client: " + err.Error())
+	}
+	appLogger.Info("Embedding client initialized with provider " + embeddingProvider.Name() + " and service URL: " + embeddingServiceURL)
+
+	// Health probes reuse the last embedding service check for a while
+	embeddingHealth := embedding.NewHealthMonitor(embeddingClient, 30*time.Second)
+
+	// Texts embedded before are answered from memory, outside retries and fault injection
+	embeddingCache, err := embedding.NewCachingClient(embeddingClient, &cfg.Embedding, embeddingStore, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize embedding cache: " + err.Error())
+	}
+	embeddingClient = embeddingCache
 
 	// Initialize content classifier with validation and defaults
-	metadataClassifier, err := classifier.NewReadabilityClassifier(&cfg.Classifier, embeddingClient, appLogger)
+	var metadataClassifier classifier.Classifier
+	metadataClassifier, err = classifier.NewReadabilityClassifier(&cfg.Classifier, embeddingClient, appLogger)
 	if err != nil {
 		appLogger.Fatal("Failed to initialize classifier: " + err.Error())
 	}
 
+	// A canary classifier takes over a percentage of pages to roll out new settings gradually
+	var canaryClassifier *classifier.CanaryClassifier
+	if cfg.Classifier.Canary != "" {
+		canaryClassifier, err = classifier.NewCanaryClassifier(&cfg.Classifier, metadataClassifier, embeddingClient, appLogger)
+		if err != nil {
+			appLogger.Fatal("Failed to initialize canary classifier: " + err.Error())
+		}
+		metadataClassifier = canaryClassifier
+		appLogger.Info("Canary classifier enabled: " + cfg.Classifier.Canary + " for " + cfg.Classifier.CanaryPercent + "% of pages")
+	}
+
+	// A shadow classifier processes the same pages for comparison without affecting stored metadata
+	var shadowClassifier *classifier.ShadowClassifier
+	if cfg.Classifier.Shadow != "" {
+		shadowClassifier, err = classifier.NewShadowClassifier(&cfg.Classifier, metadataClassifier, embeddingClient, appLogger)
+		if err != nil {
+			appLogger.Fatal("Failed to initialize shadow classifier: " + err.Error())
+		}
+		metadataClassifier = shadowClassifier
+		appLogger.Info("Shadow classifier enabled: " + cfg.Classifier.Shadow)
+	}
+
 	// Create adapter to bridge interface compatibility
 	metadataExtractor := adapter.NewClassifierToMetadataExtractor(metadataClassifier)
 
+	// Object storage keeps permanent copies of extracted pages; nil when disabled
+	snapshotStorage, err := storage.NewStorage(&cfg.Storage, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize object storage: " + err.Error())
+	}
+
+	// Tokens are signed with HS256 and JWT_SECRET unless a signing key is configured
+	signingKeys, err := signing.NewKeySet(&cfg.JWT)
+	if err != nil {
+		appLogger.Fatal("Failed to load JWT signing keys: " + err.Error())
+	}
+	appLogger.Info("Signing tokens with " + signingKeys.Algorithm())
+
+	// Security events are kept in the audit log for admins to query
+	auditService, err := audit.NewService(&cfg.Audit, repository.NewGORMAuditRepository(db, appLogger), appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize audit service: " + err.Error())
+	}
+	auditRecorder := adapter.NewAuditServiceToUserAuditRecorder(auditService)
+
 	// Initialize business services with dependency injection
-	userService, err := user.NewService(&cfg.JWT, userRepo, appLogger)
+	userService, err := user.NewService(&cfg.JWT, &cfg.Password, &cfg.Account, signingKeys, userRepo, snapshotStorage, auditRecorder, appLogger)
 	if err != nil {
 		appLogger.Fatal("Failed to initialize user service: " + err.Error())
 	}
-	articleService := article.NewService(articleRepo, metadataExtractor, appLogger)
 
-	// Create service adapter for rating dependencies
+	// Users listed in ADMIN_EMAILS are made admins; they can appoint others
+	if cfg.Admin.Emails != "" {
+		var adminEmails []string
+		for _, email := range strings.Split(cfg.Admin.Emails, ",") {
+			if email = strings.TrimSpace(email); email != "" {
+				adminEmails = append(adminEmails, email)
+			}
+		}
+		if err := userService.GrantAdmin(adminEmails); err != nil {
+			appLogger.Fatal("Failed to grant admin roles: " + err.Error())
+		}
+	}
+
+	// Metadata extraction runs on a shared queue: interactive saves first, then imports, then retries
+	extractionQueue, err := worker.NewPriorityQueue(&cfg.Queue, "metadata-extraction", appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize extraction queue: " + err.Error())
+	}
+
+	// Recommendations read the content of articles kept in object storage from there
+	if contentStore, _ := article.ParseContentStore(cfg.Article.ContentStore); contentStore == article.ContentStoreObject {
+		recArticleRepo = adapter.NewContentStorageToRecommendationArticleRepository(recArticleRepo, snapshotStorage)
+	}
+
+	recommendationService, err := recommendation.NewService(&cfg.Recommendation, recArticleRepo, recRatingRepo, recProfileRepo, recBanditRepo, embeddingClient, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize recommendation service: " + err.Error())
+	}
+
+	// Domain events; rating changes drop cached recommendations and refresh
+	// the stored profile
+	eventBus := events.NewBus(appLogger)
+	recommendation.SubscribeRatingEvents(eventBus, recommendationService)
+
+	// Favorites feed the recommendation profile
+	articleService, err := article.NewService(&cfg.Article, articleRepo, metadataExtractor, snapshotStorage, adapter.NewPriorityQueueToExtractionQueue(extractionQueue), adapter.NewRecommendationServiceToFavoritesListener(recommendationService), appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize article service: " + err.Error())
+	}
+
+	// Share links are signed with a key derived from the JWT secret
+	shareService, err := share.NewService(
+		&cfg.JWT,
+		repository.NewGORMShareRepository(db, appLogger),
+		adapter.NewArticleServiceToShareArticleService(articleService),
+		appLogger,
+	)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize share service: " + err.Error())
+	}
+
+	// Create service adapters for rating dependencies; the rating policy may
+	// allow rating other users' shared articles. Changes are published as events.
 	ratingArticleService := adapter.NewArticleServiceToRatingArticleService(articleService)
-	ratingService := rating.NewService(ratingRepo, ratingArticleService, appLogger)
-	recommendationService := recommendation.NewService(recArticleRepo, recRatingRepo, embeddingClient, appLogger)
+	ratingService, err := rating.NewService(
+		&cfg.Rating,
+		ratingRepo,
+		ratingArticleService,
+		adapter.NewShareServiceToRatingSharedArticles(shareService),
+		eventBus,
+		appLogger,
+	)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize rating service: " + err.Error())
+	}
+
+	// Imports create articles through the article service and warm up recommendations when done
+	importService := importer.NewService(
+		repository.NewGORMImportJobRepository(db, appLogger),
+		adapter.NewArticleServiceToImporterArticleService(articleService),
+		adapter.NewRecommendationServiceToProfilePrimer(recommendationService),
+		appLogger,
+	)
+
+	collectionService := collection.NewService(
+		repository.NewGORMCollectionRepository(db, appLogger),
+		adapter.NewArticleServiceToCollectionArticleService(articleService),
+		appLogger,
+	)
+
+	// Feeds save new entries as articles through the article service
+	feedService, err := feed.NewService(
+		&cfg.Feed,
+		repository.NewGORMFeedRepository(db, appLogger),
+		adapter.NewArticleServiceToFeedArticleService(articleService),
+		appLogger,
+	)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize feed service: " + err.Error())
+	}
+
+	mlExportService, err := mlexport.NewService(&cfg.MLExport, repository.NewGORMMLExportRepository(db, appLogger), appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize ML export service: " + err.Error())
+	}
+
+	usageService, err := usage.NewService(&cfg.Usage, repository.NewGORMUsageRepository(db, appLogger), appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize usage service: " + err.Error())
+	}
+
+	aggregateService, err := aggregate.NewService(&cfg.Aggregate, repository.NewGORMAggregateRepository(db, appLogger), appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize aggregate service: " + err.Error())
+	}
+
+	// Exports read content kept in object storage from there
+	exportService, err := export.NewService(&cfg.Export, repository.NewGORMExportRepository(db, appLogger), snapshotStorage, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize export service: " + err.Error())
+	}
+
+	// Providers without a client ID stay disabled
+	oauthService, err := oauth.NewService(&cfg.OAuth, &cfg.JWT, repository.NewGORMOAuthRepository(db, appLogger), adapter.NewUserServiceToOAuthAccounts(userService), appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize OAuth service: " + err.Error())
+	}
+
+	// All /admin routes share a stricter per-admin rate limit
+	adminRateLimiter, err := admin.NewRateLimiter(&cfg.Admin)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize admin rate limiter: " + err.Error())
+	}
+	// Failed logins are counted per instance
+	loginGuard, err := user.NewLoginGuard(&cfg.Login, user.NewMemoryFailureStore(), auditRecorder, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize login guard: " + err.Error())
+	}
 
 	// Initialize HTTP handlers
-	userHandler := user.NewHandler(userService)
+	userHandler := user.NewHandler(userService, loginGuard)
 	articleHandler := article.NewHandler(articleService)
 	ratingHandler := rating.NewHandler(ratingService)
+	collectionHandler := collection.NewHandler(collectionService)
+	shareHandler := share.NewHandler(shareService)
 	recommendationHandler := recommendation.NewHandler(recommendationService)
+	chaosHandler := chaos.NewHandler(faultInjector)
+	mlExportHandler := mlexport.NewHandler(mlExportService)
+	importHandler := importer.NewHandler(importService)
+	exportHandler := export.NewHandler(exportService)
+	oauthHandler := oauth.NewHandler(oauthService)
+	feedHandler := feed.NewHandler(feedService)
+	usageHandler := usage.NewHandler(usageService)
+	aggregateHandler := aggregate.NewHandler(aggregateService)
+	auditHandler := audit.NewHandler(auditService)
 
 	// Initialize background worker for metadata retries
 	metadataRetryWorker, err := worker.NewRetryWorker(
@@ -109,21 +352,224 @@ func main() {
 		appLogger.Fatal("Failed to initialize retry worker: " + err.Error())
 	}
 
+	// Usage counters are buffered in memory and written out periodically
+	usageFlushInterval := cfg.Usage.FlushInterval
+	if usageFlushInterval == "" {
+		usageFlushInterval = "1m" // default
+	}
+	usageFlushWorker, err := worker.NewRetryWorker(
+		&config.WorkerConfig{RetryInterval: usageFlushInterval},
+		"usage-flush",
+		usageService.Flush,
+		appLogger,
+	)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize usage flush worker: " + err.Error())
+	}
+
+	// Recommendations for recently active users are precomputed off-peak
+	recommendationPrecomputer, err := recommendation.NewPrecomputer(&cfg.Recommendation, recommendationService, usageService, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize recommendation precomputer: " + err.Error())
+	}
+	precomputeSchedule := cfg.Recommendation.PrecomputeSchedule
+	if precomputeSchedule == "" {
+		precomputeSchedule = "0 3 * * *" // default: nightly at 03:00
+	}
+	recommendationPrecomputeWorker, err := worker.NewScheduledWorker(
+		precomputeSchedule,
+		"recommendation-precompute",
+		recommendationPrecomputer.Run,
+		appLogger,
+	)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize recommendation precompute worker: " + err.Error())
+	}
+
+	// Subscribed feeds are polled for new entries
+	feedPollSchedule := cfg.Feed.PollSchedule
+	if feedPollSchedule == "" {
+		feedPollSchedule = "*/30 * * * *" // default: every 30 minutes
+	}
+	feedPollWorker, err := worker.NewScheduledWorker(
+		feedPollSchedule,
+		"feed-poller",
+		feedService.PollDueFeeds,
+		appLogger,
+	)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize feed poller: " + err.Error())
+	}
+
+	// Articles deleted more than 30 days ago are purged from the trash
+	trashPurgeSchedule := cfg.Article.TrashPurgeSchedule
+	if trashPurgeSchedule == "" {
+		trashPurgeSchedule = "0 4 * * *" // default: daily at 04:00
+	}
+	trashPurgeWorker, err := worker.NewScheduledWorker(
+		trashPurgeSchedule,
+		"trash-purge",
+		articleService.PurgeTrash,
+		appLogger,
+	)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize trash purge worker: " + err.Error())
+	}
+
+	// Daily aggregates behind stats endpoints are rebuilt from the source tables
+	aggregateRefreshSchedule := cfg.Aggregate.RefreshSchedule
+	if aggregateRefreshSchedule == "" {
+		aggregateRefreshSchedule = "*/15 * * * *" // default: every 15 minutes
+	}
+	aggregateRefreshWorker, err := worker.NewScheduledWorker(
+		aggregateRefreshSchedule,
+		"aggregate-refresh",
+		aggregateService.Refresh,
+		appLogger,
+	)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize aggregate refresh worker: " + err.Error())
+	}
+
+	// Expired refresh tokens are deleted
+	refreshPurgeSchedule := cfg.JWT.RefreshPurgeSchedule
+	if refreshPurgeSchedule == "" {
+		refreshPurgeSchedule = "30 4 * * *" // default: daily at 04:30
+	}
+	refreshPurgeWorker, err := worker.NewScheduledWorker(
+		refreshPurgeSchedule,
+		"refresh-token-purge",
+		userService.PurgeRefreshTokens,
+		appLogger,
+	)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize refresh token purge worker: " + err.Error())
+	}
+
+	// Accounts are deleted once their grace period has passed
+	accountDeletionSchedule := cfg.Account.DeletionSchedule
+	if accountDeletionSchedule == "" {
+		accountDeletionSchedule = "*/15 * * * *" // default: every 15 minutes
+	}
+	accountDeletionWorker, err := worker.NewScheduledWorker(
+		accountDeletionSchedule,
+		"account-deletion",
+		userService.PurgeDeletedAccounts,
+		appLogger,
+	)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize account deletion worker: " + err.Error())
+	}
+
+	// Expired export archives are deleted
+	exportPurgeSchedule := cfg.Export.PurgeSchedule
+	if exportPurgeSchedule == "" {
+		exportPurgeSchedule = "0 5 * * *" // default: daily at 05:00
+	}
+	exportPurgeWorker, err := worker.NewScheduledWorker(
+		exportPurgeSchedule,
+		"export-purge",
+		exportService.PurgeExpired,
+		appLogger,
+	)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize export purge worker: " + err.Error())
+	}
+
+	// Audit events past their retention are deleted
+	auditPurgeSchedule := cfg.Audit.PurgeSchedule
+	if auditPurgeSchedule == "" {
+		auditPurgeSchedule = "45 4 * * *" // default: daily at 04:45
+	}
+	auditPurgeWorker, err := worker.NewScheduledWorker(
+		auditPurgeSchedule,
+		"audit-purge",
+		auditService.Purge,
+		appLogger,
+	)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize audit purge worker: " + err.Error())
+	}
+
+	// Admins can pause, resume and run the scheduled workers
+	adminService := admin.NewService(
+		repository.NewGORMAdminRepository(db, appLogger),
+		adapter.NewArticleServiceToAdminModerator(articleService),
+		[]admin.Worker{
+			metadataRetryWorker,
+			usageFlushWorker,
+			recommendationPrecomputeWorker,
+			feedPollWorker,
+			trashPurgeWorker,
+			aggregateRefreshWorker,
+			refreshPurgeWorker,
+			accountDeletionWorker,
+			exportPurgeWorker,
+			auditPurgeWorker,
+		},
+		appLogger,
+	)
+	adminHandler := admin.NewHandler(adminService)
+
 	// Start background processing
+	if err := extractionQueue.Start(); err != nil {
+		appLogger.Error("Failed to start extraction queue: " + err.Error())
+	}
 	if err := metadataRetryWorker.Start(); err != nil {
 		appLogger.Error("Failed to start metadata retry worker: " + err.Error())
 	}
+	if err := usageFlushWorker.Start(); err != nil {
+		appLogger.Error("Failed to start usage flush worker: " + err.Error())
+	}
+	if err := recommendationPrecomputeWorker.Start(); err != nil {
+		appLogger.Error("Failed to start recommendation precompute worker: " + err.Error())
+	}
+	if err := feedPollWorker.Start(); err != nil {
+		appLogger.Error("Failed to start feed poller: " + err.Error())
+	}
+	if err := trashPurgeWorker.Start(); err != nil {
+		appLogger.Error("Failed to start trash purge worker: " + err.Error())
+	}
+	if err := aggregateRefreshWorker.Start(); err != nil {
+		appLogger.Error("Failed to start aggregate refresh worker: " + err.Error())
+	}
+	if err := refreshPurgeWorker.Start(); err != nil {
+		appLogger.Error("Failed to start refresh token purge worker: " + err.Error())
+	}
+	if err := accountDeletionWorker.Start(); err != nil {
+		appLogger.Error("Failed to start account deletion worker: " + err.Error())
+	}
+	if err := exportPurgeWorker.Start(); err != nil {
+		appLogger.Error("Failed to start export purge worker: " + err.Error())
+	}
+	if err := auditPurgeWorker.Start(); err != nil {
+		appLogger.Error("Failed to start audit purge worker: " + err.Error())
+	}
+
+	// Total time a request may spend on downstream calls
+	requestBudget := 20 * time.Second // default
+	if cfg.Server.RequestBudget != "" {
+		if duration, err := time.ParseDuration(cfg.Server.RequestBudget); err == nil {
+			requestBudget = duration
+		}
+	}
 
 	// Setup HTTP router with middleware
 	router := gin.New()
+	if err := utils.SetTrustedProxies(router, cfg.Server.TrustedProxies); err != nil {
+		appLogger.Fatal(err.Error())
+	}
 
 	// Configure standard middleware stack
 	router.Use(requestid.New())
-	router.Use(gin.Logger())
+	router.Use(requestIDContextMiddleware())
+	router.Use(gin.LoggerWithFormatter(utils.AccessLogFormatter))
 	router.Use(gin.Recovery())
+	router.Use(utils.RequestBudget(requestBudget))
+	router.Use(statementTimeoutMiddleware(statementTimeouts.HTTP))
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:  []string{"*"},
-		AllowMethods:  []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods:  []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:  []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID"},
 		ExposeHeaders: []string{"X-Request-ID"},
 	}))
@@ -138,39 +584,66 @@ func main() {
 	})
 
 	router.GET("/health/detailed", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
+		health := gin.H{
 			"status":       "healthy",
 			"timestamp":    time.Now(),
 			"service":      "articles-backend",
 			"retry_worker": metadataRetryWorker.IsRunning(),
+			"queue":        extractionQueue.Stats(),
 			"database":     "connected",
 			"classifier":   metadataClassifier.IsHealthy(),
-		})
+		}
+		if canaryClassifier != nil {
+			health["classifier_canary"] = canaryClassifier.Stats()
+		}
+		if shadowClassifier != nil {
+			health["classifier_shadow"] = shadowClassifier.Stats()
+		}
+		embeddingStatus := embeddingHealth.Status()
+		health["embedding"] = embeddingStatus
+		health["embedding_cache"] = embeddingCache.Stats()
+		if !embeddingStatus.Healthy {
+			health["status"] = "degraded"
+		}
+		c.JSON(http.StatusOK, health)
 	})
 
-	// Create simple JWT validation middleware
-	jwtSecret := cfg.JWT.Secret
-	if jwtSecret == "" {
-		jwtSecret = "change-me-in-production" // default
-	}
-	authMiddleware := createJWTMiddleware(jwtSecret)
+	// Other services verify tokens with the published public keys
+	signing.NewHandler(signingKeys).RegisterRoutes(router)
+
+	// Every protected route authenticates through the user handler, which also
+	// accounts API usage
+	authMiddleware := userHandler.AuthMiddleware(usageService)
+	adminRateLimit := adminRateLimiter.Middleware()
 
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
 		// Register feature routes - each feature manages its own routes
-		userHandler.RegisterRoutes(v1, authMiddleware)
+		userHandler.RegisterRoutes(v1, authMiddleware, adminRateLimit)
 		articleHandler.RegisterRoutes(v1, authMiddleware)
 		ratingHandler.RegisterRoutes(v1, authMiddleware)
-		recommendationHandler.RegisterRoutes(v1, authMiddleware)
+		collectionHandler.RegisterRoutes(v1, authMiddleware)
+		shareHandler.RegisterRoutes(v1, authMiddleware)
+		recommendationHandler.RegisterRoutes(v1, authMiddleware, adminRateLimit)
+		chaosHandler.RegisterRoutes(v1, authMiddleware, adminRateLimit)
+		mlExportHandler.RegisterRoutes(v1, authMiddleware, adminRateLimit)
+		importHandler.RegisterRoutes(v1, authMiddleware)
+		exportHandler.RegisterRoutes(v1, authMiddleware)
+		oauthHandler.RegisterRoutes(v1)
+		feedHandler.RegisterRoutes(v1, authMiddleware)
+		usageHandler.RegisterRoutes(v1, authMiddleware)
+		adminHandler.RegisterRoutes(v1, authMiddleware, adminRateLimit)
+		aggregateHandler.RegisterRoutes(v1, authMiddleware, adminRateLimit)
+		auditHandler.RegisterRoutes(v1, authMiddleware, adminRateLimit)
 	}
 
 	// Legacy compatibility routes (can be removed later)
 	legacyRoutes := router.Group("/")
 	{
 		// Auth routes (public)
-		legacyRoutes.POST("/signup", userHandler.SignUp)
-		legacyRoutes.POST("/login", userHandler.Login)
+		legacyRoutes.POST("/signup", userHandler.LoginRateLimit(), userHandler.SignUp)
+		legacyRoutes.POST("/login", userHandler.LoginRateLimit(), userHandler.Login)
 
 		// Protected routes with auth middleware
 		protected := legacyRoutes.Group("/")
@@ -181,15 +654,34 @@ func main() {
 			// Articles
 			protected.POST("/articles", articleHandler.CreateArticle)
 			protected.GET("/articles", articleHandler.GetArticles)
+			protected.GET("/articles/search", articleHandler.SearchArticles)
+			protected.GET("/articles/export", articleHandler.ExportArticles)
+			protected.GET("/articles/stats", articleHandler.GetStats)
+			protected.GET("/articles/:id", articleHandler.GetArticle)
+			protected.PATCH("/articles/:id", articleHandler.UpdateArticle)
+			protected.POST("/articles/:id/refresh", articleHandler.RefreshMetadata)
+			protected.GET("/articles/:id/content", articleHandler.GetContent)
 			protected.DELETE("/articles/:id", articleHandler.DeleteArticle)
+			protected.POST("/articles/:id/tags", articleHandler.AddTags)
+			protected.DELETE("/articles/:id/tags/:tag", articleHandler.RemoveTag)
+			protected.GET("/articles/:id/highlights", articleHandler.GetHighlights)
+			protected.POST("/articles/:id/highlights", articleHandler.CreateHighlight)
+			protected.PATCH("/articles/:id/highlights/:highlightId", articleHandler.UpdateHighlight)
+			protected.DELETE("/articles/:id/highlights/:highlightId", articleHandler.DeleteHighlight)
+			protected.GET("/tags", articleHandler.GetTags)
 
 			// Ratings - using simplified path as per requirements
 			protected.POST("/articles/:id/rate", ratingHandler.RateArticle)
 			protected.GET("/articles/:id/rate", ratingHandler.GetRating)
 			protected.DELETE("/articles/:id/rate", ratingHandler.DeleteRating)
+			protected.GET("/articles/:id/ratings/stats", ratingHandler.GetRatingStats)
+			protected.GET("/articles/:id/reactions", ratingHandler.GetReactions)
+			protected.POST("/articles/:id/reactions", ratingHandler.React)
+			protected.DELETE("/articles/:id/reactions/:reaction", ratingHandler.RemoveReaction)
 
 			// Recommendations
 			protected.GET("/recommendations", recommendationHandler.GetRecommendations)
+			protected.GET("/search/semantic", recommendationHandler.SemanticSearch)
 		}
 	}
 
@@ -246,6 +738,33 @@ func main() {
 	if err := metadataRetryWorker.Stop(); err != nil {
 		appLogger.Error("Error stopping retry worker: " + err.Error())
 	}
+	if err := usageFlushWorker.Stop(); err != nil {
+		appLogger.Error("Error stopping usage flush worker: " + err.Error())
+	}
+	if err := recommendationPrecomputeWorker.Stop(); err != nil {
+		appLogger.Error("Error stopping recommendation precompute worker: " + err.Error())
+	}
+	if err := feedPollWorker.Stop(); err != nil {
+		appLogger.Error("Error stopping feed poller: " + err.Error())
+	}
+	if err := trashPurgeWorker.Stop(); err != nil {
+		appLogger.Error("Error stopping trash purge worker: " + err.Error())
+	}
+	if err := aggregateRefreshWorker.Stop(); err != nil {
+		appLogger.Error("Error stopping aggregate refresh worker: " + err.Error())
+	}
+	if err := refreshPurgeWorker.Stop(); err != nil {
+		appLogger.Error("Error stopping refresh token purge worker: " + err.Error())
+	}
+	if err := accountDeletionWorker.Stop(); err != nil {
+		appLogger.Error("Error stopping account deletion worker: " + err.Error())
+	}
+	if err := exportPurgeWorker.Stop(); err != nil {
+		appLogger.Error("Error stopping export purge worker: " + err.Error())
+	}
+	if err := auditPurgeWorker.Stop(); err != nil {
+		appLogger.Error("Error stopping audit purge worker: " + err.Error())
+	}
 
 	// Shutdown server with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -255,66 +774,36 @@ func main() {
 		appLogger.Fatal("Server forced to shutdown: " + err.Error())
 	}
 
+	// Let running extractions finish; articles still waiting keep their status and can be refreshed
+	if err := extractionQueue.Stop(); err != nil {
+		appLogger.Error("Error stopping extraction queue: " + err.Error())
+	}
+
+	// Persist usage counted since the last flush
+	if err := usageService.Flush(); err != nil {
+		appLogger.Error("Failed to flush usage counters: " + err.Error())
+	}
+
 	appLogger.Info("Server shutdown complete")
 }
 
 // loadConfig is no longer used - configuration is now loaded directly as raw strings
 // and each package handles its own defaults and validation
 
-// createJWTMiddleware creates a simple JWT validation middleware
-func createJWTMiddleware(secret string) gin.HandlerFunc {
+// statementTimeoutMiddleware applies the stricter HTTP statement timeout to
+// queries bound to the request context
+func statementTimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
-			return
-		}
-
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
-			c.Abort()
-			return
-		}
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(secret), nil
-		})
-
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
-			return
-		}
-
-		// Extract user information from token claims
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			if userIDStr, exists := claims["user_id"].(string); exists {
-				if userID, err := uuid.Parse(userIDStr); err == nil {
-					c.Set("user_id", userID)
-				}
-			}
-			if email, exists := claims["email"]; exists {
-				c.Set("email", email)
-			}
-			// Create a proper User struct for the handler
-			if userIDStr, exists := claims["user_id"].(string); exists {
-				if email, emailExists := claims["email"].(string); emailExists {
-					if userID, err := uuid.Parse(userIDStr); err == nil {
-						userObj := &user.User{
-							ID:    userID,
-							Email: email,
-						}
-						c.Set("user", userObj)
-					}
-				}
-			}
-		}
+		c.Request = c.Request.WithContext(database.WithStatementTimeout(c.Request.Context(), timeout))
+		c.Next()
+	}
+}
 
+// requestIDContextMiddleware puts the request ID into the request context, so
+// services log under it and forward it to the embedding service
+func requestIDContextMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(logger.ContextWithRequestID(c.Request.Context(), requestid.Get(c)))
 		c.Next()
 	}
 }
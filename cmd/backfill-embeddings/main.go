@@ -0,0 +1,115 @@
+// Command backfill-embeddings embeds articles whose title or content
+// embedding is pending or failed, e.g. after the embedding service was down
+// or a new embedding space was added. It reads the same environment as the
+// API server and can be stopped and run again at any time.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/backfill"
+	"github.com/dustin/articles-backend/internal/embedding"
+	"github.com/dustin/articles-backend/internal/repository"
+	"github.com/dustin/articles-backend/pkg/database"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/dustin/articles-backend/pkg/storage"
+)
+
+func main() {
+	statuses := flag.String("status", "pending,failed", "Comma-separated embedding statuses to backfill")
+	batchSize := flag.Int("batch-size", backfill.DefaultBatchSize, fmt.Sprintf("Articles per embedding service request (at most %d)", backfill.MaxBatchSize))
+	interval := flag.Duration("interval", time.Second, "Least time between two embedding service requests")
+	maxArticles := flag.Int("max", 0, "Stop after this many articles; 0 for all")
+	dryRun := flag.Bool("dry-run", false, "Only count the articles that would be backfilled")
+	flag.Parse()
+
+	// Load configuration from environment variables
+	cfg := config.Load()
+
+	appLogger, err := logger.NewLogger(&cfg.Logging)
+	if err != nil {
+		panic("Failed to initialize logger: " + err.Error())
+	}
+
+	db, err := database.NewConnection(&cfg.Database)
+	if err != nil {
+		appLogger.Fatal("Failed to connect to database: " + err.Error())
+	}
+
+	// Content kept in object storage is read from there
+	contentStorage, err := storage.NewStorage(&cfg.Storage, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize storage: " + err.Error())
+	}
+
+	embeddingServiceURL := os.Getenv("EMBEDDING_SERVICE_URL")
+	if embeddingServiceURL == "" {
+		embeddingServiceURL = "http://localhost:8001"
+	}
+
+	embeddingService := embedding.NewClient(embeddingServiceURL)
+	embeddingProvider, err := embedding.NewProvider(&cfg.Embedding, embeddingService)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize embedding provider: " + err.Error())
+	}
+	embeddingStore := repository.NewGORMEmbeddingRepository(db, appLogger)
+	resilientClient, err := embedding.NewResilientClient(
+		embedding.NewProviderClient(embeddingProvider, embeddingService, embeddingStore, appLogger),
+		&cfg.Embedding,
+		appLogger,
+	)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize embedding client: " + err.Error())
+	}
+	// Articles saved by several users share their text, which is embedded once
+	embeddingClient, err := embedding.NewCachingClient(resilientClient, &cfg.Embedding, embeddingStore, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize embedding cache: " + err.Error())
+	}
+
+	backfillService := backfill.NewService(
+		repository.NewGORMBackfillRepository(db, appLogger),
+		embeddingClient,
+		contentStorage,
+		appLogger,
+	)
+
+	// Stop between batches on Ctrl-C; running again picks up where this run left off
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	opts := backfill.Options{
+		Statuses:    strings.Split(*statuses, ","),
+		BatchSize:   *batchSize,
+		Interval:    *interval,
+		MaxArticles: *maxArticles,
+		DryRun:      *dryRun,
+	}
+	progress, err := backfillService.Run(ctx, opts, func(progress *backfill.Progress) {
+		percent := 100.0
+		if progress.Total > 0 {
+			percent = float64(progress.Processed) / float64(progress.Total) * 100
+		}
+		fmt.Printf("%d/%d articles (%.0f%%): %d embedded, %d failed, %d skipped\n", progress.Processed, progress.Total, percent, progress.Embedded, progress.Failed, progress.Skipped)
+	})
+	if err != nil {
+		if progress != nil {
+			fmt.Printf("Stopped after %d of %d articles: %s\n", progress.Processed, progress.Total, err)
+		}
+		appLogger.Fatal("Embedding backfill failed: " + err.Error())
+	}
+
+	if *dryRun {
+		fmt.Printf("%d articles to backfill\n", progress.Total)
+		return
+	}
+	fmt.Printf("Done: %d articles, %d embedded, %d failed, %d skipped\n", progress.Processed, progress.Embedded, progress.Failed, progress.Skipped)
+}
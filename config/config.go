@@ -2,20 +2,42 @@ package config
 
 // Config contains all configuration grouped by domain
 type Config struct {
-	Server     ServerConfig
-	Database   DatabaseConfig
-	JWT        JWTConfig
-	Worker     WorkerConfig
-	Logging    LoggingConfig
-	Classifier ClassifierConfig
+	Server         ServerConfig
+	Database       DatabaseConfig
+	JWT            JWTConfig
+	Password       PasswordConfig
+	Account        AccountConfig
+	Worker         WorkerConfig
+	Queue          QueueConfig
+	Logging        LoggingConfig
+	Classifier     ClassifierConfig
+	Chaos          ChaosConfig
+	MLExport       MLExportConfig
+	Recommendation RecommendationConfig
+	Usage          UsageConfig
+	Admin          AdminConfig
+	Storage        StorageConfig
+	Feed           FeedConfig
+	Article        ArticleConfig
+	Aggregate      AggregateConfig
+	Export         ExportConfig
+	OAuth          OAuthConfig
+	Login          LoginConfig
+	Audit          AuditConfig
+	Rating         RatingConfig
+	Embedding      EmbeddingConfig
 }
 
 // All config structs use string fields only - packages handle conversion during initialization
 type ServerConfig struct {
-	Port         string
-	Environment  string
-	ReadTimeout  string
-	WriteTimeout string
+	Port          string
+	Environment   string
+	ReadTimeout   string
+	WriteTimeout  string
+	RequestBudget string
+	// TrustedProxies lists the IPs or CIDRs whose X-Forwarded-For is believed;
+	// empty trusts none
+	TrustedProxies string
 }
 
 type DatabaseConfig struct {
@@ -25,17 +47,50 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	StatementTimeout     string // Longest a single statement may run
+	HTTPStatementTimeout string // Stricter limit for statements bound to a request
 }
 
 type JWTConfig struct {
-	Secret     string
-	Expiration string
+	Secret       string
+	Expiration   string // Longest lifetime of scoped tokens
+	UserCacheTTL string
+	// Login sessions: access tokens renewed with rotating refresh tokens
+	AccessExpiration     string
+	RefreshExpiration    string
+	RefreshPurgeSchedule string
+	// Asymmetric signing: a PEM private key (RSA or Ed25519) replaces HS256
+	// with the secret; retired keys keep verifying until their tokens expire
+	SigningKeyFile       string
+	VerificationKeyFiles string // Comma-separated PEM keys
+	AcceptSecretTokens   string // Keep accepting HS256 tokens after switching to a signing key
+}
+
+type AccountConfig struct {
+	DeletionGracePeriod string // How long deleted accounts wait before they are purged
+	DeletionSchedule    string
+}
+
+type PasswordConfig struct {
+	HashCost    string
+	HashTarget  string
+	AutoTune    string
+	MinHashCost string
 }
 
 type WorkerConfig struct {
 	RetryInterval string
 }
 
+type QueueConfig struct {
+	Concurrency       string
+	MaxWait           string
+	InteractiveWeight string
+	ImportWeight      string
+	RetryWeight       string
+}
+
 type LoggingConfig struct {
 	Level       string
 	Format      string
@@ -46,4 +101,139 @@ type ClassifierConfig struct {
 	MinConfidenceScore string
 	HTTPTimeout        string
 	UserAgent          string
+	ReadingWPM         string
+	ReadingCPM         string // Characters per minute for Chinese and Japanese text
+
+	// Shadow names a second classifier that processes the same pages without
+	// affecting stored metadata; empty disables shadow mode
+	Shadow                   string
+	ShadowMinConfidenceScore string
+	ShadowSampleRate         string
+
+	// Canary names a classifier that processes CanaryPercent of the pages in
+	// place of the primary one; empty or 0 percent disables the rollout
+	Canary                   string
+	CanaryMinConfidenceScore string
+	CanaryPercent            string
+}
+
+type ChaosConfig struct {
+	Enabled   string
+	Latency   string
+	ErrorRate string
+	Targets   string
+}
+
+type MLExportConfig struct {
+	Enabled           string
+	AnonymizationSalt string
+}
+
+type RecommendationConfig struct {
+	EmbeddingSpace       string
+	BlendWeight          string
+	CacheTTL             string
+	PrecomputeSchedule   string
+	PrecomputeActiveDays string
+	PrecomputeMaxUsers   string
+	Engine               string // content, popular, collaborative, hybrid or bandit
+	HybridContentWeight  string
+	HybridCollabWeight   string
+	DedupeURLs           string
+	DiversityLambda      string // Similarity/variety balance of content-based rankings, 1 disables
+	ResultCacheTTL       string
+	// Popular engine ranking: weights of the signals it blends, and how fast
+	// the recency signal decays
+	PopularSavesWeight   string
+	PopularRatingWeight  string
+	PopularRecencyWeight string
+	PopularHalfLife      string
+	// Content engine: lowest rating that adds an article to the profile, and
+	// similar articles fetched per recommendation
+	MinRating           string
+	CandidateMultiplier string
+}
+
+type UsageConfig struct {
+	DailyQuota    string
+	FlushInterval string
+}
+
+type AdminConfig struct {
+	RateLimit  string
+	RateWindow string
+	Emails     string // Comma-separated; registered users with these emails are made admins at startup
+}
+
+type StorageConfig struct {
+	Backend           string
+	Path              string
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+}
+
+type FeedConfig struct {
+	PollSchedule string
+	HTTPTimeout  string
+	MaxPerUser   string
+}
+
+type ArticleConfig struct {
+	RefreshCooldown    string
+	RefreshDailyLimit  string
+	TrashPurgeSchedule string
+	BlockedDomains     string // Comma-separated; subdomains are blocked too
+	ContentStore       string // database or object
+}
+
+type AggregateConfig struct {
+	RefreshSchedule string
+	RetentionDays   string
+	LookbackDays    string // Recent days rebuilt on each refresh
+}
+
+type ExportConfig struct {
+	Retention     string // How long export archives can be downloaded
+	PurgeSchedule string
+}
+
+type OAuthConfig struct {
+	GoogleClientID     string // Google sign-in is enabled when set
+	GoogleClientSecret string
+	GitHubClientID     string // GitHub sign-in is enabled when set
+	GitHubClientSecret string
+	RedirectBaseURL    string // Public URL of /api/v1/auth/oauth; providers redirect to <base>/<provider>/callback
+}
+
+type LoginConfig struct {
+	RateLimit     string // Login and signup requests per client IP and window
+	RateWindow    string
+	MaxFailures   string // Failed logins of an account before it is locked out
+	MaxIPFailures string // Failed logins from an IP before it is locked out
+	Lockout       string
+}
+
+type AuditConfig struct {
+	RetentionDays string
+	PurgeSchedule string
+}
+
+type RatingConfig struct {
+	Policy string // own or shared; shared also allows rating other users' shared articles
+}
+
+type EmbeddingConfig struct {
+	Provider         string // service, openai or cohere
+	Model            string // Model of an openai or cohere provider
+	APIKey           string
+	APIURL           string // Base URL of an OpenAI-compatible or Cohere API
+	MaxRetries       string // Retries of a failed call; 0 disables retries
+	RetryBaseDelay   string // Backoff before the first retry, doubled for each further one
+	RetryMaxDelay    string
+	BreakerThreshold string // Consecutive failed calls that open the circuit breaker; 0 disables it
+	BreakerCooldown  string // How long the breaker stays open before letting a trial call through
+	CacheSize        string // Embeddings kept in memory by text; 0 disables the cache
 }
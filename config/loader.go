@@ -7,10 +7,13 @@ import "os"
 func Load() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port:         os.Getenv("SERVER_PORT"),
-			Environment:  os.Getenv("SERVER_ENV"),
-			ReadTimeout:  os.Getenv("SERVER_READ_TIMEOUT"),
-			WriteTimeout: os.Getenv("SERVER_WRITE_TIMEOUT"),
+			Port:          os.Getenv("SERVER_PORT"),
+			Environment:   os.Getenv("SERVER_ENV"),
+			ReadTimeout:   os.Getenv("SERVER_READ_TIMEOUT"),
+			WriteTimeout:  os.Getenv("SERVER_WRITE_TIMEOUT"),
+			RequestBudget: os.Getenv("SERVER_REQUEST_BUDGET"),
+
+			TrustedProxies: os.Getenv("SERVER_TRUSTED_PROXIES"),
 		},
 		Database: DatabaseConfig{
 			Host:     os.Getenv("DB_HOST"),
@@ -19,14 +22,43 @@ func Load() *Config {
 			Password: os.Getenv("DB_PASSWORD"),
 			DBName:   os.Getenv("DB_NAME"),
 			SSLMode:  os.Getenv("DB_SSLMODE"),
+
+			StatementTimeout:     os.Getenv("DB_STATEMENT_TIMEOUT"),
+			HTTPStatementTimeout: os.Getenv("DB_HTTP_STATEMENT_TIMEOUT"),
 		},
 		JWT: JWTConfig{
-			Secret:     os.Getenv("JWT_SECRET"),
-			Expiration: os.Getenv("JWT_EXPIRATION"),
+			Secret:       os.Getenv("JWT_SECRET"),
+			Expiration:   os.Getenv("JWT_EXPIRATION"),
+			UserCacheTTL: os.Getenv("AUTH_USER_CACHE_TTL"),
+
+			AccessExpiration:     os.Getenv("JWT_ACCESS_EXPIRATION"),
+			RefreshExpiration:    os.Getenv("JWT_REFRESH_EXPIRATION"),
+			RefreshPurgeSchedule: os.Getenv("JWT_REFRESH_PURGE_SCHEDULE"),
+
+			SigningKeyFile:       os.Getenv("JWT_SIGNING_KEY_FILE"),
+			VerificationKeyFiles: os.Getenv("JWT_VERIFICATION_KEY_FILES"),
+			AcceptSecretTokens:   os.Getenv("JWT_ACCEPT_HS256"),
+		},
+		Password: PasswordConfig{
+			HashCost:    os.Getenv("PASSWORD_HASH_COST"),
+			HashTarget:  os.Getenv("PASSWORD_HASH_TARGET"),
+			AutoTune:    os.Getenv("PASSWORD_HASH_AUTOTUNE"),
+			MinHashCost: os.Getenv("PASSWORD_HASH_MIN_COST"),
+		},
+		Account: AccountConfig{
+			DeletionGracePeriod: os.Getenv("ACCOUNT_DELETION_GRACE_PERIOD"),
+			DeletionSchedule:    os.Getenv("ACCOUNT_DELETION_SCHEDULE"),
 		},
 		Worker: WorkerConfig{
 			RetryInterval: os.Getenv("WORKER_RETRY_INTERVAL"),
 		},
+		Queue: QueueConfig{
+			Concurrency:       os.Getenv("QUEUE_CONCURRENCY"),
+			MaxWait:           os.Getenv("QUEUE_MAX_WAIT"),
+			InteractiveWeight: os.Getenv("QUEUE_WEIGHT_INTERACTIVE"),
+			ImportWeight:      os.Getenv("QUEUE_WEIGHT_IMPORT"),
+			RetryWeight:       os.Getenv("QUEUE_WEIGHT_RETRY"),
+		},
 		Logging: LoggingConfig{
 			Level:       os.Getenv("LOG_LEVEL"),
 			Format:      os.Getenv("LOG_FORMAT"),
@@ -36,6 +68,117 @@ func Load() *Config {
 			MinConfidenceScore: os.Getenv("CLASSIFIER_MIN_CONFIDENCE"),
 			HTTPTimeout:        os.Getenv("CLASSIFIER_HTTP_TIMEOUT"),
 			UserAgent:          os.Getenv("CLASSIFIER_USER_AGENT"),
+			ReadingWPM:         os.Getenv("CLASSIFIER_READING_WPM"),
+			ReadingCPM:         os.Getenv("CLASSIFIER_READING_CPM"),
+
+			Shadow:                   os.Getenv("CLASSIFIER_SHADOW"),
+			ShadowMinConfidenceScore: os.Getenv("CLASSIFIER_SHADOW_MIN_CONFIDENCE"),
+			ShadowSampleRate:         os.Getenv("CLASSIFIER_SHADOW_SAMPLE_RATE"),
+			Canary:                   os.Getenv("CLASSIFIER_CANARY"),
+			CanaryMinConfidenceScore: os.Getenv("CLASSIFIER_CANARY_MIN_CONFIDENCE"),
+			CanaryPercent:            os.Getenv("CLASSIFIER_CANARY_PERCENT"),
+		},
+		Chaos: ChaosConfig{
+			Enabled:   os.Getenv("CHAOS_ENABLED"),
+			Latency:   os.Getenv("CHAOS_LATENCY"),
+			ErrorRate: os.Getenv("CHAOS_ERROR_RATE"),
+			Targets:   os.Getenv("CHAOS_TARGETS"),
+		},
+		MLExport: MLExportConfig{
+			Enabled:           os.Getenv("ML_EXPORT_ENABLED"),
+			AnonymizationSalt: os.Getenv("ML_EXPORT_SALT"),
+		},
+		Recommendation: RecommendationConfig{
+			EmbeddingSpace:       os.Getenv("RECOMMENDATION_EMBEDDING_SPACE"),
+			BlendWeight:          os.Getenv("RECOMMENDATION_BLEND_WEIGHT"),
+			CacheTTL:             os.Getenv("RECOMMENDATION_CACHE_TTL"),
+			PrecomputeSchedule:   os.Getenv("RECOMMENDATION_PRECOMPUTE_SCHEDULE"),
+			PrecomputeActiveDays: os.Getenv("RECOMMENDATION_PRECOMPUTE_ACTIVE_DAYS"),
+			PrecomputeMaxUsers:   os.Getenv("RECOMMENDATION_PRECOMPUTE_MAX_USERS"),
+			Engine:               os.Getenv("RECOMMENDATION_ENGINE"),
+			HybridContentWeight:  os.Getenv("RECOMMENDATION_HYBRID_CONTENT_WEIGHT"),
+			HybridCollabWeight:   os.Getenv("RECOMMENDATION_HYBRID_COLLABORATIVE_WEIGHT"),
+			DedupeURLs:           os.Getenv("RECOMMENDATION_DEDUPE_URLS"),
+			DiversityLambda:      os.Getenv("RECOMMENDATION_DIVERSITY_LAMBDA"),
+			ResultCacheTTL:       os.Getenv("RECOMMENDATION_RESULT_CACHE_TTL"),
+			PopularSavesWeight:   os.Getenv("RECOMMENDATION_POPULAR_SAVES_WEIGHT"),
+			PopularRatingWeight:  os.Getenv("RECOMMENDATION_POPULAR_RATING_WEIGHT"),
+			PopularRecencyWeight: os.Getenv("RECOMMENDATION_POPULAR_RECENCY_WEIGHT"),
+			PopularHalfLife:      os.Getenv("RECOMMENDATION_POPULAR_HALF_LIFE"),
+			MinRating:            os.Getenv("RECOMMENDATION_MIN_RATING"),
+			CandidateMultiplier:  os.Getenv("RECOMMENDATION_CANDIDATE_MULTIPLIER"),
+		},
+		Usage: UsageConfig{
+			DailyQuota:    os.Getenv("USAGE_DAILY_QUOTA"),
+			FlushInterval: os.Getenv("USAGE_FLUSH_INTERVAL"),
+		},
+		Admin: AdminConfig{
+			RateLimit:  os.Getenv("ADMIN_RATE_LIMIT"),
+			RateWindow: os.Getenv("ADMIN_RATE_WINDOW"),
+			Emails:     os.Getenv("ADMIN_EMAILS"),
+		},
+		Storage: StorageConfig{
+			Backend:           os.Getenv("STORAGE_BACKEND"),
+			Path:              os.Getenv("STORAGE_PATH"),
+			S3Endpoint:        os.Getenv("STORAGE_S3_ENDPOINT"),
+			S3Region:          os.Getenv("STORAGE_S3_REGION"),
+			S3Bucket:          os.Getenv("STORAGE_S3_BUCKET"),
+			S3AccessKeyID:     os.Getenv("STORAGE_S3_ACCESS_KEY_ID"),
+			S3SecretAccessKey: os.Getenv("STORAGE_S3_SECRET_ACCESS_KEY"),
+		},
+		Feed: FeedConfig{
+			PollSchedule: os.Getenv("FEED_POLL_SCHEDULE"),
+			HTTPTimeout:  os.Getenv("FEED_HTTP_TIMEOUT"),
+			MaxPerUser:   os.Getenv("FEED_MAX_PER_USER"),
+		},
+		Article: ArticleConfig{
+			RefreshCooldown:    os.Getenv("ARTICLE_REFRESH_COOLDOWN"),
+			RefreshDailyLimit:  os.Getenv("ARTICLE_REFRESH_DAILY_LIMIT"),
+			TrashPurgeSchedule: os.Getenv("ARTICLE_TRASH_PURGE_SCHEDULE"),
+			BlockedDomains:     os.Getenv("ARTICLE_BLOCKED_DOMAINS"),
+			ContentStore:       os.Getenv("ARTICLE_CONTENT_STORE"),
+		},
+		Aggregate: AggregateConfig{
+			RefreshSchedule: os.Getenv("AGGREGATE_REFRESH_SCHEDULE"),
+			RetentionDays:   os.Getenv("AGGREGATE_RETENTION_DAYS"),
+			LookbackDays:    os.Getenv("AGGREGATE_LOOKBACK_DAYS"),
+		},
+		Export: ExportConfig{
+			Retention:     os.Getenv("EXPORT_RETENTION"),
+			PurgeSchedule: os.Getenv("EXPORT_PURGE_SCHEDULE"),
+		},
+		OAuth: OAuthConfig{
+			GoogleClientID:     os.Getenv("OAUTH_GOOGLE_CLIENT_ID"),
+			GoogleClientSecret: os.Getenv("OAUTH_GOOGLE_CLIENT_SECRET"),
+			GitHubClientID:     os.Getenv("OAUTH_GITHUB_CLIENT_ID"),
+			GitHubClientSecret: os.Getenv("OAUTH_GITHUB_CLIENT_SECRET"),
+			RedirectBaseURL:    os.Getenv("OAUTH_REDIRECT_BASE_URL"),
+		},
+		Login: LoginConfig{
+			RateLimit:     os.Getenv("LOGIN_RATE_LIMIT"),
+			RateWindow:    os.Getenv("LOGIN_RATE_WINDOW"),
+			MaxFailures:   os.Getenv("LOGIN_MAX_FAILURES"),
+			MaxIPFailures: os.Getenv("LOGIN_MAX_IP_FAILURES"),
+			Lockout:       os.Getenv("LOGIN_LOCKOUT"),
+		},
+		Audit: AuditConfig{
+			RetentionDays: os.Getenv("AUDIT_RETENTION_DAYS"),
+			PurgeSchedule: os.Getenv("AUDIT_PURGE_SCHEDULE"),
+		},
+		Rating: RatingConfig{
+			Policy: os.Getenv("RATING_POLICY"),
+		},
+		Embedding: EmbeddingConfig{
+			Provider:         os.Getenv("EMBEDDING_PROVIDER"),
+			Model:            os.Getenv("EMBEDDING_MODEL"),
+			APIKey:           os.Getenv("EMBEDDING_API_KEY"),
+			APIURL:           os.Getenv("EMBEDDING_API_URL"),
+			MaxRetries:       os.Getenv("EMBEDDING_MAX_RETRIES"),
+			RetryBaseDelay:   os.Getenv("EMBEDDING_RETRY_BASE_DELAY"),
+			RetryMaxDelay:    os.Getenv("EMBEDDING_RETRY_MAX_DELAY"),
+			BreakerThreshold: os.Getenv("EMBEDDING_BREAKER_THRESHOLD"),
+			BreakerCooldown:  os.Getenv("EMBEDDING_BREAKER_COOLDOWN"),
+			CacheSize:        os.Getenv("EMBEDDING_CACHE_SIZE"),
 		},
 	}
 }
@@ -4,36 +4,61 @@ Multilingual Sentence Embedding Microservice
 Uses all-MiniLM-L6-v2 model for generating embeddings
 """
 
-from flask import Flask, request, jsonify
+from flask import Flask, request, jsonify, g, has_request_context
 from sentence_transformers import SentenceTransformer
 from transformers import pipeline
 import numpy as np
+import json
 import logging
 import os
 import re
-from uuid import UUID
+from uuid import UUID, uuid4
 from sqlalchemy.exc import SQLAlchemyError
 
 from database import init_database, get_db_session, health_check as db_health_check
 from models import Article
 
 app = Flask(__name__)
-logging.basicConfig(level=logging.INFO)
+
+# Header carrying the ID of the API request that led to a call, so one ID
+# traces a request across the API and this service
+REQUEST_ID_HEADER = 'X-Request-ID'
+
+class RequestIDFilter(logging.Filter):
+    """Tag log records with the ID of the request being served"""
+    def filter(self, record):
+        record.request_id = g.get('request_id', '-') if has_request_context() else '-'
+        return True
+
+logging.basicConfig(level=logging.INFO, format='%(asctime)s %(levelname)s %(name)s [request_id=%(request_id)s] %(message)s')
+for log_handler in logging.getLogger().handlers:
+    log_handler.addFilter(RequestIDFilter())
 logger = logging.getLogger(__name__)
 
 # Load the multilingual sentence transformer model
 MODEL_NAME = "all-MiniLM-L6-v2"
 CLASSIFIER_MODEL_NAME = "distilbert-base-uncased-finetuned-sst-2-english"
+# Optional model for non-English articles; it must produce vectors of the same
+# dimension in a space aligned with MODEL_NAME so languages stay comparable
+MULTILINGUAL_MODEL_NAME = os.getenv('MULTILINGUAL_MODEL_NAME', '')
 model = None
+multilingual_model = None
 classifier = None
 
 def load_model():
     """Load the sentence transformer model and classifier"""
-    global model, classifier
+    global model, multilingual_model, classifier
     try:
         logger.info(f"Loading embedding model: {MODEL_NAME}")
         model = SentenceTransformer(MODEL_NAME)
         logger.info("Embedding model loaded successfully")
+
+        if MULTILINGUAL_MODEL_NAME:
+            logger.info(f"Loading multilingual embedding model: {MULTILINGUAL_MODEL_NAME}")
+            multilingual_model = SentenceTransformer(MULTILINGUAL_MODEL_NAME)
+            if multilingual_model.get_sentence_embedding_dimension() != model.get_sentence_embedding_dimension():
+                raise ValueError("multilingual model dimension does not match the embedding model")
+            logger.info("Multilingual embedding model loaded successfully")
         
         logger.info(f"Loading classifier model: {CLASSIFIER_MODEL_NAME}")
         classifier = pipeline("text-classification", 
@@ -44,6 +69,12 @@ def load_model():
         logger.error(f"Failed to load models: {e}")
         raise
 
+def model_for(language):
+    """Pick the embedding model for a text's detected language (ISO 639-1, may be empty)"""
+    if multilingual_model is not None and language and language != 'en':
+        return multilingual_model
+    return model
+
 def initialize():
     """Initialize the model and database"""
     load_model()
@@ -58,6 +89,25 @@ def initialize():
 with app.app_context():
     initialize()
 
+@app.before_request
+def assign_request_id():
+    """Use the caller's request ID, or a new one for direct calls"""
+    g.request_id = request.headers.get(REQUEST_ID_HEADER) or str(uuid4())
+
+@app.after_request
+def echo_request_id(response):
+    """Return the request ID, also in the body of error responses"""
+    request_id = g.get('request_id')
+    if not request_id:
+        return response
+    response.headers[REQUEST_ID_HEADER] = request_id
+    if response.status_code >= 400 and response.is_json:
+        body = response.get_json(silent=True)
+        if isinstance(body, dict):
+            body['request_id'] = request_id
+            response.set_data(json.dumps(body))
+    return response
+
 @app.route('/health', methods=['GET'])
 def health_check():
     """Health check endpoint"""
@@ -65,10 +115,12 @@ def health_check():
     return jsonify({
         "status": "healthy" if db_healthy else "unhealthy",
         "embedding_model": MODEL_NAME,
+        "multilingual_model": MULTILINGUAL_MODEL_NAME or None,
         "classifier_model": CLASSIFIER_MODEL_NAME,
         "embedding_model_loaded": model is not None,
         "classifier_loaded": classifier is not None,
-        "database_healthy": db_healthy
+        "database_healthy": db_healthy,
+        "embedding_dimension": model.get_sentence_embedding_dimension() if model is not None else None
     })
 
 @app.route('/embed', methods=['POST'])
@@ -349,10 +401,18 @@ def generate_and_store_embedding(article_id):
         if not text:
             return jsonify({"error": "Empty text provided"}), 400
         
-        logger.info(f"Generating and storing embedding for article {article_id}")
+        # 'title' embeds title+description, 'content' embeds the full text
+        target = data.get('target', 'title')
+        if target not in ('title', 'content'):
+            return jsonify({"error": "Invalid target, expected 'title' or 'content'"}), 400
+        
+        # Detected by the Go service, empty when unknown
+        language = data.get('language', '')
+        
+        logger.info(f"Generating and storing {target} embedding for article {article_id} (language: {language or 'unknown'})")
         
         # Generate embedding
-        embedding = model.encode([text])[0]
+        embedding = model_for(language).encode([text])[0]
         embedding_list = embedding.tolist()
         
         # Store in database
@@ -362,9 +422,13 @@ def generate_and_store_embedding(article_id):
             if not article:
                 return jsonify({"error": "Article not found"}), 404
             
-            # Update embedding fields
-            article.embedding = embedding_list
-            article.embedding_status = 'success'
+            # Update embedding fields for the requested target
+            if target == 'content':
+                article.content_embedding = embedding_list
+                article.content_embedding_status = 'success'
+            else:
+                article.embedding = embedding_list
+                article.embedding_status = 'success'
             
             session.commit()
             
@@ -374,6 +438,7 @@ def generate_and_store_embedding(article_id):
             "article_id": article_id,
             "embedding_dimension": len(embedding_list),
             "embedding_status": "success",
+            "target": target,
             "message": "Embedding generated and stored successfully"
         })
         
@@ -406,6 +471,17 @@ def generate_batch_embeddings_and_store():
                 try:
                     article_id = article_data.get('id')
                     text = article_data.get('text', '').strip()
+                    # 'title' embeds title+description, 'content' embeds the full text
+                    target = article_data.get('target', 'title')
+                    language = article_data.get('language', '')
+                    
+                    if target not in ('title', 'content'):
+                        results.append({
+                            "article_id": article_id,
+                            "status": "error",
+                            "message": "Invalid target, expected 'title' or 'content'"
+                        })
+                        continue
                     
                     if not article_id or not text:
                         results.append({
@@ -427,7 +503,7 @@ def generate_batch_embeddings_and_store():
                         continue
                     
                     # Generate embedding
-                    embedding = model.encode([text])[0]
+                    embedding = model_for(language).encode([text])[0]
                     embedding_list = embedding.tolist()
                     
                     # Find and update article
@@ -440,9 +516,13 @@ def generate_batch_embeddings_and_store():
                         })
                         continue
                     
-                    # Update embedding fields
-                    article.embedding = embedding_list
-                    article.embedding_status = 'success'
+                    # Update embedding fields for the requested target
+                    if target == 'content':
+                        article.content_embedding = embedding_list
+                        article.content_embedding_status = 'success'
+                    else:
+                        article.embedding = embedding_list
+                        article.embedding_status = 'success'
                     
                     successful_updates += 1
                     results.append({
@@ -32,6 +32,8 @@ class Article(Base):
     # Vector embedding fields
     embedding = Column(Vector(384), index=True)  # 384-dimensional vector for all-MiniLM-L6-v2
     embedding_status = Column(String(20), default='pending')
+    content_embedding = Column(Vector(384))  # Full-content embedding for long-document similarity
+    content_embedding_status = Column(String(20), default='pending')
     
     # Timestamps
     created_at = Column(DateTime(timezone=True), server_default=func.now(), index=True)
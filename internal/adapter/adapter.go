@@ -1,9 +1,23 @@
 package adapter
 
 import (
+	"context"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/admin"
 	"github.com/dustin/articles-backend/internal/article"
+	"github.com/dustin/articles-backend/internal/audit"
+	"github.com/dustin/articles-backend/internal/auth/oauth"
 	"github.com/dustin/articles-backend/internal/classifier"
+	"github.com/dustin/articles-backend/internal/collection"
+	"github.com/dustin/articles-backend/internal/feed"
+	"github.com/dustin/articles-backend/internal/importer"
 	"github.com/dustin/articles-backend/internal/rating"
+	"github.com/dustin/articles-backend/internal/recommendation"
+	"github.com/dustin/articles-backend/internal/share"
+	"github.com/dustin/articles-backend/internal/user"
+	"github.com/dustin/articles-backend/internal/worker"
+	"github.com/dustin/articles-backend/pkg/storage"
 	"github.com/google/uuid"
 )
 
@@ -19,9 +33,9 @@ func NewClassifierToMetadataExtractor(c classifier.Classifier) article.MetadataE
 	}
 }
 
-func (a *ClassifierToMetadataExtractor) Extract(url string) (*article.ExtractedMetadata, error) {
+func (a *ClassifierToMetadataExtractor) Extract(ctx context.Context, url string) (*article.ExtractedMetadata, error) {
 	// Call classifier with empty HTML to let it fetch the content
-	result, err := a.classifier.Classify(url, "")
+	result, err := a.classifier.Classify(ctx, url, "")
 	if err != nil {
 		return nil, err
 	}
@@ -31,12 +45,46 @@ func (a *ClassifierToMetadataExtractor) Extract(url string) (*article.ExtractedM
 		Title:       result.Title,
 		Description: result.Description,
 		Content:     result.Content,
+		HTML:        result.HTML,
 		ImageURL:    result.Image,
+		SiteName:    result.SiteName,
+		FaviconURL:  result.FaviconURL,
 		WordCount:   result.WordCount,
+		ReadingTime: result.ReadingTime,
+		Language:    result.Language,
 		Confidence:  result.Confidence,
 	}, nil
 }
 
+// ArticleServiceToAdminModerator adapts article.Service to admin.ArticleModerator
+type ArticleServiceToAdminModerator struct {
+	service article.Service
+}
+
+// NewArticleServiceToAdminModerator creates a new adapter
+func NewArticleServiceToAdminModerator(s article.Service) admin.ArticleModerator {
+	return &ArticleServiceToAdminModerator{
+		service: s,
+	}
+}
+
+func (a *ArticleServiceToAdminModerator) RemoveArticle(id uuid.UUID) (*admin.ArticleRow, error) {
+	articleEntity, err := a.service.RemoveArticle(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &admin.ArticleRow{
+		ID:              articleEntity.ID,
+		UserID:          articleEntity.UserID,
+		URL:             articleEntity.URL,
+		Title:           articleEntity.Title,
+		MetadataStatus:  articleEntity.MetadataStatus,
+		EmbeddingStatus: articleEntity.EmbeddingStatus,
+		CreatedAt:       articleEntity.CreatedAt,
+	}, nil
+}
+
 // ArticleServiceToRatingArticleService adapts article.Service to rating.ArticleService
 type ArticleServiceToRatingArticleService struct {
 	service article.Service
@@ -63,3 +111,359 @@ func (a *ArticleServiceToRatingArticleService) GetArticle(id uuid.UUID, userID u
 		URL:    articleEntity.URL,
 	}, nil
 }
+
+// ArticleServiceToCollectionArticleService adapts article.Service to collection.ArticleService
+type ArticleServiceToCollectionArticleService struct {
+	service article.Service
+}
+
+// NewArticleServiceToCollectionArticleService creates a new adapter
+func NewArticleServiceToCollectionArticleService(s article.Service) collection.ArticleService {
+	return &ArticleServiceToCollectionArticleService{
+		service: s,
+	}
+}
+
+func (a *ArticleServiceToCollectionArticleService) GetArticle(id uuid.UUID, userID uuid.UUID) (*collection.Article, error) {
+	articleEntity, err := a.service.GetArticle(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert article.Article to collection.Article
+	return &collection.Article{
+		ID:             articleEntity.ID,
+		UserID:         articleEntity.UserID,
+		URL:            articleEntity.URL,
+		Title:          articleEntity.Title,
+		Description:    articleEntity.Description,
+		ImageURL:       articleEntity.ImageURL,
+		WordCount:      articleEntity.WordCount,
+		ReadingTime:    articleEntity.ReadingTime,
+		MetadataStatus: articleEntity.MetadataStatus,
+		CreatedAt:      articleEntity.CreatedAt,
+	}, nil
+}
+
+// ArticleServiceToShareArticleService adapts article.Service to share.ArticleService
+type ArticleServiceToShareArticleService struct {
+	service article.Service
+}
+
+// NewArticleServiceToShareArticleService creates a new adapter
+func NewArticleServiceToShareArticleService(s article.Service) share.ArticleService {
+	return &ArticleServiceToShareArticleService{
+		service: s,
+	}
+}
+
+func (a *ArticleServiceToShareArticleService) GetArticle(id uuid.UUID, userID uuid.UUID) (*share.Article, error) {
+	articleEntity, err := a.service.GetArticle(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert article.Article to share.Article
+	return &share.Article{
+		ID:          articleEntity.ID,
+		UserID:      articleEntity.UserID,
+		URL:         articleEntity.URL,
+		Title:       articleEntity.Title,
+		Description: articleEntity.Description,
+		ImageURL:    articleEntity.ImageURL,
+		SiteName:    articleEntity.SiteName,
+		Content:     articleEntity.Content,
+		WordCount:   articleEntity.WordCount,
+		ReadingTime: articleEntity.ReadingTime,
+		Language:    articleEntity.Language,
+		CreatedAt:   articleEntity.CreatedAt,
+	}, nil
+}
+
+// ShareServiceToRatingSharedArticles adapts share.Service to rating.SharedArticles
+type ShareServiceToRatingSharedArticles struct {
+	service share.Service
+}
+
+// NewShareServiceToRatingSharedArticles creates a new adapter
+func NewShareServiceToRatingSharedArticles(s share.Service) rating.SharedArticles {
+	return &ShareServiceToRatingSharedArticles{
+		service: s,
+	}
+}
+
+func (a *ShareServiceToRatingSharedArticles) IsShared(articleID uuid.UUID) (bool, error) {
+	return a.service.IsShared(articleID)
+}
+
+// ArticleServiceToImporterArticleService adapts article.Service to importer.ArticleService
+type ArticleServiceToImporterArticleService struct {
+	service article.Service
+}
+
+// NewArticleServiceToImporterArticleService creates a new adapter
+func NewArticleServiceToImporterArticleService(s article.Service) importer.ArticleService {
+	return &ArticleServiceToImporterArticleService{
+		service: s,
+	}
+}
+
+func (a *ArticleServiceToImporterArticleService) ImportArticles(userID uuid.UUID, items []*importer.Item) ([]*importer.ImportedArticle, error) {
+	// Convert importer.Item to article.ImportedArticle
+	articles := make([]*article.ImportedArticle, len(items))
+	for i, item := range items {
+		articles[i] = &article.ImportedArticle{
+			URL:     item.URL,
+			Title:   item.Title,
+			Tags:    item.Tags,
+			AddedAt: item.AddedAt,
+		}
+	}
+
+	created, err := a.service.ImportArticles(userID, articles)
+	if err != nil {
+		return nil, err
+	}
+
+	// Results are parallel to the items, nil where nothing was created
+	imported := make([]*importer.ImportedArticle, 0, len(created))
+	for i, articleEntity := range created {
+		if articleEntity == nil {
+			continue
+		}
+		imported = append(imported, &importer.ImportedArticle{
+			ArticleID: articleEntity.ID,
+			Item:      items[i],
+		})
+	}
+
+	return imported, nil
+}
+
+// ArticleServiceToFeedArticleService adapts article.Service to feed.ArticleService
+type ArticleServiceToFeedArticleService struct {
+	service article.Service
+}
+
+// NewArticleServiceToFeedArticleService creates a new adapter
+func NewArticleServiceToFeedArticleService(s article.Service) feed.ArticleService {
+	return &ArticleServiceToFeedArticleService{
+		service: s,
+	}
+}
+
+func (a *ArticleServiceToFeedArticleService) CreateFromEntries(userID uuid.UUID, entries []*feed.Entry) (int, error) {
+	// Entries are saved as imports, so metadata is extracted in the background
+	// and links already in the library are skipped
+	articles := make([]*article.ImportedArticle, len(entries))
+	for i, entry := range entries {
+		articles[i] = &article.ImportedArticle{
+			URL:   entry.URL,
+			Title: entry.Title,
+		}
+	}
+
+	created, err := a.service.ImportArticles(userID, articles)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, articleEntity := range created {
+		if articleEntity != nil {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// feedbackTimeout bounds crediting a recommendation while a favorite is saved
+const feedbackTimeout = 2 * time.Second
+
+// RecommendationServiceToFavoritesListener adapts recommendation.Service to article.FavoritesListener
+type RecommendationServiceToFavoritesListener struct {
+	service recommendation.Service
+}
+
+// NewRecommendationServiceToFavoritesListener creates a new adapter
+func NewRecommendationServiceToFavoritesListener(s recommendation.Service) article.FavoritesListener {
+	return &RecommendationServiceToFavoritesListener{
+		service: s,
+	}
+}
+
+// FavoriteChanged updates the user's profile, and credits the recommendation
+// a new favorite came from like a liked rating
+func (a *RecommendationServiceToFavoritesListener) FavoriteChanged(userID, articleID uuid.UUID, favorite bool) {
+	a.service.InvalidateRecommendations(userID)
+	a.service.RefreshProfile(userID)
+
+	if favorite {
+		ctx, cancel := context.WithTimeout(context.Background(), feedbackTimeout)
+		defer cancel()
+		_ = a.service.RecordFeedback(ctx, userID, articleID)
+	}
+}
+
+// RecommendationServiceToProfilePrimer adapts recommendation.Service to importer.ProfilePrimer
+type RecommendationServiceToProfilePrimer struct {
+	service recommendation.Service
+}
+
+// NewRecommendationServiceToProfilePrimer creates a new adapter
+func NewRecommendationServiceToProfilePrimer(s recommendation.Service) importer.ProfilePrimer {
+	return &RecommendationServiceToProfilePrimer{
+		service: s,
+	}
+}
+
+func (a *RecommendationServiceToProfilePrimer) PrimeProfile(ctx context.Context, userID uuid.UUID, articles []*importer.ImportedArticle) error {
+	// Convert importer.ImportedArticle to recommendation.ProfileSeed
+	seeds := make([]recommendation.ProfileSeed, len(articles))
+	for i, imported := range articles {
+		seeds[i] = recommendation.ProfileSeed{
+			ArticleID: imported.ArticleID,
+			Favorite:  imported.Item.Favorite,
+			Read:      imported.Item.Read,
+		}
+	}
+
+	_, err := a.service.PrimeProfile(ctx, userID, seeds)
+	return err
+}
+
+// PriorityQueueToExtractionQueue adapts worker.PriorityQueue to article.ExtractionQueue
+type PriorityQueueToExtractionQueue struct {
+	queue *worker.PriorityQueue
+}
+
+// NewPriorityQueueToExtractionQueue creates a new adapter
+func NewPriorityQueueToExtractionQueue(q *worker.PriorityQueue) article.ExtractionQueue {
+	return &PriorityQueueToExtractionQueue{
+		queue: q,
+	}
+}
+
+// extractionPriorities maps article extraction priorities to queue classes
+var extractionPriorities = map[article.ExtractionPriority]worker.Priority{
+	article.ExtractionPriorityInteractive: worker.PriorityInteractive,
+	article.ExtractionPriorityImport:      worker.PriorityImport,
+	article.ExtractionPriorityRetry:       worker.PriorityRetry,
+}
+
+func (a *PriorityQueueToExtractionQueue) Enqueue(priority article.ExtractionPriority, articleID uuid.UUID, task func() error) bool {
+	queuePriority, ok := extractionPriorities[priority]
+	if !ok {
+		queuePriority = worker.PriorityRetry
+	}
+
+	// Keyed by article so an article is not queued or extracted twice at once
+	return a.queue.Enqueue(queuePriority, extractionKey(articleID), task)
+}
+
+func (a *PriorityQueueToExtractionQueue) Run(articleID uuid.UUID, task func() error) error {
+	return a.queue.Run(extractionKey(articleID), task)
+}
+
+// extractionKey deduplicates queued extractions of an article
+func extractionKey(articleID uuid.UUID) string {
+	return "article:" + articleID.String()
+}
+
+// ContentStorageToRecommendationArticleRepository adapts a recommendation.ArticleRepository
+// to article content kept in object storage, so articles found by ID come with their content
+type ContentStorageToRecommendationArticleRepository struct {
+	recommendation.ArticleRepository
+	store storage.Storage
+}
+
+// NewContentStorageToRecommendationArticleRepository creates a new adapter
+func NewContentStorageToRecommendationArticleRepository(repo recommendation.ArticleRepository, store storage.Storage) recommendation.ArticleRepository {
+	return &ContentStorageToRecommendationArticleRepository{
+		ArticleRepository: repo,
+		store:             store,
+	}
+}
+
+func (a *ContentStorageToRecommendationArticleRepository) WithContext(ctx context.Context) recommendation.ArticleRepository {
+	return NewContentStorageToRecommendationArticleRepository(a.ArticleRepository.WithContext(ctx), a.store)
+}
+
+// FindByID loads the article's content; without it, content embeddings fall back to the title
+func (a *ContentStorageToRecommendationArticleRepository) FindByID(id uuid.UUID) (*recommendation.Article, error) {
+	articleEntity, err := a.ArticleRepository.FindByID(id)
+	if err != nil || articleEntity.ContentKey == "" {
+		return articleEntity, err
+	}
+
+	if object, err := a.store.Get(articleEntity.ContentKey); err == nil {
+		articleEntity.Content = string(object.Data)
+	}
+	return articleEntity, nil
+}
+
+// UserServiceToOAuthAccounts adapts user.Service to oauth.Accounts
+type UserServiceToOAuthAccounts struct {
+	service user.Service
+}
+
+// NewUserServiceToOAuthAccounts creates a new adapter
+func NewUserServiceToOAuthAccounts(s user.Service) oauth.Accounts {
+	return &UserServiceToOAuthAccounts{
+		service: s,
+	}
+}
+
+func (a *UserServiceToOAuthAccounts) FindOrCreateByEmail(email string) (uuid.UUID, bool, error) {
+	userEntity, created, err := a.service.FindOrCreateByEmail(email)
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+	return userEntity.ID, created, nil
+}
+
+func (a *UserServiceToOAuthAccounts) StartSession(userID uuid.UUID, client oauth.Client) (*oauth.Session, error) {
+	response, err := a.service.StartSession(userID, user.Client{UserAgent: client.UserAgent, IPAddress: client.IPAddress})
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth.Session{
+		Token:        response.Token,
+		RefreshToken: response.RefreshToken,
+		ExpiresAt:    response.ExpiresAt,
+	}, nil
+}
+
+// AuditServiceToUserAuditRecorder adapts audit.Service to user.AuditRecorder
+type AuditServiceToUserAuditRecorder struct {
+	service audit.Service
+}
+
+// NewAuditServiceToUserAuditRecorder creates a new adapter
+func NewAuditServiceToUserAuditRecorder(s audit.Service) user.AuditRecorder {
+	return &AuditServiceToUserAuditRecorder{
+		service: s,
+	}
+}
+
+func (a *AuditServiceToUserAuditRecorder) Record(event *user.AuditEvent) {
+	// Nil IDs mean the user or actor is unknown or absent
+	optional := func(id uuid.UUID) *uuid.UUID {
+		if id == uuid.Nil {
+			return nil
+		}
+		return &id
+	}
+
+	a.service.Record(&audit.Event{
+		Type:      event.Type,
+		UserID:    optional(event.UserID),
+		ActorID:   optional(event.ActorID),
+		Email:     event.Email,
+		IPAddress: event.Client.IPAddress,
+		UserAgent: event.Client.UserAgent,
+		Details:   event.Details,
+	})
+}
@@ -1,11 +1,16 @@
 package adapter
 
 import (
+	"context"
 	"errors"
 	"testing"
 
+	"github.com/dustin/articles-backend/config"
 	"github.com/dustin/articles-backend/internal/article"
 	"github.com/dustin/articles-backend/internal/classifier"
+	"github.com/dustin/articles-backend/internal/worker"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/dustin/articles-backend/pkg/storage"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -17,7 +22,7 @@ type mockClassifier struct {
 	err    error
 }
 
-func (m *mockClassifier) Classify(url, html string) (*classifier.Result, error) {
+func (m *mockClassifier) Classify(ctx context.Context, url, html string) (*classifier.Result, error) {
 	return m.result, m.err
 }
 
@@ -34,23 +39,33 @@ func TestClassifierToMetadataExtractor_Extract_Success(t *testing.T) {
 		Title:       "Test Article",
 		Description: "Test Description",
 		Content:     "Test Content",
+		HTML:        "<p>Test Content</p>",
 		Image:       "https://example.com/image.jpg",
+		SiteName:    "Example News",
+		FaviconURL:  "https://example.com/favicon.ico",
 		WordCount:   500,
+		ReadingTime: 3,
+		Language:    "de",
 		Confidence:  0.85,
 	}
 
 	mock := &mockClassifier{result: mockResult}
 	adapter := NewClassifierToMetadataExtractor(mock)
 
-	result, err := adapter.Extract("https://example.com/article")
+	result, err := adapter.Extract(context.Background(), "https://example.com/article")
 	require.NoError(t, err)
 	assert.NotNil(t, result)
 
 	assert.Equal(t, "Test Article", result.Title)
 	assert.Equal(t, "Test Description", result.Description)
 	assert.Equal(t, "Test Content", result.Content)
+	assert.Equal(t, "<p>Test Content</p>", result.HTML)
 	assert.Equal(t, "https://example.com/image.jpg", result.ImageURL)
+	assert.Equal(t, "Example News", result.SiteName)
+	assert.Equal(t, "https://example.com/favicon.ico", result.FaviconURL)
 	assert.Equal(t, 500, result.WordCount)
+	assert.Equal(t, 3, result.ReadingTime)
+	assert.Equal(t, "de", result.Language)
 	assert.Equal(t, 0.85, result.Confidence)
 }
 
@@ -58,7 +73,7 @@ func TestClassifierToMetadataExtractor_Extract_Error(t *testing.T) {
 	mock := &mockClassifier{err: errors.New("classification failed")}
 	adapter := NewClassifierToMetadataExtractor(mock)
 
-	result, err := adapter.Extract("https://example.com/article")
+	result, err := adapter.Extract(context.Background(), "https://example.com/article")
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "classification failed")
@@ -76,7 +91,7 @@ func TestClassifierToMetadataExtractor_Extract_EmptyHTML(t *testing.T) {
 	mock := &mockClassifier{result: mockResult}
 	adapter := &ClassifierToMetadataExtractor{classifier: mock}
 
-	result, err := adapter.Extract("https://example.com/test")
+	result, err := adapter.Extract(context.Background(), "https://example.com/test")
 	require.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, "Test", result.Title)
@@ -88,7 +103,7 @@ type mockArticleService struct {
 	err     error
 }
 
-func (m *mockArticleService) CreateArticle(userID uuid.UUID, url string) (*article.Article, error) {
+func (m *mockArticleService) CreateArticle(ctx context.Context, userID uuid.UUID, url string) (*article.Article, error) {
 	return m.article, m.err
 }
 
@@ -96,25 +111,120 @@ func (m *mockArticleService) GetArticle(id, userID uuid.UUID) (*article.Article,
 	return m.article, m.err
 }
 
-func (m *mockArticleService) GetUserArticles(userID uuid.UUID, page, limit int) ([]*article.Article, int64, error) {
+func (m *mockArticleService) GetUserArticles(userID uuid.UUID, filter *article.ArticleFilter, page, limit int) ([]*article.Article, int64, error) {
 	if m.err != nil {
 		return nil, 0, m.err
 	}
 	return []*article.Article{m.article}, 1, nil
 }
 
+func (m *mockArticleService) GetUserArticlesAfter(userID uuid.UUID, filter *article.ArticleFilter, cursor string, limit int) ([]*article.Article, string, error) {
+	if m.err != nil {
+		return nil, "", m.err
+	}
+	return []*article.Article{m.article}, "", nil
+}
+
+func (m *mockArticleService) GetStats(userID uuid.UUID) (*article.ArticleStatsResponse, error) {
+	return nil, m.err
+}
+
 func (m *mockArticleService) DeleteArticle(id, userID uuid.UUID) error {
 	return m.err
 }
 
-func (m *mockArticleService) UpdateMetadata(id uuid.UUID, title, description, content string, wordCount int, confidence float64) error {
+func (m *mockArticleService) MarkRead(id, userID uuid.UUID, read bool) (*article.Article, error) {
+	return nil, m.err
+}
+
+func (m *mockArticleService) SetFavorite(id, userID uuid.UUID, favorite bool) (*article.Article, error) {
+	return nil, m.err
+}
+
+func (m *mockArticleService) ValidateURLs(userID uuid.UUID, req *article.ValidateURLsRequest) (*article.ValidateURLsResponse, error) {
+	return nil, m.err
+}
+
+func (m *mockArticleService) DeleteArticles(userID uuid.UUID, req *article.BatchDeleteRequest) (*article.BatchDeleteResponse, error) {
+	return nil, m.err
+}
+
+func (m *mockArticleService) GetTrash(userID uuid.UUID, page, limit int) ([]*article.Article, int64, error) {
+	return nil, 0, m.err
+}
+
+func (m *mockArticleService) RestoreArticle(id, userID uuid.UUID) (*article.Article, error) {
+	return nil, m.err
+}
+
+func (m *mockArticleService) UpdateArticleFields(id, userID uuid.UUID, req *article.UpdateArticleRequest) (*article.Article, error) {
+	return m.article, m.err
+}
+
+func (m *mockArticleService) SearchArticles(userID uuid.UUID, query string, page, limit int) ([]*article.SearchResult, int64, error) {
+	return nil, 0, m.err
+}
+
+func (m *mockArticleService) ExportArticles(userID uuid.UUID, fn func(article *article.Article) error) error {
 	return m.err
 }
 
+func (m *mockArticleService) AddTags(id, userID uuid.UUID, names []string) (*article.Article, error) {
+	return m.article, m.err
+}
+
+func (m *mockArticleService) RemoveTag(id, userID uuid.UUID, name string) error {
+	return m.err
+}
+
+func (m *mockArticleService) GetUserTags(userID uuid.UUID) ([]*article.Tag, error) {
+	return nil, m.err
+}
+
+func (m *mockArticleService) GetHighlights(id uuid.UUID, userID uuid.UUID) ([]*article.Highlight, error) {
+	return nil, m.err
+}
+
+func (m *mockArticleService) CreateHighlight(id uuid.UUID, userID uuid.UUID, req *article.CreateHighlightRequest) (*article.Highlight, error) {
+	return nil, m.err
+}
+
+func (m *mockArticleService) UpdateHighlight(id, highlightID uuid.UUID, userID uuid.UUID, req *article.UpdateHighlightRequest) (*article.Highlight, error) {
+	return nil, m.err
+}
+
+func (m *mockArticleService) DeleteHighlight(id, highlightID uuid.UUID, userID uuid.UUID) error {
+	return m.err
+}
+
+func (m *mockArticleService) UpdateMetadata(id uuid.UUID, title, description, content, language, siteName, faviconURL string, wordCount, readingTime int, confidence float64) error {
+	return m.err
+}
+
+func (m *mockArticleService) ImportArticles(userID uuid.UUID, items []*article.ImportedArticle) ([]*article.Article, error) {
+	return nil, m.err
+}
+
+func (m *mockArticleService) RefreshMetadata(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*article.Article, error) {
+	return nil, m.err
+}
+
+func (m *mockArticleService) GetContent(id uuid.UUID, userID uuid.UUID) (*storage.Object, error) {
+	return nil, m.err
+}
+
 func (m *mockArticleService) RetryFailedMetadata() error {
 	return m.err
 }
 
+func (m *mockArticleService) RemoveArticle(id uuid.UUID) (*article.Article, error) {
+	return nil, m.err
+}
+
+func (m *mockArticleService) PurgeTrash() error {
+	return m.err
+}
+
 func (m *mockArticleService) ExtractMetadata(articleID uuid.UUID) error {
 	return m.err
 }
@@ -144,7 +254,7 @@ func TestArticleServiceToRatingArticleService_GetArticle_Success(t *testing.T) {
 }
 
 func TestArticleServiceToRatingArticleService_GetArticle_Error(t *testing.T) {
-	mockService := &mockArticleService{err: errors.New("article not found")}
+	mockService := &mockArticleService{err: article.ErrNotFound}
 	adapter := NewArticleServiceToRatingArticleService(mockService)
 
 	articleID := uuid.New()
@@ -156,6 +266,31 @@ func TestArticleServiceToRatingArticleService_GetArticle_Error(t *testing.T) {
 	assert.Contains(t, err.Error(), "article not found")
 }
 
+func TestArticleServiceToCollectionArticleService_GetArticle(t *testing.T) {
+	mockArticle := &article.Article{
+		ID:             uuid.New(),
+		UserID:         uuid.New(),
+		Title:          "Test Article",
+		URL:            "https://example.com/article",
+		ReadingTime:    4,
+		MetadataStatus: article.MetadataStatusSuccess,
+	}
+
+	adapter := NewArticleServiceToCollectionArticleService(&mockArticleService{article: mockArticle})
+
+	result, err := adapter.GetArticle(mockArticle.ID, mockArticle.UserID)
+	require.NoError(t, err)
+	assert.Equal(t, mockArticle.ID, result.ID)
+	assert.Equal(t, mockArticle.UserID, result.UserID)
+	assert.Equal(t, "Test Article", result.Title)
+	assert.Equal(t, 4, result.ReadingTime)
+	assert.Equal(t, article.MetadataStatusSuccess, result.MetadataStatus)
+
+	adapter = NewArticleServiceToCollectionArticleService(&mockArticleService{err: article.ErrNotFound})
+	_, err = adapter.GetArticle(uuid.New(), uuid.New())
+	assert.EqualError(t, err, "article not found")
+}
+
 func TestArticleServiceToRatingArticleService_GetArticle_Mapping(t *testing.T) {
 	articleID := uuid.New()
 	userID := uuid.New()
@@ -208,7 +343,7 @@ func TestClassifierToMetadataExtractor_ExtractedMetadata_AllFields(t *testing.T)
 	mock := &mockClassifier{result: mockResult}
 	adapter := NewClassifierToMetadataExtractor(mock)
 
-	result, err := adapter.Extract("https://example.com/complete")
+	result, err := adapter.Extract(context.Background(), "https://example.com/complete")
 	require.NoError(t, err)
 	assert.NotNil(t, result)
 
@@ -232,7 +367,7 @@ func TestClassifierToMetadataExtractor_ExtractedMetadata_MinimalFields(t *testin
 	mock := &mockClassifier{result: mockResult}
 	adapter := NewClassifierToMetadataExtractor(mock)
 
-	result, err := adapter.Extract("https://example.com/minimal")
+	result, err := adapter.Extract(context.Background(), "https://example.com/minimal")
 	require.NoError(t, err)
 	assert.NotNil(t, result)
 
@@ -243,3 +378,30 @@ func TestClassifierToMetadataExtractor_ExtractedMetadata_MinimalFields(t *testin
 	assert.Equal(t, 1, result.WordCount)
 	assert.Equal(t, 0.1, result.Confidence)
 }
+
+func TestPriorityQueueToExtractionQueue_Enqueue(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+	queue, err := worker.NewPriorityQueue(nil, "test-extraction", log)
+	require.NoError(t, err)
+
+	adapter := NewPriorityQueueToExtractionQueue(queue)
+	articleID := uuid.New()
+	noop := func() error { return nil }
+
+	assert.True(t, adapter.Enqueue(article.ExtractionPriorityImport, articleID, noop))
+	assert.False(t, adapter.Enqueue(article.ExtractionPriorityInteractive, articleID, noop), "an article waits in the queue only once")
+	assert.True(t, adapter.Enqueue(article.ExtractionPriorityRetry, uuid.New(), noop))
+
+	stats := queue.Stats()
+	assert.Equal(t, 1, stats["import"].Depth)
+	assert.Equal(t, 1, stats["retry"].Depth)
+	assert.Equal(t, 0, stats["interactive"].Depth)
+	assert.Equal(t, int64(1), stats["interactive"].Deduplicated)
+
+	// Extracting while the user waits replaces the queued extraction
+	ran := false
+	require.NoError(t, adapter.Run(articleID, func() error { ran = true; return nil }))
+	assert.True(t, ran)
+	assert.Equal(t, 0, queue.Stats()["import"].Depth)
+}
@@ -0,0 +1,181 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// ErrWorkerNotFound is returned for workers that do not exist
+var ErrWorkerNotFound = utils.NewNotFoundError("worker not found")
+
+// Supported list formats
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// UserFilter narrows the user listing; zero values match everything
+type UserFilter struct {
+	Email         string // Case-insensitive substring
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+}
+
+// ArticleFilter narrows the article listing; zero values match everything
+type ArticleFilter struct {
+	UserID         *uuid.UUID
+	MetadataStatus string
+	Query          string // Case-insensitive substring of title or URL
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+}
+
+// FailureFilter narrows the search for processing failures; zero values match everything
+type FailureFilter struct {
+	Domain       string // Host of the article URL without "www."; subdomains match too
+	Category     string // Error category, e.g. timeout or http_4xx
+	FailedAfter  *time.Time
+	FailedBefore *time.Time
+}
+
+// UserRow is a user as shown to admins
+type UserRow struct {
+	ID           uuid.UUID `json:"id"`
+	Email        string    `json:"email"`
+	Role         string    `json:"role"`
+	ArticleCount int64     `json:"article_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ArticleRow is an article as shown to admins, without its content
+type ArticleRow struct {
+	ID              uuid.UUID `json:"id"`
+	UserID          uuid.UUID `json:"user_id"`
+	URL             string    `json:"url"`
+	Title           string    `json:"title"`
+	MetadataStatus  string    `json:"metadata_status"`
+	EmbeddingStatus string    `json:"embedding_status"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// FailureGroup counts the articles whose metadata extraction last failed on
+// the same domain with the same kind of error
+type FailureGroup struct {
+	Domain            string      `json:"domain"`
+	Category          string      `json:"category"`
+	Count             int64       `json:"count"`
+	LastFailedAt      time.Time   `json:"last_failed_at"`
+	LastError         string      `json:"last_error"`          // Message of the most recent failure
+	LastRequestID     string      `json:"last_request_id"`     // Request ID of the most recent failure, for tracing it in the logs
+	ExampleArticleIDs []uuid.UUID `json:"example_article_ids"` // Most recent failures first
+}
+
+// WorkerStatus is a background worker as shown to admins
+type WorkerStatus struct {
+	Name      string     `json:"name"`
+	Schedule  string     `json:"schedule"` // Cron expression
+	Running   bool       `json:"running"`  // False while paused
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	LastError string     `json:"last_error,omitempty"` // Of the last run, if it failed
+}
+
+// Repository lists rows newest first, starting after the cursor when one is given
+type Repository interface {
+	ListUsers(filter *UserFilter, after *utils.Cursor, limit int) ([]*UserRow, error)
+	ListArticles(filter *ArticleFilter, after *utils.Cursor, limit int) ([]*ArticleRow, error)
+	// SearchFailures groups failing articles by domain and error category,
+	// largest groups first, with up to examples article IDs each. It also
+	// returns how many articles matched across all groups.
+	SearchFailures(filter *FailureFilter, examples, limit int) ([]*FailureGroup, int64, error)
+}
+
+// ArticleModerator removes articles of any user (dependency inversion)
+type ArticleModerator interface {
+	// RemoveArticle permanently deletes the article, trashed or not
+	RemoveArticle(id uuid.UUID) (*ArticleRow, error)
+}
+
+// Worker is a scheduled background job admins can pause, resume and run
+type Worker interface {
+	Name() string
+	Schedule() string
+	IsRunning() bool
+	Start() error
+	Stop() error
+	// Trigger runs the job once in the background, failing while it runs
+	Trigger() error
+	// LastRun returns when the job last finished and its error message
+	LastRun() (time.Time, string)
+}
+
+// Service defines the interface for admin listings, moderation and worker
+// control
+type Service interface {
+	ListUsers(filter *UserFilter, cursor string, limit int) (*UserListResponse, error)
+	ListArticles(filter *ArticleFilter, cursor string, limit int) (*ArticleListResponse, error)
+	ExportUsers(filter *UserFilter, w RowWriter) error
+	ExportArticles(filter *ArticleFilter, w RowWriter) error
+	SearchFailures(filter *FailureFilter, limit int) (*FailureSearchResponse, error)
+	RemoveArticle(adminID, articleID uuid.UUID) (*ArticleRow, error)
+
+	ListWorkers() []*WorkerStatus
+	PauseWorker(name string) (*WorkerStatus, error)
+	ResumeWorker(name string) (*WorkerStatus, error)
+	// RunWorker starts a run of the worker now, whether or not it is paused
+	RunWorker(name string) (*WorkerStatus, error)
+}
+
+// RowWriter receives exported rows; the first row is the header
+type RowWriter interface {
+	Write(record []string) error
+}
+
+// UserListResponse is a page of users
+type UserListResponse struct {
+	Users      []*UserRow       `json:"users"`
+	Pagination utils.CursorMeta `json:"pagination"`
+}
+
+// ArticleListResponse is a page of articles
+type ArticleListResponse struct {
+	Articles   []*ArticleRow    `json:"articles"`
+	Pagination utils.CursorMeta `json:"pagination"`
+}
+
+// FailureSearchResponse lists groups of processing failures
+type FailureSearchResponse struct {
+	Groups []*FailureGroup `json:"groups"`
+	Total  int64           `json:"total"` // Failing articles across all groups, including ones past the limit
+}
+
+// Column headers of the CSV exports
+var (
+	UserCSVHeader    = []string{"id", "email", "role", "article_count", "created_at"}
+	ArticleCSVHeader = []string{"id", "user_id", "url", "title", "metadata_status", "embedding_status", "created_at"}
+)
+
+// CSVRecord returns the user as a row matching UserCSVHeader
+func (u *UserRow) CSVRecord() []string {
+	return []string{
+		u.ID.String(),
+		u.Email,
+		u.Role,
+		utils.IntToString(int(u.ArticleCount)),
+		u.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// CSVRecord returns the article as a row matching ArticleCSVHeader
+func (a *ArticleRow) CSVRecord() []string {
+	return []string{
+		a.ID.String(),
+		a.UserID.String(),
+		a.URL,
+		a.Title,
+		a.MetadataStatus,
+		a.EmbeddingStatus,
+		a.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,276 @@
+package admin
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T, repo Repository) Service {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+	return NewService(repo, nil, nil, log)
+}
+
+func TestListUsers(t *testing.T) {
+	repo := newMockRepository(5)
+	svc := newTestService(t, repo)
+
+	t.Run("Walks pages with cursors", func(t *testing.T) {
+		first, err := svc.ListUsers(&UserFilter{}, "", 2)
+		require.NoError(t, err)
+		require.Len(t, first.Users, 2)
+		assert.True(t, first.Pagination.HasMore)
+		assert.Equal(t, repo.users[0].ID, first.Users[0].ID)
+
+		second, err := svc.ListUsers(&UserFilter{}, first.Pagination.NextCursor, 2)
+		require.NoError(t, err)
+		require.Len(t, second.Users, 2)
+		assert.Equal(t, repo.users[2].ID, second.Users[0].ID)
+
+		last, err := svc.ListUsers(&UserFilter{}, second.Pagination.NextCursor, 2)
+		require.NoError(t, err)
+		require.Len(t, last.Users, 1)
+		assert.False(t, last.Pagination.HasMore)
+		assert.Empty(t, last.Pagination.NextCursor)
+	})
+
+	t.Run("Clamps limit", func(t *testing.T) {
+		response, err := svc.ListUsers(&UserFilter{}, "", 1000)
+		require.NoError(t, err)
+		assert.Equal(t, maxListLimit, response.Pagination.Limit)
+
+		response, err = svc.ListUsers(&UserFilter{}, "", 0)
+		require.NoError(t, err)
+		assert.Equal(t, defaultListLimit, response.Pagination.Limit)
+	})
+
+	t.Run("Rejects invalid cursor", func(t *testing.T) {
+		_, err := svc.ListUsers(&UserFilter{}, "garbage", 10)
+		assert.ErrorIs(t, err, utils.ErrInvalidCursor)
+	})
+}
+
+func TestExportUsers(t *testing.T) {
+	repo := newMockRepository(exportBatchSize + 3)
+	svc := newTestService(t, repo)
+
+	w := &recordingWriter{}
+	require.NoError(t, svc.ExportUsers(&UserFilter{}, w))
+
+	require.Len(t, w.records, exportBatchSize+4)
+	assert.Equal(t, UserCSVHeader, w.records[0])
+	assert.Equal(t, repo.users[0].ID.String(), w.records[1][0])
+	assert.Equal(t, repo.users[exportBatchSize+2].ID.String(), w.records[exportBatchSize+3][0])
+}
+
+func TestSearchFailures(t *testing.T) {
+	repo := newMockRepository(0)
+	svc := newTestService(t, repo)
+
+	t.Run("Normalizes the filter", func(t *testing.T) {
+		response, err := svc.SearchFailures(&FailureFilter{Domain: " WWW.Example.com. ", Category: "Timeout"}, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "example.com", repo.failureFilter.Domain)
+		assert.Equal(t, "timeout", repo.failureFilter.Category)
+		assert.Equal(t, failureExamples, repo.examples)
+		assert.Equal(t, defaultListLimit, repo.limit)
+		assert.NotNil(t, response.Groups, "empty groups are listed as []")
+	})
+
+	t.Run("Returns groups with the total", func(t *testing.T) {
+		repo.failures = []*FailureGroup{{Domain: "example.com", Category: "http_4xx", Count: 3}}
+		repo.failureTotal = 7
+
+		response, err := svc.SearchFailures(&FailureFilter{}, 1000)
+		require.NoError(t, err)
+		assert.Equal(t, maxListLimit, repo.limit)
+		assert.Equal(t, repo.failures, response.Groups)
+		assert.Equal(t, int64(7), response.Total)
+	})
+}
+
+func TestWorkers(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+	purge := &mockWorker{name: "trash-purge", running: true}
+	flush := &mockWorker{name: "usage-flush", running: true}
+	svc := NewService(newMockRepository(0), nil, []Worker{purge, flush}, log)
+
+	workers := svc.ListWorkers()
+	require.Len(t, workers, 2)
+	assert.Equal(t, "trash-purge", workers[0].Name)
+	assert.Nil(t, workers[0].LastRunAt)
+
+	status, err := svc.PauseWorker("usage-flush")
+	require.NoError(t, err)
+	assert.False(t, status.Running)
+	status, err = svc.PauseWorker("usage-flush")
+	require.NoError(t, err, "pausing twice is harmless")
+	assert.False(t, status.Running)
+
+	status, err = svc.ResumeWorker("usage-flush")
+	require.NoError(t, err)
+	assert.True(t, status.Running)
+
+	status, err = svc.RunWorker("trash-purge")
+	require.NoError(t, err)
+	require.NotNil(t, status.LastRunAt)
+	assert.Equal(t, "disk full", status.LastError)
+	assert.Equal(t, 1, purge.triggered)
+
+	_, err = svc.RunWorker("missing")
+	assert.ErrorIs(t, err, ErrWorkerNotFound)
+}
+
+func TestRemoveArticle(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+	moderator := &mockModerator{removed: make(map[uuid.UUID]bool)}
+	svc := NewService(newMockRepository(0), moderator, nil, log)
+
+	articleID := uuid.New()
+	article, err := svc.RemoveArticle(uuid.New(), articleID)
+	require.NoError(t, err)
+	assert.Equal(t, articleID, article.ID)
+	assert.True(t, moderator.removed[articleID])
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	limiter, err := NewRateLimiter(&config.AdminConfig{})
+	require.NoError(t, err)
+	assert.NotNil(t, limiter)
+
+	_, err = NewRateLimiter(&config.AdminConfig{RateLimit: "0"})
+	assert.Error(t, err)
+
+	_, err = NewRateLimiter(&config.AdminConfig{RateWindow: "soon"})
+	assert.Error(t, err)
+}
+
+// mockRepository serves users newest first; several share a creation time to exercise tie-breaking
+type mockRepository struct {
+	users []*UserRow
+
+	failures      []*FailureGroup
+	failureTotal  int64
+	failureFilter FailureFilter
+	examples      int
+	limit         int
+}
+
+func newMockRepository(count int) *mockRepository {
+	base := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	repo := &mockRepository{}
+	for i := 0; i < count; i++ {
+		repo.users = append(repo.users, &UserRow{
+			ID:        uuid.New(),
+			Email:     "user@example.com",
+			CreatedAt: base.Add(-time.Duration(i/2) * time.Hour),
+		})
+	}
+
+	sort.Slice(repo.users, func(i, j int) bool {
+		return cursorLess(cursorOf(repo.users[j]), cursorOf(repo.users[i]))
+	})
+	return repo
+}
+
+func (m *mockRepository) ListUsers(filter *UserFilter, after *utils.Cursor, limit int) ([]*UserRow, error) {
+	var users []*UserRow
+	for _, user := range m.users {
+		if after != nil && !cursorLess(cursorOf(user), *after) {
+			continue
+		}
+		users = append(users, user)
+		if len(users) == limit {
+			break
+		}
+	}
+	return users, nil
+}
+
+func (m *mockRepository) ListArticles(filter *ArticleFilter, after *utils.Cursor, limit int) ([]*ArticleRow, error) {
+	return nil, nil
+}
+
+func (m *mockRepository) SearchFailures(filter *FailureFilter, examples, limit int) ([]*FailureGroup, int64, error) {
+	m.failureFilter, m.examples, m.limit = *filter, examples, limit
+	return m.failures, m.failureTotal, nil
+}
+
+func cursorOf(user *UserRow) utils.Cursor {
+	return utils.Cursor{CreatedAt: user.CreatedAt, ID: user.ID}
+}
+
+// cursorLess mirrors the (created_at, id) < (?, ?) row comparison
+func cursorLess(a, b utils.Cursor) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID.String() < b.ID.String()
+}
+
+type recordingWriter struct {
+	records [][]string
+}
+
+func (w *recordingWriter) Write(record []string) error {
+	w.records = append(w.records, record)
+	return nil
+}
+
+// mockWorker records control calls; runs finish at once with an error
+type mockWorker struct {
+	name      string
+	running   bool
+	triggered int
+	lastRunAt time.Time
+}
+
+func (w *mockWorker) Name() string     { return w.name }
+func (w *mockWorker) Schedule() string { return "0 3 * * *" }
+func (w *mockWorker) IsRunning() bool  { return w.running }
+
+func (w *mockWorker) Start() error {
+	w.running = true
+	return nil
+}
+
+func (w *mockWorker) Stop() error {
+	w.running = false
+	return nil
+}
+
+func (w *mockWorker) Trigger() error {
+	w.triggered++
+	w.lastRunAt = time.Now()
+	return nil
+}
+
+func (w *mockWorker) LastRun() (time.Time, string) {
+	if w.lastRunAt.IsZero() {
+		return w.lastRunAt, ""
+	}
+	return w.lastRunAt, "disk full"
+}
+
+// mockModerator removes any article once
+type mockModerator struct {
+	removed map[uuid.UUID]bool
+}
+
+func (m *mockModerator) RemoveArticle(id uuid.UUID) (*ArticleRow, error) {
+	if m.removed[id] {
+		return nil, utils.NewNotFoundError("article not found")
+	}
+	m.removed[id] = true
+	return &ArticleRow{ID: id, UserID: uuid.New(), URL: "https://example.com/spam"}, nil
+}
@@ -0,0 +1,229 @@
+package admin
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for admin listings, moderation and workers
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new admin handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// ListUsers handles listing users, as JSON pages or a full CSV export
+func (h *Handler) ListUsers(c *gin.Context) {
+	filter := &UserFilter{Email: c.Query("email")}
+	var err error
+	if filter.CreatedAfter, filter.CreatedBefore, err = parseCreatedRange(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch c.DefaultQuery("format", FormatJSON) {
+	case FormatCSV:
+		h.export(c, "users", func(w RowWriter) error {
+			return h.service.ExportUsers(filter, w)
+		})
+	case FormatJSON:
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		response, err := h.service.ListUsers(filter, c.Query("cursor"), limit)
+		if err != nil {
+			utils.RespondError(c, err, "Failed to list users")
+			return
+		}
+		c.JSON(http.StatusOK, response)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported format: " + c.Query("format")})
+	}
+}
+
+// ListArticles handles listing articles of all users, as JSON pages or a full CSV export
+func (h *Handler) ListArticles(c *gin.Context) {
+	filter := &ArticleFilter{
+		MetadataStatus: c.Query("status"),
+		Query:          c.Query("q"),
+	}
+	if param := c.Query("user_id"); param != "" {
+		userID, err := uuid.Parse(param)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		filter.UserID = &userID
+	}
+	var err error
+	if filter.CreatedAfter, filter.CreatedBefore, err = parseCreatedRange(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch c.DefaultQuery("format", FormatJSON) {
+	case FormatCSV:
+		h.export(c, "articles", func(w RowWriter) error {
+			return h.service.ExportArticles(filter, w)
+		})
+	case FormatJSON:
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		response, err := h.service.ListArticles(filter, c.Query("cursor"), limit)
+		if err != nil {
+			utils.RespondError(c, err, "Failed to list articles")
+			return
+		}
+		c.JSON(http.StatusOK, response)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported format: " + c.Query("format")})
+	}
+}
+
+// SearchFailures handles searching articles whose metadata extraction failed,
+// grouped by domain and error category
+func (h *Handler) SearchFailures(c *gin.Context) {
+	filter := &FailureFilter{
+		Domain:   c.Query("domain"),
+		Category: c.Query("category"),
+	}
+	var err error
+	if filter.FailedAfter, filter.FailedBefore, err = parseTimeRange(c, "failed_after", "failed_before"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	response, err := h.service.SearchFailures(filter, limit)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to search processing failures")
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RemoveArticle handles moderating an article of any user away for good
+func (h *Handler) RemoveArticle(c *gin.Context) {
+	articleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	adminID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	article, err := h.service.RemoveArticle(adminID, articleID)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to remove article")
+		return
+	}
+	c.JSON(http.StatusOK, article)
+}
+
+// ListWorkers handles listing the background workers with their state
+func (h *Handler) ListWorkers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"workers": h.service.ListWorkers()})
+}
+
+// PauseWorker handles stopping a worker's schedule until it is resumed
+func (h *Handler) PauseWorker(c *gin.Context) {
+	status, err := h.service.PauseWorker(c.Param("name"))
+	if err != nil {
+		utils.RespondError(c, err, "Failed to pause worker")
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// ResumeWorker handles putting a paused worker back on its schedule
+func (h *Handler) ResumeWorker(c *gin.Context) {
+	status, err := h.service.ResumeWorker(c.Param("name"))
+	if err != nil {
+		utils.RespondError(c, err, "Failed to resume worker")
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// RunWorker handles starting a run of a worker now
+func (h *Handler) RunWorker(c *gin.Context) {
+	status, err := h.service.RunWorker(c.Param("name"))
+	if err != nil {
+		utils.RespondError(c, err, "Failed to run worker")
+		return
+	}
+	c.JSON(http.StatusAccepted, status)
+}
+
+func (h *Handler) export(c *gin.Context, name string, exportFunc func(RowWriter) error) {
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename="+name+".csv")
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	err := exportFunc(writer)
+	writer.Flush()
+
+	if err != nil {
+		// Headers are already sent; abort the stream so clients see a truncated download
+		c.Error(err)
+		c.Abort()
+	}
+}
+
+// parseCreatedRange reads the optional RFC 3339 created_after and created_before parameters
+func parseCreatedRange(c *gin.Context) (after *time.Time, before *time.Time, err error) {
+	return parseTimeRange(c, "created_after", "created_before")
+}
+
+// parseTimeRange reads a pair of optional RFC 3339 parameters bounding a range
+func parseTimeRange(c *gin.Context, afterParam, beforeParam string) (after *time.Time, before *time.Time, err error) {
+	parse := func(name string) (*time.Time, error) {
+		param := c.Query(name)
+		if param == "" {
+			return nil, nil
+		}
+		parsed, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			return nil, utils.NewValidationError(name, "must be an RFC 3339 timestamp")
+		}
+		return &parsed, nil
+	}
+
+	if after, err = parse(afterParam); err != nil {
+		return nil, nil, err
+	}
+	if before, err = parse(beforeParam); err != nil {
+		return nil, nil, err
+	}
+	return after, before, nil
+}
+
+// RegisterRoutes registers admin listing, moderation and worker routes. rateLimit runs after the role
+// and scope checks so limits apply per authenticated admin.
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, rateLimit gin.HandlerFunc) {
+	admin := router.Group("/admin")
+	admin.Use(authMiddleware, utils.RequireRole(utils.RoleAdmin), utils.RequireScope(utils.ScopeAdmin), rateLimit)
+	{
+		admin.GET("/users", h.ListUsers)
+		admin.GET("/articles", h.ListArticles)
+		admin.GET("/failures", h.SearchFailures)
+		admin.DELETE("/articles/:id", h.RemoveArticle)
+		admin.GET("/workers", h.ListWorkers)
+		admin.POST("/workers/:name/pause", h.PauseWorker)
+		admin.POST("/workers/:name/resume", h.ResumeWorker)
+		admin.POST("/workers/:name/run", h.RunWorker)
+	}
+}
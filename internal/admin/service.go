@@ -0,0 +1,307 @@
+package admin
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+	// exportBatchSize is the number of rows fetched per query while exporting
+	exportBatchSize = 500
+	// failureExamples is the number of article IDs listed per failure group
+	failureExamples = 5
+)
+
+// service implements the Service interface
+type service struct {
+	repo        Repository
+	articles    ArticleModerator
+	workers     map[string]Worker
+	logger      *logger.Logger
+	auditLogger *logger.Logger
+}
+
+// NewService creates a new admin service controlling the workers
+func NewService(repo Repository, articles ArticleModerator, workers []Worker, log *logger.Logger) Service {
+	byName := make(map[string]Worker, len(workers))
+	for _, worker := range workers {
+		byName[worker.Name()] = worker
+	}
+
+	return &service{
+		repo:        repo,
+		articles:    articles,
+		workers:     byName,
+		logger:      log.WithComponent("admin-service"),
+		auditLogger: log.WithComponent("audit"),
+	}
+}
+
+// NewRateLimiter creates the limiter shared by all admin routes with validation and defaults
+func NewRateLimiter(cfg *config.AdminConfig) (*utils.RateLimiter, error) {
+	limit := 60
+	if cfg.RateLimit != "" {
+		parsed, err := strconv.Atoi(cfg.RateLimit)
+		if err != nil || parsed < 1 {
+			return nil, fmt.Errorf("invalid admin rate limit '%s': must be a positive integer", cfg.RateLimit)
+		}
+		limit = parsed
+	}
+
+	window := time.Minute
+	if cfg.RateWindow != "" {
+		parsed, err := time.ParseDuration(cfg.RateWindow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid admin rate window '%s': %v", cfg.RateWindow, err)
+		}
+		if parsed <= 0 {
+			return nil, fmt.Errorf("invalid admin rate window '%s': must be positive", cfg.RateWindow)
+		}
+		window = parsed
+	}
+
+	return utils.NewRateLimiter(limit, window), nil
+}
+
+func (s *service) ListUsers(filter *UserFilter, cursor string, limit int) (*UserListResponse, error) {
+	after, limit, err := parsePageRequest(cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	users, err := s.repo.ListUsers(filter, after, limit+1)
+	if err != nil {
+		s.logger.Error("Failed to list users: " + err.Error())
+		return nil, err
+	}
+
+	response := &UserListResponse{Users: users, Pagination: utils.CursorMeta{Limit: limit}}
+	if len(users) > limit {
+		response.Users = users[:limit]
+		last := response.Users[limit-1]
+		response.Pagination.HasMore = true
+		response.Pagination.NextCursor = utils.EncodeCursor(utils.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	return response, nil
+}
+
+func (s *service) ListArticles(filter *ArticleFilter, cursor string, limit int) (*ArticleListResponse, error) {
+	after, limit, err := parsePageRequest(cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	articles, err := s.repo.ListArticles(filter, after, limit+1)
+	if err != nil {
+		s.logger.Error("Failed to list articles: " + err.Error())
+		return nil, err
+	}
+
+	response := &ArticleListResponse{Articles: articles, Pagination: utils.CursorMeta{Limit: limit}}
+	if len(articles) > limit {
+		response.Articles = articles[:limit]
+		last := response.Articles[limit-1]
+		response.Pagination.HasMore = true
+		response.Pagination.NextCursor = utils.EncodeCursor(utils.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	return response, nil
+}
+
+func (s *service) ExportUsers(filter *UserFilter, w RowWriter) error {
+	if err := w.Write(UserCSVHeader); err != nil {
+		return err
+	}
+
+	// Walk the listing page by page so exports never hold every row in memory
+	var after *utils.Cursor
+	for {
+		users, err := s.repo.ListUsers(filter, after, exportBatchSize)
+		if err != nil {
+			s.logger.Error("Failed to export users: " + err.Error())
+			return err
+		}
+
+		for _, user := range users {
+			if err := w.Write(user.CSVRecord()); err != nil {
+				return err
+			}
+		}
+
+		if len(users) < exportBatchSize {
+			return nil
+		}
+		last := users[len(users)-1]
+		after = &utils.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+func (s *service) ExportArticles(filter *ArticleFilter, w RowWriter) error {
+	if err := w.Write(ArticleCSVHeader); err != nil {
+		return err
+	}
+
+	// Walk the listing page by page so exports never hold every row in memory
+	var after *utils.Cursor
+	for {
+		articles, err := s.repo.ListArticles(filter, after, exportBatchSize)
+		if err != nil {
+			s.logger.Error("Failed to export articles: " + err.Error())
+			return err
+		}
+
+		for _, article := range articles {
+			if err := w.Write(article.CSVRecord()); err != nil {
+				return err
+			}
+		}
+
+		if len(articles) < exportBatchSize {
+			return nil
+		}
+		last := articles[len(articles)-1]
+		after = &utils.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+func (s *service) SearchFailures(filter *FailureFilter, limit int) (*FailureSearchResponse, error) {
+	if limit < 1 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	normalized := *filter
+	normalized.Domain = strings.TrimPrefix(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(filter.Domain)), "."), "www.")
+	normalized.Category = strings.ToLower(strings.TrimSpace(filter.Category))
+
+	groups, total, err := s.repo.SearchFailures(&normalized, failureExamples, limit)
+	if err != nil {
+		s.logger.Error("Failed to search processing failures: " + err.Error())
+		return nil, err
+	}
+	if groups == nil {
+		groups = []*FailureGroup{}
+	}
+
+	return &FailureSearchResponse{Groups: groups, Total: total}, nil
+}
+
+// parsePageRequest decodes the cursor and clamps the page size
+func (s *service) RemoveArticle(adminID, articleID uuid.UUID) (*ArticleRow, error) {
+	article, err := s.articles.RemoveArticle(articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditLogger.Warn("Article " + articleID.String() + " (" + article.URL + ") of user " + article.UserID.String() + " removed by admin " + adminID.String())
+
+	return article, nil
+}
+
+func (s *service) ListWorkers() []*WorkerStatus {
+	statuses := make([]*WorkerStatus, 0, len(s.workers))
+	for _, worker := range s.workers {
+		statuses = append(statuses, workerStatus(worker))
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
+
+func (s *service) PauseWorker(name string) (*WorkerStatus, error) {
+	worker, ok := s.workers[name]
+	if !ok {
+		return nil, ErrWorkerNotFound
+	}
+
+	if worker.IsRunning() {
+		if err := worker.Stop(); err != nil {
+			return nil, err
+		}
+		s.auditLogger.Warn("Worker " + name + " paused")
+	}
+
+	return workerStatus(worker), nil
+}
+
+func (s *service) ResumeWorker(name string) (*WorkerStatus, error) {
+	worker, ok := s.workers[name]
+	if !ok {
+		return nil, ErrWorkerNotFound
+	}
+
+	if !worker.IsRunning() {
+		if err := worker.Start(); err != nil {
+			s.logger.Error("Failed to resume worker " + name + ": " + err.Error())
+			return nil, err
+		}
+		s.auditLogger.Info("Worker " + name + " resumed")
+	}
+
+	return workerStatus(worker), nil
+}
+
+func (s *service) RunWorker(name string) (*WorkerStatus, error) {
+	worker, ok := s.workers[name]
+	if !ok {
+		return nil, ErrWorkerNotFound
+	}
+
+	if err := worker.Trigger(); err != nil {
+		return nil, err
+	}
+	s.auditLogger.Info("Worker " + name + " run on demand")
+
+	return workerStatus(worker), nil
+}
+
+// workerStatus describes the worker as shown to admins
+func workerStatus(worker Worker) *WorkerStatus {
+	status := &WorkerStatus{
+		Name:     worker.Name(),
+		Schedule: worker.Schedule(),
+		Running:  worker.IsRunning(),
+	}
+	if lastRunAt, lastError := worker.LastRun(); !lastRunAt.IsZero() {
+		status.LastRunAt = &lastRunAt
+		status.LastError = lastError
+	}
+
+	return status
+}
+
+func parsePageRequest(cursor string, limit int) (*utils.Cursor, int, error) {
+	if limit < 1 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	if cursor == "" {
+		return nil, limit, nil
+	}
+
+	after, err := utils.DecodeCursor(cursor)
+	if err != nil {
+		return nil, 0, err
+	}
+	return after, limit, nil
+}
@@ -0,0 +1,114 @@
+package aggregate
+
+import (
+	"time"
+)
+
+// Metrics kept as daily aggregates
+const (
+	MetricArticlesSaved = "articles_saved" // Articles saved, keyed by user ID
+	MetricDomainSaves   = "domain_saves"   // Articles saved, keyed by domain
+	MetricRatings       = "ratings"        // Articles rated, keyed by user ID
+	MetricAPIRequests   = "api_requests"   // Authenticated requests, keyed by user ID
+)
+
+// Metrics lists every metric in refresh order
+var Metrics = []string{MetricArticlesSaved, MetricDomainSaves, MetricRatings, MetricAPIRequests}
+
+// TotalKey is the key of the bucket summing all keys of a metric on a day
+const TotalKey = "*"
+
+// Bucket is the value of a metric for one key on one day (UTC)
+type Bucket struct {
+	Metric string    `json:"metric" gorm:"size:50;primaryKey"`
+	Key    string    `json:"key" gorm:"size:255;primaryKey"`
+	Day    time.Time `json:"day" gorm:"type:date;primaryKey"`
+	Value  int64     `json:"value" gorm:"not null;default:0"`
+}
+
+// TableName returns the table name for GORM
+func (Bucket) TableName() string {
+	return "aggregate_buckets"
+}
+
+// Refresh records when a metric's buckets were last rebuilt
+type Refresh struct {
+	Metric      string    `gorm:"size:50;primaryKey"`
+	RefreshedAt time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for GORM
+func (Refresh) TableName() string {
+	return "aggregate_refreshes"
+}
+
+// KeyTotal is the sum of a metric for one key over a window
+type KeyTotal struct {
+	Key   string `json:"key"`
+	Value int64  `json:"value"`
+}
+
+// Repository defines the interface for aggregate storage
+type Repository interface {
+	// Rebuild recomputes the metric's buckets from the given day on out of the
+	// source tables and records refreshedAt, atomically for readers
+	Rebuild(metric string, since, refreshedAt time.Time) error
+	// DeleteBefore drops buckets of all metrics older than the given day
+	DeleteBefore(day time.Time) error
+	FindRefreshes() (map[string]time.Time, error)
+	FindSeries(metric, key string, since time.Time) ([]*Bucket, error)
+	// FindTop sums the metric per key since the given day, largest first
+	FindTop(metric string, since time.Time, limit int) ([]*KeyTotal, error)
+}
+
+// Service defines the interface for windowed aggregates. Reads never touch the
+// source tables; they see the buckets as of the last refresh.
+type Service interface {
+	// Refresh rebuilds the recent buckets of every metric and prunes expired ones
+	Refresh() error
+	// Series returns a metric's daily values for a key over the last days;
+	// TotalKey selects the sum over all keys
+	Series(metric, key string, days int) (*SeriesResponse, error)
+	// Top returns the keys with the largest totals over the last days
+	Top(metric string, days, limit int) (*TopResponse, error)
+	// Status lists when each metric was last refreshed
+	Status() (*StatusResponse, error)
+}
+
+// DayValue is a metric's value on a single day
+type DayValue struct {
+	Date  string `json:"date"` // YYYY-MM-DD in UTC
+	Value int64  `json:"value"`
+}
+
+// SeriesResponse is a metric's daily values for one key
+type SeriesResponse struct {
+	Metric      string      `json:"metric"`
+	Key         string      `json:"key"`
+	From        string      `json:"from"`
+	To          string      `json:"to"`
+	Total       int64       `json:"total"`
+	Days        []*DayValue `json:"days"`
+	RefreshedAt *time.Time  `json:"refreshed_at"` // Null before the first refresh
+}
+
+// TopResponse ranks the keys of a metric over a window
+type TopResponse struct {
+	Metric      string      `json:"metric"`
+	From        string      `json:"from"`
+	To          string      `json:"to"`
+	Keys        []*KeyTotal `json:"keys"`
+	RefreshedAt *time.Time  `json:"refreshed_at"` // Null before the first refresh
+}
+
+// MetricStatus tells how fresh a metric's buckets are
+type MetricStatus struct {
+	Metric      string     `json:"metric"`
+	RefreshedAt *time.Time `json:"refreshed_at"` // Null before the first refresh
+}
+
+// StatusResponse lists the state of every metric
+type StatusResponse struct {
+	RetentionDays int             `json:"retention_days"`
+	Metrics       []*MetricStatus `json:"metrics"`
+}
@@ -0,0 +1,222 @@
+package aggregate
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rebuild records a Rebuild call
+type rebuild struct {
+	metric string
+	since  time.Time
+}
+
+// mockRepository keeps buckets in memory and records rebuilds
+type mockRepository struct {
+	buckets      []*Bucket
+	refreshes    map[string]time.Time
+	rebuilds     []rebuild
+	deleteBefore time.Time
+	failMetric   string
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{refreshes: make(map[string]time.Time)}
+}
+
+func (m *mockRepository) Rebuild(metric string, since, refreshedAt time.Time) error {
+	if metric == m.failMetric {
+		return errors.New("source table unavailable")
+	}
+	m.rebuilds = append(m.rebuilds, rebuild{metric: metric, since: since})
+	m.refreshes[metric] = refreshedAt
+	return nil
+}
+
+func (m *mockRepository) DeleteBefore(day time.Time) error {
+	m.deleteBefore = day
+	return nil
+}
+
+func (m *mockRepository) FindRefreshes() (map[string]time.Time, error) {
+	refreshes := make(map[string]time.Time, len(m.refreshes))
+	for metric, refreshedAt := range m.refreshes {
+		refreshes[metric] = refreshedAt
+	}
+	return refreshes, nil
+}
+
+func (m *mockRepository) FindSeries(metric, key string, since time.Time) ([]*Bucket, error) {
+	var buckets []*Bucket
+	for _, bucket := range m.buckets {
+		if bucket.Metric == metric && bucket.Key == key && !bucket.Day.Before(since) {
+			buckets = append(buckets, bucket)
+		}
+	}
+	return buckets, nil
+}
+
+func (m *mockRepository) FindTop(metric string, since time.Time, limit int) ([]*KeyTotal, error) {
+	sums := make(map[string]int64)
+	for _, bucket := range m.buckets {
+		if bucket.Metric == metric && bucket.Key != TotalKey && !bucket.Day.Before(since) {
+			sums[bucket.Key] += bucket.Value
+		}
+	}
+	totals := make([]*KeyTotal, 0, len(sums))
+	for key, value := range sums {
+		totals = append(totals, &KeyTotal{Key: key, Value: value})
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Value != totals[j].Value {
+			return totals[i].Value > totals[j].Value
+		}
+		return totals[i].Key < totals[j].Key
+	})
+	if len(totals) > limit {
+		totals = totals[:limit]
+	}
+	return totals, nil
+}
+
+func newTestService(t *testing.T, cfg *config.AggregateConfig, repo Repository, now time.Time) *service {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	svc, err := NewService(cfg, repo, log)
+	require.NoError(t, err)
+
+	s := svc.(*service)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestNewService(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	_, err = NewService(&config.AggregateConfig{RetentionDays: "a while"}, newMockRepository(), log)
+	assert.Error(t, err)
+
+	_, err = NewService(&config.AggregateConfig{LookbackDays: "0"}, newMockRepository(), log)
+	assert.Error(t, err)
+
+	_, err = NewService(&config.AggregateConfig{RetentionDays: "7", LookbackDays: "8"}, newMockRepository(), log)
+	assert.Error(t, err)
+}
+
+func TestRefresh(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+	cfg := &config.AggregateConfig{RetentionDays: "30", LookbackDays: "2"}
+
+	t.Run("Backfills new metrics and rebuilds recent days of known ones", func(t *testing.T) {
+		repo := newMockRepository()
+		repo.refreshes[MetricRatings] = now.Add(-time.Hour)
+		svc := newTestService(t, cfg, repo, now)
+
+		require.NoError(t, svc.Refresh())
+
+		require.Len(t, repo.rebuilds, len(Metrics))
+		for _, r := range repo.rebuilds {
+			if r.metric == MetricRatings {
+				assert.Equal(t, time.Date(2024, 5, 9, 0, 0, 0, 0, time.UTC), r.since)
+			} else {
+				assert.Equal(t, time.Date(2024, 4, 11, 0, 0, 0, 0, time.UTC), r.since, r.metric)
+			}
+			assert.Equal(t, now, repo.refreshes[r.metric])
+		}
+		assert.Equal(t, time.Date(2024, 4, 11, 0, 0, 0, 0, time.UTC), repo.deleteBefore)
+	})
+
+	t.Run("Keeps refreshing other metrics when one fails", func(t *testing.T) {
+		repo := newMockRepository()
+		repo.failMetric = MetricDomainSaves
+		svc := newTestService(t, cfg, repo, now)
+
+		assert.Error(t, svc.Refresh())
+
+		assert.Len(t, repo.rebuilds, len(Metrics)-1)
+		assert.NotContains(t, repo.refreshes, MetricDomainSaves)
+		assert.False(t, repo.deleteBefore.IsZero(), "expired buckets are still pruned")
+	})
+}
+
+func TestSeries(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+
+	repo := newMockRepository()
+	repo.refreshes[MetricArticlesSaved] = now.Add(-10 * time.Minute)
+	repo.buckets = []*Bucket{
+		{Metric: MetricArticlesSaved, Key: TotalKey, Day: day(8), Value: 5},
+		{Metric: MetricArticlesSaved, Key: TotalKey, Day: day(10), Value: 2},
+		{Metric: MetricArticlesSaved, Key: "user-1", Day: day(10), Value: 2},
+		{Metric: MetricArticlesSaved, Key: TotalKey, Day: day(1), Value: 40}, // Outside the window
+		{Metric: MetricRatings, Key: TotalKey, Day: day(10), Value: 9},
+	}
+	svc := newTestService(t, &config.AggregateConfig{RetentionDays: "30"}, repo, now)
+
+	t.Run("Fills days without buckets with zero", func(t *testing.T) {
+		series, err := svc.Series(MetricArticlesSaved, "", 3)
+		require.NoError(t, err)
+
+		assert.Equal(t, TotalKey, series.Key)
+		assert.Equal(t, "2024-05-08", series.From)
+		assert.Equal(t, "2024-05-10", series.To)
+		assert.Equal(t, []*DayValue{{Date: "2024-05-08", Value: 5}, {Date: "2024-05-09", Value: 0}, {Date: "2024-05-10", Value: 2}}, series.Days)
+		assert.Equal(t, int64(7), series.Total)
+		require.NotNil(t, series.RefreshedAt)
+		assert.Equal(t, repo.refreshes[MetricArticlesSaved], *series.RefreshedAt)
+	})
+
+	t.Run("Reads a single key", func(t *testing.T) {
+		series, err := svc.Series(MetricArticlesSaved, "user-1", 7)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), series.Total)
+		assert.Len(t, series.Days, 7)
+	})
+
+	t.Run("Has no refresh time before the first refresh", func(t *testing.T) {
+		series, err := svc.Series(MetricAPIRequests, "", 0)
+		require.NoError(t, err)
+		assert.Nil(t, series.RefreshedAt)
+		assert.Len(t, series.Days, defaultWindowDays)
+	})
+
+	t.Run("Rejects unknown metrics and windows beyond retention", func(t *testing.T) {
+		_, err := svc.Series("page_views", "", 7)
+		assert.ErrorIs(t, err, utils.ErrValidation)
+
+		_, err = svc.Series(MetricArticlesSaved, "", 31)
+		assert.ErrorIs(t, err, utils.ErrValidation)
+	})
+}
+
+func TestTop(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+	day := time.Date(2024, 5, 9, 0, 0, 0, 0, time.UTC)
+
+	repo := newMockRepository()
+	repo.buckets = []*Bucket{
+		{Metric: MetricDomainSaves, Key: TotalKey, Day: day, Value: 10},
+		{Metric: MetricDomainSaves, Key: "example.com", Day: day, Value: 6},
+		{Metric: MetricDomainSaves, Key: "go.dev", Day: day, Value: 3},
+		{Metric: MetricDomainSaves, Key: "blog.example.org", Day: day, Value: 1},
+	}
+	svc := newTestService(t, nil, repo, now)
+
+	top, err := svc.Top(MetricDomainSaves, 7, 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, "2024-05-04", top.From)
+	assert.Equal(t, []*KeyTotal{{Key: "example.com", Value: 6}, {Key: "go.dev", Value: 3}}, top.Keys)
+	assert.Nil(t, top.RefreshedAt)
+}
@@ -0,0 +1,88 @@
+package aggregate
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles HTTP requests for aggregated metrics
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new aggregate handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// GetStatus handles listing the metrics and when they were last refreshed
+func (h *Handler) GetStatus(c *gin.Context) {
+	response, err := h.service.Status()
+	if err != nil {
+		utils.RespondError(c, err, "Failed to get metrics")
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetSeries handles getting a metric's daily values for one key, or all keys by default
+func (h *Handler) GetSeries(c *gin.Context) {
+	days, ok := intQuery(c, "days")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.Series(c.Param("metric"), c.Query("key"), days)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to get metric")
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTop handles ranking the keys of a metric by their total
+func (h *Handler) GetTop(c *gin.Context) {
+	days, ok := intQuery(c, "days")
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	response, err := h.service.Top(c.Param("metric"), days, limit)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to rank metric")
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// intQuery reads an optional integer parameter, responding 400 when malformed
+func intQuery(c *gin.Context, name string) (int, bool) {
+	param := c.Query(name)
+	if param == "" {
+		return 0, true
+	}
+	value, err := strconv.Atoi(param)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name})
+		return 0, false
+	}
+	return value, true
+}
+
+// RegisterRoutes registers metric routes under /admin. rateLimit runs after the
+// role and scope checks so limits apply per authenticated admin.
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, rateLimit gin.HandlerFunc) {
+	metrics := router.Group("/admin/metrics")
+	metrics.Use(authMiddleware, utils.RequireRole(utils.RoleAdmin), utils.RequireScope(utils.ScopeAdmin), rateLimit)
+	{
+		metrics.GET("", h.GetStatus)
+		metrics.GET("/:metric", h.GetSeries)
+		metrics.GET("/:metric/top", h.GetTop)
+	}
+}
@@ -0,0 +1,254 @@
+package aggregate
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+)
+
+const (
+	defaultRetentionDays = 90
+	defaultLookbackDays  = 2
+	defaultWindowDays    = 30
+	defaultTopLimit      = 10
+	maxTopLimit          = 100
+	dateLayout           = "2006-01-02"
+)
+
+// service implements the Service interface. Refreshes rebuild only the last
+// few days, since older source rows rarely change; a metric without buckets
+// yet is backfilled over the whole retention window.
+type service struct {
+	repo          Repository
+	retentionDays int
+	lookbackDays  int
+	logger        *logger.Logger
+	now           func() time.Time
+}
+
+// NewService creates an aggregate service with validation and defaults
+func NewService(cfg *config.AggregateConfig, repo Repository, log *logger.Logger) (Service, error) {
+	retentionDays := defaultRetentionDays
+	lookbackDays := defaultLookbackDays
+	if cfg != nil {
+		var err error
+		if retentionDays, err = parseDays("retention days", cfg.RetentionDays, defaultRetentionDays); err != nil {
+			return nil, err
+		}
+		if lookbackDays, err = parseDays("lookback days", cfg.LookbackDays, defaultLookbackDays); err != nil {
+			return nil, err
+		}
+	}
+	if lookbackDays > retentionDays {
+		return nil, fmt.Errorf("invalid aggregate lookback days '%d': must not exceed retention days %d", lookbackDays, retentionDays)
+	}
+
+	return &service{
+		repo:          repo,
+		retentionDays: retentionDays,
+		lookbackDays:  lookbackDays,
+		logger:        log.WithComponent("aggregate-service"),
+		now:           time.Now,
+	}, nil
+}
+
+func parseDays(name, value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid aggregate %s '%s': %v", name, value, err)
+	}
+	if days < 1 {
+		return 0, fmt.Errorf("invalid aggregate %s '%s': must be at least 1", name, value)
+	}
+	return days, nil
+}
+
+func (s *service) Refresh() error {
+	refreshes, err := s.repo.FindRefreshes()
+	if err != nil {
+		s.logger.Error("Failed to load aggregate refresh times: " + err.Error())
+		return err
+	}
+
+	now := s.now()
+	today := truncateDay(now)
+	oldest := today.AddDate(0, 0, -(s.retentionDays - 1))
+
+	// A failing metric must not keep the others stale
+	var failures []error
+	for _, metric := range Metrics {
+		since := today.AddDate(0, 0, -(s.lookbackDays - 1))
+		if _, refreshed := refreshes[metric]; !refreshed {
+			since = oldest
+		}
+
+		if err := s.repo.Rebuild(metric, since, now); err != nil {
+			s.logger.Error("Failed to refresh aggregate " + metric + ": " + err.Error())
+			failures = append(failures, err)
+		}
+	}
+
+	if err := s.repo.DeleteBefore(oldest); err != nil {
+		s.logger.Error("Failed to prune expired aggregates: " + err.Error())
+		failures = append(failures, err)
+	}
+
+	if len(failures) > 0 {
+		return errors.Join(failures...)
+	}
+
+	s.logger.Debug("Refreshed " + strconv.Itoa(len(Metrics)) + " aggregates")
+	return nil
+}
+
+func (s *service) Series(metric, key string, days int) (*SeriesResponse, error) {
+	if err := validateMetric(metric); err != nil {
+		return nil, err
+	}
+	if key == "" {
+		key = TotalKey
+	}
+	days, err := s.windowDays(days)
+	if err != nil {
+		return nil, err
+	}
+
+	today := truncateDay(s.now())
+	since := today.AddDate(0, 0, -(days - 1))
+
+	buckets, err := s.repo.FindSeries(metric, key, since)
+	if err != nil {
+		s.logger.Error("Failed to load aggregate " + metric + " for " + key + ": " + err.Error())
+		return nil, err
+	}
+	refreshedAt, err := s.refreshedAt(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]int64, len(buckets))
+	for _, bucket := range buckets {
+		values[bucket.Day.UTC().Format(dateLayout)] += bucket.Value
+	}
+
+	// Days without activity have no bucket and read as zero
+	response := &SeriesResponse{
+		Metric:      metric,
+		Key:         key,
+		From:        since.Format(dateLayout),
+		To:          today.Format(dateLayout),
+		Days:        make([]*DayValue, 0, days),
+		RefreshedAt: refreshedAt,
+	}
+	for day := since; !day.After(today); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		response.Days = append(response.Days, &DayValue{Date: date, Value: values[date]})
+		response.Total += values[date]
+	}
+
+	return response, nil
+}
+
+func (s *service) Top(metric string, days, limit int) (*TopResponse, error) {
+	if err := validateMetric(metric); err != nil {
+		return nil, err
+	}
+	days, err := s.windowDays(days)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultTopLimit
+	}
+	if limit > maxTopLimit {
+		limit = maxTopLimit
+	}
+
+	today := truncateDay(s.now())
+	since := today.AddDate(0, 0, -(days - 1))
+
+	keys, err := s.repo.FindTop(metric, since, limit)
+	if err != nil {
+		s.logger.Error("Failed to rank aggregate " + metric + ": " + err.Error())
+		return nil, err
+	}
+	refreshedAt, err := s.refreshedAt(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TopResponse{
+		Metric:      metric,
+		From:        since.Format(dateLayout),
+		To:          today.Format(dateLayout),
+		Keys:        keys,
+		RefreshedAt: refreshedAt,
+	}, nil
+}
+
+func (s *service) Status() (*StatusResponse, error) {
+	refreshes, err := s.repo.FindRefreshes()
+	if err != nil {
+		s.logger.Error("Failed to load aggregate refresh times: " + err.Error())
+		return nil, err
+	}
+
+	response := &StatusResponse{
+		RetentionDays: s.retentionDays,
+		Metrics:       make([]*MetricStatus, 0, len(Metrics)),
+	}
+	for _, metric := range Metrics {
+		status := &MetricStatus{Metric: metric}
+		if refreshedAt, ok := refreshes[metric]; ok {
+			status.RefreshedAt = &refreshedAt
+		}
+		response.Metrics = append(response.Metrics, status)
+	}
+
+	return response, nil
+}
+
+// windowDays applies the default window and rejects windows beyond retention
+func (s *service) windowDays(days int) (int, error) {
+	if days == 0 {
+		days = min(defaultWindowDays, s.retentionDays)
+	}
+	if days < 1 || days > s.retentionDays {
+		return 0, utils.NewValidationError("days", fmt.Sprintf("must be between 1 and %d", s.retentionDays))
+	}
+	return days, nil
+}
+
+// refreshedAt returns when the metric was last refreshed, or nil if never
+func (s *service) refreshedAt(metric string) (*time.Time, error) {
+	refreshes, err := s.repo.FindRefreshes()
+	if err != nil {
+		s.logger.Error("Failed to load aggregate refresh times: " + err.Error())
+		return nil, err
+	}
+	if refreshedAt, ok := refreshes[metric]; ok {
+		return &refreshedAt, nil
+	}
+	return nil, nil
+}
+
+func validateMetric(metric string) error {
+	if !slices.Contains(Metrics, metric) {
+		return utils.NewValidationError("metric", "unknown metric '"+metric+"'")
+	}
+	return nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
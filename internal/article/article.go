@@ -1,33 +1,108 @@
 package article
 
 import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/storage"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Errors returned by the article service and repository
+var (
+	ErrNotFound            = utils.NewNotFoundError("article not found")
+	ErrHighlightNotFound   = utils.NewNotFoundError("highlight not found")
+	ErrTagNotFound         = utils.NewNotFoundError("tag not found")
+	ErrContentNotAvailable = utils.NewNotFoundError("content not available")
+
+	// ErrMetadataExtraction wraps failures to fetch or parse the source page, as opposed to storage errors
+	ErrMetadataExtraction = errors.New("metadata extraction failed")
 )
 
 // Article represents an article with optimized GORM relationships
 type Article struct {
-	ID              uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	UserID          uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index:idx_user_articles"`
-	URL             string    `json:"url" gorm:"not null;size:2048;uniqueIndex:idx_user_url,composite:user_id"`
-	Title           string    `json:"title" gorm:"size:500"`
-	Description     string    `json:"description" gorm:"type:text"`
-	ImageURL        string    `json:"image_url" gorm:"size:2048"`
-	Content         string    `json:"content" gorm:"type:text"`
-	WordCount       int       `json:"word_count" gorm:"default:0"`
-	MetadataStatus  string    `json:"metadata_status" gorm:"size:20;default:'pending';index"`
-	RetryCount      int       `json:"retry_count" gorm:"default:0"`
-	ConfidenceScore float64   `json:"confidence_score" gorm:"default:0"`
-	ClassifierUsed  string    `json:"classifier_used" gorm:"size:50"`
-	Embedding       []float64 `json:"-" gorm:"type:vector(384);index"`                   // Store embedding for recommendations
-	EmbeddingStatus string    `json:"embedding_status" gorm:"size:20;default:'pending'"` // Track embedding generation status
-	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime;index"`
-	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID              uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid();index:idx_user_articles_keyset,priority:3"`
+	UserID          uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index:idx_user_articles;index:idx_user_articles_keyset,priority:1"`
+	URL             string     `json:"url" gorm:"not null;size:2048;uniqueIndex:idx_user_url,composite:user_id"`
+	Title           string     `json:"title" gorm:"size:500"`
+	Description     string     `json:"description" gorm:"type:text"`
+	ImageURL        string     `json:"image_url" gorm:"size:2048"`
+	SiteName        string     `json:"site_name" gorm:"size:200"`
+	FaviconURL      string     `json:"favicon_url" gorm:"size:2048"`
+	Content         string     `json:"content" gorm:"type:text"`
+	Notes           string     `json:"notes" gorm:"type:text"`
+	ReadAt          *time.Time `json:"read_at,omitempty" gorm:"index"`      // Nil while the article is in the unread queue
+	FavoritedAt     *time.Time `json:"favorited_at,omitempty" gorm:"index"` // Nil unless the user marked the article a favorite
+	WordCount       int        `json:"word_count" gorm:"default:0"`
+	ReadingTime     int        `json:"reading_time_minutes" gorm:"column:reading_time_minutes;default:0;index"` // 0 until metadata is extracted
+	Language        string     `json:"language" gorm:"size:8;index"`                                            // ISO 639-1 code, empty until detected
+	MetadataStatus  string     `json:"metadata_status" gorm:"size:20;default:'pending';index"`
+	RetryCount      int        `json:"retry_count" gorm:"default:0"`
+	LastError       string     `json:"last_error,omitempty" gorm:"type:text"` // Why the last extraction failed; cleared once one succeeds
+	ErrorCategory   string     `json:"last_error_category,omitempty" gorm:"column:last_error_category;size:20;index"`
+	LastErrorAt     *time.Time `json:"last_error_at,omitempty" gorm:"index"`
+	ConfidenceScore float64    `json:"confidence_score" gorm:"default:0"`
+	ClassifierUsed  string     `json:"classifier_used" gorm:"size:50"`
+	Embedding       []float64  `json:"-" gorm:"type:vector(384);index"`                   // Store embedding for recommendations
+	EmbeddingStatus string     `json:"embedding_status" gorm:"size:20;default:'pending'"` // Track embedding generation status
+	// Request ID the failed extraction ran under, to find it in the logs of both services
+	LastErrorRequestID string `json:"last_error_request_id,omitempty" gorm:"size:64"`
+	// Full-content embedding, kept apart from the title+description embedding above
+	ContentEmbedding       []float64 `json:"-" gorm:"type:vector(384)"`
+	ContentEmbeddingStatus string    `json:"content_embedding_status" gorm:"size:20;default:'pending'"`
+	// Set while the content lives in object storage instead of the content column
+	ContentKey string `json:"-" gorm:"size:255"`
+	// Permanent copy of the extracted page in object storage
+	SnapshotKey string     `json:"-" gorm:"size:255"`
+	SnapshotAt  *time.Time `json:"snapshot_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime;index;index:idx_user_articles_keyset,priority:2"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	// Set while the article is in the trash; queries through this model skip trashed articles
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Associations
 	User    *User    `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
 	Ratings []Rating `json:"ratings,omitempty" gorm:"foreignKey:ArticleID;constraint:OnDelete:CASCADE"`
+	Tags    []Tag    `json:"tags,omitempty" gorm:"many2many:article_tags;constraint:OnDelete:CASCADE"`
+
+	// Content as loaded from object storage, so unchanged content is not written again
+	loadedContent string
+}
+
+// Tag represents a user-defined label attached to articles through article_tags
+type Tag struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_user_tag_name"`
+	Name      string    `json:"name" gorm:"size:50;not null;uniqueIndex:idx_user_tag_name"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// SearchResult pairs an article with its full-text relevance
+type SearchResult struct {
+	Article *Article
+	Rank    float64
+}
+
+// ImportedArticle is a link brought in from another read-later service
+type ImportedArticle struct {
+	URL     string
+	Title   string
+	Tags    []string
+	AddedAt time.Time
+}
+
+// ArticleFilter narrows down article listings
+type ArticleFilter struct {
+	Tags           []string // Articles must carry all of these tags
+	MaxReadingTime int      // Minutes; 0 disables the filter. Articles of unknown length are left out.
+	Language       string   // ISO 639-1 code; empty disables the filter
+	Unread         bool     // Only articles not marked as read
+	Favorites      bool     // Only articles marked as favorites
 }
 
 // User represents user for foreign key relationship (forward declaration)
@@ -61,11 +136,51 @@ const (
 // Repository defines the interface for article data access
 type Repository interface {
 	Create(article *Article) error
+	// CreateBatch creates the articles with few round trips and returns the IDs
+	// of those created; links the user saved meanwhile are skipped
+	CreateBatch(articles []*Article) ([]uuid.UUID, error)
+	FindExistingURLs(userID uuid.UUID, urls []string) (map[string]bool, error)
 	FindByID(id uuid.UUID) (*Article, error)
-	FindByUserID(userID uuid.UUID, offset, limit int) ([]*Article, error)
-	FindByUserIDWithRatings(userID uuid.UUID, offset, limit int) ([]*Article, error)
+	FindByUserID(userID uuid.UUID, filter *ArticleFilter, offset, limit int) ([]*Article, error)
+	FindByUserIDWithRatings(userID uuid.UUID, filter *ArticleFilter, offset, limit int) ([]*Article, error)
+	CountByUserID(userID uuid.UUID, filter *ArticleFilter) (int64, error)
+	// Library statistics, aggregated in the database
+	CountTotals(userID uuid.UUID) (*ArticleTotals, error)
+	CountByDomain(userID uuid.UUID, limit int) ([]*DomainCount, error)
+	CountByMetadataStatus(userID uuid.UUID) (map[string]int64, error)
+	CountRatingsByScore(userID uuid.UUID) (map[int]int64, error)
+	// FindByUserIDAfter lists articles newest first, starting after the cursor (nil for the first page)
+	FindByUserIDAfter(userID uuid.UUID, filter *ArticleFilter, after *Cursor, limit int) ([]*Article, error)
+	StreamByUserID(userID uuid.UUID, batchSize int, fn func(batch []*Article) error) error
 	Update(article *Article) error
+	UpdateFields(id uuid.UUID, fields map[string]any) error
 	Delete(id uuid.UUID) error
+	// DeleteBatch moves the user's articles among ids (all if empty) with the
+	// metadata status (any if empty) to the trash in one transaction, returning their IDs
+	DeleteBatch(userID uuid.UUID, ids []uuid.UUID, status string) ([]uuid.UUID, error)
+
+	// Trash: Delete moves an article to the trash, which the queries above skip
+	FindTrashedByID(id uuid.UUID) (*Article, error)
+	FindTrashedByUserID(userID uuid.UUID, offset, limit int) ([]*Article, error)
+	CountTrashedByUserID(userID uuid.UUID) (int64, error)
+	FindTrashedBefore(before time.Time, limit int) ([]*Article, error)
+	Restore(id uuid.UUID) error
+	Purge(id uuid.UUID) error
+
+	// Full-text search
+	Search(userID uuid.UUID, query string, offset, limit int) ([]*SearchResult, int64, error)
+
+	// Tag management
+	AddTags(articleID, userID uuid.UUID, names []string) error
+	RemoveTag(articleID, userID uuid.UUID, name string) error
+	FindTagsByUserID(userID uuid.UUID) ([]*Tag, error)
+
+	// Highlights
+	CreateHighlight(highlight *Highlight) error
+	FindHighlights(articleID uuid.UUID) ([]*Highlight, error)
+	FindHighlightByID(id uuid.UUID) (*Highlight, error)
+	UpdateHighlight(highlight *Highlight) error
+	DeleteHighlight(id uuid.UUID) error
 
 	// Metadata-specific queries
 	FindFailedMetadata(maxRetries int) ([]*Article, error)
@@ -74,20 +189,77 @@ type Repository interface {
 
 // Service defines the interface for article business logic
 type Service interface {
-	CreateArticle(userID uuid.UUID, url string) (*Article, error)
+	CreateArticle(ctx context.Context, userID uuid.UUID, url string) (*Article, error)
+	// ValidateURLs reports what saving each URL would do, without saving anything
+	ValidateURLs(userID uuid.UUID, req *ValidateURLsRequest) (*ValidateURLsResponse, error)
 	GetArticle(id uuid.UUID, userID uuid.UUID) (*Article, error)
-	GetUserArticles(userID uuid.UUID, page, limit int) ([]*Article, int64, error)
+	GetUserArticles(userID uuid.UUID, filter *ArticleFilter, page, limit int) ([]*Article, int64, error)
+	GetUserArticlesAfter(userID uuid.UUID, filter *ArticleFilter, cursor string, limit int) ([]*Article, string, error)
 	DeleteArticle(id uuid.UUID, userID uuid.UUID) error
-	UpdateMetadata(id uuid.UUID, title, description, content string, wordCount int, confidence float64) error
+	DeleteArticles(userID uuid.UUID, req *BatchDeleteRequest) (*BatchDeleteResponse, error)
+	GetTrash(userID uuid.UUID, page, limit int) ([]*Article, int64, error)
+	RestoreArticle(id uuid.UUID, userID uuid.UUID) (*Article, error)
+	GetStats(userID uuid.UUID) (*ArticleStatsResponse, error)
+	UpdateArticleFields(id uuid.UUID, userID uuid.UUID, req *UpdateArticleRequest) (*Article, error)
+	// MarkRead moves an article out of the unread queue, or back into it when read is false
+	MarkRead(id uuid.UUID, userID uuid.UUID, read bool) (*Article, error)
+	// SetFavorite marks an article a favorite, or unmarks it when favorite is false
+	SetFavorite(id uuid.UUID, userID uuid.UUID, favorite bool) (*Article, error)
+	SearchArticles(userID uuid.UUID, query string, page, limit int) ([]*SearchResult, int64, error)
+	ExportArticles(userID uuid.UUID, fn func(article *Article) error) error
+	AddTags(id uuid.UUID, userID uuid.UUID, names []string) (*Article, error)
+	RemoveTag(id uuid.UUID, userID uuid.UUID, name string) error
+	GetUserTags(userID uuid.UUID) ([]*Tag, error)
+	GetHighlights(id uuid.UUID, userID uuid.UUID) ([]*Highlight, error)
+	CreateHighlight(id uuid.UUID, userID uuid.UUID, req *CreateHighlightRequest) (*Highlight, error)
+	UpdateHighlight(id, highlightID uuid.UUID, userID uuid.UUID, req *UpdateHighlightRequest) (*Highlight, error)
+	DeleteHighlight(id, highlightID uuid.UUID, userID uuid.UUID) error
+	UpdateMetadata(id uuid.UUID, title, description, content, language, siteName, faviconURL string, wordCount, readingTime int, confidence float64) error
+	ImportArticles(userID uuid.UUID, items []*ImportedArticle) ([]*Article, error)
+	RefreshMetadata(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Article, error)
+	GetContent(id uuid.UUID, userID uuid.UUID) (*storage.Object, error)
+
+	// RemoveArticle permanently deletes any user's article, trashed or not,
+	// for moderation
+	RemoveArticle(id uuid.UUID) (*Article, error)
 
 	// Background processing
 	RetryFailedMetadata() error
+	PurgeTrash() error
 	ExtractMetadata(articleID uuid.UUID) error
 }
 
+// FavoritesListener is told when a user marks an article a favorite or
+// unmarks it, e.g. to update their recommendation profile (dependency inversion)
+type FavoritesListener interface {
+	FavoriteChanged(userID, articleID uuid.UUID, favorite bool)
+}
+
 // MetadataExtractor interface for content extraction
 type MetadataExtractor interface {
-	Extract(url string) (*ExtractedMetadata, error)
+	// Extract fetches and classifies the page; ctx carries the request ID
+	// forwarded to the embedding service
+	Extract(ctx context.Context, url string) (*ExtractedMetadata, error)
+}
+
+// ExtractionPriority orders background metadata extraction
+type ExtractionPriority int
+
+// Extraction priorities, highest first
+const (
+	ExtractionPriorityInteractive ExtractionPriority = iota // The user just saved the article
+	ExtractionPriorityImport                                // Part of a bulk import
+	ExtractionPriorityRetry                                 // Retry of a failed extraction
+)
+
+// ExtractionQueue runs metadata extraction in the background, at most once at a
+// time per article. Enqueue reports false when the task was not queued, e.g.
+// because the article is already waiting or being extracted.
+type ExtractionQueue interface {
+	Enqueue(priority ExtractionPriority, articleID uuid.UUID, task func() error) bool
+	// Run extracts right away on the caller's goroutine, or waits for an
+	// extraction of the article already running and returns its error
+	Run(articleID uuid.UUID, task func() error) error
 }
 
 // ExtractedMetadata represents extracted article metadata
@@ -95,8 +267,13 @@ type ExtractedMetadata struct {
 	Title       string
 	Description string
 	Content     string
+	HTML        string // Cleaned markup of the main content, empty if unavailable
 	ImageURL    string
+	SiteName    string // Publisher name declared by the page, empty if none
+	FaviconURL  string
 	WordCount   int
+	ReadingTime int    // Estimated minutes
+	Language    string // ISO 639-1 code, empty if unknown
 	Confidence  float64
 }
 
@@ -105,24 +282,96 @@ type CreateArticleRequest struct {
 	URL string `json:"url" binding:"required,url"`
 }
 
+// UpdateArticleRequest represents a partial update of user-editable fields
+type UpdateArticleRequest struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	Notes       *string `json:"notes"`
+}
+
+// BatchDeleteRequest selects articles to delete at once: the listed IDs, the
+// articles with a metadata status, or the listed IDs that have the status
+type BatchDeleteRequest struct {
+	IDs    []uuid.UUID `json:"ids" binding:"max=1000"`
+	Status string      `json:"status"` // Metadata status, such as failed
+}
+
+// Outcomes of deleting one article in a batch
+const (
+	BatchDeleteStatusDeleted  = "deleted"
+	BatchDeleteStatusNotFound = "not_found" // Missing, owned by another user or already in the trash
+)
+
+// BatchDeleteResult reports what happened to one article of a batch delete
+type BatchDeleteResult struct {
+	ID     uuid.UUID `json:"id"`
+	Status string    `json:"status"`
+}
+
+// BatchDeleteResponse reports a batch delete; results follow the order of the requested IDs
+type BatchDeleteResponse struct {
+	Deleted int                  `json:"deleted"`
+	Results []*BatchDeleteResult `json:"results"`
+}
+
+// ValidateURLsRequest lists URLs to check before saving them
+type ValidateURLsRequest struct {
+	URLs []string `json:"urls" binding:"required,min=1,max=100"`
+}
+
+// URLValidationResult is the verdict on one URL of a validation request
+type URLValidationResult struct {
+	URL           string `json:"url"`
+	NormalizedURL string `json:"normalized_url,omitempty"` // The form the URL would be saved in
+	Verdict       string `json:"verdict"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+// ValidateURLsResponse reports verdicts in request order, and how many URLs got each
+type ValidateURLsResponse struct {
+	Results []*URLValidationResult `json:"results"`
+	Summary map[string]int         `json:"summary"`
+}
+
+// TagsRequest represents a request to attach tags to an article
+type TagsRequest struct {
+	Tags []string `json:"tags" binding:"required,min=1,max=20"`
+}
+
 // ArticleResponse represents article in API responses
 type ArticleResponse struct {
-	ID              uuid.UUID `json:"id"`
-	UserID          uuid.UUID `json:"user_id"`
-	URL             string    `json:"url"`
-	Title           string    `json:"title"`
-	Description     string    `json:"description"`
-	ImageURL        string    `json:"image_url"`
-	WordCount       int       `json:"word_count"`
-	MetadataStatus  string    `json:"metadata_status"`
-	ConfidenceScore float64   `json:"confidence_score"`
-	ClassifierUsed  string    `json:"classifier_used"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              uuid.UUID  `json:"id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	URL             string     `json:"url"`
+	Title           string     `json:"title"`
+	Description     string     `json:"description"`
+	ImageURL        string     `json:"image_url"`
+	SiteName        string     `json:"site_name"`
+	FaviconURL      string     `json:"favicon_url"`
+	Content         string     `json:"content,omitempty"`
+	Notes           string     `json:"notes,omitempty"`
+	ReadAt          *time.Time `json:"read_at,omitempty"`
+	FavoritedAt     *time.Time `json:"favorited_at,omitempty"`
+	Tags            []string   `json:"tags,omitempty"`
+	WordCount       int        `json:"word_count"`
+	ReadingTime     int        `json:"reading_time_minutes"`
+	Language        string     `json:"language"`
+	MetadataStatus  string     `json:"metadata_status"`
+	ConfidenceScore float64    `json:"confidence_score"`
+	ClassifierUsed  string     `json:"classifier_used"`
+	SnapshotAt      *time.Time `json:"snapshot_at,omitempty"` // Set once GET /articles/:id/content serves a stored copy
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"` // Only for articles in the trash
 
 	// Optional associations
-	AverageRating *float64 `json:"average_rating,omitempty"`
-	RatingCount   *int     `json:"rating_count,omitempty"`
+	AverageRating *float64     `json:"average_rating,omitempty"`
+	RatingCount   *int         `json:"rating_count,omitempty"`
+	Highlights    []*Highlight `json:"highlights,omitempty"` // Only with ?include=highlights
+
+	// Processing state, only with ?include=progress
+	EmbeddingStatus    string `json:"embedding_status,omitempty"`
+	ProcessingProgress *int   `json:"processing_progress,omitempty"` // Percentage of processing stages done
 }
 
 // ArticleListResponse represents paginated article list
@@ -132,6 +381,87 @@ type ArticleListResponse struct {
 	Page     int                `json:"page"`
 	Limit    int                `json:"limit"`
 	Pages    int                `json:"pages"`
+	// NextCursor continues a cursor-paginated listing; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// SearchResultResponse represents a ranked article in search responses
+type SearchResultResponse struct {
+	*ArticleResponse
+	Rank float64 `json:"rank"`
+}
+
+// SearchResponse represents paginated search results
+type SearchResponse struct {
+	Query   string                  `json:"query"`
+	Results []*SearchResultResponse `json:"results"`
+	Total   int64                   `json:"total"`
+	Page    int                     `json:"page"`
+	Limit   int                     `json:"limit"`
+	Pages   int                     `json:"pages"`
+}
+
+// Supported export formats
+const (
+	ExportFormatJSON = "json"
+	ExportFormatCSV  = "csv"
+)
+
+// ExportCSVHeader lists the columns of ArticleExport.CSVRecord
+var ExportCSVHeader = []string{
+	"id", "url", "title", "description", "image_url", "notes", "tags", "word_count",
+	"metadata_status", "rating", "average_rating", "rating_count", "created_at", "updated_at",
+}
+
+// ArticleExport represents an article in data exports
+type ArticleExport struct {
+	*ArticleResponse
+	Rating *int `json:"rating,omitempty"` // The owner's own score
+}
+
+// ToExport converts Article to ArticleExport; ratings must be loaded
+func (a *Article) ToExport() *ArticleExport {
+	export := &ArticleExport{ArticleResponse: a.ToResponse()}
+	for _, rating := range a.Ratings {
+		if rating.UserID == a.UserID {
+			score := rating.Score
+			export.Rating = &score
+			break
+		}
+	}
+	return export
+}
+
+// CSVRecord flattens the export into a row matching ExportCSVHeader
+func (e *ArticleExport) CSVRecord() []string {
+	optionalInt := func(value *int) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.Itoa(*value)
+	}
+
+	averageRating := ""
+	if e.AverageRating != nil {
+		averageRating = strconv.FormatFloat(*e.AverageRating, 'f', 2, 64)
+	}
+
+	return []string{
+		e.ID.String(),
+		e.URL,
+		e.Title,
+		e.Description,
+		e.ImageURL,
+		e.Notes,
+		strings.Join(e.Tags, ";"),
+		strconv.Itoa(e.WordCount),
+		e.MetadataStatus,
+		optionalInt(e.Rating),
+		averageRating,
+		optionalInt(e.RatingCount),
+		e.CreatedAt.UTC().Format(time.RFC3339),
+		e.UpdatedAt.UTC().Format(time.RFC3339),
+	}
 }
 
 // ToResponse converts Article to ArticleResponse
@@ -143,14 +473,35 @@ func (a *Article) ToResponse() *ArticleResponse {
 		Title:           a.Title,
 		Description:     a.Description,
 		ImageURL:        a.ImageURL,
+		SiteName:        a.SiteName,
+		FaviconURL:      a.FaviconURL,
+		Notes:           a.Notes,
+		ReadAt:          a.ReadAt,
+		FavoritedAt:     a.FavoritedAt,
 		WordCount:       a.WordCount,
+		ReadingTime:     a.ReadingTime,
+		Language:        a.Language,
 		MetadataStatus:  a.MetadataStatus,
 		ConfidenceScore: a.ConfidenceScore,
 		ClassifierUsed:  a.ClassifierUsed,
+		SnapshotAt:      a.SnapshotAt,
 		CreatedAt:       a.CreatedAt,
 		UpdatedAt:       a.UpdatedAt,
 	}
 
+	if a.DeletedAt.Valid {
+		deletedAt := a.DeletedAt.Time
+		response.DeletedAt = &deletedAt
+	}
+
+	// Flatten tags if they are loaded
+	if len(a.Tags) > 0 {
+		response.Tags = make([]string, len(a.Tags))
+		for i, tag := range a.Tags {
+			response.Tags[i] = tag.Name
+		}
+	}
+
 	// Calculate average rating if ratings are loaded
 	if len(a.Ratings) > 0 {
 		total := 0
@@ -166,6 +517,49 @@ func (a *Article) ToResponse() *ArticleResponse {
 	return response
 }
 
+// ToDetailResponse converts Article to ArticleResponse including the extracted content
+func (a *Article) ToDetailResponse() *ArticleResponse {
+	response := a.ToResponse()
+	response.Content = a.Content
+	return response
+}
+
+// IncludeProgress adds the article's embedding status and processing progress
+func (r *ArticleResponse) IncludeProgress(a *Article) {
+	progress := a.ProcessingProgress()
+	r.EmbeddingStatus = a.EmbeddingStatus
+	r.ProcessingProgress = &progress
+}
+
+// ProcessingProgress estimates how much of the article's processing is done,
+// in percent. The stages are metadata extraction, the title embedding and, for
+// articles with content, the content embedding. Failed stages that will not be
+// retried count as done, so progress reaches 100 once nothing is left to do.
+func (a *Article) ProcessingProgress() int {
+	settled := func(status string) bool {
+		return status == EmbeddingStatusSuccess || status == EmbeddingStatusFailed
+	}
+
+	stages, done := 2, 0
+	extracting := a.NeedsMetadataExtraction()
+	if !extracting {
+		done++
+	}
+	if settled(a.EmbeddingStatus) {
+		done++
+	}
+
+	// Whether there is content is only known once extraction is done
+	if extracting || strings.TrimSpace(a.Content) != "" || a.ContentKey != "" {
+		stages++
+		if settled(a.ContentEmbeddingStatus) {
+			done++
+		}
+	}
+
+	return done * 100 / stages
+}
+
 // IsOwnedBy checks if the article belongs to the specified user
 func (a *Article) IsOwnedBy(userID uuid.UUID) bool {
 	return a.UserID == userID
@@ -181,3 +575,8 @@ func (a *Article) NeedsMetadataExtraction() bool {
 func (Article) TableName() string {
 	return "articles"
 }
+
+// TableName returns the table name for GORM
+func (Tag) TableName() string {
+	return "tags"
+}
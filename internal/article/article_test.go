@@ -1,11 +1,24 @@
 package article
 
 import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
 	"testing"
 	"time"
 
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/dustin/articles-backend/pkg/storage"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 func TestArticle(t *testing.T) {
@@ -92,6 +105,7 @@ func TestArticle(t *testing.T) {
 			Description:     "Test Description",
 			ImageURL:        "https://example.com/image.jpg",
 			WordCount:       500,
+			ReadingTime:     3,
 			MetadataStatus:  MetadataStatusSuccess,
 			ConfidenceScore: 0.9,
 			ClassifierUsed:  "readability",
@@ -108,11 +122,24 @@ func TestArticle(t *testing.T) {
 		assert.Equal(t, article.Description, response.Description)
 		assert.Equal(t, article.ImageURL, response.ImageURL)
 		assert.Equal(t, article.WordCount, response.WordCount)
+		assert.Equal(t, article.ReadingTime, response.ReadingTime)
 		assert.Equal(t, article.MetadataStatus, response.MetadataStatus)
 		assert.Equal(t, article.ConfidenceScore, response.ConfidenceScore)
 		assert.Equal(t, article.ClassifierUsed, response.ClassifierUsed)
 	})
 
+	t.Run("ToDetailResponse includes content", func(t *testing.T) {
+		article := Article{
+			ID:      uuid.New(),
+			UserID:  uuid.New(),
+			Title:   "Test Article",
+			Content: "Full article content",
+		}
+
+		assert.Empty(t, article.ToResponse().Content)
+		assert.Equal(t, "Full article content", article.ToDetailResponse().Content)
+	})
+
 	t.Run("ToResponse with ratings", func(t *testing.T) {
 		article := Article{
 			ID:     uuid.New(),
@@ -133,12 +160,103 @@ func TestArticle(t *testing.T) {
 		assert.Equal(t, 3, *response.RatingCount)
 	})
 
+	t.Run("ToResponse with tags", func(t *testing.T) {
+		article := Article{
+			ID:   uuid.New(),
+			Tags: []Tag{{Name: "golang"}, {Name: "databases"}},
+		}
+
+		response := article.ToResponse()
+
+		assert.Equal(t, []string{"golang", "databases"}, response.Tags)
+	})
+
+	t.Run("ToResponse of trashed article", func(t *testing.T) {
+		deletedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		article := Article{ID: uuid.New()}
+
+		assert.Nil(t, article.ToResponse().DeletedAt)
+
+		article.DeletedAt = gorm.DeletedAt{Time: deletedAt, Valid: true}
+		response := article.ToResponse()
+		require.NotNil(t, response.DeletedAt)
+		assert.Equal(t, deletedAt, *response.DeletedAt)
+	})
+
+	t.Run("Processing progress", func(t *testing.T) {
+		pending := Article{MetadataStatus: MetadataStatusPending, EmbeddingStatus: EmbeddingStatusPending, ContentEmbeddingStatus: EmbeddingStatusPending}
+		assert.Equal(t, 0, pending.ProcessingProgress())
+		assert.Empty(t, pending.ToResponse().EmbeddingStatus)
+		assert.Nil(t, pending.ToResponse().ProcessingProgress)
+
+		extracted := Article{MetadataStatus: MetadataStatusSuccess, Content: "Body", EmbeddingStatus: EmbeddingStatusSuccess, ContentEmbeddingStatus: EmbeddingStatusPending}
+		assert.Equal(t, 66, extracted.ProcessingProgress())
+
+		// Without content there is no content embedding to wait for
+		extracted.Content = ""
+		assert.Equal(t, 100, extracted.ProcessingProgress())
+
+		// Failures that will be retried are still to do
+		failed := Article{MetadataStatus: MetadataStatusFailed, RetryCount: 1, EmbeddingStatus: EmbeddingStatusFailed, ContentEmbeddingStatus: EmbeddingStatusPending}
+		assert.Equal(t, 33, failed.ProcessingProgress())
+		failed.RetryCount = 3
+		assert.Equal(t, 100, failed.ProcessingProgress())
+
+		response := extracted.ToResponse()
+		response.IncludeProgress(&extracted)
+		assert.Equal(t, EmbeddingStatusSuccess, response.EmbeddingStatus)
+		require.NotNil(t, response.ProcessingProgress)
+		assert.Equal(t, 100, *response.ProcessingProgress)
+	})
+
 	t.Run("Table name", func(t *testing.T) {
 		article := Article{}
 		assert.Equal(t, "articles", article.TableName())
+		assert.Equal(t, "tags", Tag{}.TableName())
 	})
 }
 
+func TestNormalizeTagName(t *testing.T) {
+	tag, err := NormalizeTagName("  GoLang ")
+	assert.NoError(t, err)
+	assert.Equal(t, "golang", tag)
+
+	_, err = NormalizeTagName("   ")
+	assert.Error(t, err)
+
+	_, err = NormalizeTagName("a,b")
+	assert.Error(t, err)
+}
+
+func TestParseLanguage(t *testing.T) {
+	language, err := ParseLanguage(" ZH ")
+	require.NoError(t, err)
+	assert.Equal(t, "zh", language)
+
+	for _, code := range []string{"", "e", "english", "e1"} {
+		_, err := ParseLanguage(code)
+		assert.Error(t, err, code)
+	}
+}
+
+func TestQuoteRange(t *testing.T) {
+	content := "Über die Brücke gehen"
+
+	text, err := quoteRange(content, 0, 4)
+	require.NoError(t, err)
+	assert.Equal(t, "Über", text, "offsets count characters, not bytes")
+
+	text, err = quoteRange(content, 9, 15)
+	require.NoError(t, err)
+	assert.Equal(t, "Brücke", text)
+
+	for _, r := range [][2]int{{-1, 3}, {5, 5}, {6, 2}, {0, 100}} {
+		_, err := quoteRange(content, r[0], r[1])
+		_, ok := utils.AsValidationError(err)
+		assert.True(t, ok, "range %v", r)
+	}
+}
+
 func TestBuildPaginationResponse(t *testing.T) {
 	articles := []*Article{
 		{
@@ -159,3 +277,521 @@ func TestBuildPaginationResponse(t *testing.T) {
 	assert.Equal(t, 5, response.Limit)
 	assert.Equal(t, 2, response.Pages) // 10/5 = 2 pages
 }
+
+func TestCursor(t *testing.T) {
+	article := &Article{ID: uuid.New(), CreatedAt: time.Date(2024, 5, 10, 12, 30, 0, 123456000, time.FixedZone("CEST", 2*60*60))}
+
+	cursor, err := DecodeCursor(EncodeCursor(article))
+	require.NoError(t, err)
+	assert.Equal(t, article.ID, cursor.ID)
+	assert.True(t, article.CreatedAt.Equal(cursor.CreatedAt))
+
+	for _, token := range []string{"not base64!", "bm8tc2VwYXJhdG9y", EncodeCursor(&Article{})[:10]} {
+		_, err := DecodeCursor(token)
+		validationErr, ok := utils.AsValidationError(err)
+		require.True(t, ok, token)
+		assert.Equal(t, "cursor", validationErr.Field)
+	}
+
+	response := BuildCursorResponse([]*Article{article}, 20, "next")
+	assert.Len(t, response.Articles, 1)
+	assert.Equal(t, 20, response.Limit)
+	assert.Equal(t, "next", response.NextCursor)
+	assert.Zero(t, response.Total)
+}
+
+func TestBuildStatsResponse(t *testing.T) {
+	response := BuildStatsResponse(&ArticleTotals{Articles: 3, WordCount: 4200}, nil, map[string]int64{"success": 3}, map[int]int64{5: 2, 3: 1})
+
+	assert.Equal(t, int64(3), response.TotalArticles)
+	assert.Equal(t, int64(4200), response.TotalWordCount)
+	assert.NotNil(t, response.ByDomain)
+	assert.Equal(t, map[int]int64{1: 0, 2: 0, 3: 1, 4: 0, 5: 2}, response.Ratings)
+}
+
+func TestBuildSearchResponse(t *testing.T) {
+	results := []*SearchResult{
+		{Article: &Article{ID: uuid.New(), Title: "Postgres full-text search"}, Rank: 0.6},
+		{Article: &Article{ID: uuid.New(), Title: "Search in Go"}, Rank: 0.2},
+	}
+
+	response := BuildSearchResponse("search", results, 12, 2, 10)
+
+	assert.Equal(t, "search", response.Query)
+	assert.Len(t, response.Results, 2)
+	assert.Equal(t, "Postgres full-text search", response.Results[0].Title)
+	assert.Equal(t, 0.6, response.Results[0].Rank)
+	assert.Equal(t, int64(12), response.Total)
+	assert.Equal(t, 2, response.Page)
+	assert.Equal(t, 2, response.Pages)
+}
+
+func TestArticleExport(t *testing.T) {
+	userID := uuid.New()
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	article := &Article{
+		ID:             uuid.New(),
+		UserID:         userID,
+		URL:            "https://example.com/article",
+		Title:          "Title, with comma",
+		Notes:          "My notes",
+		WordCount:      1200,
+		MetadataStatus: MetadataStatusSuccess,
+		CreatedAt:      createdAt,
+		UpdatedAt:      createdAt,
+		Tags:           []Tag{{Name: "go"}, {Name: "db"}},
+		Ratings: []Rating{
+			{UserID: uuid.New(), Score: 2},
+			{UserID: userID, Score: 4},
+		},
+	}
+
+	t.Run("Includes own rating", func(t *testing.T) {
+		export := article.ToExport()
+		assert.NotNil(t, export.Rating)
+		assert.Equal(t, 4, *export.Rating)
+		assert.Equal(t, 3.0, *export.AverageRating)
+	})
+
+	t.Run("CSV record matches header", func(t *testing.T) {
+		record := article.ToExport().CSVRecord()
+		assert.Len(t, record, len(ExportCSVHeader))
+		assert.Equal(t, "Title, with comma", record[2])
+		assert.Equal(t, "go;db", record[6])
+		assert.Equal(t, "4", record[9])
+		assert.Equal(t, "3.00", record[10])
+		assert.Equal(t, "2024-03-01T12:00:00Z", record[12])
+	})
+
+	t.Run("Unrated article", func(t *testing.T) {
+		record := (&Article{ID: uuid.New(), UserID: userID}).ToExport().CSVRecord()
+		assert.Equal(t, "", record[9])
+		assert.Equal(t, "", record[10])
+		assert.Equal(t, "", record[11])
+	})
+}
+
+func TestWriteRSS(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	articles := []*Article{
+		{ID: uuid.New(), URL: "https://example.com/a?x=1&y=2", Title: "Fish & <Chips>", Description: "Tasty", CreatedAt: createdAt, Tags: []Tag{{Name: "food"}}},
+		{ID: uuid.New(), URL: "https://example.com/untitled", CreatedAt: createdAt.Add(-time.Hour)},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteRSS(&buf, &FeedInfo{Title: "Saved articles", SelfURL: "https://api.example.com/api/v1/articles/feed.xml"}, articles))
+	assert.Contains(t, buf.String(), `<atom:link href="https://api.example.com/api/v1/articles/feed.xml" rel="self" type="application/rss+xml"></atom:link>`)
+
+	// The output must be well-formed and round-trip through a feed parser
+	var doc rssDocument
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	require.Len(t, doc.Channel.Items, 2)
+
+	item := doc.Channel.Items[0]
+	assert.Equal(t, "Fish & <Chips>", item.Title)
+	assert.Equal(t, "https://example.com/a?x=1&y=2", item.Link)
+	assert.Equal(t, articles[0].ID.String(), item.GUID.Value)
+	assert.Equal(t, "Fri, 01 Mar 2024 12:00:00 +0000", item.PubDate)
+	assert.Equal(t, []string{"food"}, item.Categories)
+	assert.Equal(t, item.PubDate, doc.Channel.LastBuildDate)
+
+	// Articles without metadata are titled by their URL
+	assert.Equal(t, "https://example.com/untitled", doc.Channel.Items[1].Title)
+	assert.Nil(t, item.Enclosure)
+}
+
+func TestWriteAtom(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	articles := []*Article{
+		{ID: uuid.New(), URL: "https://example.com/a", Title: "Stored", SnapshotKey: "snapshots/a.html", CreatedAt: createdAt, UpdatedAt: createdAt, Tags: []Tag{{Name: "go"}}},
+		{ID: uuid.New(), URL: "https://example.com/b", Title: "Not stored", CreatedAt: createdAt, UpdatedAt: createdAt},
+	}
+	info := &FeedInfo{
+		Title:   "Reading list",
+		SelfURL: "https://api.example.com/api/v1/feeds/me.xml?format=atom",
+		EnclosureURL: func(article *Article) string {
+			return "https://api.example.com/api/v1/feeds/me/content/" + article.ID.String()
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteAtom(&buf, info, articles))
+
+	var feed atomFeed
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &feed))
+	assert.Equal(t, "Reading list", feed.Title)
+	assert.Equal(t, "2024-03-01T12:00:00Z", feed.Updated)
+	require.Len(t, feed.Entries, 2)
+
+	entry := feed.Entries[0]
+	assert.Equal(t, "urn:uuid:"+articles[0].ID.String(), entry.ID)
+	assert.Equal(t, []atomCategory{{Term: "go"}}, entry.Categories)
+	assert.Equal(t, []atomLink{
+		{Href: "https://example.com/a", Rel: "alternate"},
+		{Href: info.EnclosureURL(articles[0]), Rel: "enclosure", Type: "text/html"},
+	}, entry.Links)
+
+	// Only articles with a stored copy get an enclosure
+	assert.Len(t, feed.Entries[1].Links, 1)
+
+	buf.Reset()
+	require.NoError(t, WriteRSS(&buf, info, articles))
+	var doc rssDocument
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	require.NotNil(t, doc.Channel.Items[0].Enclosure)
+	assert.Equal(t, info.EnclosureURL(articles[0]), doc.Channel.Items[0].Enclosure.URL)
+	assert.Nil(t, doc.Channel.Items[1].Enclosure)
+}
+
+func TestAllowRefresh(t *testing.T) {
+	svc := &service{
+		refreshCooldown: utils.NewRateLimiter(1, time.Minute),
+		refreshDaily:    utils.NewRateLimiter(2, 24*time.Hour),
+	}
+	userID := uuid.New()
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+
+	require.NoError(t, svc.allowRefresh(first, userID))
+	assert.ErrorIs(t, svc.allowRefresh(first, userID), utils.ErrRateLimited, "article is cooling down")
+
+	require.NoError(t, svc.allowRefresh(second, userID))
+	assert.ErrorIs(t, svc.allowRefresh(third, userID), utils.ErrRateLimited, "daily limit reached")
+
+	// Limits are per user
+	require.NoError(t, svc.allowRefresh(uuid.New(), uuid.New()))
+}
+
+func TestDeleteArticles(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	kept, gone := uuid.New(), uuid.New()
+	repo := &batchDeleteRepository{deleted: []uuid.UUID{kept}}
+	svc := &service{repo: repo, logger: log}
+
+	response, err := svc.DeleteArticles(userID, &BatchDeleteRequest{IDs: []uuid.UUID{kept, gone, kept}})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{kept, gone}, repo.ids, "duplicate IDs are dropped")
+	assert.Equal(t, 1, response.Deleted)
+	assert.Equal(t, []*BatchDeleteResult{
+		{ID: kept, Status: BatchDeleteStatusDeleted},
+		{ID: gone, Status: BatchDeleteStatusNotFound},
+	}, response.Results)
+
+	response, err = svc.DeleteArticles(userID, &BatchDeleteRequest{Status: MetadataStatusFailed})
+	require.NoError(t, err)
+	assert.Equal(t, MetadataStatusFailed, repo.status)
+	assert.Equal(t, []*BatchDeleteResult{{ID: kept, Status: BatchDeleteStatusDeleted}}, response.Results)
+
+	_, err = svc.DeleteArticles(userID, &BatchDeleteRequest{})
+	assert.ErrorIs(t, err, utils.ErrValidation)
+	_, err = svc.DeleteArticles(userID, &BatchDeleteRequest{Status: "archived"})
+	assert.ErrorIs(t, err, utils.ErrValidation)
+}
+
+// batchDeleteRepository records batch deletes; other calls are not used
+type batchDeleteRepository struct {
+	Repository
+	deleted []uuid.UUID
+	ids     []uuid.UUID
+	status  string
+}
+
+func (r *batchDeleteRepository) DeleteBatch(userID uuid.UUID, ids []uuid.UUID, status string) ([]uuid.UUID, error) {
+	r.ids, r.status = ids, status
+	return r.deleted, nil
+}
+
+func TestValidateURLs(t *testing.T) {
+	blocked, err := parseBlockedDomains(" Example.ORG, ,bücher.de. ")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.org", "xn--bcher-kva.de"}, blocked)
+
+	repo := &existingURLsRepository{existing: map[string]bool{"https://saved.com/a": true}}
+	svc := &service{repo: repo, blockedDomains: blocked}
+
+	response, err := svc.ValidateURLs(uuid.New(), &ValidateURLsRequest{URLs: []string{
+		"https://fresh.com/a",
+		"https://SAVED.com/a#intro",
+		"https://fresh.com/a",
+		"https://news.example.org/story",
+		"http://127.0.0.1:8080/admin",
+		"http://10.0.0.5/",
+		"http://localhost/",
+		"ftp://fresh.com/file",
+	}})
+	require.NoError(t, err)
+
+	verdicts := make([]string, len(response.Results))
+	for i, result := range response.Results {
+		verdicts[i] = result.Verdict
+	}
+	assert.Equal(t, []string{
+		URLVerdictValid,
+		URLVerdictDuplicate,
+		URLVerdictDuplicate,
+		URLVerdictBlockedDomain,
+		URLVerdictPrivateIP,
+		URLVerdictPrivateIP,
+		URLVerdictPrivateIP,
+		URLVerdictInvalid,
+	}, verdicts)
+	assert.Equal(t, "https://saved.com/a", response.Results[1].NormalizedURL)
+	assert.Equal(t, map[string]int{"valid": 1, "duplicate": 2, "blocked_domain": 1, "private_ip": 3, "invalid": 1}, response.Summary)
+
+	// Saving is refused for the same reasons
+	_, err = svc.checkURL("https://example.org/")
+	assert.ErrorIs(t, err, ErrBlockedDomain)
+	_, err = svc.checkURL("https://notexample.org/")
+	assert.NoError(t, err, "only the domain and its subdomains are blocked")
+}
+
+// existingURLsRepository reports saved URLs; other calls are not used
+type existingURLsRepository struct {
+	Repository
+	existing map[string]bool
+}
+
+func (r *existingURLsRepository) FindExistingURLs(userID uuid.UUID, urls []string) (map[string]bool, error) {
+	found := make(map[string]bool)
+	for _, url := range urls {
+		if r.existing[url] {
+			found[url] = true
+		}
+	}
+	return found, nil
+}
+
+func TestCategorizeExtractionError(t *testing.T) {
+	cases := map[string]error{
+		FailureTimeout:        fmt.Errorf("failed to fetch HTML: %w", &url.Error{Op: "Get", URL: "https://example.com", Err: context.DeadlineExceeded}),
+		FailureNetwork:        fmt.Errorf("failed to fetch HTML: %w", &url.Error{Op: "Get", URL: "https://example.com", Err: &net.DNSError{Err: "no such host", Name: "example.com"}}),
+		FailureHTTPClient:     errors.New("failed to fetch HTML: HTTP 403: 403 Forbidden"),
+		FailureHTTPServer:     errors.New("failed to fetch HTML: HTTP 502: 502 Bad Gateway"),
+		FailureParse:          errors.New("readability parsing failed: unexpected EOF"),
+		FailureClassification: errors.New("ML classification failed"),
+		FailureOther:          errors.New("something else"),
+	}
+
+	for category, err := range cases {
+		assert.Equal(t, category, CategorizeExtractionError(err), err.Error())
+	}
+}
+
+func TestExtractMetadataRecordsFailure(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	article := &Article{ID: uuid.New(), URL: "https://example.com/post", MetadataStatus: MetadataStatusPending}
+	extractor := &stubExtractor{err: errors.New("failed to fetch HTML: HTTP 404: 404 Not Found")}
+	svc := &service{repo: &singleArticleRepository{article: article}, extractor: extractor, logger: log}
+
+	require.Error(t, svc.ExtractMetadata(article.ID))
+	assert.Equal(t, MetadataStatusFailed, article.MetadataStatus)
+	assert.Equal(t, "failed to fetch HTML: HTTP 404: 404 Not Found", article.LastError)
+	assert.Equal(t, FailureHTTPClient, article.ErrorCategory)
+	require.NotNil(t, article.LastErrorAt)
+	assert.NotEmpty(t, article.LastErrorRequestID, "extractions no request asked for get an ID of their own")
+	assert.Equal(t, article.LastErrorRequestID, extractor.requestID)
+
+	// Extractions a request asked for run under its ID
+	ctx := logger.ContextWithRequestID(context.Background(), "req-123")
+	require.Error(t, svc.runExtraction(ctx, article.ID))
+	assert.Equal(t, "req-123", article.LastErrorRequestID)
+	assert.Equal(t, "req-123", extractor.requestID)
+
+	// A later success clears the failure
+	extractor.err = nil
+	require.NoError(t, svc.ExtractMetadata(article.ID))
+	assert.Equal(t, MetadataStatusSuccess, article.MetadataStatus)
+	assert.Empty(t, article.LastError)
+	assert.Empty(t, article.ErrorCategory)
+	assert.Nil(t, article.LastErrorAt)
+	assert.Empty(t, article.LastErrorRequestID)
+}
+
+// singleArticleRepository stores one article; other calls are not used
+type singleArticleRepository struct {
+	Repository
+	article *Article
+}
+
+func (r *singleArticleRepository) FindByID(id uuid.UUID) (*Article, error) {
+	if id != r.article.ID {
+		return nil, ErrNotFound
+	}
+	return r.article, nil
+}
+
+func (r *singleArticleRepository) Update(article *Article) error {
+	r.article = article
+	return nil
+}
+
+type stubExtractor struct {
+	err       error
+	requestID string // Request ID of the last extraction
+}
+
+func (e *stubExtractor) Extract(ctx context.Context, url string) (*ExtractedMetadata, error) {
+	e.requestID = logger.RequestIDFromContext(ctx)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &ExtractedMetadata{Title: "Post", Content: "Body"}, nil
+}
+
+func TestContentRepository(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	_, err = ParseContentStore("disk")
+	assert.Error(t, err)
+	_, err = NewService(&config.ArticleConfig{ContentStore: "object"}, &copyingArticleRepository{}, nil, nil, nil, nil, log)
+	assert.Error(t, err, "object content store without storage backend")
+
+	// Content left in the column before the switch is moved out on the next update
+	stored := &Article{ID: uuid.New(), UserID: uuid.New(), Content: "Old body"}
+	rows := &copyingArticleRepository{article: stored}
+	objects := &memoryStorage{objects: map[string][]byte{}}
+	repo := newContentRepository(rows, objects, log)
+
+	article, err := repo.FindByID(stored.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Old body", article.Content)
+
+	article.Content = "New body"
+	require.NoError(t, repo.Update(article))
+	assert.Equal(t, "New body", article.Content, "caller keeps the content")
+	assert.Empty(t, rows.article.Content)
+	assert.Equal(t, contentKey(article), rows.article.ContentKey)
+	assert.Equal(t, "New body", string(objects.objects[contentKey(article)]))
+
+	article, err = repo.FindByID(stored.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "New body", article.Content)
+
+	// Lists read the row, which still counts the content embedding
+	row := *rows.article
+	row.MetadataStatus, row.EmbeddingStatus = MetadataStatusSuccess, EmbeddingStatusSuccess
+	assert.Equal(t, 66, row.ProcessingProgress())
+
+	// Unchanged content is not written again
+	objects.puts = 0
+	require.NoError(t, repo.Update(article))
+	assert.Zero(t, objects.puts)
+
+	// Metadata stays readable while the content is missing
+	delete(objects.objects, contentKey(article))
+	article, err = repo.FindByID(stored.ID)
+	require.NoError(t, err)
+	assert.Empty(t, article.Content)
+	require.NoError(t, repo.Update(article))
+	assert.Equal(t, contentKey(article), rows.article.ContentKey, "failed load does not drop the content")
+
+	// Clearing the content removes the object
+	objects.objects[contentKey(article)] = []byte("Body")
+	article, err = repo.FindByID(stored.ID)
+	require.NoError(t, err)
+	article.Content = ""
+	require.NoError(t, repo.Update(article))
+	assert.Empty(t, rows.article.ContentKey)
+	assert.Empty(t, objects.objects)
+}
+
+// copyingArticleRepository stores a copy of one article, like a table row
+type copyingArticleRepository struct {
+	Repository
+	article *Article
+}
+
+func (r *copyingArticleRepository) FindByID(id uuid.UUID) (*Article, error) {
+	if r.article == nil || id != r.article.ID {
+		return nil, ErrNotFound
+	}
+	row := *r.article
+	row.loadedContent = "" // Not a column
+	return &row, nil
+}
+
+func (r *copyingArticleRepository) Update(article *Article) error {
+	row := *article
+	r.article = &row
+	return nil
+}
+
+// memoryStorage keeps objects in memory
+type memoryStorage struct {
+	objects map[string][]byte
+	puts    int
+}
+
+func (s *memoryStorage) Put(key string, data []byte, contentType string) error {
+	s.objects[key] = data
+	s.puts++
+	return nil
+}
+
+func (s *memoryStorage) Get(key string) (*storage.Object, error) {
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &storage.Object{Data: data, ContentType: snapshotTextType}, nil
+}
+
+func (s *memoryStorage) Delete(key string) error {
+	delete(s.objects, key)
+	return nil
+}
+
+// fieldsRepository records the fields updated on its article
+type fieldsRepository struct {
+	singleArticleRepository
+	updates []map[string]any
+}
+
+func (r *fieldsRepository) UpdateFields(id uuid.UUID, fields map[string]any) error {
+	r.updates = append(r.updates, fields)
+	return nil
+}
+
+// favoritesRecorder records favorite changes
+type favoritesRecorder struct {
+	changes []bool
+}
+
+func (r *favoritesRecorder) FavoriteChanged(userID, articleID uuid.UUID, favorite bool) {
+	r.changes = append(r.changes, favorite)
+}
+
+func TestSetFavorite(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	repo := &fieldsRepository{singleArticleRepository: singleArticleRepository{article: &Article{ID: uuid.New(), UserID: userID}}}
+	listener := &favoritesRecorder{}
+	svc := &service{repo: repo, favorites: listener, logger: log}
+
+	article, err := svc.SetFavorite(repo.article.ID, userID, true)
+	require.NoError(t, err)
+	require.NotNil(t, article.FavoritedAt)
+	favoritedAt := *article.FavoritedAt
+
+	// Favoriting again keeps the first time and tells no one
+	article, err = svc.SetFavorite(repo.article.ID, userID, true)
+	require.NoError(t, err)
+	assert.Equal(t, favoritedAt, *article.FavoritedAt)
+	assert.Len(t, repo.updates, 1)
+
+	article, err = svc.SetFavorite(repo.article.ID, userID, false)
+	require.NoError(t, err)
+	assert.Nil(t, article.FavoritedAt)
+	assert.Equal(t, []bool{true, false}, listener.changes)
+
+	// Other users' articles are not found
+	_, err = svc.SetFavorite(repo.article.ID, uuid.New(), true)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
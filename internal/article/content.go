@@ -0,0 +1,125 @@
+package article
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/dustin/articles-backend/pkg/storage"
+	"github.com/google/uuid"
+)
+
+// Where the extracted text of articles is kept
+const (
+	ContentStoreDatabase = "database" // The content column of the articles table
+	ContentStoreObject   = "object"   // The configured object storage backend
+)
+
+// ParseContentStore validates a content store name; empty selects the database
+func ParseContentStore(value string) (string, error) {
+	switch store := strings.ToLower(strings.TrimSpace(value)); store {
+	case "":
+		return ContentStoreDatabase, nil
+	case ContentStoreDatabase, ContentStoreObject:
+		return store, nil
+	default:
+		return "", fmt.Errorf("invalid article content store '%s': must be one of database, object", value)
+	}
+}
+
+// contentKey is where the article's content is kept in object storage
+func contentKey(article *Article) string {
+	return "content/" + article.UserID.String() + "/" + article.ID.String() + ".txt"
+}
+
+// contentRepository keeps article content in object storage, so the articles
+// table only holds metadata. Articles read by ID get their content back; lists
+// leave it empty, as list responses do not include it. Content still found in
+// the content column is moved out the next time the article is updated.
+type contentRepository struct {
+	Repository
+	store  storage.Storage
+	logger *logger.Logger
+}
+
+func newContentRepository(repo Repository, store storage.Storage, log *logger.Logger) *contentRepository {
+	return &contentRepository{
+		Repository: repo,
+		store:      store,
+		logger:     log.WithComponent("article-content"),
+	}
+}
+
+func (r *contentRepository) Create(article *Article) error {
+	return r.withStoredContent(article, r.Repository.Create)
+}
+
+func (r *contentRepository) Update(article *Article) error {
+	return r.withStoredContent(article, r.Repository.Update)
+}
+
+// FindByID loads the article's content from object storage. Metadata stays
+// available while the storage is unreachable; the content then reads as empty.
+func (r *contentRepository) FindByID(id uuid.UUID) (*Article, error) {
+	article, err := r.Repository.FindByID(id)
+	if err != nil || article.ContentKey == "" {
+		return article, err
+	}
+
+	object, err := r.store.Get(article.ContentKey)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		r.logger.Error("Content of article " + id.String() + " is missing from " + article.ContentKey)
+	case err != nil:
+		r.logger.Error("Failed to load content of article " + id.String() + ": " + err.Error())
+	default:
+		article.Content = string(object.Data)
+		article.loadedContent = article.Content
+	}
+
+	return article, nil
+}
+
+// withStoredContent moves changed content to object storage and saves the
+// article without it. The article keeps its content for the caller.
+func (r *contentRepository) withStoredContent(article *Article, save func(*Article) error) error {
+	content := article.Content
+	if content != article.loadedContent {
+		if err := r.storeContent(article); err != nil {
+			return err
+		}
+	}
+
+	article.Content = ""
+	err := save(article)
+	article.Content = content
+	if err != nil {
+		return err
+	}
+
+	article.loadedContent = content
+	return nil
+}
+
+// storeContent writes the article's content to object storage, or removes it
+// once the article has no content anymore
+func (r *contentRepository) storeContent(article *Article) error {
+	if article.Content == "" {
+		// A leftover object is only reachable through the article, so it is harmless
+		if article.ContentKey != "" {
+			if err := r.store.Delete(article.ContentKey); err != nil {
+				r.logger.Error("Failed to delete content of article " + article.ID.String() + ": " + err.Error())
+			}
+			article.ContentKey = ""
+		}
+		return nil
+	}
+
+	key := contentKey(article)
+	if err := r.store.Put(key, []byte(article.Content), snapshotTextType); err != nil {
+		return fmt.Errorf("failed to store content of article %s: %w", article.ID, err)
+	}
+	article.ContentKey = key
+	return nil
+}
@@ -0,0 +1,47 @@
+package article
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Cursor marks a position in a user's article list, which is ordered newest
+// first by (created_at, id). Unlike an offset it stays stable while articles
+// are added, and the database seeks to it through the keyset index.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// EncodeCursor returns the opaque token for the position just after article
+func EncodeCursor(article *Article) string {
+	raw := article.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + article.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a token produced by EncodeCursor
+func DecodeCursor(token string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, utils.ErrInvalidCursor
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, utils.ErrInvalidCursor
+	}
+
+	cursor := &Cursor{}
+	if cursor.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, utils.ErrInvalidCursor
+	}
+	if cursor.ID, err = uuid.Parse(id); err != nil {
+		return nil, utils.ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
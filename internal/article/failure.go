@@ -0,0 +1,83 @@
+package article
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+)
+
+// Categories of metadata extraction failures, so failures sharing a cause
+// can be found together
+const (
+	FailureTimeout        = "timeout"        // The site did not answer in time
+	FailureNetwork        = "network"        // DNS, connection or TLS errors
+	FailureHTTPClient     = "http_4xx"       // The site refused the page, e.g. 403 or 404
+	FailureHTTPServer     = "http_5xx"       // The site failed to serve the page
+	FailureParse          = "parse"          // The page could not be parsed
+	FailureClassification = "classification" // The page could not be classified
+	FailureOther          = "other"
+)
+
+// maxLastErrorLength caps the stored failure message
+const maxLastErrorLength = 1000
+
+// httpStatusPattern finds the status the extractor reports for unsuccessful responses, e.g. "HTTP 404: 404 Not Found"
+var httpStatusPattern = regexp.MustCompile(`HTTP ([1-5])\d\d`)
+
+// CategorizeExtractionError tells which kind of problem made an extraction fail.
+// The extractor does not expose typed errors for every failure, so parse and
+// classification failures are recognized by their message.
+func CategorizeExtractionError(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return FailureTimeout
+	}
+
+	message := err.Error()
+	if match := httpStatusPattern.FindStringSubmatch(message); match != nil {
+		switch match[1] {
+		case "4":
+			return FailureHTTPClient
+		case "5":
+			return FailureHTTPServer
+		}
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) || errors.As(err, &netErr) {
+		return FailureNetwork
+	}
+
+	switch {
+	case strings.Contains(message, "parsing failed"):
+		return FailureParse
+	case strings.Contains(message, "classification failed"):
+		return FailureClassification
+	default:
+		return FailureOther
+	}
+}
+
+// recordFailure notes why the article's extraction failed and the request ID
+// it ran under
+func (a *Article) recordFailure(err error, at time.Time, requestID string) {
+	a.LastError = utils.SanitizeText(err.Error(), maxLastErrorLength)
+	a.ErrorCategory = CategorizeExtractionError(err)
+	a.LastErrorAt = &at
+	a.LastErrorRequestID = requestID
+}
+
+// clearFailure forgets the last failure once an extraction succeeded
+func (a *Article) clearFailure() {
+	a.LastError = ""
+	a.ErrorCategory = ""
+	a.LastErrorAt = nil
+	a.LastErrorRequestID = ""
+}
@@ -1,8 +1,13 @@
 package article
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/dustin/articles-backend/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -30,15 +35,15 @@ func (h *Handler) CreateArticle(c *gin.Context) {
 	}
 
 	// Extract user ID from JWT token
-	userID, err := utils.GetUserIDFromToken(c)
+	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 		return
 	}
 
-	article, err := h.service.CreateArticle(userID, req.URL)
+	article, err := h.service.CreateArticle(c.Request.Context(), userID, req.URL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create article"})
+		utils.RespondError(c, err, "Failed to create article")
 		return
 	}
 
@@ -48,7 +53,7 @@ func (h *Handler) CreateArticle(c *gin.Context) {
 // GetArticles handles getting user's articles with pagination
 func (h *Handler) GetArticles(c *gin.Context) {
 	// Extract user ID from JWT token
-	userID, err := utils.GetUserIDFromToken(c)
+	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 		return
@@ -69,16 +74,607 @@ func (h *Handler) GetArticles(c *gin.Context) {
 		}
 	}
 
-	articles, total, err := h.service.GetUserArticles(userID, page, limit)
+	// Parse optional filters
+	filter := &ArticleFilter{}
+	if tags := c.Query("tags"); tags != "" {
+		for _, tag := range strings.Split(tags, ",") {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				filter.Tags = append(filter.Tags, tag)
+			}
+		}
+	}
+	if m := c.Query("max_reading_time"); m != "" {
+		minutes, err := strconv.Atoi(m)
+		if err != nil || minutes <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "max_reading_time must be a positive number of minutes"})
+			return
+		}
+		filter.MaxReadingTime = minutes
+	}
+	if l := c.Query("language"); l != "" {
+		language, err := ParseLanguage(l)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "language must be an ISO 639-1 code such as en or zh"})
+			return
+		}
+		filter.Language = language
+	}
+	if u := c.Query("unread"); u != "" {
+		unread, err := strconv.ParseBool(u)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unread must be true or false"})
+			return
+		}
+		filter.Unread = unread
+	}
+	if f := c.Query("favorites"); f != "" {
+		favorites, err := strconv.ParseBool(f)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "favorites must be true or false"})
+			return
+		}
+		filter.Favorites = favorites
+	}
+
+	// A cursor parameter, empty for the first page, selects keyset pagination
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		articles, nextCursor, err := h.service.GetUserArticlesAfter(userID, filter, cursor, limit)
+		if err != nil {
+			utils.RespondError(c, err, "Failed to fetch articles")
+			return
+		}
+
+		response := BuildCursorResponse(articles, limit, nextCursor)
+		if includes(c, "progress") {
+			includeProgress(response.Articles, articles)
+		}
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	articles, total, err := h.service.GetUserArticles(userID, filter, page, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch articles"})
 		return
 	}
 
 	response := BuildPaginationResponse(articles, total, page, limit)
+	if includes(c, "progress") {
+		includeProgress(response.Articles, articles)
+	}
 	c.JSON(http.StatusOK, response)
 }
 
+// SearchArticles handles full-text search over the user's articles
+func (h *Handler) SearchArticles(c *gin.Context) {
+	// Extract user ID from JWT token
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	// Parse pagination parameters
+	page := 1
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	limit := 20
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+
+	query := c.Query("q")
+	results, total, err := h.service.SearchArticles(userID, query, page, limit)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to search articles")
+		return
+	}
+
+	response := BuildSearchResponse(query, results, total, page, limit)
+	if includes(c, "progress") {
+		for i, result := range results {
+			response.Results[i].IncludeProgress(result.Article)
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// includes reports whether the comma-separated include parameter asks for the
+// named optional part of the response
+func includes(c *gin.Context, name string) bool {
+	for _, part := range strings.Split(c.Query("include"), ",") {
+		if strings.TrimSpace(part) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// includeProgress adds processing progress to responses built from articles in the same order
+func includeProgress(responses []*ArticleResponse, articles []*Article) {
+	for i, article := range articles {
+		responses[i].IncludeProgress(article)
+	}
+}
+
+// ExportArticles streams all of the user's articles with metadata and ratings as JSON or CSV
+func (h *Handler) ExportArticles(c *gin.Context) {
+	// Extract user ID from JWT token
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	format := c.DefaultQuery("format", ExportFormatJSON)
+	if format != ExportFormatJSON && format != ExportFormatCSV {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be 'json' or 'csv'"})
+		return
+	}
+
+	if format == ExportFormatCSV {
+		c.Header("Content-Type", "text/csv")
+	} else {
+		c.Header("Content-Type", "application/json")
+	}
+	c.Header("Content-Disposition", "attachment; filename=articles."+format)
+	c.Status(http.StatusOK)
+
+	if format == ExportFormatCSV {
+		writer := csv.NewWriter(c.Writer)
+		err = writer.Write(ExportCSVHeader)
+		if err == nil {
+			err = h.service.ExportArticles(userID, func(article *Article) error {
+				return writer.Write(article.ToExport().CSVRecord())
+			})
+		}
+		writer.Flush()
+	} else {
+		// Write the array element by element so the export is never held in memory
+		encoder := json.NewEncoder(c.Writer)
+		separator := ""
+		_, err = c.Writer.WriteString("[")
+		if err == nil {
+			err = h.service.ExportArticles(userID, func(article *Article) error {
+				if _, err := c.Writer.WriteString(separator); err != nil {
+					return err
+				}
+				separator = ","
+				return encoder.Encode(article.ToExport())
+			})
+		}
+		if err == nil {
+			_, err = c.Writer.WriteString("]")
+		}
+	}
+
+	if err != nil {
+		// Headers are already sent; abort the stream so clients see a truncated download
+		c.Error(err)
+		c.Abort()
+	}
+}
+
+// GetArticle handles fetching a single article owned by the user
+func (h *Handler) GetArticle(c *gin.Context) {
+	// Parse article ID from URL
+	idParam := c.Param("id")
+	articleID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	// Extract user ID from JWT token
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	article, err := h.service.GetArticle(articleID, userID)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to fetch article")
+		return
+	}
+
+	response := article.ToDetailResponse()
+	if includes(c, "progress") {
+		response.IncludeProgress(article)
+	}
+
+	// Reader clients can fetch annotations along with the content in one request
+	if includes(c, "highlights") {
+		highlights, err := h.service.GetHighlights(articleID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch highlights"})
+			return
+		}
+		response.Highlights = highlights
+		if response.Highlights == nil {
+			response.Highlights = []*Highlight{}
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateArticle handles partial updates of title, description and notes
+func (h *Handler) UpdateArticle(c *gin.Context) {
+	// Parse article ID from URL
+	idParam := c.Param("id")
+	articleID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	var req UpdateArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Extract user ID from JWT token
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	article, err := h.service.UpdateArticleFields(articleID, userID, &req)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to update article")
+		return
+	}
+
+	c.JSON(http.StatusOK, article.ToDetailResponse())
+}
+
+// MarkRead handles moving an article out of the unread queue
+func (h *Handler) MarkRead(c *gin.Context) {
+	h.setRead(c, true)
+}
+
+// MarkUnread handles moving an article back into the unread queue
+func (h *Handler) MarkUnread(c *gin.Context) {
+	h.setRead(c, false)
+}
+
+func (h *Handler) setRead(c *gin.Context, read bool) {
+	articleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	article, err := h.service.MarkRead(articleID, userID, read)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to update read state")
+		return
+	}
+
+	c.JSON(http.StatusOK, article.ToResponse())
+}
+
+// Favorite handles marking an article a favorite
+func (h *Handler) Favorite(c *gin.Context) {
+	h.setFavorite(c, true)
+}
+
+// Unfavorite handles unmarking a favorite article
+func (h *Handler) Unfavorite(c *gin.Context) {
+	h.setFavorite(c, false)
+}
+
+func (h *Handler) setFavorite(c *gin.Context, favorite bool) {
+	articleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	article, err := h.service.SetFavorite(articleID, userID, favorite)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to update favorite state")
+		return
+	}
+
+	c.JSON(http.StatusOK, article.ToResponse())
+}
+
+// RefreshMetadata handles re-fetching an article's metadata on demand
+func (h *Handler) RefreshMetadata(c *gin.Context) {
+	// Parse article ID from URL
+	idParam := c.Param("id")
+	articleID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	// Extract user ID from JWT token
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	article, err := h.service.RefreshMetadata(c.Request.Context(), articleID, userID)
+	if err != nil {
+		if errors.Is(err, ErrMetadataExtraction) {
+			// The source site could not be fetched or parsed; the retry worker will try again
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to extract metadata", "details": err.Error()})
+		} else {
+			utils.RespondError(c, err, "Failed to refresh metadata")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, article.ToDetailResponse())
+}
+
+// GetContent handles serving the stored copy of an article's page
+func (h *Handler) GetContent(c *gin.Context) {
+	// Parse article ID from URL
+	idParam := c.Param("id")
+	articleID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	// Extract user ID from JWT token
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	content, err := h.service.GetContent(articleID, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrContentNotAvailable) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Content not available"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch content"})
+		}
+		return
+	}
+
+	// Snapshots are third-party markup served from our origin; sandbox them so
+	// they cannot run scripts or act on behalf of the user
+	c.Header("Content-Security-Policy", "sandbox; default-src 'none'; img-src * data:; style-src 'unsafe-inline'")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, content.ContentType, content.Data)
+}
+
+// GetHighlights handles listing the highlights of an article
+func (h *Handler) GetHighlights(c *gin.Context) {
+	userID, articleID, ok := h.highlightTarget(c)
+	if !ok {
+		return
+	}
+
+	highlights, err := h.service.GetHighlights(articleID, userID)
+	if err != nil {
+		h.highlightError(c, err, "Failed to fetch highlights")
+		return
+	}
+	if highlights == nil {
+		highlights = []*Highlight{}
+	}
+
+	c.JSON(http.StatusOK, &HighlightListResponse{ArticleID: articleID, Highlights: highlights})
+}
+
+// CreateHighlight handles highlighting a passage of an article's content
+func (h *Handler) CreateHighlight(c *gin.Context) {
+	var req CreateHighlightRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, articleID, ok := h.highlightTarget(c)
+	if !ok {
+		return
+	}
+
+	highlight, err := h.service.CreateHighlight(articleID, userID, &req)
+	if err != nil {
+		h.highlightError(c, err, "Failed to create highlight")
+		return
+	}
+
+	c.JSON(http.StatusCreated, highlight)
+}
+
+// UpdateHighlight handles changing the note of a highlight
+func (h *Handler) UpdateHighlight(c *gin.Context) {
+	var req UpdateHighlightRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, articleID, ok := h.highlightTarget(c)
+	if !ok {
+		return
+	}
+	highlightID, err := uuid.Parse(c.Param("highlightId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid highlight ID"})
+		return
+	}
+
+	highlight, err := h.service.UpdateHighlight(articleID, highlightID, userID, &req)
+	if err != nil {
+		h.highlightError(c, err, "Failed to update highlight")
+		return
+	}
+
+	c.JSON(http.StatusOK, highlight)
+}
+
+// DeleteHighlight handles removing a highlight
+func (h *Handler) DeleteHighlight(c *gin.Context) {
+	userID, articleID, ok := h.highlightTarget(c)
+	if !ok {
+		return
+	}
+	highlightID, err := uuid.Parse(c.Param("highlightId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid highlight ID"})
+		return
+	}
+
+	if err := h.service.DeleteHighlight(articleID, highlightID, userID); err != nil {
+		h.highlightError(c, err, "Failed to delete highlight")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Highlight deleted successfully"})
+}
+
+// highlightTarget extracts the user and article of a highlight request, writing the error response on failure
+func (h *Handler) highlightTarget(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	articleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	// Extract user ID from JWT token
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, articleID, true
+}
+
+func (h *Handler) highlightError(c *gin.Context, err error, message string) {
+	// Highlights anchor into the extracted text, so missing content is a conflict here rather than a 404
+	if errors.Is(err, ErrContentNotAvailable) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Article content has not been extracted yet"})
+		return
+	}
+	utils.RespondError(c, err, message)
+}
+
+// AddTags handles attaching tags to an article
+func (h *Handler) AddTags(c *gin.Context) {
+	// Parse article ID from URL
+	idParam := c.Param("id")
+	articleID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	var req TagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Extract user ID from JWT token
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	article, err := h.service.AddTags(articleID, userID, req.Tags)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to add tags")
+		return
+	}
+
+	c.JSON(http.StatusOK, article.ToResponse())
+}
+
+// RemoveTag handles detaching a tag from an article
+func (h *Handler) RemoveTag(c *gin.Context) {
+	// Parse article ID from URL
+	idParam := c.Param("id")
+	articleID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	// Extract user ID from JWT token
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	err = h.service.RemoveTag(articleID, userID, c.Param("tag"))
+	if err != nil {
+		utils.RespondError(c, err, "Failed to remove tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tag removed successfully"})
+}
+
+// GetTags handles listing all tags of the user
+func (h *Handler) GetTags(c *gin.Context) {
+	// Extract user ID from JWT token
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	tags, err := h.service.GetUserTags(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags, "count": len(tags)})
+}
+
+// GetStats handles retrieval of library statistics
+func (h *Handler) GetStats(c *gin.Context) {
+	// Extract user ID from JWT token
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	stats, err := h.service.GetStats(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch article statistics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
 // DeleteArticle handles article deletion
 func (h *Handler) DeleteArticle(c *gin.Context) {
 	// Parse article ID from URL
@@ -90,7 +686,7 @@ func (h *Handler) DeleteArticle(c *gin.Context) {
 	}
 
 	// Extract user ID from JWT token
-	userID, err := utils.GetUserIDFromToken(c)
+	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 		return
@@ -98,25 +694,265 @@ func (h *Handler) DeleteArticle(c *gin.Context) {
 
 	err = h.service.DeleteArticle(articleID, userID)
 	if err != nil {
-		if err.Error() == "article not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete article"})
-		}
+		utils.RespondError(c, err, "Failed to delete article")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Article deleted successfully"})
 }
 
+// ValidateURLs handles checking URLs before saving them, e.g. to preview an import
+func (h *Handler) ValidateURLs(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	var req ValidateURLsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.service.ValidateURLs(userID, &req)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to validate URLs")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteArticles handles moving several articles to the trash at once
+func (h *Handler) DeleteArticles(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	var req BatchDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.service.DeleteArticles(userID, &req)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to delete articles")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTrash handles listing the user's deleted articles, most recently deleted first
+func (h *Handler) GetTrash(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	page := 1
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	limit := 20
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+
+	articles, total, err := h.service.GetTrash(userID, page, limit)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to fetch trash")
+		return
+	}
+
+	c.JSON(http.StatusOK, BuildPaginationResponse(articles, total, page, limit))
+}
+
+// RestoreArticle handles moving an article out of the trash
+func (h *Handler) RestoreArticle(c *gin.Context) {
+	articleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	article, err := h.service.RestoreArticle(articleID, userID)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to restore article")
+		return
+	}
+
+	c.JSON(http.StatusOK, article.ToResponse())
+}
+
+// GetRSSFeed handles rendering the user's newest articles as an RSS feed
+func (h *Handler) GetRSSFeed(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+
+	filter := &ArticleFilter{}
+	if tags := c.Query("tags"); tags != "" {
+		for _, tag := range strings.Split(tags, ",") {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				filter.Tags = append(filter.Tags, tag)
+			}
+		}
+	}
+
+	articles, _, err := h.service.GetUserArticles(userID, filter, 1, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch articles"})
+		return
+	}
+
+	// The self link leaves out the query so the token is not repeated in the document
+	info := &FeedInfo{
+		Title:       "Saved articles",
+		Description: "Articles saved to your reading list",
+		SelfURL:     utils.RequestBaseURL(c) + c.Request.URL.Path,
+	}
+
+	c.Header("Content-Type", "application/rss+xml; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := WriteRSS(c.Writer, info, articles); err != nil {
+		// Headers are already sent; abort so the reader sees a truncated feed
+		c.Error(err)
+		c.Abort()
+	}
+}
+
+// GetReadingListFeed handles rendering the user's unread queue as an RSS or
+// Atom feed. Articles with a stored copy link it as an enclosure.
+func (h *Handler) GetReadingListFeed(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "rss")
+	if format != "rss" && format != "atom" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be rss or atom"})
+		return
+	}
+
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+
+	articles, _, err := h.service.GetUserArticles(userID, &ArticleFilter{Unread: true}, 1, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch articles"})
+		return
+	}
+
+	baseURL := utils.RequestBaseURL(c)
+	info := &FeedInfo{
+		Title:       "Reading list",
+		Description: "Articles you have not read yet",
+		SelfURL:     baseURL + c.Request.URL.Path,
+	}
+	if format == "atom" {
+		info.SelfURL += "?format=atom"
+	}
+
+	// Enclosures are fetched by the feed reader, so they carry the token the
+	// feed was requested with; a reader sending a header sends it for them too
+	contentPath := baseURL + strings.TrimSuffix(c.Request.URL.Path, ".xml") + "/content/"
+	tokenQuery := ""
+	if token := c.Query("token"); token != "" {
+		tokenQuery = "?token=" + url.QueryEscape(token)
+	}
+	info.EnclosureURL = func(article *Article) string {
+		return contentPath + article.ID.String() + tokenQuery
+	}
+
+	if format == "atom" {
+		c.Header("Content-Type", "application/atom+xml; charset=utf-8")
+		c.Status(http.StatusOK)
+		err = WriteAtom(c.Writer, info, articles)
+	} else {
+		c.Header("Content-Type", "application/rss+xml; charset=utf-8")
+		c.Status(http.StatusOK)
+		err = WriteRSS(c.Writer, info, articles)
+	}
+	if err != nil {
+		// Headers are already sent; abort so the reader sees a truncated feed
+		c.Error(err)
+		c.Abort()
+	}
+}
+
 // RegisterRoutes registers all article routes
 func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	// Feed readers cannot send headers, so the feed also takes the token as a query parameter
+	router.GET("/articles/feed.xml", utils.TokenFromQuery("token"), authMiddleware, h.GetRSSFeed)
+	router.GET("/feeds/me.xml", utils.TokenFromQuery("token"), authMiddleware, h.GetReadingListFeed)
+	router.GET("/feeds/me/content/:id", utils.TokenFromQuery("token"), authMiddleware, h.GetContent)
+
 	// All article routes require authentication
 	articles := router.Group("/articles")
 	articles.Use(authMiddleware)
 	{
 		articles.POST("", h.CreateArticle)
 		articles.GET("", h.GetArticles)
+		articles.DELETE("", h.DeleteArticles)
+		articles.POST("/validate", h.ValidateURLs)
+		articles.GET("/search", h.SearchArticles)
+		articles.GET("/export", h.ExportArticles)
+		articles.GET("/stats", h.GetStats)
+		articles.GET("/trash", h.GetTrash)
+		articles.GET("/:id", h.GetArticle)
+		articles.PATCH("/:id", h.UpdateArticle)
+		articles.POST("/:id/refresh", h.RefreshMetadata)
+		articles.POST("/:id/refresh-metadata", h.RefreshMetadata)
+		articles.GET("/:id/content", h.GetContent)
+		articles.POST("/:id/read", h.MarkRead)
+		articles.DELETE("/:id/read", h.MarkUnread)
+		articles.POST("/:id/favorite", h.Favorite)
+		articles.DELETE("/:id/favorite", h.Unfavorite)
+		articles.POST("/:id/tags", h.AddTags)
+		articles.DELETE("/:id/tags/:tag", h.RemoveTag)
+		articles.GET("/:id/highlights", h.GetHighlights)
+		articles.POST("/:id/highlights", h.CreateHighlight)
+		articles.PATCH("/:id/highlights/:highlightId", h.UpdateHighlight)
+		articles.DELETE("/:id/highlights/:highlightId", h.DeleteHighlight)
 		articles.DELETE("/:id", h.DeleteArticle)
+		articles.POST("/:id/restore", h.RestoreArticle)
+	}
+
+	tags := router.Group("/tags")
+	tags.Use(authMiddleware)
+	{
+		tags.GET("", h.GetTags)
 	}
 }
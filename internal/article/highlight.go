@@ -0,0 +1,64 @@
+package article
+
+import (
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Highlight marks a passage of an article's extracted content, optionally with a note.
+// Offsets count Unicode characters (runes) of Article.Content; Text keeps the
+// quoted passage so clients can re-anchor it if a refresh changes the content.
+type Highlight struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ArticleID   uuid.UUID `json:"article_id" gorm:"type:uuid;not null;index:idx_article_highlights"`
+	UserID      uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	StartOffset int       `json:"start_offset" gorm:"not null"`
+	EndOffset   int       `json:"end_offset" gorm:"not null"`
+	Text        string    `json:"text" gorm:"type:text;not null"`
+	Note        string    `json:"note" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Associations
+	Article *Article `json:"-" gorm:"foreignKey:ArticleID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for GORM
+func (Highlight) TableName() string {
+	return "highlights"
+}
+
+// CreateHighlightRequest represents highlight creation request
+type CreateHighlightRequest struct {
+	StartOffset *int   `json:"start_offset" binding:"required"`
+	EndOffset   *int   `json:"end_offset" binding:"required"`
+	Note        string `json:"note"`
+}
+
+// UpdateHighlightRequest represents a change of a highlight's note
+type UpdateHighlightRequest struct {
+	Note *string `json:"note" binding:"required"`
+}
+
+// HighlightListResponse lists the highlights of an article
+type HighlightListResponse struct {
+	ArticleID  uuid.UUID    `json:"article_id"`
+	Highlights []*Highlight `json:"highlights"`
+}
+
+// quoteRange returns the passage of content between the rune offsets, validating the range
+func quoteRange(content string, start, end int) (string, error) {
+	runes := []rune(content)
+	if start < 0 {
+		return "", utils.NewValidationError("start_offset", "must not be negative")
+	}
+	if end <= start {
+		return "", utils.NewValidationError("end_offset", "must be greater than start_offset")
+	}
+	if end > len(runes) {
+		return "", utils.NewValidationError("end_offset", "must not exceed the content length of "+utils.IntToString(len(runes))+" characters")
+	}
+	return string(runes[start:end]), nil
+}
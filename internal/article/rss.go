@@ -0,0 +1,202 @@
+package article
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// FeedInfo describes a feed of articles rendered by WriteRSS or WriteAtom
+type FeedInfo struct {
+	Title       string
+	Description string
+	SelfURL     string // The feed's own URL
+	// EnclosureURL returns where an article's stored copy can be fetched, or
+	// "" to leave it out. Nil renders no enclosures.
+	EnclosureURL func(article *Article) string
+}
+
+// snapshotEnclosureType is announced for stored copies; plain-text ones are
+// rare, and feed readers only use the type to pick a viewer
+const snapshotEnclosureType = "text/html"
+
+// RSS 2.0 document rendered for a user's saved articles
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	SelfLink      rssLink   `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+// rssLink is the channel's atom:link to its own URL, as feed validators expect
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        rssGUID       `xml:"guid"`
+	Description string        `xml:"description,omitempty"`
+	PubDate     string        `xml:"pubDate"`
+	Categories  []string      `xml:"category"`
+	Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// rssEnclosure links the stored copy of an article. Its size is not known
+// without fetching it, and 0 is the customary value for that.
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int    `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// WriteRSS renders articles as an RSS 2.0 feed whose items link to the saved
+// pages. Items are dated by when they were saved.
+func WriteRSS(w io.Writer, info *FeedInfo, articles []*Article) error {
+	channel := rssChannel{
+		Title:       info.Title,
+		Link:        info.SelfURL,
+		Description: info.Description,
+		SelfLink:    rssLink{Href: info.SelfURL, Rel: "self", Type: "application/rss+xml"},
+		Items:       make([]rssItem, len(articles)),
+	}
+	if len(articles) > 0 {
+		channel.LastBuildDate = articles[0].CreatedAt.UTC().Format(time.RFC1123Z)
+	}
+
+	for i, article := range articles {
+		channel.Items[i] = rssItem{
+			Title:       feedEntryTitle(article),
+			Link:        article.URL,
+			GUID:        rssGUID{Value: article.ID.String()},
+			Description: article.Description,
+			PubDate:     article.CreatedAt.UTC().Format(time.RFC1123Z),
+			Categories:  tagNames(article),
+		}
+		if url := info.enclosureURL(article); url != "" {
+			channel.Items[i].Enclosure = &rssEnclosure{URL: url, Type: snapshotEnclosureType}
+		}
+	}
+
+	return writeXML(w, rssDocument{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: channel,
+	})
+}
+
+// Atom 1.0 document rendered for a user's saved articles
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Links      []atomLink     `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Summary    string         `xml:"summary,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// WriteAtom renders articles as an Atom 1.0 feed, the counterpart of WriteRSS
+func WriteAtom(w io.Writer, info *FeedInfo, articles []*Article) error {
+	feed := atomFeed{
+		ID:       info.SelfURL,
+		Title:    info.Title,
+		Subtitle: info.Description,
+		Updated:  time.Now().UTC().Format(time.RFC3339),
+		Links:    []atomLink{{Href: info.SelfURL, Rel: "self", Type: "application/atom+xml"}},
+		Entries:  make([]atomEntry, len(articles)),
+	}
+	if len(articles) > 0 {
+		feed.Updated = articles[0].CreatedAt.UTC().Format(time.RFC3339)
+	}
+
+	for i, article := range articles {
+		entry := atomEntry{
+			ID:        "urn:uuid:" + article.ID.String(),
+			Title:     feedEntryTitle(article),
+			Links:     []atomLink{{Href: article.URL, Rel: "alternate"}},
+			Published: article.CreatedAt.UTC().Format(time.RFC3339),
+			Updated:   article.UpdatedAt.UTC().Format(time.RFC3339),
+			Summary:   article.Description,
+		}
+		for _, name := range tagNames(article) {
+			entry.Categories = append(entry.Categories, atomCategory{Term: name})
+		}
+		if url := info.enclosureURL(article); url != "" {
+			entry.Links = append(entry.Links, atomLink{Href: url, Rel: "enclosure", Type: snapshotEnclosureType})
+		}
+		feed.Entries[i] = entry
+	}
+
+	return writeXML(w, feed)
+}
+
+// enclosureURL returns the enclosure of an article with a stored copy, if any
+func (info *FeedInfo) enclosureURL(article *Article) string {
+	if info.EnclosureURL == nil || article.SnapshotKey == "" {
+		return ""
+	}
+	return info.EnclosureURL(article)
+}
+
+// feedEntryTitle falls back to the URL for articles whose metadata is not extracted yet
+func feedEntryTitle(article *Article) string {
+	if article.Title == "" {
+		return article.URL
+	}
+	return article.Title
+}
+
+func tagNames(article *Article) []string {
+	names := make([]string, len(article.Tags))
+	for i, tag := range article.Tags {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+func writeXML(w io.Writer, document any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(document)
+}
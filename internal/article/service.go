@@ -1,33 +1,126 @@
 package article
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/dustin/articles-backend/config"
 	"github.com/dustin/articles-backend/internal/utils"
 	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/dustin/articles-backend/pkg/storage"
 	"github.com/google/uuid"
 )
 
+// maxTagLength matches the size of the tags.name column
+const maxTagLength = 50
+
+// Media types of stored snapshots
+const (
+	snapshotHTMLType = "text/html; charset=utf-8"
+	snapshotTextType = "text/plain; charset=utf-8"
+)
+
+// maxSearchQueryLength bounds the full-text query to keep tsquery parsing cheap
+const maxSearchQueryLength = 200
+
+const (
+	// trashRetention is how long deleted articles can be restored before they are purged
+	trashRetention = 30 * 24 * time.Hour
+	// purgeBatchSize bounds the articles loaded per purge query
+	purgeBatchSize = 500
+)
+
 // service implements the Service interface
 type service struct {
 	repo      Repository
 	extractor MetadataExtractor
+	snapshots storage.Storage   // Nil when object storage is disabled
+	queue     ExtractionQueue   // Nil to extract on a goroutine per article
+	favorites FavoritesListener // Nil when nothing follows favorites
 	logger    *logger.Logger
+
+	blockedDomains []string // Saving links to these domains or their subdomains is refused
+
+	// Limits on refreshes requested by users
+	refreshCooldown *utils.RateLimiter // Per article
+	refreshDaily    *utils.RateLimiter // Per user
 }
 
-// NewService creates a new article service
-func NewService(repo Repository, extractor MetadataExtractor, log *logger.Logger) Service {
-	return &service{
-		repo:      repo,
-		extractor: extractor,
-		logger:    log.WithComponent("article-service"),
+// NewService creates a new article service with validation and defaults.
+// snapshots may be nil, in which case extracted pages are not copied to object
+// storage; the content store "object" keeps article content there as well.
+// queue may be nil, in which case each extraction starts right away on its own
+// goroutine; that is meant for tests, as imports then run all their extractions
+// at once. favorites may be nil.
+func NewService(cfg *config.ArticleConfig, repo Repository, extractor MetadataExtractor, snapshots storage.Storage, queue ExtractionQueue, favorites FavoritesListener, log *logger.Logger) (Service, error) {
+	cooldown := 10 * time.Minute
+	if cfg != nil && cfg.RefreshCooldown != "" {
+		parsed, err := time.ParseDuration(cfg.RefreshCooldown)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid article refresh cooldown '%s': must be a positive duration", cfg.RefreshCooldown)
+		}
+		cooldown = parsed
+	}
+
+	dailyLimit := 20
+	if cfg != nil && cfg.RefreshDailyLimit != "" {
+		parsed, err := strconv.Atoi(cfg.RefreshDailyLimit)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid article refresh daily limit '%s': must be a positive integer", cfg.RefreshDailyLimit)
+		}
+		dailyLimit = parsed
+	}
+
+	var blockedDomains []string
+	if cfg != nil {
+		var err error
+		if blockedDomains, err = parseBlockedDomains(cfg.BlockedDomains); err != nil {
+			return nil, err
+		}
+	}
+
+	contentStore := ContentStoreDatabase
+	if cfg != nil {
+		var err error
+		if contentStore, err = ParseContentStore(cfg.ContentStore); err != nil {
+			return nil, err
+		}
 	}
+	if contentStore == ContentStoreObject {
+		if snapshots == nil {
+			return nil, fmt.Errorf("invalid article content store '%s': requires a storage backend", cfg.ContentStore)
+		}
+		repo = newContentRepository(repo, snapshots, log)
+	}
+
+	return &service{
+		repo:            repo,
+		extractor:       extractor,
+		snapshots:       snapshots,
+		queue:           queue,
+		favorites:       favorites,
+		logger:          log.WithComponent("article-service"),
+		blockedDomains:  blockedDomains,
+		refreshCooldown: utils.NewRateLimiter(1, cooldown),
+		refreshDaily:    utils.NewRateLimiter(dailyLimit, 24*time.Hour),
+	}, nil
 }
 
-func (s *service) CreateArticle(userID uuid.UUID, url string) (*Article, error) {
+func (s *service) CreateArticle(ctx context.Context, userID uuid.UUID, url string) (*Article, error) {
 	s.logger.Info("Creating article for user " + userID.String() + ": " + url)
 
+	// Normalize and validate the user-supplied URL
+	url, err := s.checkURL(url)
+	if err != nil {
+		s.logger.Info("Rejected article URL for user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
 	// Create article with pending metadata
 	article := &Article{
 		ID:             uuid.New(),
@@ -40,18 +133,14 @@ func (s *service) CreateArticle(userID uuid.UUID, url string) (*Article, error)
 	}
 
 	// Save to database
-	err := s.repo.Create(article)
+	err = s.repo.Create(article)
 	if err != nil {
 		s.logger.Error("Failed to create article for user " + userID.String() + " URL " + url + ": " + err.Error())
 		return nil, err
 	}
 
-	// Asynchronously extract metadata
-	go func() {
-		if err := s.ExtractMetadata(article.ID); err != nil {
-			s.logger.Error("Failed to extract metadata for article " + article.ID.String() + " URL " + url + ": " + err.Error())
-		}
-	}()
+	// Extract metadata in the background, ahead of imports and retries
+	s.scheduleExtraction(ctx, article.ID, ExtractionPriorityInteractive)
 
 	s.logger.Info("Article created successfully: " + article.ID.String() + " for user " + userID.String() + " URL " + url)
 
@@ -66,13 +155,13 @@ func (s *service) GetArticle(id uuid.UUID, userID uuid.UUID) (*Article, error) {
 
 	// Verify ownership
 	if !article.IsOwnedBy(userID) {
-		return nil, errors.New("article not found")
+		return nil, ErrNotFound
 	}
 
 	return article, nil
 }
 
-func (s *service) GetUserArticles(userID uuid.UUID, page, limit int) ([]*Article, int64, error) {
+func (s *service) GetUserArticles(userID uuid.UUID, filter *ArticleFilter, page, limit int) ([]*Article, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -85,37 +174,90 @@ func (s *service) GetUserArticles(userID uuid.UUID, page, limit int) ([]*Article
 	s.logger.Info("Fetching user articles for " + userID.String() + " (page " + utils.IntToString(page) + ", limit " + utils.IntToString(limit) + ", offset " + utils.IntToString(offset) + ")")
 
 	// Get articles with ratings for better response
-	articles, err := s.repo.FindByUserIDWithRatings(userID, offset, limit)
+	articles, err := s.repo.FindByUserIDWithRatings(userID, filter, offset, limit)
 	if err != nil {
 		s.logger.Error("Failed to fetch user articles for " + userID.String() + ": " + err.Error())
 		return nil, 0, err
 	}
 
-	// Get total count for pagination
-	// This is a simplified approach - in production, you might want a separate count query
-	allArticles, err := s.repo.FindByUserID(userID, 0, 10000) // Get all for count
+	// Get total count for pagination, with the same filter as the page
+	total, err := s.repo.CountByUserID(userID, filter)
 	if err != nil {
-		return articles, 0, nil // Return articles even if count fails
+		s.logger.Error("Failed to count user articles for " + userID.String() + ": " + err.Error())
+		return nil, 0, err
 	}
-	total := int64(len(allArticles))
 
 	return articles, total, nil
 }
 
+func (s *service) GetUserArticlesAfter(userID uuid.UUID, filter *ArticleFilter, cursor string, limit int) ([]*Article, string, error) {
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	var after *Cursor
+	if cursor != "" {
+		decoded, err := DecodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		after = decoded
+	}
+
+	// Fetch one extra article to learn whether another page follows
+	articles, err := s.repo.FindByUserIDAfter(userID, filter, after, limit+1)
+	if err != nil {
+		s.logger.Error("Failed to fetch user articles by cursor for " + userID.String() + ": " + err.Error())
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(articles) > limit {
+		articles = articles[:limit]
+		nextCursor = EncodeCursor(articles[limit-1])
+	}
+
+	return articles, nextCursor, nil
+}
+
+func (s *service) GetStats(userID uuid.UUID) (*ArticleStatsResponse, error) {
+	totals, err := s.repo.CountTotals(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	domains, err := s.repo.CountByDomain(userID, statsDomainLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := s.repo.CountByMetadataStatus(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ratings, err := s.repo.CountRatingsByScore(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildStatsResponse(totals, domains, statuses, ratings), nil
+}
+
 func (s *service) DeleteArticle(id uuid.UUID, userID uuid.UUID) error {
 	s.logger.Info("Deleting article " + id.String() + " for user " + userID.String())
 
 	// First verify ownership
 	article, err := s.repo.FindByID(id)
 	if err != nil {
-		return errors.New("article not found")
+		return err
 	}
 
 	if !article.IsOwnedBy(userID) {
-		return errors.New("article not found")
+		return ErrNotFound
 	}
 
-	// Delete the article
+	// Move the article to the trash; its snapshot is kept until it is purged
 	err = s.repo.Delete(id)
 	if err != nil {
 		s.logger.Error("Failed to delete article " + id.String() + " for user " + userID.String() + ": " + err.Error())
@@ -127,27 +269,558 @@ func (s *service) DeleteArticle(id uuid.UUID, userID uuid.UUID) error {
 	return nil
 }
 
-func (s *service) UpdateMetadata(id uuid.UUID, title, description, content string, wordCount int, confidence float64) error {
+func (s *service) MarkRead(id uuid.UUID, userID uuid.UUID, read bool) (*Article, error) {
+	article, err := s.GetArticle(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Marking a read article as read again keeps when it was first read
+	if read == (article.ReadAt != nil) {
+		return article, nil
+	}
+
+	var readAt *time.Time
+	if read {
+		now := time.Now()
+		readAt = &now
+	}
+	if err := s.repo.UpdateFields(id, map[string]any{"read_at": readAt}); err != nil {
+		s.logger.Error("Failed to update read state of article " + id.String() + " for user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	article.ReadAt = readAt
+	return article, nil
+}
+
+func (s *service) SetFavorite(id uuid.UUID, userID uuid.UUID, favorite bool) (*Article, error) {
+	article, err := s.GetArticle(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Favoriting an article again keeps when it was first favorited
+	if favorite == (article.FavoritedAt != nil) {
+		return article, nil
+	}
+
+	var favoritedAt *time.Time
+	if favorite {
+		now := time.Now()
+		favoritedAt = &now
+	}
+	if err := s.repo.UpdateFields(id, map[string]any{"favorited_at": favoritedAt}); err != nil {
+		s.logger.Error("Failed to update favorite state of article " + id.String() + " for user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	if s.favorites != nil {
+		s.favorites.FavoriteChanged(userID, id, favorite)
+	}
+
+	article.FavoritedAt = favoritedAt
+	return article, nil
+}
+
+func (s *service) DeleteArticles(userID uuid.UUID, req *BatchDeleteRequest) (*BatchDeleteResponse, error) {
+	if len(req.IDs) == 0 && req.Status == "" {
+		return nil, utils.NewValidationError("ids", "or status is required")
+	}
+	switch req.Status {
+	case "", MetadataStatusPending, MetadataStatusSuccess, MetadataStatusFailed:
+	default:
+		return nil, utils.NewValidationError("status", "must be pending, success or failed")
+	}
+
+	// Report each requested ID once, in request order
+	ids := make([]uuid.UUID, 0, len(req.IDs))
+	seen := make(map[uuid.UUID]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	s.logger.Info("Deleting batch of articles for user " + userID.String() + " (" + utils.IntToString(len(ids)) + " IDs, status '" + req.Status + "')")
+
+	deleted, err := s.repo.DeleteBatch(userID, ids, req.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &BatchDeleteResponse{Deleted: len(deleted)}
+	if len(ids) == 0 {
+		// Selected by status alone: report what was deleted
+		response.Results = make([]*BatchDeleteResult, len(deleted))
+		for i, id := range deleted {
+			response.Results[i] = &BatchDeleteResult{ID: id, Status: BatchDeleteStatusDeleted}
+		}
+		return response, nil
+	}
+
+	wasDeleted := make(map[uuid.UUID]bool, len(deleted))
+	for _, id := range deleted {
+		wasDeleted[id] = true
+	}
+	response.Results = make([]*BatchDeleteResult, len(ids))
+	for i, id := range ids {
+		status := BatchDeleteStatusNotFound
+		if wasDeleted[id] {
+			status = BatchDeleteStatusDeleted
+		}
+		response.Results[i] = &BatchDeleteResult{ID: id, Status: status}
+	}
+
+	return response, nil
+}
+
+func (s *service) GetTrash(userID uuid.UUID, page, limit int) ([]*Article, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	articles, err := s.repo.FindTrashedByUserID(userID, (page-1)*limit, limit)
+	if err != nil {
+		s.logger.Error("Failed to fetch trash of user " + userID.String() + ": " + err.Error())
+		return nil, 0, err
+	}
+
+	total, err := s.repo.CountTrashedByUserID(userID)
+	if err != nil {
+		s.logger.Error("Failed to count trash of user " + userID.String() + ": " + err.Error())
+		return nil, 0, err
+	}
+
+	return articles, total, nil
+}
+
+func (s *service) RestoreArticle(id uuid.UUID, userID uuid.UUID) (*Article, error) {
+	s.logger.Info("Restoring article " + id.String() + " for user " + userID.String())
+
+	article, err := s.repo.FindTrashedByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !article.IsOwnedBy(userID) {
+		return nil, ErrNotFound
+	}
+
+	if err := s.repo.Restore(id); err != nil {
+		s.logger.Error("Failed to restore article " + id.String() + " for user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	return s.repo.FindByID(id)
+}
+
+func (s *service) UpdateArticleFields(id uuid.UUID, userID uuid.UUID, req *UpdateArticleRequest) (*Article, error) {
+	s.logger.Info("Updating fields of article " + id.String() + " for user " + userID.String())
+
+	article, err := s.GetArticle(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect only the fields present in the request
+	fields := make(map[string]any)
+	if req.Title != nil {
+		title, err := utils.ValidateText("title", *req.Title, utils.MaxTitleLength)
+		if err != nil {
+			return nil, err
+		}
+		fields["title"] = title
+		article.Title = title
+	}
+	if req.Description != nil {
+		description, err := utils.ValidateText("description", *req.Description, utils.MaxDescriptionLength)
+		if err != nil {
+			return nil, err
+		}
+		fields["description"] = description
+		article.Description = description
+	}
+	if req.Notes != nil {
+		notes, err := utils.ValidateText("notes", *req.Notes, utils.MaxNotesLength)
+		if err != nil {
+			return nil, err
+		}
+		fields["notes"] = notes
+		article.Notes = notes
+	}
+
+	if len(fields) == 0 {
+		return nil, utils.NewValidationError("body", "at least one of title, description or notes is required")
+	}
+
+	article.UpdatedAt = time.Now()
+	fields["updated_at"] = article.UpdatedAt
+
+	if err := s.repo.UpdateFields(id, fields); err != nil {
+		s.logger.Error("Failed to update fields of article " + id.String() + " for user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	s.logger.Info("Article fields updated successfully: " + id.String() + " for user " + userID.String())
+
+	return article, nil
+}
+
+func (s *service) SearchArticles(userID uuid.UUID, query string, page, limit int) ([]*SearchResult, int64, error) {
+	query, err := utils.ValidateText("q", query, maxSearchQueryLength)
+	if err != nil {
+		return nil, 0, err
+	}
+	if query == "" {
+		return nil, 0, utils.NewValidationError("q", "is required")
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := (page - 1) * limit
+
+	s.logger.Info("Searching articles for " + userID.String() + " (page " + utils.IntToString(page) + ", limit " + utils.IntToString(limit) + ")")
+
+	results, total, err := s.repo.Search(userID, query, offset, limit)
+	if err != nil {
+		s.logger.Error("Failed to search articles for " + userID.String() + ": " + err.Error())
+		return nil, 0, err
+	}
+
+	return results, total, nil
+}
+
+// exportBatchSize is the number of articles loaded per round trip while exporting
+const exportBatchSize = 500
+
+func (s *service) ExportArticles(userID uuid.UUID, fn func(article *Article) error) error {
+	s.logger.Info("Exporting articles for user " + userID.String())
+
+	count := 0
+	err := s.repo.StreamByUserID(userID, exportBatchSize, func(batch []*Article) error {
+		for _, article := range batch {
+			if err := fn(article); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to export articles for user " + userID.String() + " after " + utils.IntToString(count) + " articles: " + err.Error())
+		return err
+	}
+
+	s.logger.Info("Exported " + utils.IntToString(count) + " articles for user " + userID.String())
+
+	return nil
+}
+
+func (s *service) AddTags(id uuid.UUID, userID uuid.UUID, names []string) (*Article, error) {
+	s.logger.Info("Adding tags to article " + id.String() + " for user " + userID.String())
+
+	if _, err := s.GetArticle(id, userID); err != nil {
+		return nil, err
+	}
+
+	normalized := make([]string, 0, len(names))
+	seen := make(map[string]bool)
+	for _, name := range names {
+		tag, err := NormalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+
+	if err := s.repo.AddTags(id, userID, normalized); err != nil {
+		s.logger.Error("Failed to add tags to article " + id.String() + " for user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	s.logger.Info("Tags added successfully to article " + id.String() + " for user " + userID.String())
+
+	// Reload to return the complete tag set
+	return s.GetArticle(id, userID)
+}
+
+func (s *service) RemoveTag(id uuid.UUID, userID uuid.UUID, name string) error {
+	s.logger.Info("Removing tag from article " + id.String() + " for user " + userID.String())
+
+	if _, err := s.GetArticle(id, userID); err != nil {
+		return err
+	}
+
+	tag, err := NormalizeTagName(name)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.RemoveTag(id, userID, tag); err != nil {
+		if !errors.Is(err, ErrTagNotFound) {
+			s.logger.Error("Failed to remove tag from article " + id.String() + " for user " + userID.String() + ": " + err.Error())
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) GetUserTags(userID uuid.UUID) ([]*Tag, error) {
+	return s.repo.FindTagsByUserID(userID)
+}
+
+func (s *service) GetHighlights(id uuid.UUID, userID uuid.UUID) ([]*Highlight, error) {
+	if _, err := s.GetArticle(id, userID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindHighlights(id)
+}
+
+func (s *service) CreateHighlight(id uuid.UUID, userID uuid.UUID, req *CreateHighlightRequest) (*Highlight, error) {
+	s.logger.Info("Creating highlight on article " + id.String() + " for user " + userID.String())
+
+	article, err := s.GetArticle(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if article.Content == "" {
+		return nil, ErrContentNotAvailable
+	}
+
+	text, err := quoteRange(article.Content, *req.StartOffset, *req.EndOffset)
+	if err != nil {
+		return nil, err
+	}
+	note, err := utils.ValidateText("note", req.Note, utils.MaxNotesLength)
+	if err != nil {
+		return nil, err
+	}
+
+	highlight := &Highlight{
+		ID:          uuid.New(),
+		ArticleID:   id,
+		UserID:      userID,
+		StartOffset: *req.StartOffset,
+		EndOffset:   *req.EndOffset,
+		Text:        text,
+		Note:        note,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := s.repo.CreateHighlight(highlight); err != nil {
+		s.logger.Error("Failed to create highlight on article " + id.String() + " for user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	return highlight, nil
+}
+
+func (s *service) UpdateHighlight(id, highlightID uuid.UUID, userID uuid.UUID, req *UpdateHighlightRequest) (*Highlight, error) {
+	highlight, err := s.getHighlight(id, highlightID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	note, err := utils.ValidateText("note", *req.Note, utils.MaxNotesLength)
+	if err != nil {
+		return nil, err
+	}
+
+	highlight.Note = note
+	highlight.UpdatedAt = time.Now()
+	if err := s.repo.UpdateHighlight(highlight); err != nil {
+		s.logger.Error("Failed to update highlight " + highlightID.String() + " for user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	return highlight, nil
+}
+
+func (s *service) DeleteHighlight(id, highlightID uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.getHighlight(id, highlightID, userID); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteHighlight(highlightID); err != nil {
+		s.logger.Error("Failed to delete highlight " + highlightID.String() + " for user " + userID.String() + ": " + err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// getHighlight loads a highlight, verifying it belongs to the user's article
+func (s *service) getHighlight(id, highlightID uuid.UUID, userID uuid.UUID) (*Highlight, error) {
+	if _, err := s.GetArticle(id, userID); err != nil {
+		return nil, err
+	}
+
+	highlight, err := s.repo.FindHighlightByID(highlightID)
+	if err != nil {
+		return nil, err
+	}
+	if highlight.ArticleID != id || highlight.UserID != userID {
+		return nil, ErrHighlightNotFound
+	}
+
+	return highlight, nil
+}
+
+func (s *service) UpdateMetadata(id uuid.UUID, title, description, content, language, siteName, faviconURL string, wordCount, readingTime int, confidence float64) error {
 	article, err := s.repo.FindByID(id)
 	if err != nil {
 		return err
 	}
 
-	// Update metadata fields
-	article.Title = title
-	article.Description = description
-	article.Content = content
+	// Update metadata fields, stripping control characters from extracted text
+	article.Title = utils.SanitizeText(title, utils.MaxTitleLength)
+	article.Description = utils.SanitizeText(description, utils.MaxDescriptionLength)
+	article.Content = utils.SanitizeText(content, 0)
 	article.WordCount = wordCount
+	article.ReadingTime = readingTime
+	article.Language = language
+	article.SiteName = utils.SanitizeText(siteName, utils.MaxSiteNameLength)
+	article.FaviconURL = faviconURL
 	article.ConfidenceScore = confidence
 	article.MetadataStatus = MetadataStatusSuccess
+	article.clearFailure()
 	article.ClassifierUsed = "readability" // Could be parameterized
 	article.UpdatedAt = time.Now()
 
 	return s.repo.Update(article)
 }
 
+// ImportArticles creates articles for imported links in bulk. The returned slice
+// is parallel to items; entries are nil for invalid or refused URLs and links
+// the user already saved. Metadata is then extracted in the background at import
+// priority, so large imports do not hold up articles saved interactively.
+func (s *service) ImportArticles(userID uuid.UUID, items []*ImportedArticle) ([]*Article, error) {
+	s.logger.Info("Importing " + utils.IntToString(len(items)) + " articles for user " + userID.String())
+
+	results := make([]*Article, len(items))
+	urls := make([]string, 0, len(items))
+	seen := make(map[string]bool)
+	for i, item := range items {
+		url, err := s.checkURL(item.URL)
+		if err != nil || seen[url] {
+			continue
+		}
+		seen[url] = true
+
+		createdAt := item.AddedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+
+		results[i] = &Article{
+			ID:             uuid.New(),
+			UserID:         userID,
+			URL:            url,
+			Title:          utils.SanitizeText(item.Title, utils.MaxTitleLength),
+			MetadataStatus: MetadataStatusPending,
+			CreatedAt:      createdAt,
+			UpdatedAt:      time.Now(),
+		}
+		urls = append(urls, url)
+	}
+
+	existing, err := s.repo.FindExistingURLs(userID, urls)
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]*Article, 0, len(urls))
+	for i, article := range results {
+		if article == nil {
+			continue
+		}
+		if existing[article.URL] {
+			results[i] = nil
+			continue
+		}
+		created = append(created, article)
+	}
+
+	ids, err := s.repo.CreateBatch(created)
+	if err != nil {
+		s.logger.Error("Failed to import articles for user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	// Links saved while the import ran were skipped by the insert
+	if len(ids) < len(created) {
+		inserted := make(map[uuid.UUID]bool, len(ids))
+		for _, id := range ids {
+			inserted[id] = true
+		}
+		created = created[:0]
+		for i, article := range results {
+			if article == nil {
+				continue
+			}
+			if !inserted[article.ID] {
+				results[i] = nil
+				continue
+			}
+			created = append(created, article)
+		}
+	}
+
+	// Carry over tags from the source service
+	for i, article := range results {
+		if article == nil || len(items[i].Tags) == 0 {
+			continue
+		}
+
+		tags := make([]string, 0, len(items[i].Tags))
+		for _, name := range items[i].Tags {
+			if tag, err := NormalizeTagName(name); err == nil {
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) == 0 {
+			continue
+		}
+
+		if err := s.repo.AddTags(article.ID, userID, tags); err != nil {
+			s.logger.Error("Failed to tag imported article " + article.ID.String() + " for user " + userID.String() + ": " + err.Error())
+		}
+	}
+
+	for _, article := range created {
+		s.scheduleExtraction(context.Background(), article.ID, ExtractionPriorityImport)
+	}
+
+	s.logger.Info("Imported " + utils.IntToString(len(created)) + " of " + utils.IntToString(len(items)) + " articles for user " + userID.String())
+
+	return results, nil
+}
+
 func (s *service) ExtractMetadata(articleID uuid.UUID) error {
-	s.logger.Info("Extracting metadata for article: " + articleID.String())
+	return s.extractMetadata(extractionContext(context.Background()), articleID)
+}
+
+// extractMetadata extracts an article's metadata, logging under the request ID
+// carried by ctx and recording it with a failure
+func (s *service) extractMetadata(ctx context.Context, articleID uuid.UUID) error {
+	log := s.logger.WithContext(ctx)
+	log.Info("Extracting metadata for article: " + articleID.String())
 
 	// Get article
 	article, err := s.repo.FindByID(articleID)
@@ -156,28 +829,153 @@ func (s *service) ExtractMetadata(articleID uuid.UUID) error {
 	}
 
 	// Extract metadata
-	metadata, err := s.extractor.Extract(article.URL)
+	metadata, err := s.extractor.Extract(ctx, article.URL)
 	if err != nil {
-		s.logger.Error("Metadata extraction failed for article " + articleID.String() + " URL " + article.URL + ": " + err.Error())
+		log.Error("Metadata extraction failed for article " + articleID.String() + " URL " + article.URL + ": " + err.Error())
 
 		// Update failure status
 		article.MetadataStatus = MetadataStatusFailed
 		article.RetryCount++
 		article.UpdatedAt = time.Now()
+		article.recordFailure(err, article.UpdatedAt, logger.RequestIDFromContext(ctx))
 		s.repo.Update(article)
 
 		return err
 	}
 
 	// Update article with extracted metadata
-	return s.UpdateMetadata(
+	if err := s.UpdateMetadata(
 		articleID,
 		metadata.Title,
 		metadata.Description,
 		metadata.Content,
+		metadata.Language,
+		metadata.SiteName,
+		metadata.FaviconURL,
 		metadata.WordCount,
+		metadata.ReadingTime,
 		metadata.Confidence,
-	)
+	); err != nil {
+		return err
+	}
+
+	// The snapshot only guards against the source disappearing, so failing to store it does not fail extraction
+	if err := s.saveSnapshot(article, metadata); err != nil {
+		log.Error("Failed to store snapshot of article " + articleID.String() + ": " + err.Error())
+	}
+
+	return nil
+}
+
+// GetContent returns the stored snapshot of an article the user owns, falling
+// back to the extracted text when no snapshot was stored
+func (s *service) GetContent(id uuid.UUID, userID uuid.UUID) (*storage.Object, error) {
+	article, err := s.GetArticle(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.snapshots != nil && article.SnapshotKey != "" {
+		object, err := s.snapshots.Get(article.SnapshotKey)
+		if err == nil {
+			return object, nil
+		}
+		if !errors.Is(err, storage.ErrNotFound) {
+			s.logger.Error("Failed to load snapshot of article " + id.String() + ": " + err.Error())
+			return nil, err
+		}
+	}
+
+	if article.Content == "" {
+		return nil, ErrContentNotAvailable
+	}
+	return &storage.Object{Data: []byte(article.Content), ContentType: snapshotTextType}, nil
+}
+
+// saveSnapshot copies the extracted page to object storage and records its key
+func (s *service) saveSnapshot(article *Article, metadata *ExtractedMetadata) error {
+	if s.snapshots == nil {
+		return nil
+	}
+
+	// Prefer the cleaned markup so headings, links and images survive
+	key := "articles/" + article.UserID.String() + "/" + article.ID.String()
+	var data []byte
+	var contentType string
+	switch {
+	case strings.TrimSpace(metadata.HTML) != "":
+		key += ".html"
+		contentType = snapshotHTMLType
+		data = []byte("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>" +
+			html.EscapeString(utils.SanitizeText(metadata.Title, utils.MaxTitleLength)) +
+			"</title></head><body>\n" + metadata.HTML + "\n</body></html>\n")
+	case strings.TrimSpace(metadata.Content) != "":
+		key += ".txt"
+		contentType = snapshotTextType
+		data = []byte(utils.SanitizeText(metadata.Content, 0))
+	default:
+		return nil
+	}
+
+	if err := s.snapshots.Put(key, data, contentType); err != nil {
+		return err
+	}
+
+	// A re-extraction may switch between markup and text; drop the stale copy
+	if article.SnapshotKey != "" && article.SnapshotKey != key {
+		if err := s.snapshots.Delete(article.SnapshotKey); err != nil {
+			s.logger.Error("Failed to delete stale snapshot of article " + article.ID.String() + ": " + err.Error())
+		}
+	}
+
+	return s.repo.UpdateFields(article.ID, map[string]any{
+		"snapshot_key": key,
+		"snapshot_at":  time.Now(),
+	})
+}
+
+// RefreshMetadata re-extracts metadata for an article the user owns. The retry
+// count is reset first, so if this attempt fails the retry worker picks the
+// article up again with a fresh budget. Refreshes are limited per article and
+// per user, as each one fetches the page again.
+func (s *service) RefreshMetadata(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Article, error) {
+	article, err := s.GetArticle(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.allowRefresh(id, userID); err != nil {
+		s.logger.Info("Refused metadata refresh of article " + id.String() + " for user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	s.logger.Info("Refreshing metadata for article " + id.String() + " on request of user " + userID.String())
+
+	article.MetadataStatus = MetadataStatusPending
+	article.RetryCount = 0
+	article.UpdatedAt = time.Now()
+	if err := s.repo.Update(article); err != nil {
+		s.logger.Error("Failed to reset metadata status for article " + id.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	if err := s.runExtraction(ctx, id); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMetadataExtraction, err)
+	}
+
+	return s.repo.FindByID(id)
+}
+
+// allowRefresh enforces the per-article cooldown and the per-user daily limit
+// on refreshes. A refresh refused by the daily limit still starts the cooldown.
+func (s *service) allowRefresh(id uuid.UUID, userID uuid.UUID) error {
+	if allowed, _, resetIn := s.refreshCooldown.Allow(id.String()); !allowed {
+		return utils.NewRateLimitError("this article was refreshed recently, try again later", resetIn)
+	}
+	if allowed, _, resetIn := s.refreshDaily.Allow(userID.String()); !allowed {
+		return utils.NewRateLimitError("daily refresh limit reached", resetIn)
+	}
+	return nil
 }
 
 func (s *service) RetryFailedMetadata() error {
@@ -204,29 +1002,230 @@ func (s *service) RetryFailedMetadata() error {
 			continue
 		}
 
-		s.logger.Info("Retrying metadata extraction for article " + article.ID.String() + " URL " + article.URL + " (retry " + utils.IntToString(article.RetryCount) + ")")
+		s.logger.Info("Scheduling metadata retry for article " + article.ID.String() + " URL " + article.URL + " (retry " + utils.IntToString(article.RetryCount) + ")")
+		s.scheduleExtraction(context.Background(), article.ID, ExtractionPriorityRetry)
+	}
+
+	return nil
+}
+
+// RemoveArticle permanently deletes an article, trashed or not, for moderation
+func (s *service) RemoveArticle(id uuid.UUID) (*Article, error) {
+	article, err := s.repo.FindByID(id)
+	if errors.Is(err, ErrNotFound) {
+		article, err = s.repo.FindTrashedByID(id)
+	} else if err == nil {
+		// Only trashed articles are purged
+		err = s.repo.Delete(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Purge(id); err != nil {
+		s.logger.Error("Failed to remove article " + id.String() + ": " + err.Error())
+		return nil, err
+	}
+	s.deleteObjects(article)
+
+	return article, nil
+}
+
+// deleteObjects deletes the snapshot and content of a purged article. They are
+// only reachable through the article, so a leftover copy is harmless.
+func (s *service) deleteObjects(article *Article) {
+	if s.snapshots != nil && article.SnapshotKey != "" {
+		if err := s.snapshots.Delete(article.SnapshotKey); err != nil {
+			s.logger.Error("Failed to delete snapshot of article " + article.ID.String() + ": " + err.Error())
+		}
+	}
+	if s.snapshots != nil && article.ContentKey != "" {
+		if err := s.snapshots.Delete(article.ContentKey); err != nil {
+			s.logger.Error("Failed to delete content of article " + article.ID.String() + ": " + err.Error())
+		}
+	}
+}
+
+// PurgeTrash permanently deletes articles that have been in the trash longer
+// than trashRetention, along with their snapshots and stored content
+func (s *service) PurgeTrash() error {
+	cutoff := time.Now().Add(-trashRetention)
+	purged := 0
 
-		// Retry extraction
-		err := s.ExtractMetadata(article.ID)
+	for {
+		articles, err := s.repo.FindTrashedBefore(cutoff, purgeBatchSize)
 		if err != nil {
-			s.logger.Error("Retry failed for article " + article.ID.String() + ": " + err.Error())
-		} else {
-			s.logger.Info("Retry succeeded for article " + article.ID.String())
+			s.logger.Error("Failed to find articles to purge: " + err.Error())
+			return err
+		}
+
+		batchPurged := 0
+		for _, article := range articles {
+			if err := s.repo.Purge(article.ID); err != nil {
+				s.logger.Error("Failed to purge article " + article.ID.String() + ": " + err.Error())
+				continue
+			}
+			batchPurged++
+			s.deleteObjects(article)
 		}
+		purged += batchPurged
 
-		// Add small delay between retries to avoid overwhelming the service
-		time.Sleep(1 * time.Second)
+		// Stop on a short batch, or when failures would return the same articles again
+		if len(articles) < purgeBatchSize || batchPurged == 0 {
+			break
+		}
+	}
+
+	if purged > 0 {
+		s.logger.Info("Purged " + utils.IntToString(purged) + " articles from the trash")
 	}
 
 	return nil
 }
 
+// scheduleExtraction extracts an article's metadata in the background under
+// the request ID of ctx
+func (s *service) scheduleExtraction(ctx context.Context, articleID uuid.UUID, priority ExtractionPriority) {
+	ctx = extractionContext(ctx)
+	task := func() error {
+		return s.extractMetadata(ctx, articleID)
+	}
+
+	if s.queue == nil {
+		go func() {
+			if err := task(); err != nil {
+				s.logger.WithContext(ctx).Error("Failed to extract metadata for article " + articleID.String() + ": " + err.Error())
+			}
+		}()
+		return
+	}
+
+	if !s.queue.Enqueue(priority, articleID, task) {
+		s.logger.WithContext(ctx).Info("Metadata extraction for article " + articleID.String() + " not queued, it is already queued or running, or the queue is stopping")
+	}
+}
+
+// runExtraction extracts an article's metadata while the caller waits,
+// sharing the outcome of an extraction the queue is already running
+func (s *service) runExtraction(ctx context.Context, articleID uuid.UUID) error {
+	ctx = extractionContext(ctx)
+	task := func() error {
+		return s.extractMetadata(ctx, articleID)
+	}
+
+	if s.queue == nil {
+		return task()
+	}
+	return s.queue.Run(articleID, task)
+}
+
+// extractionContext keeps only the request ID of ctx, so an extraction is
+// traced to the request that asked for it without ending with that request.
+// Extractions no request asked for, such as retries and imports, get an ID
+// of their own.
+func extractionContext(ctx context.Context) context.Context {
+	requestID := logger.RequestIDFromContext(ctx)
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+	return logger.ContextWithRequestID(context.Background(), requestID)
+}
+
 // shouldRetry checks if article should be retried (max 3 retries)
 func (s *service) shouldRetry(article *Article) bool {
 	const maxRetries = 3
 	return article.RetryCount < maxRetries
 }
 
+// NormalizeTagName lowercases and validates a tag name
+func NormalizeTagName(name string) (string, error) {
+	tag, err := utils.ValidateText("tags", strings.ToLower(name), maxTagLength)
+	if err != nil {
+		return "", err
+	}
+	if tag == "" {
+		return "", utils.NewValidationError("tags", "must not be empty")
+	}
+	if strings.Contains(tag, ",") {
+		return "", utils.NewValidationError("tags", "must not contain commas")
+	}
+	return tag, nil
+}
+
+// ParseLanguage lowercases and validates an ISO 639 language code
+func ParseLanguage(code string) (string, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if len(code) < 2 || len(code) > 3 {
+		return "", utils.NewValidationError("language", "must be an ISO 639-1 code")
+	}
+	for _, r := range code {
+		if r < 'a' || r > 'z' {
+			return "", utils.NewValidationError("language", "must be an ISO 639-1 code")
+		}
+	}
+	return code, nil
+}
+
+// BuildStatsResponse assembles library statistics, listing every score from 1 to 5
+func BuildStatsResponse(totals *ArticleTotals, domains []*DomainCount, statuses map[string]int64, ratings map[int]int64) *ArticleStatsResponse {
+	if domains == nil {
+		domains = make([]*DomainCount, 0)
+	}
+	if statuses == nil {
+		statuses = make(map[string]int64)
+	}
+
+	distribution := make(map[int]int64, 5)
+	for score := 1; score <= 5; score++ {
+		distribution[score] = ratings[score]
+	}
+
+	return &ArticleStatsResponse{
+		TotalArticles:    totals.Articles,
+		TotalWordCount:   totals.WordCount,
+		ByDomain:         domains,
+		ByMetadataStatus: statuses,
+		Ratings:          distribution,
+	}
+}
+
+// BuildCursorResponse creates a cursor-paginated article list. Nothing is
+// counted, so total, page and pages stay zero.
+func BuildCursorResponse(articles []*Article, limit int, nextCursor string) *ArticleListResponse {
+	responses := make([]*ArticleResponse, len(articles))
+	for i, article := range articles {
+		responses[i] = article.ToResponse()
+	}
+
+	return &ArticleListResponse{
+		Articles:   responses,
+		Limit:      limit,
+		NextCursor: nextCursor,
+	}
+}
+
+// BuildSearchResponse builds a paginated search response
+func BuildSearchResponse(query string, results []*SearchResult, total int64, page, limit int) *SearchResponse {
+	responses := make([]*SearchResultResponse, len(results))
+	for i, result := range results {
+		responses[i] = &SearchResultResponse{
+			ArticleResponse: result.Article.ToResponse(),
+			Rank:            result.Rank,
+		}
+	}
+
+	pagination := utils.CalculatePagination(total, page, limit)
+
+	return &SearchResponse{
+		Query:   query,
+		Results: responses,
+		Total:   pagination.Total,
+		Page:    pagination.Page,
+		Limit:   pagination.Limit,
+		Pages:   pagination.Pages,
+	}
+}
+
 // BuildPaginationResponse builds a paginated response
 func BuildPaginationResponse(articles []*Article, total int64, page, limit int) *ArticleListResponse {
 	responses := make([]*ArticleResponse, len(articles))
@@ -0,0 +1,25 @@
+package article
+
+// statsDomainLimit caps the domains listed in article statistics
+const statsDomainLimit = 50
+
+// DomainCount is the number of articles saved from one domain
+type DomainCount struct {
+	Domain string `json:"domain"` // Host without "www.", empty for malformed URLs
+	Count  int64  `json:"count"`
+}
+
+// ArticleTotals sums up a user's library
+type ArticleTotals struct {
+	Articles  int64
+	WordCount int64
+}
+
+// ArticleStatsResponse summarizes a user's library
+type ArticleStatsResponse struct {
+	TotalArticles    int64            `json:"total_articles"`
+	TotalWordCount   int64            `json:"total_word_count"`
+	ByDomain         []*DomainCount   `json:"by_domain"`          // Most saved first, top 50
+	ByMetadataStatus map[string]int64 `json:"by_metadata_status"` // Status to article count
+	Ratings          map[int]int64    `json:"ratings"`            // Score (1-5) to number of rated articles
+}
@@ -0,0 +1,130 @@
+package article
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+	"golang.org/x/net/idna"
+)
+
+// maxValidateURLs bounds the URLs checked by one validation request
+const maxValidateURLs = 100
+
+// Verdicts on a URL checked before saving it
+const (
+	URLVerdictValid         = "valid"
+	URLVerdictInvalid       = "invalid"        // Not an absolute http(s) URL
+	URLVerdictDuplicate     = "duplicate"      // Already saved, or listed earlier in the same request
+	URLVerdictBlockedDomain = "blocked_domain" // On the ARTICLE_BLOCKED_DOMAINS list
+	URLVerdictPrivateIP     = "private_ip"     // Points at a loopback, private or link-local address
+)
+
+// Errors for URLs that are well formed but may not be saved
+var (
+	ErrBlockedDomain  = utils.NewValidationError("url", "domain is blocked")
+	ErrPrivateAddress = utils.NewValidationError("url", "must not point at a private or local address")
+)
+
+// parseBlockedDomains reads a comma-separated domain list into the form
+// NormalizeURL gives hosts, lowercase punycode
+func parseBlockedDomains(list string) ([]string, error) {
+	var domains []string
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.Trim(strings.TrimSpace(entry), ".")
+		if entry == "" {
+			continue
+		}
+		domain, err := idna.Lookup.ToASCII(strings.ToLower(entry))
+		if err != nil {
+			return nil, fmt.Errorf("invalid blocked domain '%s': %v", entry, err)
+		}
+		domains = append(domains, domain)
+	}
+	return domains, nil
+}
+
+// checkURL normalizes a URL and applies the rules for saving it, apart from
+// the duplicate check, which needs the user's library
+func (s *service) checkURL(raw string) (string, error) {
+	normalized, err := utils.NormalizeURL(raw)
+	if err != nil {
+		return "", err
+	}
+
+	parsed, err := url.Parse(normalized)
+	if err != nil {
+		return "", utils.NewValidationError("url", "is not a valid URL")
+	}
+	host := parsed.Hostname()
+
+	if utils.IsPrivateHost(host) {
+		return "", ErrPrivateAddress
+	}
+	for _, domain := range s.blockedDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return "", ErrBlockedDomain
+		}
+	}
+
+	return normalized, nil
+}
+
+// ValidateURLs reports what saving each URL would do, without saving anything
+func (s *service) ValidateURLs(userID uuid.UUID, req *ValidateURLsRequest) (*ValidateURLsResponse, error) {
+	if len(req.URLs) > maxValidateURLs {
+		return nil, utils.NewValidationError("urls", "must list at most "+utils.IntToString(maxValidateURLs)+" URLs")
+	}
+
+	results := make([]*URLValidationResult, len(req.URLs))
+	normalized := make([]string, 0, len(req.URLs))
+	for i, raw := range req.URLs {
+		result := &URLValidationResult{URL: raw}
+		results[i] = result
+
+		checked, err := s.checkURL(raw)
+		switch {
+		case err == nil:
+			result.NormalizedURL = checked
+			normalized = append(normalized, checked)
+		case errors.Is(err, ErrBlockedDomain):
+			result.Verdict = URLVerdictBlockedDomain
+		case errors.Is(err, ErrPrivateAddress):
+			result.Verdict = URLVerdictPrivateIP
+		default:
+			result.Verdict = URLVerdictInvalid
+		}
+		if err != nil {
+			result.Reason = err.Error()
+		}
+	}
+
+	existing, err := s.repo.FindExistingURLs(userID, normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ValidateURLsResponse{Results: results, Summary: make(map[string]int)}
+	seen := make(map[string]bool)
+	for _, result := range results {
+		if result.Verdict == "" {
+			switch {
+			case existing[result.NormalizedURL]:
+				result.Verdict = URLVerdictDuplicate
+				result.Reason = "already saved"
+			case seen[result.NormalizedURL]:
+				result.Verdict = URLVerdictDuplicate
+				result.Reason = "listed more than once"
+			default:
+				result.Verdict = URLVerdictValid
+			}
+			seen[result.NormalizedURL] = true
+		}
+		response.Summary[result.Verdict]++
+	}
+
+	return response, nil
+}
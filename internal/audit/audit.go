@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Event is a security event, such as a login or a revoked token
+type Event struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	Type      string     `json:"type" gorm:"size:50;not null;index"`
+	UserID    *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`  // Nil for failed logins of unknown emails
+	ActorID   *uuid.UUID `json:"actor_id,omitempty" gorm:"type:uuid;index"` // The admin acting on the user, if any
+	Email     string     `json:"email,omitempty" gorm:"size:255;index"`
+	IPAddress string     `json:"ip_address,omitempty" gorm:"size:45;index"`
+	UserAgent string     `json:"user_agent,omitempty" gorm:"size:512"`
+	Details   string     `json:"details,omitempty" gorm:"size:1000"`
+	CreatedAt time.Time  `json:"created_at" gorm:"not null;index"`
+}
+
+// TableName returns the table name for GORM
+func (Event) TableName() string {
+	return "audit_logs"
+}
+
+// Filter narrows an event listing; zero fields match every event
+type Filter struct {
+	Type      string
+	UserID    *uuid.UUID
+	Email     string
+	IPAddress string
+	After     *time.Time
+	Before    *time.Time
+}
+
+// Repository defines the interface for audit log storage
+type Repository interface {
+	Create(event *Event) error
+	// List returns events matching the filter, newest first, starting after the cursor
+	List(filter *Filter, after *utils.Cursor, limit int) ([]*Event, error)
+	// DeleteBefore deletes events created before the time, returning how many
+	DeleteBefore(before time.Time) (int64, error)
+}
+
+// Service defines the interface for the security audit log
+type Service interface {
+	// Record stores the event and writes it to the audit logger. Failures are
+	// logged rather than returned, so auditing never fails the audited action.
+	Record(event *Event)
+	// List returns a page of events, newest first
+	List(filter *Filter, cursor string, limit int) (*ListResponse, error)
+	// Purge deletes events older than the retention period
+	Purge() error
+}
+
+// ListResponse is a page of events
+type ListResponse struct {
+	Events     []*Event         `json:"events"`
+	Pagination utils.CursorMeta `json:"pagination"`
+}
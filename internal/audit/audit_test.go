@@ -0,0 +1,198 @@
+package audit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRepository keeps events in memory
+type mockRepository struct {
+	events       []*Event
+	deleteBefore time.Time
+	failCreate   bool
+}
+
+func (m *mockRepository) Create(event *Event) error {
+	if m.failCreate {
+		return errors.New("database down")
+	}
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockRepository) List(filter *Filter, after *utils.Cursor, limit int) ([]*Event, error) {
+	var events []*Event
+	for _, event := range m.events {
+		if filter.Type != "" && event.Type != filter.Type {
+			continue
+		}
+		if filter.UserID != nil && (event.UserID == nil || *event.UserID != *filter.UserID) {
+			continue
+		}
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].CreatedAt.After(events[j].CreatedAt)
+	})
+	if after != nil {
+		for i, event := range events {
+			if event.ID == after.ID {
+				events = events[i+1:]
+				break
+			}
+		}
+	}
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+func (m *mockRepository) DeleteBefore(before time.Time) (int64, error) {
+	m.deleteBefore = before
+	var kept []*Event
+	for _, event := range m.events {
+		if !event.CreatedAt.Before(before) {
+			kept = append(kept, event)
+		}
+	}
+	purged := int64(len(m.events) - len(kept))
+	m.events = kept
+	return purged, nil
+}
+
+func newTestService(t *testing.T, cfg *config.AuditConfig, repo Repository, now time.Time) *service {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	svc, err := NewService(cfg, repo, log)
+	require.NoError(t, err)
+
+	s := svc.(*service)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestNewService(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	for _, days := range []string{"0", "-1", "forever"} {
+		_, err = NewService(&config.AuditConfig{RetentionDays: days}, &mockRepository{}, log)
+		assert.Error(t, err, days)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Sets the ID and time", func(t *testing.T) {
+		repo := &mockRepository{}
+		svc := newTestService(t, nil, repo, now)
+
+		svc.Record(&Event{Type: "login.failed", Email: "user@example.com", IPAddress: "203.0.113.7"})
+
+		require.Len(t, repo.events, 1)
+		assert.NotEqual(t, uuid.Nil, repo.events[0].ID)
+		assert.Equal(t, now, repo.events[0].CreatedAt)
+	})
+
+	t.Run("Storage errors do not panic", func(t *testing.T) {
+		svc := newTestService(t, nil, &mockRepository{failCreate: true}, now)
+
+		assert.NotPanics(t, func() { svc.Record(&Event{Type: "login.failed"}) })
+	})
+}
+
+func TestList(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := &mockRepository{}
+	svc := newTestService(t, nil, repo, now)
+
+	userID := uuid.New()
+	for i := 0; i < 5; i++ {
+		svc.now = func() time.Time { return now.Add(time.Duration(i) * time.Minute) }
+		svc.Record(&Event{Type: "login.succeeded", UserID: &userID})
+	}
+	svc.Record(&Event{Type: "login.failed"})
+
+	t.Run("Pages newest first", func(t *testing.T) {
+		first, err := svc.List(&Filter{Type: "login.succeeded"}, "", 3)
+		require.NoError(t, err)
+		require.Len(t, first.Events, 3)
+		assert.Equal(t, now.Add(4*time.Minute), first.Events[0].CreatedAt)
+		assert.True(t, first.Pagination.HasMore)
+
+		second, err := svc.List(&Filter{Type: "login.succeeded"}, first.Pagination.NextCursor, 3)
+		require.NoError(t, err)
+		assert.Len(t, second.Events, 2)
+		assert.False(t, second.Pagination.HasMore)
+		assert.Empty(t, second.Pagination.NextCursor)
+	})
+
+	t.Run("Filters by user", func(t *testing.T) {
+		response, err := svc.List(&Filter{UserID: &userID}, "", 0)
+		require.NoError(t, err)
+		assert.Len(t, response.Events, 5)
+		assert.Equal(t, defaultListLimit, response.Pagination.Limit)
+	})
+
+	t.Run("Caps the limit", func(t *testing.T) {
+		response, err := svc.List(&Filter{}, "", 10000)
+		require.NoError(t, err)
+		assert.Equal(t, maxListLimit, response.Pagination.Limit)
+	})
+
+	t.Run("Rejects invalid cursors", func(t *testing.T) {
+		_, err := svc.List(&Filter{}, "not-a-cursor", 10)
+		assert.Error(t, err)
+	})
+}
+
+func TestPurge(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := &mockRepository{events: []*Event{
+		{ID: uuid.New(), Type: "login.failed", CreatedAt: now.AddDate(0, 0, -31)},
+		{ID: uuid.New(), Type: "login.failed", CreatedAt: now.AddDate(0, 0, -29)},
+	}}
+	svc := newTestService(t, &config.AuditConfig{RetentionDays: "30"}, repo, now)
+
+	require.NoError(t, svc.Purge())
+	assert.Equal(t, now.AddDate(0, 0, -30), repo.deleteBefore)
+	assert.Len(t, repo.events, 1)
+}
+
+func TestListEventsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := newTestService(t, nil, &mockRepository{}, time.Now())
+	svc.Record(&Event{Type: "password.changed"})
+
+	router := gin.New()
+	router.GET("/admin/audit", NewHandler(svc).ListEvents)
+
+	for _, tc := range []struct {
+		query  string
+		status int
+	}{
+		{"?type=password.changed", http.StatusOK},
+		{"?user_id=not-a-uuid", http.StatusBadRequest},
+		{"?after=yesterday", http.StatusBadRequest},
+		{"?cursor=bogus", http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/audit"+tc.query, nil))
+		assert.Equal(t, tc.status, w.Code, tc.query)
+	}
+}
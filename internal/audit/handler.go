@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for the audit log
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new audit handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// ListEvents handles listing audit events, newest first
+func (h *Handler) ListEvents(c *gin.Context) {
+	filter := &Filter{
+		Type:      c.Query("type"),
+		Email:     c.Query("email"),
+		IPAddress: c.Query("ip"),
+	}
+	if param := c.Query("user_id"); param != "" {
+		userID, err := uuid.Parse(param)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		filter.UserID = &userID
+	}
+	for name, target := range map[string]**time.Time{"after": &filter.After, "before": &filter.Before} {
+		if param := c.Query(name); param != "" {
+			parsed, err := time.Parse(time.RFC3339, param)
+			if err != nil {
+				utils.RespondError(c, utils.NewValidationError(name, "must be an RFC 3339 timestamp"), "Failed to list audit events")
+				return
+			}
+			*target = &parsed
+		}
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	response, err := h.service.List(filter, c.Query("cursor"), limit)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to list audit events")
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RegisterRoutes registers audit log routes under /admin. rateLimit runs after
+// the role and scope checks so limits apply per authenticated admin.
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, rateLimit gin.HandlerFunc) {
+	admin := router.Group("/admin")
+	admin.Use(authMiddleware, utils.RequireRole(utils.RoleAdmin), utils.RequireScope(utils.ScopeAdmin), rateLimit)
+	{
+		admin.GET("/audit", h.ListEvents)
+	}
+}
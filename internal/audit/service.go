@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultRetentionDays = 90
+	defaultListLimit     = 50
+	maxListLimit         = 500
+)
+
+// service implements the Service interface
+type service struct {
+	repo      Repository
+	retention time.Duration
+	logger    *logger.Logger
+	audit     *logger.Logger
+	now       func() time.Time
+}
+
+// NewService creates an audit log service with validation and defaults
+func NewService(cfg *config.AuditConfig, repo Repository, log *logger.Logger) (Service, error) {
+	retentionDays := defaultRetentionDays
+	if cfg != nil && cfg.RetentionDays != "" {
+		parsed, err := strconv.Atoi(cfg.RetentionDays)
+		if err != nil || parsed < 1 {
+			return nil, fmt.Errorf("invalid audit retention days '%s': must be a positive integer", cfg.RetentionDays)
+		}
+		retentionDays = parsed
+	}
+
+	return &service{
+		repo:      repo,
+		retention: time.Duration(retentionDays) * 24 * time.Hour,
+		logger:    log.WithComponent("audit-service"),
+		audit:     log.WithComponent("audit"),
+		now:       time.Now,
+	}, nil
+}
+
+func (s *service) Record(event *Event) {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = s.now()
+	}
+
+	s.log(event)
+	if err := s.repo.Create(event); err != nil {
+		s.logger.Error("Failed to store audit event " + event.Type + ": " + err.Error())
+	}
+}
+
+// log writes the event to the audit logger with its fields
+func (s *service) log(event *Event) {
+	log := s.audit.WithField("event", event.Type)
+	if event.UserID != nil {
+		log = log.WithField("user_id", event.UserID.String())
+	}
+	if event.ActorID != nil {
+		log = log.WithField("actor_id", event.ActorID.String())
+	}
+	for key, value := range map[string]string{"email": event.Email, "ip": event.IPAddress, "user_agent": event.UserAgent} {
+		if value != "" {
+			log = log.WithField(key, value)
+		}
+	}
+
+	message := "Audit event " + event.Type
+	if event.Details != "" {
+		message += ": " + event.Details
+	}
+	log.Info(message)
+}
+
+func (s *service) List(filter *Filter, cursor string, limit int) (*ListResponse, error) {
+	if limit < 1 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	var after *utils.Cursor
+	if cursor != "" {
+		decoded, err := utils.DecodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = decoded
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	events, err := s.repo.List(filter, after, limit+1)
+	if err != nil {
+		s.logger.Error("Failed to list audit events: " + err.Error())
+		return nil, err
+	}
+
+	response := &ListResponse{Events: events, Pagination: utils.CursorMeta{Limit: limit}}
+	if len(events) > limit {
+		response.Events = events[:limit]
+		last := response.Events[limit-1]
+		response.Pagination.HasMore = true
+		response.Pagination.NextCursor = utils.EncodeCursor(utils.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	return response, nil
+}
+
+func (s *service) Purge() error {
+	purged, err := s.repo.DeleteBefore(s.now().Add(-s.retention))
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		s.logger.Info("Purged audit events: " + strconv.FormatInt(purged, 10))
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package oauth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// stateCookie binds a callback to the browser that started the sign-in
+const stateCookie = "oauth_state"
+
+// Handler handles HTTP requests for OAuth sign-in
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new OAuth handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// ListProviders handles listing the providers users can sign in with
+func (h *Handler) ListProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.service.Providers()})
+}
+
+// Authorize handles starting a sign-in by redirecting to the provider
+func (h *Handler) Authorize(c *gin.Context) {
+	authURL, state, err := h.service.AuthorizeURL(c.Param("provider"))
+	if err != nil {
+		utils.RespondError(c, err, "Failed to start sign-in")
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(stateCookie, state, int(stateTTL.Seconds()), "/", "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback handles the provider returning the user with an authorization code
+func (h *Handler) Callback(c *gin.Context) {
+	if reason := c.Query("error"); reason != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Sign-in was not authorized: " + reason})
+		return
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code is required"})
+		return
+	}
+	// A state from another browser would sign this one into that account
+	if cookie, err := c.Cookie(stateCookie); err != nil || cookie != state {
+		c.JSON(http.StatusBadRequest, gin.H{"error": ErrInvalidState.Error()})
+		return
+	}
+
+	session, err := h.service.Callback(c.Request.Context(), c.Param("provider"), code, state, Client{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	})
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(stateCookie, "", -1, "/", "", c.Request.TLS != nil, true)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidState):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrEmailNotVerified):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrProvider):
+			c.JSON(http.StatusBadGateway, gin.H{"error": ErrProvider.Error()})
+		default:
+			utils.RespondError(c, err, "Failed to sign in")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// RegisterRoutes registers all OAuth routes. They are public: the provider
+// proves who signs in.
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	oauth := router.Group("/auth/oauth")
+	{
+		oauth.GET("", h.ListProviders)
+		oauth.GET("/:provider", h.Authorize)
+		oauth.GET("/:provider/callback", h.Callback)
+	}
+}
@@ -0,0 +1,88 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Supported providers
+const (
+	ProviderGoogle = "google"
+	ProviderGitHub = "github"
+)
+
+// Errors returned by the OAuth service and repository
+var (
+	ErrUnknownProvider  = utils.NewNotFoundError("sign-in provider not found")
+	ErrIdentityNotFound = utils.NewNotFoundError("identity not found")
+	// ErrInvalidState is returned for callbacks without the state the sign-in
+	// started with, or after it expired
+	ErrInvalidState = errors.New("invalid or expired sign-in state")
+	// ErrEmailNotVerified is returned when the provider vouches for no email
+	ErrEmailNotVerified = errors.New("provider account has no verified email")
+	// ErrProvider is returned when the provider rejects the code or fails
+	ErrProvider = errors.New("sign-in provider request failed")
+)
+
+// Identity links an account of a provider to a user
+type Identity struct {
+	Provider  string    `gorm:"size:20;primaryKey"`
+	Subject   string    `gorm:"size:255;primaryKey"` // The provider's ID of the account
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	Email     string    `gorm:"size:255;not null"` // Verified email when linked
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName returns the table name for GORM
+func (Identity) TableName() string {
+	return "oauth_identities"
+}
+
+// Profile is the account a provider signed in
+type Profile struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// Session is the tokens issued on sign-in, as returned by login
+type Session struct {
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// Repository defines the interface for identity persistence
+type Repository interface {
+	FindIdentity(provider, subject string) (*Identity, error)
+	CreateIdentity(identity *Identity) error
+}
+
+// Accounts finds, creates and signs in users (dependency inversion)
+type Accounts interface {
+	// FindOrCreateByEmail returns the user with a verified email, creating one
+	// when none exists, and reports whether it was created
+	FindOrCreateByEmail(email string) (uuid.UUID, bool, error)
+	StartSession(userID uuid.UUID, client Client) (*Session, error)
+}
+
+// Client is the device signing in, recorded on the session
+type Client struct {
+	UserAgent string
+	IPAddress string
+}
+
+// Service defines the interface for OAuth sign-in
+type Service interface {
+	// Providers lists the configured providers
+	Providers() []string
+	// AuthorizeURL starts a sign-in, returning the provider's consent page
+	// and the state the callback must carry
+	AuthorizeURL(provider string) (url string, state string, err error)
+	// Callback completes a sign-in with the code the provider returned
+	Callback(ctx context.Context, provider, code, state string, client Client) (*Session, error)
+}
@@ -0,0 +1,300 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+	return log
+}
+
+// mockRepository keeps identities in memory
+type mockRepository struct {
+	mu         sync.Mutex
+	identities map[string]Identity
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{identities: make(map[string]Identity)}
+}
+
+func (m *mockRepository) FindIdentity(provider, subject string) (*Identity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	identity, ok := m.identities[provider+"/"+subject]
+	if !ok {
+		return nil, ErrIdentityNotFound
+	}
+	return &identity, nil
+}
+
+func (m *mockRepository) CreateIdentity(identity *Identity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.identities[identity.Provider+"/"+identity.Subject] = *identity
+	return nil
+}
+
+// mockAccounts keeps users by email and issues the user ID as the token
+type mockAccounts struct {
+	users map[string]uuid.UUID
+}
+
+func (m *mockAccounts) FindOrCreateByEmail(email string) (uuid.UUID, bool, error) {
+	if id, ok := m.users[email]; ok {
+		return id, false, nil
+	}
+	id := uuid.New()
+	m.users[email] = id
+	return id, true, nil
+}
+
+func (m *mockAccounts) StartSession(userID uuid.UUID, client Client) (*Session, error) {
+	return &Session{Token: userID.String(), RefreshToken: "refresh"}, nil
+}
+
+// fakeProvider serves the token and profile endpoints of Google and GitHub
+type fakeProvider struct {
+	*httptest.Server
+	googleInfo   map[string]any
+	githubEmails []map[string]any
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	f := &fakeProvider{
+		googleInfo: map[string]any{"sub": "g-123", "email": "reader@example.com", "email_verified": true},
+		githubEmails: []map[string]any{
+			{"email": "old@example.com", "primary": false, "verified": true},
+			{"email": "reader@example.com", "primary": true, "verified": true},
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		if r.PostForm.Get("code") != "good-code" || r.PostForm.Get("client_secret") != "secret" {
+			// GitHub reports a bad code with status 200
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "access", "token_type": "bearer"})
+	})
+	authorized := func(next func(w http.ResponseWriter) any) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer access" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(next(w))
+		}
+	}
+	mux.HandleFunc("/v1/userinfo", authorized(func(w http.ResponseWriter) any { return f.googleInfo }))
+	mux.HandleFunc("/user", authorized(func(w http.ResponseWriter) any { return map[string]any{"id": 42} }))
+	mux.HandleFunc("/user/emails", authorized(func(w http.ResponseWriter) any { return f.githubEmails }))
+
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+// newTestService creates a service with both providers pointed at the fake
+func newTestService(t *testing.T, fake *fakeProvider, repo Repository, accounts Accounts) *service {
+	svc, err := NewService(&config.OAuthConfig{
+		GoogleClientID:     "google-client",
+		GoogleClientSecret: "secret",
+		GitHubClientID:     "github-client",
+		GitHubClientSecret: "secret",
+		RedirectBaseURL:    "https://api.example.com/api/v1/auth/oauth/",
+	}, &config.JWTConfig{Secret: "test-secret"}, repo, accounts, newTestLogger(t))
+	require.NoError(t, err)
+
+	s := svc.(*service)
+	for _, p := range s.providers {
+		p.tokenURL = fake.URL + "/token"
+		p.apiURL = fake.URL
+		p.client = fake.Client()
+	}
+	return s
+}
+
+func TestCallback(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Creates a user on first sign-in and reuses the identity", func(t *testing.T) {
+		fake := newFakeProvider(t)
+		repo := newMockRepository()
+		accounts := &mockAccounts{users: make(map[string]uuid.UUID)}
+		svc := newTestService(t, fake, repo, accounts)
+
+		authURL, state, err := svc.AuthorizeURL(ProviderGoogle)
+		require.NoError(t, err)
+		parsed, err := url.Parse(authURL)
+		require.NoError(t, err)
+		assert.Equal(t, "accounts.google.com", parsed.Host)
+		assert.Equal(t, state, parsed.Query().Get("state"))
+		assert.Equal(t, "https://api.example.com/api/v1/auth/oauth/google/callback", parsed.Query().Get("redirect_uri"))
+
+		session, err := svc.Callback(ctx, ProviderGoogle, "good-code", state, Client{})
+		require.NoError(t, err)
+		userID := accounts.users["reader@example.com"]
+		assert.Equal(t, userID.String(), session.Token)
+
+		identity, err := repo.FindIdentity(ProviderGoogle, "g-123")
+		require.NoError(t, err)
+		assert.Equal(t, userID, identity.UserID)
+
+		// The identity keeps signing in the user after the email changes
+		fake.googleInfo["email"] = "renamed@example.com"
+		_, state, err = svc.AuthorizeURL(ProviderGoogle)
+		require.NoError(t, err)
+		session, err = svc.Callback(ctx, ProviderGoogle, "good-code", state, Client{})
+		require.NoError(t, err)
+		assert.Equal(t, userID.String(), session.Token)
+		assert.Len(t, accounts.users, 1)
+	})
+
+	t.Run("Links an existing user by verified email", func(t *testing.T) {
+		fake := newFakeProvider(t)
+		userID := uuid.New()
+		accounts := &mockAccounts{users: map[string]uuid.UUID{"reader@example.com": userID}}
+		svc := newTestService(t, fake, newMockRepository(), accounts)
+
+		_, state, err := svc.AuthorizeURL(ProviderGitHub)
+		require.NoError(t, err)
+		session, err := svc.Callback(ctx, ProviderGitHub, "good-code", state, Client{})
+		require.NoError(t, err)
+		assert.Equal(t, userID.String(), session.Token, "the primary email links the user")
+	})
+
+	t.Run("Rejects unverified emails", func(t *testing.T) {
+		fake := newFakeProvider(t)
+		fake.googleInfo["email_verified"] = false
+		fake.githubEmails[1]["verified"] = false
+		accounts := &mockAccounts{users: map[string]uuid.UUID{"reader@example.com": uuid.New()}}
+		svc := newTestService(t, fake, newMockRepository(), accounts)
+
+		for _, name := range []string{ProviderGoogle, ProviderGitHub} {
+			_, state, err := svc.AuthorizeURL(name)
+			require.NoError(t, err)
+			_, err = svc.Callback(ctx, name, "good-code", state, Client{})
+			assert.ErrorIs(t, err, ErrEmailNotVerified, name)
+		}
+	})
+
+	t.Run("Rejects invalid states", func(t *testing.T) {
+		fake := newFakeProvider(t)
+		svc := newTestService(t, fake, newMockRepository(), &mockAccounts{users: make(map[string]uuid.UUID)})
+
+		_, state, err := svc.AuthorizeURL(ProviderGoogle)
+		require.NoError(t, err)
+
+		_, err = svc.Callback(ctx, ProviderGitHub, "good-code", state, Client{})
+		assert.ErrorIs(t, err, ErrInvalidState, "states are bound to their provider")
+		_, err = svc.Callback(ctx, ProviderGoogle, "good-code", state+"x", Client{})
+		assert.ErrorIs(t, err, ErrInvalidState)
+		_, err = svc.Callback(ctx, ProviderGoogle, "good-code", "", Client{})
+		assert.ErrorIs(t, err, ErrInvalidState)
+
+		expired := strings.Replace(state, strings.SplitN(state, ".", 2)[0], "1", 1)
+		_, err = svc.Callback(ctx, ProviderGoogle, "good-code", expired, Client{})
+		assert.ErrorIs(t, err, ErrInvalidState)
+	})
+
+	t.Run("Reports provider failures", func(t *testing.T) {
+		fake := newFakeProvider(t)
+		svc := newTestService(t, fake, newMockRepository(), &mockAccounts{users: make(map[string]uuid.UUID)})
+
+		_, state, err := svc.AuthorizeURL(ProviderGitHub)
+		require.NoError(t, err)
+		_, err = svc.Callback(ctx, ProviderGitHub, "bad-code", state, Client{})
+		assert.ErrorIs(t, err, ErrProvider)
+	})
+
+	t.Run("Unknown provider", func(t *testing.T) {
+		svc := newTestService(t, newFakeProvider(t), newMockRepository(), &mockAccounts{})
+		_, _, err := svc.AuthorizeURL("myspace")
+		assert.ErrorIs(t, err, ErrUnknownProvider)
+	})
+}
+
+func TestNewService(t *testing.T) {
+	log := newTestLogger(t)
+	jwtCfg := &config.JWTConfig{Secret: "test-secret"}
+
+	svc, err := NewService(&config.OAuthConfig{}, jwtCfg, newMockRepository(), &mockAccounts{}, log)
+	require.NoError(t, err)
+	assert.Empty(t, svc.Providers())
+
+	svc, err = NewService(&config.OAuthConfig{GitHubClientID: "id", GitHubClientSecret: "secret", RedirectBaseURL: "https://api.example.com/api/v1/auth/oauth"}, jwtCfg, newMockRepository(), &mockAccounts{}, log)
+	require.NoError(t, err)
+	assert.Equal(t, []string{ProviderGitHub}, svc.Providers())
+
+	_, err = NewService(&config.OAuthConfig{GitHubClientID: "id", GitHubClientSecret: "secret"}, jwtCfg, newMockRepository(), &mockAccounts{}, log)
+	assert.Error(t, err, "a redirect base URL is required")
+	_, err = NewService(&config.OAuthConfig{GitHubClientID: "id", RedirectBaseURL: "https://api.example.com"}, jwtCfg, newMockRepository(), &mockAccounts{}, log)
+	assert.Error(t, err, "a client secret is required")
+	_, err = NewService(&config.OAuthConfig{GitHubClientID: "id", GitHubClientSecret: "secret", RedirectBaseURL: "/auth/oauth"}, jwtCfg, newMockRepository(), &mockAccounts{}, log)
+	assert.Error(t, err, "the redirect base URL must be absolute")
+}
+
+func TestHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fake := newFakeProvider(t)
+	svc := newTestService(t, fake, newMockRepository(), &mockAccounts{users: make(map[string]uuid.UUID)})
+	router := gin.New()
+	NewHandler(svc).RegisterRoutes(router.Group("/api/v1"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/auth/oauth/google", nil))
+	require.Equal(t, http.StatusFound, w.Code)
+	parsed, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	state := parsed.Query().Get("state")
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, state, cookies[0].Value)
+	assert.True(t, cookies[0].HttpOnly)
+
+	callback := "/api/v1/auth/oauth/google/callback?code=good-code&state=" + url.QueryEscape(state)
+
+	t.Run("Rejects callbacks from another browser", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", callback, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Signs in with the state cookie", func(t *testing.T) {
+		req := httptest.NewRequest("GET", callback, nil)
+		req.AddCookie(cookies[0])
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var session Session
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &session))
+		assert.NotEmpty(t, session.Token)
+		assert.Equal(t, "refresh", session.RefreshToken)
+	})
+
+	t.Run("Unknown provider", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/auth/oauth/myspace", nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
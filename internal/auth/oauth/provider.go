@@ -0,0 +1,202 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// maxResponseSize bounds responses read from providers
+const maxResponseSize = 1 << 20
+
+// provider is an OAuth 2.0 authorization server and the API telling who
+// signed in
+type provider struct {
+	name         string
+	authURL      string
+	tokenURL     string
+	apiURL       string // Base of the profile endpoints
+	clientID     string
+	clientSecret string
+	scopes       []string
+	// profile reads the signed-in account with the access token
+	profile func(ctx context.Context, p *provider, accessToken string) (*Profile, error)
+	client  *http.Client
+}
+
+// newGoogleProvider signs in with Google accounts through OpenID Connect
+func newGoogleProvider(clientID, clientSecret string, client *http.Client) *provider {
+	return &provider{
+		name:         ProviderGoogle,
+		authURL:      "https://accounts.google.com/o/oauth2/v2/auth",
+		tokenURL:     "https://oauth2.googleapis.com/token",
+		apiURL:       "https://openidconnect.googleapis.com",
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scopes:       []string{"openid", "email"},
+		profile:      googleProfile,
+		client:       client,
+	}
+}
+
+// newGitHubProvider signs in with GitHub accounts
+func newGitHubProvider(clientID, clientSecret string, client *http.Client) *provider {
+	return &provider{
+		name:         ProviderGitHub,
+		authURL:      "https://github.com/login/oauth/authorize",
+		tokenURL:     "https://github.com/login/oauth/access_token",
+		apiURL:       "https://api.github.com",
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scopes:       []string{"read:user", "user:email"},
+		profile:      githubProfile,
+		client:       client,
+	}
+}
+
+// authorizeURL returns the consent page asking the user to sign in
+func (p *provider) authorizeURL(redirectURI, state string) string {
+	query := url.Values{
+		"response_type": {"code"},
+		"client_id":     {p.clientID},
+		"redirect_uri":  {redirectURI},
+		"scope":         {strings.Join(p.scopes, " ")},
+		"state":         {state},
+	}
+	return p.authURL + "?" + query.Encode()
+}
+
+// exchange trades an authorization code for an access token
+func (p *provider) exchange(ctx context.Context, code, redirectURI string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {p.clientID},
+		"client_secret": {p.clientSecret},
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	// GitHub reports errors with status 200, so the body decides
+	status, err := p.do(req, &token)
+	if err != nil {
+		return "", err
+	}
+	if token.Error != "" {
+		return "", fmt.Errorf("token exchange failed: %s %s", token.Error, token.ErrorDescription)
+	}
+	if status != http.StatusOK || token.AccessToken == "" {
+		return "", fmt.Errorf("token exchange failed: HTTP %d", status)
+	}
+
+	return token.AccessToken, nil
+}
+
+// get reads a profile endpoint with the access token
+func (p *provider) get(ctx context.Context, path, accessToken string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.apiURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	status, err := p.do(req, v)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("GET %s failed: HTTP %d", path, status)
+	}
+	return nil
+}
+
+// do sends the request and decodes the JSON response into v
+func (p *provider) do(req *http.Request, v any) (int, error) {
+	req.Header.Set("User-Agent", "Articles-Backend/1.0") // Required by GitHub
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		if err := json.Unmarshal(data, v); err != nil {
+			return 0, fmt.Errorf("invalid response from %s: %w", req.URL.Host, err)
+		}
+	} else {
+		// Error bodies are decoded when they are JSON, for their error fields
+		_ = json.Unmarshal(data, v)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// googleProfile reads the OpenID Connect claims of the account
+func googleProfile(ctx context.Context, p *provider, accessToken string) (*Profile, error) {
+	var info struct {
+		Subject       string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	if err := p.get(ctx, "/v1/userinfo", accessToken, &info); err != nil {
+		return nil, err
+	}
+	if info.Subject == "" {
+		return nil, fmt.Errorf("userinfo response has no subject")
+	}
+
+	return &Profile{Subject: info.Subject, Email: info.Email, EmailVerified: info.EmailVerified}, nil
+}
+
+// githubProfile reads the account and its primary email, which GitHub only
+// vouches for once verified
+func githubProfile(ctx context.Context, p *provider, accessToken string) (*Profile, error) {
+	var account struct {
+		ID int64 `json:"id"`
+	}
+	if err := p.get(ctx, "/user", accessToken, &account); err != nil {
+		return nil, err
+	}
+	if account.ID == 0 {
+		return nil, fmt.Errorf("user response has no ID")
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.get(ctx, "/user/emails", accessToken, &emails); err != nil {
+		return nil, err
+	}
+
+	profile := &Profile{Subject: strconv.FormatInt(account.ID, 10)}
+	for _, email := range emails {
+		if email.Primary {
+			profile.Email = email.Email
+			profile.EmailVerified = email.Verified
+		}
+	}
+	return profile, nil
+}
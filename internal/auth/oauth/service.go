@@ -0,0 +1,224 @@
+package oauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+)
+
+const (
+	// stateTTL is how long a user has to complete a sign-in at the provider
+	stateTTL = 10 * time.Minute
+	// providerTimeout bounds each request to a provider
+	providerTimeout = 10 * time.Second
+)
+
+// service implements the Service interface
+type service struct {
+	providers       map[string]*provider
+	redirectBaseURL string
+	signingKey      []byte
+	repo            Repository
+	accounts        Accounts
+	logger          *logger.Logger
+	auditLogger     *logger.Logger
+}
+
+// NewService creates an OAuth service for the providers with a client ID
+// configured. States are signed with a key derived from the JWT secret.
+func NewService(cfg *config.OAuthConfig, jwtCfg *config.JWTConfig, repo Repository, accounts Accounts, log *logger.Logger) (Service, error) {
+	client := &http.Client{Timeout: providerTimeout}
+	providers := make(map[string]*provider)
+	if cfg != nil && cfg.GoogleClientID != "" {
+		providers[ProviderGoogle] = newGoogleProvider(cfg.GoogleClientID, cfg.GoogleClientSecret, client)
+	}
+	if cfg != nil && cfg.GitHubClientID != "" {
+		providers[ProviderGitHub] = newGitHubProvider(cfg.GitHubClientID, cfg.GitHubClientSecret, client)
+	}
+
+	var redirectBaseURL string
+	if len(providers) > 0 {
+		for name, p := range providers {
+			if p.clientSecret == "" {
+				return nil, fmt.Errorf("invalid OAuth configuration: %s client secret is required", name)
+			}
+		}
+		if cfg.RedirectBaseURL == "" {
+			return nil, errors.New("invalid OAuth configuration: redirect base URL is required")
+		}
+		if parsed, err := url.Parse(cfg.RedirectBaseURL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid OAuth redirect base URL '%s': must be an absolute URL", cfg.RedirectBaseURL)
+		}
+		if jwtCfg == nil || jwtCfg.Secret == "" {
+			return nil, errors.New("OAuth sign-in requires a JWT secret to sign states")
+		}
+		redirectBaseURL = strings.TrimSuffix(cfg.RedirectBaseURL, "/")
+	}
+
+	var secret string
+	if jwtCfg != nil {
+		secret = jwtCfg.Secret
+	}
+	// A separate key keeps state signatures from being usable as anything else
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("oauth-state"))
+
+	return &service{
+		providers:       providers,
+		redirectBaseURL: redirectBaseURL,
+		signingKey:      mac.Sum(nil),
+		repo:            repo,
+		accounts:        accounts,
+		logger:          log.WithComponent("oauth-service"),
+		auditLogger:     log.WithComponent("audit"),
+	}, nil
+}
+
+func (s *service) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *service) AuthorizeURL(name string) (string, string, error) {
+	p, ok := s.providers[name]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+
+	state, err := s.newState(name)
+	if err != nil {
+		return "", "", err
+	}
+
+	return p.authorizeURL(s.redirectURI(name), state), state, nil
+}
+
+func (s *service) Callback(ctx context.Context, name, code, state string, client Client) (*Session, error) {
+	p, ok := s.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	if !s.checkState(name, state) {
+		return nil, ErrInvalidState
+	}
+
+	accessToken, err := p.exchange(ctx, code, s.redirectURI(name))
+	if err != nil {
+		s.logger.Error("Failed to exchange " + name + " authorization code: " + err.Error())
+		return nil, fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+	profile, err := p.profile(ctx, p, accessToken)
+	if err != nil {
+		s.logger.Error("Failed to read " + name + " profile: " + err.Error())
+		return nil, fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+
+	identity, err := s.repo.FindIdentity(name, profile.Subject)
+	if err != nil && !errors.Is(err, ErrIdentityNotFound) {
+		return nil, err
+	}
+	if identity == nil {
+		identity, err = s.link(name, profile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	session, err := s.accounts.StartSession(identity.UserID, client)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("User signed in with " + name + ": " + identity.UserID.String())
+	return session, nil
+}
+
+// link creates the identity of a provider account signing in for the first
+// time, on the user with its verified email
+func (s *service) link(name string, profile *Profile) (*Identity, error) {
+	// Whoever controls the email gets the account, so only an email the
+	// provider verified may link one
+	if profile.Email == "" || !profile.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	userID, created, err := s.accounts.FindOrCreateByEmail(profile.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Provider: name,
+		Subject:  profile.Subject,
+		UserID:   userID,
+		Email:    profile.Email,
+	}
+	if err := s.repo.CreateIdentity(identity); err != nil {
+		return nil, err
+	}
+
+	if created {
+		s.auditLogger.Info("User " + userID.String() + " created by " + name + " sign-in of " + profile.Email)
+	} else {
+		s.auditLogger.Warn(name + " account " + profile.Subject + " linked to user " + userID.String() + " by verified email " + profile.Email)
+	}
+	return identity, nil
+}
+
+// redirectURI is where the provider sends the user back to
+func (s *service) redirectURI(name string) string {
+	return s.redirectBaseURL + "/" + name + "/callback"
+}
+
+// newState returns a signed "<expiry>.<nonce>.<signature>" for the provider.
+// The nonce makes each state unique, so it can bind the callback to the
+// browser that started the sign-in.
+func (s *service) newState(name string) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	payload := strconv.FormatInt(time.Now().Add(stateTTL).Unix(), 10) + "." + base64.RawURLEncoding.EncodeToString(nonce)
+	return payload + "." + s.sign(name, payload), nil
+}
+
+// checkState verifies a state made by newState for the provider
+func (s *service) checkState(name, state string) bool {
+	i := strings.LastIndex(state, ".")
+	if i < 0 {
+		return false
+	}
+	payload, signature := state[:i], state[i+1:]
+	if !hmac.Equal([]byte(signature), []byte(s.sign(name, payload))) {
+		return false
+	}
+
+	expiry, _, _ := strings.Cut(payload, ".")
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	return err == nil && time.Now().Unix() < expiresAt
+}
+
+// sign returns the signature of a state payload for the provider
+func (s *service) sign(name, payload string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(name + "." + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,31 @@
+package signing
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler serves the public keys tokens are signed with
+type Handler struct {
+	keys *KeySet
+}
+
+// NewHandler creates a new JWKS handler
+func NewHandler(keys *KeySet) *Handler {
+	return &Handler{
+		keys: keys,
+	}
+}
+
+// GetJWKS handles listing the public keys other services verify tokens with
+func (h *Handler) GetJWKS(c *gin.Context) {
+	// Short caching lets verifiers pick up a rotation quickly
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
+
+// RegisterRoutes registers the JWKS route at its well-known location
+func (h *Handler) RegisterRoutes(router gin.IRoutes) {
+	router.GET("/.well-known/jwks.json", h.GetJWKS)
+}
@@ -0,0 +1,270 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSABits is the smallest RSA modulus accepted for signing keys
+const minRSABits = 2048
+
+// defaultSecret signs HS256 tokens when no secret is configured
+const defaultSecret = "change-me-in-production"
+
+// ErrUnknownKey is returned for tokens signed with a key not in the set
+var ErrUnknownKey = errors.New("token signed with an unknown key")
+
+// Key is an asymmetric key that signs or verifies tokens
+type Key struct {
+	ID        string // Thumbprint of the public key, sent as the "kid" header
+	method    jwt.SigningMethod
+	private   crypto.Signer // Nil for keys that only verify
+	publicKey crypto.PublicKey
+}
+
+// KeySet signs tokens with its signing key and verifies tokens signed with
+// any of its keys, so tokens outlive a key rotation
+type KeySet struct {
+	secret  []byte // Signs and verifies HS256 tokens, which carry no key ID
+	hmac    bool   // HS256 tokens are accepted
+	signing *Key   // Nil to sign with the secret
+	keys    map[string]*Key
+}
+
+// NewKeySet loads the signing and verification keys. Without a signing key,
+// tokens are signed with HS256 and the shared secret, as before asymmetric
+// keys were supported.
+func NewKeySet(cfg *config.JWTConfig) (*KeySet, error) {
+	secret := defaultSecret
+	if cfg != nil && cfg.Secret != "" {
+		secret = cfg.Secret
+	}
+	set := &KeySet{secret: []byte(secret), hmac: true, keys: make(map[string]*Key)}
+	if cfg == nil || cfg.SigningKeyFile == "" {
+		if cfg != nil && cfg.VerificationKeyFiles != "" {
+			return nil, errors.New("JWT verification keys require a signing key")
+		}
+		return set, nil
+	}
+
+	signingKey, err := loadKey(cfg.SigningKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if signingKey.private == nil {
+		return nil, fmt.Errorf("invalid JWT signing key '%s': must be a private key", cfg.SigningKeyFile)
+	}
+	set.signing = signingKey
+	set.keys[signingKey.ID] = signingKey
+
+	for _, path := range strings.Split(cfg.VerificationKeyFiles, ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		key, err := loadKey(path)
+		if err != nil {
+			return nil, err
+		}
+		// Only the public half of retired keys is needed
+		key.private = nil
+		if _, exists := set.keys[key.ID]; !exists {
+			set.keys[key.ID] = key
+		}
+	}
+
+	// HS256 tokens issued before switching to a signing key can be accepted
+	// until they expire
+	set.hmac = false
+	if cfg.AcceptSecretTokens != "" {
+		accept, err := strconv.ParseBool(cfg.AcceptSecretTokens)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT accept HS256 setting '%s': must be true or false", cfg.AcceptSecretTokens)
+		}
+		set.hmac = accept
+	}
+
+	return set, nil
+}
+
+// Algorithm returns the algorithm new tokens are signed with
+func (s *KeySet) Algorithm() string {
+	if s.signing == nil {
+		return jwt.SigningMethodHS256.Alg()
+	}
+	return s.signing.method.Alg()
+}
+
+// Sign signs the claims with the signing key
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	if s.signing == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	}
+
+	token := jwt.NewWithClaims(s.signing.method, claims)
+	token.Header["kid"] = s.signing.ID
+	return token.SignedString(s.signing.private)
+}
+
+// Parse verifies the token with the key it names and decodes its claims
+func (s *KeySet) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, s.keyFunc, jwt.WithValidMethods(s.methods()))
+}
+
+// keyFunc returns the key verifying the token
+func (s *KeySet) keyFunc(token *jwt.Token) (any, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if !s.hmac {
+			return nil, ErrUnknownKey
+		}
+		return s.secret, nil
+	}
+
+	id, _ := token.Header["kid"].(string)
+	key, ok := s.keys[id]
+	// A key verifies only tokens of its own algorithm
+	if !ok || key.method.Alg() != token.Method.Alg() {
+		return nil, ErrUnknownKey
+	}
+	return key.publicKey, nil
+}
+
+// methods lists the algorithms accepted for tokens. Every asymmetric
+// algorithm is listed so tokens from dropped keys fail with ErrUnknownKey;
+// keyFunc still matches each key to its own algorithm.
+func (s *KeySet) methods() []string {
+	methods := []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+	if s.hmac {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	return methods
+}
+
+// JWK is a public key in the JSON Web Key format (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Curve     string `json:"crv,omitempty"` // OKP keys
+	X         string `json:"x,omitempty"`   // OKP keys
+	N         string `json:"n,omitempty"`   // RSA keys
+	E         string `json:"e,omitempty"`   // RSA keys
+}
+
+// JWKS is a set of public keys, as served for other services to verify tokens
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the set, the signing key first. HS256
+// secrets are never published.
+func (s *KeySet) JWKS() *JWKS {
+	set := &JWKS{Keys: []JWK{}}
+	for _, key := range s.keys {
+		set.Keys = append(set.Keys, key.jwk())
+	}
+	sort.SliceStable(set.Keys, func(i, j int) bool {
+		if s.signing != nil && (set.Keys[i].KeyID == s.signing.ID) != (set.Keys[j].KeyID == s.signing.ID) {
+			return set.Keys[i].KeyID == s.signing.ID
+		}
+		return set.Keys[i].KeyID < set.Keys[j].KeyID
+	})
+
+	return set
+}
+
+// jwk returns the public half of the key as a JWK
+func (k *Key) jwk() JWK {
+	jwk := JWK{Use: "sig", Algorithm: k.method.Alg(), KeyID: k.ID}
+	switch publicKey := k.publicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	}
+	return jwk
+}
+
+// loadKey reads a PEM-encoded RSA or Ed25519 key. Private keys may be PKCS #8
+// or PKCS #1; public keys are PKIX.
+func loadKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT key: %w", err)
+	}
+	key, err := parseKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT key '%s': %v", path, err)
+	}
+	return key, nil
+}
+
+// parseKey decodes the first PEM block of data into a key
+func parseKey(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block '%s'", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{}
+	if signer, ok := parsed.(crypto.Signer); ok {
+		key.private = signer
+		parsed = signer.Public()
+	}
+	switch publicKey := parsed.(type) {
+	case *rsa.PublicKey:
+		if publicKey.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSABits)
+		}
+		key.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T: must be RSA or Ed25519", publicKey)
+	}
+	key.publicKey = parsed
+
+	der, err := x509.MarshalPKIXPublicKey(parsed)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+	key.ID = base64.RawURLEncoding.EncodeToString(sum[:])[:16]
+
+	return key, nil
+}
@@ -0,0 +1,185 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKey writes the private key, or its public half, as a PEM file
+func writeKey(t *testing.T, key any, public bool) string {
+	var block *pem.Block
+	if public {
+		der, err := x509.MarshalPKIXPublicKey(key.(crypto.Signer).Public())
+		require.NoError(t, err)
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	} else {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0o600))
+	return path
+}
+
+func newClaims() jwt.MapClaims {
+	return jwt.MapClaims{"user_id": "7c9e6679-7425-40de-944b-e07fc1f90ae7", "exp": time.Now().Add(time.Hour).Unix()}
+}
+
+func TestKeySet(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("Signs with the secret by default", func(t *testing.T) {
+		keys, err := NewKeySet(&config.JWTConfig{Secret: "secret"})
+		require.NoError(t, err)
+		assert.Equal(t, "HS256", keys.Algorithm())
+
+		token, err := keys.Sign(newClaims())
+		require.NoError(t, err)
+		parsed, err := jwt.Parse(token, func(*jwt.Token) (any, error) { return []byte("secret"), nil })
+		require.NoError(t, err, "tokens are the same as before signing keys")
+		assert.NotContains(t, parsed.Header, "kid")
+
+		_, err = keys.Parse(token, jwt.MapClaims{})
+		assert.NoError(t, err)
+		assert.Empty(t, keys.JWKS().Keys, "secrets are never published")
+	})
+
+	t.Run("Signs with RS256 and EdDSA keys", func(t *testing.T) {
+		for alg, key := range map[string]any{"RS256": rsaKey, "EdDSA": edKey} {
+			keys, err := NewKeySet(&config.JWTConfig{Secret: "secret", SigningKeyFile: writeKey(t, key, false)})
+			require.NoError(t, err)
+			assert.Equal(t, alg, keys.Algorithm())
+
+			token, err := keys.Sign(newClaims())
+			require.NoError(t, err)
+			parsed, err := keys.Parse(token, jwt.MapClaims{})
+			require.NoError(t, err, alg)
+			assert.Equal(t, alg, parsed.Method.Alg())
+			assert.Equal(t, keys.signing.ID, parsed.Header["kid"])
+		}
+	})
+
+	t.Run("Retired keys keep verifying", func(t *testing.T) {
+		oldKeys, err := NewKeySet(&config.JWTConfig{SigningKeyFile: writeKey(t, rsaKey, false)})
+		require.NoError(t, err)
+		oldToken, err := oldKeys.Sign(newClaims())
+		require.NoError(t, err)
+
+		rotated, err := NewKeySet(&config.JWTConfig{
+			SigningKeyFile:       writeKey(t, edKey, false),
+			VerificationKeyFiles: writeKey(t, rsaKey, true),
+		})
+		require.NoError(t, err)
+		_, err = rotated.Parse(oldToken, jwt.MapClaims{})
+		assert.NoError(t, err)
+
+		jwks := rotated.JWKS()
+		require.Len(t, jwks.Keys, 2)
+		assert.Equal(t, "OKP", jwks.Keys[0].KeyType, "the signing key comes first")
+		assert.Equal(t, "Ed25519", jwks.Keys[0].Curve)
+		assert.Equal(t, "RSA", jwks.Keys[1].KeyType)
+		assert.Equal(t, "AQAB", jwks.Keys[1].E)
+
+		// Once the retired key is dropped, its tokens stop working
+		dropped, err := NewKeySet(&config.JWTConfig{SigningKeyFile: writeKey(t, edKey, false)})
+		require.NoError(t, err)
+		_, err = dropped.Parse(oldToken, jwt.MapClaims{})
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("HS256 tokens are rejected after switching unless accepted", func(t *testing.T) {
+		secretKeys, err := NewKeySet(&config.JWTConfig{Secret: "secret"})
+		require.NoError(t, err)
+		secretToken, err := secretKeys.Sign(newClaims())
+		require.NoError(t, err)
+
+		keyFile := writeKey(t, rsaKey, false)
+		switched, err := NewKeySet(&config.JWTConfig{Secret: "secret", SigningKeyFile: keyFile})
+		require.NoError(t, err)
+		_, err = switched.Parse(secretToken, jwt.MapClaims{})
+		assert.Error(t, err)
+
+		accepting, err := NewKeySet(&config.JWTConfig{Secret: "secret", SigningKeyFile: keyFile, AcceptSecretTokens: "true"})
+		require.NoError(t, err)
+		_, err = accepting.Parse(secretToken, jwt.MapClaims{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Rejects tokens naming another key or algorithm", func(t *testing.T) {
+		keys, err := NewKeySet(&config.JWTConfig{SigningKeyFile: writeKey(t, rsaKey, false)})
+		require.NoError(t, err)
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, newClaims())
+		token.Header["kid"] = "unknown"
+		signed, err := token.SignedString(rsaKey)
+		require.NoError(t, err)
+		_, err = keys.Parse(signed, jwt.MapClaims{})
+		assert.ErrorIs(t, err, ErrUnknownKey)
+
+		_, err = keys.Parse(mustSign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType), jwt.MapClaims{})
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid configuration", func(t *testing.T) {
+		weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.NoError(t, err)
+		invalidPath := filepath.Join(t.TempDir(), "invalid.pem")
+		require.NoError(t, os.WriteFile(invalidPath, []byte("not a key"), 0o600))
+
+		for name, cfg := range map[string]*config.JWTConfig{
+			"missing file":                 {SigningKeyFile: filepath.Join(t.TempDir(), "missing.pem")},
+			"not PEM":                      {SigningKeyFile: invalidPath},
+			"public signing key":           {SigningKeyFile: writeKey(t, rsaKey, true)},
+			"weak RSA key":                 {SigningKeyFile: writeKey(t, weakKey, false)},
+			"verification without signing": {VerificationKeyFiles: writeKey(t, rsaKey, true)},
+			"invalid accept setting":       {SigningKeyFile: writeKey(t, edKey, false), AcceptSecretTokens: "maybe"},
+		} {
+			_, err := NewKeySet(cfg)
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func mustSign(t *testing.T, method jwt.SigningMethod, key any) string {
+	signed, err := jwt.NewWithClaims(method, newClaims()).SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keys, err := NewKeySet(&config.JWTConfig{SigningKeyFile: writeKey(t, edKey, false)})
+	require.NoError(t, err)
+
+	router := gin.New()
+	NewHandler(keys).RegisterRoutes(router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"kid":"`+keys.signing.ID+`"`)
+	assert.NotContains(t, w.Body.String(), `"d"`, "private keys are never published")
+}
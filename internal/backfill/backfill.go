@@ -0,0 +1,75 @@
+package backfill
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Embedding statuses an article can be backfilled from
+const (
+	StatusPending = "pending"
+	StatusFailed  = "failed"
+)
+
+// Limits on backfill batches
+const (
+	DefaultBatchSize = 50
+	MaxBatchSize     = 500
+)
+
+// Article is an article missing an embedding (forward declaration)
+type Article struct {
+	ID                     uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Title                  string
+	Description            string
+	Content                string
+	ContentKey             string // Set while the content lives in object storage
+	Language               string
+	EmbeddingStatus        string
+	ContentEmbeddingStatus string
+}
+
+// TableName maps the forward declaration onto the articles table
+func (Article) TableName() string {
+	return "articles"
+}
+
+// Repository defines the interface for finding articles to backfill
+type Repository interface {
+	// CountPending counts articles not in the trash whose title or content
+	// embedding has one of the statuses
+	CountPending(statuses []string) (int64, error)
+	// FindPending returns up to limit such articles with IDs after the given
+	// one, in ID order, so each run visits an article once
+	FindPending(statuses []string, after uuid.UUID, limit int) ([]*Article, error)
+	// MarkFailed sets the status of the article's title or content embedding
+	MarkFailed(articleID uuid.UUID, target string) error
+}
+
+// Options controls a backfill run
+type Options struct {
+	Statuses    []string      // Embedding statuses to backfill; both by default
+	BatchSize   int           // Articles per embedding service request
+	Interval    time.Duration // Least time between two requests, to spare the embedding service
+	MaxArticles int           // Stop after this many articles; 0 for all
+	DryRun      bool          // Only count the articles that would be backfilled
+}
+
+// Progress reports how far a backfill run got
+type Progress struct {
+	Total     int64 `json:"total"`     // Articles pending when the run started
+	Processed int   `json:"processed"` // Articles sent to the embedding service or skipped
+	Embedded  int   `json:"embedded"`  // Embeddings stored
+	Failed    int   `json:"failed"`    // Embeddings the service could not create, now marked failed
+	Skipped   int   `json:"skipped"`   // Articles without text to embed
+	Batches   int   `json:"batches"`
+}
+
+// Service defines the interface for backfill business logic
+type Service interface {
+	// Run embeds pending articles batch by batch, calling report after each
+	// batch. A failing request stops the run; running again resumes it.
+	Run(ctx context.Context, opts Options, report func(*Progress)) (*Progress, error)
+}
@@ -0,0 +1,223 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/embedding"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRepository keeps articles in memory
+type mockRepository struct {
+	articles []*Article
+	failed   map[string]bool // "<id> <target>" of embeddings marked failed
+}
+
+func (m *mockRepository) isPending(article *Article, statuses []string) bool {
+	for _, status := range statuses {
+		if article.EmbeddingStatus == status || (article.ContentEmbeddingStatus == status && (article.Content != "" || article.ContentKey != "")) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *mockRepository) CountPending(statuses []string) (int64, error) {
+	var count int64
+	for _, article := range m.articles {
+		if m.isPending(article, statuses) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockRepository) FindPending(statuses []string, after uuid.UUID, limit int) ([]*Article, error) {
+	sort.Slice(m.articles, func(i, j int) bool { return m.articles[i].ID.String() < m.articles[j].ID.String() })
+
+	var articles []*Article
+	for _, article := range m.articles {
+		if article.ID.String() > after.String() && m.isPending(article, statuses) && len(articles) < limit {
+			articles = append(articles, article)
+		}
+	}
+	return articles, nil
+}
+
+func (m *mockRepository) MarkFailed(articleID uuid.UUID, target string) error {
+	if m.failed == nil {
+		m.failed = make(map[string]bool)
+	}
+	m.failed[articleID.String()+" "+target] = true
+	return nil
+}
+
+// mockEmbeddingClient stores embeddings by marking articles embedded, and
+// fails texts equal to failText
+type mockEmbeddingClient struct {
+	embedding.EmbeddingClient
+	repo     *mockRepository
+	failText string
+	down     bool
+	batches  [][]embedding.ArticleText
+}
+
+func (m *mockEmbeddingClient) StoreArticleEmbeddings(ctx context.Context, texts []embedding.ArticleText) (*embedding.BatchStoreResponse, error) {
+	if m.down {
+		return nil, errors.New("connection refused")
+	}
+	m.batches = append(m.batches, texts)
+
+	response := &embedding.BatchStoreResponse{TotalArticles: len(texts)}
+	for _, text := range texts {
+		if text.Text == m.failText {
+			response.Results = append(response.Results, embedding.StoreResult{ArticleID: text.ID, Status: "error", Message: "model error"})
+			continue
+		}
+		for _, article := range m.repo.articles {
+			if article.ID.String() != text.ID {
+				continue
+			}
+			if text.Target == embedding.TargetContent {
+				article.ContentEmbeddingStatus = "success"
+			} else {
+				article.EmbeddingStatus = "success"
+			}
+		}
+		response.SuccessfulUpdates++
+		response.Results = append(response.Results, embedding.StoreResult{ArticleID: text.ID, Status: "success"})
+	}
+	return response, nil
+}
+
+func newTestService(t *testing.T, repo *mockRepository, client *mockEmbeddingClient) Service {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+	return NewService(repo, client, nil, log)
+}
+
+func pendingArticle(title, content string) *Article {
+	return &Article{ID: uuid.New(), Title: title, Content: content, EmbeddingStatus: StatusPending, ContentEmbeddingStatus: StatusPending}
+}
+
+func TestRun(t *testing.T) {
+	t.Run("Embeds titles and content in batches", func(t *testing.T) {
+		repo := &mockRepository{articles: []*Article{
+			pendingArticle("One", "First body"),
+			pendingArticle("Two", ""),
+			pendingArticle("Three", "Third body"),
+			{ID: uuid.New(), Title: "Done", EmbeddingStatus: "success", ContentEmbeddingStatus: "success"},
+		}}
+		client := &mockEmbeddingClient{repo: repo}
+		svc := newTestService(t, repo, client)
+
+		var reports []Progress
+		progress, err := svc.Run(context.Background(), Options{BatchSize: 2}, func(p *Progress) { reports = append(reports, *p) })
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(3), progress.Total)
+		assert.Equal(t, 3, progress.Processed)
+		assert.Equal(t, 5, progress.Embedded)
+		assert.Equal(t, 2, progress.Batches)
+		assert.Len(t, client.batches, 2)
+		require.Len(t, reports, 2)
+		assert.Equal(t, 2, reports[0].Processed)
+
+		remaining, err := repo.CountPending([]string{StatusPending, StatusFailed})
+		require.NoError(t, err)
+		assert.Zero(t, remaining)
+	})
+
+	t.Run("Marks embeddings the service could not create as failed", func(t *testing.T) {
+		broken := pendingArticle("Broken", "")
+		repo := &mockRepository{articles: []*Article{broken, pendingArticle("Fine", "")}}
+		client := &mockEmbeddingClient{repo: repo, failText: "Broken"}
+		svc := newTestService(t, repo, client)
+
+		progress, err := svc.Run(context.Background(), Options{}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 1, progress.Embedded)
+		assert.Equal(t, 1, progress.Failed)
+		assert.True(t, repo.failed[broken.ID.String()+" "+embedding.TargetTitle])
+	})
+
+	t.Run("Skips articles without text", func(t *testing.T) {
+		repo := &mockRepository{articles: []*Article{pendingArticle("", "")}}
+		client := &mockEmbeddingClient{repo: repo}
+		svc := newTestService(t, repo, client)
+
+		progress, err := svc.Run(context.Background(), Options{}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 1, progress.Skipped)
+		assert.Empty(t, client.batches)
+	})
+
+	t.Run("Stops at the article limit", func(t *testing.T) {
+		repo := &mockRepository{}
+		for i := 0; i < 5; i++ {
+			repo.articles = append(repo.articles, pendingArticle("Title", ""))
+		}
+		svc := newTestService(t, repo, &mockEmbeddingClient{repo: repo})
+
+		progress, err := svc.Run(context.Background(), Options{BatchSize: 2, MaxArticles: 3}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), progress.Total)
+		assert.Equal(t, 3, progress.Processed)
+	})
+
+	t.Run("Dry runs only count", func(t *testing.T) {
+		repo := &mockRepository{articles: []*Article{pendingArticle("Title", "Body")}}
+		client := &mockEmbeddingClient{repo: repo}
+		svc := newTestService(t, repo, client)
+
+		progress, err := svc.Run(context.Background(), Options{DryRun: true}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), progress.Total)
+		assert.Empty(t, client.batches)
+	})
+
+	t.Run("A failing request stops the run", func(t *testing.T) {
+		repo := &mockRepository{articles: []*Article{pendingArticle("Title", "")}}
+		svc := newTestService(t, repo, &mockEmbeddingClient{repo: repo, down: true})
+
+		progress, err := svc.Run(context.Background(), Options{}, nil)
+		assert.Error(t, err)
+		assert.Zero(t, progress.Embedded)
+	})
+
+	t.Run("Waits between requests until cancelled", func(t *testing.T) {
+		repo := &mockRepository{articles: []*Article{pendingArticle("One", ""), pendingArticle("Two", "")}}
+		client := &mockEmbeddingClient{repo: repo}
+		svc := newTestService(t, repo, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := svc.Run(ctx, Options{BatchSize: 1, Interval: time.Hour}, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Len(t, client.batches, 1)
+	})
+
+	t.Run("Rejects invalid options", func(t *testing.T) {
+		svc := newTestService(t, &mockRepository{}, &mockEmbeddingClient{})
+
+		for _, opts := range []Options{
+			{Statuses: []string{"success"}},
+			{BatchSize: MaxBatchSize + 1},
+			{BatchSize: -1},
+			{Interval: -time.Second},
+			{MaxArticles: -1},
+		} {
+			_, err := svc.Run(context.Background(), opts, nil)
+			assert.Error(t, err, opts)
+		}
+	})
+}
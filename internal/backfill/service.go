@@ -0,0 +1,216 @@
+package backfill
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/embedding"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/dustin/articles-backend/pkg/storage"
+	"github.com/google/uuid"
+)
+
+// service implements the Service interface
+type service struct {
+	repo   Repository
+	client embedding.EmbeddingClient
+	store  storage.Storage // Nil when object storage is disabled
+	logger *logger.Logger
+}
+
+// NewService creates a new backfill service. store may be nil, in which case
+// articles whose content lives in object storage get title embeddings only.
+func NewService(repo Repository, client embedding.EmbeddingClient, store storage.Storage, log *logger.Logger) Service {
+	return &service{
+		repo:   repo,
+		client: client,
+		store:  store,
+		logger: log.WithComponent("backfill-service"),
+	}
+}
+
+// normalize validates the options and fills in defaults
+func normalize(opts Options) (Options, error) {
+	if len(opts.Statuses) == 0 {
+		opts.Statuses = []string{StatusPending, StatusFailed}
+	}
+	for _, status := range opts.Statuses {
+		if status != StatusPending && status != StatusFailed {
+			return opts, fmt.Errorf("invalid embedding status '%s': must be pending or failed", status)
+		}
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.BatchSize < 0 || opts.BatchSize > MaxBatchSize {
+		return opts, fmt.Errorf("invalid batch size %d: must be between 1 and %d", opts.BatchSize, MaxBatchSize)
+	}
+	if opts.Interval < 0 {
+		return opts, fmt.Errorf("invalid interval %s: must not be negative", opts.Interval)
+	}
+	if opts.MaxArticles < 0 {
+		return opts, fmt.Errorf("invalid article limit %d: must not be negative", opts.MaxArticles)
+	}
+	return opts, nil
+}
+
+func (s *service) Run(ctx context.Context, opts Options, report func(*Progress)) (*Progress, error) {
+	opts, err := normalize(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.repo.CountPending(opts.Statuses)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxArticles > 0 && total > int64(opts.MaxArticles) {
+		total = int64(opts.MaxArticles)
+	}
+	progress := &Progress{Total: total}
+
+	s.logger.Info(fmt.Sprintf("Backfilling embeddings of %d articles with status %s", total, strings.Join(opts.Statuses, " or ")))
+	if opts.DryRun {
+		return progress, nil
+	}
+
+	after := uuid.Nil
+	var lastRequest time.Time
+	for {
+		if err := ctx.Err(); err != nil {
+			return progress, err
+		}
+
+		limit := opts.BatchSize
+		if opts.MaxArticles > 0 {
+			limit = min(limit, opts.MaxArticles-progress.Processed)
+		}
+		if limit <= 0 {
+			break
+		}
+
+		articles, err := s.repo.FindPending(opts.Statuses, after, limit)
+		if err != nil {
+			return progress, err
+		}
+		if len(articles) == 0 {
+			break
+		}
+		after = articles[len(articles)-1].ID
+
+		texts := s.texts(articles, opts.Statuses, progress)
+		progress.Processed += len(articles)
+		if len(texts) > 0 {
+			// Space requests out so the backfill does not starve live traffic
+			if wait := opts.Interval - time.Since(lastRequest); !lastRequest.IsZero() && wait > 0 {
+				if err := sleep(ctx, wait); err != nil {
+					return progress, err
+				}
+			}
+			lastRequest = time.Now()
+
+			if err := s.embed(ctx, texts, progress); err != nil {
+				return progress, err
+			}
+		}
+
+		progress.Batches++
+		s.logger.Info(fmt.Sprintf("Backfilled %d of %d articles: %d embedded, %d failed, %d skipped", progress.Processed, progress.Total, progress.Embedded, progress.Failed, progress.Skipped))
+		if report != nil {
+			report(progress)
+		}
+	}
+
+	return progress, nil
+}
+
+// texts returns the title and content texts of the articles that need
+// embeddings, counting articles without any as skipped
+func (s *service) texts(articles []*Article, statuses []string, progress *Progress) []embedding.ArticleText {
+	pending := func(status string) bool {
+		for _, candidate := range statuses {
+			if status == candidate {
+				return true
+			}
+		}
+		return false
+	}
+
+	var texts []embedding.ArticleText
+	for _, article := range articles {
+		before := len(texts)
+
+		if title := strings.TrimSpace(article.Title + " " + article.Description); title != "" && pending(article.EmbeddingStatus) {
+			texts = append(texts, embedding.ArticleText{ID: article.ID.String(), Text: title, Target: embedding.TargetTitle, Language: article.Language})
+		}
+		if pending(article.ContentEmbeddingStatus) {
+			if content := strings.TrimSpace(s.content(article)); content != "" {
+				texts = append(texts, embedding.ArticleText{ID: article.ID.String(), Text: content, Target: embedding.TargetContent, Language: article.Language})
+			}
+		}
+
+		if len(texts) == before {
+			progress.Skipped++
+		}
+	}
+	return texts
+}
+
+// content returns the article's content, reading it from object storage when kept there
+func (s *service) content(article *Article) string {
+	if article.ContentKey == "" || s.store == nil {
+		return article.Content
+	}
+	object, err := s.store.Get(article.ContentKey)
+	if err != nil {
+		s.logger.Error("Failed to read content of article " + article.ID.String() + ": " + err.Error())
+		return ""
+	}
+	return string(object.Data)
+}
+
+// embed sends one batch to the embedding service, which stores the
+// embeddings, and marks the texts it could not embed as failed
+func (s *service) embed(ctx context.Context, texts []embedding.ArticleText, progress *Progress) error {
+	response, err := s.client.StoreArticleEmbeddings(ctx, texts)
+	if err != nil {
+		s.logger.Error("Failed to backfill embeddings: " + err.Error())
+		return fmt.Errorf("failed to embed batch: %w", err)
+	}
+	progress.Embedded += response.SuccessfulUpdates
+
+	// Results come in request order
+	if len(response.Results) != len(texts) {
+		return nil
+	}
+	for i, result := range response.Results {
+		if result.Status == "success" {
+			continue
+		}
+		progress.Failed++
+		articleID, err := uuid.Parse(texts[i].ID)
+		if err != nil {
+			continue
+		}
+		s.logger.Info("Embedding " + texts[i].Target + " of article " + texts[i].ID + " failed: " + result.Message)
+		if err := s.repo.MarkFailed(articleID, texts[i].Target); err != nil {
+			s.logger.Error("Failed to mark embedding of article " + texts[i].ID + " failed: " + err.Error())
+		}
+	}
+	return nil
+}
+
+// sleep waits for d unless ctx is done first
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,201 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// Fault injection targets
+const (
+	TargetDatabase  = "database"
+	TargetEmbedding = "embedding"
+)
+
+// ErrInjectedFault is returned by calls failed on purpose
+var ErrInjectedFault = errors.New("chaos: injected fault")
+
+// Settings controls which calls are disturbed and how
+type Settings struct {
+	Enabled   bool     `json:"enabled"`
+	LatencyMs int      `json:"latency_ms"`
+	ErrorRate float64  `json:"error_rate"`
+	Targets   []string `json:"targets"`
+}
+
+// Injector adds artificial latency and errors to outgoing calls.
+// It refuses to enable itself in production environments.
+type Injector struct {
+	mu       sync.RWMutex
+	settings Settings
+	allowed  bool
+	logger   *logger.Logger
+}
+
+// NewInjector creates a fault injector with validation and defaults
+func NewInjector(cfg *config.ChaosConfig, environment string, log *logger.Logger) (*Injector, error) {
+	settings := Settings{
+		Targets: []string{TargetDatabase, TargetEmbedding},
+	}
+
+	if cfg != nil && cfg.Enabled != "" {
+		enabled, err := strconv.ParseBool(cfg.Enabled)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chaos enabled flag '%s': %v", cfg.Enabled, err)
+		}
+		settings.Enabled = enabled
+	}
+
+	if cfg != nil && cfg.Latency != "" {
+		latency, err := time.ParseDuration(cfg.Latency)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chaos latency '%s': %v", cfg.Latency, err)
+		}
+		settings.LatencyMs = int(latency.Milliseconds())
+	}
+
+	if cfg != nil && cfg.ErrorRate != "" {
+		rate, err := strconv.ParseFloat(cfg.ErrorRate, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chaos error rate '%s': %v", cfg.ErrorRate, err)
+		}
+		settings.ErrorRate = rate
+	}
+
+	if cfg != nil && cfg.Targets != "" {
+		settings.Targets = nil
+		for _, target := range strings.Split(cfg.Targets, ",") {
+			if target = strings.TrimSpace(target); target != "" {
+				settings.Targets = append(settings.Targets, target)
+			}
+		}
+	}
+
+	injector := &Injector{
+		allowed: environment != "production",
+		logger:  log.WithComponent("chaos"),
+	}
+
+	if err := injector.Update(settings); err != nil {
+		return nil, err
+	}
+
+	return injector, nil
+}
+
+// Allowed reports whether fault injection may be enabled in this environment
+func (i *Injector) Allowed() bool {
+	return i.allowed
+}
+
+// Settings returns the current fault injection settings
+func (i *Injector) Settings() Settings {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	settings := i.settings
+	settings.Targets = append([]string(nil), i.settings.Targets...)
+	return settings
+}
+
+// Update validates and applies new fault injection settings
+func (i *Injector) Update(settings Settings) error {
+	if settings.Enabled && !i.allowed {
+		return errors.New("fault injection cannot be enabled in production")
+	}
+	if settings.LatencyMs < 0 {
+		return fmt.Errorf("latency must not be negative, got %d", settings.LatencyMs)
+	}
+	if settings.ErrorRate < 0 || settings.ErrorRate > 1 {
+		return fmt.Errorf("error rate must be between 0 and 1, got %v", settings.ErrorRate)
+	}
+	for _, target := range settings.Targets {
+		if target != TargetDatabase && target != TargetEmbedding {
+			return fmt.Errorf("unknown chaos target '%s'", target)
+		}
+	}
+
+	i.mu.Lock()
+	i.settings = settings
+	i.mu.Unlock()
+
+	if settings.Enabled {
+		i.logger.Warn(fmt.Sprintf("Fault injection enabled: latency=%dms error_rate=%.2f targets=%s",
+			settings.LatencyMs, settings.ErrorRate, strings.Join(settings.Targets, ",")))
+	} else {
+		i.logger.Info("Fault injection disabled")
+	}
+
+	return nil
+}
+
+// Inject delays the caller, unless ctx is done first, and may return
+// ErrInjectedFault for the target
+func (i *Injector) Inject(ctx context.Context, target string) error {
+	settings := i.Settings()
+	if !settings.Enabled || !containsTarget(settings.Targets, target) {
+		return nil
+	}
+
+	if settings.LatencyMs > 0 {
+		timer := time.NewTimer(time.Duration(settings.LatencyMs) * time.Millisecond)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if settings.ErrorRate > 0 && rand.Float64() < settings.ErrorRate {
+		i.logger.Debug("Injecting fault on " + target)
+		return fmt.Errorf("%w on %s", ErrInjectedFault, target)
+	}
+
+	return nil
+}
+
+// RegisterGORMCallbacks injects faults before every database operation
+func (i *Injector) RegisterGORMCallbacks(db *gorm.DB) error {
+	inject := func(tx *gorm.DB) {
+		if err := i.Inject(tx.Statement.Context, TargetDatabase); err != nil {
+			tx.AddError(err)
+		}
+	}
+
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("chaos:create", inject); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("chaos:query", inject); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("chaos:update", inject); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("chaos:delete", inject); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("chaos:row", inject); err != nil {
+		return err
+	}
+	return callbacks.Raw().Before("gorm:raw").Register("chaos:raw", inject)
+}
+
+func containsTarget(targets []string, target string) bool {
+	for _, t := range targets {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
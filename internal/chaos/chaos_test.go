@@ -0,0 +1,101 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+	return log
+}
+
+func TestNewInjector(t *testing.T) {
+	log := newTestLogger(t)
+
+	t.Run("Defaults to disabled", func(t *testing.T) {
+		injector, err := NewInjector(&config.ChaosConfig{}, "development", log)
+		require.NoError(t, err)
+
+		settings := injector.Settings()
+		assert.False(t, settings.Enabled)
+		assert.Equal(t, []string{TargetDatabase, TargetEmbedding}, settings.Targets)
+		assert.True(t, injector.Allowed())
+	})
+
+	t.Run("Parses config", func(t *testing.T) {
+		injector, err := NewInjector(&config.ChaosConfig{
+			Enabled:   "true",
+			Latency:   "150ms",
+			ErrorRate: "0.25",
+			Targets:   "embedding",
+		}, "staging", log)
+		require.NoError(t, err)
+
+		settings := injector.Settings()
+		assert.True(t, settings.Enabled)
+		assert.Equal(t, 150, settings.LatencyMs)
+		assert.Equal(t, 0.25, settings.ErrorRate)
+		assert.Equal(t, []string{TargetEmbedding}, settings.Targets)
+	})
+
+	t.Run("Refuses production", func(t *testing.T) {
+		_, err := NewInjector(&config.ChaosConfig{Enabled: "true"}, "production", log)
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid values", func(t *testing.T) {
+		_, err := NewInjector(&config.ChaosConfig{ErrorRate: "2"}, "development", log)
+		assert.Error(t, err)
+
+		_, err = NewInjector(&config.ChaosConfig{Targets: "cache"}, "development", log)
+		assert.Error(t, err)
+
+		_, err = NewInjector(&config.ChaosConfig{Latency: "soon"}, "development", log)
+		assert.Error(t, err)
+	})
+}
+
+func TestInject(t *testing.T) {
+	log := newTestLogger(t)
+	injector, err := NewInjector(&config.ChaosConfig{}, "development", log)
+	require.NoError(t, err)
+
+	t.Run("Disabled injects nothing", func(t *testing.T) {
+		assert.NoError(t, injector.Inject(context.Background(), TargetDatabase))
+	})
+
+	t.Run("Always fails at error rate 1", func(t *testing.T) {
+		require.NoError(t, injector.Update(Settings{Enabled: true, ErrorRate: 1, Targets: []string{TargetDatabase}}))
+
+		err := injector.Inject(context.Background(), TargetDatabase)
+		assert.True(t, errors.Is(err, ErrInjectedFault))
+
+		// Targets outside the list are untouched
+		assert.NoError(t, injector.Inject(context.Background(), TargetEmbedding))
+	})
+
+	t.Run("Adds latency", func(t *testing.T) {
+		require.NoError(t, injector.Update(Settings{Enabled: true, LatencyMs: 20, Targets: []string{TargetEmbedding}}))
+
+		start := time.Now()
+		assert.NoError(t, injector.Inject(context.Background(), TargetEmbedding))
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	})
+
+	t.Run("Latency ends when the context is done", func(t *testing.T) {
+		require.NoError(t, injector.Update(Settings{Enabled: true, LatencyMs: 60000, Targets: []string{TargetEmbedding}}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, injector.Inject(ctx, TargetEmbedding), context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,70 @@
+package chaos
+
+import (
+	"context"
+
+	"github.com/dustin/articles-backend/internal/embedding"
+)
+
+// embeddingClient decorates an embedding client with fault injection
+type embeddingClient struct {
+	inner    embedding.EmbeddingClient
+	injector *Injector
+}
+
+// NewEmbeddingClient wraps an embedding client so its calls can be disturbed
+func NewEmbeddingClient(inner embedding.EmbeddingClient, injector *Injector) embedding.EmbeddingClient {
+	return &embeddingClient{
+		inner:    inner,
+		injector: injector,
+	}
+}
+
+func (c *embeddingClient) GetEmbedding(ctx context.Context, text string) ([]float64, error) {
+	if err := c.injector.Inject(ctx, TargetEmbedding); err != nil {
+		return nil, err
+	}
+	return c.inner.GetEmbedding(ctx, text)
+}
+
+func (c *embeddingClient) GetBatchEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	if err := c.injector.Inject(ctx, TargetEmbedding); err != nil {
+		return nil, err
+	}
+	return c.inner.GetBatchEmbeddings(ctx, texts)
+}
+
+func (c *embeddingClient) CalculateSimilarity(ctx context.Context, embedding1, embedding2 []float64) (float64, error) {
+	if err := c.injector.Inject(ctx, TargetEmbedding); err != nil {
+		return 0, err
+	}
+	return c.inner.CalculateSimilarity(ctx, embedding1, embedding2)
+}
+
+func (c *embeddingClient) HealthCheck(ctx context.Context) (*embedding.HealthResponse, error) {
+	if err := c.injector.Inject(ctx, TargetEmbedding); err != nil {
+		return nil, err
+	}
+	return c.inner.HealthCheck(ctx)
+}
+
+func (c *embeddingClient) ClassifyContent(ctx context.Context, text string) (*embedding.ClassifyResponse, error) {
+	if err := c.injector.Inject(ctx, TargetEmbedding); err != nil {
+		return nil, err
+	}
+	return c.inner.ClassifyContent(ctx, text)
+}
+
+func (c *embeddingClient) ClassifyBatchContent(ctx context.Context, texts []string) (*embedding.BatchClassifyResponse, error) {
+	if err := c.injector.Inject(ctx, TargetEmbedding); err != nil {
+		return nil, err
+	}
+	return c.inner.ClassifyBatchContent(ctx, texts)
+}
+
+func (c *embeddingClient) StoreArticleEmbeddings(ctx context.Context, articles []embedding.ArticleText) (*embedding.BatchStoreResponse, error) {
+	if err := c.injector.Inject(ctx, TargetEmbedding); err != nil {
+		return nil, err
+	}
+	return c.inner.StoreArticleEmbeddings(ctx, articles)
+}
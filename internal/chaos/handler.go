@@ -0,0 +1,54 @@
+package chaos
+
+import (
+	"net/http"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler exposes runtime control of fault injection to admins
+type Handler struct {
+	injector *Injector
+}
+
+// NewHandler creates a new chaos handler
+func NewHandler(injector *Injector) *Handler {
+	return &Handler{
+		injector: injector,
+	}
+}
+
+// GetSettings returns the current fault injection settings
+func (h *Handler) GetSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"settings": h.injector.Settings(),
+		"allowed":  h.injector.Allowed(),
+	})
+}
+
+// UpdateSettings replaces the fault injection settings
+func (h *Handler) UpdateSettings(c *gin.Context) {
+	var settings Settings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.injector.Update(settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": h.injector.Settings()})
+}
+
+// RegisterRoutes registers admin-only chaos routes, rate limited per admin
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, rateLimit gin.HandlerFunc) {
+	admin := router.Group("/admin/chaos")
+	admin.Use(authMiddleware, utils.RequireRole(utils.RoleAdmin), utils.RequireScope(utils.ScopeAdmin), rateLimit)
+	{
+		admin.GET("", h.GetSettings)
+		admin.PUT("", h.UpdateSettings)
+	}
+}
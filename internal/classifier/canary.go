@@ -0,0 +1,155 @@
+package classifier
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/embedding"
+	"github.com/dustin/articles-backend/pkg/logger"
+)
+
+// CohortStats counts the pages one cohort of a canary rollout classified
+type CohortStats struct {
+	Classifier  string  `json:"classifier"`
+	Classified  int64   `json:"classified"`
+	Articles    int64   `json:"articles"` // Classified as articles
+	Errors      int64   `json:"errors"`
+	SuccessRate float64 `json:"success_rate"` // Share of pages classified as articles
+}
+
+// CanaryStats compares the canary cohort with the rest of the pages
+type CanaryStats struct {
+	Percent int         `json:"percent"`
+	Control CohortStats `json:"control"`
+	Canary  CohortStats `json:"canary"`
+}
+
+// cohort counts classifications of one side of the rollout
+type cohort struct {
+	classifier Classifier
+	classified atomic.Int64
+	articles   atomic.Int64
+	errors     atomic.Int64
+}
+
+func (c *cohort) classify(ctx context.Context, url, html string) (*Result, error) {
+	result, err := c.classifier.Classify(ctx, url, html)
+	if err != nil {
+		c.errors.Add(1)
+		return nil, err
+	}
+	c.classified.Add(1)
+	if result.IsArticle {
+		c.articles.Add(1)
+	}
+	return result, nil
+}
+
+func (c *cohort) stats() CohortStats {
+	stats := CohortStats{
+		Classifier: c.classifier.Name(),
+		Classified: c.classified.Load(),
+		Articles:   c.articles.Load(),
+		Errors:     c.errors.Load(),
+	}
+	if attempts := stats.Classified + stats.Errors; attempts > 0 {
+		stats.SuccessRate = float64(stats.Articles) / float64(attempts)
+	}
+	return stats
+}
+
+// CanaryClassifier sends a percentage of pages to a differently configured
+// classifier and stores its results, so a new threshold can be rolled out
+// gradually. Unlike shadow mode, each page is classified once. Pages are
+// assigned by a hash of their URL, so retries and refreshes of a page stay in
+// the same cohort.
+type CanaryClassifier struct {
+	control *cohort
+	canary  *cohort
+	percent int
+	logger  *logger.Logger
+}
+
+// NewCanaryClassifier wraps primary with the classifier named by cfg.Canary,
+// which receives CLASSIFIER_CANARY_PERCENT of the pages.
+// CLASSIFIER_CANARY_MIN_CONFIDENCE overrides the threshold for the canary only.
+func NewCanaryClassifier(cfg *config.ClassifierConfig, primary Classifier, embeddingClient embedding.EmbeddingClient, log *logger.Logger) (*CanaryClassifier, error) {
+	percent, err := strconv.Atoi(cfg.CanaryPercent)
+	if err != nil || percent < 0 || percent > 100 {
+		return nil, fmt.Errorf("invalid canary percent '%s': must be an integer between 0 and 100", cfg.CanaryPercent)
+	}
+
+	canaryCfg := *cfg
+	if cfg.CanaryMinConfidenceScore != "" {
+		canaryCfg.MinConfidenceScore = cfg.CanaryMinConfidenceScore
+	}
+
+	canary, err := New(cfg.Canary, &canaryCfg, embeddingClient, log)
+	if err != nil {
+		return nil, fmt.Errorf("invalid canary classifier: %w", err)
+	}
+
+	return newCanaryClassifier(primary, canary, percent, log), nil
+}
+
+func newCanaryClassifier(primary, canary Classifier, percent int, log *logger.Logger) *CanaryClassifier {
+	return &CanaryClassifier{
+		control: &cohort{classifier: primary},
+		canary:  &cohort{classifier: canary},
+		percent: percent,
+		logger:  log.WithComponent("canary-classifier"),
+	}
+}
+
+// Name reports the primary classifier; results of the canary carry its name
+// in ClassifierUsed
+func (c *CanaryClassifier) Name() string {
+	return c.control.classifier.Name()
+}
+
+func (c *CanaryClassifier) IsHealthy() bool {
+	return c.control.classifier.IsHealthy() && c.canary.classifier.IsHealthy()
+}
+
+// Classify classifies the page with the classifier of its cohort
+func (c *CanaryClassifier) Classify(ctx context.Context, url string, html string) (*Result, error) {
+	if inCanary(url, c.percent) {
+		c.logger.WithContext(ctx).Debug("Classifying " + url + " with canary " + c.canary.classifier.Name())
+		return c.canary.classify(ctx, url, html)
+	}
+	return c.control.classify(ctx, url, html)
+}
+
+// FetchHTML downloads the page with the classifier of its cohort, so a shadow
+// classifier wrapping the canary sees the same markup
+func (c *CanaryClassifier) FetchHTML(url string) (string, error) {
+	target := c.control.classifier
+	if inCanary(url, c.percent) {
+		target = c.canary.classifier
+	}
+	fetcher, ok := target.(htmlFetcher)
+	if !ok {
+		return "", fmt.Errorf("classifier %s cannot fetch pages", target.Name())
+	}
+	return fetcher.FetchHTML(url)
+}
+
+// Stats returns the cohort counters since startup
+func (c *CanaryClassifier) Stats() CanaryStats {
+	return CanaryStats{
+		Percent: c.percent,
+		Control: c.control.stats(),
+		Canary:  c.canary.stats(),
+	}
+}
+
+// inCanary reports whether the page falls in the first percent of 100 buckets
+func inCanary(url string, percent int) bool {
+	hash := fnv.New32a()
+	hash.Write([]byte(url))
+	return int(hash.Sum32()%100) < percent
+}
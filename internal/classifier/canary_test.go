@@ -0,0 +1,89 @@
+package classifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanaryClassifier_SplitsPagesByURL(t *testing.T) {
+	primary := &fakeClassifier{name: "readability", result: &Result{IsArticle: true, ClassifierUsed: "readability"}}
+	canary := &fakeClassifier{name: "candidate", result: &Result{IsArticle: false, ClassifierUsed: "candidate"}}
+	c := newCanaryClassifier(primary, canary, 20, testLogger())
+
+	assigned := make(map[string]string)
+	for i := 0; i < 1000; i++ {
+		url := fmt.Sprintf("https://example.com/post/%d", i)
+		result, err := c.Classify(context.Background(), url, "<html></html>")
+		require.NoError(t, err)
+		assigned[url] = result.ClassifierUsed
+	}
+
+	stats := c.Stats()
+	assert.Equal(t, 20, stats.Percent)
+	assert.InDelta(t, 200, stats.Canary.Classified, 50, "about 20% of pages")
+	assert.Equal(t, int64(1000), stats.Control.Classified+stats.Canary.Classified)
+	assert.Equal(t, 1.0, stats.Control.SuccessRate)
+	assert.Equal(t, 0.0, stats.Canary.SuccessRate)
+	assert.Equal(t, "candidate", stats.Canary.Classifier)
+
+	// A page stays in its cohort when classified again
+	for url, used := range assigned {
+		result, err := c.Classify(context.Background(), url, "<html></html>")
+		require.NoError(t, err)
+		assert.Equal(t, used, result.ClassifierUsed, url)
+	}
+}
+
+func TestCanaryClassifier_Bounds(t *testing.T) {
+	primary := &fakeClassifier{name: "readability", result: &Result{IsArticle: true}}
+	canary := &fakeClassifier{name: "candidate", result: &Result{IsArticle: true}}
+
+	none := newCanaryClassifier(primary, canary, 0, testLogger())
+	all := newCanaryClassifier(primary, canary, 100, testLogger())
+	for i := 0; i < 100; i++ {
+		url := fmt.Sprintf("https://example.com/%d", i)
+		_, err := none.Classify(context.Background(), url, "")
+		require.NoError(t, err)
+		_, err = all.Classify(context.Background(), url, "")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int64(0), none.Stats().Canary.Classified)
+	assert.Equal(t, int64(0), all.Stats().Control.Classified)
+}
+
+func TestCanaryClassifier_CountsErrors(t *testing.T) {
+	primary := &fakeClassifier{name: "readability", result: &Result{IsArticle: true}}
+	canary := &fakeClassifier{name: "candidate", err: errors.New("fetch failed")}
+	c := newCanaryClassifier(primary, canary, 100, testLogger())
+
+	_, err := c.Classify(context.Background(), "https://example.com", "")
+	assert.Error(t, err)
+
+	stats := c.Stats().Canary
+	assert.Equal(t, int64(1), stats.Errors)
+	assert.Equal(t, int64(0), stats.Classified)
+	assert.Equal(t, 0.0, stats.SuccessRate)
+}
+
+func TestNewCanaryClassifier_InvalidConfig(t *testing.T) {
+	primary := &fakeClassifier{name: "readability"}
+
+	for _, percent := range []string{"", "half", "-1", "101"} {
+		_, err := NewCanaryClassifier(&config.ClassifierConfig{Canary: "readability", CanaryPercent: percent}, primary, nil, testLogger())
+		assert.Error(t, err, percent)
+	}
+
+	_, err := NewCanaryClassifier(&config.ClassifierConfig{Canary: "unknown", CanaryPercent: "10"}, primary, nil, testLogger())
+	assert.Error(t, err)
+
+	c, err := NewCanaryClassifier(&config.ClassifierConfig{Canary: "readability", CanaryPercent: "10", CanaryMinConfidenceScore: "0.8"}, primary, nil, testLogger())
+	require.NoError(t, err)
+	assert.Equal(t, 0.8, c.canary.classifier.(*ReadabilityClassifier).minConfidenceScore)
+}
@@ -1,7 +1,10 @@
 package classifier
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -10,13 +13,16 @@ import (
 
 	"github.com/dustin/articles-backend/config"
 	"github.com/dustin/articles-backend/internal/embedding"
+	"github.com/dustin/articles-backend/internal/utils"
 	"github.com/dustin/articles-backend/pkg/logger"
 	"github.com/go-shiori/go-readability"
 )
 
 // Classifier defines content classification capabilities
 type Classifier interface {
-	Classify(url string, html string) (*Result, error)
+	// Classify extracts and classifies the page; ctx carries the request ID
+	// forwarded to the embedding service
+	Classify(ctx context.Context, url string, html string) (*Result, error)
 	Name() string
 	IsHealthy() bool
 }
@@ -28,25 +34,42 @@ type Result struct {
 	Title          string    `json:"title"`
 	Description    string    `json:"description"`
 	Image          string    `json:"image"`
+	SiteName       string    `json:"site_name"`   // From og:site_name or similar, empty if undeclared
+	FaviconURL     string    `json:"favicon_url"` // Absolute URL, empty if the page declares no icon
 	Content        string    `json:"content"`
+	HTML           string    `json:"html,omitempty"` // Readability-cleaned markup of the main content
 	WordCount      int       `json:"word_count"`
+	ReadingTime    int       `json:"reading_time_minutes"`
+	Language       string    `json:"language"` // ISO 639-1 code, empty if unknown
 	ClassifierUsed string    `json:"classifier_used"`
 	ProcessedAt    time.Time `json:"processed_at"`
 }
 
+// New creates the classifier implementation registered under name
+func New(name string, cfg *config.ClassifierConfig, embeddingClient embedding.EmbeddingClient, log *logger.Logger) (Classifier, error) {
+	switch name {
+	case "", "readability":
+		return NewReadabilityClassifier(cfg, embeddingClient, log)
+	default:
+		return nil, fmt.Errorf("unknown classifier '%s': must be one of readability", name)
+	}
+}
+
 // ReadabilityClassifier implements article extraction using go-readability + ML classification
 type ReadabilityClassifier struct {
 	minConfidenceScore float64
 	httpTimeout        time.Duration
 	userAgent          string
+	readingWPM         int
+	readingCPM         int
 	logger             *logger.Logger
 	client             *http.Client
-	embeddingClient    *embedding.Client
+	embeddingClient    embedding.EmbeddingClient
 	isHealthy          bool
 }
 
 // NewReadabilityClassifier creates a content classifier with validation and defaults
-func NewReadabilityClassifier(cfg *config.ClassifierConfig, embeddingClient *embedding.Client, log *logger.Logger) (*ReadabilityClassifier, error) {
+func NewReadabilityClassifier(cfg *config.ClassifierConfig, embeddingClient embedding.EmbeddingClient, log *logger.Logger) (*ReadabilityClassifier, error) {
 	// Set defaults for nil or empty config values
 	var minConfidence float64 = 0.6
 	if cfg != nil && cfg.MinConfidenceScore != "" {
@@ -71,13 +94,41 @@ func NewReadabilityClassifier(cfg *config.ClassifierConfig, embeddingClient *emb
 		userAgent = cfg.UserAgent
 	}
 
+	readingWPM := 230 // Average adult silent reading speed
+	if cfg != nil && cfg.ReadingWPM != "" {
+		wpm, err := strconv.Atoi(cfg.ReadingWPM)
+		if err != nil || wpm <= 0 {
+			return nil, fmt.Errorf("invalid reading WPM '%s': must be a positive integer", cfg.ReadingWPM)
+		}
+		readingWPM = wpm
+	}
+
+	readingCPM := 300 // Silent reading speed of Chinese and Japanese text
+	if cfg != nil && cfg.ReadingCPM != "" {
+		cpm, err := strconv.Atoi(cfg.ReadingCPM)
+		if err != nil || cpm <= 0 {
+			return nil, fmt.Errorf("invalid reading CPM '%s': must be a positive integer", cfg.ReadingCPM)
+		}
+		readingCPM = cpm
+	}
+
+	// Saved pages are fetched directly and never from private addresses,
+	// whether requested, redirected to or resolved from a name
+	dialer := &net.Dialer{Timeout: httpTimeout, Control: utils.DenyPrivateAddresses}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
 	return &ReadabilityClassifier{
 		minConfidenceScore: minConfidence,
 		httpTimeout:        httpTimeout,
 		userAgent:          userAgent,
+		readingWPM:         readingWPM,
+		readingCPM:         readingCPM,
 		logger:             log.WithComponent("readability-classifier"),
 		client: &http.Client{
-			Timeout: httpTimeout,
+			Timeout:   httpTimeout,
+			Transport: transport,
 		},
 		embeddingClient: embeddingClient,
 		isHealthy:       true,
@@ -92,13 +143,14 @@ func (r *ReadabilityClassifier) IsHealthy() bool {
 	return r.isHealthy
 }
 
-func (r *ReadabilityClassifier) Classify(urlStr string, html string) (*Result, error) {
-	r.logger.Info("Starting content classification for URL: " + urlStr)
+func (r *ReadabilityClassifier) Classify(ctx context.Context, urlStr string, html string) (*Result, error) {
+	log := r.logger.WithContext(ctx)
+	log.Info("Starting content classification for URL: " + urlStr)
 
 	// Validate URL
 	parsedURL, err := url.Parse(urlStr)
 	if err != nil {
-		r.logger.Error("Invalid URL: " + urlStr + ", error: " + err.Error())
+		log.Error("Invalid URL: " + urlStr + ", error: " + err.Error())
 		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
@@ -106,7 +158,7 @@ func (r *ReadabilityClassifier) Classify(urlStr string, html string) (*Result, e
 	if html == "" {
 		html, err = r.fetchHTML(urlStr)
 		if err != nil {
-			r.logger.Error("Failed to fetch HTML for " + urlStr + ": " + err.Error())
+			log.Error("Failed to fetch HTML for " + urlStr + ": " + err.Error())
 			return nil, fmt.Errorf("failed to fetch HTML: %w", err)
 		}
 	}
@@ -114,25 +166,37 @@ func (r *ReadabilityClassifier) Classify(urlStr string, html string) (*Result, e
 	// Use readability to parse content
 	article, err := readability.FromReader(strings.NewReader(html), parsedURL)
 	if err != nil {
-		r.logger.Error("Readability parsing failed for " + urlStr + ": " + err.Error())
+		log.Error("Readability parsing failed for " + urlStr + ": " + err.Error())
 		return nil, fmt.Errorf("readability parsing failed: %w", err)
 	}
 
-	// Calculate basic metrics
-	wordCount := len(strings.Fields(article.TextContent))
+	// Calculate basic metrics, counting CJK text by character
+	metrics := MeasureText(article.TextContent)
 
 	// Use ML-based classification for article worthiness
-	confidence, isArticle := r.classifyWithML(article, urlStr)
+	confidence, isArticle := r.classifyWithML(ctx, log, article, urlStr)
 
 	// Clean and validate content
 	title := r.cleanText(article.Title)
 	description := r.cleanText(article.Excerpt)
 	content := r.cleanText(article.TextContent)
 	imageURL := r.validateImageURL(article.Image, parsedURL)
+	siteName := r.cleanText(article.SiteName)
+	favicon := article.Favicon
+	if favicon == "" {
+		favicon = findIconLink(html)
+	}
+	faviconURL := r.validateImageURL(favicon, parsedURL)
+
+	// Detect the language from the text, trusting the page's declaration only when the text is inconclusive
+	language := DetectLanguage(content)
+	if language == "" {
+		language = normalizeLanguageTag(article.Language)
+	}
 
 	// Return error if ML classification failed
 	if confidence < 0 {
-		r.logger.Error("ML classification failed for " + urlStr)
+		log.Error("ML classification failed for " + urlStr)
 		return nil, fmt.Errorf("ML classification failed")
 	}
 
@@ -142,17 +206,36 @@ func (r *ReadabilityClassifier) Classify(urlStr string, html string) (*Result, e
 		Title:          title,
 		Description:    description,
 		Image:          imageURL,
+		SiteName:       siteName,
+		FaviconURL:     faviconURL,
 		Content:        content,
-		WordCount:      wordCount,
+		HTML:           article.Content,
+		WordCount:      metrics.WordCount(),
+		ReadingTime:    metrics.ReadingTimeMinutes(r.readingWPM, r.readingCPM),
+		Language:       language,
 		ClassifierUsed: r.Name(),
 		ProcessedAt:    time.Now(),
 	}
 
-	r.logger.Info("Content classification completed for " + urlStr)
+	log.Info("Content classification completed for " + urlStr)
 
 	return result, nil
 }
 
+// ReadingTimeMinutes estimates how long the text takes to read, rounded up to
+// whole minutes. Returns 0 when there is no text.
+func ReadingTimeMinutes(wordCount, wordsPerMinute int) int {
+	if wordCount <= 0 || wordsPerMinute <= 0 {
+		return 0
+	}
+	return (wordCount + wordsPerMinute - 1) / wordsPerMinute
+}
+
+// FetchHTML downloads the page so it can be classified more than once
+func (r *ReadabilityClassifier) FetchHTML(urlStr string) (string, error) {
+	return r.fetchHTML(urlStr)
+}
+
 func (r *ReadabilityClassifier) fetchHTML(urlStr string) (string, error) {
 	req, err := http.NewRequest("GET", urlStr, nil)
 	if err != nil {
@@ -165,7 +248,10 @@ func (r *ReadabilityClassifier) fetchHTML(urlStr string) (string, error) {
 
 	resp, err := r.client.Do(req)
 	if err != nil {
-		r.isHealthy = false
+		// A refused address says nothing about the classifier's connectivity
+		if !errors.Is(err, utils.ErrPrivateDestination) {
+			r.isHealthy = false
+		}
 		return "", err
 	}
 	defer resp.Body.Close()
@@ -201,7 +287,7 @@ func (r *ReadabilityClassifier) fetchHTML(urlStr string) (string, error) {
 }
 
 // classifyWithML uses machine learning model for article classification
-func (r *ReadabilityClassifier) classifyWithML(article readability.Article, urlStr string) (confidence float64, isArticle bool) {
+func (r *ReadabilityClassifier) classifyWithML(ctx context.Context, log *logger.Logger, article readability.Article, urlStr string) (confidence float64, isArticle bool) {
 	// Prepare text for classification (combine title, excerpt, and content)
 	classificationText := strings.TrimSpace(article.Title)
 	if article.Excerpt != "" {
@@ -213,18 +299,18 @@ func (r *ReadabilityClassifier) classifyWithML(article readability.Article, urlS
 
 	// Error if no content to classify
 	if classificationText == "" {
-		r.logger.Error("No content to classify for URL: " + urlStr)
+		log.Error("No content to classify for URL: " + urlStr)
 		return 0, false
 	}
 
 	// Call ML classification service
-	result, err := r.embeddingClient.ClassifyContent(classificationText)
+	result, err := r.embeddingClient.ClassifyContent(ctx, classificationText)
 	if err != nil {
-		r.logger.Error("ML classification failed for " + urlStr + ": " + err.Error())
+		log.Error("ML classification failed for " + urlStr + ": " + err.Error())
 		return 0, false // Return error via negative confidence
 	}
 
-	r.logger.Info("ML classification result for " + urlStr + ": confidence=" + fmt.Sprintf("%.2f", result.Confidence) + ", is_article=" + fmt.Sprintf("%t", result.IsArticle))
+	log.Info("ML classification result for " + urlStr + ": confidence=" + fmt.Sprintf("%.2f", result.Confidence) + ", is_article=" + fmt.Sprintf("%t", result.IsArticle))
 
 	// Apply minimum confidence threshold
 	isArticleResult := result.IsArticle && result.Confidence >= r.minConfidenceScore
@@ -1,6 +1,8 @@
 package classifier
 
 import (
+	"context"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/dustin/articles-backend/config"
 	"github.com/dustin/articles-backend/internal/embedding"
+	"github.com/dustin/articles-backend/internal/utils"
 	"github.com/dustin/articles-backend/pkg/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,7 +31,18 @@ func createTestClassifier() (*ReadabilityClassifier, error) {
 	}
 	log, _ := logger.NewLogger(logCfg)
 
-	return NewReadabilityClassifier(cfg, embeddingClient, log)
+	classifier, err := NewReadabilityClassifier(cfg, embeddingClient, log)
+	if err != nil {
+		return nil, err
+	}
+	allowLoopback(classifier)
+	return classifier, nil
+}
+
+// allowLoopback lets the classifier reach test servers, which listen on the
+// loopback addresses it otherwise refuses
+func allowLoopback(classifier *ReadabilityClassifier) {
+	classifier.client.Transport = http.DefaultTransport
 }
 
 func TestNewReadabilityClassifier(t *testing.T) {
@@ -40,6 +54,93 @@ func TestNewReadabilityClassifier(t *testing.T) {
 	assert.True(t, classifier.IsHealthy())
 }
 
+func TestNewReadabilityClassifier_InvalidReadingWPM(t *testing.T) {
+	log, _ := logger.NewLogger(&config.LoggingConfig{Level: "error"})
+
+	for _, wpm := range []string{"fast", "0", "-200"} {
+		_, err := NewReadabilityClassifier(&config.ClassifierConfig{ReadingWPM: wpm}, nil, log)
+		assert.Error(t, err, wpm)
+	}
+}
+
+func TestReadingTimeMinutes(t *testing.T) {
+	assert.Equal(t, 0, ReadingTimeMinutes(0, 230))
+	assert.Equal(t, 1, ReadingTimeMinutes(1, 230))
+	assert.Equal(t, 1, ReadingTimeMinutes(230, 230))
+	assert.Equal(t, 2, ReadingTimeMinutes(231, 230))
+	assert.Equal(t, 10, ReadingTimeMinutes(2000, 200))
+}
+
+func TestMeasureText(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected TextMetrics
+	}{
+		{"Empty", "", TextMetrics{}},
+		{"English", "The quick brown fox — it jumps!", TextMetrics{Words: 6}},
+		{"Chinese", "机器学习是人工智能的一个分支。", TextMetrics{Characters: 14}},
+		{"Japanese", "東京は日本の首都です", TextMetrics{Characters: 10}},
+		{"Mixed", "使用 Go 语言编写 web 服务", TextMetrics{Words: 2, Characters: 8}},
+		{"Korean uses spaces", "한국어 문장은 띄어쓰기를 합니다", TextMetrics{Words: 4}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, MeasureText(tc.text))
+		})
+	}
+
+	t.Run("Word count counts characters as words", func(t *testing.T) {
+		assert.Equal(t, 10, TextMetrics{Words: 2, Characters: 8}.WordCount())
+	})
+
+	t.Run("Reading time combines both speeds", func(t *testing.T) {
+		assert.Equal(t, 0, TextMetrics{}.ReadingTimeMinutes(230, 300))
+		assert.Equal(t, 1, TextMetrics{Characters: 300}.ReadingTimeMinutes(230, 300))
+		assert.Equal(t, 2, TextMetrics{Characters: 301}.ReadingTimeMinutes(230, 300))
+		assert.Equal(t, 2, TextMetrics{Words: 230, Characters: 300}.ReadingTimeMinutes(230, 300))
+		assert.Equal(t, 10, TextMetrics{Characters: 3000}.ReadingTimeMinutes(230, 300), "not 14 minutes as 3000 words")
+	})
+}
+
+func TestNewReadabilityClassifier_InvalidReadingCPM(t *testing.T) {
+	log, _ := logger.NewLogger(&config.LoggingConfig{Level: "error"})
+
+	for _, cpm := range []string{"fast", "0", "-300"} {
+		_, err := NewReadabilityClassifier(&config.ClassifierConfig{ReadingCPM: cpm}, nil, log)
+		assert.Error(t, err, cpm)
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	samples := map[string]string{
+		"en": "The quick brown fox jumps over the lazy dog and this is a sentence that is written in English for the test.",
+		"de": "Das ist ein kurzer Text, der nicht sehr lang ist, aber mit den Wörtern auf Deutsch und von hier zu dort.",
+		"fr": "Le chat est sur la table et les enfants sont dans le jardin pour une journée qui est très belle.",
+		"es": "El perro de la casa es muy grande y los niños juegan con el por la tarde para que no se aburra.",
+		"pt": "O gato da casa não é muito grande e os meninos brincam com ele em uma tarde para que não fique triste.",
+		"zh": "機器學習是人工智慧的一個分支，它讓電腦能夠從資料中學習並改進，而不需要明確的程式設計。",
+		"ja": "機械学習は人工知能の一分野であり、コンピュータがデータから学習することを可能にします。",
+		"ko": "기계 학습은 컴퓨터가 명시적으로 프로그래밍하지 않고도 데이터에서 학습할 수 있게 하는 인공 지능의 한 분야입니다.",
+		"ru": "Машинное обучение — это раздел искусственного интеллекта, который позволяет компьютерам учиться на данных.",
+		"uk": "Машинне навчання є галуззю штучного інтелекту, яка дозволяє комп'ютерам навчатися на даних.",
+	}
+	for expected, text := range samples {
+		assert.Equal(t, expected, DetectLanguage(text), text)
+	}
+
+	assert.Equal(t, "", DetectLanguage("Too short"))
+	assert.Equal(t, "", DetectLanguage("Kubernetes Docker Terraform Ansible Prometheus Grafana"), "no function words")
+}
+
+func TestNormalizeLanguageTag(t *testing.T) {
+	assert.Equal(t, "en", normalizeLanguageTag("en-US"))
+	assert.Equal(t, "pt", normalizeLanguageTag(" PT_br "))
+	assert.Equal(t, "", normalizeLanguageTag("english"))
+	assert.Equal(t, "", normalizeLanguageTag(""))
+}
+
 func TestReadabilityClassifier_FetchHTML_Success(t *testing.T) {
 	// Create test server with known content
 	testHTML := `<html><head><title>Test Article</title></head><body><h1>Test Title</h1><p>Test content here.</p></body></html>`
@@ -129,13 +230,15 @@ func TestReadabilityClassifier_Classify_Success(t *testing.T) {
 	classifier, err := createTestClassifier()
 	require.NoError(t, err)
 
-	result, err := classifier.Classify(server.URL, "")
+	result, err := classifier.Classify(context.Background(), server.URL, "")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	// Note: Actual classification may depend on embedding service availability
 	assert.Equal(t, "Test Article", result.Title)
 	assert.Greater(t, result.WordCount, 0)
+	assert.Equal(t, 1, result.ReadingTime)
+	assert.Equal(t, "en", result.Language)
 	assert.Equal(t, "readability", result.ClassifierUsed)
 }
 
@@ -145,7 +248,7 @@ func TestReadabilityClassifier_Classify_WithProvidedHTML(t *testing.T) {
 	classifier, err := createTestClassifier()
 	require.NoError(t, err)
 
-	result, err := classifier.Classify("https://example.com", testHTML)
+	result, err := classifier.Classify(context.Background(), "https://example.com", testHTML)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -153,13 +256,32 @@ func TestReadabilityClassifier_Classify_WithProvidedHTML(t *testing.T) {
 	assert.Equal(t, "readability", result.ClassifierUsed)
 }
 
+func TestReadabilityClassifier_Classify_SiteNameAndFavicon(t *testing.T) {
+	testHTML := `<html><head><title>Test Article</title><meta property="og:site_name" content="Example News"><link rel="shortcut icon" href="/static/favicon.ico"></head><body><h1>Test Title</h1><p>Test content provided directly.</p></body></html>`
+
+	classifier, err := createTestClassifier()
+	require.NoError(t, err)
+
+	result, err := classifier.Classify(context.Background(), "https://example.com/posts/1", testHTML)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Example News", result.SiteName)
+	assert.Equal(t, "https://example.com/static/favicon.ico", result.FaviconURL)
+}
+
+func TestFindIconLink(t *testing.T) {
+	assert.Equal(t, "/favicon.svg", findIconLink(`<head><link rel="apple-touch-icon" href="/touch.png"><link rel="icon" type="image/svg+xml" href="/favicon.svg"></head>`))
+	assert.Equal(t, "/touch.png", findIconLink(`<head><link rel="Apple-Touch-Icon" href="/touch.png"></head>`))
+	assert.Equal(t, "", findIconLink(`<head><link rel="stylesheet" href="/site.css"></head><body><link rel="icon" href="/late.ico"></body>`))
+}
+
 func TestReadabilityClassifier_Classify_EmbeddingServiceError(t *testing.T) {
 	testHTML := `<html><head><title>Test</title></head><body><h1>Test</h1><p>Content</p></body></html>`
 
 	classifier, err := createTestClassifier()
 	require.NoError(t, err)
 
-	result, err := classifier.Classify("https://example.com", testHTML)
+	result, err := classifier.Classify(context.Background(), "https://example.com", testHTML)
 
 	assert.NoError(t, err) // Should not error, just fall back to readability-only
 	assert.NotNil(t, result)
@@ -170,7 +292,7 @@ func TestReadabilityClassifier_Classify_InvalidURL(t *testing.T) {
 	classifier, err := createTestClassifier()
 	require.NoError(t, err)
 
-	result, err := classifier.Classify("not-a-valid-url", "")
+	result, err := classifier.Classify(context.Background(), "not-a-valid-url", "")
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -192,14 +314,37 @@ func TestReadabilityClassifier_Classify_NetworkTimeout(t *testing.T) {
 	log, _ := logger.NewLogger(logCfg)
 	classifier, err := NewReadabilityClassifier(cfg, embeddingClient, log)
 	require.NoError(t, err)
+	allowLoopback(classifier)
 
-	result, err := classifier.Classify(server.URL, "")
+	result, err := classifier.Classify(context.Background(), server.URL, "")
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "deadline exceeded")
 }
 
+func TestReadabilityClassifier_RefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body><p>Internal page</p></body></html>"))
+	}))
+	defer server.Close()
+
+	log, _ := logger.NewLogger(&config.LoggingConfig{Level: "error"})
+	classifier, err := NewReadabilityClassifier(&config.ClassifierConfig{HTTPTimeout: "1s"}, nil, log)
+	require.NoError(t, err)
+
+	// localhost is a name, so only the address it resolves to gives it away
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	for _, url := range []string{server.URL, "http://localhost:" + port + "/"} {
+		result, err := classifier.Classify(context.Background(), url, "")
+		assert.ErrorIs(t, err, utils.ErrPrivateDestination, url)
+		assert.Nil(t, result)
+	}
+
+	assert.True(t, classifier.IsHealthy(), "refused addresses do not mark the classifier unhealthy")
+}
+
 func TestReadabilityClassifier_IsHealthy(t *testing.T) {
 	// Test healthy classifier
 	healthyClassifier, err := createTestClassifier()
@@ -224,7 +369,7 @@ func TestReadabilityClassifier_Classify_EmptyContent(t *testing.T) {
 	classifier, err := createTestClassifier()
 	require.NoError(t, err)
 
-	result, err := classifier.Classify(server.URL, "")
+	result, err := classifier.Classify(context.Background(), server.URL, "")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -243,7 +388,7 @@ func TestReadabilityClassifier_Classify_NonHTMLContent(t *testing.T) {
 	classifier, err := createTestClassifier()
 	require.NoError(t, err)
 
-	result, err := classifier.Classify(server.URL, "")
+	result, err := classifier.Classify(context.Background(), server.URL, "")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
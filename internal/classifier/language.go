@@ -0,0 +1,158 @@
+package classifier
+
+import (
+	"strings"
+	"unicode"
+)
+
+// languageSampleRunes bounds how much text language detection looks at
+const languageSampleRunes = 4000
+
+// minLanguageLetters is the least text detection will decide on
+const minLanguageLetters = 20
+
+// minStopwordHits is the least evidence for a Latin-script language
+const minStopwordHits = 3
+
+// stopwords are frequent function words that tell Latin-script languages apart
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "in", "that", "it", "for", "with", "was", "this", "are", "on", "be", "you"},
+	"de": {"der", "die", "und", "das", "ist", "nicht", "ein", "eine", "mit", "den", "auf", "sich", "ich", "es", "zu", "von"},
+	"fr": {"le", "la", "les", "et", "des", "est", "une", "un", "que", "pour", "dans", "pas", "du", "sur", "qui", "au"},
+	"es": {"el", "la", "los", "las", "y", "que", "de", "del", "es", "una", "por", "para", "con", "no", "se", "lo"},
+	"it": {"il", "la", "di", "che", "e", "è", "per", "non", "una", "del", "con", "sono", "gli", "della", "le", "un"},
+	"pt": {"o", "a", "os", "as", "de", "que", "e", "não", "uma", "um", "para", "com", "do", "da", "em", "é"},
+	"nl": {"de", "het", "een", "en", "van", "is", "dat", "niet", "op", "te", "zijn", "met", "voor", "die", "ik", "ook"},
+}
+
+// stopwordLanguages maps each stopword to the languages using it
+var stopwordLanguages = func() map[string][]string {
+	index := make(map[string][]string)
+	for language, words := range stopwords {
+		for _, word := range words {
+			index[word] = append(index[word], language)
+		}
+	}
+	return index
+}()
+
+// DetectLanguage guesses the ISO 639-1 code of the text's language. Non-Latin
+// scripts are recognised by their characters, Latin-script languages by their
+// most frequent words. Returns "" when the text is too short or inconclusive.
+func DetectLanguage(text string) string {
+	scripts := make(map[string]int)
+	letters := 0
+	sampled := 0
+	for _, r := range text {
+		if sampled++; sampled > languageSampleRunes {
+			break
+		}
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+			scripts["kana"]++
+		case unicode.Is(unicode.Han, r):
+			scripts["han"]++
+		case unicode.Is(unicode.Hangul, r):
+			scripts["ko"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			if strings.ContainsRune("іїєґІЇЄҐ", r) {
+				scripts["uk"]++ // Letters only Ukrainian uses
+			}
+			scripts["cyrillic"]++
+		case unicode.Is(unicode.Arabic, r):
+			scripts["ar"]++
+		case unicode.Is(unicode.Hebrew, r):
+			scripts["he"]++
+		case unicode.Is(unicode.Greek, r):
+			scripts["el"]++
+		case unicode.Is(unicode.Thai, r):
+			scripts["th"]++
+		case unicode.Is(unicode.Devanagari, r):
+			scripts["hi"]++
+		case unicode.Is(unicode.Latin, r):
+			scripts["latin"]++
+		}
+	}
+
+	if letters < minLanguageLetters {
+		return ""
+	}
+
+	// CJK text shares Han characters; kana marks Japanese
+	if scripts["han"]+scripts["kana"] > letters/2 {
+		if scripts["kana"] > 0 {
+			return "ja"
+		}
+		return "zh"
+	}
+	if scripts["cyrillic"] > letters/2 {
+		if scripts["uk"] > 0 {
+			return "uk"
+		}
+		return "ru"
+	}
+	for _, language := range []string{"ko", "ar", "he", "el", "th", "hi"} {
+		if scripts[language] > letters/2 {
+			return language
+		}
+	}
+	if scripts["latin"] <= letters/2 {
+		return ""
+	}
+
+	return detectLatinLanguage(text)
+}
+
+// detectLatinLanguage picks the language whose stopwords occur most often
+func detectLatinLanguage(text string) string {
+	if len(text) > languageSampleRunes*4 {
+		text = text[:languageSampleRunes*4]
+	}
+
+	hits := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	for _, word := range words {
+		for _, language := range stopwordLanguages[word] {
+			hits[language]++
+		}
+	}
+
+	best, bestHits, runnerUpHits := "", 0, 0
+	for language, count := range hits {
+		switch {
+		case count > bestHits || (count == bestHits && language < best):
+			best, bestHits, runnerUpHits = language, count, bestHits
+		case count > runnerUpHits:
+			runnerUpHits = count
+		}
+	}
+
+	if bestHits < minStopwordHits || bestHits == runnerUpHits {
+		return ""
+	}
+	return best
+}
+
+// normalizeLanguageTag reduces a declared language such as "en-US" to its
+// ISO 639 code, or "" when the tag is not usable
+func normalizeLanguageTag(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if len(tag) < 2 || len(tag) > 3 {
+		return ""
+	}
+	for _, r := range tag {
+		if r < 'a' || r > 'z' {
+			return ""
+		}
+	}
+	return tag
+}
@@ -0,0 +1,66 @@
+package classifier
+
+import (
+	"unicode"
+)
+
+// TextMetrics counts the units text is read in. Chinese and Japanese are
+// written without spaces between words, so their characters are counted one
+// by one rather than split on whitespace.
+type TextMetrics struct {
+	Words      int // Whitespace-separated words of other scripts
+	Characters int // Han ideographs and kana
+}
+
+// MeasureText counts the words and CJK characters of the text. Runs of
+// punctuation or symbols without a letter or digit are not counted as words.
+func MeasureText(text string) TextMetrics {
+	var metrics TextMetrics
+	inWord, wordHasLetter := false, false
+	endWord := func() {
+		if inWord && wordHasLetter {
+			metrics.Words++
+		}
+		inWord, wordHasLetter = false, false
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			endWord()
+			metrics.Characters++
+		case unicode.IsSpace(r):
+			endWord()
+		default:
+			inWord = true
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				wordHasLetter = true
+			}
+		}
+	}
+	endWord()
+
+	return metrics
+}
+
+// WordCount is the length reported for an article, counting each CJK
+// character as a word as word processors do
+func (m TextMetrics) WordCount() int {
+	return m.Words + m.Characters
+}
+
+// ReadingTimeMinutes estimates how long the text takes to read at the given
+// speeds, rounded up to whole minutes. Returns 0 when there is no text.
+func (m TextMetrics) ReadingTimeMinutes(wordsPerMinute, charactersPerMinute int) int {
+	if wordsPerMinute <= 0 || charactersPerMinute <= 0 {
+		return 0
+	}
+	// words/wpm + characters/cpm, kept in integers until rounding up
+	units := m.Words*charactersPerMinute + m.Characters*wordsPerMinute
+	return ReadingTimeMinutes(units, wordsPerMinute*charactersPerMinute)
+}
+
+// isCJK reports characters of scripts written without spaces between words
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r)
+}
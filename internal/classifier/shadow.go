@@ -0,0 +1,225 @@
+package classifier
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/embedding"
+	"github.com/dustin/articles-backend/pkg/logger"
+)
+
+// maxShadowRuns bounds the shadow classifications running at once; pages
+// arriving while all slots are busy are not shadowed
+const maxShadowRuns = 2
+
+// Differences smaller than these are not reported as mismatches
+const (
+	shadowConfidenceTolerance = 0.05
+	shadowWordCountTolerance  = 0.1
+)
+
+// htmlFetcher is implemented by classifiers that can download a page once so
+// both classifiers see the same markup
+type htmlFetcher interface {
+	FetchHTML(url string) (string, error)
+}
+
+// ShadowStats summarises how the shadow classifier compares to the primary
+type ShadowStats struct {
+	Shadow     string `json:"shadow"`
+	Compared   int64  `json:"compared"`
+	Mismatched int64  `json:"mismatched"`
+	Errors     int64  `json:"errors"`
+	Skipped    int64  `json:"skipped"`
+}
+
+// ShadowClassifier returns the primary classifier's results unchanged while a
+// second classifier processes the same pages in the background. The shadow's
+// results are only logged and compared, so new extraction logic can be
+// validated on production traffic without touching stored metadata.
+type ShadowClassifier struct {
+	primary    Classifier
+	shadow     Classifier
+	sampleRate float64
+	slots      chan struct{}
+	wg         sync.WaitGroup
+	logger     *logger.Logger
+
+	compared   atomic.Int64
+	mismatched atomic.Int64
+	errors     atomic.Int64
+	skipped    atomic.Int64
+}
+
+// NewShadowClassifier wraps primary with the classifier named by cfg.Shadow.
+// CLASSIFIER_SHADOW_MIN_CONFIDENCE overrides the threshold for the shadow
+// only, so the current implementation can trial a new threshold.
+func NewShadowClassifier(cfg *config.ClassifierConfig, primary Classifier, embeddingClient embedding.EmbeddingClient, log *logger.Logger) (*ShadowClassifier, error) {
+	shadowCfg := *cfg
+	if cfg.ShadowMinConfidenceScore != "" {
+		shadowCfg.MinConfidenceScore = cfg.ShadowMinConfidenceScore
+	}
+
+	shadow, err := New(cfg.Shadow, &shadowCfg, embeddingClient, log)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shadow classifier: %w", err)
+	}
+
+	sampleRate := 1.0
+	if cfg.ShadowSampleRate != "" {
+		rate, err := strconv.ParseFloat(cfg.ShadowSampleRate, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("invalid shadow sample rate '%s': must be between 0 and 1", cfg.ShadowSampleRate)
+		}
+		sampleRate = rate
+	}
+
+	return newShadowClassifier(primary, shadow, sampleRate, log), nil
+}
+
+func newShadowClassifier(primary, shadow Classifier, sampleRate float64, log *logger.Logger) *ShadowClassifier {
+	return &ShadowClassifier{
+		primary:    primary,
+		shadow:     shadow,
+		sampleRate: sampleRate,
+		slots:      make(chan struct{}, maxShadowRuns),
+		logger:     log.WithComponent("shadow-classifier"),
+	}
+}
+
+// Name reports the primary classifier, which produced the stored results
+func (s *ShadowClassifier) Name() string {
+	return s.primary.Name()
+}
+
+func (s *ShadowClassifier) IsHealthy() bool {
+	return s.primary.IsHealthy()
+}
+
+// Classify returns the primary result and, for sampled pages, starts the
+// shadow classifier on the same markup
+func (s *ShadowClassifier) Classify(ctx context.Context, url string, html string) (*Result, error) {
+	if s.sampleRate < 1 && rand.Float64() >= s.sampleRate {
+		return s.primary.Classify(ctx, url, html)
+	}
+
+	// Fetch the page once so the shadow is not compared against a different response
+	if fetcher, ok := s.primary.(htmlFetcher); ok && html == "" {
+		fetched, err := fetcher.FetchHTML(url)
+		if err != nil {
+			return s.primary.Classify(ctx, url, html)
+		}
+		html = fetched
+	}
+
+	result, err := s.primary.Classify(ctx, url, html)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		s.skipped.Add(1)
+		return result, nil
+	}
+
+	// The shadow outlives the caller, so it keeps only the request ID of ctx
+	shadowCtx := context.WithoutCancel(ctx)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() { <-s.slots }()
+		s.runShadow(shadowCtx, url, html, result)
+	}()
+
+	return result, nil
+}
+
+// runShadow classifies the page with the shadow and logs how it differs
+func (s *ShadowClassifier) runShadow(ctx context.Context, url, html string, primary *Result) {
+	log := s.logger.WithContext(ctx)
+	defer func() {
+		if r := recover(); r != nil {
+			s.errors.Add(1)
+			log.Error(fmt.Sprintf("Shadow classifier %s panicked for %s: %v", s.shadow.Name(), url, r))
+		}
+	}()
+
+	shadow, err := s.shadow.Classify(ctx, url, html)
+	if err != nil {
+		s.errors.Add(1)
+		log.Warn("Shadow classifier " + s.shadow.Name() + " failed for " + url + ": " + err.Error())
+		return
+	}
+
+	s.compared.Add(1)
+	differences := compareResults(primary, shadow)
+	if len(differences) == 0 {
+		log.Debug("Shadow classifier " + s.shadow.Name() + " agrees for " + url)
+		return
+	}
+
+	s.mismatched.Add(1)
+	log.Warn("Shadow classifier " + s.shadow.Name() + " differs for " + url + ": " + strings.Join(differences, ", "))
+}
+
+// Wait blocks until running shadow classifications have finished
+func (s *ShadowClassifier) Wait() {
+	s.wg.Wait()
+}
+
+// Stats returns the comparison counters since startup
+func (s *ShadowClassifier) Stats() ShadowStats {
+	return ShadowStats{
+		Shadow:     s.shadow.Name(),
+		Compared:   s.compared.Load(),
+		Mismatched: s.mismatched.Load(),
+		Errors:     s.errors.Load(),
+		Skipped:    s.skipped.Load(),
+	}
+}
+
+// compareResults describes each field where the shadow result differs from
+// the primary beyond the tolerances, as "field primary→shadow"
+func compareResults(primary, shadow *Result) []string {
+	var differences []string
+
+	if primary.IsArticle != shadow.IsArticle {
+		differences = append(differences, fmt.Sprintf("is_article %t→%t", primary.IsArticle, shadow.IsArticle))
+	}
+	if math.Abs(primary.Confidence-shadow.Confidence) > shadowConfidenceTolerance {
+		differences = append(differences, fmt.Sprintf("confidence %.2f→%.2f", primary.Confidence, shadow.Confidence))
+	}
+	if primary.Title != shadow.Title {
+		differences = append(differences, fmt.Sprintf("title %q→%q", primary.Title, shadow.Title))
+	}
+	if wordCountDiffers(primary.WordCount, shadow.WordCount) {
+		differences = append(differences, fmt.Sprintf("word_count %d→%d", primary.WordCount, shadow.WordCount))
+	}
+	if primary.Language != shadow.Language {
+		differences = append(differences, fmt.Sprintf("language %q→%q", primary.Language, shadow.Language))
+	}
+
+	return differences
+}
+
+// wordCountDiffers reports counts that differ by more than the relative tolerance
+func wordCountDiffers(primary, shadow int) bool {
+	larger := max(primary, shadow)
+	if larger == 0 {
+		return false
+	}
+	diff := primary - shadow
+	if diff < 0 {
+		diff = -diff
+	}
+	return float64(diff)/float64(larger) > shadowWordCountTolerance
+}
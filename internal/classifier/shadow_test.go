@@ -0,0 +1,120 @@
+package classifier
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClassifier returns a fixed result and records the markup it was given
+type fakeClassifier struct {
+	name   string
+	result *Result
+	err    error
+	html   string
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (f *fakeClassifier) Classify(ctx context.Context, url string, html string) (*Result, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, html)
+	f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	result := *f.result
+	return &result, nil
+}
+
+func (f *fakeClassifier) Name() string    { return f.name }
+func (f *fakeClassifier) IsHealthy() bool { return true }
+
+func (f *fakeClassifier) FetchHTML(url string) (string, error) {
+	return f.html, nil
+}
+
+func testLogger() *logger.Logger {
+	log, _ := logger.NewLogger(&config.LoggingConfig{Level: "error"})
+	return log
+}
+
+func TestShadowClassifier_ReturnsPrimaryResult(t *testing.T) {
+	primary := &fakeClassifier{name: "readability", html: "<html>page</html>", result: &Result{IsArticle: true, Confidence: 0.9, Title: "Primary", WordCount: 1000, Language: "en"}}
+	shadow := &fakeClassifier{name: "candidate", result: &Result{IsArticle: false, Confidence: 0.4, Title: "Shadow", WordCount: 500, Language: "de"}}
+	s := newShadowClassifier(primary, shadow, 1, testLogger())
+
+	result, err := s.Classify(context.Background(), "https://example.com/a", "")
+	require.NoError(t, err)
+	s.Wait()
+
+	assert.Equal(t, "Primary", result.Title)
+	assert.Equal(t, "readability", s.Name())
+	// The page is fetched once and both classifiers see the same markup
+	assert.Equal(t, []string{"<html>page</html>"}, primary.calls)
+	assert.Equal(t, []string{"<html>page</html>"}, shadow.calls)
+
+	stats := s.Stats()
+	assert.Equal(t, int64(1), stats.Compared)
+	assert.Equal(t, int64(1), stats.Mismatched)
+	assert.Equal(t, "candidate", stats.Shadow)
+}
+
+func TestShadowClassifier_ShadowErrorDoesNotAffectPrimary(t *testing.T) {
+	primary := &fakeClassifier{name: "readability", result: &Result{IsArticle: true, Title: "Primary"}}
+	shadow := &fakeClassifier{name: "candidate", err: errors.New("boom")}
+	s := newShadowClassifier(primary, shadow, 1, testLogger())
+
+	result, err := s.Classify(context.Background(), "https://example.com/a", "<html></html>")
+	require.NoError(t, err)
+	s.Wait()
+
+	assert.Equal(t, "Primary", result.Title)
+	assert.Equal(t, int64(1), s.Stats().Errors)
+	assert.Equal(t, int64(0), s.Stats().Compared)
+}
+
+func TestShadowClassifier_NotSampled(t *testing.T) {
+	primary := &fakeClassifier{name: "readability", result: &Result{Title: "Primary"}}
+	shadow := &fakeClassifier{name: "candidate", result: &Result{Title: "Shadow"}}
+	s := newShadowClassifier(primary, shadow, 0, testLogger())
+
+	_, err := s.Classify(context.Background(), "https://example.com/a", "<html></html>")
+	require.NoError(t, err)
+	s.Wait()
+
+	assert.Empty(t, shadow.calls)
+}
+
+func TestNewShadowClassifier_InvalidConfig(t *testing.T) {
+	primary := &fakeClassifier{name: "readability"}
+
+	_, err := NewShadowClassifier(&config.ClassifierConfig{Shadow: "unknown"}, primary, nil, testLogger())
+	assert.Error(t, err)
+
+	_, err = NewShadowClassifier(&config.ClassifierConfig{Shadow: "readability", ShadowSampleRate: "1.5"}, primary, nil, testLogger())
+	assert.Error(t, err)
+}
+
+func TestCompareResults(t *testing.T) {
+	primary := &Result{IsArticle: true, Confidence: 0.80, Title: "Title", WordCount: 1000, Language: "en"}
+
+	// Small confidence and word count drift is tolerated
+	assert.Empty(t, compareResults(primary, &Result{IsArticle: true, Confidence: 0.83, Title: "Title", WordCount: 950, Language: "en"}))
+
+	differences := compareResults(primary, &Result{IsArticle: false, Confidence: 0.5, Title: "Other", WordCount: 700, Language: "fr"})
+	assert.Equal(t, []string{
+		"is_article true→false",
+		"confidence 0.80→0.50",
+		`title "Title"→"Other"`,
+		"word_count 1000→700",
+		`language "en"→"fr"`,
+	}, differences)
+}
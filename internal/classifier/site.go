@@ -0,0 +1,62 @@
+package classifier
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// findIconLink returns the href of the page's first icon link, preferring
+// rel="icon" over rel="apple-touch-icon". Readability only picks PNG favicons,
+// so this catches the .ico and SVG icons most sites declare.
+func findIconLink(page string) string {
+	touchIcon := ""
+	tokenizer := html.NewTokenizer(strings.NewReader(page))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return touchIcon
+		case html.EndTagToken:
+			// Icons are declared in the head, so stop at its end
+			if name, _ := tokenizer.TagName(); string(name) == "head" {
+				return touchIcon
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			if string(name) == "body" {
+				return touchIcon
+			}
+			if string(name) != "link" || !hasAttr {
+				continue
+			}
+
+			var rel, href string
+			for {
+				key, value, more := tokenizer.TagAttr()
+				switch string(key) {
+				case "rel":
+					rel = strings.ToLower(string(value))
+				case "href":
+					href = strings.TrimSpace(string(value))
+				}
+				if !more {
+					break
+				}
+			}
+			if href == "" {
+				continue
+			}
+
+			for _, token := range strings.Fields(rel) {
+				switch token {
+				case "icon":
+					return href
+				case "apple-touch-icon":
+					if touchIcon == "" {
+						touchIcon = href
+					}
+				}
+			}
+		}
+	}
+}
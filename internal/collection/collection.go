@@ -0,0 +1,182 @@
+package collection
+
+import (
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// MaxNameLength matches the size of the collections.name column
+const MaxNameLength = 100
+
+// Errors returned by the collection service and repository
+var (
+	ErrNotFound               = utils.NewNotFoundError("collection not found")
+	ErrArticleNotFound        = utils.NewNotFoundError("article not found")
+	ErrArticleNotInCollection = utils.NewNotFoundError("article is not in this collection")
+	ErrAlreadyExists          = utils.NewConflictError("a collection with this name already exists")
+)
+
+// Collection is a user-named folder grouping articles. An article may sit in
+// any number of collections; deleting a collection keeps its articles.
+type Collection struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID      uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_user_collection_name"`
+	Name        string    `json:"name" gorm:"size:100;not null;uniqueIndex:idx_user_collection_name"`
+	Description string    `json:"description" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Number of articles, filled in by listing queries only
+	ArticleCount int `json:"article_count" gorm:"->;-:migration"`
+
+	// Associations (forward declarations)
+	User *User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for GORM
+func (Collection) TableName() string {
+	return "collections"
+}
+
+// Membership assigns an article to a collection
+type Membership struct {
+	CollectionID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ArticleID    uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	AddedAt      time.Time `gorm:"autoCreateTime"`
+
+	// Associations (forward declarations)
+	Collection *Collection `gorm:"foreignKey:CollectionID;constraint:OnDelete:CASCADE"`
+	Article    *Article    `gorm:"foreignKey:ArticleID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for GORM
+func (Membership) TableName() string {
+	return "collection_articles"
+}
+
+// User represents user for foreign key relationship (forward declaration)
+type User struct {
+	ID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Email string
+}
+
+// Article is the summary of an article shown in collection listings (forward declaration)
+type Article struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID         uuid.UUID `json:"user_id" gorm:"type:uuid;not null"`
+	URL            string    `json:"url"`
+	Title          string    `json:"title"`
+	Description    string    `json:"description"`
+	ImageURL       string    `json:"image_url"`
+	WordCount      int       `json:"word_count"`
+	ReadingTime    int       `json:"reading_time_minutes" gorm:"column:reading_time_minutes"`
+	MetadataStatus string    `json:"metadata_status"`
+	CreatedAt      time.Time `json:"created_at"`
+	AddedAt        time.Time `json:"added_at" gorm:"->;-:migration"` // When the article joined the collection
+}
+
+// TableName returns the table name for GORM
+func (Article) TableName() string {
+	return "articles"
+}
+
+// Repository defines the interface for collection data access
+type Repository interface {
+	Create(collection *Collection) error
+	FindByID(id uuid.UUID) (*Collection, error)
+	FindByName(userID uuid.UUID, name string) (*Collection, error)
+	FindByUserID(userID uuid.UUID) ([]*Collection, error)
+	Update(collection *Collection) error
+	Delete(id uuid.UUID) error
+
+	// Article assignment
+	AddArticle(collectionID, articleID uuid.UUID) error
+	RemoveArticle(collectionID, articleID uuid.UUID) error
+	FindArticles(collectionID uuid.UUID, offset, limit int) ([]*Article, int64, error)
+}
+
+// Service defines the interface for collection business logic
+type Service interface {
+	CreateCollection(userID uuid.UUID, req *CreateCollectionRequest) (*Collection, error)
+	GetCollection(id, userID uuid.UUID) (*Collection, error)
+	GetUserCollections(userID uuid.UUID) ([]*Collection, error)
+	UpdateCollection(id, userID uuid.UUID, req *UpdateCollectionRequest) (*Collection, error)
+	DeleteCollection(id, userID uuid.UUID) error
+	AddArticle(id, userID, articleID uuid.UUID) error
+	RemoveArticle(id, userID, articleID uuid.UUID) error
+	GetArticles(id, userID uuid.UUID, page, limit int) ([]*Article, int64, error)
+}
+
+// ArticleService interface for article ownership checks (dependency inversion)
+type ArticleService interface {
+	GetArticle(id uuid.UUID, userID uuid.UUID) (*Article, error)
+}
+
+// CreateCollectionRequest represents collection creation request
+type CreateCollectionRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+// UpdateCollectionRequest represents a partial update of a collection
+type UpdateCollectionRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+// AddArticleRequest represents a request to put an article into a collection
+type AddArticleRequest struct {
+	ArticleID string `json:"article_id" binding:"required"`
+}
+
+// CollectionListResponse lists a user's collections
+type CollectionListResponse struct {
+	Collections []*Collection `json:"collections"`
+}
+
+// CollectionArticlesResponse represents a paginated page of a collection's articles
+type CollectionArticlesResponse struct {
+	Collection *Collection `json:"collection"`
+	Articles   []*Article  `json:"articles"`
+	Total      int64       `json:"total"`
+	Page       int         `json:"page"`
+	Limit      int         `json:"limit"`
+	Pages      int         `json:"pages"`
+}
+
+// BuildArticlesResponse creates a paginated response of a collection's articles
+func BuildArticlesResponse(collection *Collection, articles []*Article, total int64, page, limit int) *CollectionArticlesResponse {
+	if articles == nil {
+		articles = []*Article{}
+	}
+
+	pagination := utils.CalculatePagination(total, page, limit)
+
+	return &CollectionArticlesResponse{
+		Collection: collection,
+		Articles:   articles,
+		Total:      pagination.Total,
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+		Pages:      pagination.Pages,
+	}
+}
+
+// ValidateName sanitizes a collection name and checks it is present and fits the name column
+func ValidateName(name string) (string, error) {
+	name, err := utils.ValidateText("name", name, MaxNameLength)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		return "", utils.NewValidationError("name", "is required")
+	}
+	return name, nil
+}
+
+// IsOwnedBy checks if the collection belongs to the specified user
+func (c *Collection) IsOwnedBy(userID uuid.UUID) bool {
+	return c.UserID == userID
+}
@@ -0,0 +1,240 @@
+package collection
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T, articles ...*Article) (Service, *mockRepository) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	repo := newMockRepository()
+	return NewService(repo, &mockArticleService{articles: articles}, log), repo
+}
+
+func TestValidateName(t *testing.T) {
+	name, err := ValidateName("  Reading list \x00")
+	require.NoError(t, err)
+	assert.Equal(t, "Reading list", name)
+
+	_, err = ValidateName("   ")
+	assert.Error(t, err)
+
+	_, err = ValidateName(strings.Repeat("a", MaxNameLength+1))
+	validationErr, ok := utils.AsValidationError(err)
+	require.True(t, ok)
+	assert.Equal(t, "name", validationErr.Field)
+}
+
+func TestCollectionLifecycle(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("Names are unique per user", func(t *testing.T) {
+		svc, _ := newTestService(t)
+
+		created, err := svc.CreateCollection(userID, &CreateCollectionRequest{Name: "Go"})
+		require.NoError(t, err)
+		assert.Equal(t, "Go", created.Name)
+
+		_, err = svc.CreateCollection(userID, &CreateCollectionRequest{Name: " Go "})
+		assert.ErrorIs(t, err, ErrAlreadyExists)
+
+		_, err = svc.CreateCollection(uuid.New(), &CreateCollectionRequest{Name: "Go"})
+		assert.NoError(t, err, "other users may reuse the name")
+
+		// Keeping the current name on update is not a conflict
+		name := "Go"
+		_, err = svc.UpdateCollection(created.ID, userID, &UpdateCollectionRequest{Name: &name})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Other users cannot see a collection", func(t *testing.T) {
+		svc, _ := newTestService(t)
+
+		created, err := svc.CreateCollection(userID, &CreateCollectionRequest{Name: "Private"})
+		require.NoError(t, err)
+
+		_, err = svc.GetCollection(created.ID, uuid.New())
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		err = svc.DeleteCollection(created.ID, uuid.New())
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Update requires a field", func(t *testing.T) {
+		svc, _ := newTestService(t)
+
+		created, err := svc.CreateCollection(userID, &CreateCollectionRequest{Name: "Later"})
+		require.NoError(t, err)
+
+		_, err = svc.UpdateCollection(created.ID, userID, &UpdateCollectionRequest{})
+		_, ok := utils.AsValidationError(err)
+		assert.True(t, ok)
+
+		description := "Weekend reads"
+		updated, err := svc.UpdateCollection(created.ID, userID, &UpdateCollectionRequest{Description: &description})
+		require.NoError(t, err)
+		assert.Equal(t, "Weekend reads", updated.Description)
+		assert.Equal(t, "Later", updated.Name)
+	})
+}
+
+func TestCollectionArticles(t *testing.T) {
+	userID := uuid.New()
+	owned := &Article{ID: uuid.New(), UserID: userID, Title: "Mine"}
+	foreign := &Article{ID: uuid.New(), UserID: uuid.New(), Title: "Theirs"}
+
+	svc, repo := newTestService(t, owned, foreign)
+	created, err := svc.CreateCollection(userID, &CreateCollectionRequest{Name: "Go"})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.AddArticle(created.ID, userID, owned.ID))
+	require.NoError(t, svc.AddArticle(created.ID, userID, owned.ID), "adding twice is a no-op")
+
+	err = svc.AddArticle(created.ID, userID, foreign.ID)
+	assert.ErrorIs(t, err, ErrArticleNotFound)
+
+	articles, total, err := svc.GetArticles(created.ID, userID, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, articles, 1)
+	assert.Equal(t, "Mine", articles[0].Title)
+
+	_, _, err = svc.GetArticles(created.ID, uuid.New(), 1, 20)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, svc.RemoveArticle(created.ID, userID, owned.ID))
+	err = svc.RemoveArticle(created.ID, userID, owned.ID)
+	assert.ErrorIs(t, err, ErrArticleNotInCollection)
+
+	// Deleting the collection drops memberships only
+	require.NoError(t, svc.AddArticle(created.ID, userID, owned.ID))
+	require.NoError(t, svc.DeleteCollection(created.ID, userID))
+	assert.Empty(t, repo.memberships)
+}
+
+func TestBuildArticlesResponse(t *testing.T) {
+	response := BuildArticlesResponse(&Collection{Name: "Go"}, nil, 45, 2, 20)
+
+	assert.NotNil(t, response.Articles)
+	assert.Equal(t, int64(45), response.Total)
+	assert.Equal(t, 2, response.Page)
+	assert.Equal(t, 3, response.Pages)
+}
+
+// mockRepository stores collections in memory
+type mockRepository struct {
+	collections map[uuid.UUID]*Collection
+	memberships map[uuid.UUID][]uuid.UUID // Collection ID to article IDs
+	articles    map[uuid.UUID]*Article
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{
+		collections: make(map[uuid.UUID]*Collection),
+		memberships: make(map[uuid.UUID][]uuid.UUID),
+		articles:    make(map[uuid.UUID]*Article),
+	}
+}
+
+func (m *mockRepository) Create(collection *Collection) error {
+	stored := *collection
+	m.collections[collection.ID] = &stored
+	return nil
+}
+
+func (m *mockRepository) FindByID(id uuid.UUID) (*Collection, error) {
+	collection, ok := m.collections[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	found := *collection
+	found.ArticleCount = len(m.memberships[id])
+	return &found, nil
+}
+
+func (m *mockRepository) FindByName(userID uuid.UUID, name string) (*Collection, error) {
+	for _, collection := range m.collections {
+		if collection.UserID == userID && collection.Name == name {
+			return collection, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m *mockRepository) FindByUserID(userID uuid.UUID) ([]*Collection, error) {
+	var collections []*Collection
+	for _, collection := range m.collections {
+		if collection.UserID == userID {
+			collections = append(collections, collection)
+		}
+	}
+	return collections, nil
+}
+
+func (m *mockRepository) Update(collection *Collection) error {
+	stored := *collection
+	m.collections[collection.ID] = &stored
+	return nil
+}
+
+func (m *mockRepository) Delete(id uuid.UUID) error {
+	delete(m.collections, id)
+	delete(m.memberships, id)
+	return nil
+}
+
+func (m *mockRepository) AddArticle(collectionID, articleID uuid.UUID) error {
+	for _, id := range m.memberships[collectionID] {
+		if id == articleID {
+			return nil
+		}
+	}
+	m.memberships[collectionID] = append(m.memberships[collectionID], articleID)
+	m.articles[articleID] = &Article{ID: articleID, Title: "Mine", AddedAt: time.Now()}
+	return nil
+}
+
+func (m *mockRepository) RemoveArticle(collectionID, articleID uuid.UUID) error {
+	ids := m.memberships[collectionID]
+	for i, id := range ids {
+		if id == articleID {
+			m.memberships[collectionID] = append(ids[:i], ids[i+1:]...)
+			return nil
+		}
+	}
+	return ErrArticleNotInCollection
+}
+
+func (m *mockRepository) FindArticles(collectionID uuid.UUID, offset, limit int) ([]*Article, int64, error) {
+	ids := m.memberships[collectionID]
+	var articles []*Article
+	for i := offset; i < len(ids) && i < offset+limit; i++ {
+		articles = append(articles, m.articles[ids[i]])
+	}
+	return articles, int64(len(ids)), nil
+}
+
+// mockArticleService resolves articles the way the article service does, hiding other users' articles
+type mockArticleService struct {
+	articles []*Article
+}
+
+func (m *mockArticleService) GetArticle(id uuid.UUID, userID uuid.UUID) (*Article, error) {
+	for _, article := range m.articles {
+		if article.ID == id && article.UserID == userID {
+			return article, nil
+		}
+	}
+	return nil, errors.New("article not found")
+}
@@ -0,0 +1,241 @@
+package collection
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for collection operations
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new collection handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// CreateCollection handles collection creation
+func (h *Handler) CreateCollection(c *gin.Context) {
+	var req CreateCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Extract user ID from JWT token
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	collection, err := h.service.CreateCollection(userID, &req)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to create collection")
+		return
+	}
+
+	c.JSON(http.StatusCreated, collection)
+}
+
+// GetCollections handles listing the user's collections
+func (h *Handler) GetCollections(c *gin.Context) {
+	// Extract user ID from JWT token
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	collections, err := h.service.GetUserCollections(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collections"})
+		return
+	}
+	if collections == nil {
+		collections = []*Collection{}
+	}
+
+	c.JSON(http.StatusOK, &CollectionListResponse{Collections: collections})
+}
+
+// GetCollection handles getting a single collection
+func (h *Handler) GetCollection(c *gin.Context) {
+	userID, collectionID, ok := h.collectionTarget(c)
+	if !ok {
+		return
+	}
+
+	collection, err := h.service.GetCollection(collectionID, userID)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to fetch collection")
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// UpdateCollection handles renaming a collection or changing its description
+func (h *Handler) UpdateCollection(c *gin.Context) {
+	var req UpdateCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, collectionID, ok := h.collectionTarget(c)
+	if !ok {
+		return
+	}
+
+	collection, err := h.service.UpdateCollection(collectionID, userID, &req)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to update collection")
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// DeleteCollection handles collection deletion; its articles are kept
+func (h *Handler) DeleteCollection(c *gin.Context) {
+	userID, collectionID, ok := h.collectionTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteCollection(collectionID, userID); err != nil {
+		utils.RespondError(c, err, "Failed to delete collection")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collection deleted successfully"})
+}
+
+// GetArticles handles listing the articles of a collection with pagination
+func (h *Handler) GetArticles(c *gin.Context) {
+	userID, collectionID, ok := h.collectionTarget(c)
+	if !ok {
+		return
+	}
+
+	// Parse pagination parameters
+	page := 1
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	limit := 20
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+
+	collection, err := h.service.GetCollection(collectionID, userID)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to fetch collection")
+		return
+	}
+
+	articles, total, err := h.service.GetArticles(collectionID, userID, page, limit)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to fetch collection articles")
+		return
+	}
+
+	c.JSON(http.StatusOK, BuildArticlesResponse(collection, articles, total, page, limit))
+}
+
+// AddArticle handles putting an article into a collection
+func (h *Handler) AddArticle(c *gin.Context) {
+	var req AddArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, collectionID, ok := h.collectionTarget(c)
+	if !ok {
+		return
+	}
+
+	articleID, err := uuid.Parse(req.ArticleID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	if err := h.service.AddArticle(collectionID, userID, articleID); err != nil {
+		utils.RespondError(c, err, "Failed to add article to collection")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Article added to collection"})
+}
+
+// RemoveArticle handles taking an article out of a collection
+func (h *Handler) RemoveArticle(c *gin.Context) {
+	userID, collectionID, ok := h.collectionTarget(c)
+	if !ok {
+		return
+	}
+
+	articleID, err := uuid.Parse(c.Param("articleId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	if err := h.service.RemoveArticle(collectionID, userID, articleID); err != nil {
+		utils.RespondError(c, err, "Failed to remove article from collection")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Article removed from collection"})
+}
+
+// collectionTarget extracts the user and collection of a request, writing the error response on failure
+func (h *Handler) collectionTarget(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	collectionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, collectionID, true
+}
+
+// RegisterRoutes registers all collection routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	// All collection routes require authentication
+	collections := router.Group("/collections")
+	collections.Use(authMiddleware)
+	{
+		collections.POST("", h.CreateCollection)
+		collections.GET("", h.GetCollections)
+		collections.GET("/:id", h.GetCollection)
+		collections.PATCH("/:id", h.UpdateCollection)
+		collections.DELETE("/:id", h.DeleteCollection)
+
+		// Article assignment
+		collections.GET("/:id/articles", h.GetArticles)
+		collections.POST("/:id/articles", h.AddArticle)
+		collections.DELETE("/:id/articles/:articleId", h.RemoveArticle)
+	}
+}
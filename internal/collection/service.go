@@ -0,0 +1,200 @@
+package collection
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// service implements the Service interface
+type service struct {
+	repo           Repository
+	articleService ArticleService
+	logger         *logger.Logger
+}
+
+// NewService creates a new collection service
+func NewService(repo Repository, articleService ArticleService, log *logger.Logger) Service {
+	return &service{
+		repo:           repo,
+		articleService: articleService,
+		logger:         log.WithComponent("collection-service"),
+	}
+}
+
+func (s *service) CreateCollection(userID uuid.UUID, req *CreateCollectionRequest) (*Collection, error) {
+	name, err := ValidateName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	description, err := utils.ValidateText("description", req.Description, utils.MaxDescriptionLength)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Creating collection " + name + " for user " + userID.String())
+
+	if err := s.ensureNameAvailable(userID, name, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	collection := &Collection{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Name:        name,
+		Description: description,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := s.repo.Create(collection); err != nil {
+		s.logger.Error("Failed to create collection " + name + " for user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	return collection, nil
+}
+
+func (s *service) GetCollection(id, userID uuid.UUID) (*Collection, error) {
+	collection, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify ownership
+	if !collection.IsOwnedBy(userID) {
+		return nil, ErrNotFound
+	}
+
+	return collection, nil
+}
+
+func (s *service) GetUserCollections(userID uuid.UUID) ([]*Collection, error) {
+	return s.repo.FindByUserID(userID)
+}
+
+func (s *service) UpdateCollection(id, userID uuid.UUID, req *UpdateCollectionRequest) (*Collection, error) {
+	s.logger.Info("Updating collection " + id.String() + " for user " + userID.String())
+
+	collection, err := s.GetCollection(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name == nil && req.Description == nil {
+		return nil, utils.NewValidationError("body", "at least one of name or description is required")
+	}
+
+	if req.Name != nil {
+		name, err := ValidateName(*req.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.ensureNameAvailable(userID, name, collection.ID); err != nil {
+			return nil, err
+		}
+		collection.Name = name
+	}
+	if req.Description != nil {
+		description, err := utils.ValidateText("description", *req.Description, utils.MaxDescriptionLength)
+		if err != nil {
+			return nil, err
+		}
+		collection.Description = description
+	}
+
+	collection.UpdatedAt = time.Now()
+	if err := s.repo.Update(collection); err != nil {
+		s.logger.Error("Failed to update collection " + id.String() + " for user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	return collection, nil
+}
+
+func (s *service) DeleteCollection(id, userID uuid.UUID) error {
+	s.logger.Info("Deleting collection " + id.String() + " for user " + userID.String())
+
+	if _, err := s.GetCollection(id, userID); err != nil {
+		return err
+	}
+
+	// Memberships cascade; the articles themselves stay in the library
+	if err := s.repo.Delete(id); err != nil {
+		s.logger.Error("Failed to delete collection " + id.String() + " for user " + userID.String() + ": " + err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) AddArticle(id, userID, articleID uuid.UUID) error {
+	s.logger.Info("Adding article " + articleID.String() + " to collection " + id.String() + " for user " + userID.String())
+
+	if _, err := s.GetCollection(id, userID); err != nil {
+		return err
+	}
+
+	// Only the user's own articles can be collected
+	if _, err := s.articleService.GetArticle(articleID, userID); err != nil {
+		s.logger.Info("Article not found or access denied " + articleID.String() + " for user " + userID.String() + ": " + err.Error())
+		return ErrArticleNotFound
+	}
+
+	if err := s.repo.AddArticle(id, articleID); err != nil {
+		s.logger.Error("Failed to add article " + articleID.String() + " to collection " + id.String() + ": " + err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) RemoveArticle(id, userID, articleID uuid.UUID) error {
+	s.logger.Info("Removing article " + articleID.String() + " from collection " + id.String() + " for user " + userID.String())
+
+	if _, err := s.GetCollection(id, userID); err != nil {
+		return err
+	}
+
+	if err := s.repo.RemoveArticle(id, articleID); err != nil {
+		if !errors.Is(err, ErrArticleNotInCollection) {
+			s.logger.Error("Failed to remove article " + articleID.String() + " from collection " + id.String() + ": " + err.Error())
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) GetArticles(id, userID uuid.UUID, page, limit int) ([]*Article, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	if _, err := s.GetCollection(id, userID); err != nil {
+		return nil, 0, err
+	}
+
+	return s.repo.FindArticles(id, (page-1)*limit, limit)
+}
+
+// ensureNameAvailable rejects a name already used by another of the user's collections
+func (s *service) ensureNameAvailable(userID uuid.UUID, name string, exceptID uuid.UUID) error {
+	existing, err := s.repo.FindByName(userID, name)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != exceptID {
+		return ErrAlreadyExists
+	}
+	return nil
+}
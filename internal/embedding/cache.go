@@ -0,0 +1,301 @@
+package embedding
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// defaultCacheSize holds about 15 MB of vectors
+const defaultCacheSize = 5000
+
+// CacheStats counts how often embeddings were answered from the cache
+type CacheStats struct {
+	Hits     int64 `json:"hits"`
+	Misses   int64 `json:"misses"`
+	Entries  int   `json:"entries"`
+	Capacity int   `json:"capacity"`
+}
+
+// cacheEntry is an embedding kept by the hash of its text
+type cacheEntry struct {
+	key    [sha256.Size]byte
+	vector []float64
+}
+
+// CachingClient decorates an embedding client with an in-memory LRU cache of
+// embeddings keyed by the SHA-256 of their text, so the same text is embedded
+// once. Query embeddings and stored article embeddings are cached apart, as
+// the service embeds articles with a model chosen by their language.
+type CachingClient struct {
+	inner    EmbeddingClient
+	store    VectorStore
+	capacity int // 0 disables the cache
+	logger   *logger.Logger
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List // Most recently used first
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewCachingClient creates a caching client with validation and defaults.
+// Cached article embeddings are written to store without calling the service.
+func NewCachingClient(inner EmbeddingClient, cfg *config.EmbeddingConfig, store VectorStore, log *logger.Logger) (*CachingClient, error) {
+	capacity := defaultCacheSize
+	if cfg.CacheSize != "" {
+		size, err := strconv.Atoi(cfg.CacheSize)
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("invalid embedding cache size '%s': must be a non-negative integer", cfg.CacheSize)
+		}
+		capacity = size
+	}
+
+	return &CachingClient{
+		inner:    inner,
+		store:    store,
+		capacity: capacity,
+		logger:   log.WithComponent("embedding-cache"),
+		entries:  make(map[[sha256.Size]byte]*list.Element),
+		order:    list.New(),
+	}, nil
+}
+
+// Stats returns the cache counters since startup
+func (c *CachingClient) Stats() CacheStats {
+	c.mu.Lock()
+	entries := c.order.Len()
+	c.mu.Unlock()
+
+	return CacheStats{
+		Hits:     c.hits.Load(),
+		Misses:   c.misses.Load(),
+		Entries:  entries,
+		Capacity: c.capacity,
+	}
+}
+
+// queryKey keys the embedding of a query text
+func queryKey(text string) [sha256.Size]byte {
+	return sha256.Sum256([]byte("query\x00" + text))
+}
+
+// articleKey keys the stored embedding of an article text in a language
+func articleKey(text, language string) [sha256.Size]byte {
+	return sha256.Sum256([]byte("article\x00" + language + "\x00" + text))
+}
+
+// get returns a copy of the cached vector and counts the hit or miss
+func (c *CachingClient) get(key [sha256.Size]byte) ([]float64, bool) {
+	c.mu.Lock()
+	element, ok := c.entries[key]
+	if ok {
+		c.order.MoveToFront(element)
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return append([]float64(nil), element.Value.(*cacheEntry).vector...), true
+}
+
+// put caches a copy of the vector, evicting the least recently used entry
+// when full
+func (c *CachingClient) put(key [sha256.Size]byte, vector []float64) {
+	if c.capacity == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	vector = append([]float64(nil), vector...)
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*cacheEntry).vector = vector
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, vector: vector})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (c *CachingClient) GetEmbedding(ctx context.Context, text string) ([]float64, error) {
+	key := queryKey(text)
+	if vector, ok := c.get(key); ok {
+		return vector, nil
+	}
+
+	vector, err := c.inner.GetEmbedding(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	c.put(key, vector)
+	return vector, nil
+}
+
+// GetBatchEmbeddings embeds only the texts missing from the cache, each once
+func (c *CachingClient) GetBatchEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return c.inner.GetBatchEmbeddings(ctx, texts)
+	}
+
+	vectors := make([][]float64, len(texts))
+	var missing []string
+	missingAt := make(map[string][]int) // Positions of each missing text
+	for i, text := range texts {
+		if vector, ok := c.get(queryKey(text)); ok {
+			vectors[i] = vector
+			continue
+		}
+		if _, ok := missingAt[text]; !ok {
+			missing = append(missing, text)
+		}
+		missingAt[text] = append(missingAt[text], i)
+	}
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	embedded, err := c.inner.GetBatchEmbeddings(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	if len(embedded) != len(missing) {
+		return nil, fmt.Errorf("embedding service returned %d embeddings for %d texts", len(embedded), len(missing))
+	}
+	for j, text := range missing {
+		c.put(queryKey(text), embedded[j])
+		for _, i := range missingAt[text] {
+			vectors[i] = embedded[j]
+		}
+	}
+	return vectors, nil
+}
+
+// StoreArticleEmbeddings writes cached embeddings straight to the store and
+// sends only the other texts to the service. Embeddings it stores are read
+// back to be cached.
+func (c *CachingClient) StoreArticleEmbeddings(ctx context.Context, articles []ArticleText) (*BatchStoreResponse, error) {
+	if len(articles) == 0 || c.capacity == 0 {
+		return c.inner.StoreArticleEmbeddings(ctx, articles)
+	}
+
+	results := make([]*StoreResult, len(articles))
+	var missing []ArticleText
+	var missingAt []int
+	successful := 0
+	for i, article := range articles {
+		if c.storeCached(article) {
+			results[i] = &StoreResult{ArticleID: article.ID, Status: "success"}
+			successful++
+			continue
+		}
+		missing = append(missing, article)
+		missingAt = append(missingAt, i)
+	}
+
+	if len(missing) > 0 {
+		response, err := c.inner.StoreArticleEmbeddings(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		successful += response.SuccessfulUpdates
+
+		// Results come in request order
+		if len(response.Results) == len(missing) {
+			for j, result := range response.Results {
+				results[missingAt[j]] = &result
+				if result.Status == "success" {
+					c.remember(missing[j])
+				}
+			}
+		}
+	}
+
+	response := &BatchStoreResponse{TotalArticles: len(articles), SuccessfulUpdates: successful}
+	for _, result := range results {
+		if result != nil {
+			response.Results = append(response.Results, *result)
+		}
+	}
+	return response, nil
+}
+
+// storeCached stores the cached embedding of the article's text and reports
+// whether it did
+func (c *CachingClient) storeCached(article ArticleText) bool {
+	text := strings.TrimSpace(article.Text)
+	articleID, err := uuid.Parse(article.ID)
+	if text == "" || err != nil || (article.Target != "" && article.Target != TargetTitle && article.Target != TargetContent) {
+		return false
+	}
+
+	vector, ok := c.get(articleKey(text, article.Language))
+	if !ok {
+		return false
+	}
+
+	target := article.Target
+	if target == "" {
+		target = TargetTitle
+	}
+	if err := c.store.StoreEmbedding(articleID, target, vector); err != nil {
+		c.logger.Error("Failed to store cached embedding of article " + article.ID + ": " + err.Error())
+		return false
+	}
+	return true
+}
+
+// remember caches the embedding just stored for the article's text
+func (c *CachingClient) remember(article ArticleText) {
+	articleID, err := uuid.Parse(article.ID)
+	if err != nil {
+		return
+	}
+
+	target := article.Target
+	if target == "" {
+		target = TargetTitle
+	}
+	vector, err := c.store.LoadEmbedding(articleID, target)
+	if err != nil {
+		c.logger.Error("Failed to load stored embedding of article " + article.ID + ": " + err.Error())
+		return
+	}
+	c.put(articleKey(strings.TrimSpace(article.Text), article.Language), vector)
+}
+
+func (c *CachingClient) CalculateSimilarity(ctx context.Context, embedding1, embedding2 []float64) (float64, error) {
+	return c.inner.CalculateSimilarity(ctx, embedding1, embedding2)
+}
+
+func (c *CachingClient) HealthCheck(ctx context.Context) (*HealthResponse, error) {
+	return c.inner.HealthCheck(ctx)
+}
+
+func (c *CachingClient) ClassifyContent(ctx context.Context, text string) (*ClassifyResponse, error) {
+	return c.inner.ClassifyContent(ctx, text)
+}
+
+func (c *CachingClient) ClassifyBatchContent(ctx context.Context, texts []string) (*BatchClassifyResponse, error) {
+	return c.inner.ClassifyBatchContent(ctx, texts)
+}
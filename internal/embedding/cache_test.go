@@ -0,0 +1,123 @@
+package embedding
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingClient embeds each text as its length and stores embeddings in a
+// vector store, counting the texts it was sent
+type countingClient struct {
+	EmbeddingClient
+	store fakeVectorStore
+	texts []string
+}
+
+func (c *countingClient) GetEmbedding(ctx context.Context, text string) ([]float64, error) {
+	c.texts = append(c.texts, text)
+	return []float64{float64(len(text))}, nil
+}
+
+func (c *countingClient) GetBatchEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i], _ = c.GetEmbedding(ctx, text)
+	}
+	return vectors, nil
+}
+
+func (c *countingClient) StoreArticleEmbeddings(ctx context.Context, articles []ArticleText) (*BatchStoreResponse, error) {
+	response := &BatchStoreResponse{TotalArticles: len(articles)}
+	for _, article := range articles {
+		vector, _ := c.GetEmbedding(ctx, article.Text)
+		_ = c.store.StoreEmbedding(uuid.MustParse(article.ID), article.Target, vector)
+		response.SuccessfulUpdates++
+		response.Results = append(response.Results, StoreResult{ArticleID: article.ID, Status: "success"})
+	}
+	return response, nil
+}
+
+func newTestCachingClient(t *testing.T, inner *countingClient, size string) *CachingClient {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+	client, err := NewCachingClient(inner, &config.EmbeddingConfig{CacheSize: size}, inner.store, log)
+	require.NoError(t, err)
+	return client
+}
+
+func TestCachingClient(t *testing.T) {
+	t.Run("Embeds each text once", func(t *testing.T) {
+		inner := &countingClient{store: fakeVectorStore{}}
+		client := newTestCachingClient(t, inner, "")
+
+		vector, err := client.GetEmbedding(context.Background(), "golang")
+		require.NoError(t, err)
+		assert.Equal(t, []float64{6}, vector)
+
+		vectors, err := client.GetBatchEmbeddings(context.Background(), []string{"golang", "rust", "rust"})
+		require.NoError(t, err)
+		assert.Equal(t, [][]float64{{6}, {4}, {4}}, vectors)
+
+		assert.Equal(t, []string{"golang", "rust"}, inner.texts)
+		assert.Equal(t, CacheStats{Hits: 1, Misses: 3, Entries: 2, Capacity: defaultCacheSize}, client.Stats())
+	})
+
+	t.Run("Stores cached article embeddings without the service", func(t *testing.T) {
+		inner := &countingClient{store: fakeVectorStore{}}
+		client := newTestCachingClient(t, inner, "")
+
+		first, second := uuid.New(), uuid.New()
+		_, err := client.StoreArticleEmbeddings(context.Background(), []ArticleText{{ID: first.String(), Text: "Go 1.23 released", Target: TargetTitle}})
+		require.NoError(t, err)
+
+		// The same text saved by another user, and in another language
+		response, err := client.StoreArticleEmbeddings(context.Background(), []ArticleText{
+			{ID: second.String(), Text: " Go 1.23 released ", Target: TargetTitle},
+			{ID: second.String(), Text: "Go 1.23 released", Target: TargetContent, Language: "de"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, response.SuccessfulUpdates)
+		assert.Len(t, response.Results, 2)
+		assert.Equal(t, []float64{16}, inner.store[second.String()+" "+TargetTitle])
+		assert.Len(t, inner.texts, 2)
+		assert.Equal(t, int64(1), client.Stats().Hits)
+	})
+
+	t.Run("Evicts the least recently used text", func(t *testing.T) {
+		inner := &countingClient{store: fakeVectorStore{}}
+		client := newTestCachingClient(t, inner, "2")
+
+		for _, text := range []string{"a", "b", "a", "c", "a", "b"} {
+			_, err := client.GetEmbedding(context.Background(), text)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, []string{"a", "b", "c", "b"}, inner.texts)
+		assert.Equal(t, 2, client.Stats().Entries)
+	})
+
+	t.Run("A size of 0 disables the cache", func(t *testing.T) {
+		inner := &countingClient{store: fakeVectorStore{}}
+		client := newTestCachingClient(t, inner, "0")
+
+		for i := 0; i < 2; i++ {
+			_, err := client.GetEmbedding(context.Background(), "golang")
+			require.NoError(t, err)
+		}
+		assert.Len(t, inner.texts, 2)
+		assert.Zero(t, client.Stats().Entries)
+	})
+
+	t.Run("Rejects invalid sizes", func(t *testing.T) {
+		log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+		require.NoError(t, err)
+
+		_, err = NewCachingClient(&countingClient{}, &config.EmbeddingConfig{CacheSize: "-1"}, nil, log)
+		assert.Error(t, err)
+	})
+}
@@ -2,21 +2,47 @@ package embedding
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/dustin/articles-backend/pkg/logger"
 )
 
-// EmbeddingClient defines the interface for embedding operations
+// EmbeddingClient defines the interface for embedding operations. Every call
+// is bound to ctx: it is abandoned once ctx is done, and the request ID of
+// ctx is forwarded to the service.
 type EmbeddingClient interface {
-	GetEmbedding(text string) ([]float64, error)
-	GetBatchEmbeddings(texts []string) ([][]float64, error)
-	CalculateSimilarity(embedding1, embedding2 []float64) (float64, error)
-	HealthCheck() (*HealthResponse, error)
-	ClassifyContent(text string) (*ClassifyResponse, error)
-	ClassifyBatchContent(texts []string) (*BatchClassifyResponse, error)
+	GetEmbedding(ctx context.Context, text string) ([]float64, error)
+	GetBatchEmbeddings(ctx context.Context, texts []string) ([][]float64, error)
+	CalculateSimilarity(ctx context.Context, embedding1, embedding2 []float64) (float64, error)
+	HealthCheck(ctx context.Context) (*HealthResponse, error)
+	ClassifyContent(ctx context.Context, text string) (*ClassifyResponse, error)
+	ClassifyBatchContent(ctx context.Context, texts []string) (*BatchClassifyResponse, error)
+	StoreArticleEmbeddings(ctx context.Context, articles []ArticleText) (*BatchStoreResponse, error)
+}
+
+// ErrInvalidInput is returned for calls the service would reject, before any request is made
+var ErrInvalidInput = errors.New("invalid input")
+
+// StatusError is returned when the service answers with an error status
+type StatusError struct {
+	Service    string // Which endpoint failed, e.g. embedding or classification
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s service error (status %d): %s", e.Service, e.StatusCode, e.Body)
+}
+
+// Temporary reports whether the request may succeed when tried again
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode >= http.StatusInternalServerError || e.StatusCode == http.StatusTooManyRequests
 }
 
 // Client handles communication with the embedding microservice
@@ -35,6 +61,33 @@ func NewClient(baseURL string) *Client {
 	}
 }
 
+func (c *Client) post(ctx context.Context, url string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.setRequestID(req)
+	return c.client.Do(req)
+}
+
+func (c *Client) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setRequestID(req)
+	return c.client.Do(req)
+}
+
+// setRequestID forwards the ID of the request being served, so the embedding
+// service logs its work under the same ID
+func (c *Client) setRequestID(req *http.Request) {
+	if requestID := logger.RequestIDFromContext(req.Context()); requestID != "" {
+		req.Header.Set(logger.RequestIDHeader, requestID)
+	}
+}
+
 // EmbedRequest represents a single text embedding request
 type EmbedRequest struct {
 	Text string `json:"text"`
@@ -104,19 +157,58 @@ type ClassifyResult struct {
 	Index      int     `json:"index"`
 }
 
+// Embedding targets an article text can be stored under
+const (
+	TargetTitle   = "title"   // title + description
+	TargetContent = "content" // full article content
+)
+
+// ArticleText identifies an article and the text to embed for it.
+// Target selects the embedding column and defaults to TargetTitle.
+// Language is the detected ISO 639-1 code, empty if unknown.
+type ArticleText struct {
+	ID       string `json:"id"`
+	Text     string `json:"text"`
+	Target   string `json:"target,omitempty"`
+	Language string `json:"language,omitempty"`
+}
+
+// BatchStoreRequest represents a request to embed and persist multiple articles
+type BatchStoreRequest struct {
+	Articles []ArticleText `json:"articles"`
+}
+
+// BatchStoreResponse represents the result of persisting article embeddings
+type BatchStoreResponse struct {
+	TotalArticles     int           `json:"total_articles"`
+	SuccessfulUpdates int           `json:"successful_updates"`
+	Results           []StoreResult `json:"results"`
+}
+
+// StoreResult represents the outcome for a single article
+type StoreResult struct {
+	ArticleID string `json:"article_id"`
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+}
+
 // HealthResponse represents the health check response
 type HealthResponse struct {
 	Status               string `json:"status"`
 	EmbeddingModel       string `json:"embedding_model"`
+	MultilingualModel    string `json:"multilingual_model"`
 	ClassifierModel      string `json:"classifier_model"`
 	EmbeddingModelLoaded bool   `json:"embedding_model_loaded"`
 	ClassifierLoaded     bool   `json:"classifier_loaded"`
+	DatabaseHealthy      bool   `json:"database_healthy"`
+	EmbeddingDimension   int    `json:"embedding_dimension"` // 0 from services that do not report it
+	Provider             string `json:"-"`                   // Which backend embeds texts, set by the client
 }
 
 // GetEmbedding generates an embedding for a single text
-func (c *Client) GetEmbedding(text string) ([]float64, error) {
+func (c *Client) GetEmbedding(ctx context.Context, text string) ([]float64, error) {
 	if text == "" {
-		return nil, fmt.Errorf("empty text provided")
+		return nil, fmt.Errorf("%w: empty text provided", ErrInvalidInput)
 	}
 
 	reqBody := EmbedRequest{Text: text}
@@ -125,7 +217,7 @@ func (c *Client) GetEmbedding(text string) ([]float64, error) {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.client.Post(c.baseURL+"/embed", "application/json", bytes.NewBuffer(jsonData))
+	resp, err := c.post(ctx, c.baseURL+"/embed", jsonData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -133,7 +225,7 @@ func (c *Client) GetEmbedding(text string) ([]float64, error) {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("embedding service error (status %d): %s", resp.StatusCode, string(body))
+		return nil, &StatusError{Service: "embedding", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var embedResp EmbedResponse
@@ -145,9 +237,9 @@ func (c *Client) GetEmbedding(text string) ([]float64, error) {
 }
 
 // GetBatchEmbeddings generates embeddings for multiple texts
-func (c *Client) GetBatchEmbeddings(texts []string) ([][]float64, error) {
+func (c *Client) GetBatchEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
 	if len(texts) == 0 {
-		return nil, fmt.Errorf("empty texts list provided")
+		return nil, fmt.Errorf("%w: empty texts list provided", ErrInvalidInput)
 	}
 
 	reqBody := BatchEmbedRequest{Texts: texts}
@@ -156,7 +248,7 @@ func (c *Client) GetBatchEmbeddings(texts []string) ([][]float64, error) {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.client.Post(c.baseURL+"/embed/batch", "application/json", bytes.NewBuffer(jsonData))
+	resp, err := c.post(ctx, c.baseURL+"/embed/batch", jsonData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -164,7 +256,7 @@ func (c *Client) GetBatchEmbeddings(texts []string) ([][]float64, error) {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("embedding service error (status %d): %s", resp.StatusCode, string(body))
+		return nil, &StatusError{Service: "embedding", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var embedResp BatchEmbedResponse
@@ -176,9 +268,9 @@ func (c *Client) GetBatchEmbeddings(texts []string) ([][]float64, error) {
 }
 
 // CalculateSimilarity calculates cosine similarity between two embeddings
-func (c *Client) CalculateSimilarity(embedding1, embedding2 []float64) (float64, error) {
+func (c *Client) CalculateSimilarity(ctx context.Context, embedding1, embedding2 []float64) (float64, error) {
 	if len(embedding1) != len(embedding2) {
-		return 0, fmt.Errorf("embedding dimensions don't match: %d vs %d", len(embedding1), len(embedding2))
+		return 0, fmt.Errorf("%w: embedding dimensions don't match: %d vs %d", ErrInvalidInput, len(embedding1), len(embedding2))
 	}
 
 	reqBody := SimilarityRequest{
@@ -190,7 +282,7 @@ func (c *Client) CalculateSimilarity(embedding1, embedding2 []float64) (float64,
 		return 0, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.client.Post(c.baseURL+"/similarity", "application/json", bytes.NewBuffer(jsonData))
+	resp, err := c.post(ctx, c.baseURL+"/similarity", jsonData)
 	if err != nil {
 		return 0, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -198,7 +290,7 @@ func (c *Client) CalculateSimilarity(embedding1, embedding2 []float64) (float64,
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return 0, fmt.Errorf("embedding service error (status %d): %s", resp.StatusCode, string(body))
+		return 0, &StatusError{Service: "embedding", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var simResp SimilarityResponse
@@ -210,25 +302,30 @@ func (c *Client) CalculateSimilarity(embedding1, embedding2 []float64) (float64,
 }
 
 // HealthCheck checks if the embedding service is healthy
-func (c *Client) HealthCheck() (*HealthResponse, error) {
-	resp, err := c.client.Get(c.baseURL + "/health")
+func (c *Client) HealthCheck(ctx context.Context) (*HealthResponse, error) {
+	resp, err := c.get(ctx, c.baseURL+"/health")
 	if err != nil {
 		return nil, fmt.Errorf("failed to make health check request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("health check returned HTTP %d", resp.StatusCode)
+	}
+
 	var healthResp HealthResponse
 	if err := json.NewDecoder(resp.Body).Decode(&healthResp); err != nil {
 		return nil, fmt.Errorf("failed to decode health response: %w", err)
 	}
+	healthResp.Provider = ProviderService
 
 	return &healthResp, nil
 }
 
 // ClassifyContent classifies if content is article-worthy using ML model
-func (c *Client) ClassifyContent(text string) (*ClassifyResponse, error) {
+func (c *Client) ClassifyContent(ctx context.Context, text string) (*ClassifyResponse, error) {
 	if text == "" {
-		return nil, fmt.Errorf("empty text provided")
+		return nil, fmt.Errorf("%w: empty text provided", ErrInvalidInput)
 	}
 
 	reqBody := ClassifyRequest{Text: text}
@@ -237,7 +334,7 @@ func (c *Client) ClassifyContent(text string) (*ClassifyResponse, error) {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.client.Post(c.baseURL+"/classify", "application/json", bytes.NewBuffer(jsonData))
+	resp, err := c.post(ctx, c.baseURL+"/classify", jsonData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -245,7 +342,7 @@ func (c *Client) ClassifyContent(text string) (*ClassifyResponse, error) {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("classification service error (status %d): %s", resp.StatusCode, string(body))
+		return nil, &StatusError{Service: "classification", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var classifyResp ClassifyResponse
@@ -257,9 +354,9 @@ func (c *Client) ClassifyContent(text string) (*ClassifyResponse, error) {
 }
 
 // ClassifyBatchContent classifies multiple texts for article-worthiness
-func (c *Client) ClassifyBatchContent(texts []string) (*BatchClassifyResponse, error) {
+func (c *Client) ClassifyBatchContent(ctx context.Context, texts []string) (*BatchClassifyResponse, error) {
 	if len(texts) == 0 {
-		return nil, fmt.Errorf("empty texts list provided")
+		return nil, fmt.Errorf("%w: empty texts list provided", ErrInvalidInput)
 	}
 
 	reqBody := BatchClassifyRequest{Texts: texts}
@@ -268,7 +365,7 @@ func (c *Client) ClassifyBatchContent(texts []string) (*BatchClassifyResponse, e
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.client.Post(c.baseURL+"/classify/batch", "application/json", bytes.NewBuffer(jsonData))
+	resp, err := c.post(ctx, c.baseURL+"/classify/batch", jsonData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -276,7 +373,7 @@ func (c *Client) ClassifyBatchContent(texts []string) (*BatchClassifyResponse, e
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("batch classification service error (status %d): %s", resp.StatusCode, string(body))
+		return nil, &StatusError{Service: "batch classification", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var batchResp BatchClassifyResponse
@@ -286,3 +383,34 @@ func (c *Client) ClassifyBatchContent(texts []string) (*BatchClassifyResponse, e
 
 	return &batchResp, nil
 }
+
+// StoreArticleEmbeddings generates embeddings for articles and stores them in the database
+func (c *Client) StoreArticleEmbeddings(ctx context.Context, articles []ArticleText) (*BatchStoreResponse, error) {
+	if len(articles) == 0 {
+		return nil, fmt.Errorf("%w: empty articles list provided", ErrInvalidInput)
+	}
+
+	reqBody := BatchStoreRequest{Articles: articles}
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := c.post(ctx, c.baseURL+"/articles/batch/embedding", jsonData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{Service: "embedding", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var storeResp BatchStoreResponse
+	if err := json.NewDecoder(resp.Body).Decode(&storeResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &storeResp, nil
+}
@@ -0,0 +1,51 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientContext(t *testing.T) {
+	t.Run("Forwards the request ID", func(t *testing.T) {
+		var requestID string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID = r.Header.Get(logger.RequestIDHeader)
+			_ = json.NewEncoder(w).Encode(EmbedResponse{Embedding: []float64{0.1, 0.2}})
+		}))
+		defer server.Close()
+
+		ctx := logger.ContextWithRequestID(context.Background(), "req-123")
+		embedding, err := NewClient(server.URL).GetEmbedding(ctx, "hello")
+		require.NoError(t, err)
+		assert.Equal(t, []float64{0.1, 0.2}, embedding)
+		assert.Equal(t, "req-123", requestID)
+	})
+
+	t.Run("Gives up once the context is done", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		defer server.Close()
+		defer close(release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := NewClient(server.URL).GetBatchEmbeddings(ctx, []string{"hello"})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+}
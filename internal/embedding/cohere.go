@@ -0,0 +1,102 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+)
+
+const (
+	defaultCohereURL   = "https://api.cohere.com/v2"
+	defaultCohereModel = "embed-multilingual-light-v3.0" // Produces the schema's 384 dimensions
+	cohereMaxBatch     = 96                              // Texts per request
+)
+
+// cohereModelDimensions lists the vector sizes of known Cohere models
+var cohereModelDimensions = map[string]int{
+	"embed-english-v3.0":            1024,
+	"embed-multilingual-v3.0":       1024,
+	"embed-english-light-v3.0":      384,
+	"embed-multilingual-light-v3.0": 384,
+	"embed-v4.0":                    1536,
+}
+
+// cohereProvider embeds with the Cohere embed API
+type cohereProvider struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+func newCohereProvider(cfg *config.EmbeddingConfig) (*cohereProvider, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("embedding API key is required for the %s provider", ProviderCohere)
+	}
+
+	provider := &cohereProvider{
+		baseURL: defaultCohereURL,
+		apiKey:  cfg.APIKey,
+		model:   defaultCohereModel,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+	if cfg.APIURL != "" {
+		provider.baseURL = strings.TrimRight(cfg.APIURL, "/")
+	}
+	if cfg.Model != "" {
+		provider.model = cfg.Model
+	}
+
+	// Cohere cannot shorten vectors to the schema's size, so the model must
+	// produce it; unknown models are checked on their first answer
+	if dimension, ok := cohereModelDimensions[provider.model]; ok && dimension != Dimension {
+		return nil, fmt.Errorf("embedding model '%s' produces %d dimensions, the database stores %d", provider.model, dimension, Dimension)
+	}
+
+	return provider, nil
+}
+
+func (p *cohereProvider) Name() string {
+	return ProviderCohere
+}
+
+func (p *cohereProvider) Model() string {
+	return p.model
+}
+
+// cohereEmbedRequest is the body of an /embed request
+type cohereEmbedRequest struct {
+	Model          string   `json:"model"`
+	Texts          []string `json:"texts"`
+	InputType      string   `json:"input_type"`
+	EmbeddingTypes []string `json:"embedding_types"`
+}
+
+// cohereEmbedResponse is the answer to an /embed request
+type cohereEmbedResponse struct {
+	Embeddings struct {
+		Float [][]float64 `json:"float"`
+	} `json:"embeddings"`
+}
+
+// Embed implements Provider. Cohere embeds search queries differently from
+// the documents they are matched against.
+func (p *cohereProvider) Embed(ctx context.Context, texts []string, purpose Purpose) ([][]float64, error) {
+	inputType := "search_document"
+	if purpose == PurposeQuery {
+		inputType = "search_query"
+	}
+
+	return embedInChunks(texts, cohereMaxBatch, func(chunk []string) ([][]float64, error) {
+		request := cohereEmbedRequest{Model: p.model, Texts: chunk, InputType: inputType, EmbeddingTypes: []string{"float"}}
+		var response cohereEmbedResponse
+		if err := postJSON(ctx, p.client, ProviderCohere, p.baseURL+"/embed", p.apiKey, request, &response); err != nil {
+			return nil, err
+		}
+		return response.Embeddings.Float, nil
+	})
+}
@@ -0,0 +1,125 @@
+package embedding
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Dimension is the size of the vectors the database schema stores
+const Dimension = 384
+
+// healthCheckTimeout bounds a single health check so a hung service cannot
+// stall the health endpoint
+const healthCheckTimeout = 3 * time.Second
+
+// HealthStatus describes the embedding service as of the last check
+type HealthStatus struct {
+	Healthy           bool           `json:"healthy"`
+	Status            string         `json:"status,omitempty"`   // As reported by the service
+	Provider          string         `json:"provider,omitempty"` // Which backend embeds texts
+	EmbeddingModel    string         `json:"embedding_model,omitempty"`
+	MultilingualModel string         `json:"multilingual_model,omitempty"`
+	ClassifierModel   string         `json:"classifier_model,omitempty"`
+	Dimension         int            `json:"dimension,omitempty"` // 0 when the service does not report it
+	ExpectedDimension int            `json:"expected_dimension"`
+	DimensionMismatch bool           `json:"dimension_mismatch"`
+	Error             string         `json:"error,omitempty"`
+	CheckedAt         time.Time      `json:"checked_at"`
+	LastSuccessAt     *time.Time     `json:"last_success_at,omitempty"` // Last check that found the service healthy
+	CircuitBreaker    *BreakerStatus `json:"circuit_breaker,omitempty"` // Current, not cached; set for resilient clients
+}
+
+// HealthMonitor checks the embedding service on demand and caches the result,
+// so frequent health probes do not each call the service
+type HealthMonitor struct {
+	client  EmbeddingClient
+	breaker interface{ Breaker() BreakerStatus } // Nil unless the client has a circuit breaker
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu            sync.Mutex
+	status        *HealthStatus
+	cachedUntil   time.Time
+	lastSuccessAt *time.Time
+}
+
+// NewHealthMonitor creates a monitor reusing each check for ttl
+func NewHealthMonitor(client EmbeddingClient, ttl time.Duration) *HealthMonitor {
+	monitor := &HealthMonitor{
+		client: client,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+	if breaker, ok := client.(interface{ Breaker() BreakerStatus }); ok {
+		monitor.breaker = breaker
+	}
+	return monitor
+}
+
+// Status returns the cached status, checking the service again once it is
+// older than the TTL. Concurrent callers wait for a single check. While the
+// circuit breaker is open the service counts as unhealthy.
+func (m *HealthMonitor) Status() HealthStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if m.status == nil || !now.Before(m.cachedUntil) {
+		m.status = m.check(now)
+		m.cachedUntil = now.Add(m.ttl)
+	}
+
+	status := *m.status
+	if m.breaker != nil {
+		breaker := m.breaker.Breaker()
+		status.CircuitBreaker = &breaker
+		if breaker.State != BreakerClosed {
+			status.Healthy = false
+			if status.Error == "" {
+				status.Error = ErrCircuitOpen.Error()
+			}
+		}
+	}
+	return status
+}
+
+// check calls the service; the caller must hold m.mu
+func (m *HealthMonitor) check(now time.Time) *HealthStatus {
+	status := &HealthStatus{ExpectedDimension: Dimension, CheckedAt: now}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	response, err := m.client.HealthCheck(ctx)
+	if err != nil {
+		status.Error = err.Error()
+		status.LastSuccessAt = m.lastSuccessAt
+		return status
+	}
+
+	status.Status = response.Status
+	status.Provider = response.Provider
+	status.EmbeddingModel = response.EmbeddingModel
+	status.MultilingualModel = response.MultilingualModel
+	status.ClassifierModel = response.ClassifierModel
+	status.Dimension = response.EmbeddingDimension
+	// Vectors of another size cannot be stored or compared with existing ones
+	status.DimensionMismatch = response.EmbeddingDimension != 0 && response.EmbeddingDimension != Dimension
+
+	switch {
+	case response.Status != "healthy":
+		status.Error = "service reports status " + response.Status
+	case !response.EmbeddingModelLoaded:
+		status.Error = "embedding model is not loaded"
+	case status.DimensionMismatch:
+		status.Error = "embedding dimension does not match the database schema"
+	default:
+		status.Healthy = true
+		success := now
+		m.lastSuccessAt = &success
+	}
+
+	status.LastSuccessAt = m.lastSuccessAt
+	return status
+}
@@ -0,0 +1,70 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthMonitor(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	client := &fakeHealthClient{response: &HealthResponse{
+		Status:               "healthy",
+		EmbeddingModel:       "all-MiniLM-L6-v2",
+		EmbeddingModelLoaded: true,
+		EmbeddingDimension:   Dimension,
+	}}
+	monitor := NewHealthMonitor(client, time.Minute)
+	monitor.now = func() time.Time { return now }
+
+	status := monitor.Status()
+	assert.True(t, status.Healthy)
+	assert.Equal(t, "all-MiniLM-L6-v2", status.EmbeddingModel)
+	assert.Equal(t, now, *status.LastSuccessAt)
+
+	// Checks are cached for the TTL
+	monitor.Status()
+	assert.Equal(t, 1, client.calls)
+
+	// A failure keeps reporting the last success
+	client.err = errors.New("connection refused")
+	now = now.Add(time.Minute)
+	status = monitor.Status()
+	assert.False(t, status.Healthy)
+	assert.Equal(t, "connection refused", status.Error)
+	assert.Equal(t, now.Add(-time.Minute), *status.LastSuccessAt)
+	assert.Equal(t, 2, client.calls)
+
+	// Vectors of another size cannot be stored
+	client.err = nil
+	client.response.EmbeddingDimension = 768
+	now = now.Add(time.Minute)
+	status = monitor.Status()
+	assert.False(t, status.Healthy)
+	assert.True(t, status.DimensionMismatch)
+
+	// Services that do not report a dimension are not failed for it
+	client.response.EmbeddingDimension = 0
+	now = now.Add(time.Minute)
+	assert.True(t, monitor.Status().Healthy)
+}
+
+// fakeHealthClient answers health checks; other calls are not used
+type fakeHealthClient struct {
+	EmbeddingClient
+	response *HealthResponse
+	err      error
+	calls    int
+}
+
+func (f *fakeHealthClient) HealthCheck(ctx context.Context) (*HealthResponse, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	response := *f.response
+	return &response, nil
+}
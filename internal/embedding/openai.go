@@ -0,0 +1,118 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+)
+
+const (
+	defaultOpenAIURL   = "https://api.openai.com/v1"
+	defaultOpenAIModel = "text-embedding-3-small"
+	openAIMaxBatch     = 2048 // Inputs per request
+)
+
+// openAIModel describes the vectors of a known OpenAI model
+type openAIModel struct {
+	dimension   int
+	shortenable bool // Accepts a dimensions parameter to return shorter vectors
+}
+
+var openAIModels = map[string]openAIModel{
+	"text-embedding-3-small": {dimension: 1536, shortenable: true},
+	"text-embedding-3-large": {dimension: 3072, shortenable: true},
+	"text-embedding-ada-002": {dimension: 1536},
+}
+
+// openAIProvider embeds with OpenAI, or any server offering an
+// OpenAI-compatible /embeddings endpoint
+type openAIProvider struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	dimensions int // Requested from shortenable models; 0 requests none
+	client     *http.Client
+}
+
+func newOpenAIProvider(cfg *config.EmbeddingConfig) (*openAIProvider, error) {
+	provider := &openAIProvider{
+		baseURL: defaultOpenAIURL,
+		apiKey:  cfg.APIKey,
+		model:   defaultOpenAIModel,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+	if cfg.APIURL != "" {
+		provider.baseURL = strings.TrimRight(cfg.APIURL, "/")
+	}
+	if cfg.Model != "" {
+		provider.model = cfg.Model
+	}
+
+	// OpenAI itself needs a key; compatible servers may not
+	if provider.apiKey == "" && provider.baseURL == defaultOpenAIURL {
+		return nil, fmt.Errorf("embedding API key is required for the %s provider", ProviderOpenAI)
+	}
+
+	// Known models either shorten their vectors to the schema's size or must
+	// already produce it; other models are checked on their first answer
+	if model, ok := openAIModels[provider.model]; ok {
+		switch {
+		case model.shortenable:
+			provider.dimensions = Dimension
+		case model.dimension != Dimension:
+			return nil, fmt.Errorf("embedding model '%s' produces %d dimensions, the database stores %d", provider.model, model.dimension, Dimension)
+		}
+	}
+
+	return provider, nil
+}
+
+func (p *openAIProvider) Name() string {
+	return ProviderOpenAI
+}
+
+func (p *openAIProvider) Model() string {
+	return p.model
+}
+
+// openAIEmbedRequest is the body of an /embeddings request
+type openAIEmbedRequest struct {
+	Model          string   `json:"model"`
+	Input          []string `json:"input"`
+	Dimensions     int      `json:"dimensions,omitempty"`
+	EncodingFormat string   `json:"encoding_format"`
+}
+
+// openAIEmbedResponse is the answer to an /embeddings request
+type openAIEmbedResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed implements Provider; OpenAI models embed queries and documents alike
+func (p *openAIProvider) Embed(ctx context.Context, texts []string, purpose Purpose) ([][]float64, error) {
+	return embedInChunks(texts, openAIMaxBatch, func(chunk []string) ([][]float64, error) {
+		request := openAIEmbedRequest{Model: p.model, Input: chunk, Dimensions: p.dimensions, EncodingFormat: "float"}
+		var response openAIEmbedResponse
+		if err := postJSON(ctx, p.client, ProviderOpenAI, p.baseURL+"/embeddings", p.apiKey, request, &response); err != nil {
+			return nil, err
+		}
+		if len(response.Data) != len(chunk) {
+			return nil, fmt.Errorf("%s returned %d embeddings for %d texts", ProviderOpenAI, len(response.Data), len(chunk))
+		}
+
+		sort.Slice(response.Data, func(i, j int) bool { return response.Data[i].Index < response.Data[j].Index })
+		vectors := make([][]float64, len(response.Data))
+		for i, data := range response.Data {
+			vectors[i] = data.Embedding
+		}
+		return vectors, nil
+	})
+}
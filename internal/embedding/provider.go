@@ -0,0 +1,298 @@
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// Embedding providers
+const (
+	ProviderService = "service" // The embedding microservice
+	ProviderOpenAI  = "openai"  // OpenAI or any API compatible with its /embeddings endpoint
+	ProviderCohere  = "cohere"
+)
+
+// Purpose tells a provider what the texts are embedded for; some models embed
+// search queries differently from the documents they are matched against
+type Purpose string
+
+// Embedding purposes
+const (
+	PurposeDocument Purpose = "document" // Article texts, stored for recommendations
+	PurposeQuery    Purpose = "query"    // Search queries and interest topics
+)
+
+// ErrDimensionMismatch is returned when a provider produces vectors the
+// database schema cannot store
+var ErrDimensionMismatch = errors.New("embedding dimension does not match the database schema")
+
+// Vector store errors, both of kind utils.ErrNotFound
+var (
+	ErrArticleNotFound = utils.NewNotFoundError("article not found")
+	ErrNoEmbedding     = utils.NewNotFoundError("article has no embedding")
+)
+
+// Provider turns texts into vectors of the schema's Dimension
+type Provider interface {
+	// Name returns the provider, e.g. openai
+	Name() string
+	// Model returns the model the provider embeds with; empty when the
+	// provider reports it itself
+	Model() string
+	// Embed returns one vector per text, in order
+	Embed(ctx context.Context, texts []string, purpose Purpose) ([][]float64, error)
+}
+
+// VectorStore saves embeddings computed outside the embedding service onto
+// their articles, and reads stored ones back
+type VectorStore interface {
+	// StoreEmbedding sets the title or content embedding of an article and
+	// marks it successful
+	StoreEmbedding(articleID uuid.UUID, target string, vector []float64) error
+	// LoadEmbedding returns the title or content embedding of an article
+	LoadEmbedding(articleID uuid.UUID, target string) ([]float64, error)
+}
+
+// NewProvider creates the provider selected by the configuration. The
+// embedding service is its own provider.
+func NewProvider(cfg *config.EmbeddingConfig, service *Client) (Provider, error) {
+	switch name := strings.ToLower(strings.TrimSpace(cfg.Provider)); name {
+	case "", ProviderService:
+		return service, nil
+	case ProviderOpenAI:
+		return newOpenAIProvider(cfg)
+	case ProviderCohere:
+		return newCohereProvider(cfg)
+	default:
+		return nil, fmt.Errorf("invalid embedding provider '%s': must be one of %s, %s, %s", cfg.Provider, ProviderService, ProviderOpenAI, ProviderCohere)
+	}
+}
+
+// Name implements Provider
+func (c *Client) Name() string {
+	return ProviderService
+}
+
+// Model implements Provider; the service reports its models in health checks
+func (c *Client) Model() string {
+	return ""
+}
+
+// Embed implements Provider. The service picks its model by language when
+// storing article embeddings, so the purpose does not matter here.
+func (c *Client) Embed(ctx context.Context, texts []string, purpose Purpose) ([][]float64, error) {
+	return c.GetBatchEmbeddings(ctx, texts)
+}
+
+// providerClient embeds with a provider other than the embedding service,
+// which still classifies content and stores nothing itself
+type providerClient struct {
+	provider Provider
+	service  *Client
+	store    VectorStore
+	logger   *logger.Logger
+
+	mu        sync.Mutex
+	dimension int // Of the last vectors the provider returned
+}
+
+// NewProviderClient creates an embedding client that embeds with provider and
+// stores article embeddings in store. With the embedding service as provider
+// this is the service client itself, which stores embeddings on its own.
+func NewProviderClient(provider Provider, service *Client, store VectorStore, log *logger.Logger) EmbeddingClient {
+	if provider == Provider(service) {
+		return service
+	}
+	return &providerClient{
+		provider: provider,
+		service:  service,
+		store:    store,
+		logger:   log.WithComponent("embedding-provider"),
+	}
+}
+
+// embed calls the provider and checks it returned vectors the schema stores
+func (c *providerClient) embed(ctx context.Context, texts []string, purpose Purpose) ([][]float64, error) {
+	vectors, err := c.provider.Embed(ctx, texts, purpose)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("%s returned %d embeddings for %d texts", c.provider.Name(), len(vectors), len(texts))
+	}
+	c.mu.Lock()
+	c.dimension = len(vectors[0])
+	c.mu.Unlock()
+	for _, vector := range vectors {
+		if len(vector) != Dimension {
+			return nil, fmt.Errorf("%w: %s model %s returned %d dimensions, the database stores %d",
+				ErrDimensionMismatch, c.provider.Name(), c.provider.Model(), len(vector), Dimension)
+		}
+	}
+	return vectors, nil
+}
+
+func (c *providerClient) GetEmbedding(ctx context.Context, text string) ([]float64, error) {
+	if text == "" {
+		return nil, fmt.Errorf("%w: empty text provided", ErrInvalidInput)
+	}
+	vectors, err := c.embed(ctx, []string{text}, PurposeQuery)
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+func (c *providerClient) GetBatchEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("%w: empty texts list provided", ErrInvalidInput)
+	}
+	return c.embed(ctx, texts, PurposeQuery)
+}
+
+func (c *providerClient) CalculateSimilarity(ctx context.Context, embedding1, embedding2 []float64) (float64, error) {
+	return c.service.CalculateSimilarity(ctx, embedding1, embedding2)
+}
+
+// HealthCheck checks the service, which still classifies content, and reports
+// the provider's model in place of the service's
+func (c *providerClient) HealthCheck(ctx context.Context) (*HealthResponse, error) {
+	response, err := c.service.HealthCheck(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	dimension := c.dimension
+	c.mu.Unlock()
+
+	response.Provider = c.provider.Name()
+	response.EmbeddingModel = c.provider.Model()
+	response.MultilingualModel = ""
+	response.EmbeddingModelLoaded = true
+	// Unknown until the provider has returned vectors
+	response.EmbeddingDimension = dimension
+	return response, nil
+}
+
+func (c *providerClient) ClassifyContent(ctx context.Context, text string) (*ClassifyResponse, error) {
+	return c.service.ClassifyContent(ctx, text)
+}
+
+func (c *providerClient) ClassifyBatchContent(ctx context.Context, texts []string) (*BatchClassifyResponse, error) {
+	return c.service.ClassifyBatchContent(ctx, texts)
+}
+
+// StoreArticleEmbeddings embeds the texts with the provider and stores them,
+// reporting per text like the service does
+func (c *providerClient) StoreArticleEmbeddings(ctx context.Context, articles []ArticleText) (*BatchStoreResponse, error) {
+	if len(articles) == 0 {
+		return nil, fmt.Errorf("%w: empty articles list provided", ErrInvalidInput)
+	}
+
+	response := &BatchStoreResponse{TotalArticles: len(articles)}
+	response.Results = make([]StoreResult, len(articles))
+
+	// Only valid texts are sent to the provider
+	var texts []string
+	var valid []int
+	articleIDs := make([]uuid.UUID, len(articles))
+	for i, article := range articles {
+		response.Results[i] = StoreResult{ArticleID: article.ID, Status: "error"}
+		text := strings.TrimSpace(article.Text)
+		articleID, err := uuid.Parse(article.ID)
+		switch {
+		case article.Target != "" && article.Target != TargetTitle && article.Target != TargetContent:
+			response.Results[i].Message = "Invalid target, expected 'title' or 'content'"
+		case text == "":
+			response.Results[i].Message = "Missing article ID or text"
+		case err != nil:
+			response.Results[i].Message = "Invalid article ID format"
+		default:
+			articleIDs[i] = articleID
+			texts = append(texts, text)
+			valid = append(valid, i)
+		}
+	}
+	if len(texts) == 0 {
+		return response, nil
+	}
+
+	vectors, err := c.embed(ctx, texts, PurposeDocument)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range valid {
+		target := articles[i].Target
+		if target == "" {
+			target = TargetTitle
+		}
+		if err := c.store.StoreEmbedding(articleIDs[i], target, vectors[j]); err != nil {
+			c.logger.Error("Failed to store embedding of article " + articles[i].ID + ": " + err.Error())
+			response.Results[i].Message = err.Error()
+			continue
+		}
+		response.Results[i].Status = "success"
+		response.SuccessfulUpdates++
+	}
+	return response, nil
+}
+
+// postJSON sends body to an external embedding API and decodes its answer into out
+func postJSON(ctx context.Context, client *http.Client, service, url, apiKey string, body, out any) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return &StatusError{Service: service, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// embedInChunks embeds texts in requests of at most size texts each
+func embedInChunks(texts []string, size int, embed func([]string) ([][]float64, error)) ([][]float64, error) {
+	vectors := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += size {
+		chunk, err := embed(texts[start:min(start+size, len(texts))])
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, chunk...)
+	}
+	return vectors, nil
+}
@@ -0,0 +1,188 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProvider(t *testing.T) {
+	service := NewClient("http://localhost:8001")
+
+	provider, err := NewProvider(&config.EmbeddingConfig{}, service)
+	require.NoError(t, err)
+	assert.Same(t, service, provider)
+
+	provider, err = NewProvider(&config.EmbeddingConfig{Provider: "openai", APIKey: "key"}, service)
+	require.NoError(t, err)
+	assert.Equal(t, "text-embedding-3-small", provider.Model())
+
+	provider, err = NewProvider(&config.EmbeddingConfig{Provider: "cohere", APIKey: "key"}, service)
+	require.NoError(t, err)
+	assert.Equal(t, "embed-multilingual-light-v3.0", provider.Model())
+
+	// Compatible servers may run without a key and with models of their own
+	_, err = NewProvider(&config.EmbeddingConfig{Provider: "openai", APIURL: "http://localhost:11434/v1", Model: "all-minilm"}, service)
+	assert.NoError(t, err)
+
+	for _, cfg := range []config.EmbeddingConfig{
+		{Provider: "word2vec"},
+		{Provider: "openai"},
+		{Provider: "openai", APIKey: "key", Model: "text-embedding-ada-002"},
+		{Provider: "cohere"},
+		{Provider: "cohere", APIKey: "key", Model: "embed-english-v3.0"},
+	} {
+		_, err := NewProvider(&cfg, service)
+		assert.Error(t, err, cfg)
+	}
+}
+
+func TestOpenAIProvider(t *testing.T) {
+	var request openAIEmbedRequest
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+		authorization = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		// Answers may come out of order
+		_, _ = w.Write([]byte(`{"data": [{"index": 1, "embedding": [0.2]}, {"index": 0, "embedding": [0.1]}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(&config.EmbeddingConfig{Provider: "openai", APIKey: "secret", APIURL: server.URL + "/v1/"}, nil)
+	require.NoError(t, err)
+
+	vectors, err := provider.Embed(context.Background(), []string{"first", "second"}, PurposeDocument)
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{0.1}, {0.2}}, vectors)
+	assert.Equal(t, "Bearer secret", authorization)
+	assert.Equal(t, []string{"first", "second"}, request.Input)
+	// The model shortens its vectors to the schema's size
+	assert.Equal(t, Dimension, request.Dimensions)
+}
+
+func TestCohereProvider(t *testing.T) {
+	var request cohereEmbedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		_, _ = w.Write([]byte(`{"embeddings": {"float": [[0.1]]}}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(&config.EmbeddingConfig{Provider: "cohere", APIKey: "secret", APIURL: server.URL}, nil)
+	require.NoError(t, err)
+
+	vectors, err := provider.Embed(context.Background(), []string{"golang"}, PurposeQuery)
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{0.1}}, vectors)
+	assert.Equal(t, "search_query", request.InputType)
+
+	provider, err = NewProvider(&config.EmbeddingConfig{Provider: "cohere", APIKey: "wrong", APIURL: server.URL}, nil)
+	require.NoError(t, err)
+	_, err = provider.Embed(context.Background(), []string{"golang"}, PurposeQuery)
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusUnauthorized, statusErr.StatusCode)
+}
+
+// fakeProvider returns vectors of the given dimension
+type fakeProvider struct {
+	dimension int
+	texts     []string
+}
+
+func (f *fakeProvider) Name() string  { return "fake" }
+func (f *fakeProvider) Model() string { return "fake-model" }
+
+func (f *fakeProvider) Embed(ctx context.Context, texts []string, purpose Purpose) ([][]float64, error) {
+	f.texts = append(f.texts, texts...)
+	vectors := make([][]float64, len(texts))
+	for i := range texts {
+		vectors[i] = make([]float64, f.dimension)
+	}
+	return vectors, nil
+}
+
+// fakeVectorStore keeps stored embeddings by "<article ID> <target>"
+type fakeVectorStore map[string][]float64
+
+func (f fakeVectorStore) StoreEmbedding(articleID uuid.UUID, target string, vector []float64) error {
+	f[articleID.String()+" "+target] = vector
+	return nil
+}
+
+func (f fakeVectorStore) LoadEmbedding(articleID uuid.UUID, target string) ([]float64, error) {
+	vector, ok := f[articleID.String()+" "+target]
+	if !ok {
+		return nil, ErrNoEmbedding
+	}
+	return vector, nil
+}
+
+func TestProviderClient(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	t.Run("The service is its own provider", func(t *testing.T) {
+		service := NewClient("http://localhost:8001")
+		assert.Same(t, service, NewProviderClient(service, service, nil, log))
+	})
+
+	t.Run("Stores embeddings of valid texts", func(t *testing.T) {
+		provider := &fakeProvider{dimension: Dimension}
+		store := fakeVectorStore{}
+		client := NewProviderClient(provider, NewClient("http://localhost:8001"), store, log)
+
+		articleID := uuid.New()
+		response, err := client.StoreArticleEmbeddings(context.Background(), []ArticleText{
+			{ID: articleID.String(), Text: "Title", Target: TargetTitle},
+			{ID: "not-a-uuid", Text: "Title"},
+			{ID: articleID.String(), Text: "  ", Target: TargetContent},
+			{ID: articleID.String(), Text: "Body", Target: TargetContent},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, response.SuccessfulUpdates)
+		require.Len(t, response.Results, 4)
+		assert.Equal(t, []string{"success", "error", "error", "success"},
+			[]string{response.Results[0].Status, response.Results[1].Status, response.Results[2].Status, response.Results[3].Status})
+		assert.Equal(t, []string{"Title", "Body"}, provider.texts)
+		assert.Len(t, store[articleID.String()+" "+TargetContent], Dimension)
+	})
+
+	t.Run("Rejects vectors the schema cannot store", func(t *testing.T) {
+		client := NewProviderClient(&fakeProvider{dimension: 1536}, NewClient("http://localhost:8001"), fakeVectorStore{}, log)
+
+		_, err := client.GetEmbedding(context.Background(), "golang")
+		assert.ErrorIs(t, err, ErrDimensionMismatch)
+		assert.False(t, transient(err))
+	})
+
+	t.Run("Reports the provider in health checks", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(HealthResponse{Status: "healthy", EmbeddingModel: "all-MiniLM-L6-v2", EmbeddingModelLoaded: true, EmbeddingDimension: Dimension})
+		}))
+		defer server.Close()
+
+		client := NewProviderClient(&fakeProvider{dimension: Dimension}, NewClient(server.URL), fakeVectorStore{}, log)
+		_, err := client.GetEmbedding(context.Background(), "golang")
+		require.NoError(t, err)
+
+		status := NewHealthMonitor(client, 0).Status()
+		assert.True(t, status.Healthy)
+		assert.Equal(t, "fake", status.Provider)
+		assert.Equal(t, "fake-model", status.EmbeddingModel)
+		assert.Equal(t, Dimension, status.Dimension)
+	})
+}
@@ -0,0 +1,296 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+)
+
+// ErrCircuitOpen is returned without calling the service while the circuit
+// breaker is open
+var ErrCircuitOpen = errors.New("embedding service circuit breaker is open")
+
+// BreakerState is the state of the circuit breaker
+type BreakerState string
+
+// Circuit breaker states
+const (
+	BreakerClosed   BreakerState = "closed"    // Calls go through
+	BreakerOpen     BreakerState = "open"      // Calls fail fast until the cooldown is over
+	BreakerHalfOpen BreakerState = "half_open" // A single trial call decides whether to close again
+)
+
+// BreakerStatus describes the circuit breaker
+type BreakerStatus struct {
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+	RetryAt             *time.Time   `json:"retry_at,omitempty"` // When the next trial call is let through
+}
+
+// ResilientClient decorates an embedding client with retries and a circuit
+// breaker. Calls failing transiently, i.e. on connection errors, timeouts,
+// 5xx or 429 answers, are retried with jittered exponential backoff. After
+// enough consecutive failed requests the breaker opens and calls fail fast
+// with ErrCircuitOpen until the cooldown is over.
+type ResilientClient struct {
+	inner      EmbeddingClient
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	threshold  int // 0 disables the breaker
+	cooldown   time.Duration
+	now        func() time.Time
+	logger     *logger.Logger
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	trial    bool // A half-open trial call is in flight
+}
+
+// NewResilientClient creates a resilient client with validation and defaults
+func NewResilientClient(inner EmbeddingClient, cfg *config.EmbeddingConfig, log *logger.Logger) (*ResilientClient, error) {
+	client := &ResilientClient{
+		inner:      inner,
+		maxRetries: 2,
+		baseDelay:  200 * time.Millisecond,
+		maxDelay:   2 * time.Second,
+		threshold:  5,
+		cooldown:   30 * time.Second,
+		now:        time.Now,
+		logger:     log.WithComponent("embedding-client"),
+		state:      BreakerClosed,
+	}
+
+	if cfg.MaxRetries != "" {
+		retries, err := strconv.Atoi(cfg.MaxRetries)
+		if err != nil || retries < 0 {
+			return nil, fmt.Errorf("invalid embedding max retries '%s': must be a non-negative integer", cfg.MaxRetries)
+		}
+		client.maxRetries = retries
+	}
+
+	if cfg.RetryBaseDelay != "" {
+		delay, err := time.ParseDuration(cfg.RetryBaseDelay)
+		if err != nil || delay <= 0 {
+			return nil, fmt.Errorf("invalid embedding retry base delay '%s': must be a positive duration", cfg.RetryBaseDelay)
+		}
+		client.baseDelay = delay
+	}
+
+	if cfg.RetryMaxDelay != "" {
+		delay, err := time.ParseDuration(cfg.RetryMaxDelay)
+		if err != nil || delay <= 0 {
+			return nil, fmt.Errorf("invalid embedding retry max delay '%s': must be a positive duration", cfg.RetryMaxDelay)
+		}
+		client.maxDelay = delay
+	}
+	if client.maxDelay < client.baseDelay {
+		return nil, fmt.Errorf("embedding retry max delay %s must not be less than the base delay %s", client.maxDelay, client.baseDelay)
+	}
+
+	if cfg.BreakerThreshold != "" {
+		threshold, err := strconv.Atoi(cfg.BreakerThreshold)
+		if err != nil || threshold < 0 {
+			return nil, fmt.Errorf("invalid embedding breaker threshold '%s': must be a non-negative integer", cfg.BreakerThreshold)
+		}
+		client.threshold = threshold
+	}
+
+	if cfg.BreakerCooldown != "" {
+		cooldown, err := time.ParseDuration(cfg.BreakerCooldown)
+		if err != nil || cooldown <= 0 {
+			return nil, fmt.Errorf("invalid embedding breaker cooldown '%s': must be a positive duration", cfg.BreakerCooldown)
+		}
+		client.cooldown = cooldown
+	}
+
+	return client, nil
+}
+
+// Breaker returns the current state of the circuit breaker
+func (c *ResilientClient) Breaker() BreakerStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := BreakerStatus{State: c.state, ConsecutiveFailures: c.failures}
+	if c.state != BreakerClosed {
+		openedAt := c.openedAt
+		retryAt := openedAt.Add(c.cooldown)
+		status.OpenedAt = &openedAt
+		status.RetryAt = &retryAt
+	}
+	return status
+}
+
+// allow reports whether a request may be sent now, and whether it is the
+// trial call of a half-open breaker
+func (c *ResilientClient) allow() (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case BreakerOpen:
+		if c.now().Before(c.openedAt.Add(c.cooldown)) {
+			return false, ErrCircuitOpen
+		}
+		c.state = BreakerHalfOpen
+		c.logger.Info("Embedding service circuit breaker half-open, sending a trial call")
+	case BreakerHalfOpen:
+		if c.trial {
+			return false, ErrCircuitOpen
+		}
+	default:
+		return false, nil
+	}
+	c.trial = true
+	return true, nil
+}
+
+// record updates the breaker with the outcome of a request. Requests abandoned
+// because their context is done say nothing about the service.
+func (c *ResilientClient) record(ctx context.Context, trial bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if trial {
+		c.trial = false
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	if !transient(err) {
+		if c.state != BreakerClosed {
+			c.logger.Info("Embedding service circuit breaker closed")
+		}
+		c.state = BreakerClosed
+		c.failures = 0
+		return
+	}
+
+	c.failures++
+	if c.threshold > 0 && (c.state == BreakerHalfOpen || (c.state == BreakerClosed && c.failures >= c.threshold)) {
+		c.state = BreakerOpen
+		c.openedAt = c.now()
+		c.logger.Warn(fmt.Sprintf("Embedding service circuit breaker open for %s after %d consecutive failures: %s", c.cooldown, c.failures, err))
+	}
+}
+
+// transient reports whether err may go away when the request is tried
+// again. Invalid input, client errors and vectors of the wrong size are
+// answers, not failures.
+func transient(err error) bool {
+	if err == nil || errors.Is(err, ErrInvalidInput) || errors.Is(err, ErrDimensionMismatch) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Temporary()
+	}
+	return true
+}
+
+// backoff returns the jittered wait before the given retry, counted from 0
+func (c *ResilientClient) backoff(retry int) time.Duration {
+	delay := c.maxDelay
+	if retry < 30 {
+		delay = min(c.baseDelay<<retry, c.maxDelay)
+	}
+	// Half fixed, half random, so clients that failed together do not retry together
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// call runs a request through the breaker, retrying transient failures
+func call[T any](c *ResilientClient, ctx context.Context, request func(context.Context) (T, error)) (T, error) {
+	var zero T
+	var lastErr error
+	for retry := 0; ; retry++ {
+		trial, err := c.allow()
+		if err != nil {
+			// A retry cut short by the breaker reports why it was needed
+			if lastErr != nil {
+				return zero, lastErr
+			}
+			return zero, err
+		}
+
+		result, err := request(ctx)
+		c.record(ctx, trial, err)
+		if err == nil {
+			return result, nil
+		}
+		if ctx.Err() != nil || !transient(err) || retry >= c.maxRetries {
+			return zero, err
+		}
+		lastErr = err
+
+		delay := c.backoff(retry)
+		c.logger.Debug(fmt.Sprintf("Embedding service call failed, retrying in %s: %s", delay, err))
+		if err := sleep(ctx, delay); err != nil {
+			return zero, err
+		}
+	}
+}
+
+// sleep waits for d unless ctx is done first
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (c *ResilientClient) GetEmbedding(ctx context.Context, text string) ([]float64, error) {
+	return call(c, ctx, func(ctx context.Context) ([]float64, error) {
+		return c.inner.GetEmbedding(ctx, text)
+	})
+}
+
+func (c *ResilientClient) GetBatchEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	return call(c, ctx, func(ctx context.Context) ([][]float64, error) {
+		return c.inner.GetBatchEmbeddings(ctx, texts)
+	})
+}
+
+func (c *ResilientClient) CalculateSimilarity(ctx context.Context, embedding1, embedding2 []float64) (float64, error) {
+	return call(c, ctx, func(ctx context.Context) (float64, error) {
+		return c.inner.CalculateSimilarity(ctx, embedding1, embedding2)
+	})
+}
+
+func (c *ResilientClient) HealthCheck(ctx context.Context) (*HealthResponse, error) {
+	return call(c, ctx, c.inner.HealthCheck)
+}
+
+func (c *ResilientClient) ClassifyContent(ctx context.Context, text string) (*ClassifyResponse, error) {
+	return call(c, ctx, func(ctx context.Context) (*ClassifyResponse, error) {
+		return c.inner.ClassifyContent(ctx, text)
+	})
+}
+
+func (c *ResilientClient) ClassifyBatchContent(ctx context.Context, texts []string) (*BatchClassifyResponse, error) {
+	return call(c, ctx, func(ctx context.Context) (*BatchClassifyResponse, error) {
+		return c.inner.ClassifyBatchContent(ctx, texts)
+	})
+}
+
+func (c *ResilientClient) StoreArticleEmbeddings(ctx context.Context, articles []ArticleText) (*BatchStoreResponse, error) {
+	return call(c, ctx, func(ctx context.Context) (*BatchStoreResponse, error) {
+		return c.inner.StoreArticleEmbeddings(ctx, articles)
+	})
+}
@@ -0,0 +1,163 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyClient fails embedding calls with the queued errors, then succeeds
+type flakyClient struct {
+	EmbeddingClient
+	errs  []error
+	calls int
+}
+
+func (f *flakyClient) GetEmbedding(ctx context.Context, text string) ([]float64, error) {
+	f.calls++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return nil, err
+	}
+	return []float64{0.1}, nil
+}
+
+func newTestResilientClient(t *testing.T, inner EmbeddingClient, cfg config.EmbeddingConfig) *ResilientClient {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+	if cfg.RetryBaseDelay == "" {
+		cfg.RetryBaseDelay = "1ms"
+		cfg.RetryMaxDelay = "2ms"
+	}
+	client, err := NewResilientClient(inner, &cfg, log)
+	require.NoError(t, err)
+	return client
+}
+
+func TestResilientClient(t *testing.T) {
+	unavailable := &StatusError{Service: "embedding", StatusCode: http.StatusServiceUnavailable}
+
+	t.Run("Retries transient failures", func(t *testing.T) {
+		inner := &flakyClient{errs: []error{errors.New("connection refused"), unavailable}}
+		client := newTestResilientClient(t, inner, config.EmbeddingConfig{MaxRetries: "2"})
+
+		embedding, err := client.GetEmbedding(context.Background(), "hello")
+		require.NoError(t, err)
+		assert.Equal(t, []float64{0.1}, embedding)
+		assert.Equal(t, 3, inner.calls)
+		assert.Equal(t, 0, client.Breaker().ConsecutiveFailures)
+	})
+
+	t.Run("Gives up after the last retry", func(t *testing.T) {
+		inner := &flakyClient{errs: []error{unavailable, unavailable, unavailable}}
+		client := newTestResilientClient(t, inner, config.EmbeddingConfig{MaxRetries: "1"})
+
+		_, err := client.GetEmbedding(context.Background(), "hello")
+		assert.ErrorIs(t, err, unavailable)
+		assert.Equal(t, 2, inner.calls)
+	})
+
+	t.Run("Does not retry answers", func(t *testing.T) {
+		for _, answer := range []error{
+			&StatusError{Service: "embedding", StatusCode: http.StatusBadRequest},
+			ErrInvalidInput,
+		} {
+			inner := &flakyClient{errs: []error{answer}}
+			client := newTestResilientClient(t, inner, config.EmbeddingConfig{})
+
+			_, err := client.GetEmbedding(context.Background(), "hello")
+			assert.ErrorIs(t, err, answer)
+			assert.Equal(t, 1, inner.calls)
+			assert.Equal(t, BreakerClosed, client.Breaker().State)
+		}
+	})
+
+	t.Run("Stops waiting once the context is done", func(t *testing.T) {
+		inner := &flakyClient{errs: []error{unavailable, unavailable}}
+		client := newTestResilientClient(t, inner, config.EmbeddingConfig{RetryBaseDelay: "1h", RetryMaxDelay: "1h"})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := client.GetEmbedding(ctx, "hello")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 1, inner.calls)
+	})
+
+	t.Run("Opens after consecutive failures and closes after a trial call", func(t *testing.T) {
+		now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		inner := &flakyClient{errs: []error{unavailable, unavailable, unavailable, unavailable}}
+		client := newTestResilientClient(t, inner, config.EmbeddingConfig{MaxRetries: "0", BreakerThreshold: "2", BreakerCooldown: "30s"})
+		client.now = func() time.Time { return now }
+
+		for i := 0; i < 2; i++ {
+			_, err := client.GetEmbedding(context.Background(), "hello")
+			assert.ErrorIs(t, err, unavailable)
+		}
+		breaker := client.Breaker()
+		assert.Equal(t, BreakerOpen, breaker.State)
+		assert.Equal(t, now.Add(30*time.Second), *breaker.RetryAt)
+
+		// Calls fail fast while open
+		_, err := client.GetEmbedding(context.Background(), "hello")
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.Equal(t, 2, inner.calls)
+
+		// A failed trial call opens the breaker again
+		now = now.Add(30 * time.Second)
+		_, err = client.GetEmbedding(context.Background(), "hello")
+		assert.ErrorIs(t, err, unavailable)
+		assert.Equal(t, BreakerOpen, client.Breaker().State)
+
+		// A successful one closes it
+		inner.errs = nil
+		now = now.Add(30 * time.Second)
+		_, err = client.GetEmbedding(context.Background(), "hello")
+		require.NoError(t, err)
+		assert.Equal(t, BreakerStatus{State: BreakerClosed}, client.Breaker())
+	})
+
+	t.Run("Reports the breaker in health", func(t *testing.T) {
+		inner := &flakyClient{errs: []error{unavailable}}
+		client := newTestResilientClient(t, inner, config.EmbeddingConfig{MaxRetries: "0", BreakerThreshold: "1"})
+		monitor := NewHealthMonitor(client, time.Minute)
+		monitor.client = &fakeHealthClient{response: &HealthResponse{Status: "healthy", EmbeddingModelLoaded: true}}
+
+		status := monitor.Status()
+		assert.True(t, status.Healthy)
+		assert.Equal(t, BreakerClosed, status.CircuitBreaker.State)
+
+		_, err := client.GetEmbedding(context.Background(), "hello")
+		assert.Error(t, err)
+
+		// The breaker is current even while the check is cached
+		status = monitor.Status()
+		assert.False(t, status.Healthy)
+		assert.Equal(t, BreakerOpen, status.CircuitBreaker.State)
+		assert.Equal(t, ErrCircuitOpen.Error(), status.Error)
+	})
+
+	t.Run("Rejects invalid settings", func(t *testing.T) {
+		log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+		require.NoError(t, err)
+
+		for _, cfg := range []config.EmbeddingConfig{
+			{MaxRetries: "-1"},
+			{RetryBaseDelay: "soon"},
+			{RetryBaseDelay: "5s", RetryMaxDelay: "1s"},
+			{BreakerThreshold: "many"},
+			{BreakerCooldown: "0s"},
+		} {
+			_, err := NewResilientClient(&flakyClient{}, &cfg, log)
+			assert.Error(t, err, cfg)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package export
+
+import (
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Errors returned by the export service and repository
+var (
+	ErrJobNotFound     = utils.NewNotFoundError("export not found")
+	ErrArchiveNotReady = utils.NewNotFoundError("export archive not ready")
+)
+
+// Job status constants
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+)
+
+// Job tracks an asynchronous export of a user's data. The archive is kept
+// until ExpiresAt.
+type Job struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Status      string     `json:"status" gorm:"size:20;not null"`
+	Size        int        `json:"size_bytes"` // Of the archive, once completed
+	Error       string     `json:"error,omitempty" gorm:"type:text"`
+	Archive     []byte     `json:"-" gorm:"type:bytea"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" gorm:"index"`
+}
+
+// TableName returns the table name for GORM
+func (Job) TableName() string {
+	return "export_jobs"
+}
+
+// Archive is the downloadable copy of a user's data
+type Archive struct {
+	ExportedAt time.Time  `json:"exported_at"`
+	Profile    *Profile   `json:"profile"`
+	Articles   []*Article `json:"articles"`
+	Ratings    []*Rating  `json:"ratings"`
+	Events     []*Event   `json:"recommendation_events"`
+}
+
+// Profile is the user's account
+type Profile struct {
+	ID        uuid.UUID `json:"id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Article is a saved article, including those in the trash
+type Article struct {
+	ID          uuid.UUID  `json:"id"`
+	URL         string     `json:"url"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Content     string     `json:"content"`
+	ContentKey  string     `json:"-"` // Set while Content lives in object storage
+	ImageURL    string     `json:"image_url"`
+	Language    string     `json:"language"`
+	Tags        []string   `json:"tags"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"` // Set for articles in the trash
+}
+
+// Rating is the user's rating of an article
+type Rating struct {
+	ArticleID uuid.UUID `json:"article_id"`
+	Score     int       `json:"score"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Event is an impression or click the user's client reported on a
+// recommended article
+type Event struct {
+	ArticleID uuid.UUID `json:"article_id"`
+	Engine    string    `json:"engine"`
+	Type      string    `json:"type"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Repository defines the interface for export jobs and the data they export
+type Repository interface {
+	CreateJob(job *Job) error
+	// UpdateJob saves the job with its archive
+	UpdateJob(job *Job) error
+	// FindLatestJob returns the user's newest job, without its archive
+	FindLatestJob(userID uuid.UUID) (*Job, error)
+	FindArchive(jobID uuid.UUID) ([]byte, error)
+	// DeleteJobs deletes the user's jobs other than keep
+	DeleteJobs(userID uuid.UUID, keep uuid.UUID) error
+	// DeleteExpiredJobs deletes jobs whose archive expired before the time,
+	// returning how many
+	DeleteExpiredJobs(before time.Time) (int64, error)
+
+	FindProfile(userID uuid.UUID) (*Profile, error)
+	FindArticles(userID uuid.UUID) ([]*Article, error)
+	FindRatings(userID uuid.UUID) ([]*Rating, error)
+	FindEvents(userID uuid.UUID) ([]*Event, error)
+}
+
+// Service defines the interface for export business logic
+type Service interface {
+	// StartExport queues an export of the user's data, or returns the one
+	// already in progress
+	StartExport(userID uuid.UUID) (*Job, error)
+	// GetLatestJob returns the status of the user's newest export
+	GetLatestJob(userID uuid.UUID) (*Job, error)
+	// GetArchive returns the archive of the user's newest completed export
+	GetArchive(userID uuid.UUID) (*Job, []byte, error)
+	PurgeExpired() error
+}
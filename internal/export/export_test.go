@@ -0,0 +1,235 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/dustin/articles-backend/pkg/storage"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+	return log
+}
+
+// mockRepository keeps jobs in memory and serves one user's data
+type mockRepository struct {
+	mu       sync.Mutex
+	jobs     map[uuid.UUID]Job
+	profile  *Profile
+	articles []*Article
+	err      error // Returned when reading data
+}
+
+func newMockRepository(userID uuid.UUID) *mockRepository {
+	return &mockRepository{
+		jobs:    make(map[uuid.UUID]Job),
+		profile: &Profile{ID: userID, Email: "reader@example.com"},
+		articles: []*Article{
+			{ID: uuid.New(), URL: "https://example.com/go", Title: "Learning Go", Content: "Go is simple.", Tags: []string{"golang"}},
+			{ID: uuid.New(), URL: "https://example.com/stored", Title: "Stored", ContentKey: "content/stored.txt", Tags: []string{}},
+		},
+	}
+}
+
+func (m *mockRepository) CreateJob(job *Job) error {
+	return m.UpdateJob(job)
+}
+
+func (m *mockRepository) UpdateJob(job *Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[job.ID] = *job
+	return nil
+}
+
+func (m *mockRepository) FindLatestJob(userID uuid.UUID) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var latest *Job
+	for _, job := range m.jobs {
+		if job.UserID == userID && (latest == nil || job.CreatedAt.After(latest.CreatedAt)) {
+			found := job
+			latest = &found
+		}
+	}
+	if latest == nil {
+		return nil, ErrJobNotFound
+	}
+	latest.Archive = nil
+	return latest, nil
+}
+
+func (m *mockRepository) FindArchive(jobID uuid.UUID) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[jobID]
+	if !ok || job.Archive == nil {
+		return nil, ErrArchiveNotReady
+	}
+	return job.Archive, nil
+}
+
+func (m *mockRepository) DeleteJobs(userID uuid.UUID, keep uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, job := range m.jobs {
+		if job.UserID == userID && id != keep {
+			delete(m.jobs, id)
+		}
+	}
+	return nil
+}
+
+func (m *mockRepository) DeleteExpiredJobs(before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deleted int64
+	for id, job := range m.jobs {
+		if job.ExpiresAt != nil && job.ExpiresAt.Before(before) {
+			delete(m.jobs, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (m *mockRepository) FindProfile(userID uuid.UUID) (*Profile, error) {
+	return m.profile, m.err
+}
+
+func (m *mockRepository) FindArticles(userID uuid.UUID) ([]*Article, error) {
+	return m.articles, m.err
+}
+
+func (m *mockRepository) FindRatings(userID uuid.UUID) ([]*Rating, error) {
+	return []*Rating{{ArticleID: m.articles[0].ID, Score: 5}}, m.err
+}
+
+func (m *mockRepository) FindEvents(userID uuid.UUID) ([]*Event, error) {
+	return []*Event{{ArticleID: m.articles[0].ID, Engine: "content", Type: "click"}}, m.err
+}
+
+// memoryObjects serves article content kept in object storage
+type memoryObjects struct {
+	storage.Storage
+	objects map[string]string
+}
+
+func (m *memoryObjects) Get(key string) (*storage.Object, error) {
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &storage.Object{Data: []byte(data), ContentType: "text/plain"}, nil
+}
+
+func TestExport(t *testing.T) {
+	log := newTestLogger(t)
+	userID := uuid.New()
+	objects := &memoryObjects{objects: map[string]string{"content/stored.txt": "Kept in object storage."}}
+
+	t.Run("Archives the user's data", func(t *testing.T) {
+		repo := newMockRepository(userID)
+		svc, err := NewService(nil, repo, objects, log)
+		require.NoError(t, err)
+
+		job := &Job{ID: uuid.New(), UserID: userID, Status: JobStatusQueued, CreatedAt: time.Now()}
+		require.NoError(t, repo.CreateJob(job))
+		svc.(*service).run(job)
+
+		assert.Equal(t, JobStatusCompleted, job.Status)
+		require.NotNil(t, job.ExpiresAt)
+		assert.WithinDuration(t, time.Now().Add(defaultRetention), *job.ExpiresAt, time.Minute)
+
+		saved, data, err := svc.GetArchive(userID)
+		require.NoError(t, err)
+		assert.Equal(t, len(data), saved.Size)
+
+		var archive Archive
+		require.NoError(t, json.Unmarshal(data, &archive))
+		assert.Equal(t, "reader@example.com", archive.Profile.Email)
+		require.Len(t, archive.Articles, 2)
+		assert.Equal(t, "Go is simple.", archive.Articles[0].Content)
+		assert.Equal(t, "Kept in object storage.", archive.Articles[1].Content)
+		assert.Len(t, archive.Ratings, 1)
+		assert.Len(t, archive.Events, 1)
+	})
+
+	t.Run("Marks job failed when reading fails", func(t *testing.T) {
+		repo := newMockRepository(userID)
+		repo.err = errors.New("database down")
+		svc, err := NewService(nil, repo, objects, log)
+		require.NoError(t, err)
+
+		job := &Job{ID: uuid.New(), UserID: userID, Status: JobStatusQueued, CreatedAt: time.Now()}
+		require.NoError(t, repo.CreateJob(job))
+		svc.(*service).run(job)
+
+		assert.Equal(t, JobStatusFailed, job.Status)
+		assert.Equal(t, "database down", job.Error)
+		_, _, err = svc.GetArchive(userID)
+		assert.ErrorIs(t, err, ErrArchiveNotReady)
+	})
+
+	t.Run("Exports run one at a time", func(t *testing.T) {
+		repo := newMockRepository(userID)
+		svc, err := NewService(nil, repo, objects, log)
+		require.NoError(t, err)
+
+		running := &Job{ID: uuid.New(), UserID: userID, Status: JobStatusRunning, CreatedAt: time.Now()}
+		require.NoError(t, repo.CreateJob(running))
+		job, err := svc.StartExport(userID)
+		require.NoError(t, err)
+		assert.Equal(t, running.ID, job.ID, "the export in progress is returned")
+
+		// Exports interrupted by a restart never finish
+		running.CreatedAt = time.Now().Add(-2 * staleJobAfter)
+		require.NoError(t, repo.UpdateJob(running))
+		job, err = svc.StartExport(userID)
+		require.NoError(t, err)
+		assert.NotEqual(t, running.ID, job.ID)
+
+		require.Eventually(t, func() bool {
+			latest, err := svc.GetLatestJob(userID)
+			return err == nil && latest.Status == JobStatusCompleted
+		}, time.Second, 10*time.Millisecond)
+		repo.mu.Lock()
+		assert.Len(t, repo.jobs, 1, "earlier exports are deleted")
+		repo.mu.Unlock()
+	})
+
+	t.Run("Expired archives cannot be downloaded", func(t *testing.T) {
+		repo := newMockRepository(userID)
+		svc, err := NewService(&config.ExportConfig{Retention: "1h"}, repo, objects, log)
+		require.NoError(t, err)
+
+		job := &Job{ID: uuid.New(), UserID: userID, Status: JobStatusQueued, CreatedAt: time.Now()}
+		require.NoError(t, repo.CreateJob(job))
+		svc.(*service).run(job)
+
+		expired := time.Now().Add(-time.Minute)
+		job.ExpiresAt = &expired
+		require.NoError(t, repo.UpdateJob(job))
+		_, _, err = svc.GetArchive(userID)
+		assert.ErrorIs(t, err, ErrArchiveNotReady)
+
+		require.NoError(t, svc.PurgeExpired())
+		_, err = svc.GetLatestJob(userID)
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+
+	t.Run("Invalid retention", func(t *testing.T) {
+		_, err := NewService(&config.ExportConfig{Retention: "0s"}, newMockRepository(userID), nil, log)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,85 @@
+package export
+
+import (
+	"net/http"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles HTTP requests for data exports
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new export handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// StartExport handles queuing an export of the current user's data
+func (h *Handler) StartExport(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	job, err := h.service.StartExport(userID)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to start export")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetExport handles polling the status of the current user's newest export
+func (h *Handler) GetExport(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	job, err := h.service.GetLatestJob(userID)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to get export")
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// DownloadExport handles downloading the archive of the current user's
+// newest export
+func (h *Handler) DownloadExport(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	job, archive, err := h.service.GetArchive(userID)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to download export")
+		return
+	}
+
+	filename := "articles-export-" + job.CompletedAt.UTC().Format("2006-01-02") + ".json"
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "application/json", archive)
+}
+
+// RegisterRoutes registers all export routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	exports := router.Group("/users/me/export")
+	exports.Use(authMiddleware)
+	{
+		exports.POST("", h.StartExport)
+		exports.GET("", h.GetExport)
+		exports.GET("/download", h.DownloadExport)
+	}
+}
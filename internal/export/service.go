@@ -0,0 +1,197 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/dustin/articles-backend/pkg/storage"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultRetention = 7 * 24 * time.Hour
+	// staleJobAfter is how long an export may run before another can start;
+	// exports running when the server stopped never finish
+	staleJobAfter = time.Hour
+)
+
+// service implements the Service interface
+type service struct {
+	repo      Repository
+	objects   storage.Storage // Nil when object storage is disabled
+	retention time.Duration
+	logger    *logger.Logger
+}
+
+// NewService creates an export service with validation and defaults.
+// objects holds article content kept in object storage, and may be nil.
+func NewService(cfg *config.ExportConfig, repo Repository, objects storage.Storage, log *logger.Logger) (Service, error) {
+	retention := defaultRetention
+	if cfg != nil && cfg.Retention != "" {
+		duration, err := time.ParseDuration(cfg.Retention)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid export retention '%s': must be a positive duration", cfg.Retention)
+		}
+		retention = duration
+	}
+
+	return &service{
+		repo:      repo,
+		objects:   objects,
+		retention: retention,
+		logger:    log.WithComponent("export-service"),
+	}, nil
+}
+
+func (s *service) StartExport(userID uuid.UUID) (*Job, error) {
+	latest, err := s.repo.FindLatestJob(userID)
+	if err != nil && !errors.Is(err, ErrJobNotFound) {
+		return nil, err
+	}
+	if latest != nil && (latest.Status == JobStatusQueued || latest.Status == JobStatusRunning) && time.Since(latest.CreatedAt) < staleJobAfter {
+		return latest, nil
+	}
+
+	s.logger.Info("Starting data export for user " + userID.String())
+
+	job := &Job{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Status:    JobStatusQueued,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.repo.CreateJob(job); err != nil {
+		s.logger.Error("Failed to create export job for user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	// A user keeps only their newest export
+	if err := s.repo.DeleteJobs(userID, job.ID); err != nil {
+		s.logger.Error("Failed to delete earlier exports of user " + userID.String() + ": " + err.Error())
+	}
+
+	// Run the export in the background; clients poll the job for progress.
+	// The caller gets a snapshot since the running export keeps mutating job.
+	snapshot := *job
+	go s.run(job)
+
+	return &snapshot, nil
+}
+
+func (s *service) GetLatestJob(userID uuid.UUID) (*Job, error) {
+	return s.repo.FindLatestJob(userID)
+}
+
+func (s *service) GetArchive(userID uuid.UUID) (*Job, []byte, error) {
+	job, err := s.repo.FindLatestJob(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != JobStatusCompleted || (job.ExpiresAt != nil && !time.Now().Before(*job.ExpiresAt)) {
+		return nil, nil, ErrArchiveNotReady
+	}
+
+	archive, err := s.repo.FindArchive(job.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return job, archive, nil
+}
+
+// PurgeExpired deletes exports whose archive has expired
+func (s *service) PurgeExpired() error {
+	purged, err := s.repo.DeleteExpiredJobs(time.Now())
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		s.logger.Info("Purged expired exports: " + strconv.FormatInt(purged, 10))
+	}
+	return nil
+}
+
+// run builds the archive and stores it with the job
+func (s *service) run(job *Job) {
+	job.Status = JobStatusRunning
+	s.saveJob(job)
+
+	archive, err := s.buildArchive(job.UserID)
+	if err == nil {
+		job.Archive, err = json.MarshalIndent(archive, "", "  ")
+	}
+	if err != nil {
+		s.logger.Error("Export job " + job.ID.String() + " failed: " + err.Error())
+		s.finishJob(job, JobStatusFailed, err.Error())
+		return
+	}
+
+	job.Size = len(job.Archive)
+	expiresAt := time.Now().Add(s.retention)
+	job.ExpiresAt = &expiresAt
+
+	s.logger.Info("Export job " + job.ID.String() + " completed: " + strconv.Itoa(len(archive.Articles)) + " articles, " + strconv.Itoa(job.Size) + " bytes")
+	s.finishJob(job, JobStatusCompleted, "")
+}
+
+// buildArchive collects the user's data
+func (s *service) buildArchive(userID uuid.UUID) (*Archive, error) {
+	profile, err := s.repo.FindProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+	articles, err := s.repo.FindArticles(userID)
+	if err != nil {
+		return nil, err
+	}
+	ratings, err := s.repo.FindRatings(userID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.repo.FindEvents(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Content moved to object storage is read back from there
+	for _, article := range articles {
+		if article.ContentKey == "" || s.objects == nil {
+			continue
+		}
+		object, err := s.objects.Get(article.ContentKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read content of article %s: %w", article.ID, err)
+		}
+		article.Content = string(object.Data)
+	}
+
+	return &Archive{
+		ExportedAt: time.Now(),
+		Profile:    profile,
+		Articles:   articles,
+		Ratings:    ratings,
+		Events:     events,
+	}, nil
+}
+
+func (s *service) finishJob(job *Job, status string, message string) {
+	now := time.Now()
+	job.Status = status
+	job.Error = message
+	job.CompletedAt = &now
+	s.saveJob(job)
+}
+
+func (s *service) saveJob(job *Job) {
+	job.UpdatedAt = time.Now()
+	if err := s.repo.UpdateJob(job); err != nil {
+		s.logger.Error("Failed to update export job " + job.ID.String() + ": " + err.Error())
+	}
+}
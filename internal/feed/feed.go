@@ -0,0 +1,148 @@
+package feed
+
+import (
+	"context"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Errors returned by the feed service and repository
+var (
+	ErrNotFound      = utils.NewNotFoundError("feed not found")
+	ErrAlreadyExists = utils.NewConflictError("already subscribed to this feed")
+	// ErrPrivateAddress rejects feeds on loopback, private or link-local
+	// addresses, which the server could reach but the subscriber should not
+	ErrPrivateAddress = utils.NewValidationError("url", "must not point at a private or local address")
+)
+
+// Feed is an RSS or Atom feed a user subscribed to. New entries are saved as
+// articles each time the feed is polled.
+type Feed struct {
+	ID      uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID  uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_user_feed_url"`
+	URL     string    `json:"url" gorm:"size:2048;not null;uniqueIndex:idx_user_feed_url"`
+	Title   string    `json:"title" gorm:"size:500"`
+	SiteURL string    `json:"site_url" gorm:"size:2048"`
+
+	// Conditional request validators from the last successful fetch
+	ETag         string `json:"-" gorm:"column:etag;size:255"`
+	LastModified string `json:"-" gorm:"size:64"`
+
+	LastPolledAt  *time.Time `json:"last_polled_at,omitempty" gorm:"index"`
+	LastEntryAt   *time.Time `json:"last_entry_at,omitempty"` // When the last new entry was saved
+	LastError     string     `json:"last_error,omitempty" gorm:"type:text"`
+	ErrorCount    int        `json:"error_count" gorm:"default:0"` // Consecutive failed polls
+	ArticlesAdded int        `json:"articles_added" gorm:"default:0"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Associations (forward declarations)
+	User *User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for GORM
+func (Feed) TableName() string {
+	return "feeds"
+}
+
+// SeenEntry records an entry of a feed that was already handled, so entries
+// are saved once even after the user deletes the article
+type SeenEntry struct {
+	FeedID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	EntryHash string    `gorm:"size:64;primaryKey"` // SHA-256 of the entry's GUID or link
+	SeenAt    time.Time `gorm:"autoCreateTime"`
+
+	// Associations (forward declarations)
+	Feed *Feed `gorm:"foreignKey:FeedID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for GORM
+func (SeenEntry) TableName() string {
+	return "feed_entries"
+}
+
+// User represents user for foreign key relationship (forward declaration)
+type User struct {
+	ID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Email string
+}
+
+// Document is a parsed RSS or Atom feed
+type Document struct {
+	Title   string
+	SiteURL string
+	Entries []*Entry
+}
+
+// Entry is a single item of a feed
+type Entry struct {
+	GUID        string // Falls back to the link when the feed gives no ID
+	URL         string
+	Title       string
+	PublishedAt time.Time // Zero when the feed gives no date
+}
+
+// FetchResult is the response to a conditional feed request
+type FetchResult struct {
+	Data         []byte
+	ETag         string
+	LastModified string
+	NotModified  bool // The feed has not changed since the validators were issued
+}
+
+// Repository defines the interface for feed data access
+type Repository interface {
+	Create(feed *Feed) error
+	FindByID(id uuid.UUID) (*Feed, error)
+	FindByURL(userID uuid.UUID, url string) (*Feed, error)
+	FindByUserID(userID uuid.UUID) ([]*Feed, error)
+	CountByUserID(userID uuid.UUID) (int64, error)
+	// FindDue returns feeds not polled since the given time, least recently polled first
+	FindDue(polledBefore time.Time, limit int) ([]*Feed, error)
+	Update(feed *Feed) error
+	Delete(id uuid.UUID) error
+
+	// Entry tracking
+	FindSeen(feedID uuid.UUID, hashes []string) (map[string]bool, error)
+	MarkSeen(feedID uuid.UUID, hashes []string) error
+}
+
+// Service defines the interface for feed business logic
+type Service interface {
+	Subscribe(ctx context.Context, userID uuid.UUID, req *SubscribeRequest) (*Feed, error)
+	GetFeed(id, userID uuid.UUID) (*Feed, error)
+	GetUserFeeds(userID uuid.UUID) ([]*Feed, error)
+	Unsubscribe(id, userID uuid.UUID) error
+	RefreshFeed(ctx context.Context, id, userID uuid.UUID) (*Feed, error)
+	// PollDueFeeds polls every feed due for a poll; run by the feed poller worker
+	PollDueFeeds() error
+}
+
+// ArticleService interface for saving feed entries as articles (dependency inversion)
+type ArticleService interface {
+	// CreateFromEntries saves the entries' links, skipping links the user
+	// already saved, and returns how many articles were created
+	CreateFromEntries(userID uuid.UUID, entries []*Entry) (int, error)
+}
+
+// Fetcher downloads feeds
+type Fetcher interface {
+	Fetch(ctx context.Context, url, etag, lastModified string) (*FetchResult, error)
+}
+
+// SubscribeRequest represents a feed subscription request
+type SubscribeRequest struct {
+	URL string `json:"url" binding:"required"`
+}
+
+// FeedListResponse lists a user's feeds
+type FeedListResponse struct {
+	Feeds []*Feed `json:"feeds"`
+}
+
+// IsOwnedBy checks if the feed belongs to the specified user
+func (f *Feed) IsOwnedBy(userID uuid.UUID) bool {
+	return f.UserID == userID
+}
@@ -0,0 +1,459 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rssFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+<channel>
+  <title>Example  Blog</title>
+  <link>https://example.com/</link>
+  <item>
+    <title>Second Post</title>
+    <link>https://example.com/second</link>
+    <guid>urn:post:2</guid>
+    <pubDate>Tue, 02 Jan 2024 10:00:00 +0000</pubDate>
+  </item>
+  <item>
+    <title>First Post</title>
+    <link>/first</link>
+  </item>
+  <item>
+    <title>No Link</title>
+  </item>
+</channel>
+</rss>`
+
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example Atom</title>
+  <link rel="self" href="https://example.com/atom.xml"/>
+  <link href="https://example.com/"/>
+  <entry>
+    <id>tag:example.com,2024:1</id>
+    <title>Atom Entry</title>
+    <link rel="alternate" href="https://example.com/atom-entry"/>
+    <updated>2024-01-03T08:00:00Z</updated>
+  </entry>
+</feed>`
+
+const rdfFeed = `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel><title>RDF Feed</title><link>https://example.com/</link></channel>
+  <item><title>RDF Item</title><link>https://example.com/rdf-item</link><dc:date>2024-01-04</dc:date></item>
+</rdf:RDF>`
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+	return log
+}
+
+func TestParse(t *testing.T) {
+	t.Run("RSS 2.0", func(t *testing.T) {
+		doc, err := Parse([]byte(rssFeed), "https://example.com/feed.xml")
+		require.NoError(t, err)
+
+		assert.Equal(t, "Example Blog", doc.Title)
+		assert.Equal(t, "https://example.com/", doc.SiteURL)
+		require.Len(t, doc.Entries, 2)
+
+		assert.Equal(t, "urn:post:2", doc.Entries[0].GUID)
+		assert.Equal(t, "https://example.com/second", doc.Entries[0].URL)
+		assert.Equal(t, time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC), doc.Entries[0].PublishedAt.UTC())
+
+		// Relative links are resolved and double as the GUID
+		assert.Equal(t, "https://example.com/first", doc.Entries[1].URL)
+		assert.Equal(t, "https://example.com/first", doc.Entries[1].GUID)
+		assert.True(t, doc.Entries[1].PublishedAt.IsZero())
+	})
+
+	t.Run("Atom", func(t *testing.T) {
+		doc, err := Parse([]byte(atomFeed), "https://example.com/atom.xml")
+		require.NoError(t, err)
+
+		assert.Equal(t, "Example Atom", doc.Title)
+		assert.Equal(t, "https://example.com/", doc.SiteURL)
+		require.Len(t, doc.Entries, 1)
+		assert.Equal(t, "tag:example.com,2024:1", doc.Entries[0].GUID)
+		assert.Equal(t, "https://example.com/atom-entry", doc.Entries[0].URL)
+		assert.Equal(t, 2024, doc.Entries[0].PublishedAt.Year())
+	})
+
+	t.Run("RSS 1.0", func(t *testing.T) {
+		doc, err := Parse([]byte(rdfFeed), "https://example.com/index.rdf")
+		require.NoError(t, err)
+
+		assert.Equal(t, "RDF Feed", doc.Title)
+		require.Len(t, doc.Entries, 1)
+		assert.Equal(t, "https://example.com/rdf-item", doc.Entries[0].URL)
+		assert.Equal(t, 4, doc.Entries[0].PublishedAt.Day())
+	})
+
+	t.Run("Rejects other documents", func(t *testing.T) {
+		_, err := Parse([]byte("<html><body>Not a feed</body></html>"), "https://example.com/")
+		assert.Error(t, err)
+
+		_, err = Parse([]byte("not xml at all"), "https://example.com/")
+		assert.Error(t, err)
+	})
+}
+
+func TestSubscribe(t *testing.T) {
+	log := newTestLogger(t)
+	userID := uuid.New()
+
+	t.Run("Marks existing entries seen", func(t *testing.T) {
+		repo := newMockRepository()
+		articles := &mockArticleService{}
+		svc := newService(repo, articles, &mockFetcher{data: rssFeed, etag: `"v1"`}, 10, log)
+
+		feed, err := svc.Subscribe(context.Background(), userID, &SubscribeRequest{URL: "https://Example.com/feed.xml"})
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/feed.xml", feed.URL)
+		assert.Equal(t, "Example Blog", feed.Title)
+		assert.Equal(t, `"v1"`, feed.ETag)
+		assert.Len(t, repo.seen[feed.ID], 2)
+		assert.Empty(t, articles.created)
+	})
+
+	t.Run("Rejects duplicates", func(t *testing.T) {
+		repo := newMockRepository()
+		svc := newService(repo, &mockArticleService{}, &mockFetcher{data: rssFeed}, 10, log)
+
+		_, err := svc.Subscribe(context.Background(), userID, &SubscribeRequest{URL: "https://example.com/feed.xml"})
+		require.NoError(t, err)
+		_, err = svc.Subscribe(context.Background(), userID, &SubscribeRequest{URL: "https://example.com/feed.xml"})
+		assert.ErrorIs(t, err, ErrAlreadyExists)
+	})
+
+	t.Run("Rejects pages that are not feeds", func(t *testing.T) {
+		svc := newService(newMockRepository(), &mockArticleService{}, &mockFetcher{data: "<html></html>"}, 10, log)
+
+		_, err := svc.Subscribe(context.Background(), userID, &SubscribeRequest{URL: "https://example.com/"})
+		assert.ErrorIs(t, err, utils.ErrValidation)
+	})
+
+	t.Run("Rejects private addresses without fetching", func(t *testing.T) {
+		fetcher := &mockFetcher{data: rssFeed}
+		svc := newService(newMockRepository(), &mockArticleService{}, fetcher, 10, log)
+
+		for _, url := range []string{
+			"http://localhost/feed.xml",
+			"http://feeds.localhost/feed.xml",
+			"http://127.0.0.1:8080/feed.xml",
+			"http://10.0.0.5/feed.xml",
+			"http://169.254.169.254/latest/meta-data/",
+		} {
+			_, err := svc.Subscribe(context.Background(), userID, &SubscribeRequest{URL: url})
+			assert.ErrorIs(t, err, ErrPrivateAddress, url)
+		}
+		assert.Zero(t, fetcher.fetches)
+	})
+
+	t.Run("Does not echo fetch errors", func(t *testing.T) {
+		svc := newService(newMockRepository(), &mockArticleService{}, &mockFetcher{err: &statusError{code: 404}}, 10, log)
+
+		_, err := svc.Subscribe(context.Background(), userID, &SubscribeRequest{URL: "https://example.com/feed.xml"})
+		assert.ErrorIs(t, err, utils.ErrValidation)
+		assert.NotContains(t, err.Error(), "404")
+
+		// Names resolving to private addresses are refused when dialing
+		svc = newService(newMockRepository(), &mockArticleService{}, &mockFetcher{err: utils.ErrPrivateDestination}, 10, log)
+		_, err = svc.Subscribe(context.Background(), userID, &SubscribeRequest{URL: "https://internal.example.com/feed.xml"})
+		assert.ErrorIs(t, err, ErrPrivateAddress)
+	})
+
+	t.Run("Enforces the per-user limit", func(t *testing.T) {
+		svc := newService(newMockRepository(), &mockArticleService{}, &mockFetcher{data: rssFeed}, 1, log)
+
+		_, err := svc.Subscribe(context.Background(), userID, &SubscribeRequest{URL: "https://example.com/one.xml"})
+		require.NoError(t, err)
+		_, err = svc.Subscribe(context.Background(), userID, &SubscribeRequest{URL: "https://example.com/two.xml"})
+		assert.ErrorIs(t, err, utils.ErrValidation)
+	})
+
+	t.Run("Feeds are private to their owner", func(t *testing.T) {
+		svc := newService(newMockRepository(), &mockArticleService{}, &mockFetcher{data: rssFeed}, 10, log)
+
+		feed, err := svc.Subscribe(context.Background(), userID, &SubscribeRequest{URL: "https://example.com/feed.xml"})
+		require.NoError(t, err)
+
+		_, err = svc.GetFeed(feed.ID, uuid.New())
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.ErrorIs(t, svc.Unsubscribe(feed.ID, uuid.New()), ErrNotFound)
+	})
+}
+
+func TestHTTPFetcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(rssFeed))
+	}))
+	defer server.Close()
+
+	// The test server listens on loopback, which feeds may not reach
+	_, err := newHTTPFetcher(time.Second).Fetch(context.Background(), server.URL, "", "")
+	assert.ErrorIs(t, err, utils.ErrPrivateDestination)
+	assert.Equal(t, "the feed's address is not allowed", describeFetchError(err))
+}
+
+func TestPoll(t *testing.T) {
+	log := newTestLogger(t)
+	userID := uuid.New()
+
+	subscribe := func(t *testing.T, fetcher *mockFetcher, articles *mockArticleService) (*service, *Feed) {
+		svc := newService(newMockRepository(), articles, fetcher, 10, log)
+		feed, err := svc.Subscribe(context.Background(), userID, &SubscribeRequest{URL: "https://example.com/feed.xml"})
+		require.NoError(t, err)
+		return svc, feed
+	}
+
+	t.Run("Saves only new entries", func(t *testing.T) {
+		fetcher := &mockFetcher{data: atomFeed}
+		articles := &mockArticleService{}
+		svc, feed := subscribe(t, fetcher, articles)
+
+		fetcher.data = rssFeed
+		refreshed, err := svc.RefreshFeed(context.Background(), feed.ID, userID)
+		require.NoError(t, err)
+		assert.Len(t, articles.created, 2)
+		assert.Equal(t, 2, refreshed.ArticlesAdded)
+		assert.NotNil(t, refreshed.LastEntryAt)
+
+		// A second poll of the same document creates nothing
+		_, err = svc.RefreshFeed(context.Background(), feed.ID, userID)
+		require.NoError(t, err)
+		assert.Len(t, articles.created, 2)
+	})
+
+	t.Run("Sends validators and handles not modified", func(t *testing.T) {
+		fetcher := &mockFetcher{data: rssFeed, etag: `"v1"`}
+		svc, feed := subscribe(t, fetcher, &mockArticleService{})
+
+		fetcher.notModified = true
+		refreshed, err := svc.RefreshFeed(context.Background(), feed.ID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, `"v1"`, fetcher.lastETag)
+		assert.Empty(t, refreshed.LastError)
+	})
+
+	t.Run("Records failures on the feed", func(t *testing.T) {
+		fetcher := &mockFetcher{data: rssFeed}
+		svc, feed := subscribe(t, fetcher, &mockArticleService{})
+
+		fetcher.err = &statusError{code: 503}
+		refreshed, err := svc.RefreshFeed(context.Background(), feed.ID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "fetch failed: HTTP 503: Service Unavailable", refreshed.LastError)
+		assert.Equal(t, 1, refreshed.ErrorCount)
+
+		// Transport errors name resolved addresses, which are not shown
+		fetcher.err = errors.New("dial tcp 10.0.0.5:80: connect: connection refused")
+		refreshed, err = svc.RefreshFeed(context.Background(), feed.ID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "fetch failed: could not connect", refreshed.LastError)
+		assert.Equal(t, 2, refreshed.ErrorCount)
+
+		fetcher.err = nil
+		refreshed, err = svc.RefreshFeed(context.Background(), feed.ID, userID)
+		require.NoError(t, err)
+		assert.Empty(t, refreshed.LastError)
+		assert.Equal(t, 0, refreshed.ErrorCount)
+	})
+
+	t.Run("Retries entries when saving fails", func(t *testing.T) {
+		fetcher := &mockFetcher{data: atomFeed}
+		articles := &mockArticleService{err: errors.New("database down")}
+		svc, feed := subscribe(t, fetcher, articles)
+
+		fetcher.data = rssFeed
+		refreshed, err := svc.RefreshFeed(context.Background(), feed.ID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, refreshed.ErrorCount)
+
+		articles.err = nil
+		_, err = svc.RefreshFeed(context.Background(), feed.ID, userID)
+		require.NoError(t, err)
+		assert.Len(t, articles.created, 2)
+	})
+
+	t.Run("Polls only due feeds", func(t *testing.T) {
+		fetcher := &mockFetcher{data: atomFeed}
+		articles := &mockArticleService{}
+		svc, feed := subscribe(t, fetcher, articles)
+
+		fetcher.data = rssFeed
+		require.NoError(t, svc.PollDueFeeds())
+		assert.Empty(t, articles.created)
+
+		stale := time.Now().Add(-time.Hour)
+		svc.repo.(*mockRepository).feeds[feed.ID].LastPolledAt = &stale
+		require.NoError(t, svc.PollDueFeeds())
+		assert.Len(t, articles.created, 2)
+	})
+}
+
+// mockRepository keeps feeds and seen entries in memory
+type mockRepository struct {
+	mu    sync.Mutex
+	feeds map[uuid.UUID]*Feed
+	seen  map[uuid.UUID]map[string]bool
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{
+		feeds: make(map[uuid.UUID]*Feed),
+		seen:  make(map[uuid.UUID]map[string]bool),
+	}
+}
+
+func (m *mockRepository) Create(feed *Feed) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *feed
+	m.feeds[feed.ID] = &stored
+	return nil
+}
+
+func (m *mockRepository) FindByID(id uuid.UUID) (*Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	feed, ok := m.feeds[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	found := *feed
+	return &found, nil
+}
+
+func (m *mockRepository) FindByURL(userID uuid.UUID, url string) (*Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, feed := range m.feeds {
+		if feed.UserID == userID && feed.URL == url {
+			found := *feed
+			return &found, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m *mockRepository) FindByUserID(userID uuid.UUID) ([]*Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var feeds []*Feed
+	for _, feed := range m.feeds {
+		if feed.UserID == userID {
+			found := *feed
+			feeds = append(feeds, &found)
+		}
+	}
+	return feeds, nil
+}
+
+func (m *mockRepository) CountByUserID(userID uuid.UUID) (int64, error) {
+	feeds, _ := m.FindByUserID(userID)
+	return int64(len(feeds)), nil
+}
+
+func (m *mockRepository) FindDue(polledBefore time.Time, limit int) ([]*Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var feeds []*Feed
+	for _, feed := range m.feeds {
+		if feed.LastPolledAt == nil || feed.LastPolledAt.Before(polledBefore) {
+			found := *feed
+			feeds = append(feeds, &found)
+		}
+	}
+	return feeds, nil
+}
+
+func (m *mockRepository) Update(feed *Feed) error {
+	return m.Create(feed)
+}
+
+func (m *mockRepository) Delete(id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.feeds, id)
+	delete(m.seen, id)
+	return nil
+}
+
+func (m *mockRepository) FindSeen(feedID uuid.UUID, hashes []string) (map[string]bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	seen := make(map[string]bool)
+	for _, hash := range hashes {
+		if m.seen[feedID][hash] {
+			seen[hash] = true
+		}
+	}
+	return seen, nil
+}
+
+func (m *mockRepository) MarkSeen(feedID uuid.UUID, hashes []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.seen[feedID] == nil {
+		m.seen[feedID] = make(map[string]bool)
+	}
+	for _, hash := range hashes {
+		m.seen[feedID][hash] = true
+	}
+	return nil
+}
+
+// mockArticleService records the entries it was asked to save
+type mockArticleService struct {
+	mu      sync.Mutex
+	created []*Entry
+	err     error
+}
+
+func (m *mockArticleService) CreateFromEntries(userID uuid.UUID, entries []*Entry) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return 0, m.err
+	}
+	m.created = append(m.created, entries...)
+	return len(entries), nil
+}
+
+// mockFetcher serves a fixed document
+type mockFetcher struct {
+	data        string
+	etag        string
+	notModified bool
+	err         error
+	lastETag    string
+	fetches     int
+}
+
+func (m *mockFetcher) Fetch(ctx context.Context, url, etag, lastModified string) (*FetchResult, error) {
+	m.lastETag = etag
+	m.fetches++
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.notModified {
+		return &FetchResult{NotModified: true, ETag: etag, LastModified: lastModified}, nil
+	}
+	return &FetchResult{Data: []byte(m.data), ETag: m.etag}, nil
+}
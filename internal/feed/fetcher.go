@@ -0,0 +1,107 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+)
+
+// maxFeedSize bounds downloaded feeds; larger documents are rejected
+const maxFeedSize = 5 * 1024 * 1024
+
+// statusError is returned for answers other than 200 and 304
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.code, http.StatusText(e.code))
+}
+
+// describeFetchError summarizes a fetch failure for the feed's owner without
+// exposing resolved addresses or transport internals
+func describeFetchError(err error) string {
+	var statusErr *statusError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &statusErr):
+		return statusErr.Error()
+	case errors.Is(err, utils.ErrPrivateDestination):
+		return "the feed's address is not allowed"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "timed out"
+	default:
+		return "could not connect"
+	}
+}
+
+// httpFetcher downloads feeds with conditional requests, so unchanged feeds
+// cost a 304 instead of the whole document
+type httpFetcher struct {
+	client    *http.Client
+	userAgent string
+}
+
+// newHTTPFetcher creates a fetcher that refuses to connect to private
+// addresses, whether subscribed to, redirected to or resolved from a name.
+// Feeds are fetched directly, as a proxy would hide the address dialed.
+func newHTTPFetcher(timeout time.Duration) *httpFetcher {
+	dialer := &net.Dialer{Timeout: timeout, Control: utils.DenyPrivateAddresses}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &httpFetcher{
+		client:    &http.Client{Timeout: timeout, Transport: transport},
+		userAgent: "Articles-Backend-Bot/1.0",
+	}
+}
+
+func (f *httpFetcher) Fetch(ctx context.Context, url, etag, lastModified string) (*FetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", "application/rss+xml,application/atom+xml,application/xml;q=0.9,text/xml;q=0.8,*/*;q=0.5")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return &FetchResult{NotModified: true, ETag: etag, LastModified: lastModified}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{code: resp.StatusCode}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxFeedSize {
+		return nil, fmt.Errorf("feed is larger than %d bytes", maxFeedSize)
+	}
+
+	return &FetchResult{
+		Data:         data,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
@@ -0,0 +1,138 @@
+package feed
+
+import (
+	"net/http"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for feeds
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new feed handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// Subscribe handles subscribing to a feed
+func (h *Handler) Subscribe(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	var req SubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	feed, err := h.service.Subscribe(c.Request.Context(), userID, &req)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to subscribe to feed")
+		return
+	}
+
+	c.JSON(http.StatusCreated, feed)
+}
+
+// GetFeeds handles listing the user's feeds
+func (h *Handler) GetFeeds(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	feeds, err := h.service.GetUserFeeds(userID)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to get feeds")
+		return
+	}
+
+	c.JSON(http.StatusOK, FeedListResponse{Feeds: feeds})
+}
+
+// GetFeed handles getting a single feed
+func (h *Handler) GetFeed(c *gin.Context) {
+	userID, feedID, ok := h.feedTarget(c)
+	if !ok {
+		return
+	}
+
+	feed, err := h.service.GetFeed(feedID, userID)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to get feed")
+		return
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
+
+// Unsubscribe handles removing a feed; articles it created are kept
+func (h *Handler) Unsubscribe(c *gin.Context) {
+	userID, feedID, ok := h.feedTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Unsubscribe(feedID, userID); err != nil {
+		utils.RespondError(c, err, "Failed to unsubscribe from feed")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Unsubscribed from feed"})
+}
+
+// RefreshFeed handles polling a feed immediately
+func (h *Handler) RefreshFeed(c *gin.Context) {
+	userID, feedID, ok := h.feedTarget(c)
+	if !ok {
+		return
+	}
+
+	feed, err := h.service.RefreshFeed(c.Request.Context(), feedID, userID)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to refresh feed")
+		return
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
+
+// feedTarget extracts the user and feed of a request, writing the error response on failure
+func (h *Handler) feedTarget(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	feedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feed ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, feedID, true
+}
+
+// RegisterRoutes registers all feed routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	feeds := router.Group("/feeds")
+	feeds.Use(authMiddleware)
+	{
+		feeds.POST("", h.Subscribe)
+		feeds.GET("", h.GetFeeds)
+		feeds.GET("/:id", h.GetFeed)
+		feeds.DELETE("/:id", h.Unsubscribe)
+		feeds.POST("/:id/refresh", h.RefreshFeed)
+	}
+}
@@ -0,0 +1,183 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// xmlDocument covers RSS 2.0, RSS 1.0 (RDF) and Atom. Elements are matched by
+// local name, so the same struct decodes all three root elements.
+type xmlDocument struct {
+	XMLName xml.Name
+
+	// RSS
+	Channel struct {
+		Title string    `xml:"title"`
+		Links []xmlLink `xml:"link"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items []rssItem `xml:"item"` // RSS 1.0 lists items beside the channel
+
+	// Atom
+	Title   string      `xml:"title"`
+	Links   []xmlLink   `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// xmlLink is an RSS link (text) or an Atom link (href attribute)
+type xmlLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Text string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title   string    `xml:"title"`
+	Links   []xmlLink `xml:"link"`
+	GUID    string    `xml:"guid"`
+	PubDate string    `xml:"pubDate"`
+	Date    string    `xml:"date"` // Dublin Core date used by RSS 1.0
+}
+
+type atomEntry struct {
+	ID        string    `xml:"id"`
+	Title     string    `xml:"title"`
+	Links     []xmlLink `xml:"link"`
+	Published string    `xml:"published"`
+	Updated   string    `xml:"updated"`
+}
+
+// dateLayouts are the date formats seen in the wild, most common first
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// Parse reads an RSS or Atom document. Relative entry links are resolved
+// against the feed's URL; entries without a usable link are dropped.
+func Parse(data []byte, feedURL string) (*Document, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+
+	var raw xmlDocument
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("not a valid feed: %v", err)
+	}
+
+	base, _ := url.Parse(feedURL)
+	doc := &Document{}
+
+	switch strings.ToLower(raw.XMLName.Local) {
+	case "rss", "rdf":
+		doc.Title = cleanText(raw.Channel.Title)
+		doc.SiteURL = resolveLink(base, pickLink(raw.Channel.Links))
+		for _, item := range append(raw.Channel.Items, raw.Items...) {
+			link := resolveLink(base, pickLink(item.Links))
+			published := item.PubDate
+			if published == "" {
+				published = item.Date
+			}
+			doc.addEntry(strings.TrimSpace(item.GUID), link, item.Title, published)
+		}
+	case "feed":
+		doc.Title = cleanText(raw.Title)
+		doc.SiteURL = resolveLink(base, pickLink(raw.Links))
+		for _, entry := range raw.Entries {
+			link := resolveLink(base, pickLink(entry.Links))
+			published := entry.Published
+			if published == "" {
+				published = entry.Updated
+			}
+			doc.addEntry(strings.TrimSpace(entry.ID), link, entry.Title, published)
+		}
+	default:
+		return nil, errors.New("not a valid feed: unknown root element <" + raw.XMLName.Local + ">")
+	}
+
+	return doc, nil
+}
+
+func (d *Document) addEntry(guid, link, title, published string) {
+	if link == "" {
+		return
+	}
+	if guid == "" {
+		guid = link
+	}
+	d.Entries = append(d.Entries, &Entry{
+		GUID:        guid,
+		URL:         link,
+		Title:       cleanText(title),
+		PublishedAt: parseDate(published),
+	})
+}
+
+// pickLink prefers an Atom rel="alternate" link, then any link with a target
+func pickLink(links []xmlLink) string {
+	fallback := ""
+	for _, link := range links {
+		target := strings.TrimSpace(link.Href)
+		if target == "" {
+			target = strings.TrimSpace(link.Text)
+		}
+		if target == "" {
+			continue
+		}
+		if link.Rel == "" || link.Rel == "alternate" {
+			return target
+		}
+		if fallback == "" && link.Rel != "self" {
+			fallback = target
+		}
+	}
+	return fallback
+}
+
+// resolveLink makes link absolute, returning "" for anything but http(s)
+func resolveLink(base *url.URL, link string) string {
+	if link == "" {
+		return ""
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		parsed = base.ResolveReference(parsed)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return ""
+	}
+	return parsed.String()
+}
+
+func parseDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range dateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed
+		}
+	}
+	return time.Time{}
+}
+
+func cleanText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
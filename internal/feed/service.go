@@ -0,0 +1,345 @@
+package feed
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	// minPollInterval keeps scheduled polls from refetching feeds that were
+	// just subscribed to or refreshed by hand
+	minPollInterval = 10 * time.Minute
+	// pollBatchSize bounds the feeds polled by a single run; the rest are
+	// picked up by the next run, least recently polled first
+	pollBatchSize = 500
+	// pollConcurrency is the number of feeds fetched at once
+	pollConcurrency = 4
+	// maxEntriesPerPoll bounds the articles one poll creates, so a feed that
+	// republishes its whole archive cannot flood a library
+	maxEntriesPerPoll = 50
+)
+
+// service implements the Service interface
+type service struct {
+	repo           Repository
+	articleService ArticleService
+	fetcher        Fetcher
+	maxPerUser     int
+	polling        atomic.Bool // Set while PollDueFeeds runs, so overlapping runs are skipped
+	logger         *logger.Logger
+}
+
+// NewService creates a new feed service with validation and defaults
+func NewService(cfg *config.FeedConfig, repo Repository, articleService ArticleService, log *logger.Logger) (Service, error) {
+	timeout := 20 * time.Second
+	if cfg != nil && cfg.HTTPTimeout != "" {
+		parsed, err := time.ParseDuration(cfg.HTTPTimeout)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid feed HTTP timeout '%s': must be a positive duration", cfg.HTTPTimeout)
+		}
+		timeout = parsed
+	}
+
+	maxPerUser := 100
+	if cfg != nil && cfg.MaxPerUser != "" {
+		parsed, err := strconv.Atoi(cfg.MaxPerUser)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid feed max per user '%s': must be a positive integer", cfg.MaxPerUser)
+		}
+		maxPerUser = parsed
+	}
+
+	return newService(repo, articleService, newHTTPFetcher(timeout), maxPerUser, log), nil
+}
+
+func newService(repo Repository, articleService ArticleService, fetcher Fetcher, maxPerUser int, log *logger.Logger) *service {
+	return &service{
+		repo:           repo,
+		articleService: articleService,
+		fetcher:        fetcher,
+		maxPerUser:     maxPerUser,
+		logger:         log.WithComponent("feed-service"),
+	}
+}
+
+// Subscribe fetches the feed once to validate it. Entries already in the feed
+// are marked as seen, so only entries published afterwards become articles.
+func (s *service) Subscribe(ctx context.Context, userID uuid.UUID, req *SubscribeRequest) (*Feed, error) {
+	url, err := utils.NormalizeURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	if parsed, err := neturl.Parse(url); err != nil || utils.IsPrivateHost(parsed.Hostname()) {
+		return nil, ErrPrivateAddress
+	}
+
+	s.logger.Info("Subscribing user " + userID.String() + " to feed " + url)
+
+	count, err := s.repo.CountByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= int64(s.maxPerUser) {
+		return nil, utils.NewValidationError("url", "at most "+strconv.Itoa(s.maxPerUser)+" feeds are allowed")
+	}
+
+	if _, err := s.repo.FindByURL(userID, url); err == nil {
+		return nil, ErrAlreadyExists
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	result, err := s.fetcher.Fetch(ctx, url, "", "")
+	if err != nil {
+		s.logger.Info("Rejected feed " + url + " for user " + userID.String() + ": " + err.Error())
+		if errors.Is(err, utils.ErrPrivateDestination) {
+			return nil, ErrPrivateAddress
+		}
+		return nil, utils.NewValidationError("url", "could not be fetched")
+	}
+	doc, err := Parse(result.Data, url)
+	if err != nil {
+		s.logger.Info("Rejected feed " + url + " for user " + userID.String() + ": " + err.Error())
+		return nil, utils.NewValidationError("url", "is not an RSS or Atom feed")
+	}
+
+	now := time.Now()
+	feed := &Feed{
+		ID:           uuid.New(),
+		UserID:       userID,
+		URL:          url,
+		ETag:         result.ETag,
+		LastModified: result.LastModified,
+		LastPolledAt: &now,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	applyDocument(feed, doc)
+
+	if err := s.repo.Create(feed); err != nil {
+		s.logger.Error("Failed to create feed " + url + " for user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	hashes, _ := hashEntries(doc.Entries)
+	if err := s.repo.MarkSeen(feed.ID, hashes); err != nil {
+		s.logger.Error("Failed to record entries of feed " + feed.ID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+func (s *service) GetFeed(id, userID uuid.UUID) (*Feed, error) {
+	feed, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify ownership
+	if !feed.IsOwnedBy(userID) {
+		return nil, ErrNotFound
+	}
+
+	return feed, nil
+}
+
+func (s *service) GetUserFeeds(userID uuid.UUID) ([]*Feed, error) {
+	return s.repo.FindByUserID(userID)
+}
+
+func (s *service) Unsubscribe(id, userID uuid.UUID) error {
+	s.logger.Info("Unsubscribing user " + userID.String() + " from feed " + id.String())
+
+	if _, err := s.GetFeed(id, userID); err != nil {
+		return err
+	}
+
+	// Seen entries cascade; articles already created stay in the library
+	if err := s.repo.Delete(id); err != nil {
+		s.logger.Error("Failed to delete feed " + id.String() + " for user " + userID.String() + ": " + err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// RefreshFeed polls the feed now. Fetch and parse failures are reported in
+// the feed's last_error rather than as an error.
+func (s *service) RefreshFeed(ctx context.Context, id, userID uuid.UUID) (*Feed, error) {
+	feed, err := s.GetFeed(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.poll(ctx, feed); err != nil {
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+func (s *service) PollDueFeeds() error {
+	if !s.polling.CompareAndSwap(false, true) {
+		s.logger.Warn("Skipping feed poll: the previous poll is still running")
+		return nil
+	}
+	defer s.polling.Store(false)
+
+	feeds, err := s.repo.FindDue(time.Now().Add(-minPollInterval), pollBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list due feeds: %w", err)
+	}
+
+	start := time.Now()
+	var failed atomic.Int64
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, pollConcurrency)
+	for _, feed := range feeds {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(feed *Feed) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if err := s.poll(context.Background(), feed); err != nil {
+				s.logger.Error("Failed to save poll of feed " + feed.ID.String() + ": " + err.Error())
+				failed.Add(1)
+			} else if feed.LastError != "" {
+				failed.Add(1)
+			}
+		}(feed)
+	}
+	wg.Wait()
+
+	s.logger.Info("Polled " + strconv.Itoa(len(feeds)) + " feeds (" + strconv.FormatInt(failed.Load(), 10) + " failed) in " + time.Since(start).Round(time.Millisecond).String())
+	return nil
+}
+
+// poll fetches the feed and saves its new entries as articles. Fetch and parse
+// failures are recorded on the feed; only storage failures are returned.
+func (s *service) poll(ctx context.Context, feed *Feed) error {
+	now := time.Now()
+	feed.LastPolledAt = &now
+
+	result, err := s.fetcher.Fetch(ctx, feed.URL, feed.ETag, feed.LastModified)
+	if err != nil {
+		s.logger.Info("Failed to fetch feed " + feed.ID.String() + ": " + err.Error())
+		return s.recordFailure(feed, "fetch failed: "+describeFetchError(err))
+	}
+	if result.NotModified {
+		return s.recordSuccess(feed)
+	}
+
+	doc, err := Parse(result.Data, feed.URL)
+	if err != nil {
+		return s.recordFailure(feed, err.Error())
+	}
+	applyDocument(feed, doc)
+
+	hashes, entries := hashEntries(doc.Entries)
+	seen, err := s.repo.FindSeen(feed.ID, hashes)
+	if err != nil {
+		return err
+	}
+
+	var unseenHashes []string
+	var unseen []*Entry
+	for i, hash := range hashes {
+		if seen[hash] {
+			continue
+		}
+		unseenHashes = append(unseenHashes, hash)
+		// Feeds list the newest entries first; older surplus entries are dropped
+		if len(unseen) < maxEntriesPerPoll {
+			unseen = append(unseen, entries[i])
+		}
+	}
+
+	if len(unseen) > 0 {
+		created, err := s.articleService.CreateFromEntries(feed.UserID, unseen)
+		if err != nil {
+			// The entries stay unseen and are retried by the next poll
+			return s.recordFailure(feed, "failed to save entries: "+err.Error())
+		}
+		if err := s.repo.MarkSeen(feed.ID, unseenHashes); err != nil {
+			return err
+		}
+
+		if created > 0 {
+			s.logger.Info("Saved " + strconv.Itoa(created) + " new entries of feed " + feed.ID.String() + " for user " + feed.UserID.String())
+			feed.ArticlesAdded += created
+			feed.LastEntryAt = &now
+		}
+	}
+
+	// Validators are only kept once the entries are saved, so a failed save is refetched
+	feed.ETag = result.ETag
+	feed.LastModified = result.LastModified
+	return s.recordSuccess(feed)
+}
+
+func (s *service) recordSuccess(feed *Feed) error {
+	feed.LastError = ""
+	feed.ErrorCount = 0
+	return s.save(feed)
+}
+
+func (s *service) recordFailure(feed *Feed, message string) error {
+	s.logger.Warn("Failed to poll feed " + feed.ID.String() + " (" + feed.URL + "): " + message)
+	feed.LastError = message
+	feed.ErrorCount++
+	return s.save(feed)
+}
+
+func (s *service) save(feed *Feed) error {
+	feed.UpdatedAt = time.Now()
+	return s.repo.Update(feed)
+}
+
+// applyDocument copies the feed's own metadata from a parsed document
+func applyDocument(feed *Feed, doc *Document) {
+	if doc.Title != "" {
+		feed.Title = utils.SanitizeText(doc.Title, utils.MaxTitleLength)
+	}
+	if doc.SiteURL != "" && len(doc.SiteURL) <= utils.MaxURLLength {
+		feed.SiteURL = doc.SiteURL
+	}
+}
+
+// hashEntries returns the hashes of the entries' GUIDs with the entries they
+// belong to, dropping entries repeated within the document
+func hashEntries(entries []*Entry) ([]string, []*Entry) {
+	hashes := make([]string, 0, len(entries))
+	unique := make([]*Entry, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		hash := entryHash(entry)
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		hashes = append(hashes, hash)
+		unique = append(unique, entry)
+	}
+	return hashes, unique
+}
+
+// entryHash identifies an entry by the SHA-256 of its GUID
+func entryHash(entry *Entry) string {
+	sum := sha256.Sum256([]byte(entry.GUID))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,104 @@
+package importer
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxUploadSize bounds export files; a Pocket export of 20k links is well below it
+const maxUploadSize = 20 << 20
+
+// Handler handles HTTP requests for imports
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new import handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// StartImport handles uploading an export file and queues the import
+func (h *Handler) StartImport(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	format := c.PostForm("format")
+	if format != FormatPocket && format != FormatInstapaper {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be 'pocket' or 'instapaper'"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Export file is required"})
+		return
+	}
+	if fileHeader.Size > maxUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Export file is too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read export file"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxUploadSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read export file"})
+		return
+	}
+
+	job, err := h.service.StartImport(userID, format, data)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to start import")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetJob handles polling the status of an import
+func (h *Handler) GetJob(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import job ID"})
+		return
+	}
+
+	job, err := h.service.GetJob(jobID, userID)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to get import job")
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// RegisterRoutes registers all import routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	imports := router.Group("/imports")
+	imports.Use(authMiddleware)
+	{
+		imports.POST("", h.StartImport)
+		imports.GET("/:id", h.GetJob)
+	}
+}
@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"context"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// ErrJobNotFound is returned for unknown import jobs and jobs of other users
+var ErrJobNotFound = utils.NewNotFoundError("import job not found")
+
+// Supported export formats
+const (
+	FormatPocket     = "pocket"     // Pocket HTML export (ril_export.html)
+	FormatInstapaper = "instapaper" // Instapaper CSV export
+)
+
+// Job status constants
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+)
+
+// Import modes; large imports use bulk mode, creating links in far fewer round trips
+const (
+	JobModeStandard = "standard"
+	JobModeBulk     = "bulk"
+)
+
+// Item is a single link parsed from an export file
+type Item struct {
+	URL      string
+	Title    string
+	Tags     []string
+	Favorite bool
+	Read     bool
+	AddedAt  time.Time
+}
+
+// Job tracks the progress of an asynchronous import
+type Job struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Format      string     `json:"format" gorm:"size:20;not null"`
+	Status      string     `json:"status" gorm:"size:20;not null"`
+	Total       int        `json:"total"`
+	Imported    int        `json:"imported"`
+	Skipped     int        `json:"skipped"` // Duplicates and invalid URLs
+	Mode        string     `json:"mode" gorm:"size:20"`
+	Throughput  float64    `json:"links_per_second"` // Links processed per second so far
+	Error       string     `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// TableName keeps import jobs apart from article tables
+func (Job) TableName() string {
+	return "import_jobs"
+}
+
+// ImportedArticle pairs a parsed item with the article created for it
+type ImportedArticle struct {
+	ArticleID uuid.UUID
+	Item      *Item
+}
+
+// JobRepository defines the interface for import job persistence
+type JobRepository interface {
+	Create(job *Job) error
+	Update(job *Job) error
+	FindByID(id uuid.UUID) (*Job, error)
+}
+
+// ArticleService interface for creating articles (dependency inversion)
+type ArticleService interface {
+	// ImportArticles creates articles for the items and returns those that were new
+	ImportArticles(userID uuid.UUID, items []*Item) ([]*ImportedArticle, error)
+}
+
+// ProfilePrimer warms up the recommendation profile from imported articles
+type ProfilePrimer interface {
+	PrimeProfile(ctx context.Context, userID uuid.UUID, articles []*ImportedArticle) error
+}
+
+// Service defines the interface for import business logic
+type Service interface {
+	StartImport(userID uuid.UUID, format string, data []byte) (*Job, error)
+	GetJob(id uuid.UUID, userID uuid.UUID) (*Job, error)
+}
@@ -0,0 +1,239 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pocketExport = `<!DOCTYPE html>
+<html>
+<head><title>Pocket Export</title></head>
+<body>
+<h1>Unread</h1>
+<ul>
+<li><a href="https://example.com/go" time_added="1600000000" tags="golang,programming">Learning Go</a></li>
+<li><a href="https://example.com/untagged" time_added="1600000100" tags="">Untagged &amp; Unread</a></li>
+</ul>
+
+<h1>Read Archive</h1>
+<ul>
+<li><a href="https://example.com/read" time_added="1500000000" tags="">Already Read</a></li>
+</ul>
+</body>
+</html>`
+
+const instapaperExport = `URL,Title,Selection,Folder,Timestamp
+https://example.com/unread,Unread Link,,Unread,1600000000
+https://example.com/archived,"Archived, With Comma",,Archive,1600000100
+https://example.com/starred,Starred Link,,Starred,1600000200
+https://example.com/folder,Folder Link,,Research,1600000300
+`
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+	return log
+}
+
+func TestParsePocketHTML(t *testing.T) {
+	items, err := ParsePocketHTML(strings.NewReader(pocketExport))
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	assert.Equal(t, "https://example.com/go", items[0].URL)
+	assert.Equal(t, "Learning Go", items[0].Title)
+	assert.Equal(t, []string{"golang", "programming"}, items[0].Tags)
+	assert.Equal(t, time.Unix(1600000000, 0), items[0].AddedAt)
+	assert.False(t, items[0].Read)
+
+	assert.Equal(t, "Untagged & Unread", items[1].Title)
+	assert.Empty(t, items[1].Tags)
+
+	assert.True(t, items[2].Read)
+}
+
+func TestParseInstapaperCSV(t *testing.T) {
+	t.Run("Maps folders", func(t *testing.T) {
+		items, err := ParseInstapaperCSV(strings.NewReader(instapaperExport))
+		require.NoError(t, err)
+		require.Len(t, items, 4)
+
+		assert.False(t, items[0].Read || items[0].Favorite)
+		assert.Equal(t, "Archived, With Comma", items[1].Title)
+		assert.True(t, items[1].Read)
+		assert.True(t, items[2].Favorite)
+		assert.Equal(t, []string{"Research"}, items[3].Tags)
+		assert.Equal(t, time.Unix(1600000300, 0), items[3].AddedAt)
+	})
+
+	t.Run("Rejects files without URL column", func(t *testing.T) {
+		_, err := ParseInstapaperCSV(strings.NewReader("Title,Folder\nfoo,Unread\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Rejects empty files", func(t *testing.T) {
+		_, err := ParseInstapaperCSV(strings.NewReader(""))
+		assert.Error(t, err)
+	})
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	_, err := Parse("delicious", []byte(pocketExport))
+	assert.Error(t, err)
+}
+
+func TestImportJob(t *testing.T) {
+	log := newTestLogger(t)
+	userID := uuid.New()
+
+	t.Run("Imports in batches and primes profile", func(t *testing.T) {
+		jobRepo := newMockJobRepository()
+		articles := &mockArticleService{}
+		primer := &mockProfilePrimer{}
+		svc := NewService(jobRepo, articles, primer, log).(*service)
+
+		items, err := Parse(FormatInstapaper, []byte(instapaperExport))
+		require.NoError(t, err)
+
+		job := &Job{ID: uuid.New(), UserID: userID, Format: FormatInstapaper, Total: len(items)}
+		require.NoError(t, jobRepo.Create(job))
+		svc.run(job, items)
+
+		saved, err := svc.GetJob(job.ID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, JobStatusCompleted, saved.Status)
+		assert.Equal(t, 3, saved.Imported)
+		assert.Equal(t, 1, saved.Skipped)
+		assert.NotNil(t, saved.CompletedAt)
+		assert.Len(t, primer.articles, 3)
+	})
+
+	t.Run("Large imports run in bulk mode", func(t *testing.T) {
+		var export strings.Builder
+		export.WriteString("URL,Title,Selection,Folder,Timestamp\n")
+		for i := 0; i < bulkImportThreshold; i++ {
+			fmt.Fprintf(&export, "https://example.com/%d,Link %d,,Unread,1600000000\n", i, i)
+		}
+
+		jobRepo := newMockJobRepository()
+		svc := NewService(jobRepo, &mockArticleService{}, nil, log).(*service)
+		small, err := svc.StartImport(userID, FormatInstapaper, []byte(instapaperExport))
+		require.NoError(t, err)
+		assert.Equal(t, JobModeStandard, small.Mode)
+		large, err := svc.StartImport(userID, FormatInstapaper, []byte(export.String()))
+		require.NoError(t, err)
+		assert.Equal(t, JobModeBulk, large.Mode)
+
+		// One batch, of which the mock skips the last link
+		items, err := Parse(FormatInstapaper, []byte(export.String()))
+		require.NoError(t, err)
+		job := &Job{ID: uuid.New(), UserID: userID, Format: FormatInstapaper, Total: len(items), Mode: JobModeBulk}
+		require.NoError(t, jobRepo.Create(job))
+		svc.run(job, items)
+
+		assert.Equal(t, bulkImportThreshold-1, job.Imported)
+		assert.Equal(t, 1, job.Skipped)
+		assert.Greater(t, job.Throughput, 0.0)
+	})
+
+	t.Run("Marks job failed when article creation fails", func(t *testing.T) {
+		jobRepo := newMockJobRepository()
+		svc := NewService(jobRepo, &mockArticleService{err: errors.New("database down")}, nil, log).(*service)
+
+		job := &Job{ID: uuid.New(), UserID: userID, Format: FormatPocket, Total: 1}
+		require.NoError(t, jobRepo.Create(job))
+		svc.run(job, []*Item{{URL: "https://example.com"}})
+
+		assert.Equal(t, JobStatusFailed, job.Status)
+		assert.Equal(t, "database down", job.Error)
+	})
+
+	t.Run("Jobs are private to their owner", func(t *testing.T) {
+		jobRepo := newMockJobRepository()
+		svc := NewService(jobRepo, &mockArticleService{}, nil, log)
+
+		job, err := svc.StartImport(userID, FormatPocket, []byte(pocketExport))
+		require.NoError(t, err)
+		assert.Equal(t, 3, job.Total)
+
+		_, err = svc.GetJob(job.ID, uuid.New())
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+
+	t.Run("Rejects files without links", func(t *testing.T) {
+		svc := NewService(newMockJobRepository(), &mockArticleService{}, nil, log)
+
+		_, err := svc.StartImport(userID, FormatPocket, []byte("<html><body></body></html>"))
+		assert.ErrorIs(t, err, utils.ErrValidation)
+	})
+}
+
+// mockJobRepository keeps jobs in memory
+type mockJobRepository struct {
+	mu   sync.Mutex
+	jobs map[uuid.UUID]Job
+}
+
+func newMockJobRepository() *mockJobRepository {
+	return &mockJobRepository{jobs: make(map[uuid.UUID]Job)}
+}
+
+func (m *mockJobRepository) Create(job *Job) error {
+	return m.Update(job)
+}
+
+func (m *mockJobRepository) Update(job *Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[job.ID] = *job
+	return nil
+}
+
+func (m *mockJobRepository) FindByID(id uuid.UUID) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return &job, nil
+}
+
+// mockArticleService creates every link of a batch except the last one
+type mockArticleService struct {
+	err error
+}
+
+func (m *mockArticleService) ImportArticles(userID uuid.UUID, items []*Item) ([]*ImportedArticle, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	// Pretend the last link was already saved
+	imported := make([]*ImportedArticle, 0, len(items))
+	for _, item := range items[:len(items)-1] {
+		imported = append(imported, &ImportedArticle{ArticleID: uuid.New(), Item: item})
+	}
+	return imported, nil
+}
+
+type mockProfilePrimer struct {
+	articles []*ImportedArticle
+}
+
+func (m *mockProfilePrimer) PrimeProfile(ctx context.Context, userID uuid.UUID, articles []*ImportedArticle) error {
+	m.articles = articles
+	return nil
+}
@@ -0,0 +1,172 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Parse reads an export file in the given format
+func Parse(format string, data []byte) ([]*Item, error) {
+	switch format {
+	case FormatPocket:
+		return ParsePocketHTML(bytes.NewReader(data))
+	case FormatInstapaper:
+		return ParseInstapaperCSV(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("unsupported import format '%s'", format)
+	}
+}
+
+// ParsePocketHTML parses a Pocket HTML export. Links are listed under
+// "Unread" and "Read Archive" headings; tags and the time added are
+// carried as attributes on each anchor.
+func ParsePocketHTML(r io.Reader) ([]*Item, error) {
+	tokenizer := html.NewTokenizer(r)
+
+	var items []*Item
+	var current *Item
+	inHeading := false
+	heading := ""
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return nil, fmt.Errorf("invalid Pocket export: %v", err)
+			}
+			return items, nil
+
+		case html.StartTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "h1":
+				inHeading = true
+				heading = ""
+			case "a":
+				current = &Item{Read: strings.Contains(strings.ToLower(heading), "archive")}
+				for _, attr := range token.Attr {
+					switch attr.Key {
+					case "href":
+						current.URL = strings.TrimSpace(attr.Val)
+					case "time_added":
+						current.AddedAt = parseUnixTime(attr.Val)
+					case "tags":
+						current.Tags = splitTags(attr.Val)
+					}
+				}
+			}
+
+		case html.TextToken:
+			text := string(tokenizer.Text())
+			if inHeading {
+				heading += text
+			} else if current != nil {
+				current.Title += text
+			}
+
+		case html.EndTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "h1":
+				inHeading = false
+			case "a":
+				if current != nil && current.URL != "" {
+					current.Title = strings.TrimSpace(current.Title)
+					items = append(items, current)
+				}
+				current = nil
+			}
+		}
+	}
+}
+
+// ParseInstapaperCSV parses an Instapaper CSV export with the columns
+// URL, Title, Selection, Folder and Timestamp. Starred and archived links
+// become favorite and read flags; any other folder becomes a tag.
+func ParseInstapaperCSV(r io.Reader) ([]*Item, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("invalid Instapaper export: file is empty")
+		}
+		return nil, fmt.Errorf("invalid Instapaper export: %v", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["url"]; !ok {
+		return nil, errors.New("invalid Instapaper export: missing URL column")
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var items []*Item
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid Instapaper export: %v", err)
+		}
+
+		item := &Item{
+			URL:     field(record, "url"),
+			Title:   field(record, "title"),
+			AddedAt: parseUnixTime(field(record, "timestamp")),
+		}
+		if item.URL == "" {
+			continue
+		}
+
+		switch folder := field(record, "folder"); strings.ToLower(folder) {
+		case "", "unread":
+		case "archive":
+			item.Read = true
+		case "starred":
+			item.Favorite = true
+		default:
+			item.Tags = []string{folder}
+		}
+
+		items = append(items, item)
+	}
+}
+
+// parseUnixTime converts a unix timestamp in seconds, returning the zero time when invalid
+func parseUnixTime(value string) time.Time {
+	seconds, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+// splitTags splits a comma separated tag list
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
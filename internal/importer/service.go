@@ -0,0 +1,158 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxImportItems bounds a single import so one job cannot monopolize the database
+	maxImportItems = 20000
+	// importBatchSize is the number of links created per round trip
+	importBatchSize = 200
+	// Imports of at least bulkImportThreshold links run in bulk mode, with
+	// batches of bulkBatchSize links
+	bulkImportThreshold = 2000
+	bulkBatchSize       = 5000
+)
+
+// service implements the Service interface
+type service struct {
+	jobRepo        JobRepository
+	articleService ArticleService
+	primer         ProfilePrimer
+	logger         *logger.Logger
+}
+
+// NewService creates a new import service
+func NewService(jobRepo JobRepository, articleService ArticleService, primer ProfilePrimer, log *logger.Logger) Service {
+	return &service{
+		jobRepo:        jobRepo,
+		articleService: articleService,
+		primer:         primer,
+		logger:         log.WithComponent("import-service"),
+	}
+}
+
+func (s *service) StartImport(userID uuid.UUID, format string, data []byte) (*Job, error) {
+	s.logger.Info("Starting " + format + " import for user " + userID.String())
+
+	// Parse up front so malformed files are rejected immediately
+	items, err := Parse(format, data)
+	if err != nil {
+		s.logger.Info("Rejected " + format + " import for user " + userID.String() + ": " + err.Error())
+		return nil, utils.NewValidationError("file", err.Error())
+	}
+	if len(items) == 0 {
+		return nil, utils.NewValidationError("file", "contains no links")
+	}
+	if len(items) > maxImportItems {
+		return nil, utils.NewValidationError("file", fmt.Sprintf("contains %d links, at most %d are allowed", len(items), maxImportItems))
+	}
+
+	job := &Job{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Format:    format,
+		Status:    JobStatusQueued,
+		Total:     len(items),
+		Mode:      JobModeStandard,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if len(items) >= bulkImportThreshold {
+		job.Mode = JobModeBulk
+	}
+
+	if err := s.jobRepo.Create(job); err != nil {
+		s.logger.Error("Failed to create import job for user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	// Run the import in the background; clients poll the job for progress.
+	// The caller gets a snapshot since the running import keeps mutating job.
+	snapshot := *job
+	go s.run(job, items)
+
+	return &snapshot, nil
+}
+
+func (s *service) GetJob(id uuid.UUID, userID uuid.UUID) (*Job, error) {
+	job, err := s.jobRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify ownership
+	if job.UserID != userID {
+		return nil, ErrJobNotFound
+	}
+
+	return job, nil
+}
+
+// run creates articles batch by batch, then primes the recommendation profile
+func (s *service) run(job *Job, items []*Item) {
+	job.Status = JobStatusRunning
+	s.saveJob(job)
+
+	batchSize := importBatchSize
+	if job.Mode == JobModeBulk {
+		batchSize = bulkBatchSize
+	}
+
+	startedAt := time.Now()
+	var imported []*ImportedArticle
+	for start := 0; start < len(items); start += batchSize {
+		end := start + batchSize
+		if end > len(items) {
+			end = len(items)
+		}
+
+		created, err := s.articleService.ImportArticles(job.UserID, items[start:end])
+		if err != nil {
+			s.logger.Error("Import job " + job.ID.String() + " failed: " + err.Error())
+			s.finishJob(job, JobStatusFailed, err.Error())
+			return
+		}
+
+		imported = append(imported, created...)
+		job.Imported += len(created)
+		job.Skipped += end - start - len(created)
+		if elapsed := time.Since(startedAt).Seconds(); elapsed > 0 {
+			job.Throughput = float64(end) / elapsed
+		}
+		s.saveJob(job)
+	}
+
+	// Priming only improves the first recommendations, so failures do not fail the import
+	// Jobs outlive the request that started them, so priming is not bound to it
+	if s.primer != nil && len(imported) > 0 {
+		if err := s.primer.PrimeProfile(context.Background(), job.UserID, imported); err != nil {
+			s.logger.Error("Failed to prime recommendation profile after import job " + job.ID.String() + ": " + err.Error())
+		}
+	}
+
+	s.logger.Info("Import job " + job.ID.String() + " completed: " + fmt.Sprintf("%d", job.Imported) + " imported, " + fmt.Sprintf("%d", job.Skipped) + " skipped, " + fmt.Sprintf("%.0f", job.Throughput) + " links per second in " + job.Mode + " mode")
+	s.finishJob(job, JobStatusCompleted, "")
+}
+
+func (s *service) finishJob(job *Job, status string, message string) {
+	now := time.Now()
+	job.Status = status
+	job.Error = message
+	job.CompletedAt = &now
+	s.saveJob(job)
+}
+
+func (s *service) saveJob(job *Job) {
+	job.UpdatedAt = time.Now()
+	if err := s.jobRepo.Update(job); err != nil {
+		s.logger.Error("Failed to update import job " + job.ID.String() + ": " + err.Error())
+	}
+}
@@ -0,0 +1,88 @@
+package mlexport
+
+import (
+	"encoding/csv"
+	"net/http"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for ML data exports
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new ML export handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// ExportEmbeddings streams anonymized article embeddings
+func (h *Handler) ExportEmbeddings(c *gin.Context) {
+	h.export(c, "embeddings", h.service.ExportEmbeddings)
+}
+
+// ExportRatings streams the anonymized rating matrix
+func (h *Handler) ExportRatings(c *gin.Context) {
+	h.export(c, "ratings", h.service.ExportRatings)
+}
+
+func (h *Handler) export(c *gin.Context, name string, exportFunc func(*uuid.UUID, string, RowWriter) error) {
+	// Optional per-user export
+	var userID *uuid.UUID
+	if param := c.Query("user_id"); param != "" {
+		parsed, err := uuid.Parse(param)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		userID = &parsed
+	}
+
+	format := c.DefaultQuery("format", FormatCSV)
+	if format != FormatCSV {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported format: " + format})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename="+name+".csv")
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	err := exportFunc(userID, format, writer)
+	writer.Flush()
+
+	if err != nil {
+		// Headers are already sent; abort the stream so clients see a truncated download
+		c.Error(err)
+		c.Abort()
+	}
+}
+
+// featureFlag rejects requests while exports are disabled
+func (h *Handler) featureFlag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !h.service.Enabled() {
+			c.JSON(http.StatusNotFound, gin.H{"error": "ML export is disabled"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RegisterRoutes registers ML export routes gated by feature flag, admin role
+// and scope, rate limited like other admin routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, rateLimit gin.HandlerFunc) {
+	exports := router.Group("/admin/ml-export")
+	exports.Use(h.featureFlag(), authMiddleware, utils.RequireRole(utils.RoleAdmin), utils.RequireScope(utils.ScopeMLExport), rateLimit)
+	{
+		exports.GET("/embeddings", h.ExportEmbeddings)
+		exports.GET("/ratings", h.ExportRatings)
+	}
+}
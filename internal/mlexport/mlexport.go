@@ -0,0 +1,48 @@
+package mlexport
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Supported export formats
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+// EmbeddingRow is a single article embedding read from storage
+type EmbeddingRow struct {
+	ArticleID uuid.UUID
+	UserID    uuid.UUID
+	WordCount int
+	Embedding string // pgvector text representation, e.g. "[0.1,0.2]"
+	CreatedAt time.Time
+}
+
+// RatingRow is a single user/article rating read from storage
+type RatingRow struct {
+	UserID    uuid.UUID
+	ArticleID uuid.UUID
+	Score     int
+	CreatedAt time.Time
+}
+
+// Repository streams training data without loading it all into memory
+type Repository interface {
+	StreamEmbeddings(userID *uuid.UUID, fn func(row *EmbeddingRow) error) error
+	StreamRatings(userID *uuid.UUID, fn func(row *RatingRow) error) error
+}
+
+// Service defines the interface for anonymized ML data exports
+type Service interface {
+	Enabled() bool
+	ExportEmbeddings(userID *uuid.UUID, format string, w RowWriter) error
+	ExportRatings(userID *uuid.UUID, format string, w RowWriter) error
+}
+
+// RowWriter receives exported rows; the first row is the header
+type RowWriter interface {
+	Write(record []string) error
+}
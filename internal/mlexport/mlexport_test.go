@@ -0,0 +1,140 @@
+package mlexport
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRepository struct {
+	embeddings []*EmbeddingRow
+	ratings    []*RatingRow
+}
+
+func (m *mockRepository) StreamEmbeddings(userID *uuid.UUID, fn func(row *EmbeddingRow) error) error {
+	for _, row := range m.embeddings {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockRepository) StreamRatings(userID *uuid.UUID, fn func(row *RatingRow) error) error {
+	for _, row := range m.ratings {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type recordingWriter struct {
+	records [][]string
+}
+
+func (w *recordingWriter) Write(record []string) error {
+	w.records = append(w.records, record)
+	return nil
+}
+
+func newTestService(t *testing.T, cfg *config.MLExportConfig, repo Repository) Service {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	svc, err := NewService(cfg, repo, log)
+	require.NoError(t, err)
+	return svc
+}
+
+func TestNewService(t *testing.T) {
+	assert.False(t, newTestService(t, &config.MLExportConfig{}, &mockRepository{}).Enabled())
+	assert.True(t, newTestService(t, &config.MLExportConfig{Enabled: "true"}, &mockRepository{}).Enabled())
+
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+	_, err = NewService(&config.MLExportConfig{Enabled: "maybe"}, &mockRepository{}, log)
+	assert.Error(t, err)
+}
+
+func TestExportRatingsAnonymizes(t *testing.T) {
+	userID := uuid.New()
+	articleID := uuid.New()
+	repo := &mockRepository{
+		ratings: []*RatingRow{
+			{UserID: userID, ArticleID: articleID, Score: 5, CreatedAt: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)},
+			{UserID: userID, ArticleID: uuid.New(), Score: 3, CreatedAt: time.Date(2025, 8, 2, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	svc := newTestService(t, &config.MLExportConfig{Enabled: "true", AnonymizationSalt: "salt"}, repo)
+
+	writer := &recordingWriter{}
+	require.NoError(t, svc.ExportRatings(nil, FormatCSV, writer))
+
+	require.Len(t, writer.records, 3)
+	assert.Equal(t, []string{"user", "article", "score", "created_at"}, writer.records[0])
+
+	first := writer.records[1]
+	assert.NotContains(t, first[0], userID.String())
+	assert.NotContains(t, first[1], articleID.String())
+	assert.Len(t, first[0], 32)
+	assert.Equal(t, "5", first[2])
+	assert.Equal(t, "2025-08-01T00:00:00Z", first[3])
+
+	// The same user maps to the same anonymized ID
+	assert.Equal(t, first[0], writer.records[2][0])
+}
+
+func TestExportEmbeddings(t *testing.T) {
+	repo := &mockRepository{
+		embeddings: []*EmbeddingRow{
+			{ArticleID: uuid.New(), UserID: uuid.New(), WordCount: 120, Embedding: "[0.1,0.2]", CreatedAt: time.Now()},
+		},
+	}
+	svc := newTestService(t, &config.MLExportConfig{Enabled: "true"}, repo)
+
+	writer := &recordingWriter{}
+	require.NoError(t, svc.ExportEmbeddings(nil, FormatCSV, writer))
+
+	require.Len(t, writer.records, 2)
+	assert.Equal(t, "120", writer.records[1][2])
+	assert.Equal(t, "[0.1,0.2]", writer.records[1][4])
+}
+
+func TestRoutesRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := newTestService(t, &config.MLExportConfig{Enabled: "true"}, &mockRepository{})
+
+	request := func(role string) int {
+		router := gin.New()
+		auth := func(c *gin.Context) {
+			c.Set("role", role)
+			c.Set("scopes", []string{utils.ScopeRead, utils.ScopeMLExport})
+		}
+		NewHandler(svc).RegisterRoutes(router.Group("/"), auth, func(c *gin.Context) {})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/ml-export/ratings", nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, request(utils.RoleUser), "the scope alone is not enough")
+	assert.Equal(t, http.StatusOK, request(utils.RoleAdmin))
+}
+
+func TestExportRejectsParquet(t *testing.T) {
+	svc := newTestService(t, &config.MLExportConfig{Enabled: "true"}, &mockRepository{})
+
+	err := svc.ExportRatings(nil, FormatParquet, &recordingWriter{})
+	assert.True(t, errors.Is(err, ErrUnsupportedFormat))
+}
@@ -0,0 +1,146 @@
+package mlexport
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// ErrUnsupportedFormat is returned for formats the exporter cannot produce
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// service implements the Service interface
+type service struct {
+	repo    Repository
+	enabled bool
+	salt    []byte
+	logger  *logger.Logger
+}
+
+// NewService creates an ML export service with validation and defaults
+func NewService(cfg *config.MLExportConfig, repo Repository, log *logger.Logger) (Service, error) {
+	enabled := false
+	if cfg != nil && cfg.Enabled != "" {
+		parsed, err := strconv.ParseBool(cfg.Enabled)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ML export enabled flag '%s': %v", cfg.Enabled, err)
+		}
+		enabled = parsed
+	}
+
+	serviceLogger := log.WithComponent("ml-export-service")
+
+	// Without a configured salt, identifiers are only stable within this process
+	var salt []byte
+	if cfg != nil && cfg.AnonymizationSalt != "" {
+		salt = []byte(cfg.AnonymizationSalt)
+	} else {
+		salt = make([]byte, 32)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, fmt.Errorf("failed to generate anonymization salt: %v", err)
+		}
+		if enabled {
+			serviceLogger.Warn("ML_EXPORT_SALT not set; anonymized IDs will change on restart")
+		}
+	}
+
+	return &service{
+		repo:    repo,
+		enabled: enabled,
+		salt:    salt,
+		logger:  serviceLogger,
+	}, nil
+}
+
+func (s *service) Enabled() bool {
+	return s.enabled
+}
+
+func (s *service) ExportEmbeddings(userID *uuid.UUID, format string, w RowWriter) error {
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+
+	s.logger.Info("Exporting anonymized article embeddings")
+
+	if err := w.Write([]string{"article", "owner", "word_count", "created_at", "embedding"}); err != nil {
+		return err
+	}
+
+	count := 0
+	err := s.repo.StreamEmbeddings(userID, func(row *EmbeddingRow) error {
+		count++
+		return w.Write([]string{
+			s.anonymize(row.ArticleID),
+			s.anonymize(row.UserID),
+			strconv.Itoa(row.WordCount),
+			row.CreatedAt.UTC().Format(time.RFC3339),
+			row.Embedding,
+		})
+	})
+	if err != nil {
+		s.logger.Error("Failed to export article embeddings: " + err.Error())
+		return err
+	}
+
+	s.logger.Info("Exported " + strconv.Itoa(count) + " article embeddings")
+
+	return nil
+}
+
+func (s *service) ExportRatings(userID *uuid.UUID, format string, w RowWriter) error {
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+
+	s.logger.Info("Exporting anonymized rating matrix")
+
+	if err := w.Write([]string{"user", "article", "score", "created_at"}); err != nil {
+		return err
+	}
+
+	count := 0
+	err := s.repo.StreamRatings(userID, func(row *RatingRow) error {
+		count++
+		return w.Write([]string{
+			s.anonymize(row.UserID),
+			s.anonymize(row.ArticleID),
+			strconv.Itoa(row.Score),
+			row.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	})
+	if err != nil {
+		s.logger.Error("Failed to export ratings: " + err.Error())
+		return err
+	}
+
+	s.logger.Info("Exported " + strconv.Itoa(count) + " ratings")
+
+	return nil
+}
+
+// anonymize replaces an identifier with a keyed hash so rows can be joined
+// across exports without revealing the original IDs
+func (s *service) anonymize(id uuid.UUID) string {
+	mac := hmac.New(sha256.New, s.salt)
+	mac.Write(id[:])
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// checkFormat validates the requested format; Parquet needs an encoder this
+// service does not ship yet, so only CSV is produced
+func checkFormat(format string) error {
+	if format == FormatCSV {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+}
@@ -2,6 +2,7 @@ package rating
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/dustin/articles-backend/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -29,7 +30,7 @@ func (h *Handler) RateArticle(c *gin.Context) {
 	}
 
 	// Extract user ID from JWT token
-	userID, err := utils.GetUserIDFromToken(c)
+	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 		return
@@ -46,18 +47,9 @@ func (h *Handler) RateArticle(c *gin.Context) {
 		return
 	}
 
-	rating, err := h.service.RateArticle(userID, articleID, req.Score)
+	rating, err := h.service.RateArticle(userID, articleID, req.Score, req.Reaction)
 	if err != nil {
-		switch err.Error() {
-		case "article not found":
-			c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
-		default:
-			if err.Error()[:5] == "score" { // Score validation error
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rate article"})
-			}
-		}
+		utils.RespondError(c, err, "Failed to rate article")
 		return
 	}
 
@@ -67,7 +59,7 @@ func (h *Handler) RateArticle(c *gin.Context) {
 // GetRating handles getting a specific rating
 func (h *Handler) GetRating(c *gin.Context) {
 	// Extract user ID from JWT token
-	userID, err := utils.GetUserIDFromToken(c)
+	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 		return
@@ -96,7 +88,7 @@ func (h *Handler) GetRating(c *gin.Context) {
 // DeleteRating handles rating deletion
 func (h *Handler) DeleteRating(c *gin.Context) {
 	// Extract user ID from JWT token
-	userID, err := utils.GetUserIDFromToken(c)
+	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 		return
@@ -115,26 +107,180 @@ func (h *Handler) DeleteRating(c *gin.Context) {
 
 	err = h.service.DeleteRating(userID, articleID)
 	if err != nil {
-		if err.Error() == "rating not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Rating not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete rating"})
-		}
+		utils.RespondError(c, err, "Failed to delete rating")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Rating deleted successfully"})
 }
 
+// GetRatingStats handles summarizing the ratings of an article
+func (h *Handler) GetRatingStats(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	// Parse article ID from URL - supports both "articleId" and "id" params
+	articleIDParam := c.Param("articleId")
+	if articleIDParam == "" {
+		articleIDParam = c.Param("id")
+	}
+	articleID, err := uuid.Parse(articleIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	stats, err := h.service.GetRatingStats(userID, articleID)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to fetch rating stats")
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// ListRatings handles listing the user's ratings with their articles
+func (h *Handler) ListRatings(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	// Parse pagination parameters
+	query := HistoryQuery{Sort: c.Query("sort"), Page: 1, Limit: 20}
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			query.Page = parsed
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			query.Limit = parsed
+		}
+	}
+	switch c.Query("order") {
+	case "", "desc":
+	case "asc":
+		query.Ascending = true
+	default:
+		utils.RespondError(c, utils.NewValidationError("order", "must be 'asc' or 'desc'"), "Failed to list ratings")
+		return
+	}
+
+	ratings, total, err := h.service.ListRatings(userID, query)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to list ratings")
+		return
+	}
+
+	c.JSON(http.StatusOK, BuildHistoryResponse(ratings, total, query.Page, query.Limit))
+}
+
+// React handles adding a quick reaction to an article
+func (h *Handler) React(c *gin.Context) {
+	var req ReactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, articleID, ok := h.reactionTarget(c)
+	if !ok {
+		return
+	}
+
+	reactions, err := h.service.AddReaction(userID, articleID, req.Reaction)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to update reactions")
+		return
+	}
+
+	c.JSON(http.StatusOK, BuildReactionsResponse(articleID, reactions))
+}
+
+// RemoveReaction handles removing a quick reaction from an article
+func (h *Handler) RemoveReaction(c *gin.Context) {
+	userID, articleID, ok := h.reactionTarget(c)
+	if !ok {
+		return
+	}
+
+	reactions, err := h.service.RemoveReaction(userID, articleID, c.Param("reaction"))
+	if err != nil {
+		utils.RespondError(c, err, "Failed to update reactions")
+		return
+	}
+
+	c.JSON(http.StatusOK, BuildReactionsResponse(articleID, reactions))
+}
+
+// GetReactions handles listing the user's reactions to an article
+func (h *Handler) GetReactions(c *gin.Context) {
+	userID, articleID, ok := h.reactionTarget(c)
+	if !ok {
+		return
+	}
+
+	reactions, err := h.service.GetReactions(userID, articleID)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to update reactions")
+		return
+	}
+
+	c.JSON(http.StatusOK, BuildReactionsResponse(articleID, reactions))
+}
+
+// reactionTarget extracts the user and article of a reaction request, writing the error response on failure
+func (h *Handler) reactionTarget(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	// Parse article ID from URL - supports both "articleId" and "id" params
+	articleIDParam := c.Param("articleId")
+	if articleIDParam == "" {
+		articleIDParam = c.Param("id")
+	}
+	articleID, err := uuid.Parse(articleIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, articleID, true
+}
+
 // RegisterRoutes registers all rating routes
 func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
 	// All rating routes require authentication
 	ratings := router.Group("/ratings")
 	ratings.Use(authMiddleware)
 	{
+		// The user's rating history
+		ratings.GET("", h.ListRatings)
+
 		// Article-specific rating operations
 		ratings.POST("/articles/:articleId", h.RateArticle)
 		ratings.GET("/articles/:articleId", h.GetRating)
 		ratings.DELETE("/articles/:articleId", h.DeleteRating)
+		ratings.GET("/articles/:articleId/stats", h.GetRatingStats)
+
+		// Quick reactions, coexisting with the numeric score
+		ratings.GET("/articles/:articleId/reactions", h.GetReactions)
+		ratings.POST("/articles/:articleId/reactions", h.React)
+		ratings.DELETE("/articles/:articleId/reactions/:reaction", h.RemoveReaction)
+	}
+
+	articles := router.Group("/articles")
+	articles.Use(authMiddleware)
+	{
+		// Rating distribution, for charts shown next to the article
+		articles.GET("/:id/ratings/stats", h.GetRatingStats)
 	}
 }
@@ -1,16 +1,27 @@
 package rating
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/dustin/articles-backend/internal/utils"
 	"github.com/google/uuid"
 )
 
+// Errors returned by the rating service and repository
+var (
+	ErrNotFound         = utils.NewNotFoundError("rating not found")
+	ErrReactionNotFound = utils.NewNotFoundError("reaction not found")
+	ErrArticleNotFound  = utils.NewNotFoundError("article not found")
+)
+
 // Rating represents a user's rating of an article with optimized GORM relationships
 type Rating struct {
 	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey;not null;index:idx_user_ratings"`
 	ArticleID uuid.UUID `json:"article_id" gorm:"type:uuid;primaryKey;not null;index:idx_article_ratings"`
 	Score     int       `json:"score" gorm:"not null;check:score >= 1 AND score <= 5"`
+	Reaction  string    `json:"reaction,omitempty" gorm:"size:20;not null;default:''"` // Optional RatingReaction*, empty for none
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 
@@ -19,6 +30,96 @@ type Rating struct {
 	Article *Article `json:"article,omitempty" gorm:"foreignKey:ArticleID;constraint:OnDelete:CASCADE"`
 }
 
+// Reactions given with a rating, saying why the article got its score
+const (
+	RatingReactionLike       = "like"
+	RatingReactionInsightful = "insightful"
+	RatingReactionSkip       = "skip" // Not interested, whatever the score
+)
+
+// ParseRatingReaction validates the reaction given with a rating; empty means none
+func ParseRatingReaction(value string) (string, error) {
+	switch reaction := strings.ToLower(strings.TrimSpace(value)); reaction {
+	case "", RatingReactionLike, RatingReactionInsightful, RatingReactionSkip:
+		return reaction, nil
+	default:
+		return "", utils.NewValidationError("reaction", "must be one of like, insightful, skip, got '"+value+"'")
+	}
+}
+
+// Policies deciding which articles a user can rate and react to
+const (
+	PolicyOwn    = "own"    // Only the user's own articles
+	PolicyShared = "shared" // Also other users' articles with an active share link
+)
+
+// ParsePolicy parses a rating policy; empty means PolicyOwn
+func ParsePolicy(value string) (string, error) {
+	switch policy := strings.ToLower(strings.TrimSpace(value)); policy {
+	case "":
+		return PolicyOwn, nil
+	case PolicyOwn, PolicyShared:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid rating policy '%s': must be one of own, shared", value)
+	}
+}
+
+// Quick reaction kinds, stored by name
+const (
+	ReactionThumbsUp   = "thumbs_up"
+	ReactionThumbsDown = "thumbs_down"
+	ReactionHeart      = "heart"
+	ReactionBookmark   = "bookmark"
+)
+
+// reactionAliases maps emoji and names accepted from clients to reaction kinds
+var reactionAliases = map[string]string{
+	"👍":  ReactionThumbsUp,
+	"👎":  ReactionThumbsDown,
+	"❤️": ReactionHeart,
+	"❤":  ReactionHeart,
+	"🔖":  ReactionBookmark,
+
+	ReactionThumbsUp:   ReactionThumbsUp,
+	ReactionThumbsDown: ReactionThumbsDown,
+	ReactionHeart:      ReactionHeart,
+	ReactionBookmark:   ReactionBookmark,
+}
+
+// reactionEmoji maps reaction kinds back to the emoji shown to clients
+var reactionEmoji = map[string]string{
+	ReactionThumbsUp:   "👍",
+	ReactionThumbsDown: "👎",
+	ReactionHeart:      "❤️",
+	ReactionBookmark:   "🔖",
+}
+
+// Reaction is a one-tap feedback on an article. Reactions coexist with the numeric rating.
+type Reaction struct {
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey;not null;index:idx_user_reactions"`
+	ArticleID uuid.UUID `json:"article_id" gorm:"type:uuid;primaryKey;not null"`
+	Kind      string    `json:"kind" gorm:"primaryKey;size:20"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Associations (forward declarations)
+	User    *User    `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Article *Article `json:"article,omitempty" gorm:"foreignKey:ArticleID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for GORM
+func (Reaction) TableName() string {
+	return "reactions"
+}
+
+// ParseReaction converts an emoji or reaction name to a reaction kind
+func ParseReaction(value string) (string, error) {
+	if kind, ok := reactionAliases[strings.TrimSpace(value)]; ok {
+		return kind, nil
+	}
+	return "", utils.NewValidationError("reaction", "must be one of 👍 👎 ❤️ 🔖, got '"+value+"'")
+}
+
 // User represents user for foreign key relationship (forward declaration)
 type User struct {
 	ID    uuid.UUID `gorm:"type:uuid;primaryKey"`
@@ -27,10 +128,26 @@ type User struct {
 
 // Article represents article for foreign key relationship (forward declaration)
 type Article struct {
-	ID     uuid.UUID `gorm:"type:uuid;primaryKey"`
-	UserID uuid.UUID `gorm:"type:uuid;not null"`
-	Title  string
-	URL    string
+	ID       uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID   uuid.UUID `gorm:"type:uuid;not null"`
+	Title    string
+	URL      string
+	SiteName string
+	ImageURL string
+}
+
+// Sort orders of a user's rating history
+const (
+	SortByDate  = "date"  // When the article was last rated
+	SortByScore = "score" // Then by date
+)
+
+// HistoryQuery selects a page of a user's rating history
+type HistoryQuery struct {
+	Sort      string // SortByDate or SortByScore
+	Ascending bool
+	Page      int
+	Limit     int
 }
 
 // Repository defines the interface for rating data access
@@ -39,16 +156,36 @@ type Repository interface {
 	FindByUserAndArticle(userID, articleID uuid.UUID) (*Rating, error)
 	Update(rating *Rating) error
 	Delete(userID, articleID uuid.UUID) error
+	// FindByUserID returns a page of the user's ratings with their articles
+	// preloaded, skipping articles in the trash, and the total count
+	FindByUserID(userID uuid.UUID, sort string, ascending bool, offset, limit int) ([]*Rating, int64, error)
 
 	// Analytics method for recommendations
 	GetAverageRating(articleID uuid.UUID) (float64, int, error)
+	// CountByScore returns how many ratings of the article gave each score;
+	// scores nobody gave are absent
+	CountByScore(articleID uuid.UUID) (map[int]int64, error)
+
+	// Quick reactions
+	AddReaction(reaction *Reaction) error
+	RemoveReaction(userID, articleID uuid.UUID, kind string) error
+	FindReactions(userID, articleID uuid.UUID) ([]*Reaction, error)
 }
 
 // Service defines the interface for rating business logic
 type Service interface {
-	RateArticle(userID, articleID uuid.UUID, score int) (*Rating, error)
+	// RateArticle sets the user's score and optional reaction for the article,
+	// replacing both if it was rated before
+	RateArticle(userID, articleID uuid.UUID, score int, reaction string) (*Rating, error)
 	GetRating(userID, articleID uuid.UUID) (*Rating, error)
 	DeleteRating(userID, articleID uuid.UUID) error
+	// GetRatingStats summarizes the ratings of an article the user owns
+	GetRatingStats(userID, articleID uuid.UUID) (*RatingStatsResponse, error)
+	// ListRatings returns a page of the user's ratings, newest first by default
+	ListRatings(userID uuid.UUID, query HistoryQuery) ([]*Rating, int64, error)
+	AddReaction(userID, articleID uuid.UUID, reaction string) ([]*Reaction, error)
+	RemoveReaction(userID, articleID uuid.UUID, reaction string) ([]*Reaction, error)
+	GetReactions(userID, articleID uuid.UUID) ([]*Reaction, error)
 }
 
 // ArticleService interface for article validation
@@ -56,9 +193,42 @@ type ArticleService interface {
 	GetArticle(id uuid.UUID, userID uuid.UUID) (*Article, error)
 }
 
+// SharedArticles reports which articles their owners made shareable, so
+// other users can rate them under PolicyShared (dependency inversion)
+type SharedArticles interface {
+	IsShared(articleID uuid.UUID) (bool, error)
+}
+
 // RateArticleRequest represents rating creation/update request
 type RateArticleRequest struct {
-	Score int `json:"score" binding:"required,min=1,max=5"`
+	Score    int    `json:"score" binding:"required,min=1,max=5"`
+	Reaction string `json:"reaction"` // Optional: like, insightful or skip
+}
+
+// ReactRequest represents a quick reaction request
+type ReactRequest struct {
+	Reaction string `json:"reaction" binding:"required"`
+}
+
+// ReactionsResponse lists a user's reactions to an article
+type ReactionsResponse struct {
+	ArticleID uuid.UUID `json:"article_id"`
+	Reactions []string  `json:"reactions"` // Reaction kinds, e.g. "thumbs_up"
+	Emoji     []string  `json:"emoji"`     // The same reactions as emoji
+}
+
+// BuildReactionsResponse converts reactions to ReactionsResponse
+func BuildReactionsResponse(articleID uuid.UUID, reactions []*Reaction) *ReactionsResponse {
+	response := &ReactionsResponse{
+		ArticleID: articleID,
+		Reactions: make([]string, 0, len(reactions)),
+		Emoji:     make([]string, 0, len(reactions)),
+	}
+	for _, reaction := range reactions {
+		response.Reactions = append(response.Reactions, reaction.Kind)
+		response.Emoji = append(response.Emoji, reactionEmoji[reaction.Kind])
+	}
+	return response
 }
 
 // RatingResponse represents rating in API responses
@@ -66,6 +236,7 @@ type RatingResponse struct {
 	UserID    uuid.UUID `json:"user_id"`
 	ArticleID uuid.UUID `json:"article_id"`
 	Score     int       `json:"score"`
+	Reaction  string    `json:"reaction,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -76,11 +247,94 @@ func (r *Rating) ToResponse() *RatingResponse {
 		UserID:    r.UserID,
 		ArticleID: r.ArticleID,
 		Score:     r.Score,
+		Reaction:  r.Reaction,
 		CreatedAt: r.CreatedAt,
 		UpdatedAt: r.UpdatedAt,
 	}
 }
 
+// ArticleSummary is the part of a rated article shown in rating history
+type ArticleSummary struct {
+	ID       uuid.UUID `json:"id"`
+	Title    string    `json:"title"`
+	URL      string    `json:"url"`
+	SiteName string    `json:"site_name"`
+	ImageURL string    `json:"image_url"`
+}
+
+// RatingHistoryItem represents a rating with its article
+type RatingHistoryItem struct {
+	Score     int             `json:"score"`
+	Reaction  string          `json:"reaction,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Article   *ArticleSummary `json:"article"`
+}
+
+// RatingHistoryResponse represents a paginated page of a user's ratings
+type RatingHistoryResponse struct {
+	Ratings []*RatingHistoryItem `json:"ratings"`
+	Total   int64                `json:"total"`
+	Page    int                  `json:"page"`
+	Limit   int                  `json:"limit"`
+	Pages   int                  `json:"pages"`
+}
+
+// BuildHistoryResponse creates a paginated response of ratings with their
+// preloaded articles
+func BuildHistoryResponse(ratings []*Rating, total int64, page, limit int) *RatingHistoryResponse {
+	items := make([]*RatingHistoryItem, 0, len(ratings))
+	for _, rating := range ratings {
+		item := &RatingHistoryItem{
+			Score:     rating.Score,
+			Reaction:  rating.Reaction,
+			CreatedAt: rating.CreatedAt,
+			UpdatedAt: rating.UpdatedAt,
+			Article:   &ArticleSummary{ID: rating.ArticleID},
+		}
+		if rating.Article != nil {
+			item.Article.Title = rating.Article.Title
+			item.Article.URL = rating.Article.URL
+			item.Article.SiteName = rating.Article.SiteName
+			item.Article.ImageURL = rating.Article.ImageURL
+		}
+		items = append(items, item)
+	}
+
+	pagination := utils.CalculatePagination(total, page, limit)
+
+	return &RatingHistoryResponse{
+		Ratings: items,
+		Total:   pagination.Total,
+		Page:    pagination.Page,
+		Limit:   pagination.Limit,
+		Pages:   pagination.Pages,
+	}
+}
+
+// RatingStatsResponse summarizes the ratings of an article
+type RatingStatsResponse struct {
+	ArticleID uuid.UUID     `json:"article_id"`
+	Average   float64       `json:"average"` // 0 without ratings
+	Count     int           `json:"count"`
+	Histogram map[int]int64 `json:"histogram"` // Ratings per score, every score from 1 to 5
+}
+
+// BuildStatsResponse creates a rating summary, filling in scores nobody gave
+func BuildStatsResponse(articleID uuid.UUID, average float64, count int, scores map[int]int64) *RatingStatsResponse {
+	histogram := make(map[int]int64, 5)
+	for score := 1; score <= 5; score++ {
+		histogram[score] = scores[score]
+	}
+
+	return &RatingStatsResponse{
+		ArticleID: articleID,
+		Average:   average,
+		Count:     count,
+		Histogram: histogram,
+	}
+}
+
 // IsValidScore checks if the score is within valid range
 func (r *Rating) IsValidScore() bool {
 	return r.Score >= 1 && r.Score <= 5
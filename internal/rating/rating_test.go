@@ -1,11 +1,17 @@
 package rating
 
 import (
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/events"
+	"github.com/dustin/articles-backend/pkg/logger"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRating(t *testing.T) {
@@ -113,3 +119,362 @@ func TestRateArticleRequest(t *testing.T) {
 		assert.True(t, req.Score >= 1 && req.Score <= 5)
 	})
 }
+
+func TestReaction(t *testing.T) {
+	t.Run("Parse emoji and names", func(t *testing.T) {
+		cases := map[string]string{
+			"👍":          ReactionThumbsUp,
+			"👎":          ReactionThumbsDown,
+			"❤️":         ReactionHeart,
+			"❤":          ReactionHeart,
+			"🔖":          ReactionBookmark,
+			"thumbs_up":  ReactionThumbsUp,
+			" bookmark ": ReactionBookmark,
+		}
+		for input, expected := range cases {
+			kind, err := ParseReaction(input)
+			assert.NoError(t, err, input)
+			assert.Equal(t, expected, kind, input)
+		}
+	})
+
+	t.Run("Reject unknown reactions", func(t *testing.T) {
+		_, err := ParseReaction("🎉")
+		assert.ErrorIs(t, err, utils.ErrValidation)
+	})
+
+	t.Run("Build response", func(t *testing.T) {
+		articleID := uuid.New()
+		response := BuildReactionsResponse(articleID, []*Reaction{
+			{ArticleID: articleID, Kind: ReactionHeart},
+			{ArticleID: articleID, Kind: ReactionBookmark},
+		})
+
+		assert.Equal(t, []string{ReactionHeart, ReactionBookmark}, response.Reactions)
+		assert.Equal(t, []string{"❤️", "🔖"}, response.Emoji)
+		assert.Equal(t, "reactions", Reaction{}.TableName())
+	})
+
+	t.Run("Empty response", func(t *testing.T) {
+		response := BuildReactionsResponse(uuid.New(), nil)
+		assert.NotNil(t, response.Reactions)
+		assert.Empty(t, response.Reactions)
+	})
+}
+
+// memoryRepository keeps ratings and reactions in memory
+type memoryRepository struct {
+	Repository
+	ratings   map[uuid.UUID]*Rating
+	reactions []*Reaction
+}
+
+func (r *memoryRepository) Create(rating *Rating) error {
+	r.ratings[rating.ArticleID] = rating
+	return nil
+}
+
+func (r *memoryRepository) FindByUserAndArticle(userID, articleID uuid.UUID) (*Rating, error) {
+	if rating, ok := r.ratings[articleID]; ok && rating.UserID == userID {
+		return rating, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryRepository) Update(rating *Rating) error { return nil }
+
+func (r *memoryRepository) Delete(userID, articleID uuid.UUID) error {
+	delete(r.ratings, articleID)
+	return nil
+}
+
+func (r *memoryRepository) AddReaction(reaction *Reaction) error {
+	r.reactions = append(r.reactions, reaction)
+	return nil
+}
+
+func (r *memoryRepository) RemoveReaction(userID, articleID uuid.UUID, kind string) error {
+	return ErrReactionNotFound
+}
+
+func (r *memoryRepository) FindReactions(userID, articleID uuid.UUID) ([]*Reaction, error) {
+	return r.reactions, nil
+}
+
+type ownedArticles struct{}
+
+func (ownedArticles) GetArticle(id, userID uuid.UUID) (*Article, error) {
+	return &Article{ID: id, UserID: userID}, nil
+}
+
+// newTestService creates a service with the default own-articles policy
+func newTestService(t *testing.T, repo Repository, articles ArticleService, publisher events.Publisher, log *logger.Logger) Service {
+	svc, err := NewService(nil, repo, articles, nil, publisher, log)
+	require.NoError(t, err)
+	return svc
+}
+
+// eventRecorder records the events published by the service
+type eventRecorder struct {
+	events []*events.RatingChanged
+}
+
+func (r *eventRecorder) Publish(event events.Event) {
+	r.events = append(r.events, event.(*events.RatingChanged))
+}
+
+// names returns the names of the recorded events
+func (r *eventRecorder) names() []string {
+	names := make([]string, 0, len(r.events))
+	for _, event := range r.events {
+		names = append(names, event.Name())
+	}
+	return names
+}
+
+// liked returns the articles of recorded events that count as likes
+func (r *eventRecorder) liked() []uuid.UUID {
+	var liked []uuid.UUID
+	for _, event := range r.events {
+		if event.Liked {
+			liked = append(liked, event.ArticleID)
+		}
+	}
+	return liked
+}
+
+func TestChangeEvents(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	recorder := &eventRecorder{}
+	svc := newTestService(t, &memoryRepository{ratings: make(map[uuid.UUID]*Rating)}, ownedArticles{}, recorder, log)
+	userID, articleID := uuid.New(), uuid.New()
+
+	_, err = svc.RateArticle(userID, articleID, 4, "")
+	require.NoError(t, err)
+	_, err = svc.RateArticle(userID, articleID, 5, "")
+	require.NoError(t, err)
+	_, err = svc.AddReaction(userID, articleID, ReactionThumbsUp)
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteRating(userID, articleID))
+	assert.Equal(t, []string{events.RatingCreated, events.RatingUpdated, events.ReactionAdded, events.RatingDeleted}, recorder.names())
+	assert.Equal(t, []uuid.UUID{articleID, articleID, articleID}, recorder.liked())
+	assert.Equal(t, userID, recorder.events[1].UserID)
+	assert.Equal(t, 5, recorder.events[1].Score)
+
+	// Failed changes are not published
+	_, err = svc.RateArticle(userID, articleID, 9, "")
+	assert.Error(t, err)
+	_, err = svc.RemoveReaction(userID, articleID, ReactionThumbsDown)
+	assert.Error(t, err)
+	assert.Len(t, recorder.events, 4)
+
+	// Low ratings and thumbs down are not likes
+	_, err = svc.RateArticle(userID, articleID, 2, "")
+	require.NoError(t, err)
+	_, err = svc.AddReaction(userID, articleID, ReactionThumbsDown)
+	require.NoError(t, err)
+	assert.Len(t, recorder.liked(), 3)
+
+	// The publisher is optional
+	_, err = newTestService(t, &memoryRepository{ratings: make(map[uuid.UUID]*Rating)}, ownedArticles{}, nil, log).RateArticle(userID, articleID, 3, "")
+	assert.NoError(t, err)
+}
+
+// historyRepository returns fixed ratings and records the page asked for
+type historyRepository struct {
+	Repository
+	ratings   []*Rating
+	sort      string
+	ascending bool
+	offset    int
+	limit     int
+}
+
+func (r *historyRepository) FindByUserID(userID uuid.UUID, sort string, ascending bool, offset, limit int) ([]*Rating, int64, error) {
+	r.sort, r.ascending, r.offset, r.limit = sort, ascending, offset, limit
+	return r.ratings, int64(len(r.ratings)), nil
+}
+
+func TestListRatings(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	userID, articleID := uuid.New(), uuid.New()
+	repo := &historyRepository{ratings: []*Rating{
+		{UserID: userID, ArticleID: articleID, Score: 4, Article: &Article{ID: articleID, Title: "Go generics", URL: "https://example.com/go", SiteName: "Example"}},
+		{UserID: userID, ArticleID: uuid.New(), Score: 2},
+	}}
+	svc := newTestService(t, repo, ownedArticles{}, nil, log)
+
+	t.Run("Defaults to the newest first", func(t *testing.T) {
+		ratings, total, err := svc.ListRatings(userID, HistoryQuery{})
+		require.NoError(t, err)
+		assert.Len(t, ratings, 2)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, SortByDate, repo.sort)
+		assert.False(t, repo.ascending)
+		assert.Equal(t, 0, repo.offset)
+		assert.Equal(t, 20, repo.limit)
+	})
+
+	t.Run("Pages by score", func(t *testing.T) {
+		_, _, err := svc.ListRatings(userID, HistoryQuery{Sort: SortByScore, Ascending: true, Page: 3, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, SortByScore, repo.sort)
+		assert.True(t, repo.ascending)
+		assert.Equal(t, 20, repo.offset)
+		assert.Equal(t, 10, repo.limit)
+	})
+
+	t.Run("Rejects unknown sorts", func(t *testing.T) {
+		_, _, err := svc.ListRatings(userID, HistoryQuery{Sort: "title"})
+		assert.ErrorIs(t, err, utils.ErrValidation)
+	})
+
+	t.Run("Builds article summaries", func(t *testing.T) {
+		response := BuildHistoryResponse(repo.ratings, 25, 2, 10)
+		require.Len(t, response.Ratings, 2)
+		assert.Equal(t, "Go generics", response.Ratings[0].Article.Title)
+		assert.Equal(t, "Example", response.Ratings[0].Article.SiteName)
+		assert.Equal(t, repo.ratings[1].ArticleID, response.Ratings[1].Article.ID)
+		assert.Equal(t, 3, response.Pages)
+	})
+}
+
+// statsRepository returns fixed rating aggregates
+type statsRepository struct {
+	Repository
+	scores map[int]int64
+}
+
+func (r *statsRepository) GetAverageRating(articleID uuid.UUID) (float64, int, error) {
+	var total, count int64
+	for score, n := range r.scores {
+		total += int64(score) * n
+		count += n
+	}
+	if count == 0 {
+		return 0, 0, nil
+	}
+	return float64(total) / float64(count), int(count), nil
+}
+
+func (r *statsRepository) CountByScore(articleID uuid.UUID) (map[int]int64, error) {
+	return r.scores, nil
+}
+
+// missingArticles finds no article
+type missingArticles struct{}
+
+func (missingArticles) GetArticle(id, userID uuid.UUID) (*Article, error) {
+	return nil, errors.New("article not found")
+}
+
+func TestGetRatingStats(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+	userID, articleID := uuid.New(), uuid.New()
+
+	t.Run("Fills in every score", func(t *testing.T) {
+		svc := newTestService(t, &statsRepository{scores: map[int]int64{4: 1, 5: 3}}, ownedArticles{}, nil, log)
+
+		stats, err := svc.GetRatingStats(userID, articleID)
+		require.NoError(t, err)
+		assert.Equal(t, articleID, stats.ArticleID)
+		assert.Equal(t, 4.75, stats.Average)
+		assert.Equal(t, 4, stats.Count)
+		assert.Equal(t, map[int]int64{1: 0, 2: 0, 3: 0, 4: 1, 5: 3}, stats.Histogram)
+	})
+
+	t.Run("Unrated articles", func(t *testing.T) {
+		svc := newTestService(t, &statsRepository{}, ownedArticles{}, nil, log)
+
+		stats, err := svc.GetRatingStats(userID, articleID)
+		require.NoError(t, err)
+		assert.Zero(t, stats.Count)
+		assert.Len(t, stats.Histogram, 5)
+	})
+
+	t.Run("Other users' articles", func(t *testing.T) {
+		svc := newTestService(t, &statsRepository{}, missingArticles{}, nil, log)
+
+		_, err := svc.GetRatingStats(userID, articleID)
+		assert.ErrorIs(t, err, ErrArticleNotFound)
+	})
+}
+
+// sharedArticles reports the listed articles as shared
+type sharedArticles map[uuid.UUID]bool
+
+func (s sharedArticles) IsShared(articleID uuid.UUID) (bool, error) {
+	return s[articleID], nil
+}
+
+func TestRatingPolicy(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+	userID, sharedID, privateID := uuid.New(), uuid.New(), uuid.New()
+	shared := sharedArticles{sharedID: true}
+
+	t.Run("Rejects unknown policies", func(t *testing.T) {
+		_, err := NewService(&config.RatingConfig{Policy: "everyone"}, &memoryRepository{}, missingArticles{}, shared, nil, log)
+		assert.Error(t, err)
+
+		_, err = NewService(&config.RatingConfig{Policy: PolicyShared}, &memoryRepository{}, missingArticles{}, nil, nil, log)
+		assert.Error(t, err, "the shared policy needs share links")
+	})
+
+	t.Run("Own articles only", func(t *testing.T) {
+		svc, err := NewService(&config.RatingConfig{Policy: PolicyOwn}, &memoryRepository{ratings: make(map[uuid.UUID]*Rating)}, missingArticles{}, shared, nil, log)
+		require.NoError(t, err)
+
+		_, err = svc.RateArticle(userID, sharedID, 4, "")
+		assert.ErrorIs(t, err, ErrArticleNotFound)
+	})
+
+	t.Run("Shared articles of other users", func(t *testing.T) {
+		svc, err := NewService(&config.RatingConfig{Policy: "Shared"}, &memoryRepository{ratings: make(map[uuid.UUID]*Rating)}, missingArticles{}, shared, nil, log)
+		require.NoError(t, err)
+
+		rating, err := svc.RateArticle(userID, sharedID, 4, "")
+		require.NoError(t, err)
+		assert.Equal(t, sharedID, rating.ArticleID)
+		_, err = svc.AddReaction(userID, sharedID, ReactionHeart)
+		assert.NoError(t, err)
+
+		_, err = svc.RateArticle(userID, privateID, 4, "")
+		assert.ErrorIs(t, err, ErrArticleNotFound)
+		_, err = svc.GetReactions(userID, privateID)
+		assert.ErrorIs(t, err, ErrArticleNotFound)
+	})
+}
+
+func TestRatingReaction(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+	userID, articleID := uuid.New(), uuid.New()
+
+	recorder := &eventRecorder{}
+	svc := newTestService(t, &memoryRepository{ratings: make(map[uuid.UUID]*Rating)}, ownedArticles{}, recorder, log)
+
+	_, err = svc.RateArticle(userID, articleID, 3, "meh")
+	assert.ErrorIs(t, err, utils.ErrValidation)
+
+	rating, err := svc.RateArticle(userID, articleID, 3, " Insightful ")
+	require.NoError(t, err)
+	assert.Equal(t, RatingReactionInsightful, rating.Reaction)
+	assert.Equal(t, RatingReactionInsightful, rating.ToResponse().Reaction)
+
+	rating, err = svc.RateArticle(userID, articleID, 5, RatingReactionSkip)
+	require.NoError(t, err)
+	assert.Equal(t, RatingReactionSkip, rating.Reaction)
+
+	rating, err = svc.RateArticle(userID, articleID, 2, "")
+	require.NoError(t, err)
+	assert.Empty(t, rating.Reaction, "rating again replaces the reaction")
+
+	// An insightful 3 counts as liked; a skipped 5 does not
+	assert.Equal(t, []uuid.UUID{articleID}, recorder.liked())
+}
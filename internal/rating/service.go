@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/dustin/articles-backend/config"
 	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/events"
 	"github.com/dustin/articles-backend/pkg/logger"
 	"github.com/google/uuid"
 )
@@ -14,40 +16,103 @@ import (
 type service struct {
 	repo           Repository
 	articleService ArticleService
+	shared         SharedArticles
+	publisher      events.Publisher
+	policy         string
 	logger         *logger.Logger
 }
 
-// NewService creates a new rating service
-func NewService(repo Repository, articleService ArticleService, log *logger.Logger) Service {
+// NewService creates a new rating service. Changes to ratings and reactions
+// are published as events.RatingChanged; publisher may be nil. shared is
+// required by PolicyShared only.
+func NewService(cfg *config.RatingConfig, repo Repository, articleService ArticleService, shared SharedArticles, publisher events.Publisher, log *logger.Logger) (Service, error) {
+	policy := PolicyOwn
+	if cfg != nil {
+		var err error
+		if policy, err = ParsePolicy(cfg.Policy); err != nil {
+			return nil, err
+		}
+	}
+	if policy == PolicyShared && shared == nil {
+		return nil, fmt.Errorf("invalid rating policy '%s': requires share links", cfg.Policy)
+	}
+
 	return &service{
 		repo:           repo,
 		articleService: articleService,
+		shared:         shared,
+		publisher:      publisher,
+		policy:         policy,
 		logger:         log.WithComponent("rating-service"),
+	}, nil
+}
+
+// checkAccess verifies the article exists and the user may rate it: their
+// own articles always, and shared ones of other users under PolicyShared
+func (s *service) checkAccess(userID, articleID uuid.UUID) error {
+	_, err := s.articleService.GetArticle(articleID, userID)
+	if err == nil {
+		return nil
+	}
+	if s.policy == PolicyShared {
+		shared, sharedErr := s.shared.IsShared(articleID)
+		if sharedErr != nil {
+			return sharedErr
+		}
+		if shared {
+			return nil
+		}
 	}
+	s.logger.Info("Article not found or access denied " + articleID.String() + " for user " + userID.String() + ": " + err.Error())
+	return ErrArticleNotFound
 }
 
-func (s *service) RateArticle(userID, articleID uuid.UUID, score int) (*Rating, error) {
+// publish announces a change to the user's ratings or reactions
+func (s *service) publish(event *events.RatingChanged) {
+	if s.publisher != nil {
+		s.publisher.Publish(event)
+	}
+}
+
+// likedScore is the lowest rating that counts as liking the article
+const likedScore = 4
+
+// likes reports whether a rating counts as liking the article: a score of
+// likedScore or more, or a like or insightful reaction, unless it is skipped
+func likes(score int, reaction string) bool {
+	switch reaction {
+	case RatingReactionSkip:
+		return false
+	case RatingReactionLike, RatingReactionInsightful:
+		return true
+	}
+	return score >= likedScore
+}
+
+func (s *service) RateArticle(userID, articleID uuid.UUID, score int, reaction string) (*Rating, error) {
 	s.logger.Info("Rating article " + articleID.String() + " by user " + userID.String() + " with score " + utils.IntToString(score))
 
 	// Validate score
 	if score < 1 || score > 5 {
 		s.logger.Error("Invalid rating score " + utils.IntToString(score) + " for article " + articleID.String() + " by user " + userID.String())
-		return nil, fmt.Errorf("score must be between 1 and 5, got %d", score)
+		return nil, utils.NewValidationError("score", "must be between 1 and 5, got "+utils.IntToString(score))
 	}
-
-	// Verify article exists and user ownership
-	_, err := s.articleService.GetArticle(articleID, userID)
+	reaction, err := ParseRatingReaction(reaction)
 	if err != nil {
-		s.logger.Error("Article not found or access denied " + articleID.String() + " for user " + userID.String() + ": " + err.Error())
-		return nil, errors.New("article not found")
+		return nil, err
+	}
+
+	// Verify article exists and the user may rate it
+	if err := s.checkAccess(userID, articleID); err != nil {
+		return nil, err
 	}
 
 	// Check if rating already exists
-	_, err = s.repo.FindByUserAndArticle(userID, articleID)
+	existingRating, err := s.repo.FindByUserAndArticle(userID, articleID)
 	if err == nil {
 		// Rating already exists, update it inline
-		existingRating, _ := s.repo.FindByUserAndArticle(userID, articleID)
 		existingRating.Score = score
+		existingRating.Reaction = reaction
 		existingRating.UpdatedAt = time.Now()
 
 		if updateErr := s.repo.Update(existingRating); updateErr != nil {
@@ -56,6 +121,7 @@ func (s *service) RateArticle(userID, articleID uuid.UUID, score int) (*Rating,
 		}
 
 		s.logger.Info("Rating updated successfully for article " + articleID.String() + " by user " + userID.String() + " score " + utils.IntToString(score))
+		s.publish(&events.RatingChanged{Type: events.RatingUpdated, UserID: userID, ArticleID: articleID, Score: score, Reaction: reaction, Liked: likes(score, reaction)})
 		return existingRating, nil
 	}
 
@@ -64,6 +130,7 @@ func (s *service) RateArticle(userID, articleID uuid.UUID, score int) (*Rating,
 		UserID:    userID,
 		ArticleID: articleID,
 		Score:     score,
+		Reaction:  reaction,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -74,6 +141,7 @@ func (s *service) RateArticle(userID, articleID uuid.UUID, score int) (*Rating,
 	}
 
 	s.logger.Info("Rating created successfully for article " + articleID.String() + " by user " + userID.String() + " score " + utils.IntToString(score))
+	s.publish(&events.RatingChanged{Type: events.RatingCreated, UserID: userID, ArticleID: articleID, Score: score, Reaction: reaction, Liked: likes(score, reaction)})
 
 	return rating, nil
 }
@@ -82,19 +150,63 @@ func (s *service) GetRating(userID, articleID uuid.UUID) (*Rating, error) {
 	rating, err := s.repo.FindByUserAndArticle(userID, articleID)
 	if err != nil {
 		s.logger.Info("Rating not found for article " + articleID.String() + " by user " + userID.String())
-		return nil, errors.New("rating not found")
+		return nil, ErrNotFound
 	}
 
 	return rating, nil
 }
 
+func (s *service) GetRatingStats(userID, articleID uuid.UUID) (*RatingStatsResponse, error) {
+	// Verify article exists and the user may rate it
+	if err := s.checkAccess(userID, articleID); err != nil {
+		return nil, err
+	}
+
+	average, count, err := s.repo.GetAverageRating(articleID)
+	if err != nil {
+		s.logger.Error("Failed to average ratings of article " + articleID.String() + ": " + err.Error())
+		return nil, err
+	}
+	scores, err := s.repo.CountByScore(articleID)
+	if err != nil {
+		s.logger.Error("Failed to count ratings of article " + articleID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	return BuildStatsResponse(articleID, average, count, scores), nil
+}
+
+func (s *service) ListRatings(userID uuid.UUID, query HistoryQuery) ([]*Rating, int64, error) {
+	switch query.Sort {
+	case "":
+		query.Sort = SortByDate
+	case SortByDate, SortByScore:
+	default:
+		return nil, 0, utils.NewValidationError("sort", "must be '"+SortByDate+"' or '"+SortByScore+"', got '"+query.Sort+"'")
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 || query.Limit > 100 {
+		query.Limit = 20
+	}
+
+	ratings, total, err := s.repo.FindByUserID(userID, query.Sort, query.Ascending, (query.Page-1)*query.Limit, query.Limit)
+	if err != nil {
+		s.logger.Error("Failed to list ratings of user " + userID.String() + ": " + err.Error())
+		return nil, 0, err
+	}
+
+	return ratings, total, nil
+}
+
 func (s *service) DeleteRating(userID, articleID uuid.UUID) error {
 	s.logger.Info("Deleting rating for article " + articleID.String() + " by user " + userID.String())
 
 	// Verify rating exists
 	_, err := s.repo.FindByUserAndArticle(userID, articleID)
 	if err != nil {
-		return errors.New("rating not found")
+		return ErrNotFound
 	}
 
 	if err := s.repo.Delete(userID, articleID); err != nil {
@@ -103,6 +215,76 @@ func (s *service) DeleteRating(userID, articleID uuid.UUID) error {
 	}
 
 	s.logger.Info("Rating deleted successfully for article " + articleID.String() + " by user " + userID.String())
+	s.publish(&events.RatingChanged{Type: events.RatingDeleted, UserID: userID, ArticleID: articleID})
 
 	return nil
 }
+
+// opposingReactions lists reactions that cancel each other out
+var opposingReactions = map[string]string{
+	ReactionThumbsUp:   ReactionThumbsDown,
+	ReactionThumbsDown: ReactionThumbsUp,
+}
+
+func (s *service) AddReaction(userID, articleID uuid.UUID, reaction string) ([]*Reaction, error) {
+	kind, err := ParseReaction(reaction)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Adding reaction " + kind + " to article " + articleID.String() + " by user " + userID.String())
+
+	// Verify article exists and the user may react to it
+	if err := s.checkAccess(userID, articleID); err != nil {
+		return nil, err
+	}
+
+	// A thumbs up replaces a thumbs down and vice versa
+	if opposite, ok := opposingReactions[kind]; ok {
+		if err := s.repo.RemoveReaction(userID, articleID, opposite); err != nil && !errors.Is(err, ErrReactionNotFound) {
+			return nil, err
+		}
+	}
+
+	err = s.repo.AddReaction(&Reaction{
+		UserID:    userID,
+		ArticleID: articleID,
+		Kind:      kind,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		s.logger.Error("Failed to add reaction " + kind + " to article " + articleID.String() + " by user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+	s.publish(&events.RatingChanged{Type: events.ReactionAdded, UserID: userID, ArticleID: articleID, Reaction: kind, Liked: kind != ReactionThumbsDown})
+
+	return s.repo.FindReactions(userID, articleID)
+}
+
+func (s *service) RemoveReaction(userID, articleID uuid.UUID, reaction string) ([]*Reaction, error) {
+	kind, err := ParseReaction(reaction)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Removing reaction " + kind + " from article " + articleID.String() + " by user " + userID.String())
+
+	if err := s.repo.RemoveReaction(userID, articleID, kind); err != nil {
+		if !errors.Is(err, ErrReactionNotFound) {
+			s.logger.Error("Failed to remove reaction " + kind + " from article " + articleID.String() + " by user " + userID.String() + ": " + err.Error())
+		}
+		return nil, err
+	}
+	s.publish(&events.RatingChanged{Type: events.ReactionRemoved, UserID: userID, ArticleID: articleID, Reaction: kind})
+
+	return s.repo.FindReactions(userID, articleID)
+}
+
+func (s *service) GetReactions(userID, articleID uuid.UUID) ([]*Reaction, error) {
+	// Verify article exists and the user may rate it
+	if err := s.checkAccess(userID, articleID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindReactions(userID, articleID)
+}
@@ -0,0 +1,245 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// BanditArm is what a user's feedback says about one recommendation source:
+// how many of its recommendations they were shown and how many they acted on
+type BanditArm struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Engine    string    `gorm:"size:30;primaryKey"` // Name of the source engine
+	Shown     int64     `gorm:"not null;default:0"`
+	Rewards   int64     `gorm:"not null;default:0"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for GORM
+func (BanditArm) TableName() string {
+	return "recommendation_arms"
+}
+
+// Impression records which source recommended an article to a user, so later
+// feedback on the article, or on the user's own copy of its link, is credited
+// to that source once
+type Impression struct {
+	UserID     uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	ArticleID  uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	URL        string     `gorm:"not null;size:2048"`
+	Engine     string     `gorm:"size:30;not null"`
+	ShownAt    time.Time  `gorm:"not null;index"`
+	RewardedAt *time.Time // Set once feedback was credited
+}
+
+// TableName returns the table name for GORM
+func (Impression) TableName() string {
+	return "recommendation_impressions"
+}
+
+// BanditRepository stores the per-user statistics of the bandit engine and
+// the events clients report on recommendations
+type BanditRepository interface {
+	FindArms(userID uuid.UUID) ([]*BanditArm, error)
+	// RecordImpressions stores the impressions, replacing earlier ones of the
+	// same articles, and counts them as shown for their engines
+	RecordImpressions(userID uuid.UUID, impressions []*Impression) error
+	// RewardImpression credits the engines that recommended the article, or
+	// another copy of its link, to the user since the given time. It returns
+	// false when there is no such impression or each was credited before.
+	RewardImpression(userID, articleID uuid.UUID, since time.Time) (bool, error)
+
+	// Impressions and clicks reported by clients, for click-through rates
+	RecordEvents(events []*Event) error
+	// CountEvents counts the impressions and clicks of each engine since the
+	// given time, by engine name
+	CountEvents(since time.Time) ([]*EngineCTR, error)
+	DeleteEventsBefore(createdAt time.Time) error
+
+	// WithContext returns a repository whose queries are bound to ctx
+	WithContext(ctx context.Context) BanditRepository
+}
+
+// impressionRewardWindow is how long after a recommendation feedback on it
+// still counts
+const impressionRewardWindow = 30 * 24 * time.Hour
+
+// BanditEngine shares the slots of a list between several engines as an
+// upper-confidence-bound bandit. Each slot goes to the engine whose
+// recommendations the user acted on most, plus a bonus for engines shown less,
+// so engines with little feedback keep being tried.
+type BanditEngine struct {
+	engines []Engine
+	repo    BanditRepository // nil shares slots without learning
+	logger  *logger.Logger
+}
+
+// NewBanditEngine creates an engine allocating slots between the given
+// engines; earlier engines win ties
+func NewBanditEngine(engines []Engine, repo BanditRepository, log *logger.Logger) *BanditEngine {
+	return &BanditEngine{
+		engines: engines,
+		repo:    repo,
+		logger:  log.WithComponent("bandit-engine"),
+	}
+}
+
+func (b *BanditEngine) Name() string {
+	return "bandit"
+}
+
+// banditState is an engine's arm while a list is being filled
+type banditState struct {
+	shown   float64
+	rewards float64
+	results []*RecommendedArticle
+	next    int
+}
+
+// value is the upper confidence bound of the arm's reward rate. The rate is
+// smoothed towards 1/2 so arms without feedback start out alike.
+func (s *banditState) value(totalShown float64) float64 {
+	mean := (s.rewards + 1) / (s.shown + 2)
+	return mean + math.Sqrt(2*math.Log(totalShown+1)/(s.shown+1))
+}
+
+func (b *BanditEngine) Recommend(ctx context.Context, userID uuid.UUID, limit int) ([]*RecommendedArticle, error) {
+	states := make([]*banditState, len(b.engines))
+	for i := range states {
+		states[i] = &banditState{}
+	}
+
+	// Without statistics every engine starts out alike
+	if b.repo != nil {
+		dbCtx, cancel := utils.DeriveDeadline(ctx, databaseBudgetShare)
+		arms, err := b.repo.WithContext(dbCtx).FindArms(userID)
+		cancel()
+		if err != nil {
+			b.logger.Error("Failed to get bandit statistics of user " + userID.String() + ": " + err.Error())
+		}
+		for _, arm := range arms {
+			for i, engine := range b.engines {
+				if engine.Name() == arm.Engine {
+					states[i].shown, states[i].rewards = float64(arm.Shown), float64(arm.Rewards)
+				}
+			}
+		}
+	}
+
+	// Every engine may fill the whole list; they run side by side
+	errs := make([]error, len(b.engines))
+	var wg sync.WaitGroup
+	for i, engine := range b.engines {
+		wg.Add(1)
+		go func(i int, engine Engine) {
+			defer wg.Done()
+			states[i].results, errs[i] = engine.Recommend(ctx, userID, limit)
+		}(i, engine)
+	}
+	wg.Wait()
+
+	// One engine failing leaves the others' recommendations
+	var failures []error
+	for i, engine := range b.engines {
+		if errs[i] != nil {
+			b.logger.Error("Engine " + engine.Name() + " failed for user " + userID.String() + ": " + errs[i].Error())
+			failures = append(failures, errs[i])
+		}
+	}
+	if len(failures) == len(b.engines) && len(b.engines) > 0 {
+		return nil, errors.Join(failures...)
+	}
+
+	totalShown := 0.0
+	for _, state := range states {
+		totalShown += state.shown
+	}
+
+	// Each slot counts as shown for its engine right away, so one list
+	// spreads over the engines as their bonuses shrink
+	recommendations := make([]*RecommendedArticle, 0, limit)
+	impressions := make([]*Impression, 0, limit)
+	picked := make(map[string]bool)
+	shownAt := time.Now()
+	for len(recommendations) < limit {
+		best := -1
+		bestValue := math.Inf(-1)
+		for i, state := range states {
+			// Links another engine already filled a slot with are skipped
+			for state.next < len(state.results) && picked[state.results[state.next].Article.URL] {
+				state.next++
+			}
+			if state.next == len(state.results) {
+				continue
+			}
+			if value := state.value(totalShown); value > bestValue {
+				best, bestValue = i, value
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		state := states[best]
+		rec := state.results[state.next]
+		state.next++
+		state.shown++
+		totalShown++
+		picked[rec.Article.URL] = true
+
+		engineName := b.engines[best].Name()
+		recommendations = append(recommendations, &RecommendedArticle{
+			Article:         rec.Article,
+			Score:           rec.Score,
+			RawScore:        rec.RawScore,
+			ScoreType:       rec.ScoreType,
+			Reason:          rec.Reason + " (" + engineName + ")",
+			RecommenderUsed: b.Name(),
+			Explanation:     rec.Explanation,
+		})
+		impressions = append(impressions, &Impression{
+			UserID:    userID,
+			ArticleID: rec.Article.ID,
+			URL:       rec.Article.URL,
+			Engine:    engineName,
+			ShownAt:   shownAt,
+		})
+	}
+
+	if b.repo != nil && len(impressions) > 0 {
+		dbCtx, cancel := utils.DeriveDeadline(ctx, databaseBudgetShare)
+		err := b.repo.WithContext(dbCtx).RecordImpressions(userID, impressions)
+		cancel()
+		if err != nil {
+			b.logger.Error("Failed to record impressions for user " + userID.String() + ": " + err.Error())
+		}
+	}
+
+	return recommendations, nil
+}
+
+// RecordFeedback credits the engine that recommended the article, or another
+// copy of its link, to the user, e.g. once they opened or liked it
+func (s *service) RecordFeedback(ctx context.Context, userID, articleID uuid.UUID) error {
+	if s.bandit.repo == nil {
+		return nil
+	}
+
+	rewarded, err := s.bandit.repo.WithContext(ctx).RewardImpression(userID, articleID, time.Now().Add(-impressionRewardWindow))
+	if err != nil {
+		s.logger.Error("Failed to record feedback of user " + userID.String() + " on article " + articleID.String() + ": " + err.Error())
+		return err
+	}
+	if rewarded {
+		s.logger.Debug("Credited feedback of user " + userID.String() + " on article " + articleID.String())
+	}
+
+	return nil
+}
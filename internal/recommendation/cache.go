@@ -0,0 +1,118 @@
+package recommendation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// invalidationMemory is how long an invalidation is remembered, so a list that
+// was being computed when the user rated an article is not cached afterwards.
+// Computing a list never takes this long.
+const invalidationMemory = 5 * time.Minute
+
+// cacheKey identifies a user's list of one engine
+type cacheKey struct {
+	userID uuid.UUID
+	engine string
+}
+
+// cachedRecommendations is a recommendation list kept for later requests
+type cachedRecommendations struct {
+	recommendations []*RecommendedArticle
+	limit           int // Limit the list was computed with
+	expiresAt       time.Time
+}
+
+// resultCache keeps recommendation lists per user and engine until they
+// expire or the user's ratings change
+type resultCache struct {
+	mu          sync.RWMutex
+	entries     map[cacheKey]*cachedRecommendations
+	invalidated map[uuid.UUID]time.Time // When each user's lists were last invalidated
+	now         func() time.Time
+}
+
+func newResultCache() *resultCache {
+	return &resultCache{
+		entries:     make(map[cacheKey]*cachedRecommendations),
+		invalidated: make(map[uuid.UUID]time.Time),
+		now:         time.Now,
+	}
+}
+
+// get returns up to limit cached recommendations of the engine for the user.
+// It misses when the entry expired or was computed with a smaller limit than
+// requested.
+func (c *resultCache) get(userID uuid.UUID, engine string, limit int) ([]*RecommendedArticle, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[cacheKey{userID, engine}]
+	c.mu.RUnlock()
+
+	if !ok || !c.now().Before(entry.expiresAt) || entry.limit < limit {
+		return nil, false
+	}
+
+	if limit > len(entry.recommendations) {
+		limit = len(entry.recommendations)
+	}
+	recommendations := make([]*RecommendedArticle, limit)
+	copy(recommendations, entry.recommendations)
+	return recommendations, true
+}
+
+// set stores the recommendations computed for the user with the given limit
+// for ttl, and reports whether it did. Lists computed from before the user's
+// ratings last changed, as told by startedAt, are dropped.
+func (c *resultCache) set(userID uuid.UUID, engine string, recommendations []*RecommendedArticle, limit int, startedAt time.Time, ttl time.Duration) bool {
+	if ttl <= 0 {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if invalidatedAt, ok := c.invalidated[userID]; ok && !startedAt.After(invalidatedAt) {
+		return false
+	}
+
+	c.entries[cacheKey{userID, engine}] = &cachedRecommendations{
+		recommendations: recommendations,
+		limit:           limit,
+		expiresAt:       c.now().Add(ttl),
+	}
+	return true
+}
+
+// invalidate drops the user's lists of every engine
+func (c *resultCache) invalidate(userID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.entries {
+		if key.userID == userID {
+			delete(c.entries, key)
+		}
+	}
+	c.invalidated[userID] = c.now()
+}
+
+// prune drops expired entries, e.g. of users who are no longer active, and
+// invalidations old enough to be forgotten
+func (c *resultCache) prune() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	for userID, invalidatedAt := range c.invalidated {
+		if now.Sub(invalidatedAt) >= invalidationMemory {
+			delete(c.invalidated, userID)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package recommendation
+
+import (
+	"context"
+
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// CollaborativeEngine recommends articles liked by readers with similar taste.
+// Every user saves their own copy of an article, so readers are matched by
+// the links they rated highly rather than by article IDs.
+type CollaborativeEngine struct {
+	articleRepo ArticleRepository
+	logger      *logger.Logger
+}
+
+// NewCollaborativeEngine creates a new collaborative recommendation engine
+func NewCollaborativeEngine(articleRepo ArticleRepository, log *logger.Logger) Engine {
+	return &CollaborativeEngine{
+		articleRepo: articleRepo,
+		logger:      log.WithComponent("collaborative-engine"),
+	}
+}
+
+func (c *CollaborativeEngine) Recommend(ctx context.Context, userID uuid.UUID, limit int) ([]*RecommendedArticle, error) {
+	c.logger.Info("Generating collaborative recommendations for user " + userID.String())
+
+	candidates, err := c.articleRepo.WithContext(ctx).FindLikedByNeighbours(userID, limit*2)
+	if err != nil {
+		c.logger.Error("Failed to find articles liked by similar readers: " + err.Error())
+		return nil, err
+	}
+
+	recommendations := make([]*RecommendedArticle, 0, len(candidates))
+	for _, article := range candidates {
+		recommendations = append(recommendations, &RecommendedArticle{
+			Article:         article,
+			RawScore:        article.Affinity,
+			ScoreType:       ScoreTypeAffinity,
+			Reason:          "Liked by readers with similar taste",
+			RecommenderUsed: c.Name(),
+		})
+	}
+
+	// Percentiles are taken over all candidates, before the list is cut
+	normalizeScores(recommendations)
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+
+	return recommendations, nil
+}
+
+func (c *CollaborativeEngine) Name() string {
+	return "collaborative"
+}
@@ -0,0 +1,90 @@
+package recommendation
+
+import "math"
+
+// diversify re-ranks candidates by maximal marginal relevance, so the top of
+// the list is not a run of near-duplicates. Each pick is the candidate with the
+// best lambda * similarity to the profile - (1 - lambda) * similarity to the
+// closest article already picked. Candidates must be sorted by relevance; up
+// to limit of them are returned.
+func diversify(candidates []*RecommendedArticle, settings Settings, limit int) []*RecommendedArticle {
+	lambda := settings.DiversityLambda
+	if lambda <= 0 || lambda >= 1 || len(candidates) <= 1 {
+		return candidates
+	}
+	if limit > len(candidates) {
+		limit = len(candidates)
+	}
+
+	// closest holds each remaining candidate's similarity to the picked articles
+	remaining := append([]*RecommendedArticle(nil), candidates...)
+	closest := make([]float64, len(remaining))
+	for i := range closest {
+		closest[i] = math.Inf(-1)
+	}
+
+	picked := make([]*RecommendedArticle, 0, limit)
+	for len(picked) < limit {
+		best, bestValue := 0, math.Inf(-1)
+		for i, candidate := range remaining {
+			value := lambda * candidate.RawScore
+			if len(picked) > 0 {
+				value -= (1 - lambda) * closest[i]
+			}
+			// Ties keep the relevance order
+			if value > bestValue {
+				best, bestValue = i, value
+			}
+		}
+
+		choice := remaining[best]
+		picked = append(picked, choice)
+		remaining = append(remaining[:best], remaining[best+1:]...)
+		closest = append(closest[:best], closest[best+1:]...)
+
+		for i, candidate := range remaining {
+			closest[i] = math.Max(closest[i], settings.similarity(choice.Article, candidate.Article))
+		}
+	}
+
+	return picked
+}
+
+// similarity is the cosine similarity of two articles in the space
+// recommendations compare in, mirroring the distance the similarity search
+// orders by. Articles missing an embedding count as unrelated.
+func (s Settings) similarity(a, b *Article) float64 {
+	switch s.EmbeddingSpace {
+	case SpaceContent:
+		return cosineSimilarity(a.ContentEmbedding, b.ContentEmbedding)
+	case SpaceBlended:
+		contentOf := func(article *Article) []float64 {
+			if len(article.ContentEmbedding) > 0 {
+				return article.ContentEmbedding
+			}
+			return article.Embedding
+		}
+		return s.TitleWeight*cosineSimilarity(a.Embedding, b.Embedding) +
+			(1-s.TitleWeight)*cosineSimilarity(contentOf(a), contentOf(b))
+	default:
+		return cosineSimilarity(a.Embedding, b.Embedding)
+	}
+}
+
+// cosineSimilarity returns 0 for vectors of different or zero length
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
@@ -1,77 +1,82 @@
 package recommendation
 
 import (
+	"context"
+	"math"
+	"strings"
+
 	"github.com/dustin/articles-backend/internal/embedding"
 	"github.com/dustin/articles-backend/pkg/logger"
 	"github.com/google/uuid"
 )
 
+// Shares of the remaining request budget granted to each downstream call, so
+// a slow dependency fails its own call instead of exhausting the whole request
+const (
+	databaseBudgetShare  = 0.25
+	embeddingBudgetShare = 0.5
+)
+
 // ContentBasedEngine recommends articles based on content similarity
 type ContentBasedEngine struct {
 	articleRepo     ArticleRepository
 	ratingRepo      RatingRepository
+	profiles        ProfileRepository // Stored profiles; nil builds the profile on each request
 	embeddingClient embedding.EmbeddingClient
+	settings        Settings
+	popular         *PopularEngine // Fallback for users without a profile
 	logger          *logger.Logger
 }
 
 // NewContentBasedEngine creates a new content-based recommendation engine
-func NewContentBasedEngine(articleRepo ArticleRepository, ratingRepo RatingRepository, embeddingClient embedding.EmbeddingClient, log *logger.Logger) Engine {
+func NewContentBasedEngine(articleRepo ArticleRepository, ratingRepo RatingRepository, embeddingClient embedding.EmbeddingClient, settings Settings, log *logger.Logger) Engine {
+	return newContentBasedEngine(articleRepo, ratingRepo, nil, embeddingClient, settings, log)
+}
+
+func newContentBasedEngine(articleRepo ArticleRepository, ratingRepo RatingRepository, profiles ProfileRepository, embeddingClient embedding.EmbeddingClient, settings Settings, log *logger.Logger) *ContentBasedEngine {
 	return &ContentBasedEngine{
 		articleRepo:     articleRepo,
 		ratingRepo:      ratingRepo,
+		profiles:        profiles,
 		embeddingClient: embeddingClient,
+		settings:        settings,
+		popular:         &PopularEngine{articleRepo: articleRepo, settings: settings, logger: log.WithComponent("popular-engine")},
 		logger:          log.WithComponent("recommendation-engine"),
 	}
 }
 
-func (c *ContentBasedEngine) Recommend(userID uuid.UUID, limit int) ([]*RecommendedArticle, error) {
+func (c *ContentBasedEngine) Recommend(ctx context.Context, userID uuid.UUID, limit int) ([]*RecommendedArticle, error) {
 	c.logger.Info("Generating recommendations for user " + userID.String())
 
-	// Get user's highly rated articles to build profile
-	userRatings, err := c.ratingRepo.FindByUserID(userID)
+	profile, err := c.userProfile(ctx, userID)
 	if err != nil {
-		c.logger.Error("Failed to get user ratings: " + err.Error())
 		return nil, err
 	}
 
-	// Collect highly rated articles for embedding generation
-	var userTexts []string
-	var userWeights []float64
-	for _, rating := range userRatings {
-		if rating.Score >= 4 { // Only consider high ratings
-			article, err := c.articleRepo.FindByID(rating.ArticleID)
-			if err != nil {
-				c.logger.Error("Failed to get article " + rating.ArticleID.String() + ": " + err.Error())
-				continue
-			}
-
-			text := article.Title + " " + article.Description
-			if text != "" {
-				userTexts = append(userTexts, text)
-				userWeights = append(userWeights, float64(rating.Score)/5.0)
-			}
+	// Until the user liked something, the topics they picked stand in for a
+	// profile; without those either, use popular articles as default
+	preference := "Similar to articles you rated highly"
+	var query []float64
+	if profile != nil {
+		query = profile.Embedding
+	} else {
+		interests, err := c.interests(ctx, userID)
+		if err != nil {
+			return nil, err
 		}
+		if interests == nil {
+			c.logger.Info("No user profile available, using popular articles as default")
+			return c.recommendPopular(ctx, userID, limit)
+		}
+		c.logger.Info("No user profile available, using the interests of user " + userID.String())
+		query = interests.Embedding
+		preference = "Similar to your interests: " + strings.Join(interests.Topics, ", ")
 	}
 
-	// If no profile can be built, use popular articles as default
-	if len(userTexts) == 0 {
-		c.logger.Info("No user profile available, using popular articles as default")
-		return c.recommendPopular(userID, limit)
-	}
-
-	// Generate embeddings for user's preferred articles
-	userEmbeddings, err := c.embeddingClient.GetBatchEmbeddings(userTexts)
-	if err != nil {
-		c.logger.Error("Failed to get user embeddings: " + err.Error())
-		return nil, err
-	}
-
-	// Calculate weighted user profile embedding
-	userProfile := c.calculateWeightedProfile(userEmbeddings, userWeights)
-
 	// Use vector similarity search instead of loading all articles
 	// This is much more scalable as it uses database indexing
-	similarArticles, err := c.articleRepo.FindSimilar(userProfile, userID, limit*2)
+	// The similarity query is the last call needed, so it may use whatever budget is left
+	similarArticles, err := c.articleRepo.WithContext(ctx).FindSimilar(query, c.settings.candidates(userID), c.settings.EmbeddingSpace, c.settings.TitleWeight, c.settings.candidatePool(limit))
 	if err != nil {
 		c.logger.Error("Failed to find similar articles: " + err.Error())
 		return nil, err
@@ -83,91 +88,163 @@ func (c *ContentBasedEngine) Recommend(userID uuid.UUID, limit int) ([]*Recommen
 	}
 
 	// Convert similar articles to recommendations
-	// The similarity score comes from the database query (1 - cosine_distance)
+	if c.settings.DedupeURLs {
+		similarArticles = uniqueByURL(similarArticles)
+	}
 	recommendations := make([]*RecommendedArticle, 0, len(similarArticles))
 	for _, article := range similarArticles {
-		// pgvector returns cosine distance (0-2), convert to similarity (1-0)
-		// For now, we'll use a fixed high confidence since articles are pre-filtered
-		similarityScore := 0.8 // High confidence for vector similarity matches
-
 		recommendations = append(recommendations, &RecommendedArticle{
 			Article:         article,
-			Score:           similarityScore,
-			Reason:          "Similar to articles you rated highly",
+			RawScore:        1 - article.Distance, // pgvector returns cosine distance (0-2)
+			ScoreType:       ScoreTypeSimilarity,
+			Reason:          preference,
 			RecommenderUsed: c.Name(),
 		})
 	}
 
-	// Limit results (already sorted by similarity from database)
+	// Percentiles are taken over all candidates, before the list is cut
+	normalizeScores(recommendations)
+
+	// Limit results (already sorted by similarity from database), trading
+	// some similarity for variety if configured
+	recommendations = diversify(recommendations, c.settings, limit)
 	if len(recommendations) > limit {
 		recommendations = recommendations[:limit]
 	}
+	if profile != nil {
+		c.explain(ctx, profile, recommendations)
+	}
 
 	c.logger.Info("Generated recommendations for user " + userID.String())
 	return recommendations, nil
 }
 
-func (c *ContentBasedEngine) recommendPopular(userID uuid.UUID, limit int) ([]*RecommendedArticle, error) {
+func (c *ContentBasedEngine) recommendPopular(ctx context.Context, userID uuid.UUID, limit int) ([]*RecommendedArticle, error) {
 	c.logger.Info("Using popular articles as default recommendation for user " + userID.String())
 
-	popularArticles, err := c.articleRepo.FindPopular(limit * 2) // Get more to filter user's own
+	recommendations, err := c.popular.recommend(ctx, userID, limit, "Popular article (no rating history available)")
 	if err != nil {
-		c.logger.Error("Failed to get popular articles: " + err.Error())
 		return nil, err
 	}
+	for _, rec := range recommendations {
+		rec.RecommenderUsed = c.Name()
+	}
 
-	recommendations := make([]*RecommendedArticle, 0)
-	for _, article := range popularArticles {
-		if article.UserID == userID {
-			continue // Skip user's own articles
-		}
+	return recommendations, nil
+}
 
-		recommendations = append(recommendations, &RecommendedArticle{
-			Article:         article,
-			Score:           0.7, // Good confidence for popular content
-			Reason:          "Popular article (no rating history available)",
-			RecommenderUsed: c.Name(),
-		})
+// reactionWeights maps quick reactions to profile weights on the same
+// scale as numeric ratings (score / 5). A thumbs down excludes the article.
+var reactionWeights = map[string]float64{
+	"heart":       1.0, // like a 5 star rating
+	"thumbs_up":   0.8, // like a 4 star rating
+	"bookmark":    0.6, // saved for later, a weaker interest signal
+	"thumbs_down": 0,
+}
 
-		if len(recommendations) >= limit {
-			break
-		}
-	}
+// ratingReactionBoosts raises the profile weight of ratings given with a
+// reaction, up to 1. A rating with the skip reaction excludes the article.
+var ratingReactionBoosts = map[string]float64{
+	"like":       0.1,
+	"insightful": 0.2,
+}
 
-	c.logger.Info("Generated popular recommendations for user " + userID.String())
-	return recommendations, nil
+// favoriteWeight is the profile weight of a favorite article, like a 5 star
+// rating
+const favoriteWeight = 1.0
+
+// profileSignal is an article contributing to the user profile with its weight
+type profileSignal struct {
+	articleID uuid.UUID
+	weight    float64
 }
 
-// calculateWeightedProfile creates a weighted average embedding from multiple embeddings
-func (c *ContentBasedEngine) calculateWeightedProfile(embeddings [][]float64, weights []float64) []float64 {
-	if len(embeddings) == 0 || len(embeddings) != len(weights) {
-		return nil
+// profileSignals merges favorites, ratings and reactions into weighted profile
+// articles. A favorite always counts fully, whatever its rating or reactions.
+// Ratings below minRating or skipped are left out, and a like or insightful
+// reaction on a rating raises its weight. A numeric rating always wins over
+// reactions on the same article; otherwise the strongest reaction counts
+// unless the user gave a thumbs down.
+func profileSignals(ratings []*Rating, reactions []*Reaction, favorites []uuid.UUID, minRating int) []profileSignal {
+	var signals []profileSignal
+
+	rated := make(map[uuid.UUID]bool)
+	for _, articleID := range favorites {
+		if !rated[articleID] {
+			rated[articleID] = true
+			signals = append(signals, profileSignal{articleID: articleID, weight: favoriteWeight})
+		}
+	}
+	for _, rating := range ratings {
+		if rated[rating.ArticleID] {
+			continue
+		}
+		rated[rating.ArticleID] = true
+		if rating.Score >= minRating && rating.Reaction != "skip" { // Only consider high ratings
+			weight := math.Min(float64(rating.Score)/5.0+ratingReactionBoosts[rating.Reaction], 1)
+			signals = append(signals, profileSignal{articleID: rating.ArticleID, weight: weight})
+		}
 	}
 
-	// Assume all embeddings have the same dimension
-	dimension := len(embeddings[0])
-	profile := make([]float64, dimension)
-	totalWeight := 0.0
-
-	// Calculate weighted sum
-	for i, embedding := range embeddings {
-		weight := weights[i]
-		totalWeight += weight
-		for j, value := range embedding {
-			profile[j] += value * weight
+	var order []uuid.UUID
+	reactionWeight := make(map[uuid.UUID]float64)
+	disliked := make(map[uuid.UUID]bool)
+	for _, reaction := range reactions {
+		if rated[reaction.ArticleID] {
+			continue
+		}
+		weight, ok := reactionWeights[reaction.Kind]
+		if !ok {
+			continue
+		}
+		if _, seen := reactionWeight[reaction.ArticleID]; !seen {
+			order = append(order, reaction.ArticleID)
+		}
+		if weight == 0 {
+			disliked[reaction.ArticleID] = true
+		}
+		if weight > reactionWeight[reaction.ArticleID] {
+			reactionWeight[reaction.ArticleID] = weight
 		}
 	}
 
-	// Normalize by total weight
-	if totalWeight > 0 {
-		for i := range profile {
-			profile[i] /= totalWeight
+	for _, articleID := range order {
+		if disliked[articleID] || reactionWeight[articleID] == 0 {
+			continue
 		}
+		signals = append(signals, profileSignal{articleID: articleID, weight: reactionWeight[articleID]})
 	}
 
-	return profile
+	return signals
+}
+
+// profileText returns the article text embedded into the user profile so it
+// lives in the same space as the embeddings it is compared against
+func profileText(article *Article, space EmbeddingSpace) string {
+	titleText := strings.TrimSpace(article.Title + " " + article.Description)
+	if space == SpaceContent {
+		if content := strings.TrimSpace(article.Content); content != "" {
+			return content
+		}
+	}
+	return titleText
 }
 
 func (c *ContentBasedEngine) Name() string {
 	return "content-based"
 }
+
+// uniqueByURL keeps the first copy of each link, so a link saved by several
+// readers is recommended once
+func uniqueByURL(articles []*Article) []*Article {
+	seen := make(map[string]bool, len(articles))
+	unique := make([]*Article, 0, len(articles))
+	for _, article := range articles {
+		if seen[article.URL] {
+			continue
+		}
+		seen[article.URL] = true
+		unique = append(unique, article)
+	}
+	return unique
+}
@@ -0,0 +1,163 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Types of events clients report on recommended articles
+const (
+	EventImpression = "impression" // The article was shown
+	EventClick      = "click"      // The user opened the article
+)
+
+// Limits on event reports and click-through queries
+const (
+	maxEventsPerRequest = 100
+	defaultCTRDays      = 7
+	// eventRetention is how long events are kept, and so the longest window
+	// click-through rates can be computed over
+	eventRetention = 90 * 24 * time.Hour
+)
+
+// similarRecommender is the recommender_used of similar-article lists, which
+// no engine produces
+const similarRecommender = "similar-articles"
+
+// Event is an impression or click a client reported on a recommended article
+type Event struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	ArticleID uuid.UUID `gorm:"type:uuid;not null"`
+	Engine    string    `gorm:"size:30;not null"` // recommender_used of the recommendation
+	Type      string    `gorm:"size:20;not null"`
+	Position  int       `gorm:"not null;default:0"` // Zero-based rank in the list shown
+	CreatedAt time.Time `gorm:"not null;index"`
+}
+
+// TableName returns the table name for GORM
+func (Event) TableName() string {
+	return "recommendation_events"
+}
+
+// EventInput is one event of a report
+type EventInput struct {
+	Type      string    `json:"type"`
+	ArticleID uuid.UUID `json:"article_id"`
+	Engine    string    `json:"engine"`
+	Position  int       `json:"position"`
+}
+
+// EventsRequest reports events on recommended articles
+type EventsRequest struct {
+	Events []EventInput `json:"events"`
+}
+
+// EngineCTR counts the events of an engine
+type EngineCTR struct {
+	Engine      string  `json:"engine"`
+	Impressions int64   `json:"impressions"`
+	Clicks      int64   `json:"clicks"`
+	CTR         float64 `json:"ctr"` // Clicks per impression, 0 without impressions
+}
+
+// CTRResponse lists the click-through rate of each engine since a time
+type CTRResponse struct {
+	Since   time.Time    `json:"since"`
+	Days    int          `json:"days"`
+	Engines []*EngineCTR `json:"engines"`
+}
+
+// RecordEvents stores the impressions and clicks the user's client reported.
+// Clicks also credit the engine that recommended the article to the bandit
+// engine, so engines users click more are picked more.
+func (s *service) RecordEvents(ctx context.Context, userID uuid.UUID, inputs []EventInput) error {
+	if len(inputs) == 0 || len(inputs) > maxEventsPerRequest {
+		return utils.NewValidationError("events", fmt.Sprintf("must hold between 1 and %d events", maxEventsPerRequest))
+	}
+
+	recommenders := map[string]bool{similarRecommender: true}
+	for _, engine := range s.engines {
+		recommenders[engine.Name()] = true
+	}
+
+	now := time.Now()
+	events := make([]*Event, len(inputs))
+	for i, input := range inputs {
+		if input.Type != EventImpression && input.Type != EventClick {
+			return utils.NewValidationError("type", "must be impression or click")
+		}
+		if input.ArticleID == uuid.Nil {
+			return utils.NewValidationError("article_id", "is required")
+		}
+		if !recommenders[input.Engine] {
+			return utils.NewValidationError("engine", "must be the recommender_used of a recommendation")
+		}
+		if input.Position < 0 {
+			return utils.NewValidationError("position", "must not be negative")
+		}
+
+		events[i] = &Event{
+			ID:        uuid.New(),
+			UserID:    userID,
+			ArticleID: input.ArticleID,
+			Engine:    input.Engine,
+			Type:      input.Type,
+			Position:  input.Position,
+			CreatedAt: now,
+		}
+	}
+
+	if s.bandit.repo == nil {
+		return nil
+	}
+
+	if err := s.bandit.repo.WithContext(ctx).RecordEvents(events); err != nil {
+		s.logger.Error("Failed to record events of user " + userID.String() + ": " + err.Error())
+		return err
+	}
+
+	for _, event := range events {
+		if event.Type == EventClick {
+			if err := s.RecordFeedback(ctx, userID, event.ArticleID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// EngineCTR computes the click-through rate of each engine over the last days
+func (s *service) EngineCTR(ctx context.Context, days int) (*CTRResponse, error) {
+	if days == 0 {
+		days = defaultCTRDays
+	}
+	if maxDays := int(eventRetention / (24 * time.Hour)); days < 1 || days > maxDays {
+		return nil, utils.NewValidationError("days", fmt.Sprintf("must be between 1 and %d", maxDays))
+	}
+
+	since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	response := &CTRResponse{Since: since, Days: days, Engines: []*EngineCTR{}}
+	if s.bandit.repo == nil {
+		return response, nil
+	}
+
+	engines, err := s.bandit.repo.WithContext(ctx).CountEvents(since)
+	if err != nil {
+		s.logger.Error("Failed to count recommendation events: " + err.Error())
+		return nil, err
+	}
+	for _, engine := range engines {
+		if engine.Impressions > 0 {
+			engine.CTR = float64(engine.Clicks) / float64(engine.Impressions)
+		}
+	}
+	response.Engines = append(response.Engines, engines...)
+
+	return response, nil
+}
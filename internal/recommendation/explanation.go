@@ -0,0 +1,95 @@
+package recommendation
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+const (
+	// explanationSources bounds the liked articles recommendations are compared
+	// against; the ones weighing most in the profile are used
+	explanationSources = 50
+	// explanationMatches is how many liked articles an explanation lists
+	explanationMatches = 3
+)
+
+// explain tells for each recommendation which of the articles in the user's
+// profile it resembles most. Explanations are extras: when the liked articles
+// cannot be loaded, the recommendations go out without them.
+func (c *ContentBasedEngine) explain(ctx context.Context, profile *UserProfile, recommendations []*RecommendedArticle) {
+	if len(recommendations) == 0 || len(profile.Weights) == 0 {
+		return
+	}
+
+	ids := make([]uuid.UUID, 0, len(profile.Weights))
+	for id := range profile.Weights {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if profile.Weights[ids[i]] != profile.Weights[ids[j]] {
+			return profile.Weights[ids[i]] > profile.Weights[ids[j]]
+		}
+		return ids[i].String() < ids[j].String()
+	})
+	if len(ids) > explanationSources {
+		ids = ids[:explanationSources]
+	}
+
+	dbCtx, cancel := utils.DeriveDeadline(ctx, databaseBudgetShare)
+	liked, err := c.articleRepo.WithContext(dbCtx).FindByIDs(ids)
+	cancel()
+	if err != nil {
+		c.logger.Error("Failed to get liked articles of user " + profile.UserID.String() + " for explanations: " + err.Error())
+		return
+	}
+
+	for _, rec := range recommendations {
+		rec.Explanation = c.settings.explanation(rec.Article, liked)
+	}
+}
+
+// explanation lists the liked articles most similar to the article, closest
+// first, and the tags the user gave them. It returns nil when none is similar.
+func (s Settings) explanation(article *Article, liked []*Article) *Explanation {
+	type match struct {
+		article    *Article
+		similarity float64
+	}
+	matches := make([]match, 0, len(liked))
+	for _, candidate := range liked {
+		if similarity := s.similarity(article, candidate); similarity > 0 {
+			matches = append(matches, match{article: candidate, similarity: similarity})
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].similarity > matches[j].similarity
+	})
+	if len(matches) > explanationMatches {
+		matches = matches[:explanationMatches]
+	}
+
+	explanation := &Explanation{
+		MatchedTopics:     []string{},
+		SimilarArticleIDs: make([]uuid.UUID, 0, len(matches)),
+		Similarity:        matches[0].similarity,
+	}
+	seen := make(map[string]bool)
+	for _, m := range matches {
+		explanation.SimilarArticleIDs = append(explanation.SimilarArticleIDs, m.article.ID)
+		for _, tag := range m.article.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				explanation.MatchedTopics = append(explanation.MatchedTopics, tag)
+			}
+		}
+	}
+
+	return explanation
+}
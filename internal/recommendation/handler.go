@@ -1,11 +1,16 @@
 package recommendation
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/dustin/articles-backend/internal/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // Handler handles HTTP requests for recommendation operations
@@ -23,7 +28,7 @@ func NewHandler(service Service) *Handler {
 // GetRecommendations handles getting recommendations for authenticated user
 func (h *Handler) GetRecommendations(c *gin.Context) {
 	// Extract user ID from JWT token
-	userID, err := utils.GetUserIDFromToken(c)
+	userID, err := utils.GetUserIDFromContext(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 		return
@@ -36,25 +41,239 @@ func (h *Handler) GetRecommendations(c *gin.Context) {
 		limit = 10
 	}
 
-	// Get recommendations using default engine
-	recommendations, err := h.service.GetRecommendations(userID, limit)
+	// An engine parameter picks the engine; the configured one is used by default
+	engine := strings.ToLower(c.Query("engine"))
+	engineUsed := engine
+	if engineUsed == "" {
+		engineUsed = "default"
+	}
+
+	request := PageRequest{Engine: engine, Limit: limit}
+	if param := c.Query("offset"); param != "" {
+		if request.Offset, err = strconv.Atoi(param); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset"})
+			return
+		}
+	}
+	if param := c.Query("seen_window"); param != "" {
+		if request.SeenWindow, err = time.ParseDuration(param); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid seen_window"})
+			return
+		}
+	}
+
+	// A cursor parameter, empty for the first page, pages through a fixed list
+	request.Cursor, request.Snapshot = c.GetQuery("cursor")
+
+	page, err := h.service.GetRecommendationPage(c.Request.Context(), userID, request)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Recommendations timed out"})
+			return
+		}
+		utils.RespondError(c, err, "Failed to get recommendations")
+		return
+	}
+
+	if request.Snapshot {
+		c.JSON(http.StatusOK, BuildRecommendationPageResponse(page, userID, engineUsed))
+		return
+	}
+	c.JSON(http.StatusOK, BuildRecommendationResponse(page.Recommendations, userID, engineUsed))
+}
+
+// SemanticSearch handles meaning-based search over the authenticated user's articles
+func (h *Handler) SemanticSearch(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'q' is required"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	response, err := h.service.SemanticSearch(c.Request.Context(), userID, query, c.DefaultQuery("space", SpaceAuto), limit)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Search timed out"})
+			return
+		}
+		utils.RespondError(c, err, "Failed to search articles")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetSimilarArticles handles finding other readers' articles like one of the user's own
+func (h *Handler) GetSimilarArticles(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	articleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	response, err := h.service.SimilarArticles(c.Request.Context(), userID, articleID, limit)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Similar articles timed out"})
+			return
+		}
+		utils.RespondError(c, err, "Failed to find similar articles")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RecordClick handles a user opening a recommended article, crediting the
+// engine that recommended it
+func (h *Handler) RecordClick(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	articleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	if err := h.service.RecordFeedback(c.Request.Context(), userID, articleID); err != nil {
+		utils.RespondError(c, err, "Failed to record click")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RecordEvents handles a client reporting impressions and clicks on
+// recommended articles
+func (h *Handler) RecordEvents(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	var req EventsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.RecordEvents(c.Request.Context(), userID, req.Events); err != nil {
+		utils.RespondError(c, err, "Failed to record events")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetEngineCTR handles computing the click-through rate of each engine
+func (h *Handler) GetEngineCTR(c *gin.Context) {
+	days := 0
+	if param := c.Query("days"); param != "" {
+		var err error
+		if days, err = strconv.Atoi(param); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days"})
+			return
+		}
+	}
 
+	response, err := h.service.EngineCTR(c.Request.Context(), days)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get recommendations"})
+		utils.RespondError(c, err, "Failed to compute click-through rates")
 		return
 	}
 
-	response := BuildRecommendationResponse(recommendations, userID, "default")
 	c.JSON(http.StatusOK, response)
 }
 
-// RegisterRoutes registers all recommendation routes
-func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+// SetInterests handles replacing the topics that seed a new user's recommendations
+func (h *Handler) SetInterests(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	var req InterestsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.service.SetInterests(c.Request.Context(), userID, req.Topics)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to store interests")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RegisterRoutes registers all recommendation routes. rateLimit applies to the
+// admin routes, after the role and scope checks.
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, rateLimit gin.HandlerFunc) {
 	// All recommendation routes require authentication
 	recommendations := router.Group("/recommendations")
 	recommendations.Use(authMiddleware)
 	{
 		// Get recommendations
 		recommendations.GET("", h.GetRecommendations)
+		// Feedback for the bandit engine
+		recommendations.POST("/:id/click", h.RecordClick)
+		// Impressions and clicks for click-through rates
+		recommendations.POST("/events", h.RecordEvents)
+	}
+
+	admin := router.Group("/admin/recommendations")
+	admin.Use(authMiddleware, utils.RequireRole(utils.RoleAdmin), utils.RequireScope(utils.ScopeAdmin), rateLimit)
+	{
+		admin.GET("/ctr", h.GetEngineCTR)
+	}
+
+	articles := router.Group("/articles")
+	articles.Use(authMiddleware)
+	{
+		// More like one of the user's articles
+		articles.GET("/:id/similar", h.GetSimilarArticles)
+	}
+
+	users := router.Group("/users")
+	users.Use(authMiddleware)
+	{
+		// Topics seeding recommendations until the user liked something
+		users.POST("/me/interests", h.SetInterests)
+	}
+
+	search := router.Group("/search")
+	search.Use(authMiddleware)
+	{
+		// Semantic search over the user's own library
+		search.GET("/semantic", h.SemanticSearch)
 	}
 }
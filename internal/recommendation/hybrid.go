@@ -0,0 +1,154 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// WeightedEngine is a sub-engine of a HybridEngine with its share of the blend
+type WeightedEngine struct {
+	Engine Engine
+	Weight float64
+}
+
+// HybridEngine blends the recommendations of several engines. Each article's
+// blended score is the weighted mean of its percentile in every sub-engine,
+// counting 0 where an engine did not recommend it, so articles recommended by
+// several engines rise to the top.
+type HybridEngine struct {
+	engines []WeightedEngine
+	logger  *logger.Logger
+}
+
+// NewHybridEngine creates an engine blending the given engines; weights need
+// not sum to 1
+func NewHybridEngine(engines []WeightedEngine, log *logger.Logger) Engine {
+	return &HybridEngine{
+		engines: engines,
+		logger:  log.WithComponent("hybrid-engine"),
+	}
+}
+
+// contribution is one sub-engine's share of a blended recommendation
+type contribution struct {
+	engine string
+	reason string
+	share  float64
+}
+
+// blendedArticle collects what each sub-engine said about one article
+type blendedArticle struct {
+	recommendation *RecommendedArticle
+	contributions  []contribution
+}
+
+func (h *HybridEngine) Recommend(ctx context.Context, userID uuid.UUID, limit int) ([]*RecommendedArticle, error) {
+	// Sub-engines share the request budget and run side by side
+	results := make([][]*RecommendedArticle, len(h.engines))
+	errs := make([]error, len(h.engines))
+	var wg sync.WaitGroup
+	for i, weighted := range h.engines {
+		wg.Add(1)
+		go func(i int, engine Engine) {
+			defer wg.Done()
+			results[i], errs[i] = engine.Recommend(ctx, userID, limit)
+		}(i, weighted.Engine)
+	}
+	wg.Wait()
+
+	totalWeight := 0.0
+	for _, weighted := range h.engines {
+		totalWeight += weighted.Weight
+	}
+
+	// One engine failing leaves the others' recommendations
+	var failures []error
+	blended := make(map[string]*blendedArticle)
+	var order []string
+	for i, weighted := range h.engines {
+		if errs[i] != nil {
+			h.logger.Error("Engine " + weighted.Engine.Name() + " failed for user " + userID.String() + ": " + errs[i].Error())
+			failures = append(failures, errs[i])
+			continue
+		}
+		for _, rec := range results[i] {
+			// Users save their own copies of a link; it is recommended once
+			key := rec.Article.URL
+			entry, ok := blended[key]
+			if !ok {
+				entry = &blendedArticle{recommendation: &RecommendedArticle{
+					Article:         rec.Article,
+					ScoreType:       ScoreTypeBlended,
+					RecommenderUsed: h.Name(),
+				}}
+				blended[key] = entry
+				order = append(order, key)
+			} else if hasContribution(entry, weighted.Engine.Name()) {
+				continue
+			}
+
+			// The first engine that can explain the article does
+			if entry.recommendation.Explanation == nil {
+				entry.recommendation.Explanation = rec.Explanation
+			}
+
+			share := weighted.Weight * rec.Score / totalWeight
+			entry.recommendation.RawScore += share
+			entry.contributions = append(entry.contributions, contribution{engine: weighted.Engine.Name(), reason: rec.Reason, share: share})
+		}
+	}
+	if len(failures) == len(h.engines) && len(h.engines) > 0 {
+		return nil, errors.Join(failures...)
+	}
+
+	recommendations := make([]*RecommendedArticle, 0, len(order))
+	for _, key := range order {
+		entry := blended[key]
+		entry.recommendation.Reason = blendedReason(entry.contributions)
+		recommendations = append(recommendations, entry.recommendation)
+	}
+
+	// Stable, so ties keep the order of the engines listed first
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].RawScore > recommendations[j].RawScore
+	})
+	normalizeScores(recommendations)
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+
+	return recommendations, nil
+}
+
+func (h *HybridEngine) Name() string {
+	return "hybrid"
+}
+
+func hasContribution(entry *blendedArticle, engine string) bool {
+	for _, existing := range entry.contributions {
+		if existing.engine == engine {
+			return true
+		}
+	}
+	return false
+}
+
+// blendedReason lists the reasons of the engines that recommended an
+// article, largest contribution first, each tagged with its engine
+func blendedReason(contributions []contribution) string {
+	sort.SliceStable(contributions, func(i, j int) bool {
+		return contributions[i].share > contributions[j].share
+	})
+
+	parts := make([]string, len(contributions))
+	for i, c := range contributions {
+		parts[i] = c.reason + " (" + c.engine + ")"
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,150 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// UserInterests are the topics a new user picked, embedded once so the
+// content engine has a profile before the user liked anything
+type UserInterests struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Topics    []string  `gorm:"type:jsonb;serializer:json;not null"`
+	Embedding []float64 `gorm:"type:vector(384);not null"` // Mean of the topic embeddings
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for GORM
+func (UserInterests) TableName() string {
+	return "user_interests"
+}
+
+// ErrInterestsNotFound is returned by ProfileRepository.FindInterests for users without interests
+var ErrInterestsNotFound = utils.NewNotFoundError("interests not found")
+
+// Limits on the topics a user may pick
+const (
+	maxInterests      = 20
+	maxInterestLength = 50
+)
+
+// InterestsRequest replaces the user's interests; an empty list clears them
+type InterestsRequest struct {
+	Topics []string `json:"topics"`
+}
+
+// InterestsResponse lists the user's interests
+type InterestsResponse struct {
+	Topics    []string  `json:"topics"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// normalizeInterests trims the topics and drops repeats, ignoring case
+func normalizeInterests(topics []string) ([]string, error) {
+	normalized := make([]string, 0, len(topics))
+	seen := make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		topic = strings.Join(strings.Fields(topic), " ")
+		if topic == "" {
+			continue
+		}
+		if utf8.RuneCountInString(topic) > maxInterestLength {
+			return nil, utils.NewValidationError("topics", fmt.Sprintf("must each be at most %d characters", maxInterestLength))
+		}
+		key := strings.ToLower(topic)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, topic)
+	}
+	if len(normalized) > maxInterests {
+		return nil, utils.NewValidationError("topics", fmt.Sprintf("must list at most %d topics", maxInterests))
+	}
+	return normalized, nil
+}
+
+// SetInterests replaces the topics the user is interested in and embeds them
+func (s *service) SetInterests(ctx context.Context, userID uuid.UUID, topics []string) (*InterestsResponse, error) {
+	topics, err := normalizeInterests(topics)
+	if err != nil {
+		return nil, err
+	}
+	if s.content.profiles == nil {
+		return nil, errors.New("interests are not stored without a profile repository")
+	}
+
+	repo := s.content.profiles.WithContext(ctx)
+	if len(topics) == 0 {
+		if err := repo.DeleteInterests(userID); err != nil {
+			s.logger.Error("Failed to clear interests of user " + userID.String() + ": " + err.Error())
+			return nil, err
+		}
+		s.InvalidateRecommendations(userID)
+		return &InterestsResponse{Topics: topics, UpdatedAt: time.Now()}, nil
+	}
+
+	embeddings, err := s.embeddingClient.GetBatchEmbeddings(ctx, topics)
+	if err != nil {
+		s.logger.Error("Failed to embed interests of user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+	if len(embeddings) != len(topics) {
+		return nil, fmt.Errorf("embedding service returned %d embeddings for %d topics", len(embeddings), len(topics))
+	}
+
+	interests := &UserInterests{UserID: userID, Topics: topics, Embedding: meanVector(embeddings), UpdatedAt: time.Now()}
+	if err := repo.SaveInterests(interests); err != nil {
+		s.logger.Error("Failed to store interests of user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	// Lists cached while the user had no interests are outdated
+	s.InvalidateRecommendations(userID)
+
+	s.logger.Info("Stored " + fmt.Sprintf("%d", len(topics)) + " interests of user " + userID.String())
+	return &InterestsResponse{Topics: interests.Topics, UpdatedAt: interests.UpdatedAt}, nil
+}
+
+// interests returns the user's interests, or nil when they picked none
+func (c *ContentBasedEngine) interests(ctx context.Context, userID uuid.UUID) (*UserInterests, error) {
+	if c.profiles == nil {
+		return nil, nil
+	}
+
+	dbCtx, cancel := utils.DeriveDeadline(ctx, databaseBudgetShare)
+	interests, err := c.profiles.WithContext(dbCtx).FindInterests(userID)
+	cancel()
+	if errors.Is(err, ErrInterestsNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		c.logger.Error("Failed to get interests of user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+	return interests, nil
+}
+
+// meanVector averages vectors of equal length
+func meanVector(vectors [][]float64) []float64 {
+	if len(vectors) == 0 {
+		return nil
+	}
+	mean := make([]float64, len(vectors[0]))
+	for _, vector := range vectors {
+		for i, value := range vector {
+			mean[i] += value
+		}
+	}
+	for i := range mean {
+		mean[i] /= float64(len(vectors))
+	}
+	return mean
+}
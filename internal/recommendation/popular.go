@@ -0,0 +1,76 @@
+package recommendation
+
+import (
+	"context"
+	"time"
+
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// PopularityWeights blends the signals the popular engine ranks by. Each
+// signal is scaled to 0-1 before weighting.
+type PopularityWeights struct {
+	Saves   float64 // Readers who saved the link, log-scaled against the most saved link
+	Rating  float64 // Mean rating of the link, damped towards 3 while it has few ratings
+	Recency float64 // Halves every HalfLife since the article was saved
+	// HalfLife is how fast the recency signal decays
+	HalfLife time.Duration
+}
+
+// PopularEngine recommends the articles of other readers that are saved most,
+// rated best and saved most recently. It needs no rating history, so the
+// content-based engine falls back to it.
+type PopularEngine struct {
+	articleRepo ArticleRepository
+	settings    Settings
+	logger      *logger.Logger
+}
+
+// NewPopularEngine creates a new popularity recommendation engine
+func NewPopularEngine(articleRepo ArticleRepository, settings Settings, log *logger.Logger) Engine {
+	return &PopularEngine{
+		articleRepo: articleRepo,
+		settings:    settings,
+		logger:      log.WithComponent("popular-engine"),
+	}
+}
+
+func (p *PopularEngine) Recommend(ctx context.Context, userID uuid.UUID, limit int) ([]*RecommendedArticle, error) {
+	return p.recommend(ctx, userID, limit, "Popular with other readers")
+}
+
+// recommend lists popular articles the user has not saved or rated, giving each the reason
+func (p *PopularEngine) recommend(ctx context.Context, userID uuid.UUID, limit int, reason string) ([]*RecommendedArticle, error) {
+	popularArticles, err := p.articleRepo.WithContext(ctx).FindPopular(p.settings.candidates(userID), p.settings.Popularity, limit*2) // Get more to cover repeated links
+	if err != nil {
+		p.logger.Error("Failed to get popular articles: " + err.Error())
+		return nil, err
+	}
+	if p.settings.DedupeURLs {
+		popularArticles = uniqueByURL(popularArticles)
+	}
+
+	recommendations := make([]*RecommendedArticle, 0)
+	for _, article := range popularArticles {
+		recommendations = append(recommendations, &RecommendedArticle{
+			Article:         article,
+			RawScore:        article.Popularity,
+			ScoreType:       ScoreTypePopularity,
+			Reason:          reason,
+			RecommenderUsed: p.Name(),
+		})
+
+		if len(recommendations) >= limit {
+			break
+		}
+	}
+	normalizeScores(recommendations)
+
+	p.logger.Info("Generated popular recommendations for user " + userID.String())
+	return recommendations, nil
+}
+
+func (p *PopularEngine) Name() string {
+	return "popular"
+}
@@ -0,0 +1,124 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// precomputeUserTimeout bounds the work for a single user so one slow profile
+// cannot hold up the rest of a run
+const precomputeUserTimeout = 30 * time.Second
+
+// ActiveUserSource lists recently active users (dependency inversion)
+type ActiveUserSource interface {
+	ActiveUsers(days, limit int) ([]uuid.UUID, error)
+}
+
+func (s *service) PrecomputeRecommendations(ctx context.Context, userIDs []uuid.UUID) (int, error) {
+	s.cache.prune()
+	s.seen.prune()
+	if s.content.profiles != nil {
+		// Lists of users who are no longer active would never be served again
+		if err := s.content.profiles.WithContext(ctx).DeleteStoredListsBefore(s.cache.now().Add(-s.precomputedTTL)); err != nil {
+			s.logger.Error("Failed to drop stale stored recommendations: " + err.Error())
+		}
+	}
+	if s.bandit.repo != nil {
+		if err := s.bandit.repo.WithContext(ctx).DeleteEventsBefore(time.Now().Add(-eventRetention)); err != nil {
+			s.logger.Error("Failed to drop expired recommendation events: " + err.Error())
+		}
+	}
+
+	cached := 0
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return cached, err
+		}
+
+		startedAt := s.cache.now()
+		userCtx, cancel := context.WithTimeout(ctx, precomputeUserTimeout)
+		recommendations, err := s.generate(userCtx, userID, s.defaultEngine, maxRecommendationLimit)
+		cancel()
+		if err != nil {
+			s.logger.Error("Failed to precompute recommendations for user " + userID.String() + ": " + err.Error())
+			continue
+		}
+
+		// Lists the user's ratings changed under while computing are neither
+		// cached nor stored
+		if !s.cache.set(userID, s.defaultEngine, recommendations, maxRecommendationLimit, startedAt, s.precomputedTTL) {
+			continue
+		}
+		if s.content.profiles != nil {
+			if err := s.storeList(ctx, userID, s.defaultEngine, recommendations, startedAt); err != nil {
+				s.logger.Error("Failed to store precomputed recommendations for user " + userID.String() + ": " + err.Error())
+			}
+		}
+		cached++
+	}
+
+	return cached, nil
+}
+
+// Precomputer refreshes the stored and cached recommendations of recently
+// active users. Run it off-peak so the embedding calls do not compete with
+// live traffic.
+type Precomputer struct {
+	service    Service
+	users      ActiveUserSource
+	activeDays int
+	maxUsers   int
+	logger     *logger.Logger
+}
+
+// NewPrecomputer creates a recommendation precomputer with validation and defaults
+func NewPrecomputer(cfg *config.RecommendationConfig, service Service, users ActiveUserSource, log *logger.Logger) (*Precomputer, error) {
+	activeDays := 7
+	if cfg != nil && cfg.PrecomputeActiveDays != "" {
+		parsed, err := strconv.Atoi(cfg.PrecomputeActiveDays)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid recommendation precompute active days '%s': must be a positive integer", cfg.PrecomputeActiveDays)
+		}
+		activeDays = parsed
+	}
+
+	maxUsers := 1000
+	if cfg != nil && cfg.PrecomputeMaxUsers != "" {
+		parsed, err := strconv.Atoi(cfg.PrecomputeMaxUsers)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid recommendation precompute max users '%s': must be a positive integer", cfg.PrecomputeMaxUsers)
+		}
+		maxUsers = parsed
+	}
+
+	return &Precomputer{
+		service:    service,
+		users:      users,
+		activeDays: activeDays,
+		maxUsers:   maxUsers,
+		logger:     log.WithComponent("recommendation-precomputer"),
+	}, nil
+}
+
+// Run precomputes recommendations for the busiest recently active users
+func (p *Precomputer) Run() error {
+	userIDs, err := p.users.ActiveUsers(p.activeDays, p.maxUsers)
+	if err != nil {
+		return fmt.Errorf("failed to list active users: %w", err)
+	}
+
+	start := time.Now()
+	cached, err := p.service.PrecomputeRecommendations(context.Background(), userIDs)
+	if err != nil {
+		return fmt.Errorf("failed to precompute recommendations: %w", err)
+	}
+
+	p.logger.Info("Precomputed recommendations for " + strconv.Itoa(cached) + " of " + strconv.Itoa(len(userIDs)) + " active users in " + time.Since(start).Round(time.Millisecond).String())
+	return nil
+}
@@ -0,0 +1,98 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/embedding"
+	"github.com/google/uuid"
+)
+
+// Seed scores used when carrying over signals from an import source
+const (
+	favoriteSeedScore = 5
+	readSeedScore     = 4
+)
+
+// PrimeProfile embeds freshly imported articles and seeds the user's rating
+// profile from their favorite/read flags, so the first recommendation request
+// after onboarding is already personalized. Existing ratings are never changed.
+func (s *service) PrimeProfile(ctx context.Context, userID uuid.UUID, seeds []ProfileSeed) (*PrimeResult, error) {
+	s.logger.Info("Priming recommendation profile for user " + userID.String() + " with " + fmt.Sprintf("%d", len(seeds)) + " imported articles")
+
+	result := &PrimeResult{}
+	if len(seeds) == 0 {
+		return result, nil
+	}
+
+	// Collect texts for imported articles that do not have an embedding yet
+	texts := make([]embedding.ArticleText, 0, len(seeds))
+	for _, seed := range seeds {
+		article, err := s.articleRepo.FindByID(seed.ArticleID)
+		if err != nil {
+			s.logger.Error("Failed to load imported article " + seed.ArticleID.String() + ": " + err.Error())
+			continue
+		}
+
+		if article.EmbeddingStatus == "success" {
+			continue
+		}
+
+		text := strings.TrimSpace(article.Title + " " + article.Description)
+		if text == "" {
+			continue
+		}
+		texts = append(texts, embedding.ArticleText{ID: article.ID.String(), Text: text, Target: embedding.TargetTitle, Language: article.Language})
+
+		if content := strings.TrimSpace(article.Content); content != "" && article.ContentEmbeddingStatus != "success" {
+			texts = append(texts, embedding.ArticleText{ID: article.ID.String(), Text: content, Target: embedding.TargetContent, Language: article.Language})
+		}
+	}
+
+	if len(texts) > 0 {
+		storeResp, err := s.embeddingClient.StoreArticleEmbeddings(ctx, texts)
+		if err != nil {
+			s.logger.Error("Failed to embed imported articles for user " + userID.String() + ": " + err.Error())
+			return nil, fmt.Errorf("failed to embed imported articles: %w", err)
+		}
+		result.Embedded = storeResp.SuccessfulUpdates
+	}
+
+	// Seed ratings from favorite/read flags
+	for _, seed := range seeds {
+		score := 0
+		switch {
+		case seed.Favorite:
+			score = favoriteSeedScore
+		case seed.Read:
+			score = readSeedScore
+		default:
+			continue
+		}
+
+		created, err := s.ratingRepo.CreateIfAbsent(&Rating{
+			UserID:    userID,
+			ArticleID: seed.ArticleID,
+			Score:     score,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			s.logger.Error("Failed to seed rating for article " + seed.ArticleID.String() + " by user " + userID.String() + ": " + err.Error())
+			continue
+		}
+		if created {
+			result.SeededRatings++
+		}
+	}
+	if result.SeededRatings > 0 {
+		s.cache.invalidate(userID)
+		s.RefreshProfile(userID)
+	}
+
+	s.logger.Info("Primed recommendation profile for user " + userID.String() + ": " + fmt.Sprintf("%d", result.Embedded) + " embedded, " + fmt.Sprintf("%d", result.SeededRatings) + " ratings seeded")
+
+	return result, nil
+}
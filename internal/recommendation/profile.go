@@ -0,0 +1,295 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// UserProfile is a user's stored preference vector: the weighted mean of the
+// embeddings of the articles they liked. Weights records what each article
+// contributes, so a change to the user's ratings only embeds the articles it
+// affects.
+type UserProfile struct {
+	UserID    uuid.UUID             `gorm:"type:uuid;primaryKey"`
+	Space     EmbeddingSpace        `gorm:"size:20;not null"` // Space the article texts were embedded for
+	Embedding []float64             `gorm:"type:vector(384);not null"`
+	Weights   map[uuid.UUID]float64 `gorm:"type:jsonb;serializer:json;not null"`
+	UpdatedAt time.Time             `gorm:"autoUpdateTime"`
+}
+
+// totalWeight sums the weights of the articles in the profile
+func (p *UserProfile) totalWeight() float64 {
+	total := 0.0
+	for _, weight := range p.Weights {
+		total += weight
+	}
+	return total
+}
+
+// userProfile returns the user's profile, or nil when they liked nothing that
+// can be embedded. The stored profile is used when there is one; otherwise the
+// profile is built and stored for the next request.
+func (c *ContentBasedEngine) userProfile(ctx context.Context, userID uuid.UUID) (*UserProfile, error) {
+	if c.profiles == nil {
+		return c.buildProfile(ctx, userID, nil)
+	}
+
+	dbCtx, cancel := utils.DeriveDeadline(ctx, databaseBudgetShare)
+	stored, err := c.profiles.WithContext(dbCtx).FindProfile(userID)
+	cancel()
+	switch {
+	case err == nil && stored.Space == c.settings.EmbeddingSpace:
+		return stored, nil
+	case err != nil && !errors.Is(err, ErrProfileNotFound):
+		c.logger.Error("Failed to get profile of user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	profile, err := c.buildProfile(ctx, userID, nil)
+	if err != nil || profile == nil {
+		return nil, err
+	}
+
+	// A refresh running since keeps its newer profile, but a profile of
+	// another space is replaced
+	dbCtx, cancel = utils.DeriveDeadline(ctx, databaseBudgetShare)
+	if stored != nil {
+		err = c.profiles.WithContext(dbCtx).SaveProfile(profile)
+	} else {
+		err = c.profiles.WithContext(dbCtx).CreateProfileIfAbsent(profile)
+	}
+	cancel()
+	if err != nil {
+		c.logger.Error("Failed to store profile of user " + userID.String() + ": " + err.Error())
+	}
+
+	return profile, nil
+}
+
+// updateProfile brings the user's stored profile up to date with their
+// ratings and reactions, or deletes it when nothing they liked is left
+func (c *ContentBasedEngine) updateProfile(ctx context.Context, userID uuid.UUID) error {
+	repo := c.profiles.WithContext(ctx)
+
+	stored, err := repo.FindProfile(userID)
+	if err != nil && !errors.Is(err, ErrProfileNotFound) {
+		return err
+	}
+
+	profile, err := c.buildProfile(ctx, userID, stored)
+	if err != nil {
+		return err
+	}
+	if profile == nil {
+		if stored == nil {
+			return nil
+		}
+		return repo.DeleteProfile(userID)
+	}
+	if profile == stored {
+		return nil
+	}
+
+	return repo.SaveProfile(profile)
+}
+
+// buildProfile computes the user's profile from their favorites, ratings and
+// reactions.
+// Starting from a stored profile, only the articles whose weight changed are
+// embedded, and their difference is applied to the stored mean. It returns
+// the stored profile when nothing changed, and nil when the user liked nothing
+// that can be embedded.
+func (c *ContentBasedEngine) buildProfile(ctx context.Context, userID uuid.UUID, stored *UserProfile) (*UserProfile, error) {
+	// Get user's highly rated articles to build profile
+	dbCtx, cancel := utils.DeriveDeadline(ctx, databaseBudgetShare)
+	userRatings, err := c.ratingRepo.WithContext(dbCtx).FindByUserID(userID)
+	cancel()
+	if err != nil {
+		c.logger.Error("Failed to get user ratings: " + err.Error())
+		return nil, err
+	}
+
+	// Quick reactions count for articles the user has not rated numerically
+	dbCtx, cancel = utils.DeriveDeadline(ctx, databaseBudgetShare)
+	userReactions, err := c.ratingRepo.WithContext(dbCtx).FindReactionsByUserID(userID)
+	cancel()
+	if err != nil {
+		c.logger.Error("Failed to get user reactions: " + err.Error())
+		return nil, err
+	}
+
+	// Favorites count fully, whatever their rating
+	dbCtx, cancel = utils.DeriveDeadline(ctx, databaseBudgetShare)
+	userFavorites, err := c.ratingRepo.WithContext(dbCtx).FindFavoritesByUserID(userID)
+	cancel()
+	if err != nil {
+		c.logger.Error("Failed to get user favorites: " + err.Error())
+		return nil, err
+	}
+
+	signals := profileSignals(userRatings, userReactions, userFavorites, c.settings.minRating())
+
+	// Profiles embedded for another space are rebuilt
+	if stored != nil && stored.Space != c.settings.EmbeddingSpace {
+		stored = nil
+	}
+	previous := map[uuid.UUID]float64{}
+	if stored != nil {
+		previous = stored.Weights
+	}
+
+	weights := make(map[uuid.UUID]float64, len(signals))
+	var changed []uuid.UUID
+	for _, signal := range signals {
+		weights[signal.articleID] = signal.weight
+		if weight, ok := previous[signal.articleID]; !ok || weight != signal.weight {
+			changed = append(changed, signal.articleID)
+		}
+	}
+	for articleID := range previous {
+		if _, ok := weights[articleID]; !ok {
+			changed = append(changed, articleID)
+		}
+	}
+
+	if stored != nil {
+		if len(changed) == 0 {
+			return stored, nil
+		}
+		// Rebuilding costs no more than applying the changes
+		if len(changed) >= len(signals) {
+			return c.buildProfile(ctx, userID, nil)
+		}
+	}
+
+	// Collect the changed articles for embedding generation
+	var changedIDs []uuid.UUID
+	var changedTexts []string
+	for _, articleID := range changed {
+		// Skipping articles is fine, but not once the budget is spent
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		dbCtx, cancel := utils.DeriveDeadline(ctx, databaseBudgetShare)
+		article, err := c.articleRepo.WithContext(dbCtx).FindByID(articleID)
+		cancel()
+		text := ""
+		if err != nil {
+			c.logger.Error("Failed to get article " + articleID.String() + ": " + err.Error())
+		} else {
+			text = profileText(article, c.settings.EmbeddingSpace)
+		}
+
+		if text == "" {
+			// The contribution of an article that is gone cannot be taken out
+			if _, ok := previous[articleID]; ok {
+				return c.buildProfile(ctx, userID, nil)
+			}
+			delete(weights, articleID)
+			continue
+		}
+		changedIDs = append(changedIDs, articleID)
+		changedTexts = append(changedTexts, text)
+	}
+
+	profile := &UserProfile{UserID: userID, Space: c.settings.EmbeddingSpace, Weights: weights, UpdatedAt: time.Now()}
+	total := profile.totalWeight()
+	if len(weights) == 0 || total <= 0 {
+		return nil, nil
+	}
+
+	var embeddings [][]float64
+	if len(changedTexts) > 0 {
+		embeddingCtx, cancel := utils.DeriveDeadline(ctx, embeddingBudgetShare)
+		embeddings, err = c.embeddingClient.GetBatchEmbeddings(embeddingCtx, changedTexts)
+		cancel()
+		if err != nil {
+			c.logger.Error("Failed to get user embeddings: " + err.Error())
+			return nil, err
+		}
+		if len(embeddings) != len(changedTexts) {
+			return nil, fmt.Errorf("embedding service returned %d embeddings for %d articles", len(embeddings), len(changedTexts))
+		}
+	}
+
+	// Apply each changed weight to the weighted sum of the stored profile
+	var sum []float64
+	if stored != nil {
+		previousTotal := stored.totalWeight()
+		sum = make([]float64, len(stored.Embedding))
+		for i, value := range stored.Embedding {
+			sum[i] = value * previousTotal
+		}
+	}
+	for i, articleID := range changedIDs {
+		if sum == nil {
+			sum = make([]float64, len(embeddings[i]))
+		}
+		delta := weights[articleID] - previous[articleID]
+		for j, value := range embeddings[i] {
+			sum[j] += value * delta
+		}
+	}
+
+	// Normalize by total weight
+	for i := range sum {
+		sum[i] /= total
+	}
+	profile.Embedding = sum
+
+	return profile, nil
+}
+
+// profileRefreshTimeout bounds a background profile refresh
+const profileRefreshTimeout = time.Minute
+
+// RefreshProfile updates the user's stored profile in the background. Changes
+// arriving while a refresh runs are picked up by one more refresh once it is
+// done, so refreshes of a user never overlap.
+func (s *service) RefreshProfile(userID uuid.UUID) {
+	if s.content.profiles == nil {
+		return
+	}
+
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+
+	if _, running := s.refreshing[userID]; running {
+		s.refreshing[userID] = true
+		return
+	}
+	s.refreshing[userID] = false
+	go s.refreshProfile(userID)
+}
+
+func (s *service) refreshProfile(userID uuid.UUID) {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), profileRefreshTimeout)
+		err := s.content.updateProfile(ctx, userID)
+		cancel()
+		if err != nil {
+			// Without the outdated profile the next request rebuilds it
+			s.logger.Error("Failed to refresh profile of user " + userID.String() + ": " + err.Error())
+			ctx, cancel := context.WithTimeout(context.Background(), profileRefreshTimeout)
+			if err := s.content.profiles.WithContext(ctx).DeleteProfile(userID); err != nil {
+				s.logger.Error("Failed to drop outdated profile of user " + userID.String() + ": " + err.Error())
+			}
+			cancel()
+		}
+
+		s.profileMu.Lock()
+		if !s.refreshing[userID] {
+			delete(s.refreshing, userID)
+			s.profileMu.Unlock()
+			return
+		}
+		s.refreshing[userID] = false
+		s.profileMu.Unlock()
+	}
+}
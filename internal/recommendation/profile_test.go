@@ -0,0 +1,362 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryProfileRepository keeps profiles, interests and stored lists in memory
+type memoryProfileRepository struct {
+	mu        sync.Mutex
+	profiles  map[uuid.UUID]*UserProfile
+	interests map[uuid.UUID]*UserInterests
+	lists     map[cacheKey]*StoredList
+}
+
+func newMemoryProfileRepository() *memoryProfileRepository {
+	return &memoryProfileRepository{profiles: make(map[uuid.UUID]*UserProfile), interests: make(map[uuid.UUID]*UserInterests), lists: make(map[cacheKey]*StoredList)}
+}
+
+func (m *memoryProfileRepository) WithContext(ctx context.Context) ProfileRepository {
+	return m
+}
+
+func (m *memoryProfileRepository) FindProfile(userID uuid.UUID) (*UserProfile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	profile, ok := m.profiles[userID]
+	if !ok {
+		return nil, ErrProfileNotFound
+	}
+	return profile, nil
+}
+
+func (m *memoryProfileRepository) SaveProfile(profile *UserProfile) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.profiles[profile.UserID] = profile
+	return nil
+}
+
+func (m *memoryProfileRepository) CreateProfileIfAbsent(profile *UserProfile) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.profiles[profile.UserID]; !ok {
+		m.profiles[profile.UserID] = profile
+	}
+	return nil
+}
+
+func (m *memoryProfileRepository) DeleteProfile(userID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.profiles, userID)
+	return nil
+}
+
+func (m *memoryProfileRepository) FindInterests(userID uuid.UUID) (*UserInterests, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	interests, ok := m.interests[userID]
+	if !ok {
+		return nil, ErrInterestsNotFound
+	}
+	return interests, nil
+}
+
+func (m *memoryProfileRepository) SaveInterests(interests *UserInterests) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.interests[interests.UserID] = interests
+	return nil
+}
+
+func (m *memoryProfileRepository) DeleteInterests(userID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.interests, userID)
+	return nil
+}
+
+func (m *memoryProfileRepository) FindStoredList(userID uuid.UUID, engine string) (*StoredList, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list, ok := m.lists[cacheKey{userID, engine}]
+	if !ok {
+		return nil, ErrStoredListNotFound
+	}
+	return list, nil
+}
+
+func (m *memoryProfileRepository) SaveStoredList(list *StoredList) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lists[cacheKey{list.UserID, list.Engine}] = list
+	return nil
+}
+
+func (m *memoryProfileRepository) DeleteStoredLists(userID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.lists {
+		if key.userID == userID {
+			delete(m.lists, key)
+		}
+	}
+	return nil
+}
+
+func (m *memoryProfileRepository) DeleteStoredListsBefore(computedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, list := range m.lists {
+		if list.ComputedAt.Before(computedAt) {
+			delete(m.lists, key)
+		}
+	}
+	return nil
+}
+
+// titledArticleRepository gives each article a title of its own, so their
+// embeddings differ
+type titledArticleRepository struct {
+	mockArticleRepository
+	titles map[uuid.UUID]string
+}
+
+func (m *titledArticleRepository) WithContext(ctx context.Context) ArticleRepository {
+	return m
+}
+
+func (m *titledArticleRepository) FindByID(id uuid.UUID) (*Article, error) {
+	title, ok := m.titles[id]
+	if !ok {
+		return nil, ErrArticleNotFound
+	}
+	return &Article{ID: id, Title: title}, nil
+}
+
+// changingRatingRepository returns ratings a test can change
+type changingRatingRepository struct {
+	mockRatingRepository
+	mu      sync.Mutex
+	ratings []*Rating
+}
+
+func (m *changingRatingRepository) WithContext(ctx context.Context) RatingRepository {
+	return m
+}
+
+func (m *changingRatingRepository) FindByUserID(userID uuid.UUID) ([]*Rating, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*Rating(nil), m.ratings...), nil
+}
+
+func (m *changingRatingRepository) rate(articleID uuid.UUID, score int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, rating := range m.ratings {
+		if rating.ArticleID == articleID {
+			rating.Score = score
+			return
+		}
+	}
+	m.ratings = append(m.ratings, &Rating{ArticleID: articleID, Score: score})
+}
+
+// countingEmbeddingClient records the texts it embedded
+type countingEmbeddingClient struct {
+	mockEmbeddingClient
+	mu       sync.Mutex
+	embedded []string
+}
+
+func (m *countingEmbeddingClient) GetBatchEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	m.mu.Lock()
+	m.embedded = append(m.embedded, texts...)
+	m.mu.Unlock()
+	return m.mockEmbeddingClient.GetBatchEmbeddings(ctx, texts)
+}
+
+func (m *countingEmbeddingClient) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.embedded)
+}
+
+func TestUserProfile(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+	titles := map[uuid.UUID]string{first: "Go", second: "Rust ownership", third: "Zig comptime and build scripts"}
+
+	setup := func() (*ContentBasedEngine, *changingRatingRepository, *memoryProfileRepository, *countingEmbeddingClient) {
+		ratings := &changingRatingRepository{ratings: []*Rating{
+			{UserID: userID, ArticleID: first, Score: 5},
+			{UserID: userID, ArticleID: second, Score: 4},
+		}}
+		profiles := newMemoryProfileRepository()
+		client := &countingEmbeddingClient{}
+		engine := newContentBasedEngine(&titledArticleRepository{titles: titles}, ratings, profiles, client, Settings{EmbeddingSpace: SpaceTitle}, log)
+		return engine, ratings, profiles, client
+	}
+
+	t.Run("Profile is stored and read by later requests", func(t *testing.T) {
+		engine, _, profiles, client := setup()
+
+		_, err := engine.Recommend(context.Background(), userID, 5)
+		require.NoError(t, err)
+		assert.Equal(t, 2, client.count())
+
+		stored, err := profiles.FindProfile(userID)
+		require.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]float64{first: 1.0, second: 0.8}, stored.Weights)
+		assert.Equal(t, SpaceTitle, stored.Space)
+
+		_, err = engine.Recommend(context.Background(), userID, 5)
+		require.NoError(t, err)
+		assert.Equal(t, 2, client.count(), "stored profile used without embedding")
+	})
+
+	t.Run("Rating changes only embed the affected articles", func(t *testing.T) {
+		engine, ratings, profiles, client := setup()
+		require.NoError(t, engine.updateProfile(context.Background(), userID))
+		require.Equal(t, 2, client.count())
+
+		ratings.rate(second, 5)
+		ratings.rate(third, 4)
+		require.NoError(t, engine.updateProfile(context.Background(), userID))
+		assert.ElementsMatch(t, []string{titles[second], titles[third]}, client.embedded[2:])
+
+		stored, err := profiles.FindProfile(userID)
+		require.NoError(t, err)
+		rebuilt, err := engine.buildProfile(context.Background(), userID, nil)
+		require.NoError(t, err)
+		assert.Equal(t, rebuilt.Weights, stored.Weights)
+		assert.InDeltaSlice(t, rebuilt.Embedding, stored.Embedding, 1e-9)
+
+		// Nothing changed since
+		embedded := client.count()
+		require.NoError(t, engine.updateProfile(context.Background(), userID))
+		assert.Equal(t, embedded, client.count())
+	})
+
+	t.Run("Profile is dropped once nothing liked is left", func(t *testing.T) {
+		engine, ratings, profiles, _ := setup()
+		require.NoError(t, engine.updateProfile(context.Background(), userID))
+
+		ratings.rate(first, 2)
+		ratings.rate(second, 1)
+		require.NoError(t, engine.updateProfile(context.Background(), userID))
+
+		_, err := profiles.FindProfile(userID)
+		assert.ErrorIs(t, err, ErrProfileNotFound)
+	})
+
+	t.Run("Profile of another space is rebuilt", func(t *testing.T) {
+		engine, _, profiles, client := setup()
+		require.NoError(t, profiles.SaveProfile(&UserProfile{UserID: userID, Space: SpaceContent, Embedding: []float64{1}, Weights: map[uuid.UUID]float64{first: 1.0}}))
+
+		_, err := engine.Recommend(context.Background(), userID, 5)
+		require.NoError(t, err)
+		assert.Equal(t, 2, client.count())
+
+		stored, err := profiles.FindProfile(userID)
+		require.NoError(t, err)
+		assert.Equal(t, SpaceTitle, stored.Space)
+	})
+
+	t.Run("Service refreshes the profile in the background", func(t *testing.T) {
+		ratings := &changingRatingRepository{ratings: []*Rating{{UserID: userID, ArticleID: first, Score: 5}}}
+		profiles := newMemoryProfileRepository()
+		svc, err := NewService(&config.RecommendationConfig{}, &titledArticleRepository{titles: titles}, ratings, profiles, nil, &countingEmbeddingClient{}, log)
+		require.NoError(t, err)
+
+		svc.RefreshProfile(userID)
+		assert.Eventually(t, func() bool {
+			profile, err := profiles.FindProfile(userID)
+			return err == nil && len(profile.Weights) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		ratings.rate(second, 4)
+		svc.RefreshProfile(userID)
+		assert.Eventually(t, func() bool {
+			profile, err := profiles.FindProfile(userID)
+			return err == nil && len(profile.Weights) == 2
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestInterests(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	profiles := newMemoryProfileRepository()
+	client := &countingEmbeddingClient{}
+	repo := &filteringArticleRepository{candidates: []*Article{{ID: uuid.New(), URL: "https://example.com/go", Distance: 0.2}}}
+	svc, err := NewService(&config.RecommendationConfig{}, repo, &mockRatingRepository{}, profiles, nil, client, log)
+	require.NoError(t, err)
+
+	t.Run("Topics are normalized", func(t *testing.T) {
+		topics, err := normalizeInterests([]string{" Go ", "distributed   systems", "go", ""})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Go", "distributed systems"}, topics)
+
+		_, err = normalizeInterests([]string{strings.Repeat("x", maxInterestLength+1)})
+		assert.ErrorIs(t, err, utils.ErrValidation)
+		tooMany := make([]string, maxInterests+1)
+		for i := range tooMany {
+			tooMany[i] = fmt.Sprintf("topic %d", i)
+		}
+		_, err = normalizeInterests(tooMany)
+		assert.ErrorIs(t, err, utils.ErrValidation)
+	})
+
+	t.Run("Without interests cold start falls back to popular articles", func(t *testing.T) {
+		recommendations, err := svc.GetRecommendations(context.Background(), userID, EngineContent, 5)
+		require.NoError(t, err)
+		require.NotEmpty(t, recommendations)
+		assert.Equal(t, ScoreTypePopularity, recommendations[0].ScoreType)
+	})
+
+	t.Run("Interests form the initial profile", func(t *testing.T) {
+		response, err := svc.SetInterests(context.Background(), userID, []string{"Go", "databases"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Go", "databases"}, response.Topics)
+		assert.ElementsMatch(t, []string{"Go", "databases"}, client.embedded)
+
+		stored, err := profiles.FindInterests(userID)
+		require.NoError(t, err)
+		assert.Len(t, stored.Embedding, 384)
+
+		// The list cached before the interests were set is dropped
+		recommendations, err := svc.GetRecommendations(context.Background(), userID, EngineContent, 5)
+		require.NoError(t, err)
+		require.Len(t, recommendations, 1)
+		assert.Equal(t, ScoreTypeSimilarity, recommendations[0].ScoreType)
+		assert.Equal(t, "Similar to your interests: Go, databases", recommendations[0].Reason)
+		assert.Equal(t, 2, client.count(), "topics are not embedded again")
+	})
+
+	t.Run("An empty list clears the interests", func(t *testing.T) {
+		_, err := svc.SetInterests(context.Background(), userID, nil)
+		require.NoError(t, err)
+		_, err = profiles.FindInterests(userID)
+		assert.ErrorIs(t, err, ErrInterestsNotFound)
+	})
+}
@@ -0,0 +1,34 @@
+package recommendation
+
+import (
+	"context"
+	"time"
+
+	"github.com/dustin/articles-backend/pkg/events"
+)
+
+// feedbackTimeout bounds crediting a recommendation while a rating is saved
+const feedbackTimeout = 2 * time.Second
+
+// SubscribeRatingEvents keeps svc up to date with rating changes published on
+// the bus: a changed rating or reaction drops the user's cached
+// recommendations and refreshes their stored profile, and one that counts as
+// liking the article credits the recommendation the link came from.
+func SubscribeRatingEvents(bus *events.Bus, svc Service) {
+	bus.Subscribe(func(event events.Event) {
+		changed, ok := event.(*events.RatingChanged)
+		if !ok {
+			return
+		}
+
+		svc.InvalidateRecommendations(changed.UserID)
+		svc.RefreshProfile(changed.UserID)
+
+		// Failures are logged by the service and must not fail the rating
+		if changed.Liked {
+			ctx, cancel := context.WithTimeout(context.Background(), feedbackTimeout)
+			defer cancel()
+			_ = svc.RecordFeedback(ctx, changed.UserID, changed.ArticleID)
+		}
+	}, events.RatingEvents...)
+}
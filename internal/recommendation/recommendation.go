@@ -1,41 +1,198 @@
 package recommendation
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/dustin/articles-backend/internal/utils"
 	"github.com/google/uuid"
 )
 
 // Engine interface for recommendation algorithms
 type Engine interface {
-	Recommend(userID uuid.UUID, limit int) ([]*RecommendedArticle, error)
+	// Recommend generates recommendations within the deadline of ctx
+	Recommend(ctx context.Context, userID uuid.UUID, limit int) ([]*RecommendedArticle, error)
 	Name() string
 }
 
-// RecommendedArticle represents a recommended article with scoring
+// RecommendedArticle represents a recommended article with scoring. Score is
+// normalized so engines can be compared; RawScore is what the engine measured,
+// on the scale named by ScoreType.
 type RecommendedArticle struct {
 	Article         *Article `json:"article"`
-	Score           float64  `json:"score"`
+	Score           float64  `json:"score"` // Percentile among the engine's candidates, 0-1
+	RawScore        float64  `json:"raw_score"`
+	ScoreType       string   `json:"score_type"`
 	Reason          string   `json:"reason"`
 	RecommenderUsed string   `json:"recommender_used"`
+	// Why the article was recommended, for engines that can tell; nil otherwise
+	Explanation *Explanation `json:"explanation,omitempty"`
+}
+
+// Explanation relates a recommendation to the articles the user liked, so
+// clients can show "because you rated X"
+type Explanation struct {
+	MatchedTopics     []string    `json:"matched_topics"`      // The user's tags on the similar articles
+	SimilarArticleIDs []uuid.UUID `json:"similar_article_ids"` // The user's liked articles it resembles most, closest first
+	Similarity        float64     `json:"similarity"`          // Cosine similarity to the closest of them
+}
+
+// Names of the engines a request can pick
+const (
+	EngineContent       = "content"
+	EnginePopular       = "popular"
+	EngineCollaborative = "collaborative"
+	EngineHybrid        = "hybrid"
+	EngineBandit        = "bandit"
+)
+
+// ErrArticleNotFound is returned by ArticleRepository.FindByID for unknown articles
+var ErrArticleNotFound = utils.NewNotFoundError("article not found")
+
+// ErrProfileNotFound is returned by ProfileRepository.FindProfile for users without a stored profile
+var ErrProfileNotFound = utils.NewNotFoundError("profile not found")
+
+// ErrUnknownEngine is returned for engine names that are not registered
+var ErrUnknownEngine = utils.NewValidationError("engine", "must be one of content, popular, collaborative, hybrid or bandit")
+
+// CandidateFilter leaves articles the user already knows out of a search for
+// recommendations
+type CandidateFilter struct {
+	// UserID is whose recommendations these are; their own articles and the
+	// articles they rated are never candidates
+	UserID uuid.UUID
+	// ExcludeSavedURLs also leaves out other readers' copies of links the user
+	// saved, including ones in their trash
+	ExcludeSavedURLs bool
 }
 
 // Repository interfaces for data access
 type ArticleRepository interface {
 	FindByID(id uuid.UUID) (*Article, error)
+	// FindByIDs returns the articles among ids with the names of their tags
+	FindByIDs(ids []uuid.UUID) ([]*Article, error)
 	FindAll() ([]*Article, error)
-	FindPopular(limit int) ([]*Article, error)
-	FindSimilar(embedding []float64, userID uuid.UUID, limit int) ([]*Article, error)
+	// FindPopular ranks other readers' articles by popularity, blending its
+	// signals with the weights
+	FindPopular(filter CandidateFilter, weights PopularityWeights, limit int) ([]*Article, error)
+	FindSimilar(embedding []float64, filter CandidateFilter, space EmbeddingSpace, titleWeight float64, limit int) ([]*Article, error)
+	FindSimilarInLibrary(embedding []float64, userID uuid.UUID, space EmbeddingSpace, titleWeight float64, limit int) ([]*Article, error)
+	// FindLikedByNeighbours returns articles rated highly by readers who rated
+	// the same links highly as the user, one per link the user has not saved,
+	// highest Affinity first
+	FindLikedByNeighbours(userID uuid.UUID, limit int) ([]*Article, error)
+
+	// WithContext returns a repository whose queries are bound to ctx
+	WithContext(ctx context.Context) ArticleRepository
+}
+
+// EmbeddingSpace selects which article embedding similarity is measured against
+type EmbeddingSpace string
+
+const (
+	SpaceTitle   EmbeddingSpace = "title"   // title + description embedding
+	SpaceContent EmbeddingSpace = "content" // full content embedding
+	SpaceBlended EmbeddingSpace = "blended" // weighted mix of both distances
+)
+
+// ParseEmbeddingSpace validates an embedding space name
+func ParseEmbeddingSpace(value string) (EmbeddingSpace, error) {
+	switch space := EmbeddingSpace(strings.ToLower(strings.TrimSpace(value))); space {
+	case SpaceTitle, SpaceContent, SpaceBlended:
+		return space, nil
+	default:
+		return "", fmt.Errorf("unknown embedding space '%s'", value)
+	}
 }
 
 type RatingRepository interface {
 	FindByUserID(userID uuid.UUID) ([]*Rating, error)
 	GetAverageRating(articleID uuid.UUID) (float64, int, error)
+	CreateIfAbsent(rating *Rating) (bool, error)
+	FindReactionsByUserID(userID uuid.UUID) ([]*Reaction, error)
+	// FindFavoritesByUserID returns the IDs of the user's favorite articles
+	FindFavoritesByUserID(userID uuid.UUID) ([]uuid.UUID, error)
+
+	// WithContext returns a repository whose queries are bound to ctx
+	WithContext(ctx context.Context) RatingRepository
+}
+
+// ProfileRepository stores the preference profiles of users
+type ProfileRepository interface {
+	FindProfile(userID uuid.UUID) (*UserProfile, error)
+	// SaveProfile stores the profile, replacing the one stored for the user
+	SaveProfile(profile *UserProfile) error
+	// CreateProfileIfAbsent stores the profile unless one is stored for the user
+	CreateProfileIfAbsent(profile *UserProfile) error
+	DeleteProfile(userID uuid.UUID) error
+
+	// Interests picked by users who have not liked anything yet
+	FindInterests(userID uuid.UUID) (*UserInterests, error)
+	// SaveInterests stores the interests, replacing the ones stored for the user
+	SaveInterests(interests *UserInterests) error
+	DeleteInterests(userID uuid.UUID) error
+
+	// Recommendation lists precomputed by the background job
+	FindStoredList(userID uuid.UUID, engine string) (*StoredList, error)
+	// SaveStoredList stores the list, replacing the one stored for the user and engine
+	SaveStoredList(list *StoredList) error
+	// DeleteStoredLists drops the user's lists of every engine
+	DeleteStoredLists(userID uuid.UUID) error
+	// DeleteStoredListsBefore drops lists computed before the given time
+	DeleteStoredListsBefore(computedAt time.Time) error
+
+	// WithContext returns a repository whose queries are bound to ctx
+	WithContext(ctx context.Context) ProfileRepository
 }
 
 // Service defines the interface for recommendation business logic
 type Service interface {
-	GetRecommendations(userID uuid.UUID, limit int) ([]*RecommendedArticle, error)
+	// GetRecommendations runs the named engine, or the configured default for
+	// an empty name
+	GetRecommendations(ctx context.Context, userID uuid.UUID, engine string, limit int) ([]*RecommendedArticle, error)
+	// GetRecommendationPage returns a page of a new list, or of a list that
+	// stays fixed between pages, and remembers its articles as seen
+	GetRecommendationPage(ctx context.Context, userID uuid.UUID, request PageRequest) (*RecommendationPage, error)
+	PrimeProfile(ctx context.Context, userID uuid.UUID, seeds []ProfileSeed) (*PrimeResult, error)
+	// PrecomputeRecommendations computes, stores and caches recommendations
+	// for each user, returning how many users were cached
+	PrecomputeRecommendations(ctx context.Context, userIDs []uuid.UUID) (int, error)
+	// InvalidateRecommendations drops the cached and stored lists of the user,
+	// so the next request reflects changed ratings
+	InvalidateRecommendations(userID uuid.UUID)
+	// RefreshProfile updates the user's stored profile in the background
+	// after their ratings changed
+	RefreshProfile(userID uuid.UUID)
+	// RecordFeedback tells the bandit engine the user acted on the article,
+	// e.g. opened it or liked their own copy of its link
+	RecordFeedback(ctx context.Context, userID, articleID uuid.UUID) error
+	// RecordEvents stores impressions and clicks the user's client reported
+	// on recommended articles
+	RecordEvents(ctx context.Context, userID uuid.UUID, events []EventInput) error
+	// EngineCTR computes the click-through rate of each engine over the last
+	// days, 7 for 0
+	EngineCTR(ctx context.Context, days int) (*CTRResponse, error)
+	SemanticSearch(ctx context.Context, userID uuid.UUID, query string, space string, limit int) (*SemanticSearchResponse, error)
+	// SimilarArticles finds other readers' articles like one of the user's own
+	SimilarArticles(ctx context.Context, userID, articleID uuid.UUID, limit int) (*SimilarArticlesResponse, error)
+	// SetInterests replaces the topics that seed recommendations for a user
+	// who has not liked anything yet
+	SetInterests(ctx context.Context, userID uuid.UUID, topics []string) (*InterestsResponse, error)
+}
+
+// ProfileSeed describes an imported article and the signals carried over from the source
+type ProfileSeed struct {
+	ArticleID uuid.UUID
+	Favorite  bool
+	Read      bool
+}
+
+// PrimeResult summarizes a profile warm-up
+type PrimeResult struct {
+	Embedded      int `json:"embedded"`
+	SeededRatings int `json:"seeded_ratings"`
 }
 
 // Forward declarations for GORM relationships
@@ -46,23 +203,45 @@ type Article struct {
 	Title           string    `gorm:"size:500"`
 	Description     string    `gorm:"type:text"`
 	Content         string    `gorm:"type:text"`
+	ContentKey      string    `gorm:"size:255" json:"-"` // Set while Content lives in object storage
 	ImageURL        string    `gorm:"size:2048"`
 	WordCount       int       `gorm:"default:0"`
+	Language        string    `gorm:"size:8"`
 	MetadataStatus  string    `gorm:"size:20;default:'pending'"`
 	Embedding       []float64 `gorm:"type:vector(384);index" json:"-"` // Store embedding for recommendations
 	EmbeddingStatus string    `gorm:"size:20;default:'pending'"`       // Track embedding generation status
-	CreatedAt       time.Time `gorm:"autoCreateTime"`
-	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+	// Full-content embedding, kept apart from the title+description embedding above
+	ContentEmbedding       []float64 `gorm:"type:vector(384)" json:"-"`
+	ContentEmbeddingStatus string    `gorm:"size:20;default:'pending'"`
+	CreatedAt              time.Time `gorm:"autoCreateTime"`
+	UpdatedAt              time.Time `gorm:"autoUpdateTime"`
+
+	// Computed by the query that found the article; not stored
+	Distance   float64 `gorm:"->;-:migration" json:"-"` // Cosine distance to the query vector, from similarity searches
+	Popularity float64 `gorm:"->;-:migration" json:"-"` // From popularity searches, see PopularityWeights
+	Affinity   float64 `gorm:"->;-:migration" json:"-"` // From collaborative searches
+
+	// Names of the owner's tags, filled in by FindByIDs
+	Tags []string `gorm:"-" json:"-"`
 }
 
 type Rating struct {
 	UserID    uuid.UUID `gorm:"type:uuid;primaryKey"`
 	ArticleID uuid.UUID `gorm:"type:uuid;primaryKey"`
 	Score     int       `gorm:"not null;check:score >= 1 AND score <= 5"`
+	Reaction  string    `gorm:"size:20;not null;default:''"` // like, insightful, skip or empty
 	CreatedAt time.Time `gorm:"autoCreateTime"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime"`
 }
 
+// Reaction is a one-tap feedback on an article (forward declaration)
+type Reaction struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ArticleID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Kind      string    `gorm:"primaryKey;size:20"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
 type User struct {
 	ID    uuid.UUID `gorm:"type:uuid;primaryKey"`
 	Email string    `gorm:"uniqueIndex;not null;size:255"`
@@ -75,6 +254,8 @@ type RecommendationResponse struct {
 	EngineUsed      string                `json:"engine_used"`
 	UserID          uuid.UUID             `json:"user_id"`
 	Count           int                   `json:"count"`
+	// NextCursor continues a cursor-paginated listing; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // ToResponse converts a slice of RecommendedArticle to RecommendationResponse
@@ -87,3 +268,28 @@ func BuildRecommendationResponse(recommendations []*RecommendedArticle, userID u
 		Count:           len(recommendations),
 	}
 }
+
+// BuildRecommendationPageResponse creates a cursor-paginated recommendation
+// list; generated_at is when the list being paged through was computed
+func BuildRecommendationPageResponse(page *RecommendationPage, userID uuid.UUID, engineUsed string) *RecommendationResponse {
+	response := BuildRecommendationResponse(page.Recommendations, userID, engineUsed)
+	response.GeneratedAt = page.GeneratedAt
+	response.NextCursor = page.NextCursor
+	return response
+}
+
+// SimilarArticlesResponse lists articles like one of the user's own, closest first
+type SimilarArticlesResponse struct {
+	ArticleID uuid.UUID             `json:"article_id"`
+	Space     EmbeddingSpace        `json:"space"` // Space the articles were compared in
+	Articles  []*RecommendedArticle `json:"articles"`
+	Count     int                   `json:"count"`
+}
+
+// SemanticSearchResponse lists the user's own articles closest in meaning to a query
+type SemanticSearchResponse struct {
+	Query    string         `json:"query"`
+	Space    EmbeddingSpace `json:"space"`
+	Articles []*Article     `json:"articles"`
+	Count    int            `json:"count"`
+}
@@ -1,11 +1,15 @@
 package recommendation
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/dustin/articles-backend/config"
 	"github.com/dustin/articles-backend/internal/embedding"
+	"github.com/dustin/articles-backend/internal/utils"
 	"github.com/dustin/articles-backend/pkg/logger"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -105,11 +109,11 @@ func TestContentBasedEngine(t *testing.T) {
 		mockEmbeddingClient := &mockEmbeddingClient{}
 
 		// Create engine
-		engine := NewContentBasedEngine(mockArticleRepo, mockRatingRepo, mockEmbeddingClient, log)
+		engine := NewContentBasedEngine(mockArticleRepo, mockRatingRepo, mockEmbeddingClient, Settings{EmbeddingSpace: SpaceTitle}, log)
 
 		// Test recommendation
 		userID := uuid.New()
-		recommendations, err := engine.Recommend(userID, 10)
+		recommendations, err := engine.Recommend(context.Background(), userID, 10)
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, recommendations)
@@ -120,11 +124,69 @@ func TestContentBasedEngine(t *testing.T) {
 			rec := recommendations[0]
 			assert.NotNil(t, rec.Article)
 			assert.Greater(t, rec.Score, 0.0)
+			assert.Equal(t, ScoreTypeSimilarity, rec.ScoreType)
 			assert.NotEmpty(t, rec.Reason)
 			assert.Equal(t, "content-based", rec.RecommenderUsed)
 		}
 	})
 
+	t.Run("Scores come from cosine distances", func(t *testing.T) {
+		engine := NewContentBasedEngine(&mockArticleRepository{}, &mockRatingRepositoryWithRatings{}, &mockEmbeddingClient{}, Settings{EmbeddingSpace: SpaceTitle}, log)
+
+		recommendations, err := engine.Recommend(context.Background(), uuid.New(), 10)
+		require.NoError(t, err)
+		require.Len(t, recommendations, 2)
+		assert.InDelta(t, 0.9, recommendations[0].RawScore, 1e-9)
+		assert.InDelta(t, 0.1, recommendations[1].RawScore, 1e-9)
+		assert.Greater(t, recommendations[0].Score, recommendations[1].Score)
+	})
+
+	t.Run("Reasons reflect the similarity", func(t *testing.T) {
+		svc, err := NewService(&config.RecommendationConfig{}, &mockArticleRepository{}, &mockRatingRepositoryWithRatings{}, nil, nil, &mockEmbeddingClient{}, log)
+		require.NoError(t, err)
+
+		recommendations, err := svc.GetRecommendations(context.Background(), uuid.New(), EngineContent, 10)
+		require.NoError(t, err)
+		require.Len(t, recommendations, 2)
+		assert.Equal(t, "Highly similar to articles you rated highly", recommendations[0].Reason)
+		assert.Equal(t, "Potentially similar to articles you rated highly", recommendations[1].Reason)
+	})
+
+	t.Run("Known articles are excluded", func(t *testing.T) {
+		repo := &filteringArticleRepository{candidates: []*Article{
+			{ID: uuid.New(), URL: "https://example.com/a", Distance: 0.1},
+			{ID: uuid.New(), URL: "https://example.com/a", Distance: 0.2}, // Another reader's copy
+			{ID: uuid.New(), URL: "https://example.com/b", Distance: 0.3},
+		}}
+		userID := uuid.New()
+
+		engine := NewContentBasedEngine(repo, &mockRatingRepositoryWithRatings{}, &mockEmbeddingClient{}, Settings{EmbeddingSpace: SpaceTitle, DedupeURLs: true}, log)
+		recommendations, err := engine.Recommend(context.Background(), userID, 10)
+		require.NoError(t, err)
+		assert.Equal(t, CandidateFilter{UserID: userID, ExcludeSavedURLs: true}, repo.filter)
+		assert.Equal(t, 20, repo.limit, "twice the limit by default")
+		require.Len(t, recommendations, 2)
+		assert.Equal(t, repo.candidates[0].ID, recommendations[0].Article.ID, "the closest copy is kept")
+
+		engine = NewContentBasedEngine(repo, &mockRatingRepositoryWithRatings{}, &mockEmbeddingClient{}, Settings{EmbeddingSpace: SpaceTitle, CandidateMultiplier: 5}, log)
+		_, err = engine.Recommend(context.Background(), userID, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 50, repo.limit)
+		assert.Equal(t, "https://example.com/b", recommendations[1].Article.URL)
+
+		engine = NewContentBasedEngine(repo, &mockRatingRepositoryWithRatings{}, &mockEmbeddingClient{}, Settings{EmbeddingSpace: SpaceTitle}, log)
+		recommendations, err = engine.Recommend(context.Background(), userID, 10)
+		require.NoError(t, err)
+		assert.Equal(t, CandidateFilter{UserID: userID}, repo.filter)
+		assert.Len(t, recommendations, 3)
+
+		popular := NewPopularEngine(repo, Settings{DedupeURLs: true}, log)
+		recommendations, err = popular.Recommend(context.Background(), userID, 10)
+		require.NoError(t, err)
+		assert.Equal(t, CandidateFilter{UserID: userID, ExcludeSavedURLs: true}, repo.filter)
+		assert.Len(t, recommendations, 2)
+	})
+
 	t.Run("Recommend with no user ratings", func(t *testing.T) {
 		// Setup mocks - no ratings
 		mockArticleRepo := &mockArticleRepository{}
@@ -132,28 +194,39 @@ func TestContentBasedEngine(t *testing.T) {
 		mockEmbeddingClient := &mockEmbeddingClient{}
 
 		// Create engine
-		engine := NewContentBasedEngine(mockArticleRepo, mockRatingRepo, mockEmbeddingClient, log)
+		engine := NewContentBasedEngine(mockArticleRepo, mockRatingRepo, mockEmbeddingClient, Settings{EmbeddingSpace: SpaceTitle}, log)
 
 		// Test recommendation - should fall back to popular articles
 		userID := uuid.New()
-		recommendations, err := engine.Recommend(userID, 10)
+		recommendations, err := engine.Recommend(context.Background(), userID, 10)
 
 		assert.NoError(t, err)
 		// Should return popular articles as fallback
 		if len(recommendations) > 0 {
 			rec := recommendations[0]
 			assert.Contains(t, rec.Reason, "Popular article")
+			assert.Equal(t, ScoreTypePopularity, rec.ScoreType)
 		}
 	})
 
+	t.Run("Recommend with expired budget", func(t *testing.T) {
+		engine := NewContentBasedEngine(&mockArticleRepository{}, &mockRatingRepositoryWithRatings{}, &mockEmbeddingClient{}, Settings{EmbeddingSpace: SpaceTitle}, log)
+
+		ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+		defer cancel()
+
+		_, err := engine.Recommend(ctx, uuid.New(), 10)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
 	t.Run("Calculate weighted profile", func(t *testing.T) {
 		mockEmbeddingClient := &mockEmbeddingClient{}
-		engine := NewContentBasedEngine(&mockArticleRepository{}, &mockRatingRepository{}, mockEmbeddingClient, log)
+		engine := NewContentBasedEngine(&mockArticleRepository{}, &mockRatingRepository{}, mockEmbeddingClient, Settings{EmbeddingSpace: SpaceTitle}, log)
 
 		// Test that the engine correctly processes embeddings internally
 		// We can't test the private method directly, but we can test the overall behavior
 		userID := uuid.New()
-		recommendations, err := engine.Recommend(userID, 5)
+		recommendations, err := engine.Recommend(context.Background(), userID, 5)
 
 		// Should succeed without error
 		assert.NoError(t, err)
@@ -162,17 +235,642 @@ func TestContentBasedEngine(t *testing.T) {
 	})
 }
 
+func TestPrimeProfile(t *testing.T) {
+	logConfig := &config.LoggingConfig{
+		Level:  "info",
+		Format: "text",
+	}
+	log, err := logger.NewLogger(logConfig)
+	require.NoError(t, err)
+
+	t.Run("Embeds imported articles and seeds flagged ratings", func(t *testing.T) {
+		service, err := NewService(&config.RecommendationConfig{}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+		require.NoError(t, err)
+
+		result, err := service.PrimeProfile(context.Background(), uuid.New(), []ProfileSeed{
+			{ArticleID: uuid.New(), Favorite: true},
+			{ArticleID: uuid.New(), Read: true},
+			{ArticleID: uuid.New()},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Embedded)
+		assert.Equal(t, 2, result.SeededRatings)
+	})
+
+	t.Run("Existing ratings are kept", func(t *testing.T) {
+		service, err := NewService(&config.RecommendationConfig{}, &mockArticleRepository{}, &mockRatingRepositoryWithRatings{}, nil, nil, &mockEmbeddingClient{}, log)
+		require.NoError(t, err)
+
+		result, err := service.PrimeProfile(context.Background(), uuid.New(), []ProfileSeed{
+			{ArticleID: uuid.New(), Favorite: true},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.SeededRatings)
+	})
+
+	t.Run("No seeds", func(t *testing.T) {
+		service, err := NewService(&config.RecommendationConfig{}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+		require.NoError(t, err)
+
+		result, err := service.PrimeProfile(context.Background(), uuid.New(), nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, &PrimeResult{}, result)
+	})
+}
+
+func TestNewServiceSettings(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "text"})
+	require.NoError(t, err)
+
+	t.Run("Valid embedding spaces", func(t *testing.T) {
+		for _, space := range []string{"title", "content", "blended", "Blended"} {
+			_, err := NewService(&config.RecommendationConfig{EmbeddingSpace: space, BlendWeight: "0.7"}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+			assert.NoError(t, err, space)
+		}
+	})
+
+	t.Run("Invalid values", func(t *testing.T) {
+		_, err := NewService(&config.RecommendationConfig{EmbeddingSpace: "summary"}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+		assert.Error(t, err)
+
+		_, err = NewService(&config.RecommendationConfig{BlendWeight: "1.5"}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+		assert.Error(t, err)
+
+		_, err = NewService(&config.RecommendationConfig{BlendWeight: "half"}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+		assert.Error(t, err)
+
+		_, err = NewService(&config.RecommendationConfig{DedupeURLs: "sometimes"}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+		assert.Error(t, err)
+
+		for _, lambda := range []string{"0", "1.2", "some"} {
+			_, err = NewService(&config.RecommendationConfig{DiversityLambda: lambda}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+			assert.Error(t, err, lambda)
+		}
+
+		_, err = NewService(&config.RecommendationConfig{Engine: "random"}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+		assert.Error(t, err)
+
+		_, err = NewService(&config.RecommendationConfig{HybridContentWeight: "-1"}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+		assert.Error(t, err)
+
+		_, err = NewService(&config.RecommendationConfig{HybridContentWeight: "0", HybridCollabWeight: "0"}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+		assert.Error(t, err)
+
+		_, err = NewService(&config.RecommendationConfig{PopularSavesWeight: "lots"}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+		assert.Error(t, err)
+
+		_, err = NewService(&config.RecommendationConfig{PopularSavesWeight: "0", PopularRatingWeight: "0", PopularRecencyWeight: "0"}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+		assert.Error(t, err)
+
+		_, err = NewService(&config.RecommendationConfig{PopularHalfLife: "0s"}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+		assert.Error(t, err)
+
+		for _, minRating := range []string{"0", "6", "high"} {
+			_, err = NewService(&config.RecommendationConfig{MinRating: minRating}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+			assert.Error(t, err, minRating)
+		}
+
+		for _, multiplier := range []string{"0", "11", "1.5"} {
+			_, err = NewService(&config.RecommendationConfig{CandidateMultiplier: multiplier}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+			assert.Error(t, err, multiplier)
+		}
+	})
+
+	t.Run("Popularity weights reach the search", func(t *testing.T) {
+		repo := &filteringArticleRepository{candidates: []*Article{{ID: uuid.New(), URL: "https://example.com/a", Popularity: 0.8}}}
+		svc, err := NewService(&config.RecommendationConfig{PopularSavesWeight: "1", PopularRatingWeight: "0", PopularHalfLife: "72h"}, repo, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+		require.NoError(t, err)
+
+		recommendations, err := svc.GetRecommendations(context.Background(), uuid.New(), EnginePopular, 5)
+		require.NoError(t, err)
+		assert.Equal(t, PopularityWeights{Saves: 1, Rating: 0, Recency: 0.2, HalfLife: 72 * time.Hour}, repo.weights)
+		require.Len(t, recommendations, 1)
+		assert.Equal(t, 0.8, recommendations[0].RawScore)
+		assert.Equal(t, ScoreTypePopularity, recommendations[0].ScoreType)
+	})
+}
+
+func TestProfileSignals(t *testing.T) {
+	rated, loved, disliked, bookmarked := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	signals := profileSignals(
+		[]*Rating{{ArticleID: rated, Score: 2}},
+		[]*Reaction{
+			{ArticleID: rated, Kind: "heart"}, // Numeric rating wins
+			{ArticleID: loved, Kind: "bookmark"},
+			{ArticleID: loved, Kind: "heart"},
+			{ArticleID: disliked, Kind: "bookmark"},
+			{ArticleID: disliked, Kind: "thumbs_down"},
+			{ArticleID: bookmarked, Kind: "bookmark"},
+		},
+		nil,
+		4,
+	)
+
+	assert.Equal(t, []profileSignal{
+		{articleID: loved, weight: 1.0},
+		{articleID: bookmarked, weight: 0.6},
+	}, signals)
+
+	// A lower threshold lets the numeric rating in, still winning over the heart
+	signals = profileSignals([]*Rating{{ArticleID: rated, Score: 2}}, []*Reaction{{ArticleID: rated, Kind: "heart"}}, nil, 2)
+	assert.Equal(t, []profileSignal{{articleID: rated, weight: 0.4}}, signals)
+
+	// Favorites count fully, even when rated low or disliked
+	favorite := uuid.New()
+	signals = profileSignals(
+		[]*Rating{{ArticleID: rated, Score: 2}, {ArticleID: favorite, Score: 5}},
+		[]*Reaction{{ArticleID: disliked, Kind: "thumbs_down"}},
+		[]uuid.UUID{rated, disliked},
+		4,
+	)
+	assert.Equal(t, []profileSignal{
+		{articleID: rated, weight: favoriteWeight},
+		{articleID: disliked, weight: favoriteWeight},
+		{articleID: favorite, weight: 1.0},
+	}, signals)
+
+	// Reactions on ratings raise their weight, up to 1, or skip the article
+	insightful, liked, skipped := uuid.New(), uuid.New(), uuid.New()
+	signals = profileSignals(
+		[]*Rating{
+			{ArticleID: insightful, Score: 4, Reaction: "insightful"},
+			{ArticleID: liked, Score: 5, Reaction: "like"},
+			{ArticleID: skipped, Score: 5, Reaction: "skip"},
+		},
+		[]*Reaction{{ArticleID: skipped, Kind: "heart"}}, // The skipped rating still wins
+		nil,
+		4,
+	)
+	assert.Equal(t, []profileSignal{
+		{articleID: insightful, weight: 1.0},
+		{articleID: liked, weight: 1.0},
+	}, signals)
+}
+
+func TestProfileText(t *testing.T) {
+	article := &Article{Title: "Go generics", Description: "A tour", Content: "Type parameters let functions..."}
+
+	assert.Equal(t, "Go generics A tour", profileText(article, SpaceTitle))
+	assert.Equal(t, "Type parameters let functions...", profileText(article, SpaceContent))
+	assert.Equal(t, "Go generics A tour", profileText(article, SpaceBlended))
+
+	// Articles without content fall back to the title text
+	assert.Equal(t, "Go generics A tour", profileText(&Article{Title: "Go generics", Description: "A tour"}, SpaceContent))
+}
+
+func TestSemanticSearch(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "text"})
+	require.NoError(t, err)
+
+	service, err := NewService(&config.RecommendationConfig{}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+	require.NoError(t, err)
+
+	t.Run("Short queries search titles", func(t *testing.T) {
+		response, err := service.SemanticSearch(context.Background(), uuid.New(), "rust async runtimes", SpaceAuto, 10)
+		require.NoError(t, err)
+		assert.Equal(t, SpaceTitle, response.Space)
+		assert.Equal(t, 1, response.Count)
+	})
+
+	t.Run("Long queries search content", func(t *testing.T) {
+		response, err := service.SemanticSearch(context.Background(), uuid.New(), "how do teams migrate a large monolith to services without downtime", "", 10)
+		require.NoError(t, err)
+		assert.Equal(t, SpaceContent, response.Space)
+	})
+
+	t.Run("Explicit space", func(t *testing.T) {
+		response, err := service.SemanticSearch(context.Background(), uuid.New(), "databases", "blended", 10)
+		require.NoError(t, err)
+		assert.Equal(t, SpaceBlended, response.Space)
+	})
+
+	t.Run("Invalid input", func(t *testing.T) {
+		_, err := service.SemanticSearch(context.Background(), uuid.New(), "   ", SpaceAuto, 10)
+		assert.ErrorIs(t, err, utils.ErrValidation)
+
+		_, err = service.SemanticSearch(context.Background(), uuid.New(), "databases", "summary", 10)
+		assert.ErrorIs(t, err, utils.ErrValidation)
+	})
+}
+
+// storedArticleRepository serves one stored article and fixed similarity candidates
+type storedArticleRepository struct {
+	filteringArticleRepository
+	article *Article
+}
+
+func (m *storedArticleRepository) WithContext(ctx context.Context) ArticleRepository {
+	return m
+}
+
+func (m *storedArticleRepository) FindByID(id uuid.UUID) (*Article, error) {
+	if id != m.article.ID {
+		return nil, ErrArticleNotFound
+	}
+	return m.article, nil
+}
+
+func TestSimilarArticles(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "text"})
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	source := &Article{ID: uuid.New(), UserID: userID, Title: "Go generics", URL: "https://go.dev/generics", Embedding: []float64{1, 0}, EmbeddingStatus: "success"}
+	repo := &storedArticleRepository{article: source}
+	repo.candidates = []*Article{
+		{ID: uuid.New(), URL: "https://go.dev/generics", Distance: 0.01}, // Another reader's copy
+		{ID: uuid.New(), URL: "https://a.com", Distance: 0.2},
+		{ID: uuid.New(), URL: "https://b.com", Distance: 0.4},
+	}
+	svc, err := NewService(&config.RecommendationConfig{EmbeddingSpace: "content"}, repo, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+	require.NoError(t, err)
+
+	t.Run("Finds other articles by the stored embedding", func(t *testing.T) {
+		response, err := svc.SimilarArticles(context.Background(), userID, source.ID, 10)
+		require.NoError(t, err)
+
+		// Without a content embedding the title embedding is compared
+		assert.Equal(t, SpaceTitle, response.Space)
+		require.Equal(t, 2, response.Count)
+		assert.Equal(t, "https://a.com", response.Articles[0].Article.URL)
+		assert.InDelta(t, 0.8, response.Articles[0].RawScore, 1e-9)
+		assert.Equal(t, "Similar to Go generics", response.Articles[0].Reason)
+		assert.Equal(t, CandidateFilter{UserID: userID, ExcludeSavedURLs: true}, repo.filter)
+
+		limited, err := svc.SimilarArticles(context.Background(), userID, source.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, limited.Count)
+	})
+
+	t.Run("Only the owner may ask", func(t *testing.T) {
+		_, err := svc.SimilarArticles(context.Background(), uuid.New(), source.ID, 10)
+		assert.ErrorIs(t, err, ErrArticleNotFound)
+	})
+
+	t.Run("Articles must be embedded", func(t *testing.T) {
+		pending := &storedArticleRepository{article: &Article{ID: uuid.New(), UserID: userID, EmbeddingStatus: "pending"}}
+		svc, err := NewService(&config.RecommendationConfig{}, pending, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+		require.NoError(t, err)
+
+		_, err = svc.SimilarArticles(context.Background(), userID, pending.article.ID, 10)
+		assert.ErrorIs(t, err, ErrNotEmbedded)
+	})
+}
+
+func TestPrecomputeRecommendations(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "text"})
+	require.NoError(t, err)
+
+	t.Run("Cached lists are served without recomputing", func(t *testing.T) {
+		svc, err := NewService(&config.RecommendationConfig{}, &mockArticleRepository{}, &mockRatingRepositoryWithRatings{}, nil, nil, &mockEmbeddingClient{}, log)
+		require.NoError(t, err)
+		userID := uuid.New()
+
+		cached, err := svc.PrecomputeRecommendations(context.Background(), []uuid.UUID{userID})
+		require.NoError(t, err)
+		assert.Equal(t, 1, cached)
+
+		// An expired budget would fail a live computation, so results must come from the cache
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		recommendations, err := svc.GetRecommendations(ctx, userID, "", 5)
+		require.NoError(t, err)
+		assert.NotEmpty(t, recommendations)
+
+		_, err = svc.GetRecommendations(ctx, uuid.New(), "", 5)
+		assert.Error(t, err, "users without a precomputed list are computed live")
+	})
+
+	t.Run("Cache honours limit and TTL", func(t *testing.T) {
+		now := time.Now()
+		cache := newResultCache()
+		cache.now = func() time.Time { return now }
+		userID := uuid.New()
+		cache.set(userID, EngineContent, []*RecommendedArticle{{Score: 0.9}, {Score: 0.5}}, 20, now, time.Hour)
+
+		recommendations, ok := cache.get(userID, EngineContent, 1)
+		require.True(t, ok)
+		assert.Len(t, recommendations, 1)
+
+		recommendations, ok = cache.get(userID, EngineContent, 10)
+		require.True(t, ok, "a short list computed with a larger limit is complete")
+		assert.Len(t, recommendations, 2)
+
+		_, ok = cache.get(userID, EngineContent, 50)
+		assert.False(t, ok, "computed with a smaller limit")
+
+		_, ok = cache.get(userID, EnginePopular, 1)
+		assert.False(t, ok, "lists are kept per engine")
+
+		cache.now = func() time.Time { return now.Add(time.Hour) }
+		_, ok = cache.get(userID, EngineContent, 1)
+		assert.False(t, ok)
+		cache.prune()
+		assert.Empty(t, cache.entries)
+	})
+
+	t.Run("Invalidation drops lists and late results", func(t *testing.T) {
+		now := time.Now()
+		cache := newResultCache()
+		cache.now = func() time.Time { return now }
+		userID, otherID := uuid.New(), uuid.New()
+		cache.set(userID, EngineContent, []*RecommendedArticle{{}}, 10, now, time.Hour)
+		cache.set(userID, EngineHybrid, []*RecommendedArticle{{}}, 10, now, time.Hour)
+		cache.set(otherID, EngineContent, []*RecommendedArticle{{}}, 10, now, time.Hour)
+
+		startedAt := now
+		now = now.Add(time.Second)
+		cache.invalidate(userID)
+		_, ok := cache.get(userID, EngineContent, 1)
+		assert.False(t, ok)
+		_, ok = cache.get(userID, EngineHybrid, 1)
+		assert.False(t, ok)
+		_, ok = cache.get(otherID, EngineContent, 1)
+		assert.True(t, ok, "other users keep their lists")
+
+		// A list computed from the old ratings is not cached
+		cache.set(userID, EngineContent, []*RecommendedArticle{{}}, 10, startedAt, time.Hour)
+		_, ok = cache.get(userID, EngineContent, 1)
+		assert.False(t, ok)
+
+		now = now.Add(time.Second)
+		cache.set(userID, EngineContent, []*RecommendedArticle{{}}, 10, now, time.Hour)
+		_, ok = cache.get(userID, EngineContent, 1)
+		assert.True(t, ok)
+
+		now = now.Add(invalidationMemory)
+		cache.prune()
+		assert.Empty(t, cache.invalidated)
+	})
+
+	t.Run("Lists computed on request are reused until ratings change", func(t *testing.T) {
+		svc, err := NewService(&config.RecommendationConfig{}, &mockArticleRepository{}, &mockRatingRepositoryWithRatings{}, nil, nil, &mockEmbeddingClient{}, log)
+		require.NoError(t, err)
+		userID := uuid.New()
+
+		first, err := svc.GetRecommendations(context.Background(), userID, EngineContent, 5)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		again, err := svc.GetRecommendations(ctx, userID, EngineContent, 5)
+		require.NoError(t, err, "served from the cache")
+		assert.Equal(t, first, again)
+
+		svc.InvalidateRecommendations(userID)
+		_, err = svc.GetRecommendations(ctx, userID, EngineContent, 5)
+		assert.Error(t, err, "computed again after invalidation")
+
+		disabled, err := NewService(&config.RecommendationConfig{ResultCacheTTL: "0"}, &mockArticleRepository{}, &mockRatingRepositoryWithRatings{}, nil, nil, &mockEmbeddingClient{}, log)
+		require.NoError(t, err)
+		_, err = disabled.GetRecommendations(context.Background(), userID, EngineContent, 5)
+		require.NoError(t, err)
+		_, err = disabled.GetRecommendations(ctx, userID, EngineContent, 5)
+		assert.Error(t, err, "not cached with a zero TTL")
+	})
+
+	t.Run("Precomputer caches active users", func(t *testing.T) {
+		svc, err := NewService(&config.RecommendationConfig{}, &mockArticleRepository{}, &mockRatingRepositoryWithRatings{}, nil, nil, &mockEmbeddingClient{}, log)
+		require.NoError(t, err)
+		users := &mockActiveUserSource{userIDs: []uuid.UUID{uuid.New(), uuid.New()}}
+
+		precomputer, err := NewPrecomputer(&config.RecommendationConfig{PrecomputeActiveDays: "3", PrecomputeMaxUsers: "50"}, svc, users, log)
+		require.NoError(t, err)
+		require.NoError(t, precomputer.Run())
+		assert.Equal(t, 3, users.days)
+		assert.Equal(t, 50, users.limit)
+
+		_, ok := svc.(*service).cache.get(users.userIDs[1], EngineContent, 10)
+		assert.True(t, ok)
+	})
+
+	t.Run("Stored lists are served by other instances until stale", func(t *testing.T) {
+		profiles := newMemoryProfileRepository()
+		worker, err := NewService(&config.RecommendationConfig{}, &mockArticleRepository{}, &mockRatingRepositoryWithRatings{}, profiles, nil, &mockEmbeddingClient{}, log)
+		require.NoError(t, err)
+		userID := uuid.New()
+
+		_, err = worker.PrecomputeRecommendations(context.Background(), []uuid.UUID{userID})
+		require.NoError(t, err)
+		stored, err := profiles.FindStoredList(userID, EngineContent)
+		require.NoError(t, err)
+		require.NotEmpty(t, stored.Items)
+
+		svc, err := NewService(&config.RecommendationConfig{}, &mockArticleRepository{}, &mockRatingRepositoryWithRatings{}, profiles, nil, &mockEmbeddingClient{}, log)
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		recommendations, err := svc.GetRecommendations(ctx, userID, "", 1)
+		require.NoError(t, err, "served from the stored list")
+		require.Len(t, recommendations, 1)
+		assert.Equal(t, stored.Items[0].ArticleID, recommendations[0].Article.ID)
+		assert.Equal(t, "Mock Article", recommendations[0].Article.Title, "articles are read when served")
+		assert.Equal(t, stored.Items[0].Reason, recommendations[0].Reason)
+
+		popular, err := svc.GetRecommendations(context.Background(), userID, EnginePopular, 1)
+		require.NoError(t, err)
+		assert.NotEqual(t, stored.Items[0].ArticleID, popular[0].Article.ID, "lists are stored per engine")
+
+		stale, err := NewService(&config.RecommendationConfig{}, &mockArticleRepository{}, &mockRatingRepositoryWithRatings{}, profiles, nil, &mockEmbeddingClient{}, log)
+		require.NoError(t, err)
+		stale.(*service).cache.now = func() time.Time { return stored.ComputedAt.Add(25 * time.Hour) }
+		live, err := stale.GetRecommendations(context.Background(), userID, "", 1)
+		require.NoError(t, err)
+		assert.NotEqual(t, stored.Items[0].ArticleID, live[0].Article.ID, "stale lists are computed live")
+
+		svc.InvalidateRecommendations(userID)
+		_, err = profiles.FindStoredList(userID, EngineContent)
+		assert.ErrorIs(t, err, ErrStoredListNotFound)
+	})
+
+	t.Run("Precomputing drops lists of users no longer active", func(t *testing.T) {
+		profiles := newMemoryProfileRepository()
+		inactiveID := uuid.New()
+		require.NoError(t, profiles.SaveStoredList(&StoredList{UserID: inactiveID, Engine: EngineContent, ComputedAt: time.Now().Add(-48 * time.Hour)}))
+		svc, err := NewService(&config.RecommendationConfig{}, &mockArticleRepository{}, &mockRatingRepositoryWithRatings{}, profiles, nil, &mockEmbeddingClient{}, log)
+		require.NoError(t, err)
+
+		_, err = svc.PrecomputeRecommendations(context.Background(), []uuid.UUID{uuid.New()})
+		require.NoError(t, err)
+
+		_, err = profiles.FindStoredList(inactiveID, EngineContent)
+		assert.ErrorIs(t, err, ErrStoredListNotFound)
+		assert.Len(t, profiles.lists, 1)
+	})
+
+	t.Run("Invalid settings", func(t *testing.T) {
+		_, err := NewService(&config.RecommendationConfig{CacheTTL: "0s"}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+		assert.Error(t, err)
+
+		_, err = NewService(&config.RecommendationConfig{ResultCacheTTL: "soon"}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+		assert.Error(t, err)
+
+		_, err = NewPrecomputer(&config.RecommendationConfig{PrecomputeActiveDays: "week"}, nil, nil, log)
+		assert.Error(t, err)
+
+		_, err = NewPrecomputer(&config.RecommendationConfig{PrecomputeMaxUsers: "-5"}, nil, nil, log)
+		assert.Error(t, err)
+	})
+}
+
+func TestRecommendationPages(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "text"})
+	require.NoError(t, err)
+
+	newPagedService := func(t *testing.T, userID uuid.UUID, count int) *service {
+		svc, err := NewService(&config.RecommendationConfig{}, &mockArticleRepository{}, &mockRatingRepository{}, nil, nil, &mockEmbeddingClient{}, log)
+		require.NoError(t, err)
+		recommendations := make([]*RecommendedArticle, count)
+		for i := range recommendations {
+			recommendations[i] = &RecommendedArticle{Article: &Article{ID: uuid.New()}, Score: 1 - float64(i)/100}
+		}
+		svc.(*service).cache.set(userID, EngineContent, recommendations, maxRecommendationLimit, time.Now(), time.Hour)
+		return svc.(*service)
+	}
+
+	t.Run("Pages through a fixed list", func(t *testing.T) {
+		userID := uuid.New()
+		svc := newPagedService(t, userID, 25)
+
+		seen := make(map[uuid.UUID]bool)
+		cursor := ""
+		pages := 0
+		for {
+			page, err := svc.GetRecommendationPage(context.Background(), userID, PageRequest{Cursor: cursor, Snapshot: true, Limit: 10})
+			require.NoError(t, err)
+			pages++
+			for _, rec := range page.Recommendations {
+				assert.False(t, seen[rec.Article.ID], "repeated article")
+				seen[rec.Article.ID] = true
+			}
+
+			// Recomputed lists do not affect the pages of the snapshot
+			svc.cache.set(userID, EngineContent, nil, maxRecommendationLimit, time.Now(), time.Hour)
+
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		assert.Equal(t, 3, pages)
+		assert.Len(t, seen, 25)
+	})
+
+	t.Run("Cursors belong to their user and expire", func(t *testing.T) {
+		userID := uuid.New()
+		svc := newPagedService(t, userID, 5)
+		now := time.Now()
+		svc.snapshots.now = func() time.Time { return now }
+
+		page, err := svc.GetRecommendationPage(context.Background(), userID, PageRequest{Snapshot: true, Limit: 2})
+		require.NoError(t, err)
+		require.NotEmpty(t, page.NextCursor)
+
+		_, err = svc.GetRecommendationPage(context.Background(), uuid.New(), PageRequest{Cursor: page.NextCursor, Snapshot: true, Limit: 2})
+		assert.ErrorIs(t, err, ErrSnapshotExpired)
+
+		svc.snapshots.now = func() time.Time { return now.Add(snapshotTTL) }
+		_, err = svc.GetRecommendationPage(context.Background(), userID, PageRequest{Cursor: page.NextCursor, Snapshot: true, Limit: 2})
+		assert.ErrorIs(t, err, ErrSnapshotExpired)
+
+		_, err = svc.GetRecommendationPage(context.Background(), userID, PageRequest{Cursor: "garbage", Snapshot: true, Limit: 2})
+		assert.ErrorIs(t, err, utils.ErrInvalidCursor)
+	})
+
+	t.Run("Keeps a bounded number of lists per user", func(t *testing.T) {
+		store := newSnapshotStore(time.Hour)
+		userID := uuid.New()
+		for i := 0; i < maxSnapshotsPerUser+3; i++ {
+			store.put(userID, nil)
+		}
+		store.put(uuid.New(), nil)
+		assert.Len(t, store.entries, maxSnapshotsPerUser+1)
+	})
+
+	t.Run("Pages by offset", func(t *testing.T) {
+		userID := uuid.New()
+		svc := newPagedService(t, userID, 25)
+		list, err := svc.GetRecommendations(context.Background(), userID, "", 25)
+		require.NoError(t, err)
+
+		page, err := svc.GetRecommendationPage(context.Background(), userID, PageRequest{Offset: 20, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, list[20:], page.Recommendations)
+		assert.Empty(t, page.NextCursor)
+
+		_, err = svc.GetRecommendationPage(context.Background(), userID, PageRequest{Offset: -1, Limit: 10})
+		assert.ErrorIs(t, err, utils.ErrValidation)
+	})
+
+	t.Run("Leaves out articles seen within the window", func(t *testing.T) {
+		userID := uuid.New()
+		svc := newPagedService(t, userID, 25)
+		now := time.Now()
+		svc.seen.now = func() time.Time { return now }
+
+		first, err := svc.GetRecommendationPage(context.Background(), userID, PageRequest{Limit: 10})
+		require.NoError(t, err)
+
+		svc.seen.now = func() time.Time { return now.Add(30 * time.Minute) }
+		next, err := svc.GetRecommendationPage(context.Background(), userID, PageRequest{Snapshot: true, Limit: 10, SeenWindow: time.Hour})
+		require.NoError(t, err)
+		require.Len(t, next.Recommendations, 10)
+		for _, rec := range next.Recommendations {
+			for _, seen := range first.Recommendations {
+				assert.NotEqual(t, seen.Article.ID, rec.Article.ID)
+			}
+		}
+
+		// Articles seen before the window are recommended again
+		svc.seen.now = func() time.Time { return now.Add(45 * time.Minute) }
+		again, err := svc.GetRecommendationPage(context.Background(), userID, PageRequest{Limit: 5, SeenWindow: 20 * time.Minute})
+		require.NoError(t, err)
+		assert.Equal(t, first.Recommendations[:5], again.Recommendations)
+
+		_, err = svc.GetRecommendationPage(context.Background(), userID, PageRequest{Limit: 5, SeenWindow: 48 * time.Hour})
+		assert.ErrorIs(t, err, utils.ErrValidation)
+	})
+}
+
+type mockActiveUserSource struct {
+	userIDs []uuid.UUID
+	days    int
+	limit   int
+}
+
+func (m *mockActiveUserSource) ActiveUsers(days, limit int) ([]uuid.UUID, error) {
+	m.days, m.limit = days, limit
+	return m.userIDs, nil
+}
+
 type mockArticleRepository struct{}
 
+func (m *mockArticleRepository) WithContext(ctx context.Context) ArticleRepository {
+	return m
+}
+
 func (m *mockArticleRepository) FindByID(id uuid.UUID) (*Article, error) {
 	return &Article{ID: id, Title: "Mock Article"}, nil
 }
 
+func (m *mockArticleRepository) FindByIDs(ids []uuid.UUID) ([]*Article, error) {
+	articles := make([]*Article, 0, len(ids))
+	for _, id := range ids {
+		article, _ := m.FindByID(id)
+		articles = append(articles, article)
+	}
+	return articles, nil
+}
+
 func (m *mockArticleRepository) FindAll() ([]*Article, error) {
 	return []*Article{}, nil
 }
 
-func (m *mockArticleRepository) FindPopular(limit int) ([]*Article, error) {
+func (m *mockArticleRepository) FindPopular(filter CandidateFilter, weights PopularityWeights, limit int) ([]*Article, error) {
 	// Return mock popular articles
 	return []*Article{
 		{
@@ -186,7 +884,13 @@ func (m *mockArticleRepository) FindPopular(limit int) ([]*Article, error) {
 	}, nil
 }
 
-func (m *mockArticleRepository) FindSimilar(embedding []float64, userID uuid.UUID, limit int) ([]*Article, error) {
+func (m *mockArticleRepository) FindSimilarInLibrary(embedding []float64, userID uuid.UUID, space EmbeddingSpace, titleWeight float64, limit int) ([]*Article, error) {
+	return []*Article{
+		{ID: uuid.New(), UserID: userID, Title: "Library Article", URL: "https://library.com"},
+	}, nil
+}
+
+func (m *mockArticleRepository) FindSimilar(embedding []float64, filter CandidateFilter, space EmbeddingSpace, titleWeight float64, limit int) ([]*Article, error) {
 	// Return mock similar articles based on embedding
 	return []*Article{
 		{
@@ -195,6 +899,7 @@ func (m *mockArticleRepository) FindSimilar(embedding []float64, userID uuid.UUI
 			Description: "Similar content",
 			URL:         "https://similar1.com",
 			Embedding:   embedding, // Same embedding for similarity
+			Distance:    0.1,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		},
@@ -203,14 +908,49 @@ func (m *mockArticleRepository) FindSimilar(embedding []float64, userID uuid.UUI
 			Title:       "Similar Article 2",
 			Description: "Related content",
 			URL:         "https://similar2.com",
+			Distance:    0.9,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		},
 	}, nil
 }
 
+// filteringArticleRepository returns fixed candidates and records the filter searched with
+type filteringArticleRepository struct {
+	mockArticleRepository
+	candidates []*Article
+	filter     CandidateFilter
+	weights    PopularityWeights
+	limit      int
+}
+
+func (m *filteringArticleRepository) WithContext(ctx context.Context) ArticleRepository {
+	return m
+}
+
+func (m *filteringArticleRepository) FindPopular(filter CandidateFilter, weights PopularityWeights, limit int) ([]*Article, error) {
+	m.filter, m.weights = filter, weights
+	return m.candidates, nil
+}
+
+func (m *filteringArticleRepository) FindSimilar(embedding []float64, filter CandidateFilter, space EmbeddingSpace, titleWeight float64, limit int) ([]*Article, error) {
+	m.filter, m.limit = filter, limit
+	return m.candidates, nil
+}
+
+func (m *mockArticleRepository) FindLikedByNeighbours(userID uuid.UUID, limit int) ([]*Article, error) {
+	return []*Article{
+		{ID: uuid.New(), Title: "Shared Favourite", URL: "https://shared.com", Affinity: 3},
+		{ID: uuid.New(), Title: "Niche Pick", URL: "https://niche.com", Affinity: 1},
+	}, nil
+}
+
 type mockRatingRepository struct{}
 
+func (m *mockRatingRepository) WithContext(ctx context.Context) RatingRepository {
+	return m
+}
+
 func (m *mockRatingRepository) FindByUserID(userID uuid.UUID) ([]*Rating, error) {
 	return []*Rating{}, nil
 }
@@ -219,9 +959,25 @@ func (m *mockRatingRepository) GetAverageRating(articleID uuid.UUID) (float64, i
 	return 4.0, 10, nil
 }
 
+func (m *mockRatingRepository) CreateIfAbsent(rating *Rating) (bool, error) {
+	return true, nil
+}
+
+func (m *mockRatingRepository) FindReactionsByUserID(userID uuid.UUID) ([]*Reaction, error) {
+	return []*Reaction{}, nil
+}
+
+func (m *mockRatingRepository) FindFavoritesByUserID(userID uuid.UUID) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
 // mockRatingRepositoryWithRatings returns mock ratings for testing
 type mockRatingRepositoryWithRatings struct{}
 
+func (m *mockRatingRepositoryWithRatings) WithContext(ctx context.Context) RatingRepository {
+	return m
+}
+
 func (m *mockRatingRepositoryWithRatings) FindByUserID(userID uuid.UUID) ([]*Rating, error) {
 	// Return mock ratings with high scores to trigger embedding generation
 	return []*Rating{
@@ -246,10 +1002,22 @@ func (m *mockRatingRepositoryWithRatings) GetAverageRating(articleID uuid.UUID)
 	return 4.5, 5, nil
 }
 
+func (m *mockRatingRepositoryWithRatings) CreateIfAbsent(rating *Rating) (bool, error) {
+	return false, nil
+}
+
+func (m *mockRatingRepositoryWithRatings) FindReactionsByUserID(userID uuid.UUID) ([]*Reaction, error) {
+	return []*Reaction{}, nil
+}
+
+func (m *mockRatingRepositoryWithRatings) FindFavoritesByUserID(userID uuid.UUID) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
 // mockEmbeddingClient simulates the embedding service
 type mockEmbeddingClient struct{}
 
-func (m *mockEmbeddingClient) GetEmbedding(text string) ([]float64, error) {
+func (m *mockEmbeddingClient) GetEmbedding(ctx context.Context, text string) ([]float64, error) {
 	// Return a mock embedding based on text length for deterministic testing
 	embeddingSize := 384
 	embedding := make([]float64, embeddingSize)
@@ -259,20 +1027,20 @@ func (m *mockEmbeddingClient) GetEmbedding(text string) ([]float64, error) {
 	return embedding, nil
 }
 
-func (m *mockEmbeddingClient) GetBatchEmbeddings(texts []string) ([][]float64, error) {
+func (m *mockEmbeddingClient) GetBatchEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
 	embeddings := make([][]float64, len(texts))
 	for i, text := range texts {
-		embedding, _ := m.GetEmbedding(text)
+		embedding, _ := m.GetEmbedding(ctx, text)
 		embeddings[i] = embedding
 	}
 	return embeddings, nil
 }
 
-func (m *mockEmbeddingClient) CalculateSimilarity(embedding1, embedding2 []float64) (float64, error) {
+func (m *mockEmbeddingClient) CalculateSimilarity(ctx context.Context, embedding1, embedding2 []float64) (float64, error) {
 	return 0.85, nil // Mock high similarity
 }
 
-func (m *mockEmbeddingClient) HealthCheck() (*embedding.HealthResponse, error) {
+func (m *mockEmbeddingClient) HealthCheck(ctx context.Context) (*embedding.HealthResponse, error) {
 	return &embedding.HealthResponse{
 		Status:               "healthy",
 		EmbeddingModel:       "mock-model",
@@ -282,7 +1050,7 @@ func (m *mockEmbeddingClient) HealthCheck() (*embedding.HealthResponse, error) {
 	}, nil
 }
 
-func (m *mockEmbeddingClient) ClassifyContent(text string) (*embedding.ClassifyResponse, error) {
+func (m *mockEmbeddingClient) ClassifyContent(ctx context.Context, text string) (*embedding.ClassifyResponse, error) {
 	return &embedding.ClassifyResponse{
 		Text:       text,
 		IsArticle:  true,
@@ -290,7 +1058,7 @@ func (m *mockEmbeddingClient) ClassifyContent(text string) (*embedding.ClassifyR
 	}, nil
 }
 
-func (m *mockEmbeddingClient) ClassifyBatchContent(texts []string) (*embedding.BatchClassifyResponse, error) {
+func (m *mockEmbeddingClient) ClassifyBatchContent(ctx context.Context, texts []string) (*embedding.BatchClassifyResponse, error) {
 	results := make([]embedding.ClassifyResult, len(texts))
 	for i, text := range texts {
 		results[i] = embedding.ClassifyResult{
@@ -306,3 +1074,488 @@ func (m *mockEmbeddingClient) ClassifyBatchContent(texts []string) (*embedding.B
 		Processed: len(results),
 	}, nil
 }
+
+func (m *mockEmbeddingClient) StoreArticleEmbeddings(ctx context.Context, articles []embedding.ArticleText) (*embedding.BatchStoreResponse, error) {
+	results := make([]embedding.StoreResult, len(articles))
+	for i, article := range articles {
+		results[i] = embedding.StoreResult{ArticleID: article.ID, Status: "success"}
+	}
+	return &embedding.BatchStoreResponse{
+		TotalArticles:     len(articles),
+		SuccessfulUpdates: len(articles),
+		Results:           results,
+	}, nil
+}
+
+func TestNormalizeScores(t *testing.T) {
+	candidates := []*RecommendedArticle{
+		{RawScore: 0.9},
+		{RawScore: 0.4},
+		{RawScore: 0.7},
+		{RawScore: 0.4},
+	}
+
+	normalizeScores(candidates)
+
+	assert.Equal(t, 1.0, candidates[0].Score)
+	assert.Equal(t, 0.5, candidates[1].Score, "ties share the higher percentile")
+	assert.Equal(t, 0.75, candidates[2].Score)
+	assert.Equal(t, 0.5, candidates[3].Score)
+
+	normalizeScores(nil)
+}
+
+func TestDiversify(t *testing.T) {
+	// Two near-duplicates lead by similarity; a different article trails
+	first := &RecommendedArticle{Article: &Article{ID: uuid.New(), Embedding: []float64{1, 0}}, RawScore: 0.9}
+	duplicate := &RecommendedArticle{Article: &Article{ID: uuid.New(), Embedding: []float64{0.99, 0.01}}, RawScore: 0.88}
+	other := &RecommendedArticle{Article: &Article{ID: uuid.New(), Embedding: []float64{0, 1}}, RawScore: 0.6}
+	candidates := []*RecommendedArticle{first, duplicate, other}
+
+	t.Run("Similarity only", func(t *testing.T) {
+		assert.Equal(t, candidates, diversify(candidates, Settings{}, 2))
+		assert.Equal(t, candidates, diversify(candidates, Settings{DiversityLambda: 1}, 2))
+	})
+
+	t.Run("Near-duplicates give way", func(t *testing.T) {
+		ranked := diversify(candidates, Settings{EmbeddingSpace: SpaceTitle, DiversityLambda: 0.5}, 2)
+		assert.Equal(t, []*RecommendedArticle{first, other}, ranked)
+		assert.Len(t, candidates, 3, "candidates left intact")
+	})
+
+	t.Run("Strong relevance weight keeps the order", func(t *testing.T) {
+		ranked := diversify(candidates, Settings{EmbeddingSpace: SpaceTitle, DiversityLambda: 0.99}, 3)
+		assert.Equal(t, candidates, ranked)
+	})
+
+	t.Run("Articles without embeddings count as unrelated", func(t *testing.T) {
+		assert.Zero(t, Settings{EmbeddingSpace: SpaceContent}.similarity(first.Article, duplicate.Article))
+		assert.InDelta(t, 1.0, Settings{EmbeddingSpace: SpaceBlended, TitleWeight: 0.5}.similarity(first.Article, first.Article), 1e-9)
+	})
+}
+
+func TestExplanation(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "text"})
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	golang := &Article{ID: uuid.New(), Embedding: []float64{1, 0}, Tags: []string{"go", "backend"}}
+	databases := &Article{ID: uuid.New(), Embedding: []float64{0.8, 0.6}, Tags: []string{"backend", "sql"}}
+	cooking := &Article{ID: uuid.New(), Embedding: []float64{-1, 0}, Tags: []string{"food"}}
+	liked := []*Article{cooking, databases, golang}
+
+	t.Run("Closest liked articles first, with their tags", func(t *testing.T) {
+		explanation := Settings{EmbeddingSpace: SpaceTitle}.explanation(&Article{Embedding: []float64{1, 0}}, liked)
+		require.NotNil(t, explanation)
+		assert.Equal(t, []uuid.UUID{golang.ID, databases.ID}, explanation.SimilarArticleIDs)
+		assert.Equal(t, []string{"go", "backend", "sql"}, explanation.MatchedTopics)
+		assert.InDelta(t, 1.0, explanation.Similarity, 1e-9)
+	})
+
+	t.Run("Unrelated articles are not explained", func(t *testing.T) {
+		assert.Nil(t, Settings{EmbeddingSpace: SpaceTitle}.explanation(&Article{Embedding: []float64{0, -1}}, liked))
+		assert.Nil(t, Settings{EmbeddingSpace: SpaceContent}.explanation(&Article{Embedding: []float64{1, 0}}, liked))
+	})
+
+	t.Run("Content-based recommendations carry explanations", func(t *testing.T) {
+		repo := &explainingArticleRepository{liked: liked}
+		repo.candidates = []*Article{
+			{ID: uuid.New(), URL: "https://example.com/go", Embedding: []float64{0.9, 0.1}, Distance: 0.1},
+			{ID: uuid.New(), URL: "https://example.com/other", Embedding: []float64{0, -1}, Distance: 0.5},
+		}
+		ratings := &changingRatingRepository{ratings: []*Rating{
+			{UserID: userID, ArticleID: golang.ID, Score: 5},
+			{UserID: userID, ArticleID: databases.ID, Score: 4},
+		}}
+		engine := NewContentBasedEngine(repo, ratings, &mockEmbeddingClient{}, Settings{EmbeddingSpace: SpaceTitle}, log)
+
+		recommendations, err := engine.Recommend(context.Background(), userID, 10)
+		require.NoError(t, err)
+		require.Len(t, recommendations, 2)
+		assert.Equal(t, []uuid.UUID{golang.ID, databases.ID}, repo.ids, "heaviest liked articles first")
+		require.NotNil(t, recommendations[0].Explanation)
+		assert.Equal(t, golang.ID, recommendations[0].Explanation.SimilarArticleIDs[0])
+		assert.Nil(t, recommendations[1].Explanation)
+
+		// Explanations are left out when the liked articles cannot be loaded
+		repo.err = errors.New("database unavailable")
+		recommendations, err = engine.Recommend(context.Background(), userID, 10)
+		require.NoError(t, err)
+		assert.Nil(t, recommendations[0].Explanation)
+	})
+}
+
+// explainingArticleRepository returns fixed liked articles for explanations
+type explainingArticleRepository struct {
+	filteringArticleRepository
+	liked []*Article
+	ids   []uuid.UUID
+	err   error
+}
+
+func (m *explainingArticleRepository) WithContext(ctx context.Context) ArticleRepository {
+	return m
+}
+
+func (m *explainingArticleRepository) FindByIDs(ids []uuid.UUID) ([]*Article, error) {
+	m.ids = ids
+	return m.liked, m.err
+}
+
+func TestCollaborativeEngine(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "text"})
+	require.NoError(t, err)
+
+	engine := NewCollaborativeEngine(&mockArticleRepository{}, log)
+	recommendations, err := engine.Recommend(context.Background(), uuid.New(), 10)
+	require.NoError(t, err)
+	require.Len(t, recommendations, 2)
+
+	assert.Equal(t, "Shared Favourite", recommendations[0].Article.Title)
+	assert.Equal(t, 3.0, recommendations[0].RawScore)
+	assert.Equal(t, 1.0, recommendations[0].Score)
+	assert.Equal(t, ScoreTypeAffinity, recommendations[0].ScoreType)
+	assert.Equal(t, "collaborative", recommendations[0].RecommenderUsed)
+}
+
+// stubEngine returns fixed recommendations
+type stubEngine struct {
+	name            string
+	recommendations []*RecommendedArticle
+	err             error
+}
+
+func (s *stubEngine) Recommend(ctx context.Context, userID uuid.UUID, limit int) ([]*RecommendedArticle, error) {
+	return s.recommendations, s.err
+}
+
+func (s *stubEngine) Name() string {
+	return s.name
+}
+
+func TestHybridEngine(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "text"})
+	require.NoError(t, err)
+
+	both := &Article{ID: uuid.New(), URL: "https://both.com"}
+	contentOnly := &Article{ID: uuid.New(), URL: "https://content.com"}
+	collabOnly := &Article{ID: uuid.New(), URL: "https://collab.com"}
+	// Another reader's copy of a link the content engine already found
+	bothCopy := &Article{ID: uuid.New(), URL: "https://both.com"}
+
+	content := &stubEngine{name: "content-based", recommendations: []*RecommendedArticle{
+		{Article: contentOnly, Score: 1, Reason: "Similar"},
+		{Article: both, Score: 0.5, Reason: "Similar"},
+	}}
+	collaborative := &stubEngine{name: "collaborative", recommendations: []*RecommendedArticle{
+		{Article: bothCopy, Score: 1, Reason: "Liked by similar readers"},
+		{Article: collabOnly, Score: 0.5, Reason: "Liked by similar readers"},
+	}}
+
+	t.Run("Blends weighted scores and deduplicates links", func(t *testing.T) {
+		engine := NewHybridEngine([]WeightedEngine{{Engine: content, Weight: 3}, {Engine: collaborative, Weight: 1}}, log)
+
+		recommendations, err := engine.Recommend(context.Background(), uuid.New(), 10)
+		require.NoError(t, err)
+		require.Len(t, recommendations, 3)
+
+		// both: 0.75*0.5 + 0.25*1; content: 0.75*1; collab: 0.25*0.5
+		assert.Equal(t, contentOnly, recommendations[0].Article)
+		assert.InDelta(t, 0.75, recommendations[0].RawScore, 1e-9)
+		assert.Equal(t, both, recommendations[1].Article)
+		assert.InDelta(t, 0.625, recommendations[1].RawScore, 1e-9)
+		assert.Equal(t, collabOnly, recommendations[2].Article)
+
+		assert.Equal(t, 1.0, recommendations[0].Score)
+		assert.Equal(t, ScoreTypeBlended, recommendations[0].ScoreType)
+		assert.Equal(t, "hybrid", recommendations[0].RecommenderUsed)
+		assert.Equal(t, "Similar (content-based); Liked by similar readers (collaborative)", recommendations[1].Reason)
+		assert.Equal(t, "Liked by similar readers (collaborative)", recommendations[2].Reason)
+
+		limited, err := engine.Recommend(context.Background(), uuid.New(), 1)
+		require.NoError(t, err)
+		assert.Len(t, limited, 1)
+	})
+
+	t.Run("Survives a failing engine", func(t *testing.T) {
+		failing := &stubEngine{name: "collaborative", err: errors.New("database down")}
+		engine := NewHybridEngine([]WeightedEngine{{Engine: content, Weight: 1}, {Engine: failing, Weight: 1}}, log)
+
+		recommendations, err := engine.Recommend(context.Background(), uuid.New(), 10)
+		require.NoError(t, err)
+		assert.Len(t, recommendations, 2)
+
+		engine = NewHybridEngine([]WeightedEngine{{Engine: failing, Weight: 1}}, log)
+		_, err = engine.Recommend(context.Background(), uuid.New(), 10)
+		assert.Error(t, err)
+	})
+}
+
+// memoryBanditRepository keeps bandit statistics in memory
+type memoryBanditRepository struct {
+	arms        map[string]*BanditArm
+	impressions map[uuid.UUID]*Impression
+	events      []*Event
+}
+
+func newMemoryBanditRepository() *memoryBanditRepository {
+	return &memoryBanditRepository{arms: make(map[string]*BanditArm), impressions: make(map[uuid.UUID]*Impression)}
+}
+
+func (m *memoryBanditRepository) WithContext(ctx context.Context) BanditRepository {
+	return m
+}
+
+func (m *memoryBanditRepository) FindArms(userID uuid.UUID) ([]*BanditArm, error) {
+	var arms []*BanditArm
+	for _, arm := range m.arms {
+		arms = append(arms, arm)
+	}
+	return arms, nil
+}
+
+func (m *memoryBanditRepository) RecordImpressions(userID uuid.UUID, impressions []*Impression) error {
+	for _, impression := range impressions {
+		m.impressions[impression.ArticleID] = impression
+		m.arm(userID, impression.Engine).Shown++
+	}
+	return nil
+}
+
+func (m *memoryBanditRepository) RewardImpression(userID, articleID uuid.UUID, since time.Time) (bool, error) {
+	impression, ok := m.impressions[articleID]
+	if !ok || impression.RewardedAt != nil || impression.ShownAt.Before(since) {
+		return false, nil
+	}
+	now := time.Now()
+	impression.RewardedAt = &now
+	m.arm(userID, impression.Engine).Rewards++
+	return true, nil
+}
+
+func (m *memoryBanditRepository) RecordEvents(events []*Event) error {
+	m.events = append(m.events, events...)
+	return nil
+}
+
+func (m *memoryBanditRepository) CountEvents(since time.Time) ([]*EngineCTR, error) {
+	counts := make(map[string]*EngineCTR)
+	var engines []*EngineCTR
+	for _, event := range m.events {
+		if event.CreatedAt.Before(since) {
+			continue
+		}
+		count, ok := counts[event.Engine]
+		if !ok {
+			count = &EngineCTR{Engine: event.Engine}
+			counts[event.Engine] = count
+			engines = append(engines, count)
+		}
+		if event.Type == EventClick {
+			count.Clicks++
+		} else {
+			count.Impressions++
+		}
+	}
+	return engines, nil
+}
+
+func (m *memoryBanditRepository) DeleteEventsBefore(createdAt time.Time) error {
+	kept := m.events[:0]
+	for _, event := range m.events {
+		if !event.CreatedAt.Before(createdAt) {
+			kept = append(kept, event)
+		}
+	}
+	m.events = kept
+	return nil
+}
+
+func (m *memoryBanditRepository) arm(userID uuid.UUID, engine string) *BanditArm {
+	if _, ok := m.arms[engine]; !ok {
+		m.arms[engine] = &BanditArm{UserID: userID, Engine: engine}
+	}
+	return m.arms[engine]
+}
+
+func TestBanditEngine(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "text"})
+	require.NoError(t, err)
+
+	recommendations := func(prefix string, count int) []*RecommendedArticle {
+		list := make([]*RecommendedArticle, count)
+		for i := range list {
+			list[i] = &RecommendedArticle{Article: &Article{ID: uuid.New(), URL: fmt.Sprintf("https://%s.com/%d", prefix, i)}, Reason: "Because"}
+		}
+		return list
+	}
+	content := &stubEngine{name: "content-based", recommendations: recommendations("content", 10)}
+	popular := &stubEngine{name: "popular", recommendations: recommendations("popular", 10)}
+	userID := uuid.New()
+
+	countBySource := func(list []*RecommendedArticle) map[string]int {
+		counts := make(map[string]int)
+		for _, rec := range list {
+			counts[rec.Reason]++
+		}
+		return counts
+	}
+
+	t.Run("Without feedback the slots are shared", func(t *testing.T) {
+		engine := NewBanditEngine([]Engine{content, popular}, nil, log)
+
+		list, err := engine.Recommend(context.Background(), userID, 6)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"Because (content-based)": 3, "Because (popular)": 3}, countBySource(list))
+		assert.Equal(t, "bandit", list[0].RecommenderUsed)
+	})
+
+	t.Run("Feedback shifts slots to the engine acted on", func(t *testing.T) {
+		repo := newMemoryBanditRepository()
+		engine := NewBanditEngine([]Engine{content, popular}, repo, log)
+
+		list, err := engine.Recommend(context.Background(), userID, 10)
+		require.NoError(t, err)
+		require.Len(t, repo.impressions, 10)
+		assert.EqualValues(t, 5, repo.arms["popular"].Shown)
+
+		// The user acts on every popular article and ignores the rest
+		for _, rec := range list {
+			if rec.Reason == "Because (popular)" {
+				rewarded, err := repo.RewardImpression(userID, rec.Article.ID, time.Now().Add(-time.Hour))
+				require.NoError(t, err)
+				assert.True(t, rewarded)
+			}
+		}
+		rewarded, err := repo.RewardImpression(userID, list[1].Article.ID, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.False(t, rewarded, "credited once")
+
+		list, err = engine.Recommend(context.Background(), userID, 10)
+		require.NoError(t, err)
+		counts := countBySource(list)
+		assert.Greater(t, counts["Because (popular)"], counts["Because (content-based)"])
+	})
+
+	t.Run("Links are recommended once and engines may run out", func(t *testing.T) {
+		duplicate := &RecommendedArticle{Article: &Article{ID: uuid.New(), URL: content.recommendations[0].Article.URL}}
+		short := &stubEngine{name: "popular", recommendations: []*RecommendedArticle{duplicate}}
+		engine := NewBanditEngine([]Engine{content, short}, nil, log)
+
+		list, err := engine.Recommend(context.Background(), userID, 20)
+		require.NoError(t, err)
+		assert.Len(t, list, 10)
+	})
+
+	t.Run("Survives a failing engine", func(t *testing.T) {
+		failing := &stubEngine{name: "popular", err: errors.New("database down")}
+
+		list, err := NewBanditEngine([]Engine{content, failing}, nil, log).Recommend(context.Background(), userID, 4)
+		require.NoError(t, err)
+		assert.Len(t, list, 4)
+
+		_, err = NewBanditEngine([]Engine{failing}, nil, log).Recommend(context.Background(), userID, 4)
+		assert.Error(t, err)
+	})
+}
+
+func TestRecommendationEvents(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "text"})
+	require.NoError(t, err)
+
+	repo := newMemoryBanditRepository()
+	svc, err := NewService(&config.RecommendationConfig{}, &mockArticleRepository{}, &mockRatingRepositoryWithRatings{}, nil, repo, &mockEmbeddingClient{}, log)
+	require.NoError(t, err)
+	userID := uuid.New()
+
+	t.Run("Counts impressions and clicks per engine", func(t *testing.T) {
+		shown, err := svc.GetRecommendations(context.Background(), userID, EngineBandit, 4)
+		require.NoError(t, err)
+		require.NotEmpty(t, shown)
+
+		events := make([]EventInput, 0, len(shown)+1)
+		for i, rec := range shown {
+			events = append(events, EventInput{Type: EventImpression, ArticleID: rec.Article.ID, Engine: rec.RecommenderUsed, Position: i})
+		}
+		events = append(events, EventInput{Type: EventClick, ArticleID: shown[0].Article.ID, Engine: shown[0].RecommenderUsed})
+		require.NoError(t, svc.RecordEvents(context.Background(), userID, events))
+		require.NoError(t, svc.RecordEvents(context.Background(), userID, []EventInput{
+			{Type: EventImpression, ArticleID: uuid.New(), Engine: "popular"},
+		}))
+
+		ctr, err := svc.EngineCTR(context.Background(), 0)
+		require.NoError(t, err)
+		assert.Equal(t, defaultCTRDays, ctr.Days)
+		require.Len(t, ctr.Engines, 2)
+		assert.Equal(t, &EngineCTR{Engine: "bandit", Impressions: int64(len(shown)), Clicks: 1, CTR: 1 / float64(len(shown))}, ctr.Engines[0])
+		assert.Equal(t, &EngineCTR{Engine: "popular", Impressions: 1}, ctr.Engines[1])
+
+		rewarded := int64(0)
+		for _, arm := range repo.arms {
+			rewarded += arm.Rewards
+		}
+		assert.EqualValues(t, 1, rewarded, "clicks credit the bandit engine")
+	})
+
+	t.Run("Rejects malformed events", func(t *testing.T) {
+		articleID := uuid.New()
+		for name, events := range map[string][]EventInput{
+			"none":           {},
+			"unknown type":   {{Type: "hover", ArticleID: articleID, Engine: "popular"}},
+			"no article":     {{Type: EventClick, Engine: "popular"}},
+			"unknown engine": {{Type: EventClick, ArticleID: articleID, Engine: "editorial"}},
+			"negative rank":  {{Type: EventImpression, ArticleID: articleID, Engine: "popular", Position: -1}},
+		} {
+			err := svc.RecordEvents(context.Background(), userID, events)
+			assert.ErrorIs(t, err, utils.ErrValidation, name)
+		}
+
+		_, err := svc.EngineCTR(context.Background(), 91)
+		assert.ErrorIs(t, err, utils.ErrValidation)
+	})
+
+	t.Run("Precomputing drops expired events", func(t *testing.T) {
+		repo.events = append(repo.events, &Event{Engine: "popular", Type: EventClick, CreatedAt: time.Now().Add(-eventRetention - time.Hour)})
+		count := len(repo.events)
+
+		_, err := svc.PrecomputeRecommendations(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Len(t, repo.events, count-1)
+	})
+}
+
+func TestEngineSelection(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "text"})
+	require.NoError(t, err)
+
+	svc, err := NewService(&config.RecommendationConfig{}, &mockArticleRepository{}, &mockRatingRepositoryWithRatings{}, nil, nil, &mockEmbeddingClient{}, log)
+	require.NoError(t, err)
+	userID := uuid.New()
+
+	expected := map[string]string{
+		"":                  "content-based",
+		EngineContent:       "content-based",
+		EnginePopular:       "popular",
+		EngineCollaborative: "collaborative",
+		EngineHybrid:        "hybrid",
+		EngineBandit:        "bandit",
+	}
+	for engine, recommender := range expected {
+		recommendations, err := svc.GetRecommendations(context.Background(), userID, engine, 5)
+		require.NoError(t, err, engine)
+		require.NotEmpty(t, recommendations, engine)
+		assert.Equal(t, recommender, recommendations[0].RecommenderUsed, engine)
+	}
+
+	_, err = svc.GetRecommendations(context.Background(), userID, "random", 5)
+	assert.ErrorIs(t, err, ErrUnknownEngine)
+	_, err = svc.GetRecommendationPage(context.Background(), userID, PageRequest{Engine: "random", Snapshot: true, Limit: 5})
+	assert.ErrorIs(t, err, utils.ErrValidation)
+
+	// Precomputed lists belong to the default engine only
+	_, err = svc.PrecomputeRecommendations(context.Background(), []uuid.UUID{userID})
+	require.NoError(t, err)
+	recommendations, err := svc.GetRecommendations(context.Background(), userID, EnginePopular, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "popular", recommendations[0].RecommenderUsed)
+}
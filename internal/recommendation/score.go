@@ -0,0 +1,35 @@
+package recommendation
+
+import "sort"
+
+// Raw score scales, reported as score_type
+const (
+	// ScoreTypeSimilarity is the cosine similarity between the article and the
+	// user's profile, from -1 to 1
+	ScoreTypeSimilarity = "similarity"
+	// ScoreTypePopularity blends how many readers saved the link, how they
+	// rated it and how recently the article was saved, 0-1
+	ScoreTypePopularity = "popularity"
+	// ScoreTypeAffinity counts, over the readers who liked the article, the
+	// links they and the user both liked
+	ScoreTypeAffinity = "affinity"
+	// ScoreTypeBlended is the weighted mean of sub-engine percentiles, 0-1
+	ScoreTypeBlended = "blended"
+)
+
+// normalizeScores sets each recommendation's Score to its percentile among the
+// candidates by RawScore: the share of candidates scoring no higher, so the
+// best gets 1 and ties share a score. Raw scores of different engines live on
+// unrelated scales; percentiles do not, so lists can be merged by Score.
+func normalizeScores(candidates []*RecommendedArticle) {
+	raw := make([]float64, len(candidates))
+	for i, candidate := range candidates {
+		raw[i] = candidate.RawScore
+	}
+	sort.Float64s(raw)
+
+	for _, candidate := range candidates {
+		atMost := sort.Search(len(raw), func(i int) bool { return raw[i] > candidate.RawScore })
+		candidate.Score = float64(atMost) / float64(len(raw))
+	}
+}
@@ -0,0 +1,84 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+const (
+	maxSemanticQueryLength = 500
+	// Queries up to this many words read like headlines and match title embeddings best
+	shortQueryWords = 5
+)
+
+// SpaceAuto lets semantic search pick the embedding space from the query
+const SpaceAuto = "auto"
+
+// SemanticSearch finds the user's own articles closest in meaning to the query
+func (s *service) SemanticSearch(ctx context.Context, userID uuid.UUID, query string, space string, limit int) (*SemanticSearchResponse, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, utils.NewValidationError("q", "search query is required")
+	}
+	if len(query) > maxSemanticQueryLength {
+		return nil, utils.NewValidationError("q", fmt.Sprintf("search query must be at most %d characters", maxSemanticQueryLength))
+	}
+
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	selected, err := chooseSearchSpace(query, space)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Semantic search for user " + userID.String() + " in " + string(selected) + " space")
+
+	embeddingCtx, cancel := utils.DeriveDeadline(ctx, embeddingBudgetShare)
+	queryEmbedding, err := s.embeddingClient.GetEmbedding(embeddingCtx, query)
+	cancel()
+	if err != nil {
+		s.logger.Error("Failed to embed search query for user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("failed to embed search query: %w", err)
+	}
+
+	articles, err := s.articleRepo.WithContext(ctx).FindSimilarInLibrary(queryEmbedding, userID, selected, s.settings.TitleWeight, limit)
+	if err != nil {
+		s.logger.Error("Failed semantic search for user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("failed to search articles: %w", err)
+	}
+
+	if articles == nil {
+		articles = make([]*Article, 0)
+	}
+
+	return &SemanticSearchResponse{
+		Query:    query,
+		Space:    selected,
+		Articles: articles,
+		Count:    len(articles),
+	}, nil
+}
+
+// chooseSearchSpace resolves the requested space, picking one from the query length in auto mode
+func chooseSearchSpace(query string, space string) (EmbeddingSpace, error) {
+	if space == "" || space == SpaceAuto {
+		if len(strings.Fields(query)) <= shortQueryWords {
+			return SpaceTitle, nil
+		}
+		return SpaceContent, nil
+	}
+	parsed, err := ParseEmbeddingSpace(space)
+	if err != nil {
+		return "", utils.NewValidationError("space", err.Error())
+	}
+	return parsed, nil
+}
@@ -0,0 +1,101 @@
+package recommendation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxSeenWindow is how long returned articles are remembered, and so the
+	// longest seen window a request can ask for
+	maxSeenWindow = 24 * time.Hour
+	// maxSeenPerUser bounds the articles remembered for one user; the ones
+	// returned longest ago are forgotten first
+	maxSeenPerUser = 1000
+)
+
+// seenStore remembers which articles were returned to each user and when, so
+// a new list can leave out what the user was just shown
+type seenStore struct {
+	mu      sync.Mutex
+	entries map[uuid.UUID]map[uuid.UUID]time.Time // Last time each article was returned, per user
+	now     func() time.Time
+}
+
+func newSeenStore() *seenStore {
+	return &seenStore{
+		entries: make(map[uuid.UUID]map[uuid.UUID]time.Time),
+		now:     time.Now,
+	}
+}
+
+// record marks the recommendations as returned to the user now
+func (s *seenStore) record(userID uuid.UUID, recommendations []*RecommendedArticle) {
+	if len(recommendations) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	seen, ok := s.entries[userID]
+	if !ok {
+		seen = make(map[uuid.UUID]time.Time)
+		s.entries[userID] = seen
+	}
+	for _, rec := range recommendations {
+		seen[rec.Article.ID] = now
+	}
+
+	for articleID, seenAt := range seen {
+		if now.Sub(seenAt) >= maxSeenWindow {
+			delete(seen, articleID)
+		}
+	}
+	for len(seen) > maxSeenPerUser {
+		var oldestID uuid.UUID
+		var oldest time.Time
+		for articleID, seenAt := range seen {
+			if oldest.IsZero() || seenAt.Before(oldest) {
+				oldestID, oldest = articleID, seenAt
+			}
+		}
+		delete(seen, oldestID)
+	}
+}
+
+// since returns the articles returned to the user within the window
+func (s *seenStore) since(userID uuid.UUID, window time.Duration) map[uuid.UUID]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	seen := make(map[uuid.UUID]bool)
+	for articleID, seenAt := range s.entries[userID] {
+		if now.Sub(seenAt) < window {
+			seen[articleID] = true
+		}
+	}
+	return seen
+}
+
+// prune forgets articles returned longer ago than any window can reach
+func (s *seenStore) prune() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for userID, seen := range s.entries {
+		for articleID, seenAt := range seen {
+			if now.Sub(seenAt) >= maxSeenWindow {
+				delete(seen, articleID)
+			}
+		}
+		if len(seen) == 0 {
+			delete(s.entries, userID)
+		}
+	}
+}
@@ -1,49 +1,321 @@
 package recommendation
 
 import (
+	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/dustin/articles-backend/config"
 	"github.com/dustin/articles-backend/internal/embedding"
 	"github.com/dustin/articles-backend/pkg/logger"
 	"github.com/google/uuid"
 )
 
+// Settings tunes how recommendations are scored
+type Settings struct {
+	// EmbeddingSpace is the article embedding recommendations compare against
+	EmbeddingSpace EmbeddingSpace
+	// TitleWeight is the share of the title distance in the blended space (0-1)
+	TitleWeight float64
+	// DedupeURLs leaves out copies of links the user saved, and recommends
+	// each link once however many readers saved it
+	DedupeURLs bool
+	// DiversityLambda balances similarity to the profile against variety when
+	// ranking content-based recommendations (above 0, up to 1). 1, or 0 when
+	// unset, ranks by similarity only.
+	DiversityLambda float64
+	// Popularity ranks the popular engine's candidates
+	Popularity PopularityWeights
+	// MinRating is the lowest rating, 1-5, that adds an article to the user's
+	// profile; 0 means the default of 4
+	MinRating int
+	// CandidateMultiplier is how many similar articles the content engine
+	// fetches per recommendation, leaving room for repeated links and
+	// re-ranking; 0 means the default of 2
+	CandidateMultiplier int
+}
+
+// Defaults of the content engine settings left unset
+const (
+	defaultMinRating           = 4
+	defaultCandidateMultiplier = 2
+	maxCandidateMultiplier     = 10
+)
+
+// candidates returns the filter for searches on behalf of the user
+func (s Settings) candidates(userID uuid.UUID) CandidateFilter {
+	return CandidateFilter{UserID: userID, ExcludeSavedURLs: s.DedupeURLs}
+}
+
+// minRating returns the lowest rating that counts as liking an article
+func (s Settings) minRating() int {
+	if s.MinRating == 0 {
+		return defaultMinRating
+	}
+	return s.MinRating
+}
+
+// candidatePool returns how many similar articles to fetch for limit recommendations
+func (s Settings) candidatePool(limit int) int {
+	if s.CandidateMultiplier == 0 {
+		return limit * defaultCandidateMultiplier
+	}
+	return limit * s.CandidateMultiplier
+}
+
 // service implements the Service interface
 type service struct {
-	defaultEngine Engine
-	engines       map[string]Engine
-	logger        *logger.Logger
+	settings        Settings
+	defaultEngine   string // Key of the engine used when none is named
+	engines         map[string]Engine
+	content         *ContentBasedEngine
+	bandit          *BanditEngine
+	articleRepo     ArticleRepository
+	ratingRepo      RatingRepository
+	embeddingClient embedding.EmbeddingClient
+	cache           *resultCache
+	precomputedTTL  time.Duration // How long precomputed lists are served
+	resultTTL       time.Duration // How long lists computed on request are reused
+	snapshots       *snapshotStore
+	seen            *seenStore
+	profileMu       sync.Mutex
+	refreshing      map[uuid.UUID]bool // Users whose profile is being refreshed, and whether it changed again since
+	logger          *logger.Logger
 }
 
-// NewService creates a new recommendation service
-func NewService(articleRepo ArticleRepository, ratingRepo RatingRepository, embeddingClient embedding.EmbeddingClient, log *logger.Logger) Service {
-	// Create content-based recommendation engine
-	contentEngine := NewContentBasedEngine(articleRepo, ratingRepo, embeddingClient, log)
+// maxRecommendationLimit caps the number of recommendations per request
+const maxRecommendationLimit = 100
 
-	return &service{
-		defaultEngine: contentEngine,
-		engines: map[string]Engine{
-			"content": contentEngine,
-		},
-		logger: log.WithComponent("recommendation-service"),
+// NewService creates a new recommendation service with validation and defaults.
+// Without profileRepo, user profiles are built on each request; without
+// banditRepo, the bandit engine does not learn from feedback.
+func NewService(cfg *config.RecommendationConfig, articleRepo ArticleRepository, ratingRepo RatingRepository, profileRepo ProfileRepository, banditRepo BanditRepository, embeddingClient embedding.EmbeddingClient, log *logger.Logger) (Service, error) {
+	settings := Settings{
+		EmbeddingSpace: SpaceTitle, // Default to title space (matches embeddings created before content embeddings existed)
+		TitleWeight:    0.5,
+		DedupeURLs:     true,
+		Popularity:     PopularityWeights{Saves: 0.5, Rating: 0.3, Recency: 0.2, HalfLife: 7 * 24 * time.Hour},
+	}
+
+	if cfg != nil && cfg.EmbeddingSpace != "" {
+		space, err := ParseEmbeddingSpace(cfg.EmbeddingSpace)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recommendation embedding space '%s': %v", cfg.EmbeddingSpace, err)
+		}
+		settings.EmbeddingSpace = space
+	}
+
+	if cfg != nil && cfg.BlendWeight != "" {
+		weight, err := strconv.ParseFloat(cfg.BlendWeight, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recommendation blend weight '%s': %v", cfg.BlendWeight, err)
+		}
+		if weight < 0 || weight > 1 {
+			return nil, fmt.Errorf("invalid recommendation blend weight '%s': must be between 0 and 1", cfg.BlendWeight)
+		}
+		settings.TitleWeight = weight
+	}
+
+	if cfg != nil && cfg.DedupeURLs != "" {
+		dedupe, err := strconv.ParseBool(cfg.DedupeURLs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recommendation URL deduplication '%s': must be true or false", cfg.DedupeURLs)
+		}
+		settings.DedupeURLs = dedupe
+	}
+
+	if cfg != nil && cfg.DiversityLambda != "" {
+		lambda, err := strconv.ParseFloat(cfg.DiversityLambda, 64)
+		if err != nil || lambda <= 0 || lambda > 1 {
+			return nil, fmt.Errorf("invalid recommendation diversity lambda '%s': must be above 0 and at most 1", cfg.DiversityLambda)
+		}
+		settings.DiversityLambda = lambda
+	}
+
+	if cfg != nil && cfg.MinRating != "" {
+		minRating, err := strconv.Atoi(cfg.MinRating)
+		if err != nil || minRating < 1 || minRating > 5 {
+			return nil, fmt.Errorf("invalid recommendation minimum rating '%s': must be an integer between 1 and 5", cfg.MinRating)
+		}
+		settings.MinRating = minRating
+	}
+
+	if cfg != nil && cfg.CandidateMultiplier != "" {
+		multiplier, err := strconv.Atoi(cfg.CandidateMultiplier)
+		if err != nil || multiplier < 1 || multiplier > maxCandidateMultiplier {
+			return nil, fmt.Errorf("invalid recommendation candidate multiplier '%s': must be an integer between 1 and %d", cfg.CandidateMultiplier, maxCandidateMultiplier)
+		}
+		settings.CandidateMultiplier = multiplier
+	}
+
+	if cfg != nil {
+		var err error
+		if settings.Popularity.Saves, err = parseWeight("popular saves", cfg.PopularSavesWeight, settings.Popularity.Saves); err != nil {
+			return nil, err
+		}
+		if settings.Popularity.Rating, err = parseWeight("popular rating", cfg.PopularRatingWeight, settings.Popularity.Rating); err != nil {
+			return nil, err
+		}
+		if settings.Popularity.Recency, err = parseWeight("popular recency", cfg.PopularRecencyWeight, settings.Popularity.Recency); err != nil {
+			return nil, err
+		}
+	}
+	if settings.Popularity.Saves+settings.Popularity.Rating+settings.Popularity.Recency == 0 {
+		return nil, fmt.Errorf("invalid recommendation popular weights: at least one must be positive")
+	}
+
+	if cfg != nil && cfg.PopularHalfLife != "" {
+		halfLife, err := time.ParseDuration(cfg.PopularHalfLife)
+		if err != nil || halfLife <= 0 {
+			return nil, fmt.Errorf("invalid recommendation popular half-life '%s': must be a positive duration", cfg.PopularHalfLife)
+		}
+		settings.Popularity.HalfLife = halfLife
 	}
+
+	cacheTTL := 25 * time.Hour // Outlives a nightly precompute run until the next one
+	if cfg != nil && cfg.CacheTTL != "" {
+		ttl, err := time.ParseDuration(cfg.CacheTTL)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid recommendation cache TTL '%s': must be a positive duration", cfg.CacheTTL)
+		}
+		cacheTTL = ttl
+	}
+
+	resultTTL := 15 * time.Minute
+	if cfg != nil && cfg.ResultCacheTTL != "" {
+		ttl, err := time.ParseDuration(cfg.ResultCacheTTL)
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("invalid recommendation result cache TTL '%s': must be a non-negative duration", cfg.ResultCacheTTL)
+		}
+		resultTTL = ttl
+	}
+
+	contentWeight, collaborativeWeight := 0.7, 0.3
+	if cfg != nil {
+		var err error
+		if contentWeight, err = parseWeight("hybrid content", cfg.HybridContentWeight, contentWeight); err != nil {
+			return nil, err
+		}
+		if collaborativeWeight, err = parseWeight("hybrid collaborative", cfg.HybridCollabWeight, collaborativeWeight); err != nil {
+			return nil, err
+		}
+	}
+	if contentWeight+collaborativeWeight == 0 {
+		return nil, fmt.Errorf("invalid recommendation hybrid weights: at least one must be positive")
+	}
+
+	contentEngine := newContentBasedEngine(articleRepo, ratingRepo, profileRepo, embeddingClient, settings, log)
+	collaborativeEngine := NewCollaborativeEngine(articleRepo, log)
+	popularEngine := NewPopularEngine(articleRepo, settings, log)
+	banditEngine := NewBanditEngine([]Engine{contentEngine, collaborativeEngine, popularEngine}, banditRepo, log)
+	engines := map[string]Engine{
+		EngineContent:       contentEngine,
+		EnginePopular:       popularEngine,
+		EngineCollaborative: collaborativeEngine,
+		EngineHybrid: NewHybridEngine([]WeightedEngine{
+			{Engine: contentEngine, Weight: contentWeight},
+			{Engine: collaborativeEngine, Weight: collaborativeWeight},
+		}, log),
+		EngineBandit: banditEngine,
+	}
+
+	defaultEngine := EngineContent
+	if cfg != nil && cfg.Engine != "" {
+		defaultEngine = strings.ToLower(cfg.Engine)
+		if _, ok := engines[defaultEngine]; !ok {
+			return nil, fmt.Errorf("invalid recommendation engine '%s': must be content, popular, collaborative, hybrid or bandit", cfg.Engine)
+		}
+	}
+
+	return &service{
+		settings:        settings,
+		defaultEngine:   defaultEngine,
+		engines:         engines,
+		content:         contentEngine,
+		bandit:          banditEngine,
+		articleRepo:     articleRepo,
+		ratingRepo:      ratingRepo,
+		embeddingClient: embeddingClient,
+		cache:           newResultCache(),
+		precomputedTTL:  cacheTTL,
+		resultTTL:       resultTTL,
+		snapshots:       newSnapshotStore(snapshotTTL),
+		seen:            newSeenStore(),
+		refreshing:      make(map[uuid.UUID]bool),
+		logger:          log.WithComponent("recommendation-service"),
+	}, nil
 }
 
-func (s *service) GetRecommendations(userID uuid.UUID, limit int) ([]*RecommendedArticle, error) {
+func (s *service) GetRecommendations(ctx context.Context, userID uuid.UUID, engine string, limit int) ([]*RecommendedArticle, error) {
 	s.logger.Info("Getting recommendations for user " + userID.String() + " with limit " + fmt.Sprintf("%d", limit))
 
+	if engine == "" {
+		engine = s.defaultEngine
+	}
+	if _, ok := s.engines[engine]; !ok {
+		return nil, ErrUnknownEngine
+	}
+
 	// Validate limit
 	if limit < 1 {
 		limit = 10
 	}
-	if limit > 100 {
-		limit = 100
+	if limit > maxRecommendationLimit {
+		limit = maxRecommendationLimit
 	}
 
-	// Generate recommendations using default engine
-	recommendations, err := s.defaultEngine.Recommend(userID, limit)
+	// Serve lists precomputed for active users, or computed by an earlier
+	// request, without touching the embedding service
+	if recommendations, ok := s.cache.get(userID, engine, limit); ok {
+		s.logger.Debug("Serving cached recommendations for user " + userID.String())
+		return recommendations, nil
+	}
+
+	// Then lists the background job stored, possibly from another instance;
+	// users without a fresh one are computed live
+	if recommendations, ok := s.loadList(ctx, userID, engine, limit); ok {
+		s.logger.Debug("Serving stored recommendations for user " + userID.String())
+		return recommendations, nil
+	}
+
+	startedAt := s.cache.now()
+	recommendations, err := s.generate(ctx, userID, engine, limit)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.set(userID, engine, recommendations, limit, startedAt, s.resultTTL)
+	return recommendations, nil
+}
+
+// InvalidateRecommendations drops the user's cached and stored lists, e.g.
+// once their ratings changed
+func (s *service) InvalidateRecommendations(userID uuid.UUID) {
+	s.cache.invalidate(userID)
+	s.dropLists(userID)
+}
+
+// parseWeight reads a non-negative blend weight, e.g. of a hybrid engine
+func parseWeight(name, value string, fallback float64) (float64, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	weight, err := strconv.ParseFloat(value, 64)
+	if err != nil || weight < 0 {
+		return 0, fmt.Errorf("invalid recommendation %s weight '%s': must be a non-negative number", name, value)
+	}
+	return weight, nil
+}
+
+// generate runs the named engine and annotates the results
+func (s *service) generate(ctx context.Context, userID uuid.UUID, engineName string, limit int) ([]*RecommendedArticle, error) {
+	engine := s.engines[engineName]
+	recommendations, err := engine.Recommend(ctx, userID, limit)
 	if err != nil {
-		s.logger.Error("Failed to generate recommendations for user " + userID.String() + " using engine '" + s.defaultEngine.Name() + "' with limit " + fmt.Sprintf("%d", limit) + ": " + err.Error())
+		s.logger.Error("Failed to generate recommendations for user " + userID.String() + " using engine '" + engine.Name() + "' with limit " + fmt.Sprintf("%d", limit) + ": " + err.Error())
 		return nil, fmt.Errorf("failed to generate recommendations: %w", err)
 	}
 
@@ -53,17 +325,30 @@ func (s *service) GetRecommendations(userID uuid.UUID, limit int) ([]*Recommende
 	}
 
 	// Log success
-	s.logger.Info("Recommendations generated successfully for user " + userID.String() + ": " + fmt.Sprintf("%d", len(recommendations)) + " recommendations using engine '" + s.defaultEngine.Name() + "'")
+	s.logger.Info("Recommendations generated successfully for user " + userID.String() + ": " + fmt.Sprintf("%d", len(recommendations)) + " recommendations using engine '" + engine.Name() + "'")
 
-	// Enhance recommendations with additional context
+	// Enhance recommendations with additional context; popularity ranks say
+	// nothing about how well an article matches. The cosine similarity is
+	// used rather than the percentile, which ranks even a poor best match first.
 	for i, rec := range recommendations {
-		if rec.Score > 0.8 {
-			rec.Reason = "Highly " + rec.Reason
-		} else if rec.Score < 0.3 {
-			rec.Reason = "Potentially " + rec.Reason
+		if rec.ScoreType != ScoreTypeSimilarity {
+			continue
+		}
+		if rec.RawScore > 0.8 {
+			rec.Reason = "Highly " + lowerFirst(rec.Reason)
+		} else if rec.RawScore < 0.3 {
+			rec.Reason = "Potentially " + lowerFirst(rec.Reason)
 		}
 		recommendations[i] = rec
 	}
 
 	return recommendations, nil
 }
+
+// lowerFirst lets a reason follow a qualifier such as "Highly"
+func lowerFirst(reason string) string {
+	if reason == "" {
+		return reason
+	}
+	return strings.ToLower(reason[:1]) + reason[1:]
+}
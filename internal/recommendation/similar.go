@@ -0,0 +1,90 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// ErrNotEmbedded is returned when an article has no embedding to compare yet
+var ErrNotEmbedded = utils.NewConflictError("article has not been embedded yet")
+
+// SimilarArticles finds other readers' articles closest to one of the user's
+// own articles, by the article's stored embedding
+func (s *service) SimilarArticles(ctx context.Context, userID, articleID uuid.UUID, limit int) (*SimilarArticlesResponse, error) {
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > maxRecommendationLimit {
+		limit = maxRecommendationLimit
+	}
+
+	dbCtx, cancel := utils.DeriveDeadline(ctx, databaseBudgetShare)
+	article, err := s.articleRepo.WithContext(dbCtx).FindByID(articleID)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	if article.UserID != userID {
+		return nil, ErrArticleNotFound
+	}
+
+	vector, space := articleVector(article, s.settings.EmbeddingSpace)
+	if vector == nil {
+		return nil, ErrNotEmbedded
+	}
+
+	// Copies of the article's own link are left out, so ask for one more
+	articles, err := s.articleRepo.WithContext(ctx).FindSimilar(vector, s.settings.candidates(userID), space, s.settings.TitleWeight, limit+1)
+	if err != nil {
+		s.logger.Error("Failed to find articles similar to " + articleID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("failed to find similar articles: %w", err)
+	}
+	if s.settings.DedupeURLs {
+		articles = uniqueByURL(articles)
+	}
+
+	similar := make([]*RecommendedArticle, 0, len(articles))
+	for _, candidate := range articles {
+		if candidate.URL == article.URL {
+			continue
+		}
+		similar = append(similar, &RecommendedArticle{
+			Article:         candidate,
+			RawScore:        1 - candidate.Distance,
+			ScoreType:       ScoreTypeSimilarity,
+			Reason:          "Similar to " + article.Title,
+			RecommenderUsed: similarRecommender,
+		})
+	}
+	normalizeScores(similar)
+	if len(similar) > limit {
+		similar = similar[:limit]
+	}
+
+	return &SimilarArticlesResponse{
+		ArticleID: articleID,
+		Space:     space,
+		Articles:  similar,
+		Count:     len(similar),
+	}, nil
+}
+
+// articleVector picks the embedding of the article to compare in the space,
+// matching the text profiles embed: the content embedding in the content space
+// and the title embedding otherwise. Articles without a content embedding are
+// compared by their title embedding, and nil is returned when there is none.
+func articleVector(article *Article, space EmbeddingSpace) ([]float64, EmbeddingSpace) {
+	if space == SpaceContent && article.ContentEmbeddingStatus == "success" && len(article.ContentEmbedding) > 0 {
+		return article.ContentEmbedding, SpaceContent
+	}
+	if article.EmbeddingStatus != "success" || len(article.Embedding) == 0 {
+		return nil, space
+	}
+	if space == SpaceContent {
+		return article.Embedding, SpaceTitle
+	}
+	return article.Embedding, space
+}
@@ -0,0 +1,243 @@
+package recommendation
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+const (
+	// snapshotTTL is how long clients can page through a recommendation list
+	snapshotTTL = 30 * time.Minute
+	// maxSnapshotsPerUser bounds the lists kept for one user; the oldest is
+	// dropped when a user starts paging through another
+	maxSnapshotsPerUser = 5
+)
+
+// ErrSnapshotExpired is returned for cursors of lists that are no longer kept
+var ErrSnapshotExpired = utils.NewValidationError("cursor", "has expired; request the first page again")
+
+// RecommendationPage is one page of a recommendation list
+type RecommendationPage struct {
+	Recommendations []*RecommendedArticle
+	GeneratedAt     time.Time // When the list being paged through was computed
+	NextCursor      string    // Empty on the last page
+}
+
+// snapshot is a recommendation list frozen for paging. Pages are sliced from
+// it, so scores changing between requests cannot repeat or skip articles.
+type snapshot struct {
+	userID          uuid.UUID
+	recommendations []*RecommendedArticle
+	generatedAt     time.Time
+}
+
+// snapshotStore keeps recommendation lists being paged through until they expire
+type snapshotStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[uuid.UUID]*snapshot
+	now     func() time.Time
+}
+
+func newSnapshotStore(ttl time.Duration) *snapshotStore {
+	return &snapshotStore{
+		ttl:     ttl,
+		entries: make(map[uuid.UUID]*snapshot),
+		now:     time.Now,
+	}
+}
+
+// put stores a new list for the user and returns its ID. Expired lists are
+// dropped on the way.
+func (s *snapshotStore) put(userID uuid.UUID, recommendations []*RecommendedArticle) (uuid.UUID, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var oldestID uuid.UUID
+	var oldest *snapshot
+	held := 0
+	for id, entry := range s.entries {
+		if now.Sub(entry.generatedAt) >= s.ttl {
+			delete(s.entries, id)
+			continue
+		}
+		if entry.userID != userID {
+			continue
+		}
+		held++
+		if oldest == nil || entry.generatedAt.Before(oldest.generatedAt) {
+			oldestID, oldest = id, entry
+		}
+	}
+	if held >= maxSnapshotsPerUser {
+		delete(s.entries, oldestID)
+	}
+
+	id := uuid.New()
+	s.entries[id] = &snapshot{userID: userID, recommendations: recommendations, generatedAt: now}
+	return id, now
+}
+
+// get returns the user's list with the given ID, if it has not expired
+func (s *snapshotStore) get(id, userID uuid.UUID) (*snapshot, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+	if !ok || entry.userID != userID || s.now().Sub(entry.generatedAt) >= s.ttl {
+		return nil, false
+	}
+	return entry, true
+}
+
+// PageRequest selects a page of a user's recommendations
+type PageRequest struct {
+	Engine string // Empty for the configured default
+	Limit  int
+	// Cursor continues a list frozen by an earlier request; Offset and
+	// SeenWindow only shape new lists
+	Cursor string
+	// Snapshot freezes a new list so later pages can follow NextCursor
+	Snapshot bool
+	// Offset skips articles at the start of a new list
+	Offset int
+	// SeenWindow leaves articles returned to the user this recently out of a
+	// new list; 0 keeps them
+	SeenWindow time.Duration
+}
+
+// GetRecommendationPage returns a page of recommendations and remembers its
+// articles as seen. A new list is cut from up to maxRecommendationLimit
+// recommendations of the named engine; with Snapshot, or when following a
+// cursor, later pages are cut from that same list, whatever engine they name.
+func (s *service) GetRecommendationPage(ctx context.Context, userID uuid.UUID, request PageRequest) (*RecommendationPage, error) {
+	limit := request.Limit
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > maxRecommendationLimit {
+		limit = maxRecommendationLimit
+	}
+	if request.Offset < 0 || request.Offset >= maxRecommendationLimit {
+		return nil, utils.NewValidationError("offset", fmt.Sprintf("must be between 0 and %d", maxRecommendationLimit-1))
+	}
+	if request.SeenWindow < 0 || request.SeenWindow > maxSeenWindow {
+		return nil, utils.NewValidationError("seen_window", "must be between 0 and "+maxSeenWindow.String())
+	}
+
+	var page *RecommendationPage
+	var err error
+	if request.Cursor != "" {
+		page, err = s.continuePage(userID, request.Cursor, limit)
+	} else {
+		page, err = s.newPage(ctx, userID, request, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.seen.record(userID, page.Recommendations)
+	return page, nil
+}
+
+// newPage computes a list, leaves out what the user saw within the window,
+// and returns the page at the requested offset
+func (s *service) newPage(ctx context.Context, userID uuid.UUID, request PageRequest, limit int) (*RecommendationPage, error) {
+	size := maxRecommendationLimit
+	var seen map[uuid.UUID]bool
+	if request.SeenWindow > 0 {
+		seen = s.seen.since(userID, request.SeenWindow)
+	} else if !request.Snapshot {
+		size = min(request.Offset+limit, maxRecommendationLimit)
+	}
+
+	recommendations, err := s.GetRecommendations(ctx, userID, request.Engine, size)
+	if err != nil {
+		return nil, err
+	}
+	if len(seen) > 0 {
+		unseen := make([]*RecommendedArticle, 0, len(recommendations))
+		for _, rec := range recommendations {
+			if !seen[rec.Article.ID] {
+				unseen = append(unseen, rec)
+			}
+		}
+		recommendations = unseen
+	}
+
+	if !request.Snapshot {
+		start := min(request.Offset, len(recommendations))
+		end := min(start+limit, len(recommendations))
+		return &RecommendationPage{Recommendations: recommendations[start:end], GeneratedAt: time.Now()}, nil
+	}
+
+	snapshotID, generatedAt := s.snapshots.put(userID, recommendations)
+	list := &snapshot{userID: userID, recommendations: recommendations, generatedAt: generatedAt}
+	return list.page(snapshotID, request.Offset, limit), nil
+}
+
+// continuePage returns the page of a frozen list the cursor points at
+func (s *service) continuePage(userID uuid.UUID, cursor string, limit int) (*RecommendationPage, error) {
+	snapshotID, offset, err := decodeSnapshotCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := s.snapshots.get(snapshotID, userID)
+	if !ok {
+		return nil, ErrSnapshotExpired
+	}
+	return list.page(snapshotID, offset, limit), nil
+}
+
+// page cuts limit recommendations starting at offset from the list
+func (l *snapshot) page(id uuid.UUID, offset, limit int) *RecommendationPage {
+	start := min(offset, len(l.recommendations))
+	end := min(start+limit, len(l.recommendations))
+	page := &RecommendationPage{
+		Recommendations: l.recommendations[start:end],
+		GeneratedAt:     l.generatedAt,
+	}
+	if end < len(l.recommendations) {
+		page.NextCursor = encodeSnapshotCursor(id, end)
+	}
+	return page
+}
+
+// encodeSnapshotCursor returns the opaque token for the page starting at offset
+func encodeSnapshotCursor(id uuid.UUID, offset int) string {
+	raw := id.String() + "|" + strconv.Itoa(offset)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeSnapshotCursor parses a token produced by encodeSnapshotCursor
+func decodeSnapshotCursor(token string) (uuid.UUID, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return uuid.Nil, 0, utils.ErrInvalidCursor
+	}
+
+	id, offset, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return uuid.Nil, 0, utils.ErrInvalidCursor
+	}
+
+	snapshotID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, 0, utils.ErrInvalidCursor
+	}
+	position, err := strconv.Atoi(offset)
+	if err != nil || position < 0 {
+		return uuid.Nil, 0, utils.ErrInvalidCursor
+	}
+
+	return snapshotID, position, nil
+}
@@ -0,0 +1,150 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// storedListTimeout bounds reading or dropping a stored list, so a slow
+// database falls back to live computation rather than holding up the request
+const storedListTimeout = 2 * time.Second
+
+// StoredList is a recommendation list precomputed by the background job and
+// kept in the database, so every instance can serve it, including after a
+// restart
+type StoredList struct {
+	UserID     uuid.UUID     `gorm:"type:uuid;primaryKey"`
+	Engine     string        `gorm:"size:20;primaryKey"`
+	Items      []*StoredItem `gorm:"type:jsonb;serializer:json;not null"`
+	ComputedAt time.Time     `gorm:"not null;index"`
+}
+
+// TableName returns the table name for GORM
+func (StoredList) TableName() string {
+	return "recommendation_lists"
+}
+
+// StoredItem is a recommendation of a stored list. The article itself is
+// read when the list is served, so later edits show and deleted articles
+// drop out.
+type StoredItem struct {
+	ArticleID       uuid.UUID    `json:"article_id"`
+	Score           float64      `json:"score"`
+	RawScore        float64      `json:"raw_score"`
+	ScoreType       string       `json:"score_type"`
+	Reason          string       `json:"reason"`
+	RecommenderUsed string       `json:"recommender_used"`
+	Explanation     *Explanation `json:"explanation,omitempty"`
+}
+
+// ErrStoredListNotFound is returned by ProfileRepository.FindStoredList for users without a stored list
+var ErrStoredListNotFound = utils.NewNotFoundError("stored recommendations not found")
+
+// storeList keeps a precomputed list of the engine for the user
+func (s *service) storeList(ctx context.Context, userID uuid.UUID, engine string, recommendations []*RecommendedArticle, computedAt time.Time) error {
+	items := make([]*StoredItem, len(recommendations))
+	for i, rec := range recommendations {
+		items[i] = &StoredItem{
+			ArticleID:       rec.Article.ID,
+			Score:           rec.Score,
+			RawScore:        rec.RawScore,
+			ScoreType:       rec.ScoreType,
+			Reason:          rec.Reason,
+			RecommenderUsed: rec.RecommenderUsed,
+			Explanation:     rec.Explanation,
+		}
+	}
+
+	return s.content.profiles.WithContext(ctx).SaveStoredList(&StoredList{
+		UserID:     userID,
+		Engine:     engine,
+		Items:      items,
+		ComputedAt: computedAt,
+	})
+}
+
+// loadList returns up to limit recommendations of the engine from the user's
+// stored list, and caches the whole list until it goes stale. It misses
+// without a stored list, or with one older than the precomputed TTL.
+func (s *service) loadList(ctx context.Context, userID uuid.UUID, engine string, limit int) ([]*RecommendedArticle, bool) {
+	if s.content.profiles == nil {
+		return nil, false
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, storedListTimeout)
+	defer cancel()
+
+	stored, err := s.content.profiles.WithContext(dbCtx).FindStoredList(userID, engine)
+	if err != nil {
+		if !errors.Is(err, ErrStoredListNotFound) {
+			s.logger.Error("Failed to read stored recommendations of user " + userID.String() + ": " + err.Error())
+		}
+		return nil, false
+	}
+
+	age := s.cache.now().Sub(stored.ComputedAt)
+	if age >= s.precomputedTTL || len(stored.Items) == 0 {
+		return nil, false
+	}
+
+	ids := make([]uuid.UUID, len(stored.Items))
+	for i, item := range stored.Items {
+		ids[i] = item.ArticleID
+	}
+	articles, err := s.articleRepo.WithContext(dbCtx).FindByIDs(ids)
+	if err != nil {
+		s.logger.Error("Failed to read stored recommendations of user " + userID.String() + ": " + err.Error())
+		return nil, false
+	}
+	byID := make(map[uuid.UUID]*Article, len(articles))
+	for _, article := range articles {
+		byID[article.ID] = article
+	}
+
+	recommendations := make([]*RecommendedArticle, 0, len(stored.Items))
+	for _, item := range stored.Items {
+		article, ok := byID[item.ArticleID]
+		if !ok {
+			continue // Deleted since the list was computed
+		}
+		recommendations = append(recommendations, &RecommendedArticle{
+			Article:         article,
+			Score:           item.Score,
+			RawScore:        item.RawScore,
+			ScoreType:       item.ScoreType,
+			Reason:          item.Reason,
+			RecommenderUsed: item.RecommenderUsed,
+			Explanation:     item.Explanation,
+		})
+	}
+
+	// Stored lists are computed with the largest limit, so any request is
+	// served from the cache until the list goes stale
+	s.cache.set(userID, engine, recommendations, maxRecommendationLimit, stored.ComputedAt, s.precomputedTTL-age)
+
+	if limit > len(recommendations) {
+		limit = len(recommendations)
+	}
+	page := make([]*RecommendedArticle, limit)
+	copy(page, recommendations)
+	return page, true
+}
+
+// dropLists deletes the user's stored lists, so no instance serves them once
+// the user's ratings changed
+func (s *service) dropLists(userID uuid.UUID) {
+	if s.content.profiles == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storedListTimeout)
+	defer cancel()
+
+	if err := s.content.profiles.WithContext(ctx).DeleteStoredLists(userID); err != nil {
+		s.logger.Error("Failed to drop stored recommendations of user " + userID.String() + ": " + err.Error())
+	}
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	adminPkg "github.com/dustin/articles-backend/internal/admin"
+	articlePkg "github.com/dustin/articles-backend/internal/article"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// gormAdminRepository implements the admin.Repository interface
+type gormAdminRepository struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewGORMAdminRepository creates a new GORM-based admin repository
+func NewGORMAdminRepository(db *gorm.DB, log *logger.Logger) adminPkg.Repository {
+	return &gormAdminRepository{
+		db:     db,
+		logger: log.WithComponent("gorm-admin-repository"),
+	}
+}
+
+func (r *gormAdminRepository) ListUsers(filter *adminPkg.UserFilter, after *utils.Cursor, limit int) ([]*adminPkg.UserRow, error) {
+	query := r.db.Table("users").
+		Select("users.id, users.email, users.role, users.created_at, (SELECT COUNT(*) FROM articles WHERE articles.user_id = users.id) AS article_count")
+
+	if filter != nil {
+		if filter.Email != "" {
+			query = query.Where("users.email ILIKE ?", containsPattern(filter.Email))
+		}
+		if filter.CreatedAfter != nil {
+			query = query.Where("users.created_at >= ?", *filter.CreatedAfter)
+		}
+		if filter.CreatedBefore != nil {
+			query = query.Where("users.created_at < ?", *filter.CreatedBefore)
+		}
+	}
+	query = pageAfter(query, "users", after, limit)
+
+	var users []*adminPkg.UserRow
+	if err := query.Scan(&users).Error; err != nil {
+		r.logger.Error("Database error listing users: " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *gormAdminRepository) ListArticles(filter *adminPkg.ArticleFilter, after *utils.Cursor, limit int) ([]*adminPkg.ArticleRow, error) {
+	query := r.db.Table("articles").
+		Select("articles.id, articles.user_id, articles.url, articles.title, articles.metadata_status, articles.embedding_status, articles.created_at")
+
+	if filter != nil {
+		if filter.UserID != nil {
+			query = query.Where("articles.user_id = ?", *filter.UserID)
+		}
+		if filter.MetadataStatus != "" {
+			query = query.Where("articles.metadata_status = ?", filter.MetadataStatus)
+		}
+		if filter.Query != "" {
+			pattern := containsPattern(filter.Query)
+			query = query.Where("(articles.title ILIKE ? OR articles.url ILIKE ?)", pattern, pattern)
+		}
+		if filter.CreatedAfter != nil {
+			query = query.Where("articles.created_at >= ?", *filter.CreatedAfter)
+		}
+		if filter.CreatedBefore != nil {
+			query = query.Where("articles.created_at < ?", *filter.CreatedBefore)
+		}
+	}
+	query = pageAfter(query, "articles", after, limit)
+
+	var articles []*adminPkg.ArticleRow
+	if err := query.Scan(&articles).Error; err != nil {
+		r.logger.Error("Database error listing articles: " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return articles, nil
+}
+
+// failureDomain derives the host of an article URL, without "www.", in SQL
+const failureDomain = `LOWER(REGEXP_REPLACE(SUBSTRING(articles.url FROM '^[A-Za-z][A-Za-z0-9+.-]*://([^/:?#@]+)'), '^www\.', ''))`
+
+// failureGroupRow is a failure group with its example IDs joined into text
+type failureGroupRow struct {
+	Domain        string
+	Category      string
+	Count         int64
+	LastFailedAt  time.Time
+	LastError     string
+	LastRequestID string
+	ExampleIDs    string
+	Total         int64
+}
+
+func (r *gormAdminRepository) SearchFailures(filter *adminPkg.FailureFilter, examples, limit int) ([]*adminPkg.FailureGroup, int64, error) {
+	// Trashed articles are left out, as nobody is waiting for them
+	failures := r.db.Table("articles").
+		Select("articles.id, articles.last_error, articles.last_error_request_id, articles.last_error_category AS category, articles.last_error_at, "+failureDomain+" AS domain").
+		Where("articles.metadata_status = ? AND articles.last_error_at IS NOT NULL AND articles.deleted_at IS NULL", articlePkg.MetadataStatusFailed)
+
+	if filter != nil {
+		if filter.Category != "" {
+			failures = failures.Where("articles.last_error_category = ?", filter.Category)
+		}
+		if filter.FailedAfter != nil {
+			failures = failures.Where("articles.last_error_at >= ?", *filter.FailedAfter)
+		}
+		if filter.FailedBefore != nil {
+			failures = failures.Where("articles.last_error_at < ?", *filter.FailedBefore)
+		}
+	}
+
+	query := r.db.Table("(?) AS failures", failures).
+		Select(`failures.domain, failures.category, COUNT(*) AS count, MAX(failures.last_error_at) AS last_failed_at,
+			(ARRAY_AGG(failures.last_error ORDER BY failures.last_error_at DESC))[1] AS last_error,
+			(ARRAY_AGG(failures.last_error_request_id ORDER BY failures.last_error_at DESC))[1] AS last_request_id,
+			ARRAY_TO_STRING((ARRAY_AGG(failures.id::text ORDER BY failures.last_error_at DESC))[1:?], ',') AS example_ids,
+			SUM(COUNT(*)) OVER () AS total`, examples).
+		Group("failures.domain, failures.category").
+		Order("count DESC, last_failed_at DESC").
+		Limit(limit)
+	if filter != nil && filter.Domain != "" {
+		query = query.Where("(failures.domain = ? OR failures.domain LIKE ?)", filter.Domain, "%."+escapeLike(filter.Domain))
+	}
+
+	var rows []*failureGroupRow
+	if err := query.Scan(&rows).Error; err != nil {
+		r.logger.Error("Database error searching failures: " + err.Error())
+		return nil, 0, fmt.Errorf("database error: %w", err)
+	}
+
+	var total int64
+	groups := make([]*adminPkg.FailureGroup, 0, len(rows))
+	for _, row := range rows {
+		total = row.Total
+		group := &adminPkg.FailureGroup{
+			Domain:            row.Domain,
+			Category:          row.Category,
+			Count:             row.Count,
+			LastFailedAt:      row.LastFailedAt,
+			LastError:         row.LastError,
+			LastRequestID:     row.LastRequestID,
+			ExampleArticleIDs: []uuid.UUID{},
+		}
+		for _, id := range strings.Split(row.ExampleIDs, ",") {
+			if parsed, err := uuid.Parse(id); err == nil {
+				group.ExampleArticleIDs = append(group.ExampleArticleIDs, parsed)
+			}
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, total, nil
+}
+
+// pageAfter orders rows newest first and continues after the cursor
+func pageAfter(query *gorm.DB, table string, after *utils.Cursor, limit int) *gorm.DB {
+	if after != nil {
+		query = query.Where("("+table+".created_at, "+table+".id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+	return query.Order(table + ".created_at DESC").Order(table + ".id DESC").Limit(limit)
+}
+
+// containsPattern builds an ILIKE pattern matching value literally anywhere in the column
+func containsPattern(value string) string {
+	return "%" + escapeLike(value) + "%"
+}
+
+// escapeLike makes value match literally in a LIKE pattern
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
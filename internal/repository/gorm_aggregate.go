@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	aggregatePkg "github.com/dustin/articles-backend/internal/aggregate"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// aggregateSources select the key, UTC day and value of every source row of a
+// metric created on or after the given day. Trashed articles still count as
+// saved; purged ones drop out of days that are rebuilt after the purge.
+var aggregateSources = map[string]string{
+	aggregatePkg.MetricArticlesSaved: `SELECT user_id::text AS key, (created_at AT TIME ZONE 'UTC')::date AS day, 1 AS value
+		FROM articles WHERE created_at >= ?`,
+	aggregatePkg.MetricDomainSaves: `SELECT COALESCE(` + failureDomain + `, '') AS key, (articles.created_at AT TIME ZONE 'UTC')::date AS day, 1 AS value
+		FROM articles WHERE articles.created_at >= ?`,
+	aggregatePkg.MetricRatings: `SELECT user_id::text AS key, (created_at AT TIME ZONE 'UTC')::date AS day, 1 AS value
+		FROM ratings WHERE created_at >= ?`,
+	aggregatePkg.MetricAPIRequests: `SELECT user_id::text AS key, day, count AS value
+		FROM usage_counters WHERE day >= ?`,
+}
+
+// gormAggregateRepository implements the aggregate.Repository interface
+type gormAggregateRepository struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewGORMAggregateRepository creates a new GORM-based aggregate repository
+func NewGORMAggregateRepository(db *gorm.DB, log *logger.Logger) aggregatePkg.Repository {
+	return &gormAggregateRepository{
+		db:     db,
+		logger: log.WithComponent("gorm-aggregate-repository"),
+	}
+}
+
+func (r *gormAggregateRepository) Rebuild(metric string, since, refreshedAt time.Time) error {
+	source, ok := aggregateSources[metric]
+	if !ok {
+		return fmt.Errorf("no source for aggregate %s", metric)
+	}
+
+	// Readers keep seeing the previous buckets until the new ones are committed
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Instances refreshing the same metric at once would collide on the new rows
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "aggregate:"+metric).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("metric = ? AND day >= ?", metric, since).Delete(&aggregatePkg.Bucket{}).Error; err != nil {
+			return err
+		}
+
+		// One pass yields the per-key buckets and, through the second grouping
+		// set, the per-day totals under the total key
+		err := tx.Exec(`INSERT INTO aggregate_buckets (metric, key, day, value)
+			SELECT ?, CASE WHEN GROUPING(source.key) = 1 THEN ? ELSE source.key END, source.day, SUM(source.value)
+			FROM (`+source+`) AS source
+			GROUP BY GROUPING SETS ((source.key, source.day), (source.day))`,
+			metric, aggregatePkg.TotalKey, since).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "metric"}},
+			DoUpdates: clause.AssignmentColumns([]string{"refreshed_at"}),
+		}).Create(&aggregatePkg.Refresh{Metric: metric, RefreshedAt: refreshedAt}).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to rebuild aggregate " + metric + ": " + err.Error())
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormAggregateRepository) DeleteBefore(day time.Time) error {
+	if err := r.db.Where("day < ?", day).Delete(&aggregatePkg.Bucket{}).Error; err != nil {
+		r.logger.Error("Failed to delete expired aggregates: " + err.Error())
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormAggregateRepository) FindRefreshes() (map[string]time.Time, error) {
+	var refreshes []*aggregatePkg.Refresh
+
+	if err := r.db.Find(&refreshes).Error; err != nil {
+		r.logger.Error("Failed to find aggregate refreshes: " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	refreshedAt := make(map[string]time.Time, len(refreshes))
+	for _, refresh := range refreshes {
+		refreshedAt[refresh.Metric] = refresh.RefreshedAt
+	}
+	return refreshedAt, nil
+}
+
+func (r *gormAggregateRepository) FindSeries(metric, key string, since time.Time) ([]*aggregatePkg.Bucket, error) {
+	var buckets []*aggregatePkg.Bucket
+
+	err := r.db.Where("metric = ? AND key = ? AND day >= ?", metric, key, since).
+		Order("day ASC").
+		Find(&buckets).Error
+	if err != nil {
+		r.logger.Error("Failed to find aggregate " + metric + " for " + key + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return buckets, nil
+}
+
+func (r *gormAggregateRepository) FindTop(metric string, since time.Time, limit int) ([]*aggregatePkg.KeyTotal, error) {
+	var totals []*aggregatePkg.KeyTotal
+
+	err := r.db.Model(&aggregatePkg.Bucket{}).
+		Select("key, SUM(value) AS value").
+		Where("metric = ? AND key <> ? AND day >= ?", metric, aggregatePkg.TotalKey, since).
+		Group("key").
+		Order("value DESC, key ASC").
+		Limit(limit).
+		Scan(&totals).Error
+	if err != nil {
+		r.logger.Error("Failed to rank aggregate " + metric + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return totals, nil
+}
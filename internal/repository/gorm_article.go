@@ -8,8 +8,18 @@ import (
 	"github.com/dustin/articles-backend/pkg/logger"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// searchDocument is the tsvector expression covered by idx_articles_search.
+// Queries must use the exact same expression for the GIN index to apply.
+const searchDocument = `to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(description, '') || ' ' || coalesce(content, ''))`
+
+// MigrateSearchIndexes creates the GIN index backing full-text search
+func MigrateSearchIndexes(db *gorm.DB) error {
+	return db.Exec("CREATE INDEX IF NOT EXISTS idx_articles_search ON articles USING GIN (" + searchDocument + ")").Error
+}
+
 // gormArticleRepository implements the article.Repository interface with GORM optimizations
 type gormArticleRepository struct {
 	db     *gorm.DB
@@ -37,15 +47,80 @@ func (r *gormArticleRepository) Create(article *articlePkg.Article) error {
 	return nil
 }
 
+// bulkInsertRows keeps a multi-row insert of articles under the 65535
+// parameters Postgres accepts per statement
+const bulkInsertRows = 1500
+
+func (r *gormArticleRepository) CreateBatch(articles []*articlePkg.Article) ([]uuid.UUID, error) {
+	created := make([]uuid.UUID, 0, len(articles))
+
+	for start := 0; start < len(articles); start += bulkInsertRows {
+		end := start + bulkInsertRows
+		if end > len(articles) {
+			end = len(articles)
+		}
+		chunk := articles[start:end]
+
+		ids := make([]uuid.UUID, len(chunk))
+		for i, article := range chunk {
+			ids[i] = article.ID
+		}
+
+		// Links saved concurrently after the duplicate check are skipped via idx_user_url
+		err := r.db.Omit(clause.Associations).
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&chunk).Error
+		if err != nil {
+			r.logger.Error("Failed to create batch of " + fmt.Sprintf("%d", len(chunk)) + " articles: " + err.Error())
+			return nil, fmt.Errorf("failed to create articles: %w", err)
+		}
+
+		// The IDs were generated for this insert, so the ones found are the rows it created
+		var inserted []uuid.UUID
+		err = r.db.Unscoped().Model(&articlePkg.Article{}).
+			Where("id IN ?", ids).
+			Pluck("id", &inserted).Error
+		if err != nil {
+			r.logger.Error("Failed to look up created articles: " + err.Error())
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		created = append(created, inserted...)
+	}
+
+	return created, nil
+}
+
+func (r *gormArticleRepository) FindExistingURLs(userID uuid.UUID, urls []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(urls) == 0 {
+		return existing, nil
+	}
+
+	// Trashed articles still hold their URL in idx_user_url
+	var found []string
+	err := r.db.Unscoped().Model(&articlePkg.Article{}).
+		Where("user_id = ? AND url IN ?", userID, urls).
+		Pluck("url", &found).Error
+	if err != nil {
+		r.logger.Error("Failed to look up existing URLs for user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	for _, url := range found {
+		existing[url] = true
+	}
+	return existing, nil
+}
+
 func (r *gormArticleRepository) FindByID(id uuid.UUID) (*articlePkg.Article, error) {
 	var article articlePkg.Article
 
 	// Use primary key lookup for optimal performance
-	err := r.db.First(&article, id).Error
+	err := r.db.Preload("Tags").First(&article, id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			r.logger.Info("Article not found: " + id.String())
-			return nil, fmt.Errorf("article not found")
+			return nil, articlePkg.ErrNotFound
 		}
 
 		r.logger.Error("Database error finding article " + id.String() + ": " + err.Error())
@@ -55,11 +130,11 @@ func (r *gormArticleRepository) FindByID(id uuid.UUID) (*articlePkg.Article, err
 	return &article, nil
 }
 
-func (r *gormArticleRepository) FindByUserID(userID uuid.UUID, offset, limit int) ([]*articlePkg.Article, error) {
+func (r *gormArticleRepository) FindByUserID(userID uuid.UUID, filter *articlePkg.ArticleFilter, offset, limit int) ([]*articlePkg.Article, error) {
 	var articles []*articlePkg.Article
 
 	// Use index-optimized query with proper ordering
-	err := r.db.Where("user_id = ?", userID).
+	err := r.applyFilter(r.db.Where("user_id = ?", userID), userID, filter).
 		Order("created_at DESC").
 		Offset(offset).
 		Limit(limit).
@@ -75,12 +150,15 @@ func (r *gormArticleRepository) FindByUserID(userID uuid.UUID, offset, limit int
 	return articles, nil
 }
 
-func (r *gormArticleRepository) FindByUserIDWithRatings(userID uuid.UUID, offset, limit int) ([]*articlePkg.Article, error) {
+func (r *gormArticleRepository) FindByUserIDWithRatings(userID uuid.UUID, filter *articlePkg.ArticleFilter, offset, limit int) ([]*articlePkg.Article, error) {
 	var articles []*articlePkg.Article
 
-	// Use Preload for efficient rating loading
-	err := r.db.Preload("Ratings").
-		Where("user_id = ?", userID).
+	// Use Preload for efficient rating and tag loading
+	query := r.db.Preload("Ratings").
+		Preload("Tags").
+		Where("user_id = ?", userID)
+
+	err := r.applyFilter(query, userID, filter).
 		Order("created_at DESC").
 		Offset(offset).
 		Limit(limit).
@@ -96,11 +174,152 @@ func (r *gormArticleRepository) FindByUserIDWithRatings(userID uuid.UUID, offset
 	return articles, nil
 }
 
+func (r *gormArticleRepository) CountByUserID(userID uuid.UUID, filter *articlePkg.ArticleFilter) (int64, error) {
+	var total int64
+
+	err := r.applyFilter(r.db.Model(&articlePkg.Article{}).Where("user_id = ?", userID), userID, filter).
+		Count(&total).Error
+	if err != nil {
+		r.logger.Error("Database error counting articles by user " + userID.String() + ": " + err.Error())
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+
+	return total, nil
+}
+
+// urlHostPattern captures the host of a URL, without a leading "www."
+const urlHostPattern = `^[A-Za-z][A-Za-z0-9+.-]*://(?:www\.)?([^/:?#]+)`
+
+func (r *gormArticleRepository) CountTotals(userID uuid.UUID) (*articlePkg.ArticleTotals, error) {
+	var totals articlePkg.ArticleTotals
+
+	err := r.db.Model(&articlePkg.Article{}).
+		Select("COUNT(*) AS articles, COALESCE(SUM(word_count), 0) AS word_count").
+		Where("user_id = ?", userID).
+		Scan(&totals).Error
+	if err != nil {
+		r.logger.Error("Database error counting totals for user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &totals, nil
+}
+
+func (r *gormArticleRepository) CountByDomain(userID uuid.UUID, limit int) ([]*articlePkg.DomainCount, error) {
+	var counts []*articlePkg.DomainCount
+
+	// The pattern is bound as a parameter because its ? characters would be taken as placeholders
+	err := r.db.Model(&articlePkg.Article{}).
+		Select("COALESCE(LOWER(SUBSTRING(url FROM ?)), '') AS domain, COUNT(*) AS count", urlHostPattern).
+		Where("user_id = ?", userID).
+		Group("domain").
+		Order("count DESC, domain ASC").
+		Limit(limit).
+		Scan(&counts).Error
+	if err != nil {
+		r.logger.Error("Database error counting domains for user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (r *gormArticleRepository) CountByMetadataStatus(userID uuid.UUID) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+
+	err := r.db.Model(&articlePkg.Article{}).
+		Select("metadata_status AS status, COUNT(*) AS count").
+		Where("user_id = ?", userID).
+		Group("metadata_status").
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("Database error counting metadata statuses for user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *gormArticleRepository) CountRatingsByScore(userID uuid.UUID) (map[int]int64, error) {
+	var rows []struct {
+		Score int
+		Count int64
+	}
+
+	err := r.db.Table("ratings").
+		Select("ratings.score AS score, COUNT(*) AS count").
+		Joins("JOIN articles ON articles.id = ratings.article_id").
+		Where("articles.user_id = ? AND articles.deleted_at IS NULL", userID).
+		Group("ratings.score").
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("Database error counting ratings for user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	counts := make(map[int]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Score] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *gormArticleRepository) FindByUserIDAfter(userID uuid.UUID, filter *articlePkg.ArticleFilter, after *articlePkg.Cursor, limit int) ([]*articlePkg.Article, error) {
+	var articles []*articlePkg.Article
+
+	query := r.db.Preload("Ratings").
+		Preload("Tags").
+		Where("user_id = ?", userID)
+
+	// Keyset pagination: seek past the cursor instead of skipping rows
+	if after != nil {
+		query = query.Where("(created_at, id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	err := r.applyFilter(query, userID, filter).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&articles).Error
+
+	if err != nil {
+		r.logger.Error("Database error finding articles by cursor for user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return articles, nil
+}
+
+func (r *gormArticleRepository) StreamByUserID(userID uuid.UUID, batchSize int, fn func(batch []*articlePkg.Article) error) error {
+	var batch []*articlePkg.Article
+
+	// FindInBatches pages through the primary key so only one batch is held in memory
+	result := r.db.Preload("Ratings").
+		Preload("Tags").
+		Where("user_id = ?", userID).
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		})
+
+	if err := result.Error; err != nil {
+		r.logger.Error("Database error streaming articles for user " + userID.String() + ": " + err.Error())
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
 func (r *gormArticleRepository) Update(article *articlePkg.Article) error {
 	r.logger.Info("Updating article " + article.ID.String() + " for user " + article.UserID.String())
 
-	// Use Save() for updates with GORM optimizations
-	if err := r.db.Save(article).Error; err != nil {
+	// Use Save() for updates with GORM optimizations; associations are managed separately
+	if err := r.db.Omit(clause.Associations).Save(article).Error; err != nil {
 		r.logger.Error("Failed to update article " + article.ID.String() + " for user " + article.UserID.String() + ": " + err.Error())
 		return fmt.Errorf("failed to update article: %w", err)
 	}
@@ -110,10 +329,30 @@ func (r *gormArticleRepository) Update(article *articlePkg.Article) error {
 	return nil
 }
 
+func (r *gormArticleRepository) UpdateFields(id uuid.UUID, fields map[string]any) error {
+	r.logger.Info("Updating fields of article " + id.String())
+
+	// Update only the supplied columns to avoid overwriting concurrent metadata writes
+	result := r.db.Model(&articlePkg.Article{}).Where("id = ?", id).Updates(fields)
+	if err := result.Error; err != nil {
+		r.logger.Error("Failed to update fields of article " + id.String() + ": " + err.Error())
+		return fmt.Errorf("failed to update article: %w", err)
+	}
+
+	if result.RowsAffected == 0 {
+		r.logger.Warn("No article found to update: " + id.String())
+		return articlePkg.ErrNotFound
+	}
+
+	r.logger.Info("Article fields updated successfully: " + id.String())
+
+	return nil
+}
+
 func (r *gormArticleRepository) Delete(id uuid.UUID) error {
 	r.logger.Info("Deleting article: " + id.String())
 
-	// Soft delete with GORM
+	// Soft delete with GORM: the article moves to the trash until purged
 	result := r.db.Delete(&articlePkg.Article{}, id)
 	if err := result.Error; err != nil {
 		r.logger.Error("Failed to delete article " + id.String() + ": " + err.Error())
@@ -122,7 +361,7 @@ func (r *gormArticleRepository) Delete(id uuid.UUID) error {
 
 	if result.RowsAffected == 0 {
 		r.logger.Warn("No article found to delete: " + id.String())
-		return fmt.Errorf("article not found")
+		return articlePkg.ErrNotFound
 	}
 
 	r.logger.Info("Article deleted successfully: " + id.String())
@@ -130,6 +369,343 @@ func (r *gormArticleRepository) Delete(id uuid.UUID) error {
 	return nil
 }
 
+func (r *gormArticleRepository) DeleteBatch(userID uuid.UUID, ids []uuid.UUID, status string) ([]uuid.UUID, error) {
+	var deleted []uuid.UUID
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the matching rows so the IDs reported are exactly the ones deleted
+		query := tx.Model(&articlePkg.Article{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID)
+		if len(ids) > 0 {
+			query = query.Where("id IN ?", ids)
+		}
+		if status != "" {
+			query = query.Where("metadata_status = ?", status)
+		}
+		if err := query.Pluck("id", &deleted).Error; err != nil {
+			return err
+		}
+
+		if len(deleted) == 0 {
+			return nil
+		}
+		return tx.Delete(&articlePkg.Article{}, "id IN ?", deleted).Error
+	})
+
+	if err != nil {
+		r.logger.Error("Failed to delete batch of articles for user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("failed to delete articles: %w", err)
+	}
+
+	r.logger.Info("Deleted " + fmt.Sprintf("%d", len(deleted)) + " articles for user " + userID.String())
+
+	return deleted, nil
+}
+
+func (r *gormArticleRepository) FindTrashedByID(id uuid.UUID) (*articlePkg.Article, error) {
+	var article articlePkg.Article
+
+	err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&article, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, articlePkg.ErrNotFound
+		}
+
+		r.logger.Error("Database error finding trashed article " + id.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &article, nil
+}
+
+func (r *gormArticleRepository) FindTrashedByUserID(userID uuid.UUID, offset, limit int) ([]*articlePkg.Article, error) {
+	var articles []*articlePkg.Article
+
+	// Most recently deleted first
+	err := r.db.Unscoped().
+		Preload("Tags").
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&articles).Error
+	if err != nil {
+		r.logger.Error("Database error finding trashed articles by user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return articles, nil
+}
+
+func (r *gormArticleRepository) CountTrashedByUserID(userID uuid.UUID) (int64, error) {
+	var total int64
+
+	err := r.db.Unscoped().Model(&articlePkg.Article{}).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Count(&total).Error
+	if err != nil {
+		r.logger.Error("Database error counting trashed articles by user " + userID.String() + ": " + err.Error())
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+
+	return total, nil
+}
+
+func (r *gormArticleRepository) FindTrashedBefore(before time.Time, limit int) ([]*articlePkg.Article, error) {
+	var articles []*articlePkg.Article
+
+	err := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Order("deleted_at ASC").
+		Limit(limit).
+		Find(&articles).Error
+	if err != nil {
+		r.logger.Error("Database error finding articles trashed before " + before.Format(time.RFC3339) + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return articles, nil
+}
+
+func (r *gormArticleRepository) Restore(id uuid.UUID) error {
+	r.logger.Info("Restoring article: " + id.String())
+
+	result := r.db.Unscoped().Model(&articlePkg.Article{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if err := result.Error; err != nil {
+		r.logger.Error("Failed to restore article " + id.String() + ": " + err.Error())
+		return fmt.Errorf("failed to restore article: %w", err)
+	}
+
+	if result.RowsAffected == 0 {
+		return articlePkg.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *gormArticleRepository) Purge(id uuid.UUID) error {
+	// Ratings, tags, highlights and collection memberships go with it through ON DELETE CASCADE
+	result := r.db.Unscoped().Delete(&articlePkg.Article{}, "id = ? AND deleted_at IS NOT NULL", id)
+	if err := result.Error; err != nil {
+		r.logger.Error("Failed to purge article " + id.String() + ": " + err.Error())
+		return fmt.Errorf("failed to purge article: %w", err)
+	}
+
+	if result.RowsAffected == 0 {
+		return articlePkg.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *gormArticleRepository) Search(userID uuid.UUID, query string, offset, limit int) ([]*articlePkg.SearchResult, int64, error) {
+	type rankedArticle struct {
+		articlePkg.Article
+		Rank float64
+	}
+
+	tsQuery := "websearch_to_tsquery('simple', ?)"
+	base := r.db.Model(&articlePkg.Article{}).
+		Where("user_id = ?", userID).
+		Where(searchDocument+" @@ "+tsQuery, query)
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		r.logger.Error("Database error counting search results for user " + userID.String() + ": " + err.Error())
+		return nil, 0, fmt.Errorf("database error: %w", err)
+	}
+
+	var rows []*rankedArticle
+	err := base.Session(&gorm.Session{}).
+		Select("articles.*, ts_rank("+searchDocument+", "+tsQuery+") AS rank", query).
+		Order("rank DESC, created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Scan(&rows).Error
+
+	if err != nil {
+		r.logger.Error("Database error searching articles for user " + userID.String() + ": " + err.Error())
+		return nil, 0, fmt.Errorf("database error: %w", err)
+	}
+
+	results := make([]*articlePkg.SearchResult, len(rows))
+	for i, row := range rows {
+		article := row.Article
+		results[i] = &articlePkg.SearchResult{Article: &article, Rank: row.Rank}
+	}
+
+	r.logger.Info("Found " + fmt.Sprintf("%d", len(results)) + " of " + fmt.Sprintf("%d", total) + " search results for user " + userID.String())
+
+	return results, total, nil
+}
+
+func (r *gormArticleRepository) AddTags(articleID, userID uuid.UUID, names []string) error {
+	r.logger.Info("Adding " + fmt.Sprintf("%d", len(names)) + " tags to article " + articleID.String())
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		tags := make([]articlePkg.Tag, 0, len(names))
+		for _, name := range names {
+			tag := articlePkg.Tag{UserID: userID, Name: name}
+			if err := tx.Where("user_id = ? AND name = ?", userID, name).FirstOrCreate(&tag).Error; err != nil {
+				return err
+			}
+			tags = append(tags, tag)
+		}
+
+		return tx.Model(&articlePkg.Article{ID: articleID}).Association("Tags").Append(tags)
+	})
+
+	if err != nil {
+		r.logger.Error("Failed to add tags to article " + articleID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to add tags: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormArticleRepository) RemoveTag(articleID, userID uuid.UUID, name string) error {
+	r.logger.Info("Removing tag " + name + " from article " + articleID.String())
+
+	// Delete the join row only; the tag itself stays available for other articles
+	result := r.db.Exec(`
+		DELETE FROM article_tags
+		WHERE article_id = ? AND tag_id IN (SELECT id FROM tags WHERE user_id = ? AND name = ?)
+	`, articleID, userID, name)
+	if err := result.Error; err != nil {
+		r.logger.Error("Failed to remove tag " + name + " from article " + articleID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to remove tag: %w", err)
+	}
+
+	if result.RowsAffected == 0 {
+		return articlePkg.ErrTagNotFound
+	}
+
+	return nil
+}
+
+func (r *gormArticleRepository) FindTagsByUserID(userID uuid.UUID) ([]*articlePkg.Tag, error) {
+	var tags []*articlePkg.Tag
+
+	err := r.db.Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&tags).Error
+
+	if err != nil {
+		r.logger.Error("Database error finding tags by user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return tags, nil
+}
+
+func (r *gormArticleRepository) CreateHighlight(highlight *articlePkg.Highlight) error {
+	if err := r.db.Omit(clause.Associations).Create(highlight).Error; err != nil {
+		r.logger.Error("Failed to create highlight on article " + highlight.ArticleID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to create highlight: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormArticleRepository) FindHighlights(articleID uuid.UUID) ([]*articlePkg.Highlight, error) {
+	var highlights []*articlePkg.Highlight
+
+	// Reading order, so clients can render them alongside the content
+	err := r.db.Where("article_id = ?", articleID).
+		Order("start_offset ASC, created_at ASC").
+		Find(&highlights).Error
+
+	if err != nil {
+		r.logger.Error("Database error finding highlights of article " + articleID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return highlights, nil
+}
+
+func (r *gormArticleRepository) FindHighlightByID(id uuid.UUID) (*articlePkg.Highlight, error) {
+	var highlight articlePkg.Highlight
+
+	err := r.db.First(&highlight, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, articlePkg.ErrHighlightNotFound
+		}
+
+		r.logger.Error("Database error finding highlight " + id.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &highlight, nil
+}
+
+func (r *gormArticleRepository) UpdateHighlight(highlight *articlePkg.Highlight) error {
+	err := r.db.Model(&articlePkg.Highlight{ID: highlight.ID}).Updates(map[string]any{
+		"note":       highlight.Note,
+		"updated_at": highlight.UpdatedAt,
+	}).Error
+	if err != nil {
+		r.logger.Error("Failed to update highlight " + highlight.ID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to update highlight: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormArticleRepository) DeleteHighlight(id uuid.UUID) error {
+	result := r.db.Delete(&articlePkg.Highlight{}, "id = ?", id)
+	if err := result.Error; err != nil {
+		r.logger.Error("Failed to delete highlight " + id.String() + ": " + err.Error())
+		return fmt.Errorf("failed to delete highlight: %w", err)
+	}
+
+	if result.RowsAffected == 0 {
+		return articlePkg.ErrHighlightNotFound
+	}
+
+	return nil
+}
+
+// applyFilter narrows an article query by the optional listing filter
+func (r *gormArticleRepository) applyFilter(query *gorm.DB, userID uuid.UUID, filter *articlePkg.ArticleFilter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+
+	if len(filter.Tags) > 0 {
+		// Articles must carry every requested tag
+		tagged := r.db.Table("article_tags").
+			Select("article_tags.article_id").
+			Joins("JOIN tags ON tags.id = article_tags.tag_id").
+			Where("tags.user_id = ? AND tags.name IN ?", userID, filter.Tags).
+			Group("article_tags.article_id").
+			Having("COUNT(DISTINCT tags.name) = ?", len(filter.Tags))
+		query = query.Where("id IN (?)", tagged)
+	}
+
+	if filter.MaxReadingTime > 0 {
+		// A zero reading time means the length is unknown, not that the article is short
+		query = query.Where("reading_time_minutes BETWEEN 1 AND ?", filter.MaxReadingTime)
+	}
+
+	if filter.Language != "" {
+		query = query.Where("language = ?", filter.Language)
+	}
+
+	if filter.Unread {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if filter.Favorites {
+		query = query.Where("favorited_at IS NOT NULL")
+	}
+
+	return query
+}
+
 func (r *gormArticleRepository) FindFailedMetadata(maxRetries int) ([]*articlePkg.Article, error) {
 	var articles []*articlePkg.Article
 
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	auditPkg "github.com/dustin/articles-backend/internal/audit"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// gormAuditRepository implements the audit.Repository interface
+type gormAuditRepository struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewGORMAuditRepository creates a new GORM-based audit repository
+func NewGORMAuditRepository(db *gorm.DB, log *logger.Logger) auditPkg.Repository {
+	return &gormAuditRepository{
+		db:     db,
+		logger: log.WithComponent("gorm-audit-repository"),
+	}
+}
+
+func (r *gormAuditRepository) Create(event *auditPkg.Event) error {
+	if err := r.db.Create(event).Error; err != nil {
+		r.logger.Error("Failed to create audit event " + event.Type + ": " + err.Error())
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormAuditRepository) List(filter *auditPkg.Filter, after *utils.Cursor, limit int) ([]*auditPkg.Event, error) {
+	query := r.db.Model(&auditPkg.Event{})
+
+	if filter != nil {
+		if filter.Type != "" {
+			query = query.Where("audit_logs.type = ?", filter.Type)
+		}
+		if filter.UserID != nil {
+			query = query.Where("audit_logs.user_id = ?", *filter.UserID)
+		}
+		if filter.Email != "" {
+			query = query.Where("audit_logs.email = ?", strings.ToLower(filter.Email))
+		}
+		if filter.IPAddress != "" {
+			query = query.Where("audit_logs.ip_address = ?", filter.IPAddress)
+		}
+		if filter.After != nil {
+			query = query.Where("audit_logs.created_at >= ?", *filter.After)
+		}
+		if filter.Before != nil {
+			query = query.Where("audit_logs.created_at < ?", *filter.Before)
+		}
+	}
+	query = pageAfter(query, "audit_logs", after, limit)
+
+	var events []*auditPkg.Event
+	if err := query.Find(&events).Error; err != nil {
+		r.logger.Error("Database error listing audit events: " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r *gormAuditRepository) DeleteBefore(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&auditPkg.Event{})
+	if result.Error != nil {
+		r.logger.Error("Failed to delete audit events: " + result.Error.Error())
+		return 0, fmt.Errorf("database error: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"fmt"
+
+	backfillPkg "github.com/dustin/articles-backend/internal/backfill"
+	"github.com/dustin/articles-backend/internal/embedding"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// gormBackfillRepository implements the backfill.Repository interface
+type gormBackfillRepository struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewGORMBackfillRepository creates a new GORM-based backfill repository
+func NewGORMBackfillRepository(db *gorm.DB, log *logger.Logger) backfillPkg.Repository {
+	return &gormBackfillRepository{
+		db:     db,
+		logger: log.WithComponent("gorm-backfill-repository"),
+	}
+}
+
+// pending selects articles outside the trash missing a title embedding, or a
+// content embedding while they have content
+func (r *gormBackfillRepository) pending(statuses []string) *gorm.DB {
+	return r.db.Model(&backfillPkg.Article{}).
+		Where("deleted_at IS NULL").
+		Where("embedding_status IN ? OR (content_embedding_status IN ? AND (COALESCE(content, '') <> '' OR COALESCE(content_key, '') <> ''))", statuses, statuses)
+}
+
+func (r *gormBackfillRepository) CountPending(statuses []string) (int64, error) {
+	var count int64
+	if err := r.pending(statuses).Count(&count).Error; err != nil {
+		r.logger.Error("Database error counting articles to backfill: " + err.Error())
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	return count, nil
+}
+
+func (r *gormBackfillRepository) FindPending(statuses []string, after uuid.UUID, limit int) ([]*backfillPkg.Article, error) {
+	var articles []*backfillPkg.Article
+	err := r.pending(statuses).
+		Where("id > ?", after).
+		Order("id ASC").
+		Limit(limit).
+		Find(&articles).Error
+	if err != nil {
+		r.logger.Error("Database error finding articles to backfill: " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return articles, nil
+}
+
+func (r *gormBackfillRepository) MarkFailed(articleID uuid.UUID, target string) error {
+	column := "embedding_status"
+	if target == embedding.TargetContent {
+		column = "content_embedding_status"
+	}
+
+	err := r.db.Model(&backfillPkg.Article{}).
+		Where("id = ?", articleID).
+		Update(column, backfillPkg.StatusFailed).Error
+	if err != nil {
+		r.logger.Error("Database error marking embedding of article " + articleID.String() + " failed: " + err.Error())
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"fmt"
+
+	collectionPkg "github.com/dustin/articles-backend/internal/collection"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// gormCollectionRepository implements the collection.Repository interface
+type gormCollectionRepository struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewGORMCollectionRepository creates a new GORM-based collection repository
+func NewGORMCollectionRepository(db *gorm.DB, log *logger.Logger) collectionPkg.Repository {
+	return &gormCollectionRepository{
+		db:     db,
+		logger: log.WithComponent("gorm-collection-repository"),
+	}
+}
+
+// collectionWithCount selects collections along with the number of articles in each, not counting trashed ones
+const collectionWithCount = "collections.*, (SELECT COUNT(*) FROM collection_articles JOIN articles ON articles.id = collection_articles.article_id WHERE collection_articles.collection_id = collections.id AND articles.deleted_at IS NULL) AS article_count"
+
+func (r *gormCollectionRepository) Create(collection *collectionPkg.Collection) error {
+	if err := r.db.Omit(clause.Associations).Create(collection).Error; err != nil {
+		r.logger.Error("Failed to create collection " + collection.ID.String() + " for user " + collection.UserID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to create collection: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormCollectionRepository) FindByID(id uuid.UUID) (*collectionPkg.Collection, error) {
+	var collection collectionPkg.Collection
+
+	err := r.db.Select(collectionWithCount).Where("collections.id = ?", id).First(&collection).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, collectionPkg.ErrNotFound
+		}
+
+		r.logger.Error("Database error finding collection " + id.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &collection, nil
+}
+
+func (r *gormCollectionRepository) FindByName(userID uuid.UUID, name string) (*collectionPkg.Collection, error) {
+	var collection collectionPkg.Collection
+
+	err := r.db.Where("user_id = ? AND name = ?", userID, name).First(&collection).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, collectionPkg.ErrNotFound
+		}
+
+		r.logger.Error("Database error finding collection " + name + " for user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &collection, nil
+}
+
+func (r *gormCollectionRepository) FindByUserID(userID uuid.UUID) ([]*collectionPkg.Collection, error) {
+	var collections []*collectionPkg.Collection
+
+	err := r.db.Select(collectionWithCount).
+		Where("collections.user_id = ?", userID).
+		Order("collections.name ASC").
+		Find(&collections).Error
+	if err != nil {
+		r.logger.Error("Failed to list collections for user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return collections, nil
+}
+
+func (r *gormCollectionRepository) Update(collection *collectionPkg.Collection) error {
+	err := r.db.Model(&collectionPkg.Collection{ID: collection.ID}).Updates(map[string]any{
+		"name":        collection.Name,
+		"description": collection.Description,
+		"updated_at":  collection.UpdatedAt,
+	}).Error
+	if err != nil {
+		r.logger.Error("Failed to update collection " + collection.ID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to update collection: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormCollectionRepository) Delete(id uuid.UUID) error {
+	result := r.db.Delete(&collectionPkg.Collection{}, "id = ?", id)
+	if err := result.Error; err != nil {
+		r.logger.Error("Failed to delete collection " + id.String() + ": " + err.Error())
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+
+	if result.RowsAffected == 0 {
+		return collectionPkg.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *gormCollectionRepository) AddArticle(collectionID, articleID uuid.UUID) error {
+	// Adding an article twice is a no-op
+	err := r.db.Omit(clause.Associations).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&collectionPkg.Membership{CollectionID: collectionID, ArticleID: articleID}).Error
+	if err != nil {
+		r.logger.Error("Failed to add article " + articleID.String() + " to collection " + collectionID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to add article to collection: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormCollectionRepository) RemoveArticle(collectionID, articleID uuid.UUID) error {
+	result := r.db.Delete(&collectionPkg.Membership{}, "collection_id = ? AND article_id = ?", collectionID, articleID)
+	if err := result.Error; err != nil {
+		r.logger.Error("Failed to remove article " + articleID.String() + " from collection " + collectionID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to remove article from collection: %w", err)
+	}
+
+	if result.RowsAffected == 0 {
+		return collectionPkg.ErrArticleNotInCollection
+	}
+
+	return nil
+}
+
+func (r *gormCollectionRepository) FindArticles(collectionID uuid.UUID, offset, limit int) ([]*collectionPkg.Article, int64, error) {
+	query := r.db.Model(&collectionPkg.Article{}).
+		Joins("JOIN collection_articles ON collection_articles.article_id = articles.id").
+		Where("collection_articles.collection_id = ? AND articles.deleted_at IS NULL", collectionID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("Failed to count articles of collection " + collectionID.String() + ": " + err.Error())
+		return nil, 0, fmt.Errorf("database error: %w", err)
+	}
+
+	// Most recently collected first
+	var articles []*collectionPkg.Article
+	err := query.Select("articles.*, collection_articles.added_at").
+		Order("collection_articles.added_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&articles).Error
+	if err != nil {
+		r.logger.Error("Failed to list articles of collection " + collectionID.String() + ": " + err.Error())
+		return nil, 0, fmt.Errorf("database error: %w", err)
+	}
+
+	return articles, total, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/dustin/articles-backend/internal/embedding"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// gormEmbeddingRepository implements the embedding.VectorStore interface
+type gormEmbeddingRepository struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewGORMEmbeddingRepository creates a new GORM-based store for embeddings
+// computed by an embedding provider
+func NewGORMEmbeddingRepository(db *gorm.DB, log *logger.Logger) embedding.VectorStore {
+	return &gormEmbeddingRepository{
+		db:     db,
+		logger: log.WithComponent("gorm-embedding-repository"),
+	}
+}
+
+// columns returns the embedding and status columns of the target
+func (r *gormEmbeddingRepository) columns(target string) (string, string) {
+	if target == embedding.TargetContent {
+		return "content_embedding", "content_embedding_status"
+	}
+	return "embedding", "embedding_status"
+}
+
+func (r *gormEmbeddingRepository) StoreEmbedding(articleID uuid.UUID, target string, vector []float64) error {
+	column, statusColumn := r.columns(target)
+
+	result := r.db.Exec("UPDATE articles SET "+column+" = ?::vector, "+statusColumn+" = 'success' WHERE id = ?",
+		formatPostgresVector(vector), articleID)
+	if result.Error != nil {
+		r.logger.Error("Database error storing embedding of article " + articleID.String() + ": " + result.Error.Error())
+		return fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return embedding.ErrArticleNotFound
+	}
+	return nil
+}
+
+func (r *gormEmbeddingRepository) LoadEmbedding(articleID uuid.UUID, target string) ([]float64, error) {
+	column, _ := r.columns(target)
+
+	// Read the vector as text, as there is no pgvector decoder
+	var values []sql.NullString
+	err := r.db.Table("articles").
+		Where("id = ?", articleID).
+		Pluck(column+"::text", &values).Error
+	if err != nil {
+		r.logger.Error("Database error loading embedding of article " + articleID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if len(values) == 0 {
+		return nil, embedding.ErrArticleNotFound
+	}
+	if !values[0].Valid {
+		return nil, embedding.ErrNoEmbedding
+	}
+	return parsePostgresVector(values[0].String)
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/embedding"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// articlesConn answers the embedding queries for a fixed set of articles,
+// mapping IDs to their vector as text, nil for articles without one
+type articlesConn struct {
+	vectors map[string]*string
+}
+
+func (c *articlesConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+func (c *articlesConn) Close() error { return nil }
+func (c *articlesConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+// ExecContext updates the article whose ID is the last argument
+func (c *articlesConn) ExecContext(_ context.Context, _ string, args []driver.NamedValue) (driver.Result, error) {
+	if _, ok := c.vectors[args[len(args)-1].Value.(string)]; !ok {
+		return driver.RowsAffected(0), nil
+	}
+	return driver.RowsAffected(1), nil
+}
+
+// QueryContext selects the vector of the article whose ID is the argument
+func (c *articlesConn) QueryContext(_ context.Context, _ string, args []driver.NamedValue) (driver.Rows, error) {
+	rows := &vectorRows{}
+	if vector, ok := c.vectors[args[0].Value.(string)]; ok {
+		rows.values = []*string{vector}
+	}
+	return rows, nil
+}
+
+// vectorRows is a single text column
+type vectorRows struct {
+	values []*string
+}
+
+func (r *vectorRows) Columns() []string { return []string{"embedding"} }
+func (r *vectorRows) Close() error      { return nil }
+
+func (r *vectorRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	if r.values[0] != nil {
+		dest[0] = *r.values[0]
+	} else {
+		dest[0] = nil
+	}
+	r.values = r.values[1:]
+	return nil
+}
+
+// articlesConnector opens the same connection every time
+type articlesConnector struct {
+	conn *articlesConn
+}
+
+func (c articlesConnector) Connect(context.Context) (driver.Conn, error) { return c.conn, nil }
+func (c articlesConnector) Driver() driver.Driver                        { return nil }
+
+func TestGORMEmbeddingRepository(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	stored, withEmbedding, withoutEmbedding := "[0.5,1,2]", uuid.New(), uuid.New()
+	conn := &articlesConn{vectors: map[string]*string{withEmbedding.String(): &stored, withoutEmbedding.String(): nil}}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(articlesConnector{conn})}), &gorm.Config{})
+	require.NoError(t, err)
+	repo := NewGORMEmbeddingRepository(db, log)
+
+	t.Run("Store", func(t *testing.T) {
+		assert.NoError(t, repo.StoreEmbedding(withEmbedding, embedding.TargetTitle, []float64{1, 2}))
+
+		err := repo.StoreEmbedding(uuid.New(), embedding.TargetTitle, []float64{1, 2})
+		assert.ErrorIs(t, err, embedding.ErrArticleNotFound)
+		assert.ErrorIs(t, err, utils.ErrNotFound)
+	})
+
+	t.Run("Load", func(t *testing.T) {
+		vector, err := repo.LoadEmbedding(withEmbedding, embedding.TargetContent)
+		require.NoError(t, err)
+		assert.Equal(t, []float64{0.5, 1, 2}, vector)
+
+		_, err = repo.LoadEmbedding(withoutEmbedding, embedding.TargetTitle)
+		assert.ErrorIs(t, err, embedding.ErrNoEmbedding)
+		assert.ErrorIs(t, err, utils.ErrNotFound)
+
+		_, err = repo.LoadEmbedding(uuid.New(), embedding.TargetTitle)
+		assert.ErrorIs(t, err, embedding.ErrArticleNotFound)
+	})
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	exportPkg "github.com/dustin/articles-backend/internal/export"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// gormExportRepository implements the export.Repository interface
+type gormExportRepository struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewGORMExportRepository creates a new GORM-based export repository
+func NewGORMExportRepository(db *gorm.DB, log *logger.Logger) exportPkg.Repository {
+	return &gormExportRepository{
+		db:     db,
+		logger: log.WithComponent("gorm-export-repository"),
+	}
+}
+
+func (r *gormExportRepository) CreateJob(job *exportPkg.Job) error {
+	if err := r.db.Create(job).Error; err != nil {
+		r.logger.Error("Failed to create export job " + job.ID.String() + " for user " + job.UserID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to create export job: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormExportRepository) UpdateJob(job *exportPkg.Job) error {
+	if err := r.db.Save(job).Error; err != nil {
+		r.logger.Error("Failed to update export job " + job.ID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to update export job: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormExportRepository) FindLatestJob(userID uuid.UUID) (*exportPkg.Job, error) {
+	var job exportPkg.Job
+
+	err := r.db.Omit("archive").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		First(&job).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, exportPkg.ErrJobNotFound
+		}
+
+		r.logger.Error("Failed to find export job of user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &job, nil
+}
+
+func (r *gormExportRepository) FindArchive(jobID uuid.UUID) ([]byte, error) {
+	var archives [][]byte
+
+	err := r.db.Model(&exportPkg.Job{}).Where("id = ?", jobID).Pluck("archive", &archives).Error
+	if err != nil {
+		r.logger.Error("Failed to read archive of export job " + jobID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if len(archives) == 0 || archives[0] == nil {
+		return nil, exportPkg.ErrArchiveNotReady
+	}
+
+	return archives[0], nil
+}
+
+func (r *gormExportRepository) DeleteJobs(userID uuid.UUID, keep uuid.UUID) error {
+	if err := r.db.Where("user_id = ? AND id <> ?", userID, keep).Delete(&exportPkg.Job{}).Error; err != nil {
+		r.logger.Error("Failed to delete export jobs of user " + userID.String() + ": " + err.Error())
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormExportRepository) DeleteExpiredJobs(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&exportPkg.Job{})
+	if result.Error != nil {
+		r.logger.Error("Failed to delete expired export jobs: " + result.Error.Error())
+		return 0, fmt.Errorf("database error: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (r *gormExportRepository) FindProfile(userID uuid.UUID) (*exportPkg.Profile, error) {
+	var profile exportPkg.Profile
+
+	result := r.db.Table("users").
+		Select("id, email, created_at, updated_at").
+		Where("id = ?", userID).
+		Limit(1).
+		Scan(&profile)
+	if result.Error != nil {
+		r.logger.Error("Failed to read profile of user " + userID.String() + ": " + result.Error.Error())
+		return nil, fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("user %s not found", userID)
+	}
+
+	return &profile, nil
+}
+
+func (r *gormExportRepository) FindArticles(userID uuid.UUID) ([]*exportPkg.Article, error) {
+	var articles []*exportPkg.Article
+
+	// Articles in the trash are the user's data too
+	err := r.db.Table("articles").
+		Select("id, url, title, description, content, content_key, image_url, language, created_at, updated_at, deleted_at").
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Scan(&articles).Error
+	if err != nil {
+		r.logger.Error("Failed to read articles of user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	var tags []struct {
+		ArticleID uuid.UUID
+		Name      string
+	}
+	err = r.db.Table("article_tags").
+		Select("article_tags.article_id, tags.name").
+		Joins("JOIN tags ON tags.id = article_tags.tag_id").
+		Where("tags.user_id = ?", userID).
+		Order("tags.name").
+		Scan(&tags).Error
+	if err != nil {
+		r.logger.Error("Failed to read tags of user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	byID := make(map[uuid.UUID]*exportPkg.Article, len(articles))
+	for _, article := range articles {
+		article.Tags = []string{}
+		byID[article.ID] = article
+	}
+	for _, tag := range tags {
+		if article, ok := byID[tag.ArticleID]; ok {
+			article.Tags = append(article.Tags, tag.Name)
+		}
+	}
+
+	return articles, nil
+}
+
+func (r *gormExportRepository) FindRatings(userID uuid.UUID) ([]*exportPkg.Rating, error) {
+	var ratings []*exportPkg.Rating
+
+	err := r.db.Table("ratings").
+		Select("article_id, score, created_at, updated_at").
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Scan(&ratings).Error
+	if err != nil {
+		r.logger.Error("Failed to read ratings of user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return ratings, nil
+}
+
+func (r *gormExportRepository) FindEvents(userID uuid.UUID) ([]*exportPkg.Event, error) {
+	var events []*exportPkg.Event
+
+	err := r.db.Table("recommendation_events").
+		Select("article_id, engine, type, position, created_at").
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Scan(&events).Error
+	if err != nil {
+		r.logger.Error("Failed to read recommendation events of user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return events, nil
+}
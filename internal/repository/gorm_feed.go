@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	feedPkg "github.com/dustin/articles-backend/internal/feed"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// gormFeedRepository implements the feed.Repository interface
+type gormFeedRepository struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewGORMFeedRepository creates a new GORM-based feed repository
+func NewGORMFeedRepository(db *gorm.DB, log *logger.Logger) feedPkg.Repository {
+	return &gormFeedRepository{
+		db:     db,
+		logger: log.WithComponent("gorm-feed-repository"),
+	}
+}
+
+func (r *gormFeedRepository) Create(feed *feedPkg.Feed) error {
+	if err := r.db.Omit(clause.Associations).Create(feed).Error; err != nil {
+		r.logger.Error("Failed to create feed " + feed.ID.String() + " for user " + feed.UserID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to create feed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormFeedRepository) FindByID(id uuid.UUID) (*feedPkg.Feed, error) {
+	var feed feedPkg.Feed
+
+	err := r.db.Where("id = ?", id).First(&feed).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, feedPkg.ErrNotFound
+		}
+
+		r.logger.Error("Database error finding feed " + id.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &feed, nil
+}
+
+func (r *gormFeedRepository) FindByURL(userID uuid.UUID, url string) (*feedPkg.Feed, error) {
+	var feed feedPkg.Feed
+
+	err := r.db.Where("user_id = ? AND url = ?", userID, url).First(&feed).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, feedPkg.ErrNotFound
+		}
+
+		r.logger.Error("Database error finding feed " + url + " for user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &feed, nil
+}
+
+func (r *gormFeedRepository) FindByUserID(userID uuid.UUID) ([]*feedPkg.Feed, error) {
+	var feeds []*feedPkg.Feed
+
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&feeds).Error
+	if err != nil {
+		r.logger.Error("Failed to list feeds for user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return feeds, nil
+}
+
+func (r *gormFeedRepository) CountByUserID(userID uuid.UUID) (int64, error) {
+	var count int64
+
+	err := r.db.Model(&feedPkg.Feed{}).Where("user_id = ?", userID).Count(&count).Error
+	if err != nil {
+		r.logger.Error("Failed to count feeds for user " + userID.String() + ": " + err.Error())
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *gormFeedRepository) FindDue(polledBefore time.Time, limit int) ([]*feedPkg.Feed, error) {
+	var feeds []*feedPkg.Feed
+
+	err := r.db.Where("last_polled_at IS NULL OR last_polled_at < ?", polledBefore).
+		Order("last_polled_at ASC NULLS FIRST").
+		Limit(limit).
+		Find(&feeds).Error
+	if err != nil {
+		r.logger.Error("Failed to find due feeds: " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return feeds, nil
+}
+
+func (r *gormFeedRepository) Update(feed *feedPkg.Feed) error {
+	err := r.db.Model(&feedPkg.Feed{ID: feed.ID}).Updates(map[string]any{
+		"title":          feed.Title,
+		"site_url":       feed.SiteURL,
+		"etag":           feed.ETag,
+		"last_modified":  feed.LastModified,
+		"last_polled_at": feed.LastPolledAt,
+		"last_entry_at":  feed.LastEntryAt,
+		"last_error":     feed.LastError,
+		"error_count":    feed.ErrorCount,
+		"articles_added": feed.ArticlesAdded,
+		"updated_at":     feed.UpdatedAt,
+	}).Error
+	if err != nil {
+		r.logger.Error("Failed to update feed " + feed.ID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to update feed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormFeedRepository) Delete(id uuid.UUID) error {
+	result := r.db.Delete(&feedPkg.Feed{}, "id = ?", id)
+	if err := result.Error; err != nil {
+		r.logger.Error("Failed to delete feed " + id.String() + ": " + err.Error())
+		return fmt.Errorf("failed to delete feed: %w", err)
+	}
+
+	if result.RowsAffected == 0 {
+		return feedPkg.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *gormFeedRepository) FindSeen(feedID uuid.UUID, hashes []string) (map[string]bool, error) {
+	seen := make(map[string]bool, len(hashes))
+	if len(hashes) == 0 {
+		return seen, nil
+	}
+
+	var found []string
+	err := r.db.Model(&feedPkg.SeenEntry{}).
+		Where("feed_id = ? AND entry_hash IN ?", feedID, hashes).
+		Pluck("entry_hash", &found).Error
+	if err != nil {
+		r.logger.Error("Failed to find seen entries of feed " + feedID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	for _, hash := range found {
+		seen[hash] = true
+	}
+
+	return seen, nil
+}
+
+func (r *gormFeedRepository) MarkSeen(feedID uuid.UUID, hashes []string) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	entries := make([]*feedPkg.SeenEntry, len(hashes))
+	for i, hash := range hashes {
+		entries[i] = &feedPkg.SeenEntry{FeedID: feedID, EntryHash: hash}
+	}
+
+	// Entries seen twice are a no-op
+	err := r.db.Omit(clause.Associations).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(entries, 500).Error
+	if err != nil {
+		r.logger.Error("Failed to mark entries of feed " + feedID.String() + " seen: " + err.Error())
+		return fmt.Errorf("failed to mark entries seen: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"fmt"
+
+	importerPkg "github.com/dustin/articles-backend/internal/importer"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// gormImportJobRepository implements the importer.JobRepository interface
+type gormImportJobRepository struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewGORMImportJobRepository creates a new GORM-based import job repository
+func NewGORMImportJobRepository(db *gorm.DB, log *logger.Logger) importerPkg.JobRepository {
+	return &gormImportJobRepository{
+		db:     db,
+		logger: log.WithComponent("gorm-import-job-repository"),
+	}
+}
+
+func (r *gormImportJobRepository) Create(job *importerPkg.Job) error {
+	if err := r.db.Create(job).Error; err != nil {
+		r.logger.Error("Failed to create import job " + job.ID.String() + " for user " + job.UserID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to create import job: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormImportJobRepository) Update(job *importerPkg.Job) error {
+	if err := r.db.Save(job).Error; err != nil {
+		r.logger.Error("Failed to update import job " + job.ID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to update import job: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormImportJobRepository) FindByID(id uuid.UUID) (*importerPkg.Job, error) {
+	var job importerPkg.Job
+
+	err := r.db.First(&job, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, importerPkg.ErrJobNotFound
+		}
+
+		r.logger.Error("Failed to find import job " + id.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &job, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"fmt"
+
+	mlexportPkg "github.com/dustin/articles-backend/internal/mlexport"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// gormMLExportRepository implements the mlexport.Repository interface
+type gormMLExportRepository struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewGORMMLExportRepository creates a new GORM-based ML export repository
+func NewGORMMLExportRepository(db *gorm.DB, log *logger.Logger) mlexportPkg.Repository {
+	return &gormMLExportRepository{
+		db:     db,
+		logger: log.WithComponent("gorm-ml-export-repository"),
+	}
+}
+
+func (r *gormMLExportRepository) StreamEmbeddings(userID *uuid.UUID, fn func(row *mlexportPkg.EmbeddingRow) error) error {
+	// Read the vector as text so rows stream without a pgvector decoder
+	query := r.db.Table("articles").
+		Select("id AS article_id, user_id, word_count, embedding::text AS embedding, created_at").
+		Where("embedding IS NOT NULL AND embedding_status = ? AND deleted_at IS NULL", "success").
+		Order("created_at ASC")
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		r.logger.Error("Database error streaming embeddings: " + err.Error())
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row mlexportPkg.EmbeddingRow
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			return fmt.Errorf("failed to scan embedding row: %w", err)
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *gormMLExportRepository) StreamRatings(userID *uuid.UUID, fn func(row *mlexportPkg.RatingRow) error) error {
+	query := r.db.Table("ratings").
+		Select("user_id, article_id, score, created_at").
+		Order("created_at ASC")
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		r.logger.Error("Database error streaming ratings: " + err.Error())
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row mlexportPkg.RatingRow
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			return fmt.Errorf("failed to scan rating row: %w", err)
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/dustin/articles-backend/internal/auth/oauth"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// gormOAuthRepository implements the oauth.Repository interface
+type gormOAuthRepository struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewGORMOAuthRepository creates a new GORM-based OAuth identity repository
+func NewGORMOAuthRepository(db *gorm.DB, log *logger.Logger) oauth.Repository {
+	return &gormOAuthRepository{
+		db:     db,
+		logger: log.WithComponent("gorm-oauth-repository"),
+	}
+}
+
+func (r *gormOAuthRepository) FindIdentity(provider, subject string) (*oauth.Identity, error) {
+	var identity oauth.Identity
+
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, oauth.ErrIdentityNotFound
+		}
+
+		r.logger.Error("Failed to find " + provider + " identity " + subject + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &identity, nil
+}
+
+func (r *gormOAuthRepository) CreateIdentity(identity *oauth.Identity) error {
+	// A concurrent first sign-in of the same account already linked it
+	err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(identity).Error
+	if err != nil {
+		r.logger.Error("Failed to link " + identity.Provider + " identity " + identity.Subject + " to user " + identity.UserID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to create identity: %w", err)
+	}
+
+	return nil
+}
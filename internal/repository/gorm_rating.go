@@ -7,6 +7,7 @@ import (
 	"github.com/dustin/articles-backend/pkg/logger"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // gormRatingRepository implements the rating.Repository interface with GORM optimizations
@@ -44,7 +45,7 @@ func (r *gormRatingRepository) FindByUserAndArticle(userID, articleID uuid.UUID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			r.logger.Info("Repository operation")
-			return nil, fmt.Errorf("rating not found")
+			return nil, ratingPkg.ErrNotFound
 		}
 
 		r.logger.Error("Repository error")
@@ -80,7 +81,7 @@ func (r *gormRatingRepository) Delete(userID, articleID uuid.UUID) error {
 
 	if result.RowsAffected == 0 {
 		r.logger.Warn("Repository warning")
-		return fmt.Errorf("rating not found")
+		return ratingPkg.ErrNotFound
 	}
 
 	r.logger.Info("Repository operation")
@@ -88,6 +89,41 @@ func (r *gormRatingRepository) Delete(userID, articleID uuid.UUID) error {
 	return nil
 }
 
+func (r *gormRatingRepository) FindByUserID(userID uuid.UUID, sort string, ascending bool, offset, limit int) ([]*ratingPkg.Rating, int64, error) {
+	// Ratings of articles in the trash stay hidden until the article is restored
+	query := r.db.Model(&ratingPkg.Rating{}).
+		Joins("JOIN articles ON articles.id = ratings.article_id AND articles.deleted_at IS NULL").
+		Where("ratings.user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("Failed to count ratings of user " + userID.String() + ": " + err.Error())
+		return nil, 0, fmt.Errorf("database error: %w", err)
+	}
+
+	direction := "DESC"
+	if ascending {
+		direction = "ASC"
+	}
+	order := "ratings.updated_at " + direction
+	if sort == ratingPkg.SortByScore {
+		order = "ratings.score " + direction + ", " + order
+	}
+
+	var ratings []*ratingPkg.Rating
+	err := query.Preload("Article").
+		Order(order + ", ratings.article_id").
+		Offset(offset).
+		Limit(limit).
+		Find(&ratings).Error
+	if err != nil {
+		r.logger.Error("Failed to list ratings of user " + userID.String() + ": " + err.Error())
+		return nil, 0, fmt.Errorf("database error: %w", err)
+	}
+
+	return ratings, total, nil
+}
+
 func (r *gormRatingRepository) GetAverageRating(articleID uuid.UUID) (float64, int, error) {
 	type Result struct {
 		Average float64
@@ -111,3 +147,64 @@ func (r *gormRatingRepository) GetAverageRating(articleID uuid.UUID) (float64, i
 
 	return result.Average, result.Count, nil
 }
+
+func (r *gormRatingRepository) CountByScore(articleID uuid.UUID) (map[int]int64, error) {
+	var rows []struct {
+		Score int
+		Count int64
+	}
+
+	err := r.db.Model(&ratingPkg.Rating{}).
+		Select("score, COUNT(*) AS count").
+		Where("article_id = ?", articleID).
+		Group("score").
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("Database error counting ratings of article " + articleID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	counts := make(map[int]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Score] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *gormRatingRepository) AddReaction(reaction *ratingPkg.Reaction) error {
+	// Reacting twice with the same kind is a no-op
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(reaction).Error; err != nil {
+		r.logger.Error("Failed to add reaction " + reaction.Kind + " to article " + reaction.ArticleID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to add reaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormRatingRepository) RemoveReaction(userID, articleID uuid.UUID, kind string) error {
+	result := r.db.Delete(&ratingPkg.Reaction{}, "user_id = ? AND article_id = ? AND kind = ?", userID, articleID, kind)
+	if err := result.Error; err != nil {
+		r.logger.Error("Failed to remove reaction " + kind + " from article " + articleID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to remove reaction: %w", err)
+	}
+
+	if result.RowsAffected == 0 {
+		return ratingPkg.ErrReactionNotFound
+	}
+
+	return nil
+}
+
+func (r *gormRatingRepository) FindReactions(userID, articleID uuid.UUID) ([]*ratingPkg.Reaction, error) {
+	var reactions []*ratingPkg.Reaction
+
+	err := r.db.Where("user_id = ? AND article_id = ?", userID, articleID).
+		Order("created_at ASC").
+		Find(&reactions).Error
+	if err != nil {
+		r.logger.Error("Failed to find reactions for article " + articleID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return reactions, nil
+}
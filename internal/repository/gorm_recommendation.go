@@ -1,13 +1,18 @@
 package repository
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	recommendationPkg "github.com/dustin/articles-backend/internal/recommendation"
 	"github.com/dustin/articles-backend/pkg/logger"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // gormRecommendationArticleRepository implements the recommendation.ArticleRepository interface
@@ -24,15 +29,22 @@ func NewGORMRecommendationArticleRepository(db *gorm.DB, log *logger.Logger) rec
 	}
 }
 
+func (r *gormRecommendationArticleRepository) WithContext(ctx context.Context) recommendationPkg.ArticleRepository {
+	return &gormRecommendationArticleRepository{
+		db:     r.db.WithContext(ctx),
+		logger: r.logger,
+	}
+}
+
 func (r *gormRecommendationArticleRepository) FindByID(id uuid.UUID) (*recommendationPkg.Article, error) {
 	var article recommendationPkg.Article
 
 	// Use primary key lookup for optimal performance
-	err := r.db.First(&article, id).Error
+	err := r.db.Where("deleted_at IS NULL").First(&article, id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			r.logger.Info("Repository operation")
-			return nil, fmt.Errorf("article not found")
+			return nil, recommendationPkg.ErrArticleNotFound
 		}
 
 		r.logger.Error("Repository error")
@@ -42,11 +54,51 @@ func (r *gormRecommendationArticleRepository) FindByID(id uuid.UUID) (*recommend
 	return &article, nil
 }
 
+func (r *gormRecommendationArticleRepository) FindByIDs(ids []uuid.UUID) ([]*recommendationPkg.Article, error) {
+	var articles []*recommendationPkg.Article
+	if len(ids) == 0 {
+		return articles, nil
+	}
+
+	if err := r.db.Where("id IN ? AND deleted_at IS NULL", ids).Find(&articles).Error; err != nil {
+		r.logger.Error("Repository error")
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	// Tag names of all articles in one query
+	var tags []struct {
+		ArticleID uuid.UUID
+		Name      string
+	}
+	err := r.db.Table("article_tags").
+		Select("article_tags.article_id, tags.name").
+		Joins("JOIN tags ON tags.id = article_tags.tag_id").
+		Where("article_tags.article_id IN ?", ids).
+		Order("tags.name").
+		Scan(&tags).Error
+	if err != nil {
+		r.logger.Error("Repository error")
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	byID := make(map[uuid.UUID]*recommendationPkg.Article, len(articles))
+	for _, article := range articles {
+		byID[article.ID] = article
+	}
+	for _, tag := range tags {
+		if article, ok := byID[tag.ArticleID]; ok {
+			article.Tags = append(article.Tags, tag.Name)
+		}
+	}
+
+	return articles, nil
+}
+
 func (r *gormRecommendationArticleRepository) FindAll() ([]*recommendationPkg.Article, error) {
 	var articles []*recommendationPkg.Article
 
 	// Only return successfully processed articles for recommendations
-	err := r.db.Where("metadata_status = ?", "success").
+	err := r.db.Where("metadata_status = ? AND deleted_at IS NULL", "success").
 		Order("created_at DESC").
 		Find(&articles).Error
 
@@ -60,50 +112,56 @@ func (r *gormRecommendationArticleRepository) FindAll() ([]*recommendationPkg.Ar
 	return articles, nil
 }
 
-func (r *gormRecommendationArticleRepository) FindPopular(limit int) ([]*recommendationPkg.Article, error) {
+func (r *gormRecommendationArticleRepository) FindPopular(filter recommendationPkg.CandidateFilter, weights recommendationPkg.PopularityWeights, limit int) ([]*recommendationPkg.Article, error) {
 	var articles []*recommendationPkg.Article
 
-	// Use subquery to find popular articles based on rating count and average
-	err := r.db.Raw(`
-		SELECT a.* FROM articles a
-		LEFT JOIN (
-			SELECT article_id, COUNT(*) as rating_count, AVG(score) as avg_rating
-			FROM ratings 
-			GROUP BY article_id
-			HAVING COUNT(*) >= 2
-		) r ON a.id = r.article_id
-		WHERE a.metadata_status = ?
-		ORDER BY 
-			CASE WHEN r.rating_count IS NULL THEN 0 ELSE r.rating_count END DESC,
-			CASE WHEN r.avg_rating IS NULL THEN 0 ELSE r.avg_rating END DESC,
-			a.created_at DESC
-		LIMIT ?
-	`, "success", limit).Scan(&articles).Error
+	// Saves and ratings are counted per link, over every reader's copy. The
+	// mean rating is damped with two neutral ratings, so one rating of 5 does
+	// not outrank many good ones.
+	halfLifeDays := weights.HalfLife.Hours() / 24
+	popularity := `? * COALESCE(LN(1 + s.save_count) / NULLIF(LN(1 + s.max_saves), 0), 0)
+		+ ? * (COALESCE(r.rating_sum, 0) + 6) / (COALESCE(r.rating_count, 0) + 2) / 5
+		+ ? * EXP(-LN(2) * EXTRACT(EPOCH FROM (NOW() - articles.created_at)) / 86400 / ?)`
+
+	query := r.db.Table("articles").
+		Select("articles.*, "+popularity+" AS popularity", weights.Saves, weights.Rating, weights.Recency, halfLifeDays).
+		Joins(`LEFT JOIN (
+			SELECT url, COUNT(*) AS save_count, MAX(COUNT(*)) OVER () AS max_saves
+			FROM articles
+			WHERE deleted_at IS NULL
+			GROUP BY url
+		) s ON articles.url = s.url`).
+		Joins(`LEFT JOIN (
+			SELECT rated.url, COUNT(*) AS rating_count, SUM(ratings.score) AS rating_sum
+			FROM ratings
+			JOIN articles rated ON rated.id = ratings.article_id
+			GROUP BY rated.url
+		) r ON articles.url = r.url`).
+		Where("articles.metadata_status = ? AND articles.deleted_at IS NULL", "success")
+
+	err := excludeKnownArticles(query, filter).
+		Order("popularity DESC, articles.created_at DESC").
+		Limit(limit).
+		Scan(&articles).Error
 
 	if err != nil {
-		r.logger.Error("Repository error")
+		r.logger.Error("Repository error in FindPopular: " + err.Error())
 		return nil, fmt.Errorf("database error: %w", err)
 	}
 
-	r.logger.Info("Repository operation")
-
 	return articles, nil
 }
 
-func (r *gormRecommendationArticleRepository) FindSimilar(embedding []float64, userID uuid.UUID, limit int) ([]*recommendationPkg.Article, error) {
+func (r *gormRecommendationArticleRepository) FindSimilar(embedding []float64, filter recommendationPkg.CandidateFilter, space recommendationPkg.EmbeddingSpace, titleWeight float64, limit int) ([]*recommendationPkg.Article, error) {
 	var articles []*recommendationPkg.Article
 
 	// Convert embedding to PostgreSQL vector format
-	embeddingStr := r.formatEmbeddingForPostgres(embedding)
+	embeddingStr := formatPostgresVector(embedding)
 
 	// Use GORM's structured query builder with pgvector operations
-	// The <-> operator calculates cosine distance (0 = identical, 2 = opposite)
-	err := r.db.
-		Where("user_id != ?", userID).
-		Where("embedding IS NOT NULL").
+	// The <=> operator calculates cosine distance (0 = identical, 2 = opposite)
+	err := excludeKnownArticles(r.spaceQuery(space, embeddingStr, titleWeight), filter).
 		Where("metadata_status = ?", "success").
-		Where("embedding_status = ?", "success").
-		Order(r.db.Raw("embedding <-> ?::vector", embeddingStr)).
 		Limit(limit).
 		Find(&articles).Error
 
@@ -117,8 +175,123 @@ func (r *gormRecommendationArticleRepository) FindSimilar(embedding []float64, u
 	return articles, nil
 }
 
-// formatEmbeddingForPostgres converts a float64 slice to PostgreSQL vector format
-func (r *gormRecommendationArticleRepository) formatEmbeddingForPostgres(embedding []float64) string {
+func (r *gormRecommendationArticleRepository) FindSimilarInLibrary(embedding []float64, userID uuid.UUID, space recommendationPkg.EmbeddingSpace, titleWeight float64, limit int) ([]*recommendationPkg.Article, error) {
+	var articles []*recommendationPkg.Article
+
+	embeddingStr := formatPostgresVector(embedding)
+
+	err := r.spaceQuery(space, embeddingStr, titleWeight).
+		Where("user_id = ?", userID).
+		Limit(limit).
+		Find(&articles).Error
+
+	if err != nil {
+		r.logger.Error("Repository error in FindSimilarInLibrary: " + err.Error())
+		return nil, fmt.Errorf("vector similarity search error: %w", err)
+	}
+
+	return articles, nil
+}
+
+// Collaborative filtering considers ratings of 4 or 5 as likes, and only the
+// readers sharing the most likes with the user
+const (
+	collaborativeMinScore   = 4
+	collaborativeNeighbours = 50
+)
+
+// neighbourQuery finds the readers who liked the most links the user liked,
+// then the links those readers liked that the user has not saved. Each link is
+// scored by the overlap of the readers who liked it and represented by one of
+// their copies.
+const neighbourQuery = `
+	WITH liked AS (
+		SELECT DISTINCT a.url FROM ratings r
+		JOIN articles a ON a.id = r.article_id
+		WHERE r.user_id = @user AND r.score >= @min_score AND a.deleted_at IS NULL
+	),
+	neighbours AS (
+		SELECT r.user_id, COUNT(DISTINCT a.url) AS overlap FROM ratings r
+		JOIN articles a ON a.id = r.article_id
+		JOIN liked l ON l.url = a.url
+		WHERE r.user_id <> @user AND r.score >= @min_score AND a.deleted_at IS NULL
+		GROUP BY r.user_id
+		ORDER BY overlap DESC
+		LIMIT @neighbours
+	),
+	candidates AS (
+		SELECT a.url, SUM(n.overlap) AS affinity, MIN(a.id::text)::uuid AS article_id FROM ratings r
+		JOIN neighbours n ON n.user_id = r.user_id
+		JOIN articles a ON a.id = r.article_id
+		WHERE r.score >= @min_score AND a.deleted_at IS NULL AND a.metadata_status = 'success'
+			AND a.url NOT IN (SELECT url FROM articles WHERE user_id = @user)
+		GROUP BY a.url
+	)
+	SELECT articles.*, candidates.affinity FROM candidates
+	JOIN articles ON articles.id = candidates.article_id
+	ORDER BY candidates.affinity DESC, articles.created_at DESC
+	LIMIT @limit`
+
+func (r *gormRecommendationArticleRepository) FindLikedByNeighbours(userID uuid.UUID, limit int) ([]*recommendationPkg.Article, error) {
+	var articles []*recommendationPkg.Article
+
+	err := r.db.Raw(neighbourQuery, map[string]any{
+		"user":       userID,
+		"min_score":  collaborativeMinScore,
+		"neighbours": collaborativeNeighbours,
+		"limit":      limit,
+	}).Scan(&articles).Error
+	if err != nil {
+		r.logger.Error("Repository error in FindLikedByNeighbours: " + err.Error())
+		return nil, fmt.Errorf("collaborative search error: %w", err)
+	}
+
+	return articles, nil
+}
+
+// excludeKnownArticles leaves out the articles the user saved or rated and,
+// if the filter asks, other copies of the links they saved
+func excludeKnownArticles(query *gorm.DB, filter recommendationPkg.CandidateFilter) *gorm.DB {
+	query = query.
+		Where("articles.user_id <> ?", filter.UserID).
+		Where("NOT EXISTS (SELECT 1 FROM ratings WHERE ratings.article_id = articles.id AND ratings.user_id = ?)", filter.UserID)
+	if filter.ExcludeSavedURLs {
+		// Trashed articles count as saved, so links the user discarded stay out
+		query = query.Where("articles.url NOT IN (SELECT saved.url FROM articles saved WHERE saved.user_id = ?)", filter.UserID)
+	}
+	return query
+}
+
+// spaceQuery filters to articles embedded in the space and orders them by cosine distance to the
+// vector, which it selects as distance. Articles without a content embedding fall back to their
+// title embedding in the blended space. Trashed articles are never candidates.
+func (r *gormRecommendationArticleRepository) spaceQuery(space recommendationPkg.EmbeddingSpace, embeddingStr string, titleWeight float64) *gorm.DB {
+	query := r.db.Model(&recommendationPkg.Article{}).Where("deleted_at IS NULL")
+
+	switch space {
+	case recommendationPkg.SpaceContent:
+		query = query.
+			Where("content_embedding IS NOT NULL").
+			Where("content_embedding_status = ?", "success").
+			Select("articles.*, content_embedding <=> ?::vector AS distance", embeddingStr)
+	case recommendationPkg.SpaceBlended:
+		query = query.
+			Where("embedding IS NOT NULL").
+			Where("embedding_status = ?", "success").
+			Select("articles.*, ? * (embedding <=> ?::vector) + ? * (COALESCE(content_embedding, embedding) <=> ?::vector) AS distance",
+				titleWeight, embeddingStr, 1-titleWeight, embeddingStr)
+	default:
+		query = query.
+			Where("embedding IS NOT NULL").
+			Where("embedding_status = ?", "success").
+			Select("articles.*, embedding <=> ?::vector AS distance", embeddingStr)
+	}
+
+	return query.Order("distance ASC")
+}
+
+// formatPostgresVector converts a float64 slice to PostgreSQL vector format
+func formatPostgresVector(embedding []float64) string {
 	if len(embedding) == 0 {
 		return "[]"
 	}
@@ -144,6 +317,13 @@ func NewGORMRecommendationRatingRepository(db *gorm.DB, log *logger.Logger) reco
 	}
 }
 
+func (r *gormRecommendationRatingRepository) WithContext(ctx context.Context) recommendationPkg.RatingRepository {
+	return &gormRecommendationRatingRepository{
+		db:     r.db.WithContext(ctx),
+		logger: r.logger,
+	}
+}
+
 func (r *gormRecommendationRatingRepository) FindByUserID(userID uuid.UUID) ([]*recommendationPkg.Rating, error) {
 	var ratings []*recommendationPkg.Rating
 
@@ -180,3 +360,403 @@ func (r *gormRecommendationRatingRepository) GetAverageRating(articleID uuid.UUI
 
 	return result.Average, result.Count, nil
 }
+
+func (r *gormRecommendationRatingRepository) CreateIfAbsent(rating *recommendationPkg.Rating) (bool, error) {
+	// Never overwrite a rating the user gave explicitly
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(rating)
+	if err := result.Error; err != nil {
+		r.logger.Error("Repository error in CreateIfAbsent: " + err.Error())
+		return false, fmt.Errorf("failed to create rating: %w", err)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *gormRecommendationRatingRepository) FindReactionsByUserID(userID uuid.UUID) ([]*recommendationPkg.Reaction, error) {
+	var reactions []*recommendationPkg.Reaction
+
+	err := r.db.Where("user_id = ?", userID).Find(&reactions).Error
+	if err != nil {
+		r.logger.Error("Repository error in FindReactionsByUserID: " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return reactions, nil
+}
+
+func (r *gormRecommendationRatingRepository) FindFavoritesByUserID(userID uuid.UUID) ([]uuid.UUID, error) {
+	var articleIDs []uuid.UUID
+
+	err := r.db.Table("articles").
+		Where("user_id = ? AND favorited_at IS NOT NULL AND deleted_at IS NULL", userID).
+		Pluck("id", &articleIDs).Error
+	if err != nil {
+		r.logger.Error("Repository error in FindFavoritesByUserID: " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return articleIDs, nil
+}
+
+// gormRecommendationProfileRepository implements the recommendation.ProfileRepository interface
+type gormRecommendationProfileRepository struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewGORMRecommendationProfileRepository creates a new GORM-based user profile repository
+func NewGORMRecommendationProfileRepository(db *gorm.DB, log *logger.Logger) recommendationPkg.ProfileRepository {
+	return &gormRecommendationProfileRepository{
+		db:     db,
+		logger: log.WithComponent("gorm-recommendation-profile-repository"),
+	}
+}
+
+func (r *gormRecommendationProfileRepository) WithContext(ctx context.Context) recommendationPkg.ProfileRepository {
+	return &gormRecommendationProfileRepository{
+		db:     r.db.WithContext(ctx),
+		logger: r.logger,
+	}
+}
+
+// profileRow is a user_profiles row with the vector read as text, as there is
+// no pgvector decoder
+type profileRow struct {
+	UserID    uuid.UUID
+	Space     string
+	Embedding string
+	Weights   map[uuid.UUID]float64 `gorm:"serializer:json"`
+	UpdatedAt time.Time
+}
+
+func (r *gormRecommendationProfileRepository) FindProfile(userID uuid.UUID) (*recommendationPkg.UserProfile, error) {
+	var rows []profileRow
+	err := r.db.Table("user_profiles").
+		Select("user_id, space, embedding::text AS embedding, weights, updated_at").
+		Where("user_id = ?", userID).
+		Limit(1).
+		Find(&rows).Error
+	if err != nil {
+		r.logger.Error("Repository error in FindProfile: " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, recommendationPkg.ErrProfileNotFound
+	}
+
+	embedding, err := parsePostgresVector(rows[0].Embedding)
+	if err != nil {
+		return nil, fmt.Errorf("invalid profile embedding: %w", err)
+	}
+
+	return &recommendationPkg.UserProfile{
+		UserID:    rows[0].UserID,
+		Space:     recommendationPkg.EmbeddingSpace(rows[0].Space),
+		Embedding: embedding,
+		Weights:   rows[0].Weights,
+		UpdatedAt: rows[0].UpdatedAt,
+	}, nil
+}
+
+func (r *gormRecommendationProfileRepository) SaveProfile(profile *recommendationPkg.UserProfile) error {
+	return r.insert(profile, `ON CONFLICT (user_id) DO UPDATE SET
+		space = EXCLUDED.space, embedding = EXCLUDED.embedding, weights = EXCLUDED.weights, updated_at = EXCLUDED.updated_at`)
+}
+
+func (r *gormRecommendationProfileRepository) CreateProfileIfAbsent(profile *recommendationPkg.UserProfile) error {
+	return r.insert(profile, "ON CONFLICT (user_id) DO NOTHING")
+}
+
+// insert stores the profile, resolving a conflict with the stored one as onConflict says
+func (r *gormRecommendationProfileRepository) insert(profile *recommendationPkg.UserProfile, onConflict string) error {
+	weights, err := json.Marshal(profile.Weights)
+	if err != nil {
+		return fmt.Errorf("failed to encode profile weights: %w", err)
+	}
+
+	err = r.db.Exec(`INSERT INTO user_profiles (user_id, space, embedding, weights, updated_at)
+		VALUES (?, ?, ?::vector, ?::jsonb, ?) `+onConflict,
+		profile.UserID, string(profile.Space), formatPostgresVector(profile.Embedding), string(weights), profile.UpdatedAt).Error
+	if err != nil {
+		r.logger.Error("Repository error storing profile of user " + profile.UserID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to store profile: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormRecommendationProfileRepository) DeleteProfile(userID uuid.UUID) error {
+	if err := r.db.Where("user_id = ?", userID).Delete(&recommendationPkg.UserProfile{}).Error; err != nil {
+		r.logger.Error("Repository error in DeleteProfile: " + err.Error())
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+// interestsRow is a user_interests row with the vector read as text
+type interestsRow struct {
+	UserID    uuid.UUID
+	Topics    []string `gorm:"serializer:json"`
+	Embedding string
+	UpdatedAt time.Time
+}
+
+func (r *gormRecommendationProfileRepository) FindInterests(userID uuid.UUID) (*recommendationPkg.UserInterests, error) {
+	var rows []interestsRow
+	err := r.db.Table("user_interests").
+		Select("user_id, topics, embedding::text AS embedding, updated_at").
+		Where("user_id = ?", userID).
+		Limit(1).
+		Find(&rows).Error
+	if err != nil {
+		r.logger.Error("Repository error in FindInterests: " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, recommendationPkg.ErrInterestsNotFound
+	}
+
+	embedding, err := parsePostgresVector(rows[0].Embedding)
+	if err != nil {
+		return nil, fmt.Errorf("invalid interests embedding: %w", err)
+	}
+
+	return &recommendationPkg.UserInterests{
+		UserID:    rows[0].UserID,
+		Topics:    rows[0].Topics,
+		Embedding: embedding,
+		UpdatedAt: rows[0].UpdatedAt,
+	}, nil
+}
+
+func (r *gormRecommendationProfileRepository) SaveInterests(interests *recommendationPkg.UserInterests) error {
+	topics, err := json.Marshal(interests.Topics)
+	if err != nil {
+		return fmt.Errorf("failed to encode interests: %w", err)
+	}
+
+	err = r.db.Exec(`INSERT INTO user_interests (user_id, topics, embedding, updated_at)
+		VALUES (?, ?::jsonb, ?::vector, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+		topics = EXCLUDED.topics, embedding = EXCLUDED.embedding, updated_at = EXCLUDED.updated_at`,
+		interests.UserID, string(topics), formatPostgresVector(interests.Embedding), interests.UpdatedAt).Error
+	if err != nil {
+		r.logger.Error("Repository error storing interests of user " + interests.UserID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to store interests: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormRecommendationProfileRepository) DeleteInterests(userID uuid.UUID) error {
+	if err := r.db.Where("user_id = ?", userID).Delete(&recommendationPkg.UserInterests{}).Error; err != nil {
+		r.logger.Error("Repository error in DeleteInterests: " + err.Error())
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormRecommendationProfileRepository) FindStoredList(userID uuid.UUID, engine string) (*recommendationPkg.StoredList, error) {
+	var lists []*recommendationPkg.StoredList
+	err := r.db.Where("user_id = ? AND engine = ?", userID, engine).
+		Limit(1).
+		Find(&lists).Error
+	if err != nil {
+		r.logger.Error("Repository error in FindStoredList: " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if len(lists) == 0 {
+		return nil, recommendationPkg.ErrStoredListNotFound
+	}
+
+	return lists[0], nil
+}
+
+func (r *gormRecommendationProfileRepository) SaveStoredList(list *recommendationPkg.StoredList) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "engine"}},
+		DoUpdates: clause.AssignmentColumns([]string{"items", "computed_at"}),
+	}).Create(list).Error
+	if err != nil {
+		r.logger.Error("Repository error storing recommendations of user " + list.UserID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to store recommendations: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormRecommendationProfileRepository) DeleteStoredLists(userID uuid.UUID) error {
+	if err := r.db.Where("user_id = ?", userID).Delete(&recommendationPkg.StoredList{}).Error; err != nil {
+		r.logger.Error("Repository error in DeleteStoredLists: " + err.Error())
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormRecommendationProfileRepository) DeleteStoredListsBefore(computedAt time.Time) error {
+	if err := r.db.Where("computed_at < ?", computedAt).Delete(&recommendationPkg.StoredList{}).Error; err != nil {
+		r.logger.Error("Repository error in DeleteStoredListsBefore: " + err.Error())
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+// parsePostgresVector reads the text form of a pgvector vector, e.g. "[0.1,0.2]"
+func parsePostgresVector(value string) ([]float64, error) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+		return nil, fmt.Errorf("malformed vector %q", value)
+	}
+	value = strings.TrimSpace(value[1 : len(value)-1])
+	if value == "" {
+		return []float64{}, nil
+	}
+
+	parts := strings.Split(value, ",")
+	vector := make([]float64, len(parts))
+	for i, part := range parts {
+		component, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed vector component %q", part)
+		}
+		vector[i] = component
+	}
+	return vector, nil
+}
+
+// gormRecommendationBanditRepository implements the recommendation.BanditRepository interface
+type gormRecommendationBanditRepository struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewGORMRecommendationBanditRepository creates a new GORM-based bandit statistics repository
+func NewGORMRecommendationBanditRepository(db *gorm.DB, log *logger.Logger) recommendationPkg.BanditRepository {
+	return &gormRecommendationBanditRepository{
+		db:     db,
+		logger: log.WithComponent("gorm-recommendation-bandit-repository"),
+	}
+}
+
+func (r *gormRecommendationBanditRepository) WithContext(ctx context.Context) recommendationPkg.BanditRepository {
+	return &gormRecommendationBanditRepository{
+		db:     r.db.WithContext(ctx),
+		logger: r.logger,
+	}
+}
+
+func (r *gormRecommendationBanditRepository) FindArms(userID uuid.UUID) ([]*recommendationPkg.BanditArm, error) {
+	var arms []*recommendationPkg.BanditArm
+	if err := r.db.Where("user_id = ?", userID).Find(&arms).Error; err != nil {
+		r.logger.Error("Repository error in FindArms: " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return arms, nil
+}
+
+func (r *gormRecommendationBanditRepository) RecordImpressions(userID uuid.UUID, impressions []*recommendationPkg.Impression) error {
+	shown := make(map[string]int64)
+	for _, impression := range impressions {
+		shown[impression.Engine]++
+	}
+	arms := make([]*recommendationPkg.BanditArm, 0, len(shown))
+	for engine, count := range shown {
+		arms = append(arms, &recommendationPkg.BanditArm{UserID: userID, Engine: engine, Shown: count})
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Showing an article again makes feedback on it count again
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "article_id"}},
+			DoUpdates: clause.Assignments(map[string]any{"url": gorm.Expr("EXCLUDED.url"), "engine": gorm.Expr("EXCLUDED.engine"), "shown_at": gorm.Expr("EXCLUDED.shown_at"), "rewarded_at": nil}),
+		}).Create(&impressions).Error
+		if err != nil {
+			return err
+		}
+
+		// Added to the stored counts so concurrent requests never overwrite each other
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "engine"}},
+			DoUpdates: clause.Assignments(map[string]any{"shown": gorm.Expr("recommendation_arms.shown + EXCLUDED.shown"), "updated_at": gorm.Expr("EXCLUDED.updated_at")}),
+		}).Create(&arms).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to record impressions for user " + userID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to record impressions: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormRecommendationBanditRepository) RewardImpression(userID, articleID uuid.UUID, since time.Time) (bool, error) {
+	var engines []string
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Feedback usually arrives on the user's own copy of the recommended link
+		err := tx.Raw(`UPDATE recommendation_impressions SET rewarded_at = ?
+			WHERE user_id = ? AND rewarded_at IS NULL AND shown_at >= ?
+			AND (article_id = ? OR url IN (SELECT url FROM articles WHERE id = ?))
+			RETURNING engine`,
+			time.Now(), userID, since, articleID, articleID).Scan(&engines).Error
+		if err != nil {
+			return err
+		}
+
+		for _, engine := range engines {
+			err := tx.Model(&recommendationPkg.BanditArm{}).
+				Where("user_id = ? AND engine = ?", userID, engine).
+				Update("rewards", gorm.Expr("rewards + 1")).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to reward impression of article " + articleID.String() + " for user " + userID.String() + ": " + err.Error())
+		return false, fmt.Errorf("failed to reward impression: %w", err)
+	}
+
+	return len(engines) > 0, nil
+}
+
+func (r *gormRecommendationBanditRepository) RecordEvents(events []*recommendationPkg.Event) error {
+	if err := r.db.Create(&events).Error; err != nil {
+		r.logger.Error("Failed to record recommendation events: " + err.Error())
+		return fmt.Errorf("failed to record events: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormRecommendationBanditRepository) CountEvents(since time.Time) ([]*recommendationPkg.EngineCTR, error) {
+	var engines []*recommendationPkg.EngineCTR
+
+	err := r.db.Model(&recommendationPkg.Event{}).
+		Select("engine, COUNT(*) FILTER (WHERE type = ?) AS impressions, COUNT(*) FILTER (WHERE type = ?) AS clicks",
+			recommendationPkg.EventImpression, recommendationPkg.EventClick).
+		Where("created_at >= ?", since).
+		Group("engine").
+		Order("engine ASC").
+		Scan(&engines).Error
+	if err != nil {
+		r.logger.Error("Failed to count recommendation events: " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return engines, nil
+}
+
+func (r *gormRecommendationBanditRepository) DeleteEventsBefore(createdAt time.Time) error {
+	if err := r.db.Where("created_at < ?", createdAt).Delete(&recommendationPkg.Event{}).Error; err != nil {
+		r.logger.Error("Failed to delete expired recommendation events: " + err.Error())
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	sharePkg "github.com/dustin/articles-backend/internal/share"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// gormShareRepository implements the share.Repository interface
+type gormShareRepository struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewGORMShareRepository creates a new GORM-based share repository
+func NewGORMShareRepository(db *gorm.DB, log *logger.Logger) sharePkg.Repository {
+	return &gormShareRepository{
+		db:     db,
+		logger: log.WithComponent("gorm-share-repository"),
+	}
+}
+
+func (r *gormShareRepository) Create(share *sharePkg.Share) error {
+	if err := r.db.Omit(clause.Associations).Create(share).Error; err != nil {
+		r.logger.Error("Failed to create share of article " + share.ArticleID.String() + " for user " + share.UserID.String() + ": " + err.Error())
+		return fmt.Errorf("failed to create share: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormShareRepository) FindBySlug(slug string) (*sharePkg.Share, error) {
+	var share sharePkg.Share
+
+	err := r.db.Where("slug = ?", slug).First(&share).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, sharePkg.ErrNotFound
+		}
+
+		r.logger.Error("Database error finding share " + slug + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &share, nil
+}
+
+func (r *gormShareRepository) FindActiveByArticle(articleID uuid.UUID) (*sharePkg.Share, error) {
+	var share sharePkg.Share
+
+	err := r.db.Where("article_id = ? AND revoked_at IS NULL", articleID).
+		Order("created_at ASC").
+		First(&share).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, sharePkg.ErrNotFound
+		}
+
+		r.logger.Error("Database error finding share of article " + articleID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &share, nil
+}
+
+func (r *gormShareRepository) SlugExists(slug string) (bool, error) {
+	var count int64
+
+	if err := r.db.Model(&sharePkg.Share{}).Where("slug = ?", slug).Count(&count).Error; err != nil {
+		r.logger.Error("Failed to check share slug " + slug + ": " + err.Error())
+		return false, fmt.Errorf("database error: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *gormShareRepository) Revoke(id uuid.UUID, at time.Time) error {
+	result := r.db.Model(&sharePkg.Share{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		r.logger.Error("Failed to revoke share " + id.String() + ": " + result.Error.Error())
+		return fmt.Errorf("failed to revoke share: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return sharePkg.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	usagePkg "github.com/dustin/articles-backend/internal/usage"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// gormUsageRepository implements the usage.Repository interface
+type gormUsageRepository struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewGORMUsageRepository creates a new GORM-based usage counter repository
+func NewGORMUsageRepository(db *gorm.DB, log *logger.Logger) usagePkg.Repository {
+	return &gormUsageRepository{
+		db:     db,
+		logger: log.WithComponent("gorm-usage-repository"),
+	}
+}
+
+func (r *gormUsageRepository) Increment(counters []*usagePkg.Counter) error {
+	// Upsert adding to the existing count so concurrent instances never overwrite each other
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "token_id"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]any{"count": gorm.Expr("usage_counters.count + EXCLUDED.count")}),
+	}).Create(&counters).Error
+	if err != nil {
+		r.logger.Error("Failed to increment usage counters: " + err.Error())
+		return fmt.Errorf("failed to increment usage counters: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormUsageRepository) FindByUserID(userID uuid.UUID, since time.Time) ([]*usagePkg.Counter, error) {
+	var counters []*usagePkg.Counter
+
+	err := r.db.Where("user_id = ? AND day >= ?", userID, since).
+		Order("day ASC").
+		Find(&counters).Error
+	if err != nil {
+		r.logger.Error("Failed to find usage counters for user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return counters, nil
+}
+
+func (r *gormUsageRepository) FindActiveUserIDs(since time.Time, limit int) ([]uuid.UUID, error) {
+	var userIDs []uuid.UUID
+
+	err := r.db.Model(&usagePkg.Counter{}).
+		Where("day >= ?", since).
+		Group("user_id").
+		Order("SUM(count) DESC").
+		Limit(limit).
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		r.logger.Error("Failed to find active users: " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return userIDs, nil
+}
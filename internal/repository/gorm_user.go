@@ -1,12 +1,15 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	userPkg "github.com/dustin/articles-backend/internal/user"
 	"github.com/dustin/articles-backend/pkg/logger"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // gormUserRepository implements the user.Repository interface with GORM optimizations
@@ -44,7 +47,7 @@ func (r *gormUserRepository) FindByEmail(email string) (*userPkg.User, error) {
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			r.logger.Info("User not found by email: " + email)
-			return nil, fmt.Errorf("user not found")
+			return nil, userPkg.ErrNotFound
 		}
 
 		r.logger.Error("Database error finding user by email " + email + ": " + err.Error())
@@ -62,7 +65,7 @@ func (r *gormUserRepository) FindByID(id uuid.UUID) (*userPkg.User, error) {
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			r.logger.Info("User not found by ID: " + id.String())
-			return nil, fmt.Errorf("user not found")
+			return nil, userPkg.ErrNotFound
 		}
 
 		r.logger.Error("Database error finding user by ID " + id.String() + ": " + err.Error())
@@ -71,3 +74,319 @@ func (r *gormUserRepository) FindByID(id uuid.UUID) (*userPkg.User, error) {
 
 	return &user, nil
 }
+
+func (r *gormUserRepository) UpdatePassword(id uuid.UUID, passwordHash string, changedAt time.Time) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&userPkg.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"password_hash":       passwordHash,
+			"password_changed_at": changedAt,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return userPkg.ErrNotFound
+		}
+
+		// Every login of the user ends with the old password
+		return tx.Model(&userPkg.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", changedAt).Error
+	})
+	if err != nil {
+		if errors.Is(err, userPkg.ErrNotFound) {
+			return err
+		}
+
+		r.logger.Error("Failed to update password of user " + id.String() + ": " + err.Error())
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormUserRepository) UpdateEmail(id uuid.UUID, email string) error {
+	result := r.db.Model(&userPkg.User{}).Where("id = ?", id).
+		Updates(map[string]any{"email": email, "pending_email": nil})
+	if result.Error != nil {
+		r.logger.Error("Failed to update email of user " + id.String() + ": " + result.Error.Error())
+		return fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return userPkg.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *gormUserRepository) SetPendingEmail(id uuid.UUID, email *string) error {
+	result := r.db.Model(&userPkg.User{}).Where("id = ?", id).Update("pending_email", email)
+	if result.Error != nil {
+		r.logger.Error("Failed to set pending email of user " + id.String() + ": " + result.Error.Error())
+		return fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return userPkg.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *gormUserRepository) UpdateRole(id uuid.UUID, role string) error {
+	result := r.db.Model(&userPkg.User{}).Where("id = ?", id).Update("role", role)
+	if result.Error != nil {
+		r.logger.Error("Failed to update role of user " + id.String() + ": " + result.Error.Error())
+		return fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return userPkg.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *gormUserRepository) ScheduleDeletion(id uuid.UUID, deleteAfter *time.Time) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&userPkg.User{}).Where("id = ?", id).Update("delete_after", deleteAfter)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return userPkg.ErrNotFound
+		}
+		if deleteAfter == nil {
+			return nil
+		}
+
+		// The account is signed out while it waits for deletion
+		return tx.Model(&userPkg.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", time.Now()).Error
+	})
+	if err != nil {
+		if errors.Is(err, userPkg.ErrNotFound) {
+			return err
+		}
+
+		r.logger.Error("Failed to schedule deletion of user " + id.String() + ": " + err.Error())
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormUserRepository) FindDueDeletions(before time.Time, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+
+	err := r.db.Model(&userPkg.User{}).
+		Where("delete_after IS NOT NULL AND delete_after < ?", before).
+		Order("delete_after").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil {
+		r.logger.Error("Database error finding accounts to delete: " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return ids, nil
+}
+
+// accountDeletions removes a user's rows, children before their parents.
+// Tables keyed by the user's articles, collections, feeds or tags come first,
+// so deletion does not depend on which foreign keys cascade.
+var accountDeletions = []string{
+	"DELETE FROM highlights WHERE article_id IN (SELECT id FROM articles WHERE user_id = @user)",
+	"DELETE FROM highlights WHERE user_id = @user",
+	"DELETE FROM article_shares WHERE user_id = @user",
+	"DELETE FROM article_tags WHERE article_id IN (SELECT id FROM articles WHERE user_id = @user)",
+	"DELETE FROM tags WHERE user_id = @user",
+	"DELETE FROM collection_articles WHERE collection_id IN (SELECT id FROM collections WHERE user_id = @user)",
+	"DELETE FROM collections WHERE user_id = @user",
+	"DELETE FROM feed_entries WHERE feed_id IN (SELECT id FROM feeds WHERE user_id = @user)",
+	"DELETE FROM feeds WHERE user_id = @user",
+	"DELETE FROM ratings WHERE user_id = @user OR article_id IN (SELECT id FROM articles WHERE user_id = @user)",
+	"DELETE FROM reactions WHERE user_id = @user OR article_id IN (SELECT id FROM articles WHERE user_id = @user)",
+	"DELETE FROM user_profiles WHERE user_id = @user",
+	"DELETE FROM user_interests WHERE user_id = @user",
+	"DELETE FROM recommendation_lists WHERE user_id = @user",
+	"DELETE FROM recommendation_arms WHERE user_id = @user",
+	"DELETE FROM recommendation_impressions WHERE user_id = @user",
+	"DELETE FROM recommendation_events WHERE user_id = @user",
+	"DELETE FROM import_jobs WHERE user_id = @user",
+	"DELETE FROM export_jobs WHERE user_id = @user",
+	"DELETE FROM usage_counters WHERE user_id = @user",
+	// Daily aggregates keyed by user ID; totals over all users are kept
+	"DELETE FROM aggregate_buckets WHERE key = @key",
+	// Articles in the trash too, with their embeddings
+	"DELETE FROM articles WHERE user_id = @user",
+	"DELETE FROM refresh_tokens WHERE user_id = @user",
+	"DELETE FROM oauth_identities WHERE user_id = @user",
+	"DELETE FROM users WHERE id = @user",
+}
+
+func (r *gormUserRepository) DeleteAccount(id uuid.UUID) ([]string, error) {
+	var objectKeys []string
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user userPkg.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return userPkg.ErrNotFound
+			}
+			return err
+		}
+
+		var keys []struct {
+			SnapshotKey string
+			ContentKey  string
+		}
+		err := tx.Table("articles").
+			Select("snapshot_key, content_key").
+			Where("user_id = ? AND (snapshot_key <> '' OR content_key <> '')", id).
+			Scan(&keys).Error
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if key.SnapshotKey != "" {
+				objectKeys = append(objectKeys, key.SnapshotKey)
+			}
+			if key.ContentKey != "" {
+				objectKeys = append(objectKeys, key.ContentKey)
+			}
+		}
+
+		args := map[string]interface{}{"user": id, "key": id.String()}
+		for _, statement := range accountDeletions {
+			if err := tx.Exec(statement, args).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, userPkg.ErrNotFound) {
+			return nil, err
+		}
+
+		r.logger.Error("Failed to delete account of user " + id.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	r.logger.Info("Account deleted: " + id.String())
+
+	return objectKeys, nil
+}
+
+func (r *gormUserRepository) CreateRefreshToken(token *userPkg.RefreshToken) error {
+	if err := r.db.Create(token).Error; err != nil {
+		r.logger.Error("Failed to create refresh token for user " + token.UserID.String() + ": " + err.Error())
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormUserRepository) FindRefreshToken(tokenHash string) (*userPkg.RefreshToken, error) {
+	var token userPkg.RefreshToken
+
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, userPkg.ErrRefreshTokenNotFound
+		}
+
+		r.logger.Error("Database error finding refresh token: " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &token, nil
+}
+
+func (r *gormUserRepository) RotateRefreshToken(currentID uuid.UUID, next *userPkg.RefreshToken) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Only the request that revokes the token may replace it
+		result := tx.Model(&userPkg.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", currentID).
+			Update("revoked_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return userPkg.ErrRefreshTokenRevoked
+		}
+
+		return tx.Create(next).Error
+	})
+	if err != nil {
+		if errors.Is(err, userPkg.ErrRefreshTokenRevoked) {
+			return err
+		}
+
+		r.logger.Error("Failed to rotate refresh token " + currentID.String() + ": " + err.Error())
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormUserRepository) RevokeRefreshTokenFamily(familyID uuid.UUID) error {
+	err := r.db.Model(&userPkg.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		r.logger.Error("Failed to revoke refresh token family " + familyID.String() + ": " + err.Error())
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gormUserRepository) FindActiveRefreshTokens(userID uuid.UUID, after time.Time) ([]userPkg.RefreshToken, error) {
+	var tokens []userPkg.RefreshToken
+
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, after).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		r.logger.Error("Failed to find refresh tokens of user " + userID.String() + ": " + err.Error())
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return tokens, nil
+}
+
+func (r *gormUserRepository) RevokeSession(userID, familyID uuid.UUID) (bool, error) {
+	result := r.db.Model(&userPkg.RefreshToken{}).
+		Where("user_id = ? AND family_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, familyID, time.Now()).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		r.logger.Error("Failed to revoke session " + familyID.String() + ": " + result.Error.Error())
+		return false, fmt.Errorf("database error: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *gormUserRepository) IsSessionActive(familyID uuid.UUID, after time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&userPkg.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL AND expires_at > ?", familyID, after).
+		Count(&count).Error
+	if err != nil {
+		r.logger.Error("Failed to look up session " + familyID.String() + ": " + err.Error())
+		return false, fmt.Errorf("database error: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *gormUserRepository) DeleteExpiredRefreshTokens(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&userPkg.RefreshToken{})
+	if result.Error != nil {
+		r.logger.Error("Failed to delete expired refresh tokens: " + result.Error.Error())
+		return 0, fmt.Errorf("database error: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,124 @@
+package share
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for share links
+type Handler struct {
+	service    Service
+	sharedPath string // Path prefix of public links, known once routes are registered
+}
+
+// NewHandler creates a new share handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:    service,
+		sharedPath: "/shared/",
+	}
+}
+
+// ShareArticle handles creating an article's public link, or returning the existing one
+func (h *Handler) ShareArticle(c *gin.Context) {
+	userID, articleID, ok := h.articleTarget(c)
+	if !ok {
+		return
+	}
+
+	share, err := h.service.ShareArticle(articleID, userID)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to share article")
+		return
+	}
+
+	c.JSON(http.StatusOK, h.withURL(c, share))
+}
+
+// GetShare handles getting an article's active public link
+func (h *Handler) GetShare(c *gin.Context) {
+	userID, articleID, ok := h.articleTarget(c)
+	if !ok {
+		return
+	}
+
+	share, err := h.service.GetShare(articleID, userID)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to get share link")
+		return
+	}
+
+	c.JSON(http.StatusOK, h.withURL(c, share))
+}
+
+// RevokeShare handles revoking an article's public link
+func (h *Handler) RevokeShare(c *gin.Context) {
+	userID, articleID, ok := h.articleTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RevokeShare(articleID, userID); err != nil {
+		utils.RespondError(c, err, "Failed to revoke share link")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Share link revoked"})
+}
+
+// GetSharedArticle handles reading a shared article without authentication
+func (h *Handler) GetSharedArticle(c *gin.Context) {
+	article, err := h.service.GetSharedArticle(c.Param("token"))
+	if err != nil {
+		utils.RespondError(c, err, "Failed to get shared article")
+		return
+	}
+
+	// Revocation must take effect at once, and links are not meant to be indexed
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Robots-Tag", "noindex")
+	c.JSON(http.StatusOK, article)
+}
+
+// withURL fills in the public link of a share as the client reached the API
+func (h *Handler) withURL(c *gin.Context, share *ShareResponse) *ShareResponse {
+	share.URL = utils.RequestBaseURL(c) + h.sharedPath + url.PathEscape(share.Token)
+	return share
+}
+
+// articleTarget extracts the user and article of a request, writing the error response on failure
+func (h *Handler) articleTarget(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	articleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, articleID, true
+}
+
+// RegisterRoutes registers all share routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	h.sharedPath = router.BasePath() + "/shared/"
+
+	// Anyone holding a link can read the article
+	router.GET("/shared/:token", h.GetSharedArticle)
+
+	articles := router.Group("/articles")
+	articles.Use(authMiddleware)
+	{
+		articles.POST("/:id/share", h.ShareArticle)
+		articles.GET("/:id/share", h.GetShare)
+		articles.DELETE("/:id/share", h.RevokeShare)
+	}
+}
@@ -0,0 +1,202 @@
+package share
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// signatureLength is the number of HMAC bytes kept in a token; 128 bits
+// cannot be guessed, and keep links short
+const signatureLength = 16
+
+// service implements the Service interface
+type service struct {
+	repo           Repository
+	articleService ArticleService
+	signingKey     []byte
+	logger         *logger.Logger
+}
+
+// NewService creates a new share service. Tokens are signed with a key
+// derived from the JWT secret, so rotating that secret invalidates every link.
+func NewService(cfg *config.JWTConfig, repo Repository, articleService ArticleService, log *logger.Logger) (Service, error) {
+	if cfg == nil || cfg.Secret == "" {
+		return nil, errors.New("share links require a JWT secret to sign tokens")
+	}
+
+	// A separate key keeps share signatures from being usable as anything else
+	mac := hmac.New(sha256.New, []byte(cfg.Secret))
+	mac.Write([]byte("article-share-links"))
+
+	return &service{
+		repo:           repo,
+		articleService: articleService,
+		signingKey:     mac.Sum(nil),
+		logger:         log.WithComponent("share-service"),
+	}, nil
+}
+
+func (s *service) ShareArticle(articleID, userID uuid.UUID) (*ShareResponse, error) {
+	article, err := s.articleService.GetArticle(articleID, userID)
+	if err != nil {
+		s.logger.Info("Article not found or access denied " + articleID.String() + " for user " + userID.String() + ": " + err.Error())
+		return nil, ErrArticleNotFound
+	}
+
+	// Sharing is idempotent: an article has at most one active link
+	existing, err := s.repo.FindActiveByArticle(articleID)
+	if err == nil {
+		return s.toResponse(existing), nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	s.logger.Info("Sharing article " + articleID.String() + " for user " + userID.String())
+
+	slug, err := utils.UniqueSlug(utils.Slugify(article.Title), s.repo.SlugExists)
+	if err != nil {
+		s.logger.Error("Failed to generate share slug for article " + articleID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	share := &Share{
+		ID:        uuid.New(),
+		ArticleID: articleID,
+		UserID:    userID,
+		Slug:      slug,
+	}
+	if err := s.repo.Create(share); err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(share), nil
+}
+
+func (s *service) GetShare(articleID, userID uuid.UUID) (*ShareResponse, error) {
+	if _, err := s.articleService.GetArticle(articleID, userID); err != nil {
+		return nil, ErrArticleNotFound
+	}
+
+	share, err := s.repo.FindActiveByArticle(articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(share), nil
+}
+
+func (s *service) RevokeShare(articleID, userID uuid.UUID) error {
+	if _, err := s.articleService.GetArticle(articleID, userID); err != nil {
+		return ErrArticleNotFound
+	}
+
+	share, err := s.repo.FindActiveByArticle(articleID)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("Revoking share " + share.ID.String() + " of article " + articleID.String() + " for user " + userID.String())
+
+	if err := s.repo.Revoke(share.ID, time.Now()); err != nil {
+		s.logger.Error("Failed to revoke share " + share.ID.String() + ": " + err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) GetSharedArticle(token string) (*SharedArticleResponse, error) {
+	slug, ok := slugFromToken(token)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	share, err := s.repo.FindBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(token), []byte(s.sign(share))) || !share.IsActive() {
+		return nil, ErrNotFound
+	}
+
+	// Read as the owner, so trashed articles stop being shared
+	article, err := s.articleService.GetArticle(share.ArticleID, share.UserID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &SharedArticleResponse{
+		ArticleID:   article.ID,
+		URL:         article.URL,
+		Title:       article.Title,
+		Description: article.Description,
+		ImageURL:    article.ImageURL,
+		SiteName:    article.SiteName,
+		Content:     article.Content,
+		WordCount:   article.WordCount,
+		ReadingTime: article.ReadingTime,
+		Language:    article.Language,
+		SavedAt:     article.CreatedAt,
+		SharedAt:    share.CreatedAt,
+	}, nil
+}
+
+func (s *service) IsShared(articleID uuid.UUID) (bool, error) {
+	share, err := s.repo.FindActiveByArticle(articleID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	// Read as the owner, so trashed articles stop being shared
+	if _, err := s.articleService.GetArticle(articleID, share.UserID); err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *service) toResponse(share *Share) *ShareResponse {
+	return &ShareResponse{
+		ArticleID: share.ArticleID,
+		Token:     s.sign(share),
+		CreatedAt: share.CreatedAt,
+	}
+}
+
+// sign returns the public token of a share: its readable slug followed by an
+// HMAC over the share's ID and slug. Slugs come from titles and are easy to
+// guess; the signature is not. Binding the ID means a slug reused after its
+// share is deleted does not revive old links.
+func (s *service) sign(share *Share) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(share.ID.String() + "|" + share.Slug))
+	return share.Slug + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:signatureLength])
+}
+
+// slugFromToken splits the slug off a token; slugs never contain dots
+func slugFromToken(token string) (string, bool) {
+	slug, signature, ok := strings.Cut(token, ".")
+	if !ok || slug == "" || signature == "" {
+		return "", false
+	}
+	return slug, true
+}
@@ -0,0 +1,121 @@
+package share
+
+import (
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Errors returned by the share service and repository. Links that are
+// malformed, forged or revoked all read as not found, so a public request
+// cannot tell them apart.
+var (
+	ErrNotFound        = utils.NewNotFoundError("share link not found")
+	ErrArticleNotFound = utils.NewNotFoundError("article not found")
+)
+
+// Share is a public, read-only link to one of a user's articles. Revoked
+// shares are kept so their slug is never handed out again.
+type Share struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ArticleID uuid.UUID  `json:"article_id" gorm:"type:uuid;not null;index"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Slug      string     `json:"slug" gorm:"size:100;not null;uniqueIndex"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+
+	// Associations (forward declarations)
+	Article *Article `json:"-" gorm:"foreignKey:ArticleID;constraint:OnDelete:CASCADE"`
+	User    *User    `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for GORM
+func (Share) TableName() string {
+	return "article_shares"
+}
+
+// Article represents the parts of an article a share exposes (forward declaration)
+type Article struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID      uuid.UUID
+	URL         string
+	Title       string
+	Description string
+	ImageURL    string
+	SiteName    string
+	Content     string
+	WordCount   int
+	ReadingTime int `gorm:"column:reading_time_minutes"`
+	Language    string
+	CreatedAt   time.Time
+}
+
+// TableName returns the table name for GORM
+func (Article) TableName() string {
+	return "articles"
+}
+
+// User represents user for foreign key relationship (forward declaration)
+type User struct {
+	ID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Email string
+}
+
+// Repository defines the interface for share data access
+type Repository interface {
+	Create(share *Share) error
+	FindBySlug(slug string) (*Share, error)
+	// FindActiveByArticle returns the article's unrevoked share, if any
+	FindActiveByArticle(articleID uuid.UUID) (*Share, error)
+	// SlugExists also reports slugs of revoked shares as taken
+	SlugExists(slug string) (bool, error)
+	Revoke(id uuid.UUID, at time.Time) error
+}
+
+// Service defines the interface for share business logic
+type Service interface {
+	// ShareArticle returns the article's share link, creating it on first use
+	ShareArticle(articleID, userID uuid.UUID) (*ShareResponse, error)
+	GetShare(articleID, userID uuid.UUID) (*ShareResponse, error)
+	RevokeShare(articleID, userID uuid.UUID) error
+	// GetSharedArticle resolves a public token, without authentication
+	GetSharedArticle(token string) (*SharedArticleResponse, error)
+	// IsShared reports whether the article has an active share link
+	IsShared(articleID uuid.UUID) (bool, error)
+}
+
+// ArticleService interface for article operations (dependency inversion)
+type ArticleService interface {
+	// GetArticle returns the user's article, failing for trashed ones
+	GetArticle(id, userID uuid.UUID) (*Article, error)
+}
+
+// ShareResponse describes a share link to its owner
+type ShareResponse struct {
+	ArticleID uuid.UUID `json:"article_id"`
+	Token     string    `json:"token"`
+	URL       string    `json:"url,omitempty"` // Filled in by the handler, which knows the host
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SharedArticleResponse is what anyone holding a share link can read
+type SharedArticleResponse struct {
+	ArticleID   uuid.UUID `json:"article_id"` // For rating the article, when the rating policy allows it
+	URL         string    `json:"url"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	ImageURL    string    `json:"image_url"`
+	SiteName    string    `json:"site_name"`
+	Content     string    `json:"content"`
+	WordCount   int       `json:"word_count"`
+	ReadingTime int       `json:"reading_time_minutes"`
+	Language    string    `json:"language"`
+	SavedAt     time.Time `json:"saved_at"`
+	SharedAt    time.Time `json:"shared_at"`
+}
+
+// IsActive reports whether the share has not been revoked
+func (s *Share) IsActive() bool {
+	return s.RevokedAt == nil
+}
@@ -0,0 +1,178 @@
+package share
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRepository keeps shares in memory
+type mockRepository struct {
+	shares []*Share
+}
+
+func (m *mockRepository) Create(share *Share) error {
+	share.CreatedAt = time.Now()
+	m.shares = append(m.shares, share)
+	return nil
+}
+
+func (m *mockRepository) FindBySlug(slug string) (*Share, error) {
+	for _, share := range m.shares {
+		if share.Slug == slug {
+			return share, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m *mockRepository) FindActiveByArticle(articleID uuid.UUID) (*Share, error) {
+	for _, share := range m.shares {
+		if share.ArticleID == articleID && share.IsActive() {
+			return share, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m *mockRepository) SlugExists(slug string) (bool, error) {
+	_, err := m.FindBySlug(slug)
+	return err == nil, nil
+}
+
+func (m *mockRepository) Revoke(id uuid.UUID, at time.Time) error {
+	for _, share := range m.shares {
+		if share.ID == id && share.IsActive() {
+			share.RevokedAt = &at
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// mockArticleService serves articles by owner
+type mockArticleService struct {
+	articles []*Article
+}
+
+func (m *mockArticleService) GetArticle(id, userID uuid.UUID) (*Article, error) {
+	for _, article := range m.articles {
+		if article.ID == id && article.UserID == userID {
+			return article, nil
+		}
+	}
+	return nil, utils.NewNotFoundError("article not found")
+}
+
+func newTestService(t *testing.T, secret string, articles *mockArticleService) Service {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	svc, err := NewService(&config.JWTConfig{Secret: secret}, &mockRepository{}, articles, log)
+	require.NoError(t, err)
+	return svc
+}
+
+func TestNewServiceRequiresSecret(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	_, err = NewService(&config.JWTConfig{}, &mockRepository{}, &mockArticleService{}, log)
+	assert.Error(t, err)
+}
+
+func TestShareLinks(t *testing.T) {
+	userID := uuid.New()
+	article := &Article{ID: uuid.New(), UserID: userID, Title: "Hello, World!", URL: "https://example.com/hello", Content: "Body"}
+
+	t.Run("Links are readable until revoked", func(t *testing.T) {
+		svc := newTestService(t, "secret", &mockArticleService{articles: []*Article{article}})
+
+		share, err := svc.ShareArticle(article.ID, userID)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(share.Token, "hello-world."), share.Token)
+
+		again, err := svc.ShareArticle(article.ID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, share.Token, again.Token, "sharing twice returns the same link")
+
+		shared, err := svc.GetSharedArticle(share.Token)
+		require.NoError(t, err)
+		assert.Equal(t, "Hello, World!", shared.Title)
+		assert.Equal(t, "Body", shared.Content)
+
+		require.NoError(t, svc.RevokeShare(article.ID, userID))
+		_, err = svc.GetSharedArticle(share.Token)
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		// Sharing again issues a new link; the revoked slug stays reserved
+		renewed, err := svc.ShareArticle(article.ID, userID)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(renewed.Token, "hello-world-2."), renewed.Token)
+	})
+
+	t.Run("Forged and malformed tokens are rejected", func(t *testing.T) {
+		svc := newTestService(t, "secret", &mockArticleService{articles: []*Article{article}})
+
+		share, err := svc.ShareArticle(article.ID, userID)
+		require.NoError(t, err)
+
+		for _, token := range []string{"hello-world", "hello-world.", "hello-world.AAAAAAAAAAAAAAAAAAAAAA", share.Token + "x", ""} {
+			_, err := svc.GetSharedArticle(token)
+			assert.ErrorIs(t, err, ErrNotFound, token)
+		}
+	})
+
+	t.Run("Only the owner can share", func(t *testing.T) {
+		svc := newTestService(t, "secret", &mockArticleService{articles: []*Article{article}})
+
+		_, err := svc.ShareArticle(article.ID, uuid.New())
+		assert.ErrorIs(t, err, ErrArticleNotFound)
+		assert.ErrorIs(t, svc.RevokeShare(article.ID, uuid.New()), ErrArticleNotFound)
+	})
+
+	t.Run("Links to trashed articles stop working", func(t *testing.T) {
+		articles := &mockArticleService{articles: []*Article{article}}
+		svc := newTestService(t, "secret", articles)
+
+		share, err := svc.ShareArticle(article.ID, userID)
+		require.NoError(t, err)
+
+		articles.articles = nil
+		_, err = svc.GetSharedArticle(share.Token)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestIsShared(t *testing.T) {
+	userID := uuid.New()
+	article := &Article{ID: uuid.New(), UserID: userID, Title: "Hello"}
+	articles := &mockArticleService{articles: []*Article{article}}
+	svc := newTestService(t, "secret", articles)
+
+	isShared := func() bool {
+		shared, err := svc.IsShared(article.ID)
+		require.NoError(t, err)
+		return shared
+	}
+
+	assert.False(t, isShared(), "not shared yet")
+
+	_, err := svc.ShareArticle(article.ID, userID)
+	require.NoError(t, err)
+	assert.True(t, isShared())
+
+	articles.articles = nil
+	assert.False(t, isShared(), "trashed articles are not shared")
+
+	articles.articles = []*Article{article}
+	require.NoError(t, svc.RevokeShare(article.ID, userID))
+	assert.False(t, isShared(), "revoked links are not shared")
+}
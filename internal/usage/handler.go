@@ -0,0 +1,52 @@
+package usage
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles HTTP requests for usage reports
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new usage handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// GetUsage handles getting the authenticated user's daily request counts
+func (h *Handler) GetUsage(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(defaultUsageDays)))
+	if err != nil || days <= 0 || days > maxUsageDays {
+		days = defaultUsageDays
+	}
+
+	response, err := h.service.GetUsage(userID, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get usage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RegisterRoutes registers all usage routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	users := router.Group("/users")
+	users.Use(authMiddleware)
+	{
+		users.GET("/me/usage", h.GetUsage)
+	}
+}
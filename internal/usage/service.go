@@ -0,0 +1,266 @@
+package usage
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultUsageDays = 30
+	maxUsageDays     = 90
+	dateLayout       = "2006-01-02"
+)
+
+// counterKey identifies an in-memory counter
+type counterKey struct {
+	userID  uuid.UUID
+	tokenID string
+}
+
+// service implements the Service interface. Requests are counted in memory
+// and flushed to the counters table periodically, so accounting never adds
+// a database write to the request path.
+type service struct {
+	repo       Repository
+	dailyQuota int64 // 0 means unlimited
+	logger     *logger.Logger
+	now        func() time.Time
+
+	mu      sync.Mutex
+	day     time.Time
+	pending map[counterKey]int64
+	today   map[uuid.UUID]int64 // Requests per user today, flushed or not
+}
+
+// NewService creates a usage service with validation and defaults
+func NewService(cfg *config.UsageConfig, repo Repository, log *logger.Logger) (Service, error) {
+	var dailyQuota int64
+	if cfg != nil && cfg.DailyQuota != "" {
+		quota, err := strconv.ParseInt(cfg.DailyQuota, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid usage daily quota '%s': %v", cfg.DailyQuota, err)
+		}
+		if quota < 0 {
+			return nil, fmt.Errorf("invalid usage daily quota '%s': must not be negative", cfg.DailyQuota)
+		}
+		dailyQuota = quota
+	}
+
+	return &service{
+		repo:       repo,
+		dailyQuota: dailyQuota,
+		logger:     log.WithComponent("usage-service"),
+		now:        time.Now,
+		pending:    make(map[counterKey]int64),
+		today:      make(map[uuid.UUID]int64),
+	}, nil
+}
+
+func (s *service) Record(userID uuid.UUID, tokenID string) (bool, error) {
+	day := truncateDay(s.now())
+
+	s.mu.Lock()
+	var stale []*Counter
+	if !day.Equal(s.day) {
+		// Counts still pending belong to the previous day
+		stale = s.takePending()
+		s.rollover(day)
+	}
+	_, known := s.today[userID]
+	s.mu.Unlock()
+
+	if len(stale) > 0 {
+		s.write(stale)
+	}
+
+	// Seed today's total from storage the first time a user is seen today,
+	// so quotas survive restarts
+	if !known {
+		stored, err := s.storedTotal(userID, day)
+		if err != nil {
+			return true, err // Fail open; accounting must not take the API down
+		}
+		s.mu.Lock()
+		if _, ok := s.today[userID]; !ok && day.Equal(s.day) {
+			s.today[userID] = stored
+		}
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.dailyQuota > 0 && s.today[userID] >= s.dailyQuota {
+		return false, nil
+	}
+
+	s.today[userID]++
+	s.pending[counterKey{userID: userID, tokenID: tokenID}]++
+	return true, nil
+}
+
+func (s *service) Flush() error {
+	s.mu.Lock()
+	counters := s.takePending()
+	s.mu.Unlock()
+
+	return s.write(counters)
+}
+
+func (s *service) GetUsage(userID uuid.UUID, days int) (*UsageResponse, error) {
+	if days < 1 {
+		days = defaultUsageDays
+	}
+	if days > maxUsageDays {
+		days = maxUsageDays
+	}
+
+	// Persist pending counts so the report includes the latest requests
+	if err := s.Flush(); err != nil {
+		return nil, err
+	}
+
+	today := truncateDay(s.now())
+	since := today.AddDate(0, 0, -(days - 1))
+
+	counters, err := s.repo.FindByUserID(userID, since)
+	if err != nil {
+		s.logger.Error("Failed to load usage for user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	return buildUsageResponse(userID, since, today, s.dailyQuota, counters), nil
+}
+
+func (s *service) ActiveUsers(days, limit int) ([]uuid.UUID, error) {
+	if days < 1 {
+		days = 1
+	}
+
+	// Include requests counted since the last flush
+	if err := s.Flush(); err != nil {
+		return nil, err
+	}
+
+	since := truncateDay(s.now()).AddDate(0, 0, -(days - 1))
+	userIDs, err := s.repo.FindActiveUserIDs(since, limit)
+	if err != nil {
+		s.logger.Error("Failed to list active users: " + err.Error())
+		return nil, err
+	}
+
+	return userIDs, nil
+}
+
+// rollover starts a new accounting day; the caller must hold s.mu
+func (s *service) rollover(day time.Time) {
+	s.day = day
+	s.today = make(map[uuid.UUID]int64)
+}
+
+// takePending detaches the pending counters; the caller must hold s.mu
+func (s *service) takePending() []*Counter {
+	counters := make([]*Counter, 0, len(s.pending))
+	for key, count := range s.pending {
+		counters = append(counters, &Counter{
+			UserID:  key.userID,
+			TokenID: key.tokenID,
+			Day:     s.day,
+			Count:   count,
+		})
+	}
+	s.pending = make(map[counterKey]int64)
+	return counters
+}
+
+func (s *service) write(counters []*Counter) error {
+	if len(counters) == 0 {
+		return nil
+	}
+
+	if err := s.repo.Increment(counters); err != nil {
+		s.logger.Error("Failed to flush " + strconv.Itoa(len(counters)) + " usage counters: " + err.Error())
+
+		// Keep the counts for the next flush
+		s.mu.Lock()
+		for _, counter := range counters {
+			if counter.Day.Equal(s.day) {
+				s.pending[counterKey{userID: counter.UserID, tokenID: counter.TokenID}] += counter.Count
+			}
+		}
+		s.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) storedTotal(userID uuid.UUID, day time.Time) (int64, error) {
+	counters, err := s.repo.FindByUserID(userID, day)
+	if err != nil {
+		s.logger.Error("Failed to load today's usage for user " + userID.String() + ": " + err.Error())
+		return 0, err
+	}
+
+	var total int64
+	for _, counter := range counters {
+		total += counter.Count
+	}
+	return total, nil
+}
+
+// buildUsageResponse groups counters by day, filling days without requests with zeros
+func buildUsageResponse(userID uuid.UUID, since, today time.Time, dailyQuota int64, counters []*Counter) *UsageResponse {
+	byDate := make(map[string]*DailyUsage)
+	response := &UsageResponse{
+		UserID: userID,
+		From:   since.Format(dateLayout),
+		To:     today.Format(dateLayout),
+		Days:   make([]*DailyUsage, 0),
+	}
+
+	for day := since; !day.After(today); day = day.AddDate(0, 0, 1) {
+		usage := &DailyUsage{Date: day.Format(dateLayout), ByKey: make(map[string]int64)}
+		byDate[usage.Date] = usage
+		response.Days = append(response.Days, usage)
+	}
+
+	for _, counter := range counters {
+		usage, ok := byDate[counter.Day.UTC().Format(dateLayout)]
+		if !ok {
+			continue
+		}
+
+		key := counter.TokenID
+		if key == "" {
+			key = SessionKey
+		}
+		usage.ByKey[key] += counter.Count
+		usage.Total += counter.Count
+	}
+
+	response.UsedToday = byDate[today.Format(dateLayout)].Total
+	if dailyQuota > 0 {
+		quota := dailyQuota
+		remaining := dailyQuota - response.UsedToday
+		if remaining < 0 {
+			remaining = 0
+		}
+		response.DailyQuota = &quota
+		response.RemainingToday = &remaining
+	}
+
+	return response
+}
+
+// truncateDay returns midnight UTC of the given time
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,60 @@
+package usage
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SessionKey labels requests made with login tokens rather than API tokens
+const SessionKey = "session"
+
+// Counter is the number of requests a user made with one token on one day
+type Counter struct {
+	UserID  uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	TokenID string    `json:"token_id" gorm:"size:64;primaryKey"` // Empty for login sessions
+	Day     time.Time `json:"day" gorm:"type:date;primaryKey"`
+	Count   int64     `json:"count" gorm:"not null;default:0"`
+}
+
+// TableName returns the table name for GORM
+func (Counter) TableName() string {
+	return "usage_counters"
+}
+
+// Repository defines the interface for usage counter storage
+type Repository interface {
+	// Increment adds each counter's Count to the stored value, creating rows as needed
+	Increment(counters []*Counter) error
+	FindByUserID(userID uuid.UUID, since time.Time) ([]*Counter, error)
+	// FindActiveUserIDs returns users with requests since the given day, busiest first
+	FindActiveUserIDs(since time.Time, limit int) ([]uuid.UUID, error)
+}
+
+// Service defines the interface for request accounting
+type Service interface {
+	// Record counts a request and reports whether it fits in the daily quota
+	Record(userID uuid.UUID, tokenID string) (bool, error)
+	Flush() error
+	GetUsage(userID uuid.UUID, days int) (*UsageResponse, error)
+	// ActiveUsers lists up to limit users who made requests in the last days, busiest first
+	ActiveUsers(days, limit int) ([]uuid.UUID, error)
+}
+
+// DailyUsage is the request count of a single day
+type DailyUsage struct {
+	Date  string           `json:"date"` // YYYY-MM-DD in UTC
+	Total int64            `json:"total"`
+	ByKey map[string]int64 `json:"by_key"` // Token ID, or "session" for login tokens
+}
+
+// UsageResponse summarizes a user's API consumption
+type UsageResponse struct {
+	UserID         uuid.UUID     `json:"user_id"`
+	From           string        `json:"from"`
+	To             string        `json:"to"`
+	UsedToday      int64         `json:"used_today"`
+	DailyQuota     *int64        `json:"daily_quota"`     // Null when unlimited
+	RemainingToday *int64        `json:"remaining_today"` // Null when unlimited
+	Days           []*DailyUsage `json:"days"`
+}
@@ -0,0 +1,221 @@
+package usage
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T, cfg *config.UsageConfig, repo Repository, now time.Time) *service {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	svc, err := NewService(cfg, repo, log)
+	require.NoError(t, err)
+
+	s := svc.(*service)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestNewService(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	_, err = NewService(&config.UsageConfig{DailyQuota: "many"}, newMockRepository(), log)
+	assert.Error(t, err)
+
+	_, err = NewService(&config.UsageConfig{DailyQuota: "-1"}, newMockRepository(), log)
+	assert.Error(t, err)
+}
+
+func TestRecordAndReport(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+
+	t.Run("Counts per key and day", func(t *testing.T) {
+		repo := newMockRepository()
+		repo.counters = append(repo.counters, &Counter{UserID: userID, TokenID: "key-1", Day: truncateDay(now.AddDate(0, 0, -1)), Count: 7})
+		svc := newTestService(t, &config.UsageConfig{}, repo, now)
+
+		for i := 0; i < 3; i++ {
+			allowed, err := svc.Record(userID, "")
+			require.NoError(t, err)
+			assert.True(t, allowed)
+		}
+		_, err := svc.Record(userID, "key-1")
+		require.NoError(t, err)
+
+		report, err := svc.GetUsage(userID, 7)
+		require.NoError(t, err)
+
+		assert.Equal(t, "2024-05-04", report.From)
+		assert.Equal(t, "2024-05-10", report.To)
+		require.Len(t, report.Days, 7)
+		assert.Equal(t, int64(4), report.UsedToday)
+		assert.Nil(t, report.DailyQuota)
+		assert.Nil(t, report.RemainingToday)
+
+		today := report.Days[6]
+		assert.Equal(t, map[string]int64{SessionKey: 3, "key-1": 1}, today.ByKey)
+		assert.Equal(t, int64(7), report.Days[5].Total)
+		assert.Equal(t, int64(0), report.Days[0].Total)
+	})
+
+	t.Run("Enforces daily quota including stored counts", func(t *testing.T) {
+		repo := newMockRepository()
+		repo.counters = append(repo.counters, &Counter{UserID: userID, Day: truncateDay(now), Count: 1})
+		svc := newTestService(t, &config.UsageConfig{DailyQuota: "2"}, repo, now)
+
+		allowed, err := svc.Record(userID, "")
+		require.NoError(t, err)
+		assert.True(t, allowed)
+
+		allowed, err = svc.Record(userID, "")
+		require.NoError(t, err)
+		assert.False(t, allowed)
+
+		report, err := svc.GetUsage(userID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), *report.DailyQuota)
+		assert.Equal(t, int64(0), *report.RemainingToday)
+	})
+
+	t.Run("Pending counts stay on their day across midnight", func(t *testing.T) {
+		repo := newMockRepository()
+		svc := newTestService(t, &config.UsageConfig{DailyQuota: "1"}, repo, now)
+
+		allowed, _ := svc.Record(userID, "")
+		assert.True(t, allowed)
+
+		svc.now = func() time.Time { return now.Add(12 * time.Hour) }
+		allowed, _ = svc.Record(userID, "")
+		assert.True(t, allowed, "quota resets on a new day")
+
+		require.NoError(t, svc.Flush())
+		require.Len(t, repo.counters, 2)
+		assert.Equal(t, truncateDay(now), repo.counters[0].Day)
+		assert.Equal(t, truncateDay(now).AddDate(0, 0, 1), repo.counters[1].Day)
+	})
+
+	t.Run("Failed flushes are retried", func(t *testing.T) {
+		repo := newMockRepository()
+		svc := newTestService(t, &config.UsageConfig{}, repo, now)
+
+		_, err := svc.Record(userID, "")
+		require.NoError(t, err)
+
+		repo.err = errors.New("database down")
+		assert.Error(t, svc.Flush())
+
+		repo.err = nil
+		require.NoError(t, svc.Flush())
+		require.Len(t, repo.counters, 1)
+		assert.Equal(t, int64(1), repo.counters[0].Count)
+	})
+}
+
+func TestActiveUsers(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+	busy, quiet, gone := uuid.New(), uuid.New(), uuid.New()
+
+	repo := newMockRepository()
+	repo.counters = append(repo.counters,
+		&Counter{UserID: quiet, Day: truncateDay(now.AddDate(0, 0, -1)), Count: 2},
+		&Counter{UserID: busy, Day: truncateDay(now.AddDate(0, 0, -2)), Count: 5},
+		&Counter{UserID: gone, Day: truncateDay(now.AddDate(0, 0, -10)), Count: 50},
+	)
+	svc := newTestService(t, &config.UsageConfig{}, repo, now)
+
+	// Unflushed requests count too
+	_, err := svc.Record(quiet, "")
+	require.NoError(t, err)
+
+	users, err := svc.ActiveUsers(7, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{busy, quiet}, users)
+
+	users, err = svc.ActiveUsers(7, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{busy}, users)
+}
+
+// mockRepository stores counters in memory
+type mockRepository struct {
+	mu       sync.Mutex
+	counters []*Counter
+	err      error
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{}
+}
+
+func (m *mockRepository) Increment(counters []*Counter) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+
+	for _, counter := range counters {
+		found := false
+		for _, existing := range m.counters {
+			if existing.UserID == counter.UserID && existing.TokenID == counter.TokenID && existing.Day.Equal(counter.Day) {
+				existing.Count += counter.Count
+				found = true
+				break
+			}
+		}
+		if !found {
+			stored := *counter
+			m.counters = append(m.counters, &stored)
+		}
+	}
+	return nil
+}
+
+func (m *mockRepository) FindByUserID(userID uuid.UUID, since time.Time) ([]*Counter, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var counters []*Counter
+	for _, counter := range m.counters {
+		if counter.UserID == userID && !counter.Day.Before(since) {
+			counters = append(counters, counter)
+		}
+	}
+	return counters, nil
+}
+
+func (m *mockRepository) FindActiveUserIDs(since time.Time, limit int) ([]uuid.UUID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	totals := make(map[uuid.UUID]int64)
+	var userIDs []uuid.UUID
+	for _, counter := range m.counters {
+		if counter.Day.Before(since) {
+			continue
+		}
+		if _, seen := totals[counter.UserID]; !seen {
+			userIDs = append(userIDs, counter.UserID)
+		}
+		totals[counter.UserID] += counter.Count
+	}
+
+	sort.SliceStable(userIDs, func(i, j int) bool { return totals[userIDs[i]] > totals[userIDs[j]] })
+	if len(userIDs) > limit {
+		userIDs = userIDs[:limit]
+	}
+	return userIDs, nil
+}
@@ -0,0 +1,79 @@
+package user
+
+import (
+	"time"
+
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// Types of audit events recorded for users
+const (
+	AuditLoginSucceeded    = "login.succeeded"
+	AuditLoginFailed       = "login.failed"
+	AuditLoginLockedOut    = "login.locked_out" // An account or IP reached its failure limit
+	AuditLoginBlocked      = "login.blocked"    // A login was refused during a lockout
+	AuditPasswordChanged   = "password.changed"
+	AuditEmailChanged      = "email.changed"
+	AuditTokenRevoked      = "token.revoked"
+	AuditAPIKeyIssued      = "api_key.issued"
+	AuditAPIKeyUsed        = "api_key.used"
+	AuditImpersonation     = "impersonation.started"
+	AuditRoleChanged       = "role.changed"
+	AuditDeletionScheduled = "account.deletion_scheduled"
+	AuditDeletionCancelled = "account.deletion_cancelled"
+	AuditAccountDeleted    = "account.deleted"
+)
+
+// apiKeyUseAuditInterval is how often the use of one API key is recorded
+const apiKeyUseAuditInterval = time.Hour
+
+// AuditEvent is a security event of a user
+type AuditEvent struct {
+	Type    string
+	UserID  uuid.UUID // Nil when unknown, as for failed logins of unknown emails
+	ActorID uuid.UUID // The admin acting on the user, if any
+	Email   string
+	Client  Client
+	Details string
+}
+
+// AuditRecorder records security events in the audit log (dependency
+// inversion). Recording failures are handled by the recorder.
+type AuditRecorder interface {
+	Record(event *AuditEvent)
+}
+
+// logAuditRecorder writes events to the audit logger only, when no audit log
+// is configured
+type logAuditRecorder struct {
+	logger *logger.Logger
+}
+
+func (r *logAuditRecorder) Record(event *AuditEvent) {
+	message := "Audit event " + event.Type
+	if event.UserID != uuid.Nil {
+		message += " for user " + event.UserID.String()
+	}
+	if event.Details != "" {
+		message += ": " + event.Details
+	}
+	r.logger.Info(message)
+}
+
+// newAuditRecorder returns recorder, or one writing to the audit logger when nil
+func newAuditRecorder(recorder AuditRecorder, log *logger.Logger) AuditRecorder {
+	if recorder == nil {
+		return &logAuditRecorder{logger: log.WithComponent("audit")}
+	}
+	return recorder
+}
+
+// RecordAPIKeyUse records the use of a scoped API token. Each token is
+// recorded at most once an hour so busy keys do not flood the audit log.
+func (s *service) RecordAPIKeyUse(userID uuid.UUID, tokenID string, client Client) {
+	if allowed, _, _ := s.apiKeyUses.Allow(tokenID); !allowed {
+		return
+	}
+	s.audit.Record(&AuditEvent{Type: AuditAPIKeyUsed, UserID: userID, Client: client, Details: "token " + tokenID})
+}
@@ -0,0 +1,151 @@
+package user
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultUserCacheTTL bounds how long a changed or deleted user can still
+	// authenticate on another instance, which does not see the invalidation
+	defaultUserCacheTTL = 30 * time.Second
+	// maxCachedUsers bounds the cache; users beyond it are looked up each time
+	// until expired entries are dropped
+	maxCachedUsers = 10000
+)
+
+type cachedUser struct {
+	user      User
+	expiresAt time.Time
+}
+
+// userCache keeps recently looked up users so authenticating a request does
+// not query the database each time. A zero TTL disables it.
+type userCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[uuid.UUID]*cachedUser
+	now     func() time.Time
+}
+
+func newUserCache(ttl time.Duration) *userCache {
+	return &userCache{
+		ttl:     ttl,
+		entries: make(map[uuid.UUID]*cachedUser),
+		now:     time.Now,
+	}
+}
+
+// get returns a copy of the cached user, if it has not expired
+func (c *userCache) get(id uuid.UUID) (*User, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, id)
+		return nil, false
+	}
+	user := entry.user
+	return &user, true
+}
+
+// put caches a copy of the user. Expired entries are dropped once the cache is full.
+func (c *userCache) put(user *User) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if len(c.entries) >= maxCachedUsers {
+		for id, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+		if len(c.entries) >= maxCachedUsers {
+			return
+		}
+	}
+	c.entries[user.ID] = &cachedUser{user: *user, expiresAt: now.Add(c.ttl)}
+}
+
+// invalidate drops the user so the next lookup reads the database
+func (c *userCache) invalidate(id uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, id)
+}
+
+// sessionCache remembers sessions recently found active, so authenticating a
+// request with a session's access token does not query the database each
+// time. Revoked sessions are not cached; a zero TTL disables it.
+type sessionCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[uuid.UUID]time.Time // Session ID to expiry
+	now     func() time.Time
+}
+
+func newSessionCache(ttl time.Duration) *sessionCache {
+	return &sessionCache{
+		ttl:     ttl,
+		entries: make(map[uuid.UUID]time.Time),
+		now:     time.Now,
+	}
+}
+
+// active reports whether the session was found active and has not expired
+func (c *sessionCache) active(id uuid.UUID) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt, ok := c.entries[id]
+	if !ok {
+		return false
+	}
+	if !c.now().Before(expiresAt) {
+		delete(c.entries, id)
+		return false
+	}
+	return true
+}
+
+// put records the session as active. Expired entries are dropped once the
+// cache is full.
+func (c *sessionCache) put(id uuid.UUID) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if len(c.entries) >= maxCachedUsers {
+		for id, expiresAt := range c.entries {
+			if !now.Before(expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+		if len(c.entries) >= maxCachedUsers {
+			return
+		}
+	}
+	c.entries[id] = now.Add(c.ttl)
+}
+
+// invalidate drops the session so the next lookup reads the database
+func (c *sessionCache) invalidate(id uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, id)
+}
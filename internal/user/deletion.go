@@ -0,0 +1,183 @@
+package user
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Limits on account deletion
+const (
+	// deletionConfirmationTTL is how long a deletion confirmation token works
+	deletionConfirmationTTL = 10 * time.Minute
+	// deletionBatchSize is how many due accounts a purge run deletes at once
+	deletionBatchSize = 50
+)
+
+// Errors returned for account deletion
+var (
+	// ErrInvalidConfirmation is returned for wrong or expired deletion confirmation tokens
+	ErrInvalidConfirmation = utils.NewValidationError("confirmation_token", "is invalid or expired")
+	// errScheduledForDeletion rejects the tokens of users whose account is
+	// scheduled for deletion
+	errScheduledForDeletion = errors.New("account scheduled for deletion")
+)
+
+// DeleteAccountRequest asks to delete the current user's account. The first
+// request, without a confirmation token, returns one; repeating the request
+// with it deletes the account.
+type DeleteAccountRequest struct {
+	CurrentPassword   string `json:"current_password"` // Not needed by users without a password
+	ConfirmationToken string `json:"confirmation_token"`
+}
+
+// DeleteAccountResponse reports the step of an account deletion
+type DeleteAccountResponse struct {
+	// Set on the first request, to repeat with the confirmation
+	ConfirmationToken string     `json:"confirmation_token,omitempty"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	// Set once confirmed with a grace period: logging in before the account
+	// is deleted cancels the deletion
+	DeleteAfter *time.Time `json:"delete_after,omitempty"`
+	Deleted     bool       `json:"deleted"`
+}
+
+// deletionSignature signs a deletion confirmation for the user until the
+// expiry. It covers the password hash, so changing the password voids it.
+func (s *service) deletionSignature(user *User, expiresAt int64) string {
+	// A separate key keeps confirmations from being usable as anything else
+	key := hmac.New(sha256.New, []byte(s.jwtSecret))
+	key.Write([]byte("account-deletion"))
+
+	mac := hmac.New(sha256.New, key.Sum(nil))
+	mac.Write([]byte(user.ID.String() + "." + strconv.FormatInt(expiresAt, 10) + "." + user.PasswordHash))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// checkConfirmation verifies a token made by DeleteAccount for the user
+func (s *service) checkConfirmation(user *User, token string) bool {
+	expiry, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || time.Now().Unix() >= expiresAt {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(s.deletionSignature(user, expiresAt)))
+}
+
+// DeleteAccount deletes the user's account with everything they saved. The
+// first call returns a confirmation token; calling again with it deletes the
+// account, or schedules the deletion when a grace period is configured.
+func (s *service) DeleteAccount(userID uuid.UUID, reauth Reauthentication, confirmationToken string) (*DeleteAccountResponse, error) {
+	user, err := s.reauthenticate(userID, reauth)
+	if err != nil {
+		return nil, err
+	}
+
+	if confirmationToken == "" {
+		expiresAt := time.Now().Add(deletionConfirmationTTL).Truncate(time.Second)
+		token := strconv.FormatInt(expiresAt.Unix(), 10) + "." + s.deletionSignature(user, expiresAt.Unix())
+		return &DeleteAccountResponse{ConfirmationToken: token, ExpiresAt: &expiresAt}, nil
+	}
+	if !s.checkConfirmation(user, confirmationToken) {
+		return nil, ErrInvalidConfirmation
+	}
+
+	if s.deletionGrace > 0 {
+		deleteAfter := time.Now().Add(s.deletionGrace)
+		if err := s.repo.ScheduleDeletion(userID, &deleteAfter); err != nil {
+			return nil, err
+		}
+		s.InvalidateUser(userID)
+
+		s.audit.Record(&AuditEvent{Type: AuditDeletionScheduled, UserID: userID, Email: user.Email, Details: "deleting after " + deleteAfter.Format(time.RFC3339)})
+		return &DeleteAccountResponse{DeleteAfter: &deleteAfter}, nil
+	}
+
+	if err := s.purgeAccount(userID); err != nil {
+		return nil, err
+	}
+	return &DeleteAccountResponse{Deleted: true}, nil
+}
+
+// cancelDeletion cancels the scheduled deletion of a user logging in again
+func (s *service) cancelDeletion(user *User) error {
+	if user.DeleteAfter == nil {
+		return nil
+	}
+
+	if err := s.repo.ScheduleDeletion(user.ID, nil); err != nil {
+		return err
+	}
+	s.InvalidateUser(user.ID)
+	user.DeleteAfter = nil
+
+	s.audit.Record(&AuditEvent{Type: AuditDeletionCancelled, UserID: user.ID, Email: user.Email, Details: "cancelled by login"})
+	return nil
+}
+
+// purgeAccount deletes the user and their data, then the objects their
+// articles kept in object storage
+func (s *service) purgeAccount(userID uuid.UUID) error {
+	objectKeys, err := s.repo.DeleteAccount(userID)
+	if err != nil {
+		s.logger.Error("Failed to delete account of user " + userID.String() + ": " + err.Error())
+		return err
+	}
+	s.InvalidateUser(userID)
+
+	// The objects are only reachable through the deleted articles, so a
+	// leftover copy is harmless
+	if s.objects != nil {
+		for _, key := range objectKeys {
+			if err := s.objects.Delete(key); err != nil {
+				s.logger.Error("Failed to delete object " + key + " of user " + userID.String() + ": " + err.Error())
+			}
+		}
+	}
+
+	s.audit.Record(&AuditEvent{Type: AuditAccountDeleted, UserID: userID})
+	return nil
+}
+
+// PurgeDeletedAccounts deletes the accounts whose grace period has passed
+func (s *service) PurgeDeletedAccounts() error {
+	purged := 0
+
+	for {
+		ids, err := s.repo.FindDueDeletions(time.Now(), deletionBatchSize)
+		if err != nil {
+			s.logger.Error("Failed to find accounts to delete: " + err.Error())
+			return err
+		}
+
+		batchPurged := 0
+		for _, id := range ids {
+			if err := s.purgeAccount(id); err != nil {
+				continue
+			}
+			batchPurged++
+		}
+		purged += batchPurged
+
+		// Stop on a short batch, or when failures would return the same accounts again
+		if len(ids) < deletionBatchSize || batchPurged == 0 {
+			break
+		}
+	}
+
+	if purged > 0 {
+		s.logger.Info("Deleted " + utils.IntToString(purged) + " accounts after their grace period")
+	}
+
+	return nil
+}
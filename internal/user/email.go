@@ -0,0 +1,143 @@
+package user
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// emailConfirmationTTL is how long the link confirming a new email works
+const emailConfirmationTTL = 24 * time.Hour
+
+// ErrInvalidEmailConfirmation is returned for wrong, expired or superseded
+// email confirmation tokens
+var ErrInvalidEmailConfirmation = utils.NewValidationError("token", "is invalid or expired")
+
+// EmailSender delivers the links confirming a new email address
+type EmailSender interface {
+	// SendEmailConfirmation sends the token confirming the change to the new
+	// address
+	SendEmailConfirmation(email, token string, expiresAt time.Time) error
+}
+
+// logEmailSender writes confirmations to the log, for deployments without a
+// mail transport
+type logEmailSender struct {
+	logger *logger.Logger
+}
+
+func (s *logEmailSender) SendEmailConfirmation(email, token string, expiresAt time.Time) error {
+	s.logger.Info("Email confirmation for " + email + " (expires " + expiresAt.Format(time.RFC3339) + "): " + token)
+	return nil
+}
+
+// EmailChangeResponse reports an email change waiting for confirmation
+type EmailChangeResponse struct {
+	PendingEmail string    `json:"pending_email"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// ConfirmEmailRequest confirms an email change with the token sent to the
+// new address
+type ConfirmEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// emailSignature signs the confirmation of the user's pending email until the
+// expiry. It covers both addresses, so a newer change or a confirmed one
+// voids it.
+func (s *service) emailSignature(user *User, pendingEmail string, expiresAt int64) string {
+	// A separate key keeps confirmations from being usable as anything else
+	key := hmac.New(sha256.New, []byte(s.jwtSecret))
+	key.Write([]byte("email-change"))
+
+	mac := hmac.New(sha256.New, key.Sum(nil))
+	mac.Write([]byte(user.ID.String() + "." + strconv.FormatInt(expiresAt, 10) + "." + user.Email + "." + pendingEmail))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ChangeEmail records the new email as pending and sends a confirmation to
+// it. The email changes once the confirmation is used, so an account can
+// only take an address its owner controls.
+func (s *service) ChangeEmail(userID uuid.UUID, reauth Reauthentication, email string) (*EmailChangeResponse, error) {
+	email, err := utils.ValidateText("email", email, 255)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.reauthenticate(userID, reauth)
+	if err != nil {
+		return nil, err
+	}
+	if user.Email == email {
+		return nil, utils.NewValidationError("email", "is already the account's email")
+	}
+
+	if existing, _ := s.repo.FindByEmail(email); existing != nil && existing.ID != userID {
+		return nil, ErrAlreadyExists
+	}
+
+	if err := s.repo.SetPendingEmail(userID, &email); err != nil {
+		return nil, err
+	}
+	s.InvalidateUser(userID)
+
+	expiresAt := time.Now().Add(emailConfirmationTTL).Truncate(time.Second)
+	token := user.ID.String() + "." + strconv.FormatInt(expiresAt.Unix(), 10) + "." + s.emailSignature(user, email, expiresAt.Unix())
+	if err := s.emails.SendEmailConfirmation(email, token, expiresAt); err != nil {
+		s.logger.Error("Failed to send email confirmation to user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	return &EmailChangeResponse{PendingEmail: email, ExpiresAt: expiresAt}, nil
+}
+
+// ConfirmEmail switches the user named by the token to their pending email
+func (s *service) ConfirmEmail(token string) (*User, error) {
+	id, rest, _ := strings.Cut(token, ".")
+	expiry, signature, ok := strings.Cut(rest, ".")
+	if !ok {
+		return nil, ErrInvalidEmailConfirmation
+	}
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidEmailConfirmation
+	}
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || time.Now().Unix() >= expiresAt {
+		return nil, ErrInvalidEmailConfirmation
+	}
+
+	user, err := s.repo.FindByID(userID)
+	if err != nil || user.PendingEmail == nil {
+		return nil, ErrInvalidEmailConfirmation
+	}
+	email := *user.PendingEmail
+	if !hmac.Equal([]byte(signature), []byte(s.emailSignature(user, email, expiresAt))) {
+		return nil, ErrInvalidEmailConfirmation
+	}
+
+	// The address may have been taken while the change waited
+	if existing, _ := s.repo.FindByEmail(email); existing != nil && existing.ID != userID {
+		return nil, ErrAlreadyExists
+	}
+
+	if err := s.repo.UpdateEmail(userID, email); err != nil {
+		return nil, err
+	}
+	s.InvalidateUser(userID)
+
+	s.audit.Record(&AuditEvent{Type: AuditEmailChanged, UserID: userID, Email: email, Details: "changed from " + user.Email})
+
+	user.Email = email
+	user.PendingEmail = nil
+	user.UpdatedAt = time.Now()
+	return user, nil
+}
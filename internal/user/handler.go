@@ -1,21 +1,27 @@
 package user
 
 import (
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/dustin/articles-backend/internal/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // Handler handles HTTP requests for user operations
 type Handler struct {
 	service Service
+	guard   *LoginGuard
 }
 
-// NewHandler creates a new user handler
-func NewHandler(service Service) *Handler {
+// NewHandler creates a new user handler. A nil guard leaves logins unlimited.
+func NewHandler(service Service, guard *LoginGuard) *Handler {
 	return &Handler{
 		service: service,
+		guard:   guard,
 	}
 }
 
@@ -29,11 +35,7 @@ func (h *Handler) SignUp(c *gin.Context) {
 
 	user, err := h.service.SignUp(req.Email, req.Password)
 	if err != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			c.JSON(http.StatusConflict, gin.H{"error": "User already exists"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		}
+		utils.RespondError(c, err, "Internal server error")
 		return
 	}
 
@@ -48,13 +50,67 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.service.Login(req.Email, req.Password)
+	if h.guard != nil {
+		if err := h.guard.Check(c.ClientIP(), req.Email); err != nil {
+			utils.RespondError(c, err, "Internal server error")
+			return
+		}
+	}
+
+	response, err := h.service.Login(req.Email, req.Password, clientOf(c))
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			if h.guard != nil {
+				h.guard.Failed(c.ClientIP(), req.Email)
+			}
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	if h.guard != nil {
+		h.guard.Succeeded(c.ClientIP(), req.Email)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Refresh handles exchanging a refresh token for new tokens
+func (h *Handler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.service.Refresh(req.RefreshToken, clientOf(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		if errors.Is(err, ErrInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		} else {
+			utils.RespondError(c, err, "Failed to refresh token")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Logout handles revoking a refresh token
+func (h *Handler) Logout(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	if err := h.service.Logout(req.RefreshToken); err != nil {
+		utils.RespondError(c, err, "Failed to log out")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
 }
 
 // GetMe returns current user information
@@ -75,8 +131,281 @@ func (h *Handler) GetMe(c *gin.Context) {
 	c.JSON(http.StatusOK, user.ToResponse())
 }
 
-// AuthMiddleware creates middleware for JWT authentication
-func (h *Handler) AuthMiddleware() gin.HandlerFunc {
+// ChangePassword handles changing the current user's password. Every session
+// of the user ends; the response carries tokens for a new one.
+func (h *Handler) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	response, err := h.service.ChangePassword(userID, reauthenticationOf(c, req.CurrentPassword), req.NewPassword, clientOf(c))
+	if err != nil {
+		respondReauthenticationError(c, err, "Failed to change password")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ChangeEmail handles changing the current user's email. The change waits for
+// the confirmation sent to the new address.
+func (h *Handler) ChangeEmail(c *gin.Context) {
+	var req ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	response, err := h.service.ChangeEmail(userID, reauthenticationOf(c, req.CurrentPassword), req.Email)
+	if err != nil {
+		respondReauthenticationError(c, err, "Failed to change email")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
+
+// ConfirmEmail handles confirming an email change with the token sent to the
+// new address
+func (h *Handler) ConfirmEmail(c *gin.Context) {
+	var req ConfirmEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.service.ConfirmEmail(req.Token)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to confirm email")
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// DeleteAccount handles deleting the current user's account in two steps:
+// the first request returns a confirmation token to send with the second
+func (h *Handler) DeleteAccount(c *gin.Context) {
+	var req DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	response, err := h.service.DeleteAccount(userID, reauthenticationOf(c, req.CurrentPassword), req.ConfirmationToken)
+	if err != nil {
+		respondReauthenticationError(c, err, "Failed to delete account")
+		return
+	}
+
+	switch {
+	case response.Deleted:
+		c.Status(http.StatusNoContent)
+	case response.DeleteAfter != nil:
+		c.JSON(http.StatusAccepted, response)
+	default:
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// CreateToken issues a token restricted to the requested scopes
+func (h *Handler) CreateToken(c *gin.Context) {
+	var req CreateTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	var ttl time.Duration
+	if req.ExpiresIn != "" {
+		ttl, err = time.ParseDuration(req.ExpiresIn)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expires_in duration"})
+			return
+		}
+	}
+
+	token, tokenID, err := h.service.IssueScopedToken(userID, utils.GetScopesFromContext(c), req.Scopes, ttl)
+	if err != nil {
+		// The token's own user no longer exists
+		if errors.Is(err, ErrNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		} else {
+			utils.RespondError(c, err, "Failed to issue token")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"token": token, "token_id": tokenID, "scopes": req.Scopes})
+}
+
+// Impersonate issues a short-lived read-only token acting as another user
+func (h *Handler) Impersonate(c *gin.Context) {
+	var req ImpersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	// Impersonation tokens cannot be used to start another impersonation
+	if _, impersonating := c.Get("impersonator_id"); impersonating {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot impersonate while impersonating"})
+		return
+	}
+
+	var ttl time.Duration
+	if req.ExpiresIn != "" {
+		ttl, err = time.ParseDuration(req.ExpiresIn)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expires_in duration"})
+			return
+		}
+	}
+
+	token, err := h.service.Impersonate(adminID, req.UserID, ttl)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to issue impersonation token")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"token": token, "user_id": req.UserID, "scopes": []string{utils.ScopeRead}})
+}
+
+// SetRole handles an admin changing a user's role
+func (h *Handler) SetRole(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req SetRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	user, err := h.service.SetRole(adminID, userID, req.Role)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to set role")
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// ListSessions handles listing the current user's active logins
+func (h *Handler) ListSessions(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	// API tokens belong to no session, so none is current
+	currentSessionID, _ := uuid.Parse(c.GetString("session_id"))
+	sessions, err := h.service.ListSessions(userID, currentSessionID)
+	if err != nil {
+		utils.RespondError(c, err, "Failed to list sessions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// RevokeSession handles ending one of the current user's logins
+func (h *Handler) RevokeSession(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	if err := h.service.RevokeSession(userID, sessionID); err != nil {
+		utils.RespondError(c, err, "Failed to revoke session")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// clientOf describes the device making the request
+func clientOf(c *gin.Context) Client {
+	return Client{UserAgent: c.Request.UserAgent(), IPAddress: c.ClientIP()}
+}
+
+// reauthenticationOf pairs the password given with a request with when the
+// request's session signed in
+func reauthenticationOf(c *gin.Context, password string) Reauthentication {
+	reauth := Reauthentication{Password: password}
+	if signedInAt, ok := c.Get("signed_in_at"); ok {
+		reauth.SignedInAt, _ = signedInAt.(time.Time)
+	}
+	return reauth
+}
+
+// respondReauthenticationError responds to a change the user failed to
+// confirm, or to another error
+func respondReauthenticationError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrInvalidCredentials):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Current password is incorrect"})
+	case errors.Is(err, ErrReauthenticationRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Sign in again to confirm this change"})
+	default:
+		utils.RespondError(c, err, message)
+	}
+}
+
+// AuthMiddleware creates middleware for JWT authentication. It stores the
+// user, their role and the token's scopes in the context, enforces the scope
+// required by the request method and, when usage is set, counts the request
+// against the user's daily quota.
+func (h *Handler) AuthMiddleware(usage UsageRecorder) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -85,39 +414,115 @@ func (h *Handler) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Check Bearer format
-		tokenParts := strings.Split(authHeader, " ")
-		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+		// Check Bearer format; the scheme is case-insensitive (RFC 6750)
+		tokenParts := strings.Fields(authHeader)
+		if len(tokenParts) != 2 || !strings.EqualFold(tokenParts[0], "Bearer") {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization format"})
 			c.Abort()
 			return
 		}
 
-		token := tokenParts[1]
-		user, err := h.service.ValidateToken(token)
+		// Tokens of deleted users, and those issued before a password change, are revoked
+		user, claims, err := h.service.Authenticate(tokenParts[1])
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
 
-		// Store user in context for handlers
+		// Store user in context for handlers. Roles come from the user rather
+		// than the token, so changes apply at once.
 		c.Set("user", user)
 		c.Set("user_id", user.ID)
+		c.Set("email", user.Email)
+		c.Set("role", user.Role)
+
+		// Tokens without the claim are full user tokens
+		scopes := claims.Scopes
+		if len(scopes) == 0 {
+			scopes = utils.DefaultScopes
+		}
+		c.Set("scopes", scopes)
+
+		// Mark impersonated requests so handlers and logs can tell them apart
+		if claims.ImpersonatorID != "" {
+			c.Set("impersonator_id", claims.ImpersonatorID)
+		}
+		// Scoped API tokens carry an ID used to break down usage per key
+		if claims.ID != "" {
+			c.Set("token_id", claims.ID)
+			h.service.RecordAPIKeyUse(user.ID, claims.ID, clientOf(c))
+		}
+		// Tokens issued at login name the session they belong to
+		if claims.SessionID != "" {
+			c.Set("session_id", claims.SessionID)
+		}
+		if claims.AuthTime != nil && claims.ImpersonatorID == "" {
+			c.Set("signed_in_at", claims.AuthTime.Time)
+		}
+
+		// Enforce the scope required by the request method
+		requiredScope := utils.ScopeForMethod(c.Request.Method)
+		if !utils.HasScope(scopes, requiredScope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient scope", "required_scope": requiredScope})
+			c.Abort()
+			return
+		}
+
+		// Support staff acting as the user are not counted. Accounting errors
+		// are logged by the recorder and fail open.
+		if usage != nil && claims.ImpersonatorID == "" {
+			if allowed, _ := usage.Record(user.ID, claims.ID); !allowed {
+				c.JSON(http.StatusTooManyRequests, gin.H{"error": "Daily request quota exceeded"})
+				c.Abort()
+				return
+			}
+		}
+
 		c.Next()
 	}
 }
 
-// RegisterRoutes registers all user routes
-func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+// LoginRateLimit creates middleware limiting login and signup requests per
+// client IP
+func (h *Handler) LoginRateLimit() gin.HandlerFunc {
+	if h.guard == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return h.guard.RateLimit()
+}
+
+// RegisterRoutes registers all user routes. rateLimit guards the admin-only
+// routes and runs after the role and scope checks so limits apply per
+// authenticated admin.
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, rateLimit gin.HandlerFunc) {
 	// Public routes
-	router.POST("/signup", h.SignUp)
-	router.POST("/login", h.Login)
+	router.POST("/signup", h.LoginRateLimit(), h.SignUp)
+	router.POST("/login", h.LoginRateLimit(), h.Login)
+	// Refresh tokens prove the session themselves, so no access token is needed
+	router.POST("/auth/refresh", h.Refresh)
+	router.POST("/auth/logout", h.Logout)
+	// The token sent to the new address proves the change, from any browser
+	router.POST("/users/email/confirm", h.ConfirmEmail)
 
 	// Protected routes
 	protected := router.Group("/users")
 	protected.Use(authMiddleware)
 	{
 		protected.GET("/me", h.GetMe)
+		protected.PUT("/me/password", h.ChangePassword)
+		protected.PUT("/me/email", h.ChangeEmail)
+		protected.DELETE("/me", h.DeleteAccount)
+		protected.GET("/me/sessions", h.ListSessions)
+		protected.DELETE("/me/sessions/:id", h.RevokeSession)
+		protected.POST("/tokens", h.CreateToken)
+	}
+
+	// Admin-only routes
+	admin := router.Group("/admin")
+	admin.Use(authMiddleware, utils.RequireRole(utils.RoleAdmin), utils.RequireScope(utils.ScopeAdmin), rateLimit)
+	{
+		admin.POST("/impersonate", h.Impersonate)
+		admin.PUT("/users/:id/role", h.SetRole)
 	}
 }
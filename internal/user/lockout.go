@@ -0,0 +1,234 @@
+package user
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// failureSweepThreshold is the number of tracked keys above which expired
+// failure counts are dropped
+const failureSweepThreshold = 10000
+
+// FailureStore counts failed logins per key. The counts of MemoryFailureStore
+// live in one instance; a shared store such as Redis (INCR with EXPIRE, GET
+// with TTL, DEL) makes lockouts hold across instances.
+type FailureStore interface {
+	// Increment counts a failure of key and keeps the count for ttl from now
+	Increment(key string, ttl time.Duration) (int, error)
+	// Get returns the failures of key and how long they are kept
+	Get(key string) (count int, ttl time.Duration, err error)
+	// Delete forgets the failures of key
+	Delete(key string) error
+}
+
+// LoginGuard limits login and signup requests per client IP, and locks out
+// accounts and IPs after repeated failed logins until they have been quiet for
+// the lockout duration
+type LoginGuard struct {
+	store         FailureStore
+	limiter       *utils.RateLimiter
+	maxFailures   int
+	maxIPFailures int
+	lockout       time.Duration
+	audit         AuditRecorder
+	logger        *logger.Logger
+}
+
+// NewLoginGuard creates a login guard counting failures in store. Lockouts and
+// blocked logins go to audit, or only to the audit logger when it is nil.
+func NewLoginGuard(cfg *config.LoginConfig, store FailureStore, audit AuditRecorder, log *logger.Logger) (*LoginGuard, error) {
+	positive := func(name, value string, fallback int) (int, error) {
+		if value == "" {
+			return fallback, nil
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return 0, fmt.Errorf("invalid login %s '%s': must be a positive integer", name, value)
+		}
+		return parsed, nil
+	}
+	duration := func(name, value string, fallback time.Duration) (time.Duration, error) {
+		if value == "" {
+			return fallback, nil
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return 0, fmt.Errorf("invalid login %s '%s': must be a positive duration", name, value)
+		}
+		return parsed, nil
+	}
+
+	rateLimit, err := positive("rate limit", cfg.RateLimit, 10)
+	if err != nil {
+		return nil, err
+	}
+	rateWindow, err := duration("rate window", cfg.RateWindow, time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	maxFailures, err := positive("max failures", cfg.MaxFailures, 5)
+	if err != nil {
+		return nil, err
+	}
+	maxIPFailures, err := positive("max IP failures", cfg.MaxIPFailures, 20)
+	if err != nil {
+		return nil, err
+	}
+	lockout, err := duration("lockout", cfg.Lockout, 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginGuard{
+		store:         store,
+		limiter:       utils.NewRateLimiter(rateLimit, rateWindow),
+		maxFailures:   maxFailures,
+		maxIPFailures: maxIPFailures,
+		lockout:       lockout,
+		audit:         newAuditRecorder(audit, log),
+		logger:        log.WithComponent("login-guard"),
+	}, nil
+}
+
+// RateLimit creates middleware limiting requests per client IP. Place it on
+// the public login and signup routes.
+func (g *LoginGuard) RateLimit() gin.HandlerFunc {
+	return g.limiter.Middleware()
+}
+
+// Check returns a rate limit error, retryable once the lockout ends, while the
+// account or the IP is locked out. Store errors fail open so an unavailable
+// store does not stop every login.
+func (g *LoginGuard) Check(ip, email string) error {
+	for _, key := range g.keys(ip, email) {
+		count, ttl, err := g.store.Get(key.name)
+		if err != nil {
+			g.logger.Error("Failed to read login failures: " + err.Error())
+			continue
+		}
+		if count >= key.max {
+			g.record(AuditLoginBlocked, ip, email, key.name+" is locked out")
+			return utils.NewRateLimitError("too many failed login attempts", ttl)
+		}
+	}
+	return nil
+}
+
+// Failed counts a failed login of the account from the IP, locking either out
+// once it reaches its limit
+func (g *LoginGuard) Failed(ip, email string) {
+	for _, key := range g.keys(ip, email) {
+		count, err := g.store.Increment(key.name, g.lockout)
+		if err != nil {
+			g.logger.Error("Failed to count login failure: " + err.Error())
+			continue
+		}
+		if count == key.max {
+			g.record(AuditLoginLockedOut, ip, email, key.name+" locked out for "+g.lockout.String()+" after "+strconv.Itoa(count)+" failed logins")
+		}
+	}
+}
+
+// Succeeded forgets the failed logins of the account. Failures of the IP are
+// kept, so one valid account does not reset guessing at others.
+func (g *LoginGuard) Succeeded(ip, email string) {
+	if err := g.store.Delete(g.keys(ip, email)[0].name); err != nil {
+		g.logger.Error("Failed to reset login failures: " + err.Error())
+	}
+}
+
+// lockoutKey is a failure counter key with the failures that lock it out
+type lockoutKey struct {
+	name string
+	max  int
+}
+
+// keys returns the failure counters of the account and the IP
+func (g *LoginGuard) keys(ip, email string) []lockoutKey {
+	return []lockoutKey{
+		{name: "account:" + normalizeEmail(email), max: g.maxFailures},
+		{name: "ip:" + ip, max: g.maxIPFailures},
+	}
+}
+
+// record records a lockout event of the client IP and the email
+func (g *LoginGuard) record(eventType, ip, email, details string) {
+	g.audit.Record(&AuditEvent{Type: eventType, Email: normalizeEmail(email), Client: Client{IPAddress: ip}, Details: details})
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// MemoryFailureStore keeps failure counts in memory
+type MemoryFailureStore struct {
+	now func() time.Time
+
+	mu       sync.Mutex
+	failures map[string]*failureCount
+}
+
+// failureCount is the failures of one key and when they are forgotten
+type failureCount struct {
+	count     int
+	expiresAt time.Time
+}
+
+// NewMemoryFailureStore creates an empty in-memory failure store
+func NewMemoryFailureStore() *MemoryFailureStore {
+	return &MemoryFailureStore{now: time.Now, failures: make(map[string]*failureCount)}
+}
+
+func (s *MemoryFailureStore) Increment(key string, ttl time.Duration) (int, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Drop expired counts once the map grows, as the rate limiter does
+	if len(s.failures) > failureSweepThreshold {
+		for k, failure := range s.failures {
+			if !now.Before(failure.expiresAt) {
+				delete(s.failures, k)
+			}
+		}
+	}
+
+	failure, exists := s.failures[key]
+	if !exists || !now.Before(failure.expiresAt) {
+		failure = &failureCount{}
+		s.failures[key] = failure
+	}
+	failure.count++
+	failure.expiresAt = now.Add(ttl)
+	return failure.count, nil
+}
+
+func (s *MemoryFailureStore) Get(key string) (int, time.Duration, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failure, exists := s.failures[key]
+	if !exists || !now.Before(failure.expiresAt) {
+		return 0, 0, nil
+	}
+	return failure.count, failure.expiresAt.Sub(now), nil
+}
+
+func (s *MemoryFailureStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.failures, key)
+	return nil
+}
@@ -0,0 +1,104 @@
+package user
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// passwordHasher hashes passwords with bcrypt at a cost chosen at startup
+type passwordHasher struct {
+	cost int
+}
+
+// newPasswordHasher parses the hashing settings and benchmarks the configured
+// cost on this host. A cost whose hash takes longer than the target latency
+// risks login timeouts on small instances: it is reported, and lowered down to
+// the minimum cost when auto-tuning is enabled.
+func newPasswordHasher(cfg *config.PasswordConfig, log *logger.Logger) (*passwordHasher, error) {
+	cost := bcrypt.DefaultCost
+	if cfg != nil && cfg.HashCost != "" {
+		parsed, err := strconv.Atoi(cfg.HashCost)
+		if err != nil || parsed < bcrypt.MinCost || parsed > bcrypt.MaxCost {
+			return nil, fmt.Errorf("invalid password hash cost '%s': must be between %d and %d", cfg.HashCost, bcrypt.MinCost, bcrypt.MaxCost)
+		}
+		cost = parsed
+	}
+
+	minCost := 8
+	if cfg != nil && cfg.MinHashCost != "" {
+		parsed, err := strconv.Atoi(cfg.MinHashCost)
+		if err != nil || parsed < bcrypt.MinCost || parsed > bcrypt.MaxCost {
+			return nil, fmt.Errorf("invalid password minimum hash cost '%s': must be between %d and %d", cfg.MinHashCost, bcrypt.MinCost, bcrypt.MaxCost)
+		}
+		minCost = parsed
+	}
+
+	target := 250 * time.Millisecond
+	if cfg != nil && cfg.HashTarget != "" {
+		parsed, err := time.ParseDuration(cfg.HashTarget)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid password hash target '%s': must be a positive duration", cfg.HashTarget)
+		}
+		target = parsed
+	}
+
+	autoTune := false
+	if cfg != nil && cfg.AutoTune != "" {
+		parsed, err := strconv.ParseBool(cfg.AutoTune)
+		if err != nil {
+			return nil, fmt.Errorf("invalid password hash auto-tune value '%s': %v", cfg.AutoTune, err)
+		}
+		autoTune = parsed
+	}
+
+	log = log.WithComponent("password-hasher")
+
+	elapsed, err := benchmarkCost(cost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to benchmark password hashing: %w", err)
+	}
+
+	if elapsed <= target {
+		log.Info("Password hash cost " + strconv.Itoa(cost) + " takes " + elapsed.Round(time.Millisecond).String() + " on this host")
+		return &passwordHasher{cost: cost}, nil
+	}
+
+	if !autoTune {
+		log.Warn("Password hash cost " + strconv.Itoa(cost) + " takes " + elapsed.Round(time.Millisecond).String() + " on this host, above the target of " + target.String() + "; logins may time out. Lower PASSWORD_HASH_COST or set PASSWORD_HASH_AUTOTUNE=true")
+		return &passwordHasher{cost: cost}, nil
+	}
+
+	tuned := tuneCost(cost, minCost, elapsed, target)
+	log.Warn("Password hash cost " + strconv.Itoa(cost) + " takes " + elapsed.Round(time.Millisecond).String() + " on this host, above the target of " + target.String() + "; using cost " + strconv.Itoa(tuned) + " instead")
+	return &passwordHasher{cost: tuned}, nil
+}
+
+// benchmarkCost measures one hash at the given cost
+func benchmarkCost(cost int) (time.Duration, error) {
+	start := time.Now()
+	if _, err := bcrypt.GenerateFromPassword([]byte("benchmark-password"), cost); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+// tuneCost returns the highest cost not below minCost whose estimated hashing
+// time fits the target. Each bcrypt cost step doubles the work, so the time at
+// a lower cost is estimated by halving the measured time per step.
+func tuneCost(cost, minCost int, elapsed, target time.Duration) int {
+	for cost > minCost && elapsed > target {
+		cost--
+		elapsed /= 2
+	}
+	return cost
+}
+
+// Hash returns the bcrypt hash of the password
+func (h *passwordHasher) Hash(password string) ([]byte, error) {
+	return bcrypt.GenerateFromPassword([]byte(password), h.cost)
+}
@@ -0,0 +1,205 @@
+package user
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Errors returned for refresh tokens
+var (
+	// ErrInvalidRefreshToken is returned for unknown, expired or revoked refresh tokens
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenNotFound is returned by Repository.FindRefreshToken for unknown tokens
+	ErrRefreshTokenNotFound = utils.NewNotFoundError("refresh token not found")
+	// ErrRefreshTokenRevoked is returned by Repository.RotateRefreshToken when
+	// the token was revoked, e.g. rotated by a concurrent request
+	ErrRefreshTokenRevoked = errors.New("refresh token revoked")
+)
+
+// refreshTokenBytes is the length of the random part of a refresh token
+const refreshTokenBytes = 32
+
+// maxUserAgentLength is the longest user agent stored for a session
+const maxUserAgentLength = 512
+
+// RefreshToken is the stored record of a refresh token. Each refresh replaces
+// the token with a new one of the same family; presenting a replaced token
+// again means it leaked, so the whole family is revoked.
+type RefreshToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	FamilyID  uuid.UUID  `gorm:"type:uuid;not null;index"`     // Shared by the tokens rotated from one login
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex"` // SHA-256 of the token, which is never stored
+	ExpiresAt time.Time  `gorm:"not null;index"`
+	RevokedAt *time.Time // Set once the token was rotated or revoked
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+
+	// The client the token was issued to, describing the session
+	UserAgent  string    `gorm:"size:512"`
+	IPAddress  string    `gorm:"size:45"`
+	SignedInAt time.Time // When the family's login happened, kept across rotations
+}
+
+// TableName returns the table name for GORM
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// Client is the device a session is started or refreshed from
+type Client struct {
+	UserAgent string
+	IPAddress string
+}
+
+// TokenResponse is a short-lived access token with the refresh token that
+// renews it
+type TokenResponse struct {
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"` // When the access token expires
+}
+
+// RefreshRequest presents a refresh token, to renew or revoke it
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// hashRefreshToken returns the stored form of a refresh token
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newRefreshToken generates a refresh token of the family for the user,
+// returning the token and its record
+func (s *service) newRefreshToken(userID, familyID uuid.UUID, client Client, signedInAt time.Time) (string, *RefreshToken, error) {
+	buf := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	return token, &RefreshToken{
+		ID:         uuid.New(),
+		UserID:     userID,
+		FamilyID:   familyID,
+		TokenHash:  hashRefreshToken(token),
+		ExpiresAt:  time.Now().Add(s.refreshExpiry),
+		UserAgent:  truncate(client.UserAgent, maxUserAgentLength),
+		IPAddress:  client.IPAddress,
+		SignedInAt: signedInAt,
+	}, nil
+}
+
+// issueTokens signs an access token for the user and pairs it with the
+// refresh token of the session
+func (s *service) issueTokens(user *User, refreshToken string, session *RefreshToken) (*TokenResponse, error) {
+	expiresAt := time.Now().Add(s.accessExpiry)
+	token, err := s.signClaims(user, utils.ScopesForRole(user.Role), s.accessExpiry, "", "", session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenResponse{Token: token, RefreshToken: refreshToken, ExpiresAt: expiresAt}, nil
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. The presented token stops working; presenting it again revokes every
+// token rotated from the same login.
+func (s *service) Refresh(refreshToken string, client Client) (*TokenResponse, error) {
+	stored, err := s.repo.FindRefreshToken(hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, ErrRefreshTokenNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if stored.RevokedAt != nil {
+		s.revokeReused(stored)
+		return nil, ErrInvalidRefreshToken
+	}
+	if !time.Now().Before(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.repo.FindByID(stored.UserID)
+	if err != nil || user.DeleteAfter != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	next, record, err := s.newRefreshToken(stored.UserID, stored.FamilyID, client, stored.SignedInAt)
+	if err != nil {
+		s.logger.Error("Failed to generate refresh token for user " + stored.UserID.String() + ": " + err.Error())
+		return nil, err
+	}
+	if err := s.repo.RotateRefreshToken(stored.ID, record); err != nil {
+		// Another request rotated the token first, so it was presented twice
+		if errors.Is(err, ErrRefreshTokenRevoked) {
+			s.revokeReused(stored)
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	response, err := s.issueTokens(user, next, record)
+	if err != nil {
+		s.logger.Error("Failed to generate JWT token for user " + user.ID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// revokeReused revokes the family of a refresh token that was presented
+// after it had been rotated or revoked
+func (s *service) revokeReused(stored *RefreshToken) {
+	s.audit.Record(&AuditEvent{Type: AuditTokenRevoked, UserID: stored.UserID, Details: "revoked refresh token presented again; session " + stored.FamilyID.String() + " revoked"})
+	if err := s.repo.RevokeRefreshTokenFamily(stored.FamilyID); err != nil {
+		s.logger.Error("Failed to revoke refresh token family " + stored.FamilyID.String() + ": " + err.Error())
+	}
+	s.sessions.invalidate(stored.FamilyID)
+}
+
+// Logout revokes the refresh token and every token rotated from the same
+// login. Unknown tokens are ignored, so logging out twice succeeds.
+func (s *service) Logout(refreshToken string) error {
+	stored, err := s.repo.FindRefreshToken(hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, ErrRefreshTokenNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if err := s.repo.RevokeRefreshTokenFamily(stored.FamilyID); err != nil {
+		s.logger.Error("Failed to revoke refresh tokens of user " + stored.UserID.String() + ": " + err.Error())
+		return err
+	}
+	s.sessions.invalidate(stored.FamilyID)
+
+	s.audit.Record(&AuditEvent{Type: AuditTokenRevoked, UserID: stored.UserID, Details: "logged out of session " + stored.FamilyID.String()})
+	return nil
+}
+
+// PurgeRefreshTokens deletes expired refresh tokens, which can no longer be
+// used or reveal reuse
+func (s *service) PurgeRefreshTokens() error {
+	purged, err := s.repo.DeleteExpiredRefreshTokens(time.Now())
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		s.logger.Info("Purged expired refresh tokens: " + strconv.FormatInt(purged, 10))
+	}
+	return nil
+}
@@ -3,31 +3,61 @@ package user
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/auth/signing"
+	"github.com/dustin/articles-backend/internal/utils"
 	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/dustin/articles-backend/pkg/storage"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// maxImpersonationTTL caps how long support staff can act as another user
+const maxImpersonationTTL = time.Hour
+
 // service implements the Service interface
 type service struct {
-	repo      Repository
-	jwtSecret string
-	jwtExpiry time.Duration
-	logger    *logger.Logger
+	repo          Repository
+	jwtSecret     string // Derives the keys of confirmation tokens
+	keys          *signing.KeySet
+	jwtExpiry     time.Duration // Longest lifetime of scoped tokens
+	accessExpiry  time.Duration // Lifetime of access tokens issued with a refresh token
+	refreshExpiry time.Duration
+	deletionGrace time.Duration // Zero deletes accounts at once
+	passwords     *passwordHasher
+	objects       storage.Storage // Nil when object storage is disabled
+	users         *userCache
+	sessions      *sessionCache
+	audit         AuditRecorder
+	emails        EmailSender
+	apiKeyUses    *utils.RateLimiter // Throttles audit records of API key use
+	logger        *logger.Logger
 }
 
-// NewService creates a user service with JWT validation and defaults
-func NewService(cfg *config.JWTConfig, repo Repository, log *logger.Logger) (*service, error) {
+// NewService creates a user service with JWT validation and defaults. It
+// benchmarks password hashing, so call it once at startup. keys signs and
+// verifies tokens; when nil, they are loaded from cfg. accountCfg sets how
+// long deleted accounts wait. objects holds the stored copies of articles
+// deleted with an account, and may be nil. Security
+// events go to audit, or only to the audit logger when it is nil.
+func NewService(cfg *config.JWTConfig, passwordCfg *config.PasswordConfig, accountCfg *config.AccountConfig, keys *signing.KeySet, repo Repository, objects storage.Storage, audit AuditRecorder, log *logger.Logger) (*service, error) {
 	// Set defaults for nil or empty config values
 	secret := "change-me-in-production"
 	if cfg != nil && cfg.Secret != "" {
 		secret = cfg.Secret
 	}
 
+	if keys == nil {
+		var err error
+		if keys, err = signing.NewKeySet(cfg); err != nil {
+			return nil, err
+		}
+	}
+
 	var expiry time.Duration = 24 * time.Hour
 	if cfg != nil && cfg.Expiration != "" {
 		duration, err := time.ParseDuration(cfg.Expiration)
@@ -37,33 +67,99 @@ func NewService(cfg *config.JWTConfig, repo Repository, log *logger.Logger) (*se
 		expiry = duration
 	}
 
+	accessExpiry := 15 * time.Minute
+	if cfg != nil && cfg.AccessExpiration != "" {
+		duration, err := time.ParseDuration(cfg.AccessExpiration)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid JWT access expiration '%s': must be a positive duration", cfg.AccessExpiration)
+		}
+		accessExpiry = duration
+	}
+
+	refreshExpiry := 30 * 24 * time.Hour
+	if cfg != nil && cfg.RefreshExpiration != "" {
+		duration, err := time.ParseDuration(cfg.RefreshExpiration)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid JWT refresh expiration '%s': must be a positive duration", cfg.RefreshExpiration)
+		}
+		refreshExpiry = duration
+	}
+
+	var deletionGrace time.Duration
+	if accountCfg != nil && accountCfg.DeletionGracePeriod != "" {
+		duration, err := time.ParseDuration(accountCfg.DeletionGracePeriod)
+		if err != nil || duration < 0 {
+			return nil, fmt.Errorf("invalid account deletion grace period '%s': must be a non-negative duration", accountCfg.DeletionGracePeriod)
+		}
+		deletionGrace = duration
+	}
+
+	cacheTTL := defaultUserCacheTTL
+	if cfg != nil && cfg.UserCacheTTL != "" {
+		duration, err := time.ParseDuration(cfg.UserCacheTTL)
+		if err != nil || duration < 0 {
+			return nil, fmt.Errorf("invalid user cache TTL '%s': must be a non-negative duration", cfg.UserCacheTTL)
+		}
+		cacheTTL = duration
+	}
+
+	passwords, err := newPasswordHasher(passwordCfg, log)
+	if err != nil {
+		return nil, err
+	}
+
 	return &service{
-		repo:      repo,
-		jwtSecret: secret,
-		jwtExpiry: expiry,
-		logger:    log.WithComponent("user-service"),
+		repo:          repo,
+		jwtSecret:     secret,
+		keys:          keys,
+		jwtExpiry:     expiry,
+		accessExpiry:  accessExpiry,
+		refreshExpiry: refreshExpiry,
+		deletionGrace: deletionGrace,
+		passwords:     passwords,
+		objects:       objects,
+		users:         newUserCache(cacheTTL),
+		sessions:      newSessionCache(cacheTTL),
+		audit:         newAuditRecorder(audit, log),
+		emails:        &logEmailSender{logger: log.WithComponent("user-email")},
+		apiKeyUses:    utils.NewRateLimiter(1, apiKeyUseAuditInterval),
+		logger:        log.WithComponent("user-service"),
 	}, nil
 }
 
 // Claims represents JWT claims
 type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
+	UserID string   `json:"user_id"`
+	Email  string   `json:"email"`
+	Scopes []string `json:"scopes,omitempty"`
+
+	// ImpersonatorID is set when support staff act on behalf of the user
+	ImpersonatorID string `json:"impersonator_id,omitempty"`
+	// SessionID is the login the token was issued for, unset for API tokens
+	SessionID string `json:"sid,omitempty"`
+	// AuthTime is when the session signed in, kept across refreshes
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
 	jwt.RegisteredClaims
 }
 
 func (s *service) SignUp(email, password string) (*User, error) {
 	s.logger.Info("User signup attempt for email: " + email)
 
+	// Validate email before touching the database
+	email, err := utils.ValidateText("email", email, 255)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if user exists
 	existing, _ := s.repo.FindByEmail(email)
 	if existing != nil {
 		s.logger.Info("Signup failed - user already exists: " + email)
-		return nil, errors.New("user already exists")
+		return nil, ErrAlreadyExists
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := s.passwords.Hash(password)
 	if err != nil {
 		s.logger.Error("Failed to hash password for " + email + ": " + err.Error())
 		return nil, err
@@ -74,6 +170,7 @@ func (s *service) SignUp(email, password string) (*User, error) {
 		ID:           uuid.New(),
 		Email:        email,
 		PasswordHash: string(hashedPassword),
+		Role:         utils.RoleUser,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
@@ -89,97 +186,410 @@ func (s *service) SignUp(email, password string) (*User, error) {
 	return user, nil
 }
 
-func (s *service) Login(email, password string) (string, error) {
+func (s *service) Login(email, password string, client Client) (*TokenResponse, error) {
 	s.logger.Info("User login attempt for email: " + email)
 
 	// Find user
 	user, err := s.repo.FindByEmail(email)
 	if err != nil {
 		s.logger.Info("Login failed - user not found: " + email)
-		return "", errors.New("invalid credentials")
+		s.audit.Record(&AuditEvent{Type: AuditLoginFailed, Email: normalizeEmail(email), Client: client, Details: "unknown email"})
+		return nil, ErrInvalidCredentials
 	}
 
 	// Verify password
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
 	if err != nil {
 		s.logger.Info("Login failed - invalid password for " + email + " (ID: " + user.ID.String() + ")")
-		return "", errors.New("invalid credentials")
+		s.audit.Record(&AuditEvent{Type: AuditLoginFailed, UserID: user.ID, Email: user.Email, Client: client, Details: "invalid password"})
+		return nil, ErrInvalidCredentials
 	}
 
-	// Generate JWT token
-	token, err := s.generateToken(user)
+	response, err := s.startSession(user, client)
 	if err != nil {
-		s.logger.Error("Failed to generate JWT token for " + email + " (ID: " + user.ID.String() + "): " + err.Error())
-		return "", err
+		return nil, err
 	}
 
 	s.logger.Info("User logged in successfully: " + email + " (ID: " + user.ID.String() + ")")
+	s.audit.Record(&AuditEvent{Type: AuditLoginSucceeded, UserID: user.ID, Email: user.Email, Client: client})
 
-	return token, nil
+	return response, nil
+}
+
+// StartSession signs in a user authenticated elsewhere, such as by an OAuth
+// provider
+func (s *service) StartSession(userID uuid.UUID, client Client) (*TokenResponse, error) {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.startSession(user, client)
+}
+
+// startSession issues an access token and the first refresh token of a new
+// family for an authenticated user
+func (s *service) startSession(user *User, client Client) (*TokenResponse, error) {
+	// Logging in during the grace period keeps the account
+	if err := s.cancelDeletion(user); err != nil {
+		return nil, err
+	}
+
+	// Each login starts a new family of refresh tokens
+	refreshToken, record, err := s.newRefreshToken(user.ID, uuid.New(), client, time.Now())
+	if err != nil {
+		s.logger.Error("Failed to generate refresh token for user " + user.ID.String() + ": " + err.Error())
+		return nil, err
+	}
+	if err := s.repo.CreateRefreshToken(record); err != nil {
+		return nil, err
+	}
+
+	// Generate JWT token
+	response, err := s.issueTokens(user, refreshToken, record)
+	if err != nil {
+		s.logger.Error("Failed to generate JWT token for user " + user.ID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// FindOrCreateByEmail returns the user with the email, creating one without
+// a password when none exists. Callers must have verified the email, since
+// whoever presents it gets the account.
+func (s *service) FindOrCreateByEmail(email string) (*User, bool, error) {
+	email, err := utils.ValidateText("email", email, 255)
+	if err != nil {
+		return nil, false, err
+	}
+
+	existing, err := s.repo.FindByEmail(email)
+	if err == nil {
+		return existing, false, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, false, err
+	}
+
+	// An empty hash matches no password, so the account can only sign in
+	// the way it was created
+	user := &User{
+		ID:        uuid.New(),
+		Email:     email,
+		Role:      utils.RoleUser,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.repo.Create(user); err != nil {
+		s.logger.Error("Failed to create user " + email + ": " + err.Error())
+		return nil, false, err
+	}
+
+	s.logger.Info("User created without password: " + email + " (ID: " + user.ID.String() + ")")
+
+	return user, true, nil
+}
+
+// reauthenticate loads the user and checks they just proved who they are:
+// with their current password, or for users without one, by having signed in
+// recently
+func (s *service) reauthenticate(userID uuid.UUID, reauth Reauthentication) (*User, error) {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Users created through a sign-in provider have no password to confirm with
+	if user.PasswordHash == "" && reauth.Password == "" {
+		if reauth.SignedInAt.IsZero() || time.Since(reauth.SignedInAt) > reauthenticationWindow {
+			s.logger.Info("Stale sign-in for passwordless user " + userID.String())
+			return nil, ErrReauthenticationRequired
+		}
+		return user, nil
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(reauth.Password)); err != nil {
+		s.logger.Info("Current password mismatch for user " + userID.String())
+		return nil, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+func (s *service) ChangePassword(userID uuid.UUID, reauth Reauthentication, newPassword string, client Client) (*TokenResponse, error) {
+	user, err := s.reauthenticate(userID, reauth)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := s.passwords.Hash(newPassword)
+	if err != nil {
+		s.logger.Error("Failed to hash password for user " + userID.String() + ": " + err.Error())
+		return nil, err
+	}
+
+	// Tokens carry their issue time in whole seconds, so the change is
+	// recorded in whole seconds too; tokens issued from here on stay valid
+	changedAt := time.Now().Truncate(time.Second)
+	if err := s.repo.UpdatePassword(userID, string(hashedPassword), changedAt); err != nil {
+		return nil, err
+	}
+	s.InvalidateUser(userID)
+	user.PasswordHash = string(hashedPassword)
+	user.PasswordChangedAt = &changedAt
+
+	s.audit.Record(&AuditEvent{Type: AuditPasswordChanged, UserID: userID, Email: user.Email, Client: client, Details: "existing sessions revoked"})
+
+	// The client that changed the password keeps a session
+	refreshToken, record, err := s.newRefreshToken(userID, uuid.New(), client, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateRefreshToken(record); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, refreshToken, record)
 }
 
 func (s *service) GetUserByID(id uuid.UUID) (*User, error) {
-	return s.repo.FindByID(id)
+	if user, ok := s.users.get(id); ok {
+		return user, nil
+	}
+
+	user, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	s.users.put(user)
+	return user, nil
+}
+
+// InvalidateUser drops the cached record of a user. Call it after changing a
+// user's password or deleting them, so their tokens stop working at once on
+// this instance rather than once the cache entry expires.
+func (s *service) InvalidateUser(id uuid.UUID) {
+	s.users.invalidate(id)
 }
 
 func (s *service) ValidateToken(tokenString string) (*User, error) {
+	user, _, err := s.Authenticate(tokenString)
+	return user, err
+}
+
+func (s *service) Authenticate(tokenString string) (*User, *Claims, error) {
 	// Parse the token
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(s.jwtSecret), nil
-	})
+	token, err := s.keys.Parse(tokenString, &Claims{})
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Check if token is valid
 	if !token.Valid {
-		return nil, errors.New("invalid token")
+		return nil, nil, errors.New("invalid token")
 	}
 
 	// Extract claims
 	claims, ok := token.Claims.(*Claims)
 	if !ok {
-		return nil, errors.New("invalid token claims")
+		return nil, nil, errors.New("invalid token claims")
 	}
 
 	// Parse user ID
 	userID, err := uuid.Parse(claims.UserID)
 	if err != nil {
-		return nil, errors.New("invalid user ID in token")
+		return nil, nil, errors.New("invalid user ID in token")
+	}
+
+	// Get user, from the cache when it was looked up recently
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, nil, ErrNotFound
+	}
+
+	if user.DeleteAfter != nil {
+		return nil, nil, errScheduledForDeletion
+	}
+
+	// Changing the password ends sessions started before it
+	if user.PasswordChangedAt != nil && (claims.IssuedAt == nil || claims.IssuedAt.Before(*user.PasswordChangedAt)) {
+		return nil, nil, errors.New("token issued before password change")
+	}
+
+	// Access tokens end with the session they were issued for
+	if claims.SessionID != "" {
+		if err := s.checkSession(claims.SessionID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return user, claims, nil
+}
+
+// checkSession fails unless the session is active, from the cache when it
+// was found active recently
+func (s *service) checkSession(sessionID string) error {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return errors.New("invalid session ID in token")
+	}
+	if s.sessions.active(id) {
+		return nil
+	}
+
+	active, err := s.repo.IsSessionActive(id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !active {
+		return errSessionRevoked
+	}
+	s.sessions.put(id)
+	return nil
+}
+
+func (s *service) IssueScopedToken(userID uuid.UUID, callerScopes []string, scopes []string, ttl time.Duration) (string, string, error) {
+	if len(scopes) == 0 {
+		return "", "", utils.NewValidationError("scopes", "at least one scope is required")
+	}
+
+	// A token can never grant more than the token used to request it
+	for _, scope := range scopes {
+		if !utils.IsValidScope(scope) {
+			return "", "", utils.NewValidationError("scopes", "unknown scope '"+scope+"'")
+		}
+		if !utils.HasScope(callerScopes, scope) {
+			return "", "", utils.NewValidationError("scopes", "scope '"+scope+"' exceeds caller permissions")
+		}
+	}
+
+	if ttl <= 0 || ttl > s.jwtExpiry {
+		ttl = s.jwtExpiry
 	}
 
-	// Get user from database
 	user, err := s.repo.FindByID(userID)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return "", "", ErrNotFound
 	}
 
+	// Scoped tokens act as API keys; the token ID keys their usage accounting
+	tokenID := uuid.New().String()
+	token, err := s.signClaims(user, scopes, ttl, "", tokenID, nil)
+	if err != nil {
+		s.logger.Error("Failed to generate scoped token for user " + userID.String() + ": " + err.Error())
+		return "", "", err
+	}
+
+	s.audit.Record(&AuditEvent{Type: AuditAPIKeyIssued, UserID: userID, Email: user.Email, Details: "token " + tokenID + " with scopes " + strings.Join(scopes, ",") + " for " + ttl.String()})
+
+	return token, tokenID, nil
+}
+
+func (s *service) Impersonate(adminID, targetUserID uuid.UUID, ttl time.Duration) (string, error) {
+	if adminID == targetUserID {
+		return "", utils.NewValidationError("user_id", "cannot impersonate yourself")
+	}
+
+	if ttl <= 0 || ttl > maxImpersonationTTL {
+		ttl = maxImpersonationTTL
+	}
+
+	target, err := s.repo.FindByID(targetUserID)
+	if err != nil {
+		return "", ErrNotFound
+	}
+
+	// Impersonation tokens are read-only so support cannot modify user data
+	token, err := s.signClaims(target, []string{utils.ScopeRead}, ttl, adminID.String(), "", nil)
+	if err != nil {
+		s.logger.Error("Failed to generate impersonation token for user " + targetUserID.String() + " by admin " + adminID.String() + ": " + err.Error())
+		return "", err
+	}
+
+	s.audit.Record(&AuditEvent{Type: AuditImpersonation, UserID: targetUserID, ActorID: adminID, Email: target.Email, Details: "token valid for " + ttl.String()})
+
+	return token, nil
+}
+
+func (s *service) SetRole(adminID, userID uuid.UUID, role string) (*User, error) {
+	if !utils.IsValidRole(role) {
+		return nil, utils.NewValidationError("role", "unknown role '"+role+"'")
+	}
+	// Admins cannot lock everyone out by demoting themselves
+	if adminID == userID && role != utils.RoleAdmin {
+		return nil, utils.NewValidationError("user_id", "cannot remove your own admin role")
+	}
+
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		return user, nil
+	}
+
+	if err := s.repo.UpdateRole(userID, role); err != nil {
+		return nil, err
+	}
+	// Cached users would keep the old role for the middleware
+	s.InvalidateUser(userID)
+
+	s.audit.Record(&AuditEvent{Type: AuditRoleChanged, UserID: userID, ActorID: adminID, Email: user.Email, Details: "changed from " + user.Role + " to " + role})
+
+	user.Role = role
+	user.UpdatedAt = time.Now()
 	return user, nil
 }
 
-func (s *service) generateToken(user *User) (string, error) {
+func (s *service) GrantAdmin(emails []string) error {
+	for _, email := range emails {
+		user, err := s.repo.FindByEmail(email)
+		if errors.Is(err, ErrNotFound) {
+			s.logger.Warn("Admin " + email + " is not registered yet")
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if user.Role == utils.RoleAdmin {
+			continue
+		}
+
+		if err := s.repo.UpdateRole(user.ID, utils.RoleAdmin); err != nil {
+			return err
+		}
+		s.InvalidateUser(user.ID)
+		s.audit.Record(&AuditEvent{Type: AuditRoleChanged, UserID: user.ID, Email: user.Email, Details: "admin role granted by configuration"})
+	}
+
+	return nil
+}
+
+// signClaims signs a token for the user. Tokens issued with a refresh token
+// name its session and when the session signed in.
+func (s *service) signClaims(user *User, scopes []string, ttl time.Duration, impersonatorID string, tokenID string, session *RefreshToken) (string, error) {
 	// Create claims
 	claims := Claims{
-		UserID: user.ID.String(),
-		Email:  user.Email,
+		UserID:         user.ID.String(),
+		Email:          user.Email,
+		Scopes:         scopes,
+		ImpersonatorID: impersonatorID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.jwtExpiry)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "articles-backend",
 			Subject:   user.ID.String(),
+			ID:        tokenID,
 		},
 	}
-
-	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign token
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
-	if err != nil {
-		return "", err
+	if session != nil {
+		claims.SessionID = session.FamilyID.String()
+		claims.AuthTime = jwt.NewNumericDate(session.SignedInAt)
 	}
 
-	return tokenString, nil
+	// Sign with the current signing key
+	return s.keys.Sign(claims)
 }
@@ -0,0 +1,81 @@
+package user
+
+import (
+	"errors"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// ErrSessionNotFound is returned for sessions that are unknown, ended or of
+// another user
+var ErrSessionNotFound = utils.NewNotFoundError("session not found")
+
+// errSessionRevoked rejects access tokens of a session that was ended
+var errSessionRevoked = errors.New("session revoked")
+
+// Session is an active login, made of the refresh tokens rotated from it.
+// It is last seen when its refresh token was last rotated, which active
+// clients do once their access token expires.
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"` // As of the last refresh
+	SignedInAt time.Time `json:"signed_in_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"` // Unless refreshed before then
+	Current    bool      `json:"current"`    // The session of the request's token
+}
+
+func (s *service) ListSessions(userID, currentSessionID uuid.UUID) ([]Session, error) {
+	tokens, err := s.repo.FindActiveRefreshTokens(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(tokens))
+	for _, token := range tokens {
+		sessions = append(sessions, Session{
+			ID:         token.FamilyID,
+			UserAgent:  token.UserAgent,
+			IPAddress:  token.IPAddress,
+			SignedInAt: token.SignedInAt,
+			LastSeenAt: token.CreatedAt,
+			ExpiresAt:  token.ExpiresAt,
+			Current:    token.FamilyID == currentSessionID,
+		})
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+
+	return sessions, nil
+}
+
+func (s *service) RevokeSession(userID, sessionID uuid.UUID) error {
+	revoked, err := s.repo.RevokeSession(userID, sessionID)
+	if err != nil {
+		return err
+	}
+	s.sessions.invalidate(sessionID)
+	if !revoked {
+		return ErrSessionNotFound
+	}
+
+	s.audit.Record(&AuditEvent{Type: AuditTokenRevoked, UserID: userID, Details: "session " + sessionID.String() + " revoked"})
+	return nil
+}
+
+// truncate shortens s to at most max bytes without splitting a character
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
@@ -1,19 +1,50 @@
 package user
 
 import (
+	"errors"
 	"time"
 
+	"github.com/dustin/articles-backend/internal/utils"
 	"github.com/google/uuid"
 )
 
+// Errors returned by the user service and repository
+var (
+	ErrNotFound           = utils.NewNotFoundError("user not found")
+	ErrAlreadyExists      = utils.NewConflictError("user already exists")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrReauthenticationRequired is returned when a user without a password
+	// confirms a change long after signing in
+	ErrReauthenticationRequired = errors.New("recent sign-in required")
+)
+
+// reauthenticationWindow is how long after signing in users without a
+// password can confirm sensitive changes
+const reauthenticationWindow = 10 * time.Minute
+
+// Reauthentication confirms a sensitive change. Users with a password give
+// it; users without one need a session that signed in recently.
+type Reauthentication struct {
+	Password   string
+	SignedInAt time.Time // Of the session making the request; zero for other tokens
+}
+
 // User represents a user in the system with optimized GORM tags
 type User struct {
 	ID           uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
 	Email        string    `json:"email" gorm:"uniqueIndex;not null;size:255"`
 	PasswordHash string    `json:"-" gorm:"not null;size:255"`
+	Role         string    `json:"role" gorm:"not null;size:20;default:user"` // utils.RoleUser or utils.RoleAdmin
 	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 
+	// PasswordChangedAt rejects access tokens issued before the last password change
+	PasswordChangedAt *time.Time `json:"-"`
+	// DeleteAfter is set while the account waits for deletion
+	DeleteAfter *time.Time `json:"-" gorm:"index"`
+	// PendingEmail is the address the user changes to once it is confirmed
+	PendingEmail *string `json:"-" gorm:"size:255"`
+
 	// Associations - will be loaded explicitly when needed
 	Articles []Article `json:"articles,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
 	Ratings  []Rating  `json:"ratings,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
@@ -38,14 +69,92 @@ type Repository interface {
 	Create(user *User) error
 	FindByEmail(email string) (*User, error)
 	FindByID(id uuid.UUID) (*User, error)
+	// UpdatePassword stores a new password hash and revokes the user's
+	// refresh tokens in one transaction
+	UpdatePassword(id uuid.UUID, passwordHash string, changedAt time.Time) error
+	// UpdateEmail sets the email and clears the pending one
+	UpdateEmail(id uuid.UUID, email string) error
+	// SetPendingEmail records an email change waiting for confirmation
+	SetPendingEmail(id uuid.UUID, email *string) error
+	UpdateRole(id uuid.UUID, role string) error
+	// ScheduleDeletion sets when the account is deleted and revokes the user's
+	// refresh tokens; nil cancels the deletion
+	ScheduleDeletion(id uuid.UUID, deleteAfter *time.Time) error
+	// FindDueDeletions returns accounts scheduled for deletion before the time
+	FindDueDeletions(before time.Time, limit int) ([]uuid.UUID, error)
+	// DeleteAccount deletes the user with all their data in one transaction,
+	// returning the object storage keys of their articles
+	DeleteAccount(id uuid.UUID) ([]string, error)
+
+	// Refresh tokens, found by the hash of the token
+	CreateRefreshToken(token *RefreshToken) error
+	FindRefreshToken(tokenHash string) (*RefreshToken, error)
+	// RotateRefreshToken revokes the current token and stores its successor
+	// in one transaction, failing with ErrRefreshTokenRevoked when the current
+	// token was already revoked
+	RotateRefreshToken(currentID uuid.UUID, next *RefreshToken) error
+	RevokeRefreshTokenFamily(familyID uuid.UUID) error
+	// FindActiveRefreshTokens returns the unrevoked tokens of the user expiring
+	// after the given time, one per session
+	FindActiveRefreshTokens(userID uuid.UUID, after time.Time) ([]RefreshToken, error)
+	// RevokeSession revokes the tokens of the user's family, reporting whether
+	// any was active
+	RevokeSession(userID, familyID uuid.UUID) (bool, error)
+	// IsSessionActive reports whether the family has an unrevoked token
+	// expiring after the given time
+	IsSessionActive(familyID uuid.UUID, after time.Time) (bool, error)
+	// DeleteExpiredRefreshTokens deletes tokens expired before the given time,
+	// returning how many
+	DeleteExpiredRefreshTokens(before time.Time) (int64, error)
 }
 
 // Service defines the interface for user business logic
 type Service interface {
 	SignUp(email, password string) (*User, error)
-	Login(email, password string) (string, error)
+	// Login returns a short-lived access token and a refresh token
+	Login(email, password string, client Client) (*TokenResponse, error)
+	// Refresh rotates a refresh token, returning new tokens
+	Refresh(refreshToken string, client Client) (*TokenResponse, error)
+	// Logout revokes a refresh token and the tokens rotated from the same login
+	Logout(refreshToken string) error
+	StartSession(userID uuid.UUID, client Client) (*TokenResponse, error)
+	// FindOrCreateByEmail reports whether the user was created
+	FindOrCreateByEmail(email string) (*User, bool, error)
+	PurgeRefreshTokens() error
+	// ChangePassword ends every session of the user and returns tokens for a
+	// new one
+	ChangePassword(userID uuid.UUID, reauth Reauthentication, newPassword string, client Client) (*TokenResponse, error)
+	// ListSessions returns the active logins of the user, marking the current
+	// one
+	ListSessions(userID, currentSessionID uuid.UUID) ([]Session, error)
+	// RevokeSession ends a login of the user, revoking its refresh token and
+	// the access tokens issued for it
+	RevokeSession(userID, sessionID uuid.UUID) error
+	// ChangeEmail sends a confirmation to the new email, which replaces the
+	// current one once ConfirmEmail is called with it
+	ChangeEmail(userID uuid.UUID, reauth Reauthentication, email string) (*EmailChangeResponse, error)
+	ConfirmEmail(token string) (*User, error)
+	DeleteAccount(userID uuid.UUID, reauth Reauthentication, confirmationToken string) (*DeleteAccountResponse, error)
+	PurgeDeletedAccounts() error
 	GetUserByID(id uuid.UUID) (*User, error)
+	InvalidateUser(id uuid.UUID)
 	ValidateToken(tokenString string) (*User, error)
+	// Authenticate validates the token like ValidateToken and also returns its
+	// claims
+	Authenticate(tokenString string) (*User, *Claims, error)
+	IssueScopedToken(userID uuid.UUID, callerScopes []string, scopes []string, ttl time.Duration) (token string, tokenID string, err error)
+	Impersonate(adminID, targetUserID uuid.UUID, ttl time.Duration) (string, error)
+	SetRole(adminID, userID uuid.UUID, role string) (*User, error)
+	// GrantAdmin makes the registered users with the emails admins
+	GrantAdmin(emails []string) error
+	// RecordAPIKeyUse records the use of a scoped token in the audit log
+	RecordAPIKeyUse(userID uuid.UUID, tokenID string, client Client)
+}
+
+// UsageRecorder counts authenticated requests against the user's daily quota
+type UsageRecorder interface {
+	// Record reports whether the request is within the quota
+	Record(userID uuid.UUID, tokenID string) (bool, error)
 }
 
 // CreateUserRequest represents user creation request
@@ -60,22 +169,61 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// ChangePasswordRequest represents a password change, confirmed with the
+// current password. Users without one set their first password after a
+// recent sign-in.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// ChangeEmailRequest represents an email change, confirmed with the current
+// password, or a recent sign-in for users without one
+type ChangeEmailRequest struct {
+	Email           string `json:"email" binding:"required,email"`
+	CurrentPassword string `json:"current_password"`
+}
+
+// CreateTokenRequest represents a request for a token with limited scopes
+type CreateTokenRequest struct {
+	Scopes    []string `json:"scopes" binding:"required,min=1"`
+	ExpiresIn string   `json:"expires_in"`
+}
+
+// SetRoleRequest represents an admin request to change a user's role
+type SetRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// ImpersonateRequest represents an admin request to act as another user
+type ImpersonateRequest struct {
+	UserID    uuid.UUID `json:"user_id" binding:"required"`
+	ExpiresIn string    `json:"expires_in"`
+}
+
 // UserResponse represents user in API responses (without password)
 type UserResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           uuid.UUID `json:"id"`
+	Email        string    `json:"email"`
+	PendingEmail string    `json:"pending_email,omitempty"` // Until confirmed
+	Role         string    `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 // ToResponse converts User to UserResponse
 func (u *User) ToResponse() *UserResponse {
-	return &UserResponse{
+	response := &UserResponse{
 		ID:        u.ID,
 		Email:     u.Email,
+		Role:      u.Role,
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
 	}
+	if u.PendingEmail != nil {
+		response.PendingEmail = *u.PendingEmail
+	}
+	return response
 }
 
 // TableName returns the table name for GORM
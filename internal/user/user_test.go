@@ -1,11 +1,24 @@
 package user
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/dustin/articles-backend/pkg/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestUser(t *testing.T) {
@@ -75,6 +88,848 @@ func TestUser(t *testing.T) {
 	})
 }
 
+func TestTuneCost(t *testing.T) {
+	// 800ms at cost 12 halves per step: 400ms at 11, 200ms at 10
+	assert.Equal(t, 10, tuneCost(12, 8, 800*time.Millisecond, 250*time.Millisecond))
+	assert.Equal(t, 11, tuneCost(12, 11, 800*time.Millisecond, 250*time.Millisecond), "never below the minimum")
+	assert.Equal(t, 12, tuneCost(12, 8, 100*time.Millisecond, 250*time.Millisecond))
+	assert.Equal(t, 6, tuneCost(6, 8, time.Second, 250*time.Millisecond), "a cost below the minimum is kept")
+}
+
+func TestNewPasswordHasher(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	t.Run("Keeps a cost within the target", func(t *testing.T) {
+		hasher, err := newPasswordHasher(&config.PasswordConfig{HashCost: "4", HashTarget: "1m"}, log)
+		require.NoError(t, err)
+		assert.Equal(t, 4, hasher.cost)
+
+		hash, err := hasher.Hash("secret")
+		require.NoError(t, err)
+		assert.NoError(t, bcrypt.CompareHashAndPassword(hash, []byte("secret")))
+	})
+
+	t.Run("Warns without auto-tuning", func(t *testing.T) {
+		hasher, err := newPasswordHasher(&config.PasswordConfig{HashCost: "6", HashTarget: "1ns"}, log)
+		require.NoError(t, err)
+		assert.Equal(t, 6, hasher.cost)
+	})
+
+	t.Run("Auto-tunes down to the minimum", func(t *testing.T) {
+		hasher, err := newPasswordHasher(&config.PasswordConfig{HashCost: "6", HashTarget: "1ns", AutoTune: "true", MinHashCost: "4"}, log)
+		require.NoError(t, err)
+		assert.Equal(t, 4, hasher.cost)
+	})
+
+	t.Run("Invalid settings", func(t *testing.T) {
+		invalid := []*config.PasswordConfig{
+			{HashCost: "3"},
+			{HashCost: "strong"},
+			{MinHashCost: "40"},
+			{HashTarget: "fast"},
+			{AutoTune: "sometimes"},
+		}
+		for _, cfg := range invalid {
+			_, err := newPasswordHasher(cfg, log)
+			assert.Error(t, err)
+		}
+	})
+}
+
+// countingRepository serves one user and counts lookups by ID
+type countingRepository struct {
+	Repository
+	user    *User
+	lookups int
+}
+
+func (r *countingRepository) FindByID(id uuid.UUID) (*User, error) {
+	r.lookups++
+	if r.user == nil || r.user.ID != id {
+		return nil, ErrNotFound
+	}
+	found := *r.user
+	return &found, nil
+}
+
+func TestUserCache(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	newCachedService := func(t *testing.T, ttl string) (*service, *countingRepository, *time.Time) {
+		repo := &countingRepository{user: &User{ID: uuid.New(), Email: "reader@example.com"}}
+		svc, err := NewService(&config.JWTConfig{Secret: "secret", UserCacheTTL: ttl}, &config.PasswordConfig{HashCost: "4", HashTarget: "1m"}, nil, nil, repo, nil, nil, log)
+		require.NoError(t, err)
+		now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		svc.users.now = func() time.Time { return now }
+		return svc, repo, &now
+	}
+
+	t.Run("Token validation reuses recent lookups", func(t *testing.T) {
+		svc, repo, now := newCachedService(t, "30s")
+		token, err := svc.signClaims(repo.user, nil, time.Hour, "", "", nil)
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			user, err := svc.ValidateToken(token)
+			require.NoError(t, err)
+			assert.Equal(t, repo.user.ID, user.ID)
+		}
+		assert.Equal(t, 1, repo.lookups)
+
+		*now = now.Add(30 * time.Second)
+		_, err = svc.ValidateToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, 2, repo.lookups, "expired entries are looked up again")
+	})
+
+	t.Run("Invalidation drops deleted users", func(t *testing.T) {
+		svc, repo, _ := newCachedService(t, "30s")
+		id := repo.user.ID
+		_, err := svc.GetUserByID(id)
+		require.NoError(t, err)
+
+		repo.user = nil
+		_, err = svc.GetUserByID(id)
+		assert.NoError(t, err, "still cached until invalidated")
+
+		svc.InvalidateUser(id)
+		_, err = svc.GetUserByID(id)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Equal(t, 2, repo.lookups)
+	})
+
+	t.Run("Cached users are copies", func(t *testing.T) {
+		svc, repo, _ := newCachedService(t, "30s")
+		user, err := svc.GetUserByID(repo.user.ID)
+		require.NoError(t, err)
+		user.Email = "changed@example.com"
+
+		cached, err := svc.GetUserByID(repo.user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "reader@example.com", cached.Email)
+	})
+
+	t.Run("Zero TTL disables the cache", func(t *testing.T) {
+		svc, repo, _ := newCachedService(t, "0")
+		for i := 0; i < 2; i++ {
+			_, err := svc.GetUserByID(repo.user.ID)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, repo.lookups)
+	})
+
+	t.Run("Invalid TTL", func(t *testing.T) {
+		_, err := NewService(&config.JWTConfig{UserCacheTTL: "-1s"}, &config.PasswordConfig{HashCost: "4", HashTarget: "1m"}, nil, nil, &countingRepository{}, nil, nil, log)
+		assert.Error(t, err)
+	})
+}
+
+// tokenRepository serves one user and keeps refresh tokens in memory
+type tokenRepository struct {
+	Repository
+	user       *User
+	tokens     map[uuid.UUID]*RefreshToken
+	objectKeys []string // Of the user's articles
+	deleted    bool
+}
+
+// Create replaces the repository's user
+func (r *tokenRepository) Create(user *User) error {
+	r.user, r.deleted = user, false
+	return nil
+}
+
+func (r *tokenRepository) FindByEmail(email string) (*User, error) {
+	if r.deleted || r.user.Email != email {
+		return nil, ErrNotFound
+	}
+	return r.user, nil
+}
+
+func (r *tokenRepository) FindByID(id uuid.UUID) (*User, error) {
+	if r.deleted || r.user.ID != id {
+		return nil, ErrNotFound
+	}
+	return r.user, nil
+}
+
+func (r *tokenRepository) UpdatePassword(id uuid.UUID, passwordHash string, changedAt time.Time) error {
+	r.user.PasswordHash = passwordHash
+	r.user.PasswordChangedAt = &changedAt
+	for _, token := range r.tokens {
+		if token.UserID == id && token.RevokedAt == nil {
+			token.RevokedAt = &changedAt
+		}
+	}
+	return nil
+}
+
+func (r *tokenRepository) UpdateEmail(id uuid.UUID, email string) error {
+	r.user.Email = email
+	r.user.PendingEmail = nil
+	return nil
+}
+
+func (r *tokenRepository) SetPendingEmail(id uuid.UUID, email *string) error {
+	r.user.PendingEmail = email
+	return nil
+}
+
+func (r *tokenRepository) UpdateRole(id uuid.UUID, role string) error {
+	r.user.Role = role
+	return nil
+}
+
+func (r *tokenRepository) ScheduleDeletion(id uuid.UUID, deleteAfter *time.Time) error {
+	r.user.DeleteAfter = deleteAfter
+	if deleteAfter != nil {
+		r.revokeAll(id)
+	}
+	return nil
+}
+
+// revokeAll revokes every refresh token of the user
+func (r *tokenRepository) revokeAll(userID uuid.UUID) {
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+}
+
+func (r *tokenRepository) FindDueDeletions(before time.Time, limit int) ([]uuid.UUID, error) {
+	if r.deleted || r.user.DeleteAfter == nil || !r.user.DeleteAfter.Before(before) {
+		return nil, nil
+	}
+	return []uuid.UUID{r.user.ID}, nil
+}
+
+func (r *tokenRepository) DeleteAccount(id uuid.UUID) ([]string, error) {
+	if r.deleted || r.user.ID != id {
+		return nil, ErrNotFound
+	}
+	r.deleted = true
+	r.tokens = make(map[uuid.UUID]*RefreshToken)
+	return r.objectKeys, nil
+}
+
+func (r *tokenRepository) CreateRefreshToken(token *RefreshToken) error {
+	token.CreatedAt = time.Now()
+	r.tokens[token.ID] = token
+	return nil
+}
+
+func (r *tokenRepository) FindRefreshToken(tokenHash string) (*RefreshToken, error) {
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			found := *token
+			return &found, nil
+		}
+	}
+	return nil, ErrRefreshTokenNotFound
+}
+
+func (r *tokenRepository) RotateRefreshToken(currentID uuid.UUID, next *RefreshToken) error {
+	current := r.tokens[currentID]
+	if current == nil || current.RevokedAt != nil {
+		return ErrRefreshTokenRevoked
+	}
+	now := time.Now()
+	current.RevokedAt = &now
+	next.CreatedAt = now
+	r.tokens[next.ID] = next
+	return nil
+}
+
+func (r *tokenRepository) RevokeRefreshTokenFamily(familyID uuid.UUID) error {
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *tokenRepository) FindActiveRefreshTokens(userID uuid.UUID, after time.Time) ([]RefreshToken, error) {
+	var tokens []RefreshToken
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.RevokedAt == nil && token.ExpiresAt.After(after) {
+			tokens = append(tokens, *token)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *tokenRepository) RevokeSession(userID, familyID uuid.UUID) (bool, error) {
+	now := time.Now()
+	revoked := false
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.FamilyID == familyID && token.RevokedAt == nil && token.ExpiresAt.After(now) {
+			token.RevokedAt = &now
+			revoked = true
+		}
+	}
+	return revoked, nil
+}
+
+func (r *tokenRepository) IsSessionActive(familyID uuid.UUID, after time.Time) (bool, error) {
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil && token.ExpiresAt.After(after) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *tokenRepository) DeleteExpiredRefreshTokens(before time.Time) (int64, error) {
+	var deleted int64
+	for id, token := range r.tokens {
+		if token.ExpiresAt.Before(before) {
+			delete(r.tokens, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func TestRefreshTokens(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	newTokenService := func(t *testing.T) (*service, *tokenRepository) {
+		repo := &tokenRepository{
+			user:   &User{ID: uuid.New(), Email: "reader@example.com", PasswordHash: string(hash)},
+			tokens: make(map[uuid.UUID]*RefreshToken),
+		}
+		svc, err := NewService(&config.JWTConfig{Secret: "secret", AccessExpiration: "5m", RefreshExpiration: "24h"}, &config.PasswordConfig{HashCost: "4", HashTarget: "1m"}, nil, nil, repo, nil, nil, log)
+		require.NoError(t, err)
+		return svc, repo
+	}
+
+	t.Run("Login issues a short-lived access token and a refresh token", func(t *testing.T) {
+		svc, repo := newTokenService(t)
+		response, err := svc.Login("reader@example.com", "password123", Client{})
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, response.RefreshToken)
+		assert.WithinDuration(t, time.Now().Add(5*time.Minute), response.ExpiresAt, time.Minute)
+		user, err := svc.ValidateToken(response.Token)
+		require.NoError(t, err)
+		assert.Equal(t, repo.user.ID, user.ID)
+
+		require.Len(t, repo.tokens, 1)
+		for _, token := range repo.tokens {
+			assert.Equal(t, hashRefreshToken(response.RefreshToken), token.TokenHash, "only the hash is stored")
+			assert.WithinDuration(t, time.Now().Add(24*time.Hour), token.ExpiresAt, time.Minute)
+		}
+	})
+
+	t.Run("Refresh rotates the token", func(t *testing.T) {
+		svc, _ := newTokenService(t)
+		login, err := svc.Login("reader@example.com", "password123", Client{})
+		require.NoError(t, err)
+
+		refreshed, err := svc.Refresh(login.RefreshToken, Client{})
+		require.NoError(t, err)
+		assert.NotEqual(t, login.RefreshToken, refreshed.RefreshToken)
+		_, err = svc.ValidateToken(refreshed.Token)
+		assert.NoError(t, err)
+
+		_, err = svc.Refresh(refreshed.RefreshToken, Client{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Reusing a rotated token revokes its family", func(t *testing.T) {
+		svc, _ := newTokenService(t)
+		login, err := svc.Login("reader@example.com", "password123", Client{})
+		require.NoError(t, err)
+		other, err := svc.Login("reader@example.com", "password123", Client{})
+		require.NoError(t, err)
+
+		refreshed, err := svc.Refresh(login.RefreshToken, Client{})
+		require.NoError(t, err)
+
+		_, err = svc.Refresh(login.RefreshToken, Client{})
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+		_, err = svc.Refresh(refreshed.RefreshToken, Client{})
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken, "the rotated token is revoked too")
+
+		_, err = svc.Refresh(other.RefreshToken, Client{})
+		assert.NoError(t, err, "other logins are untouched")
+	})
+
+	t.Run("Logout revokes the token", func(t *testing.T) {
+		svc, _ := newTokenService(t)
+		login, err := svc.Login("reader@example.com", "password123", Client{})
+		require.NoError(t, err)
+
+		require.NoError(t, svc.Logout(login.RefreshToken))
+		_, err = svc.Refresh(login.RefreshToken, Client{})
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+		assert.NoError(t, svc.Logout(login.RefreshToken), "logging out twice succeeds")
+		assert.NoError(t, svc.Logout("unknown"))
+	})
+
+	t.Run("Expired and unknown tokens are rejected", func(t *testing.T) {
+		svc, repo := newTokenService(t)
+		login, err := svc.Login("reader@example.com", "password123", Client{})
+		require.NoError(t, err)
+		for _, token := range repo.tokens {
+			token.ExpiresAt = time.Now().Add(-time.Minute)
+		}
+
+		_, err = svc.Refresh(login.RefreshToken, Client{})
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+		_, err = svc.Refresh("unknown", Client{})
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+		require.NoError(t, svc.PurgeRefreshTokens())
+		assert.Empty(t, repo.tokens)
+	})
+
+	t.Run("Invalid expirations", func(t *testing.T) {
+		for _, cfg := range []*config.JWTConfig{
+			{AccessExpiration: "soon"},
+			{AccessExpiration: "0s"},
+			{RefreshExpiration: "-1h"},
+		} {
+			_, err := NewService(cfg, &config.PasswordConfig{HashCost: "4", HashTarget: "1m"}, nil, nil, &tokenRepository{}, nil, nil, log)
+			assert.Error(t, err)
+		}
+	})
+}
+
+func TestAccountChanges(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	newAccountService := func(t *testing.T) (*service, *tokenRepository) {
+		repo := &tokenRepository{
+			user:   &User{ID: uuid.New(), Email: "reader@example.com", PasswordHash: string(hash)},
+			tokens: make(map[uuid.UUID]*RefreshToken),
+		}
+		svc, err := NewService(&config.JWTConfig{Secret: "secret"}, &config.PasswordConfig{HashCost: "4", HashTarget: "1m"}, nil, nil, repo, nil, nil, log)
+		require.NoError(t, err)
+		return svc, repo
+	}
+
+	// signIssuedAt signs an access token as if issued at the given time
+	signIssuedAt := func(t *testing.T, svc *service, user *User, issuedAt time.Time) string {
+		claims := Claims{
+			UserID: user.ID.String(),
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(issuedAt.Add(time.Hour)),
+				IssuedAt:  jwt.NewNumericDate(issuedAt),
+			},
+		}
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(svc.jwtSecret))
+		require.NoError(t, err)
+		return token
+	}
+
+	t.Run("Password change ends existing sessions", func(t *testing.T) {
+		svc, repo := newAccountService(t)
+		login, err := svc.Login("reader@example.com", "password123", Client{})
+		require.NoError(t, err)
+		oldToken := signIssuedAt(t, svc, repo.user, time.Now().Add(-time.Minute))
+		_, err = svc.ValidateToken(oldToken)
+		require.NoError(t, err)
+
+		response, err := svc.ChangePassword(repo.user.ID, Reauthentication{Password: "password123"}, "new-password", Client{})
+		require.NoError(t, err)
+
+		_, err = svc.ValidateToken(oldToken)
+		assert.Error(t, err, "access tokens issued before the change are rejected")
+		_, err = svc.Refresh(login.RefreshToken, Client{})
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+		_, err = svc.ValidateToken(response.Token)
+		assert.NoError(t, err, "the new session works")
+		_, err = svc.Refresh(response.RefreshToken, Client{})
+		assert.NoError(t, err)
+
+		_, err = svc.Login("reader@example.com", "password123", Client{})
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+		_, err = svc.Login("reader@example.com", "new-password", Client{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Password change requires the current password", func(t *testing.T) {
+		svc, repo := newAccountService(t)
+		_, err := svc.ChangePassword(repo.user.ID, Reauthentication{Password: "wrong-password"}, "new-password", Client{})
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+		assert.Nil(t, repo.user.PasswordChangedAt)
+	})
+
+	t.Run("Email change waits for confirmation", func(t *testing.T) {
+		svc, repo := newAccountService(t)
+		emails := &emailRecorder{}
+		svc.emails = emails
+
+		_, err := svc.ChangeEmail(repo.user.ID, Reauthentication{Password: "wrong-password"}, "new@example.com")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+		assert.Empty(t, emails.tokens)
+
+		response, err := svc.ChangeEmail(repo.user.ID, Reauthentication{Password: "password123"}, "new@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "new@example.com", response.PendingEmail)
+		assert.Equal(t, "reader@example.com", repo.user.Email, "the email stays until confirmed")
+		assert.Equal(t, "new@example.com", repo.user.ToResponse().PendingEmail)
+		require.Equal(t, []string{"new@example.com"}, emails.to)
+
+		// A newer change voids the earlier confirmation
+		_, err = svc.ChangeEmail(repo.user.ID, Reauthentication{Password: "password123"}, "other@example.com")
+		require.NoError(t, err)
+		_, err = svc.ConfirmEmail(emails.tokens[0])
+		assert.ErrorIs(t, err, ErrInvalidEmailConfirmation)
+		_, err = svc.ConfirmEmail(emails.tokens[1] + "x")
+		assert.ErrorIs(t, err, ErrInvalidEmailConfirmation)
+
+		user, err := svc.ConfirmEmail(emails.tokens[1])
+		require.NoError(t, err)
+		assert.Equal(t, "other@example.com", user.Email)
+		assert.Equal(t, "other@example.com", repo.user.Email)
+		assert.Nil(t, repo.user.PendingEmail)
+
+		_, err = svc.ConfirmEmail(emails.tokens[1])
+		assert.ErrorIs(t, err, ErrInvalidEmailConfirmation, "confirmations work once")
+	})
+
+	t.Run("Users verified elsewhere sign in without a password", func(t *testing.T) {
+		svc, repo := newAccountService(t)
+		existing, created, err := svc.FindOrCreateByEmail("reader@example.com")
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, repo.user.ID, existing.ID)
+
+		user, created, err := svc.FindOrCreateByEmail("new@example.com")
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.Empty(t, user.PasswordHash)
+		_, err = svc.Login("new@example.com", "", Client{})
+		assert.ErrorIs(t, err, ErrInvalidCredentials, "accounts without a password cannot log in with one")
+
+		response, err := svc.StartSession(user.ID, Client{})
+		require.NoError(t, err)
+		_, err = svc.ValidateToken(response.Token)
+		assert.NoError(t, err)
+		_, err = svc.Refresh(response.RefreshToken, Client{})
+		assert.NoError(t, err)
+	})
+}
+
+func TestRoles(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	repo := &tokenRepository{
+		user:   &User{ID: uuid.New(), Email: "reader@example.com", PasswordHash: string(hash), Role: utils.RoleUser},
+		tokens: make(map[uuid.UUID]*RefreshToken),
+	}
+	svc, err := NewService(&config.JWTConfig{Secret: "secret", UserCacheTTL: "1m"}, &config.PasswordConfig{HashCost: "4", HashTarget: "1m"}, nil, nil, repo, nil, nil, log)
+	require.NoError(t, err)
+
+	scopesOf := func(token string) []string {
+		claims := &Claims{}
+		_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) { return []byte("secret"), nil })
+		require.NoError(t, err)
+		return claims.Scopes
+	}
+
+	login, err := svc.Login("reader@example.com", "password123", Client{})
+	require.NoError(t, err)
+	assert.Equal(t, utils.DefaultScopes, scopesOf(login.Token))
+
+	require.NoError(t, svc.GrantAdmin([]string{"reader@example.com", "unknown@example.com"}))
+	user, err := svc.ValidateToken(login.Token)
+	require.NoError(t, err)
+	assert.Equal(t, utils.RoleAdmin, user.Role, "the cached user is refreshed")
+
+	// Admins get the admin scope from their next token on
+	refreshed, err := svc.Refresh(login.RefreshToken, Client{})
+	require.NoError(t, err)
+	assert.Contains(t, scopesOf(refreshed.Token), utils.ScopeAdmin)
+
+	_, err = svc.SetRole(repo.user.ID, repo.user.ID, utils.RoleUser)
+	assert.ErrorIs(t, err, utils.ErrValidation, "admins cannot demote themselves")
+	_, err = svc.SetRole(uuid.New(), repo.user.ID, "owner")
+	assert.ErrorIs(t, err, utils.ErrValidation)
+
+	user, err = svc.SetRole(uuid.New(), repo.user.ID, utils.RoleUser)
+	require.NoError(t, err)
+	assert.Equal(t, utils.RoleUser, user.Role)
+	user, err = svc.ValidateToken(refreshed.Token)
+	require.NoError(t, err)
+	assert.Equal(t, utils.RoleUser, user.Role, "demotion applies to tokens already issued")
+}
+
+// quotaRecorder allows a fixed number of requests
+type quotaRecorder struct {
+	remaining int
+}
+
+func (r *quotaRecorder) Record(uuid.UUID, string) (bool, error) {
+	r.remaining--
+	return r.remaining >= 0, nil
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	repo := &tokenRepository{
+		user:   &User{ID: uuid.New(), Email: "reader@example.com", PasswordHash: string(hash), Role: utils.RoleUser},
+		tokens: make(map[uuid.UUID]*RefreshToken),
+	}
+	svc, err := NewService(&config.JWTConfig{Secret: "secret", UserCacheTTL: "1m"}, &config.PasswordConfig{HashCost: "4", HashTarget: "1m"}, nil, nil, repo, nil, nil, log)
+	require.NoError(t, err)
+
+	quota := &quotaRecorder{remaining: 3}
+	router := gin.New()
+	router.Use(NewHandler(svc, nil).AuthMiddleware(quota))
+	handler := func(c *gin.Context) {
+		userID, err := utils.GetUserIDFromContext(c)
+		require.NoError(t, err)
+		c.String(http.StatusOK, userID.String())
+	}
+	router.GET("/me", handler)
+	router.POST("/me", handler)
+
+	requestWithHeader := func(method, header string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/me", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+	request := func(method, token string) *httptest.ResponseRecorder {
+		if token == "" {
+			return requestWithHeader(method, "")
+		}
+		return requestWithHeader(method, "Bearer "+token)
+	}
+
+	login, err := svc.Login("reader@example.com", "password123", Client{})
+	require.NoError(t, err)
+	w := request("GET", login.Token)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, repo.user.ID.String(), w.Body.String())
+
+	// Tokens signed with another secret never reach handlers
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{UserID: repo.user.ID.String()}).SignedString([]byte("forged"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, request("GET", forged).Code)
+	assert.Equal(t, http.StatusUnauthorized, request("GET", "").Code)
+
+	// Malformed headers are rejected rather than read past their end
+	for _, header := range []string{"Bear", "Bearer", "Bearer ", "Basic " + login.Token, "Bearer " + login.Token + " extra", login.Token} {
+		assert.Equal(t, http.StatusUnauthorized, requestWithHeader("GET", header).Code, header)
+	}
+	assert.Equal(t, http.StatusOK, requestWithHeader("GET", "bearer "+login.Token).Code, "the scheme is case-insensitive")
+
+	readOnly, _, err := svc.IssueScopedToken(repo.user.ID, utils.DefaultScopes, []string{utils.ScopeRead}, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, request("POST", readOnly).Code, "read-only tokens cannot write")
+
+	assert.Equal(t, http.StatusOK, request("GET", readOnly).Code)
+	assert.Equal(t, http.StatusTooManyRequests, request("GET", login.Token).Code, "the quota is spent")
+}
+
+// memoryObjects records deleted object keys
+type memoryObjects struct {
+	storage.Storage
+	deleted []string
+}
+
+func (m *memoryObjects) Delete(key string) error {
+	m.deleted = append(m.deleted, key)
+	return nil
+}
+
+func TestDeleteAccount(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	newDeletionService := func(t *testing.T, grace string) (*service, *tokenRepository, *memoryObjects) {
+		repo := &tokenRepository{
+			user:       &User{ID: uuid.New(), Email: "reader@example.com", PasswordHash: string(hash)},
+			tokens:     make(map[uuid.UUID]*RefreshToken),
+			objectKeys: []string{"articles/1.html", "content/1.txt"},
+		}
+		objects := &memoryObjects{}
+		svc, err := NewService(&config.JWTConfig{Secret: "secret"}, &config.PasswordConfig{HashCost: "4", HashTarget: "1m"}, &config.AccountConfig{DeletionGracePeriod: grace}, nil, repo, objects, nil, log)
+		require.NoError(t, err)
+		return svc, repo, objects
+	}
+
+	t.Run("Deletion needs the confirmation token", func(t *testing.T) {
+		svc, repo, objects := newDeletionService(t, "")
+		_, err := svc.DeleteAccount(repo.user.ID, Reauthentication{Password: "wrong-password"}, "")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+		response, err := svc.DeleteAccount(repo.user.ID, Reauthentication{Password: "password123"}, "")
+		require.NoError(t, err)
+		require.NotEmpty(t, response.ConfirmationToken)
+		assert.False(t, response.Deleted)
+		assert.False(t, repo.deleted)
+
+		_, err = svc.DeleteAccount(repo.user.ID, Reauthentication{Password: "password123"}, response.ConfirmationToken+"x")
+		assert.ErrorIs(t, err, ErrInvalidConfirmation)
+		other, otherRepo, _ := newDeletionService(t, "")
+		_, err = other.DeleteAccount(otherRepo.user.ID, Reauthentication{Password: "password123"}, response.ConfirmationToken)
+		assert.ErrorIs(t, err, ErrInvalidConfirmation, "tokens are bound to the user")
+
+		response, err = svc.DeleteAccount(repo.user.ID, Reauthentication{Password: "password123"}, response.ConfirmationToken)
+		require.NoError(t, err)
+		assert.True(t, response.Deleted)
+		assert.True(t, repo.deleted)
+		assert.Equal(t, repo.objectKeys, objects.deleted)
+	})
+
+	t.Run("Expired confirmation tokens are rejected", func(t *testing.T) {
+		svc, repo, _ := newDeletionService(t, "")
+		expiresAt := time.Now().Add(-time.Second).Unix()
+		token := strconv.FormatInt(expiresAt, 10) + "." + svc.deletionSignature(repo.user, expiresAt)
+
+		_, err := svc.DeleteAccount(repo.user.ID, Reauthentication{Password: "password123"}, token)
+		assert.ErrorIs(t, err, ErrInvalidConfirmation)
+		assert.False(t, repo.deleted)
+	})
+
+	t.Run("Grace period signs out and login cancels", func(t *testing.T) {
+		svc, repo, _ := newDeletionService(t, "72h")
+		login, err := svc.Login("reader@example.com", "password123", Client{})
+		require.NoError(t, err)
+
+		response, err := svc.DeleteAccount(repo.user.ID, Reauthentication{Password: "password123"}, "")
+		require.NoError(t, err)
+		response, err = svc.DeleteAccount(repo.user.ID, Reauthentication{Password: "password123"}, response.ConfirmationToken)
+		require.NoError(t, err)
+		require.NotNil(t, response.DeleteAfter)
+		assert.WithinDuration(t, time.Now().Add(72*time.Hour), *response.DeleteAfter, time.Minute)
+		assert.False(t, repo.deleted)
+
+		_, err = svc.ValidateToken(login.Token)
+		assert.Error(t, err)
+		_, err = svc.Refresh(login.RefreshToken, Client{})
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+		require.NoError(t, svc.PurgeDeletedAccounts())
+		assert.False(t, repo.deleted, "not due yet")
+
+		login, err = svc.Login("reader@example.com", "password123", Client{})
+		require.NoError(t, err)
+		assert.Nil(t, repo.user.DeleteAfter)
+		_, err = svc.ValidateToken(login.Token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Due accounts are purged", func(t *testing.T) {
+		svc, repo, objects := newDeletionService(t, "1h")
+		due := time.Now().Add(-time.Minute)
+		repo.user.DeleteAfter = &due
+
+		require.NoError(t, svc.PurgeDeletedAccounts())
+		assert.True(t, repo.deleted)
+		assert.Len(t, objects.deleted, 2)
+	})
+
+	t.Run("Users created by a sign-in provider confirm with a recent sign-in", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		svc, repo, _ := newDeletionService(t, "")
+		user, created, err := svc.FindOrCreateByEmail("oauth@example.com")
+		require.NoError(t, err)
+		require.True(t, created)
+
+		router := gin.New()
+		handler := NewHandler(svc, nil)
+		handler.RegisterRoutes(router.Group("/"), handler.AuthMiddleware(nil), func(c *gin.Context) {})
+		request := func(method, path, token, body string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(method, path, strings.NewReader(body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		// Refreshing renews the access token but not the sign-in
+		session, err := svc.StartSession(user.ID, Client{})
+		require.NoError(t, err)
+		for _, token := range repo.tokens {
+			token.SignedInAt = time.Now().Add(-time.Hour)
+		}
+		stale, err := svc.Refresh(session.RefreshToken, Client{})
+		require.NoError(t, err)
+		w := request("DELETE", "/users/me", stale.Token, `{}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "Sign in again")
+		assert.Equal(t, http.StatusForbidden, request("PUT", "/users/me/password", stale.Token, `{"new_password": "new-password"}`).Code)
+		assert.Equal(t, http.StatusForbidden, request("DELETE", "/users/me", stale.Token, `{"current_password": "guess"}`).Code)
+
+		// Impersonation tokens name no sign-in
+		impersonation, err := svc.signClaims(user, []string{utils.ScopeRead, utils.ScopeWrite}, time.Hour, uuid.New().String(), "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, request("DELETE", "/users/me", impersonation, `{}`).Code)
+
+		fresh, err := svc.StartSession(user.ID, Client{})
+		require.NoError(t, err)
+		w = request("DELETE", "/users/me", fresh.Token, `{}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var response DeleteAccountResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.NotEmpty(t, response.ConfirmationToken)
+
+		assert.Equal(t, http.StatusNoContent, request("DELETE", "/users/me", fresh.Token, `{"confirmation_token": "`+response.ConfirmationToken+`"}`).Code)
+		assert.True(t, repo.deleted)
+	})
+
+	t.Run("Users created by a sign-in provider set a first password", func(t *testing.T) {
+		svc, repo, _ := newDeletionService(t, "")
+		user, _, err := svc.FindOrCreateByEmail("oauth@example.com")
+		require.NoError(t, err)
+
+		_, err = svc.ChangePassword(user.ID, Reauthentication{}, "new-password", Client{})
+		assert.ErrorIs(t, err, ErrReauthenticationRequired)
+
+		_, err = svc.ChangePassword(user.ID, Reauthentication{SignedInAt: time.Now().Add(-time.Minute)}, "new-password", Client{})
+		require.NoError(t, err)
+		assert.NotEmpty(t, repo.user.PasswordHash)
+
+		// From then on the password is needed
+		_, err = svc.ChangeEmail(user.ID, Reauthentication{SignedInAt: time.Now()}, "new@example.com")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+		_, err = svc.ChangeEmail(user.ID, Reauthentication{Password: "new-password"}, "new@example.com")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Invalid grace period", func(t *testing.T) {
+		_, err := NewService(&config.JWTConfig{}, &config.PasswordConfig{HashCost: "4", HashTarget: "1m"}, &config.AccountConfig{DeletionGracePeriod: "-1h"}, nil, &tokenRepository{}, nil, nil, log)
+		assert.Error(t, err)
+	})
+}
+
 func isValidEmail(email string) bool {
 	return len(email) > 3 &&
 		email[0] != '@' &&
@@ -90,3 +945,403 @@ func contains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestLoginGuard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	repo := &tokenRepository{
+		user:   &User{ID: uuid.New(), Email: "reader@example.com", PasswordHash: string(hash), Role: utils.RoleUser},
+		tokens: make(map[uuid.UUID]*RefreshToken),
+	}
+	svc, err := NewService(&config.JWTConfig{Secret: "secret"}, &config.PasswordConfig{HashCost: "4", HashTarget: "1m"}, nil, nil, repo, nil, nil, log)
+	require.NoError(t, err)
+
+	newRouter := func(cfg *config.LoginConfig) (*gin.Engine, *MemoryFailureStore) {
+		store := NewMemoryFailureStore()
+		guard, err := NewLoginGuard(cfg, store, nil, log)
+		require.NoError(t, err)
+		router := gin.New()
+		require.NoError(t, utils.SetTrustedProxies(router, ""))
+		NewHandler(svc, guard).RegisterRoutes(router.Group("/"), func(c *gin.Context) {}, func(c *gin.Context) {})
+		return router, store
+	}
+	loginForwarded := func(router *gin.Engine, ip, forwardedFor, email, password string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/login", strings.NewReader(`{"email":"`+email+`","password":"`+password+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		req.RemoteAddr = ip + ":1234"
+		router.ServeHTTP(w, req)
+		return w
+	}
+	login := func(router *gin.Engine, ip, email, password string) *httptest.ResponseRecorder {
+		return loginForwarded(router, ip, "", email, password)
+	}
+
+	t.Run("Locks out the account after repeated failures", func(t *testing.T) {
+		router, store := newRouter(&config.LoginConfig{RateLimit: "100", MaxFailures: "3", Lockout: "10m"})
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusUnauthorized, login(router, "192.0.2.1", "reader@example.com", "wrong").Code)
+		}
+
+		// Even the right password is refused, from any IP, until the lockout ends
+		w := login(router, "192.0.2.2", "Reader@Example.com", "password123")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "600", w.Header().Get("Retry-After"))
+
+		store.now = func() time.Time { return time.Now().Add(10 * time.Minute) }
+		assert.Equal(t, http.StatusOK, login(router, "192.0.2.2", "reader@example.com", "password123").Code)
+	})
+
+	t.Run("Successful logins reset the account's failures", func(t *testing.T) {
+		router, _ := newRouter(&config.LoginConfig{RateLimit: "100", MaxFailures: "2"})
+		assert.Equal(t, http.StatusUnauthorized, login(router, "192.0.2.1", "reader@example.com", "wrong").Code)
+		assert.Equal(t, http.StatusOK, login(router, "192.0.2.1", "reader@example.com", "password123").Code)
+		assert.Equal(t, http.StatusUnauthorized, login(router, "192.0.2.1", "reader@example.com", "wrong").Code)
+		assert.Equal(t, http.StatusOK, login(router, "192.0.2.1", "reader@example.com", "password123").Code)
+	})
+
+	t.Run("Locks out IPs guessing across accounts", func(t *testing.T) {
+		router, _ := newRouter(&config.LoginConfig{RateLimit: "100", MaxFailures: "5", MaxIPFailures: "3"})
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusUnauthorized, login(router, "192.0.2.1", "user"+strconv.Itoa(i)+"@example.com", "wrong").Code)
+		}
+		assert.Equal(t, http.StatusTooManyRequests, login(router, "192.0.2.1", "reader@example.com", "password123").Code)
+		assert.Equal(t, http.StatusOK, login(router, "192.0.2.2", "reader@example.com", "password123").Code)
+	})
+
+	t.Run("Forged X-Forwarded-For does not reset IP failures", func(t *testing.T) {
+		router, _ := newRouter(&config.LoginConfig{RateLimit: "100", MaxFailures: "5", MaxIPFailures: "3"})
+		for i := 0; i < 3; i++ {
+			forged := "203.0.113." + strconv.Itoa(i+1)
+			assert.Equal(t, http.StatusUnauthorized, loginForwarded(router, "192.0.2.1", forged, "user"+strconv.Itoa(i)+"@example.com", "wrong").Code)
+		}
+		assert.Equal(t, http.StatusTooManyRequests, loginForwarded(router, "192.0.2.1", "203.0.113.99", "reader@example.com", "password123").Code)
+	})
+
+	t.Run("Limits login and signup requests per IP", func(t *testing.T) {
+		router, _ := newRouter(&config.LoginConfig{RateLimit: "2"})
+		assert.Equal(t, http.StatusOK, login(router, "192.0.2.1", "reader@example.com", "password123").Code)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/signup", strings.NewReader(`{}`))
+		req.RemoteAddr = "192.0.2.1:1234"
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		assert.Equal(t, http.StatusTooManyRequests, login(router, "192.0.2.1", "reader@example.com", "password123").Code)
+		assert.Equal(t, http.StatusOK, login(router, "192.0.2.2", "reader@example.com", "password123").Code)
+	})
+
+	t.Run("Invalid configuration", func(t *testing.T) {
+		for _, cfg := range []*config.LoginConfig{{RateLimit: "0"}, {RateWindow: "soon"}, {MaxFailures: "-1"}, {MaxIPFailures: "many"}, {Lockout: "0s"}} {
+			_, err := NewLoginGuard(cfg, NewMemoryFailureStore(), nil, log)
+			assert.Error(t, err)
+		}
+	})
+}
+
+func TestSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	repo := &tokenRepository{
+		user:   &User{ID: uuid.New(), Email: "reader@example.com", PasswordHash: string(hash), Role: utils.RoleUser},
+		tokens: make(map[uuid.UUID]*RefreshToken),
+	}
+	svc, err := NewService(&config.JWTConfig{Secret: "secret"}, &config.PasswordConfig{HashCost: "4", HashTarget: "1m"}, nil, nil, repo, nil, nil, log)
+	require.NoError(t, err)
+
+	router := gin.New()
+	handler := NewHandler(svc, nil)
+	handler.RegisterRoutes(router.Group("/"), handler.AuthMiddleware(nil), func(c *gin.Context) {})
+	request := func(method, path, token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	laptop, err := svc.Login("reader@example.com", "password123", Client{UserAgent: "Firefox", IPAddress: "192.0.2.1"})
+	require.NoError(t, err)
+	phone, err := svc.Login("reader@example.com", "password123", Client{UserAgent: "Safari", IPAddress: "192.0.2.2"})
+	require.NoError(t, err)
+
+	// Refreshing keeps the session, updating where it was last seen from
+	phone, err = svc.Refresh(phone.RefreshToken, Client{UserAgent: "Safari", IPAddress: "198.51.100.7"})
+	require.NoError(t, err)
+
+	w := request("GET", "/users/me/sessions", laptop.Token)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Sessions []Session `json:"sessions"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Sessions, 2)
+
+	byAgent := make(map[string]Session)
+	for _, session := range response.Sessions {
+		byAgent[session.UserAgent] = session
+	}
+	assert.True(t, byAgent["Firefox"].Current)
+	assert.False(t, byAgent["Safari"].Current)
+	assert.Equal(t, "198.51.100.7", byAgent["Safari"].IPAddress)
+	assert.False(t, byAgent["Safari"].LastSeenAt.Before(byAgent["Safari"].SignedInAt))
+
+	// Revoking the phone's session ends its refresh token and access token
+	phoneSession := byAgent["Safari"].ID
+	assert.Equal(t, http.StatusOK, request("GET", "/users/me/sessions", phone.Token).Code)
+	assert.Equal(t, http.StatusNoContent, request("DELETE", "/users/me/sessions/"+phoneSession.String(), laptop.Token).Code)
+	_, err = svc.Refresh(phone.RefreshToken, Client{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, request("GET", "/users/me/sessions", phone.Token).Code)
+	assert.Equal(t, http.StatusOK, request("GET", "/users/me/sessions", laptop.Token).Code)
+
+	sessions, err := svc.ListSessions(repo.user.ID, uuid.Nil)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "Firefox", sessions[0].UserAgent)
+
+	assert.Equal(t, http.StatusNotFound, request("DELETE", "/users/me/sessions/"+phoneSession.String(), laptop.Token).Code, "sessions end once")
+	assert.Equal(t, http.StatusNotFound, request("DELETE", "/users/me/sessions/"+uuid.New().String(), laptop.Token).Code)
+	assert.Equal(t, http.StatusBadRequest, request("DELETE", "/users/me/sessions/not-a-uuid", laptop.Token).Code)
+
+	// Logging out ends the access token too
+	require.NoError(t, svc.Logout(laptop.RefreshToken))
+	assert.Equal(t, http.StatusUnauthorized, request("GET", "/users/me/sessions", laptop.Token).Code)
+}
+
+// emailRecorder keeps the email confirmations it is given
+type emailRecorder struct {
+	to     []string
+	tokens []string
+}
+
+func (r *emailRecorder) SendEmailConfirmation(email, token string, expiresAt time.Time) error {
+	r.to = append(r.to, email)
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+
+// recordingAuditor keeps the audit events it is given
+type recordingAuditor struct {
+	events []*AuditEvent
+}
+
+func (r *recordingAuditor) Record(event *AuditEvent) {
+	r.events = append(r.events, event)
+}
+
+func (r *recordingAuditor) types() []string {
+	types := make([]string, 0, len(r.events))
+	for _, event := range r.events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func TestAuditEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	newService := func() (*service, *tokenRepository, *recordingAuditor) {
+		repo := &tokenRepository{
+			user:   &User{ID: uuid.New(), Email: "reader@example.com", PasswordHash: string(hash), Role: utils.RoleUser},
+			tokens: make(map[uuid.UUID]*RefreshToken),
+		}
+		auditor := &recordingAuditor{}
+		svc, err := NewService(&config.JWTConfig{Secret: "secret"}, &config.PasswordConfig{HashCost: "4", HashTarget: "1m"}, nil, nil, repo, nil, auditor, log)
+		require.NoError(t, err)
+		return svc, repo, auditor
+	}
+	client := Client{UserAgent: "Firefox", IPAddress: "192.0.2.1"}
+
+	t.Run("Logins and logouts", func(t *testing.T) {
+		svc, repo, auditor := newService()
+
+		_, err := svc.Login("reader@example.com", "wrong", client)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+		response, err := svc.Login("reader@example.com", "password123", client)
+		require.NoError(t, err)
+		require.NoError(t, svc.Logout(response.RefreshToken))
+
+		assert.Equal(t, []string{AuditLoginFailed, AuditLoginSucceeded, AuditTokenRevoked}, auditor.types())
+		assert.Equal(t, repo.user.ID, auditor.events[0].UserID)
+		assert.Equal(t, client, auditor.events[1].Client)
+	})
+
+	t.Run("Lockouts", func(t *testing.T) {
+		auditor := &recordingAuditor{}
+		guard, err := NewLoginGuard(&config.LoginConfig{MaxFailures: "2"}, NewMemoryFailureStore(), auditor, log)
+		require.NoError(t, err)
+
+		guard.Failed("192.0.2.1", "Reader@Example.com")
+		guard.Failed("192.0.2.1", "reader@example.com")
+		assert.Error(t, guard.Check("192.0.2.1", "reader@example.com"))
+
+		assert.Equal(t, []string{AuditLoginLockedOut, AuditLoginBlocked}, auditor.types())
+		assert.Equal(t, "reader@example.com", auditor.events[0].Email)
+		assert.Equal(t, "192.0.2.1", auditor.events[0].Client.IPAddress)
+	})
+
+	t.Run("API keys are recorded once per interval", func(t *testing.T) {
+		svc, repo, auditor := newService()
+		token, tokenID, err := svc.IssueScopedToken(repo.user.ID, []string{utils.ScopeRead}, []string{utils.ScopeRead}, time.Hour)
+		require.NoError(t, err)
+
+		router := gin.New()
+		router.GET("/ping", NewHandler(svc, nil).AuthMiddleware(nil), func(c *gin.Context) { c.Status(http.StatusOK) })
+		for i := 0; i < 3; i++ {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/ping", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+		}
+
+		assert.Equal(t, []string{AuditAPIKeyIssued, AuditAPIKeyUsed}, auditor.types())
+		assert.Contains(t, auditor.events[1].Details, tokenID)
+	})
+}
+
+// directoryRepository serves a fixed set of users
+type directoryRepository struct {
+	Repository
+	users map[uuid.UUID]*User
+}
+
+func (r *directoryRepository) FindByID(id uuid.UUID) (*User, error) {
+	if user, ok := r.users[id]; ok {
+		return user, nil
+	}
+	return nil, ErrNotFound
+}
+
+func TestImpersonation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	admin := &User{ID: uuid.New(), Email: "support@example.com", Role: utils.RoleAdmin}
+	target := &User{ID: uuid.New(), Email: "reader@example.com", Role: utils.RoleUser}
+	repo := &directoryRepository{users: map[uuid.UUID]*User{admin.ID: admin, target.ID: target}}
+	auditor := &recordingAuditor{}
+	svc, err := NewService(&config.JWTConfig{Secret: "secret", UserCacheTTL: "1m"}, &config.PasswordConfig{HashCost: "4", HashTarget: "1m"}, nil, nil, repo, nil, auditor, log)
+	require.NoError(t, err)
+
+	claimsOf := func(token string) *Claims {
+		claims := &Claims{}
+		_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) { return []byte("secret"), nil })
+		require.NoError(t, err)
+		return claims
+	}
+	lifetimeOf := func(claims *Claims) time.Duration {
+		return claims.ExpiresAt.Sub(claims.IssuedAt.Time)
+	}
+
+	t.Run("Service", func(t *testing.T) {
+		_, err := svc.Impersonate(admin.ID, admin.ID, time.Minute)
+		assert.ErrorIs(t, err, utils.ErrValidation, "admins cannot impersonate themselves")
+		_, err = svc.Impersonate(admin.ID, uuid.New(), time.Minute)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Empty(t, auditor.events, "refused impersonations are not recorded")
+
+		token, err := svc.Impersonate(admin.ID, target.ID, 10*time.Minute)
+		require.NoError(t, err)
+		claims := claimsOf(token)
+		assert.Equal(t, target.ID.String(), claims.UserID)
+		assert.Equal(t, admin.ID.String(), claims.ImpersonatorID)
+		assert.Equal(t, []string{utils.ScopeRead}, claims.Scopes)
+		assert.Equal(t, 10*time.Minute, lifetimeOf(claims))
+
+		require.Len(t, auditor.events, 1)
+		event := auditor.events[0]
+		assert.Equal(t, AuditImpersonation, event.Type)
+		assert.Equal(t, target.ID, event.UserID)
+		assert.Equal(t, admin.ID, event.ActorID)
+		assert.Equal(t, target.Email, event.Email)
+
+		for _, ttl := range []time.Duration{0, -time.Minute, 24 * time.Hour} {
+			token, err := svc.Impersonate(admin.ID, target.ID, ttl)
+			require.NoError(t, err)
+			assert.Equal(t, maxImpersonationTTL, lifetimeOf(claimsOf(token)), ttl.String())
+		}
+	})
+
+	t.Run("Handler", func(t *testing.T) {
+		h := NewHandler(svc, nil)
+		router := gin.New()
+		h.RegisterRoutes(router.Group(""), h.AuthMiddleware(nil), func(c *gin.Context) { c.Next() })
+
+		adminToken, err := svc.signClaims(admin, []string{utils.ScopeRead, utils.ScopeWrite, utils.ScopeAdmin}, time.Hour, "", "", nil)
+		require.NoError(t, err)
+		request := func(method, path, token, body string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(method, path, strings.NewReader(body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			return w
+		}
+		impersonate := func(token, body string) *httptest.ResponseRecorder {
+			return request("POST", "/admin/impersonate", token, body)
+		}
+
+		assert.Equal(t, http.StatusBadRequest, impersonate(adminToken, `{"user_id":"`+admin.ID.String()+`"}`).Code)
+		assert.Equal(t, http.StatusNotFound, impersonate(adminToken, `{"user_id":"`+uuid.NewString()+`"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, impersonate(adminToken, `{"user_id":"`+target.ID.String()+`","expires_in":"soon"}`).Code)
+
+		w := impersonate(adminToken, `{"user_id":"`+target.ID.String()+`","expires_in":"24h"}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var response struct {
+			Token  string    `json:"token"`
+			UserID uuid.UUID `json:"user_id"`
+			Scopes []string  `json:"scopes"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, target.ID, response.UserID)
+		assert.Equal(t, []string{utils.ScopeRead}, response.Scopes)
+		claims := claimsOf(response.Token)
+		assert.Equal(t, admin.ID.String(), claims.ImpersonatorID)
+		assert.Equal(t, maxImpersonationTTL, lifetimeOf(claims))
+
+		// The token reads as the user but cannot change anything
+		w = request("GET", "/users/me", response.Token, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), target.Email)
+		assert.Equal(t, http.StatusForbidden, request("PUT", "/users/me/password", response.Token, `{"new_password":"password456"}`).Code)
+		assert.Equal(t, http.StatusForbidden, request("POST", "/users/tokens", response.Token, `{"scopes":["read"]}`).Code)
+		assert.Equal(t, http.StatusForbidden, impersonate(response.Token, `{"user_id":"`+admin.ID.String()+`"}`).Code)
+	})
+
+	t.Run("Handler refuses while impersonating", func(t *testing.T) {
+		// Even an impersonated admin session with enough scope cannot chain
+		// impersonations
+		router := gin.New()
+		router.POST("/impersonate", func(c *gin.Context) {
+			c.Set("user_id", admin.ID)
+			c.Set("impersonator_id", uuid.NewString())
+		}, NewHandler(svc, nil).Impersonate)
+
+		events := len(auditor.events)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/impersonate", strings.NewReader(`{"user_id":"`+target.ID.String()+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Len(t, auditor.events, events, "no token is issued")
+	})
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// redactedQueryParams are query parameters carrying credentials, such as the
+// tokens feed readers pass through TokenFromQuery
+var redactedQueryParams = []string{"token"}
+
+// AccessLogFormatter is gin's access log line with the request ID appended
+// and credentials in the query string masked
+func AccessLogFormatter(param gin.LogFormatterParams) string {
+	return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v | %s\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		param.StatusCode,
+		param.Latency,
+		param.ClientIP,
+		param.Method,
+		redactPath(param.Path),
+		logger.RequestIDFromContext(param.Request.Context()),
+		param.ErrorMessage,
+	)
+}
+
+// redactPath masks the values of credential query parameters in a request
+// path with its raw query
+func redactPath(path string) string {
+	base, rawQuery, ok := strings.Cut(path, "?")
+	if !ok {
+		return path
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		// Unparsable queries are dropped rather than risk logging a token
+		return base + "?[REDACTED]"
+	}
+	redacted := false
+	for _, name := range redactedQueryParams {
+		if values, ok := query[name]; ok {
+			for i := range values {
+				values[i] = "REDACTED"
+			}
+			redacted = true
+		}
+	}
+	if !redacted {
+		return path
+	}
+	return base + "?" + query.Encode()
+}
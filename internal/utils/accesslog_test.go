@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessLogFormatter(t *testing.T) {
+	const token = "eyJhbGciOiJIUzI1NiJ9.secret.signature"
+	line := func(path string) string {
+		return AccessLogFormatter(gin.LogFormatterParams{
+			Request:    httptest.NewRequest("GET", path, nil),
+			TimeStamp:  time.Now(),
+			StatusCode: 200,
+			Method:     "GET",
+			Path:       path,
+		})
+	}
+
+	logged := line("/api/v1/feeds/me.xml?format=atom&token=" + token)
+	assert.NotContains(t, logged, token)
+	assert.NotContains(t, logged, "secret")
+	assert.Contains(t, logged, "format=atom")
+	assert.Contains(t, logged, "token=REDACTED")
+
+	assert.NotContains(t, line("/api/v1/articles/feed.xml?token="+token+"&bad=%zz"), "secret")
+	assert.Contains(t, line("/api/v1/articles?limit=10"), "/api/v1/articles?limit=10")
+}
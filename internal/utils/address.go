@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// ErrPrivateDestination is returned when a connection to a private or local
+// address is refused
+var ErrPrivateDestination = errors.New("connections to private or local addresses are not allowed")
+
+// IsPrivateHost reports whether a host is an IP address outside the public
+// internet, or a name that always resolves locally. Names are not resolved,
+// so this cannot catch public names pointing at private addresses; dial
+// through DenyPrivateAddresses for that.
+func IsPrivateHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	return IsPrivateIP(ip)
+}
+
+// IsPrivateIP reports whether an IP address is loopback, private, link-local
+// or unspecified
+func IsPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// DenyPrivateAddresses is a net.Dialer Control function refusing connections
+// to private addresses. It sees the address after name resolution, so it also
+// covers redirects and names that resolve, or are rebound, to private
+// addresses.
+func DenyPrivateAddresses(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || IsPrivateIP(ip) {
+		return ErrPrivateDestination
+	}
+	return nil
+}
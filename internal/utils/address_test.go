@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPrivateHost(t *testing.T) {
+	for _, host := range []string{"localhost", "LOCALHOST.", "api.localhost", "127.0.0.1", "10.1.2.3", "192.168.0.1", "169.254.169.254", "0.0.0.0", "::1", "fe80::1", "::ffff:127.0.0.1"} {
+		assert.True(t, IsPrivateHost(host), host)
+	}
+	for _, host := range []string{"example.com", "8.8.8.8", "2001:4860:4860::8888", "localhost.example.com"} {
+		assert.False(t, IsPrivateHost(host), host)
+	}
+}
+
+func TestDenyPrivateAddresses(t *testing.T) {
+	assert.NoError(t, DenyPrivateAddresses("tcp4", "93.184.216.34:443", nil))
+	assert.NoError(t, DenyPrivateAddresses("tcp6", "[2001:4860:4860::8888]:80", nil))
+	assert.ErrorIs(t, DenyPrivateAddresses("tcp4", "127.0.0.1:80", nil), ErrPrivateDestination)
+	assert.ErrorIs(t, DenyPrivateAddresses("tcp4", "169.254.169.254:80", nil), ErrPrivateDestination)
+	assert.ErrorIs(t, DenyPrivateAddresses("tcp6", "[::1]:8080", nil), ErrPrivateDestination)
+}
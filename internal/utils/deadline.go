@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestBudget creates middleware giving every request a total time budget.
+// The budget is the deadline of the request context; downstream calls carve
+// their own deadlines out of it with DeriveDeadline.
+func RequestBudget(total time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), total)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// DeriveDeadline returns a context for a single downstream call that may use
+// share (0-1] of the time remaining in ctx, so one slow dependency cannot
+// consume the whole request budget. Without a deadline on ctx the call is
+// unbounded, as before budgets existed.
+func DeriveDeadline(ctx context.Context, share float64) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || share >= 1 {
+		return context.WithCancel(ctx)
+	}
+
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return context.WithCancel(ctx) // Already expired; ctx reports the error
+	}
+
+	return context.WithTimeout(ctx, time.Duration(float64(remaining)*share))
+}
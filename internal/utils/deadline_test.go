@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestBudget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var remaining time.Duration
+	router := gin.New()
+	router.GET("/", RequestBudget(2*time.Second), func(c *gin.Context) {
+		deadline, ok := c.Request.Context().Deadline()
+		require.True(t, ok)
+		remaining = time.Until(deadline)
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.InDelta(t, 2*time.Second, remaining, float64(100*time.Millisecond))
+}
+
+func TestDeriveDeadline(t *testing.T) {
+	t.Run("Takes a share of the remaining budget", func(t *testing.T) {
+		parent, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		child, cancelChild := DeriveDeadline(parent, 0.25)
+		defer cancelChild()
+
+		deadline, ok := child.Deadline()
+		require.True(t, ok)
+		assert.InDelta(t, 2500*time.Millisecond, time.Until(deadline), float64(100*time.Millisecond))
+	})
+
+	t.Run("Leaves unbounded contexts unbounded", func(t *testing.T) {
+		child, cancel := DeriveDeadline(context.Background(), 0.5)
+		defer cancel()
+
+		_, ok := child.Deadline()
+		assert.False(t, ok)
+	})
+
+	t.Run("Expired budgets stay expired", func(t *testing.T) {
+		parent, cancel := context.WithTimeout(context.Background(), -time.Second)
+		defer cancel()
+
+		child, cancelChild := DeriveDeadline(parent, 0.5)
+		defer cancelChild()
+
+		assert.ErrorIs(t, child.Err(), context.DeadlineExceeded)
+	})
+}
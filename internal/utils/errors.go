@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Kinds of domain errors, matched with errors.Is and mapped to HTTP statuses
+// by ErrorStatus
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrValidation  = errors.New("validation failed")
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// DomainError is an error of a known kind whose message is safe to show to API
+// clients. Modules declare them as sentinels, such as article.ErrNotFound, so
+// callers match errors with errors.Is instead of comparing messages.
+type DomainError struct {
+	kind       error
+	message    string
+	retryAfter time.Duration // Set for ErrRateLimited
+}
+
+// NewNotFoundError creates an error of kind ErrNotFound
+func NewNotFoundError(message string) *DomainError {
+	return &DomainError{kind: ErrNotFound, message: message}
+}
+
+// NewConflictError creates an error of kind ErrConflict
+func NewConflictError(message string) *DomainError {
+	return &DomainError{kind: ErrConflict, message: message}
+}
+
+// NewRateLimitError creates an error of kind ErrRateLimited for a request that
+// may be retried after retryAfter
+func NewRateLimitError(message string, retryAfter time.Duration) *DomainError {
+	return &DomainError{kind: ErrRateLimited, message: message, retryAfter: retryAfter}
+}
+
+func (e *DomainError) Error() string {
+	return e.message
+}
+
+// Is reports whether target is the error's kind
+func (e *DomainError) Is(target error) bool {
+	return target == e.kind
+}
+
+// Is makes every validation error match ErrValidation
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// ErrorStatus returns the HTTP status for the kind of err, or 500 when err is
+// not a domain error
+func ErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, ErrRateLimited):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// RespondError writes err as a JSON error response. Validation errors name the
+// rejected field and other domain errors are reported by their message; any
+// other error is reported with the fallback message so internals do not leak.
+// The request ID is included so a reported error can be found in the logs.
+func RespondError(c *gin.Context, err error, fallback string) {
+	status, body := http.StatusInternalServerError, gin.H{"error": fallback}
+
+	var domainErr *DomainError
+	if validationErr, ok := AsValidationError(err); ok {
+		status, body = http.StatusBadRequest, gin.H{"error": validationErr.Error(), "field": validationErr.Field}
+	} else if errors.As(err, &domainErr) {
+		if domainErr.retryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(domainErr.retryAfter.Seconds()))))
+		}
+		status, body = ErrorStatus(domainErr), gin.H{"error": capitalize(domainErr.message)}
+	}
+
+	if requestID := c.Writer.Header().Get(logger.RequestIDHeader); requestID != "" {
+		body["request_id"] = requestID
+	}
+	c.JSON(status, body)
+}
+
+// capitalize upper-cases the first letter of a message
+func capitalize(message string) string {
+	first, size := utf8.DecodeRuneInString(message)
+	if first == utf8.RuneError {
+		return message
+	}
+	var b strings.Builder
+	b.WriteRune(unicode.ToUpper(first))
+	b.WriteString(message[size:])
+	return b.String()
+}
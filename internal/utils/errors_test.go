@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorStatus(t *testing.T) {
+	notFound := NewNotFoundError("article not found")
+	conflict := NewConflictError("user already exists")
+
+	assert.Equal(t, http.StatusNotFound, ErrorStatus(notFound))
+	assert.Equal(t, http.StatusNotFound, ErrorStatus(fmt.Errorf("loading: %w", notFound)))
+	assert.Equal(t, http.StatusConflict, ErrorStatus(conflict))
+	assert.Equal(t, http.StatusBadRequest, ErrorStatus(NewValidationError("url", "is required")))
+	assert.Equal(t, http.StatusTooManyRequests, ErrorStatus(NewRateLimitError("slow down", time.Minute)))
+	assert.Equal(t, http.StatusInternalServerError, ErrorStatus(errors.New("database error")))
+
+	assert.ErrorIs(t, notFound, ErrNotFound)
+	assert.NotErrorIs(t, notFound, ErrConflict)
+	assert.NotErrorIs(t, notFound, NewNotFoundError("article not found"), "sentinels match by identity")
+}
+
+func TestRespondError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	respond := func(err error) (int, map[string]string) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		RespondError(c, err, "Failed to do it")
+
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return w.Code, body
+	}
+
+	code, body := respond(fmt.Errorf("wrapped: %w", NewNotFoundError("article not found")))
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, map[string]string{"error": "Article not found"}, body)
+
+	code, body = respond(NewValidationError("score", "must be between 1 and 5"))
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, map[string]string{"error": "score: must be between 1 and 5", "field": "score"}, body)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	RespondError(c, NewRateLimitError("too many refreshes", 1500*time.Millisecond), "Failed to do it")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+	// Unknown errors do not leak their message
+	code, body = respond(errors.New("pq: connection refused"))
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.Equal(t, map[string]string{"error": "Failed to do it"}, body)
+
+	// The request ID set by the request ID middleware is echoed for tracing
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Header("X-Request-ID", "req-123")
+	RespondError(c, NewNotFoundError("article not found"), "Failed to do it")
+	var traced map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &traced))
+	assert.Equal(t, map[string]string{"error": "Article not found", "request_id": "req-123"}, traced)
+}
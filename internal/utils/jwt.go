@@ -1,32 +1,37 @@
 package utils
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
-// GetUserIDFromToken extracts user ID from JWT token in the request
-// This assumes the JWT has already been validated by middleware
-func GetUserIDFromToken(c *gin.Context) (uuid.UUID, error) {
-	authHeader := c.GetHeader("Authorization")
-	tokenString := authHeader[len("Bearer "):]
-
-	// Parse without verification since middleware already validated it
-	token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
-	if err != nil {
-		return uuid.Nil, err
-	}
+// ErrNoUser is returned for requests the auth middleware did not authenticate
+var ErrNoUser = errors.New("no authenticated user in context")
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return uuid.Nil, err
+// GetUserIDFromContext returns the ID of the user the auth middleware
+// authenticated. The token is never read here, so handlers only see users
+// whose token was verified.
+func GetUserIDFromContext(c *gin.Context) (uuid.UUID, error) {
+	if value, exists := c.Get("user_id"); exists {
+		if userID, ok := value.(uuid.UUID); ok && userID != uuid.Nil {
+			return userID, nil
+		}
 	}
+	return uuid.Nil, ErrNoUser
+}
 
-	userIDStr, ok := claims["user_id"].(string)
-	if !ok {
-		return uuid.Nil, err
+// TokenFromQuery lets clients that cannot set headers, such as feed readers,
+// pass the token in the given query parameter. It must run before the auth
+// middleware; a token in the Authorization header takes precedence.
+func TokenFromQuery(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query(param); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		c.Next()
 	}
-
-	return uuid.Parse(userIDStr)
 }
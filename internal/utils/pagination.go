@@ -1,8 +1,13 @@
 package utils
 
 import (
+	"encoding/base64"
 	"math"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 // PaginationMeta represents pagination metadata
@@ -29,3 +34,50 @@ func CalculatePagination(total int64, page, limit int) PaginationMeta {
 func IntToString(i int) string {
 	return strconv.Itoa(i)
 }
+
+// Cursor is the position of the last item of a keyset-paginated page.
+// Lists using cursors are ordered by creation time, newest first, with the ID
+// breaking ties so rows created in the same instant are neither skipped nor repeated.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// ErrInvalidCursor is returned for cursors that were not produced by EncodeCursor
+var ErrInvalidCursor = NewValidationError("cursor", "is not a valid cursor")
+
+// CursorMeta represents cursor pagination metadata
+type CursorMeta struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"` // Empty on the last page
+	HasMore    bool   `json:"has_more"`
+}
+
+// EncodeCursor returns the opaque string clients pass back to get the next page
+func EncodeCursor(cursor Cursor) string {
+	raw := cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + cursor.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor
+func DecodeCursor(encoded string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &Cursor{}
+	if cursor.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.ID, err = uuid.Parse(id); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
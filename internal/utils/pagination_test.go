@@ -2,8 +2,11 @@ package utils
 
 import (
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCalculatePagination_BasicScenario(t *testing.T) {
@@ -131,3 +134,23 @@ func TestCalculatePagination_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestCursor_RoundTrip(t *testing.T) {
+	cursor := Cursor{
+		CreatedAt: time.Date(2024, 5, 10, 15, 4, 5, 123456789, time.UTC),
+		ID:        uuid.New(),
+	}
+
+	decoded, err := DecodeCursor(EncodeCursor(cursor))
+	require.NoError(t, err)
+
+	assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+	assert.Equal(t, cursor.ID, decoded.ID)
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	for _, encoded := range []string{"not base64!", "bm8tc2VwYXJhdG9y", "eWVzdGVyZGF5fDEyMw"} {
+		_, err := DecodeCursor(encoded)
+		assert.ErrorIs(t, err, ErrInvalidCursor, encoded)
+	}
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// sweepThreshold is the number of tracked keys above which expired windows are dropped
+const sweepThreshold = 10000
+
+// RateLimiter allows a fixed number of requests per key in each time window
+type RateLimiter struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	windows map[string]*rateWindow
+}
+
+// rateWindow counts requests of one key since the window started
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// NewRateLimiter creates a limiter allowing limit requests per window and key
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:   limit,
+		window:  window,
+		now:     time.Now,
+		windows: make(map[string]*rateWindow),
+	}
+}
+
+// Allow counts a request for key. It reports whether the request is within the
+// limit, how many requests remain in the window and when the window resets.
+func (l *RateLimiter) Allow(key string) (allowed bool, remaining int, resetIn time.Duration) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.windows) > sweepThreshold {
+		l.sweep(now)
+	}
+
+	current, exists := l.windows[key]
+	if !exists || now.Sub(current.start) >= l.window {
+		current = &rateWindow{start: now}
+		l.windows[key] = current
+	}
+
+	resetIn = current.start.Add(l.window).Sub(now)
+	if current.count >= l.limit {
+		return false, 0, resetIn
+	}
+
+	current.count++
+	return true, l.limit - current.count, resetIn
+}
+
+// Middleware creates middleware limiting requests per authenticated user, or
+// per client IP when the request carries no user. Place it after the auth middleware.
+func (l *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := "ip:" + c.ClientIP()
+		if userID, exists := c.Get("user_id"); exists {
+			if id, ok := userID.(uuid.UUID); ok {
+				key = "user:" + id.String()
+			}
+		}
+
+		allowed, remaining, resetIn := l.Allow(key)
+		c.Header("X-RateLimit-Limit", strconv.Itoa(l.limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(resetIn.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// sweep drops windows that have ended; the caller must hold l.mu
+func (l *RateLimiter) sweep(now time.Time) {
+	for key, current := range l.windows {
+		if now.Sub(current.start) >= l.window {
+			delete(l.windows, key)
+		}
+	}
+}
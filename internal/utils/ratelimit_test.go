@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(2, time.Minute)
+	limiter.now = func() time.Time { return now }
+
+	allowed, remaining, _ := limiter.Allow("a")
+	assert.True(t, allowed)
+	assert.Equal(t, 1, remaining)
+
+	allowed, remaining, _ = limiter.Allow("a")
+	assert.True(t, allowed)
+	assert.Equal(t, 0, remaining)
+
+	allowed, _, resetIn := limiter.Allow("a")
+	assert.False(t, allowed)
+	assert.Equal(t, time.Minute, resetIn)
+
+	// Keys are limited independently
+	allowed, _, _ = limiter.Allow("b")
+	assert.True(t, allowed)
+
+	// A new window starts once the old one has passed
+	now = now.Add(time.Minute)
+	allowed, _, _ = limiter.Allow("a")
+	assert.True(t, allowed)
+}
+
+func TestRateLimiter_Middleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := NewRateLimiter(1, time.Minute)
+	userID := uuid.New()
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if c.GetHeader("X-User") != "" {
+			c.Set("user_id", userID)
+		}
+		c.Next()
+	})
+	router.GET("/admin", limiter.Middleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(authenticated bool) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/admin", nil)
+		if authenticated {
+			req.Header.Set("X-User", "1")
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request(true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+	w = request(true)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// Anonymous requests are limited by IP, separately from the user
+	assert.Equal(t, http.StatusOK, request(false).Code)
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestBaseURL returns the scheme and host the client used to reach the API
+func RequestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// SetTrustedProxies makes the router believe X-Forwarded-For only from the
+// comma-separated proxy IPs or CIDRs. An empty list trusts no proxy, so the
+// client IP used by rate limits, lockouts and audit records is the peer's.
+func SetTrustedProxies(router *gin.Engine, list string) error {
+	var proxies []string
+	for _, entry := range strings.Split(list, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			proxies = append(proxies, entry)
+		}
+	}
+	if err := router.SetTrustedProxies(proxies); err != nil {
+		return fmt.Errorf("invalid trusted proxies '%s': %v", list, err)
+	}
+	return nil
+}
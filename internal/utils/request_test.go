@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	clientIP := func(list, remoteAddr string) string {
+		router := gin.New()
+		require.NoError(t, SetTrustedProxies(router, list))
+		router.GET("/", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", "203.0.113.9")
+		router.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	assert.Equal(t, "192.0.2.1", clientIP("", "192.0.2.1:1234"), "no proxy is trusted by default")
+	assert.Equal(t, "203.0.113.9", clientIP("10.0.0.0/8, 192.0.2.1", "192.0.2.1:1234"))
+	assert.Equal(t, "198.51.100.7", clientIP("192.0.2.1", "198.51.100.7:1234"), "other peers are not trusted")
+
+	assert.Error(t, SetTrustedProxies(gin.New(), "not-an-ip"))
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// User roles limiting what an account may do, whatever its token's scopes
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// IsValidRole checks if role is one of the known roles
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin
+}
+
+// ScopesForRole returns the scopes granted to tokens issued at login
+func ScopesForRole(role string) []string {
+	if role == RoleAdmin {
+		return []string{ScopeRead, ScopeWrite, ScopeAdmin}
+	}
+	return DefaultScopes
+}
+
+// GetRoleFromContext returns the role of the current user stored by the auth
+// middleware
+func GetRoleFromContext(c *gin.Context) string {
+	if value, exists := c.Get("role"); exists {
+		if role, ok := value.(string); ok {
+			return role
+		}
+	}
+	return ""
+}
+
+// RequireRole creates middleware rejecting requests from users without one of
+// the roles. The role is read from the user on every request, so demoting a
+// user takes effect before their tokens expire.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := GetRoleFromContext(c)
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient role", "required_roles": roles})
+		c.Abort()
+	}
+}
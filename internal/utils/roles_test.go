@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopesForRole(t *testing.T) {
+	assert.True(t, HasScope(ScopesForRole(RoleAdmin), ScopeAdmin))
+	assert.False(t, HasScope(ScopesForRole(RoleUser), ScopeAdmin))
+	assert.Equal(t, DefaultScopes, ScopesForRole(""))
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(role string) *gin.Engine {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			if role != "" {
+				c.Set("role", role)
+			}
+			c.Next()
+		})
+		router.GET("/admin", RequireRole(RoleAdmin), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return router
+	}
+
+	req, _ := http.NewRequest("GET", "/admin", nil)
+	for role, status := range map[string]int{"": http.StatusForbidden, RoleUser: http.StatusForbidden, RoleAdmin: http.StatusOK} {
+		w := httptest.NewRecorder()
+		newRouter(role).ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, role)
+	}
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Token scopes limiting what a JWT may be used for
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+
+	// ScopeMLExport grants access to anonymized training data exports
+	ScopeMLExport = "ml_export"
+)
+
+// DefaultScopes are granted to tokens issued at login and to legacy tokens without a scopes claim
+var DefaultScopes = []string{ScopeRead, ScopeWrite}
+
+// IsValidScope checks if scope is one of the known scopes
+func IsValidScope(scope string) bool {
+	return scope == ScopeRead || scope == ScopeWrite || scope == ScopeAdmin || scope == ScopeMLExport
+}
+
+// HasScope checks if the granted scopes satisfy the required scope.
+// The admin scope satisfies every requirement.
+func HasScope(granted []string, required string) bool {
+	for _, scope := range granted {
+		if scope == required || scope == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeForMethod returns the scope required to perform an HTTP method
+func ScopeForMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ScopeRead
+	default:
+		return ScopeWrite
+	}
+}
+
+// GetScopesFromContext returns the scopes stored by the auth middleware
+func GetScopesFromContext(c *gin.Context) []string {
+	if value, exists := c.Get("scopes"); exists {
+		if scopes, ok := value.([]string); ok {
+			return scopes
+		}
+	}
+	return nil
+}
+
+// RequireScope creates middleware rejecting requests whose token lacks the scope
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasScope(GetScopesFromContext(c), scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient scope", "required_scope": scope})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasScope(t *testing.T) {
+	assert.True(t, HasScope([]string{ScopeRead}, ScopeRead))
+	assert.False(t, HasScope([]string{ScopeRead}, ScopeWrite))
+	assert.True(t, HasScope([]string{ScopeAdmin}, ScopeWrite))
+	assert.False(t, HasScope(nil, ScopeRead))
+}
+
+func TestScopeForMethod(t *testing.T) {
+	assert.Equal(t, ScopeRead, ScopeForMethod(http.MethodGet))
+	assert.Equal(t, ScopeRead, ScopeForMethod(http.MethodHead))
+	assert.Equal(t, ScopeWrite, ScopeForMethod(http.MethodPost))
+	assert.Equal(t, ScopeWrite, ScopeForMethod(http.MethodDelete))
+}
+
+func TestRequireScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(scopes []string) *gin.Engine {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("scopes", scopes)
+			c.Next()
+		})
+		router.GET("/admin", RequireScope(ScopeAdmin), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return router
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin", nil)
+	newRouter(DefaultScopes).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	newRouter([]string{ScopeAdmin}).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
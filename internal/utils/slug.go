@@ -0,0 +1,221 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// MaxSlugLength bounds generated slugs, excluding collision suffixes
+	MaxSlugLength = 80
+	// maxSlugAttempts is the number of numbered suffixes tried before falling back to a random one
+	maxSlugAttempts = 20
+)
+
+// Combining marks left behind by NFKD decomposition of voiced kana
+const (
+	kanaVoicedMark     = '\u3099'
+	kanaSemiVoicedMark = '\u309A'
+)
+
+// letterFolds spells out Latin letters that do not decompose into ASCII
+var letterFolds = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d",
+	'ł': "l", 'þ': "th", 'ı': "i", 'ħ': "h", 'ŋ': "ng",
+}
+
+// hiragana romanizes the unvoiced hiragana; katakana is shifted onto this range
+// and voicing marks are applied afterwards. Small kana share their full-size reading.
+var hiragana = map[rune]string{
+	'ぁ': "a", 'あ': "a", 'ぃ': "i", 'い': "i", 'ぅ': "u", 'う': "u", 'ぇ': "e", 'え': "e", 'ぉ': "o", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'た': "ta", 'ち': "chi", 'っ': "", 'つ': "tsu", 'て': "te", 'と': "to",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'ゃ': "ya", 'や': "ya", 'ゅ': "yu", 'ゆ': "yu", 'ょ': "yo", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'ゎ': "wa", 'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n",
+}
+
+// voiced maps the romanization of a kana to its voiced (dakuten) form
+var voiced = map[string]string{
+	"ka": "ga", "ki": "gi", "ku": "gu", "ke": "ge", "ko": "go",
+	"sa": "za", "shi": "ji", "su": "zu", "se": "ze", "so": "zo",
+	"ta": "da", "chi": "ji", "tsu": "zu", "te": "de", "to": "do",
+	"ha": "ba", "hi": "bi", "fu": "bu", "he": "be", "ho": "bo",
+	"u": "vu",
+}
+
+// semiVoiced maps the romanization of a kana to its semi-voiced (handakuten) form
+var semiVoiced = map[string]string{
+	"ha": "pa", "hi": "pi", "fu": "pu", "he": "pe", "ho": "po",
+}
+
+// Bounds of the precomposed Hangul syllable block
+const (
+	hangulFirst = '\uAC00'
+	hangulLast  = '\uD7A3'
+)
+
+// Revised Romanization of Korean jamo, indexed as in the Unicode Hangul syllable block
+var (
+	hangulInitials = []string{"g", "kk", "n", "d", "tt", "r", "m", "b", "pp", "s", "ss", "", "j", "jj", "ch", "k", "t", "p", "h"}
+	hangulMedials  = []string{"a", "ae", "ya", "yae", "eo", "e", "yeo", "ye", "o", "wa", "wae", "oe", "yo", "u", "wo", "we", "wi", "yu", "eu", "ui", "i"}
+	hangulFinals   = []string{"", "k", "k", "k", "n", "n", "n", "t", "l", "k", "m", "l", "l", "l", "p", "l", "m", "p", "p", "t", "t", "ng", "t", "t", "k", "t", "p", "t"}
+)
+
+// Slugify turns a title into a lowercase, hyphen-separated, URL-safe handle.
+// Accented Latin letters are folded to ASCII and Japanese kana and Korean Hangul
+// are romanized. Scripts without a reading table here (Han characters, Cyrillic,
+// Greek, ...) are kept as Unicode letters, which browsers display as-is and
+// percent-encode on the wire. Returns an empty string if nothing usable remains.
+func Slugify(title string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	lastKana := ""
+
+	write := func(s string) {
+		if s == "" {
+			return
+		}
+		if pendingHyphen && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		pendingHyphen = false
+		b.WriteString(s)
+	}
+
+	for _, original := range title {
+		// Hangul is romanized before normalization, which would split syllables into jamo
+		if original >= hangulFirst && original <= hangulLast {
+			lastKana = ""
+			write(romanizeHangul(original))
+			continue
+		}
+
+		for _, r := range norm.NFKD.String(string(original)) {
+			r = unicode.ToLower(r)
+
+			// Voicing marks rewrite the preceding kana
+			if r == kanaVoicedMark || r == kanaSemiVoicedMark {
+				table := voiced
+				if r == kanaSemiVoicedMark {
+					table = semiVoiced
+				}
+				if replacement, ok := table[lastKana]; ok {
+					current := b.String()
+					b.Reset()
+					b.WriteString(strings.TrimSuffix(current, lastKana) + replacement)
+					lastKana = replacement
+				}
+				continue
+			}
+			if unicode.Is(unicode.Mn, r) {
+				continue
+			}
+
+			kana, isKana := romanizeKana(r)
+			if isKana {
+				write(kana)
+				lastKana = kana
+				continue
+			}
+			lastKana = ""
+
+			switch {
+			case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+				write(string(r))
+			case letterFolds[r] != "":
+				write(letterFolds[r])
+			case unicode.IsLetter(r) || unicode.IsDigit(r):
+				write(string(r))
+			default:
+				pendingHyphen = true
+			}
+		}
+	}
+
+	return truncateSlug(b.String())
+}
+
+// UniqueSlug returns base, or base with a numeric suffix, such that exists
+// reports it as free. An empty base gets a random handle.
+func UniqueSlug(base string, exists func(slug string) (bool, error)) (string, error) {
+	if base == "" {
+		base = randomSlug()
+	}
+
+	for attempt := 1; attempt <= maxSlugAttempts; attempt++ {
+		candidate := base
+		if attempt > 1 {
+			candidate += "-" + strconv.Itoa(attempt)
+		}
+
+		taken, err := exists(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+
+	// Heavily reused titles get a random suffix rather than probing forever
+	candidate := base + "-" + randomSlug()
+	taken, err := exists(candidate)
+	if err != nil {
+		return "", err
+	}
+	if taken {
+		return "", errors.New("could not generate a unique slug")
+	}
+	return candidate, nil
+}
+
+func romanizeKana(r rune) (string, bool) {
+	// Katakana sits 0x60 code points above the matching hiragana
+	if r >= 'ァ' && r <= 'ヶ' {
+		r -= 0x60
+	}
+	if r == 'ー' {
+		return "", true // Long vowel mark
+	}
+	romaji, ok := hiragana[r]
+	return romaji, ok
+}
+
+func romanizeHangul(r rune) string {
+	index := int(r - hangulFirst)
+	initial := index / (21 * 28)
+	medial := (index % (21 * 28)) / 28
+	final := index % 28
+	return hangulInitials[initial] + hangulMedials[medial] + hangulFinals[final]
+}
+
+// truncateSlug caps the slug length on a rune boundary, preferring to cut at a hyphen
+func truncateSlug(slug string) string {
+	runes := []rune(slug)
+	if len(runes) > MaxSlugLength {
+		slug = string(runes[:MaxSlugLength])
+		if cut := strings.LastIndexByte(slug, '-'); cut > MaxSlugLength/2 {
+			slug = slug[:cut]
+		}
+	}
+	return strings.Trim(slug, "-")
+}
+
+func randomSlug() string {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return "page"
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string
+		expected string
+	}{
+		{"ASCII title", "Hello, World!", "hello-world"},
+		{"Collapses separators", "  Go -- The   Good Parts  ", "go-the-good-parts"},
+		{"Folds accents", "Crème Brûlée à Paris", "creme-brulee-a-paris"},
+		{"Spells out special letters", "Straße Øresund", "strasse-oresund"},
+		{"Romanizes hiragana", "こんにちは", "konnichiha"},
+		{"Romanizes voiced katakana", "ゲーム デザイン", "gemu-dezain"},
+		{"Romanizes half-width katakana", "ｶﾞｲﾄﾞ", "gaido"},
+		{"Romanizes Hangul", "안녕 세계", "annyeong-segye"},
+		{"Keeps Han characters", "Go 语言入门", "go-语言入门"},
+		{"Keeps Cyrillic", "Привет мир", "привет-мир"},
+		{"Nothing usable", "!!! ???", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Slugify(tt.title))
+		})
+	}
+}
+
+func TestSlugify_Truncates(t *testing.T) {
+	slug := Slugify(strings.Repeat("word ", 40))
+
+	assert.LessOrEqual(t, utf8.RuneCountInString(slug), MaxSlugLength)
+	assert.False(t, strings.HasSuffix(slug, "-"))
+	assert.True(t, strings.HasSuffix(slug, "word"))
+}
+
+func TestUniqueSlug(t *testing.T) {
+	taken := map[string]bool{"my-article": true, "my-article-2": true}
+	exists := func(slug string) (bool, error) { return taken[slug], nil }
+
+	t.Run("Returns free base", func(t *testing.T) {
+		slug, err := UniqueSlug("other-article", exists)
+		require.NoError(t, err)
+		assert.Equal(t, "other-article", slug)
+	})
+
+	t.Run("Adds numeric suffix on collision", func(t *testing.T) {
+		slug, err := UniqueSlug("my-article", exists)
+		require.NoError(t, err)
+		assert.Equal(t, "my-article-3", slug)
+	})
+
+	t.Run("Falls back to random suffix", func(t *testing.T) {
+		// Every numbered candidate is taken
+		slug, err := UniqueSlug("popular", func(slug string) (bool, error) {
+			return len(slug) <= len("popular-20"), nil
+		})
+		require.NoError(t, err)
+		assert.Regexp(t, `^popular-[0-9a-f]{8}$`, slug)
+	})
+
+	t.Run("Generates handle for empty base", func(t *testing.T) {
+		slug, err := UniqueSlug("", exists)
+		require.NoError(t, err)
+		assert.NotEmpty(t, slug)
+	})
+
+	t.Run("Propagates lookup errors", func(t *testing.T) {
+		_, err := UniqueSlug("my-article", func(string) (bool, error) {
+			return false, errors.New("database down")
+		})
+		assert.EqualError(t, err, "database down")
+	})
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// Input limits shared by all services
+const (
+	MaxURLLength         = 2048
+	MaxTitleLength       = 500
+	MaxDescriptionLength = 5000
+	MaxSiteNameLength    = 200
+	MaxNotesLength       = 10000
+)
+
+// ValidationError describes why a user-supplied field was rejected
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// NewValidationError creates a validation error for the given field
+func NewValidationError(field, message string) *ValidationError {
+	return &ValidationError{Field: field, Message: message}
+}
+
+// AsValidationError reports whether err is a validation error and returns it
+func AsValidationError(err error) (*ValidationError, bool) {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr, true
+	}
+	return nil, false
+}
+
+// NormalizeURL validates a user-supplied URL and returns its canonical form.
+// Only absolute http(s) URLs are accepted; the host is lowercased and
+// converted to punycode so equivalent URLs compare equal.
+func NormalizeURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", NewValidationError("url", "is required")
+	}
+	if len(raw) > MaxURLLength {
+		return "", NewValidationError("url", "must be at most "+IntToString(MaxURLLength)+" characters")
+	}
+	if strings.IndexFunc(raw, unicode.IsControl) >= 0 {
+		return "", NewValidationError("url", "must not contain control characters")
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", NewValidationError("url", "is not a valid URL")
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", NewValidationError("url", "scheme must be http or https")
+	}
+
+	hostname := parsed.Hostname()
+	if hostname == "" {
+		return "", NewValidationError("url", "must include a host")
+	}
+
+	asciiHost, err := idna.Lookup.ToASCII(strings.ToLower(hostname))
+	if err != nil {
+		return "", NewValidationError("url", "has an invalid host")
+	}
+
+	if port := parsed.Port(); port != "" {
+		parsed.Host = asciiHost + ":" + port
+	} else {
+		parsed.Host = asciiHost
+	}
+	parsed.Fragment = ""
+
+	normalized := parsed.String()
+	if len(normalized) > MaxURLLength {
+		return "", NewValidationError("url", "must be at most "+IntToString(MaxURLLength)+" characters")
+	}
+
+	return normalized, nil
+}
+
+// SanitizeText strips control characters (keeping newlines and tabs), trims
+// surrounding whitespace and truncates the result to maxLen runes
+func SanitizeText(text string, maxLen int) string {
+	text = strings.ToValidUTF8(text, "")
+	text = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, text)
+	text = strings.TrimSpace(text)
+
+	if maxLen > 0 && utf8.RuneCountInString(text) > maxLen {
+		text = strings.TrimSpace(string([]rune(text)[:maxLen]))
+	}
+
+	return text
+}
+
+// ValidateText sanitizes a text field and rejects it when it exceeds maxLen
+func ValidateText(field, text string, maxLen int) (string, error) {
+	cleaned := SanitizeText(text, 0)
+	if maxLen > 0 && utf8.RuneCountInString(cleaned) > maxLen {
+		return "", NewValidationError(field, "must be at most "+IntToString(maxLen)+" characters")
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeURL_Valid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Plain https", "https://example.com/article", "https://example.com/article"},
+		{"Trims whitespace", "  https://example.com/a  ", "https://example.com/a"},
+		{"Lowercases scheme and host", "HTTPS://Example.COM/Path", "https://example.com/Path"},
+		{"Drops fragment", "https://example.com/a#section", "https://example.com/a"},
+		{"Keeps port", "http://example.com:8080/a", "http://example.com:8080/a"},
+		{"Punycode host", "https://bücher.de/buch", "https://xn--bcher-kva.de/buch"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := NormalizeURL(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestNormalizeURL_Invalid(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{"Empty", ""},
+		{"Unsupported scheme", "ftp://example.com/file"},
+		{"Javascript scheme", "javascript:alert(1)"},
+		{"Missing host", "https:///path"},
+		{"Control characters", "https://example.com/\x00a"},
+		{"Too long", "https://example.com/" + strings.Repeat("a", MaxURLLength)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NormalizeURL(tc.input)
+			require.Error(t, err)
+
+			validationErr, ok := AsValidationError(err)
+			require.True(t, ok)
+			assert.Equal(t, "url", validationErr.Field)
+		})
+	}
+}
+
+func TestSanitizeText(t *testing.T) {
+	assert.Equal(t, "Hello world", SanitizeText("  Hello\x00 world\x07 ", 0))
+	assert.Equal(t, "line one\nline two", SanitizeText("line one\nline two", 0))
+	assert.Equal(t, "héllo", SanitizeText("héllo wörld", 5))
+	assert.Equal(t, "", SanitizeText("\x01\x02", 10))
+}
+
+func TestValidateText(t *testing.T) {
+	cleaned, err := ValidateText("title", " Title\x00 ", 10)
+	require.NoError(t, err)
+	assert.Equal(t, "Title", cleaned)
+
+	_, err = ValidateText("title", strings.Repeat("a", 11), 10)
+	require.Error(t, err)
+
+	validationErr, ok := AsValidationError(err)
+	require.True(t, ok)
+	assert.Equal(t, "title", validationErr.Field)
+	assert.Contains(t, validationErr.Error(), "at most 10 characters")
+}
@@ -0,0 +1,389 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+)
+
+// Priority classes of queued tasks, highest first
+type Priority int
+
+const (
+	PriorityInteractive Priority = iota // A user is waiting for the result
+	PriorityImport                      // Bulk imports
+	PriorityRetry                       // Retries of failed work
+)
+
+// priorities lists every class in order
+var priorities = []Priority{PriorityInteractive, PriorityImport, PriorityRetry}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityImport:
+		return "import"
+	case PriorityRetry:
+		return "retry"
+	}
+	return "priority-" + strconv.Itoa(int(p))
+}
+
+// TaskFunc is a unit of queued work
+type TaskFunc func() error
+
+// QueueStats describes one priority class of a queue
+type QueueStats struct {
+	Depth             int     `json:"depth"`               // Tasks waiting to run
+	Running           int     `json:"running"`             // Tasks being run now
+	Processed         int64   `json:"processed"`           // Tasks finished since start
+	Failed            int64   `json:"failed"`              // Finished tasks that returned an error
+	OldestWaitSeconds float64 `json:"oldest_wait_seconds"` // Age of the oldest waiting task
+	Deduplicated      int64   `json:"deduplicated"`        // Tasks collapsed into one with the same key
+}
+
+type queuedTask struct {
+	key        string
+	run        TaskFunc
+	priority   Priority
+	enqueuedAt time.Time
+	started    bool
+	done       chan struct{} // Closed once the task has run
+	err        error         // Set before done is closed
+}
+
+// priorityClass holds the waiting tasks and counters of one priority
+type priorityClass struct {
+	tasks        []*queuedTask
+	weight       int
+	credit       int // Smooth weighted round-robin state
+	running      int
+	processed    int64
+	failed       int64
+	deduplicated int64
+}
+
+// PriorityQueue runs tasks on a fixed pool of goroutines, favouring higher
+// priorities by weight. Every non-empty class is served in proportion to its
+// weight, so lower classes always make progress, and a task that has waited
+// longer than maxWait runs next regardless of class.
+type PriorityQueue struct {
+	name        string
+	concurrency int
+	maxWait     time.Duration
+	logger      *logger.Logger
+
+	mu       sync.Mutex
+	wake     *sync.Cond
+	classes  map[Priority]*priorityClass
+	keys     map[string]*queuedTask // Waiting and running tasks by key, to collapse duplicates
+	started  bool
+	stopping bool
+	wg       sync.WaitGroup
+	now      func() time.Time
+}
+
+// NewPriorityQueue creates a priority queue with validation and defaults
+func NewPriorityQueue(cfg *config.QueueConfig, name string, log *logger.Logger) (*PriorityQueue, error) {
+	concurrency := 4
+	if cfg != nil && cfg.Concurrency != "" {
+		parsed, err := strconv.Atoi(cfg.Concurrency)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid queue concurrency '%s': must be a positive integer", cfg.Concurrency)
+		}
+		concurrency = parsed
+	}
+
+	maxWait := 2 * time.Minute
+	if cfg != nil && cfg.MaxWait != "" {
+		parsed, err := time.ParseDuration(cfg.MaxWait)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid queue max wait '%s': must be a positive duration", cfg.MaxWait)
+		}
+		maxWait = parsed
+	}
+
+	weights := map[Priority]int{PriorityInteractive: 6, PriorityImport: 3, PriorityRetry: 1}
+	if cfg != nil {
+		configured := map[Priority]string{
+			PriorityInteractive: cfg.InteractiveWeight,
+			PriorityImport:      cfg.ImportWeight,
+			PriorityRetry:       cfg.RetryWeight,
+		}
+		for priority, value := range configured {
+			if value == "" {
+				continue
+			}
+			weight, err := strconv.Atoi(value)
+			if err != nil || weight <= 0 {
+				return nil, fmt.Errorf("invalid %s queue weight '%s': must be a positive integer", priority, value)
+			}
+			weights[priority] = weight
+		}
+	}
+
+	q := &PriorityQueue{
+		name:        name,
+		concurrency: concurrency,
+		maxWait:     maxWait,
+		logger:      log.WithComponent("priority-queue"),
+		classes:     make(map[Priority]*priorityClass),
+		keys:        make(map[string]*queuedTask),
+		now:         time.Now,
+	}
+	q.wake = sync.NewCond(&q.mu)
+	for _, priority := range priorities {
+		q.classes[priority] = &priorityClass{weight: weights[priority]}
+	}
+
+	return q, nil
+}
+
+// Enqueue adds a task to the queue. The key deduplicates work: a task is
+// dropped while another with the same key is waiting or running, since that
+// one will do the same work. The return value reports whether the task was
+// queued.
+func (q *PriorityQueue) Enqueue(priority Priority, key string, run TaskFunc) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	class, ok := q.classes[priority]
+	if !ok || q.stopping {
+		return false
+	}
+	if _, exists := q.keys[key]; key != "" && exists {
+		class.deduplicated++
+		return false
+	}
+
+	task := &queuedTask{key: key, run: run, priority: priority, enqueuedAt: q.now(), done: make(chan struct{})}
+	if key != "" {
+		q.keys[key] = task
+	}
+	class.tasks = append(class.tasks, task)
+	q.wake.Signal()
+	return true
+}
+
+// Run runs a task on the caller's goroutine, for callers waiting on the
+// result, and collapses it with queued work of the same key. A waiting task
+// is taken off the queue and this run replaces it; if one is already running,
+// Run waits for it and returns its error instead of running again. Collapsed
+// runs are counted as deduplicated interactive tasks.
+func (q *PriorityQueue) Run(key string, run TaskFunc) error {
+	if key == "" {
+		return q.run(&queuedTask{run: run})
+	}
+
+	q.mu.Lock()
+	if existing, ok := q.keys[key]; ok {
+		q.classes[PriorityInteractive].deduplicated++
+		if existing.started {
+			q.mu.Unlock()
+			<-existing.done
+			return existing.err
+		}
+		q.remove(existing)
+	}
+	task := &queuedTask{key: key, run: run, priority: PriorityInteractive, enqueuedAt: q.now(), started: true, done: make(chan struct{})}
+	q.keys[key] = task
+	q.mu.Unlock()
+
+	err := q.run(task)
+
+	q.mu.Lock()
+	q.release(task, err)
+	q.mu.Unlock()
+	return err
+}
+
+// Start launches the goroutines that run queued tasks
+func (q *PriorityQueue) Start() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.started {
+		return errors.New("queue already started")
+	}
+	q.started = true
+
+	q.logger.Info("Starting priority queue " + q.name + " with " + strconv.Itoa(q.concurrency) + " workers")
+	for i := 0; i < q.concurrency; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+
+	return nil
+}
+
+// Stop stops accepting tasks and waits for running tasks to finish. Tasks
+// still waiting are dropped.
+func (q *PriorityQueue) Stop() error {
+	q.mu.Lock()
+	q.stopping = true
+	dropped := 0
+	for _, class := range q.classes {
+		dropped += len(class.tasks)
+		for _, task := range class.tasks {
+			q.release(task, errors.New("queue stopped"))
+		}
+		class.tasks = nil
+	}
+	q.wake.Broadcast()
+	q.mu.Unlock()
+
+	q.wg.Wait()
+
+	q.logger.Info("Priority queue " + q.name + " stopped, dropped " + strconv.Itoa(dropped) + " waiting tasks")
+	return nil
+}
+
+// Stats reports the state of each priority class, keyed by class name
+func (q *PriorityQueue) Stats() map[string]QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	stats := make(map[string]QueueStats, len(q.classes))
+	for _, priority := range priorities {
+		class := q.classes[priority]
+		classStats := QueueStats{
+			Depth:        len(class.tasks),
+			Running:      class.running,
+			Processed:    class.processed,
+			Failed:       class.failed,
+			Deduplicated: class.deduplicated,
+		}
+		if len(class.tasks) > 0 {
+			classStats.OldestWaitSeconds = now.Sub(class.tasks[0].enqueuedAt).Seconds()
+		}
+		stats[priority.String()] = classStats
+	}
+	return stats
+}
+
+// work runs tasks until the queue stops
+func (q *PriorityQueue) work() {
+	defer q.wg.Done()
+
+	for {
+		q.mu.Lock()
+		priority, task := q.next()
+		for task == nil && !q.stopping {
+			q.wake.Wait()
+			priority, task = q.next()
+		}
+		if task == nil {
+			q.mu.Unlock()
+			return
+		}
+		class := q.classes[priority]
+		class.running++
+		q.mu.Unlock()
+
+		err := q.run(task)
+
+		q.mu.Lock()
+		class.running--
+		class.processed++
+		if err != nil {
+			class.failed++
+		}
+		q.release(task, err)
+		q.mu.Unlock()
+	}
+}
+
+// run executes a task, turning panics into errors so one bad task cannot stop a worker
+func (q *PriorityQueue) run(task *queuedTask) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("task panicked: %v", r)
+		}
+		if err != nil {
+			q.logger.Error("Task " + task.key + " in queue " + q.name + " failed: " + err.Error())
+		}
+	}()
+	return task.run()
+}
+
+// next removes and returns the task to run next, or nil if none is waiting.
+// The task keeps its key until released. Callers must hold q.mu.
+func (q *PriorityQueue) next() (Priority, *queuedTask) {
+	now := q.now()
+
+	// Starvation guard: a task that waited too long runs first, oldest first
+	var picked *priorityClass
+	var pickedPriority Priority
+	var oldest time.Time
+	for _, priority := range priorities {
+		class := q.classes[priority]
+		if len(class.tasks) == 0 {
+			continue
+		}
+		enqueuedAt := class.tasks[0].enqueuedAt
+		if now.Sub(enqueuedAt) >= q.maxWait && (picked == nil || enqueuedAt.Before(oldest)) {
+			picked, pickedPriority, oldest = class, priority, enqueuedAt
+		}
+	}
+
+	// Otherwise smooth weighted round-robin over the classes with waiting tasks
+	if picked == nil {
+		total := 0
+		for _, priority := range priorities {
+			class := q.classes[priority]
+			if len(class.tasks) == 0 {
+				continue
+			}
+			class.credit += class.weight
+			total += class.weight
+			if picked == nil || class.credit > picked.credit {
+				picked, pickedPriority = class, priority
+			}
+		}
+		if picked == nil {
+			return 0, nil
+		}
+		picked.credit -= total
+	}
+
+	task := picked.tasks[0]
+	picked.tasks[0] = nil
+	picked.tasks = picked.tasks[1:]
+	if len(picked.tasks) == 0 {
+		picked.credit = 0 // An idle class does not bank credit
+	}
+	task.started = true
+
+	return pickedPriority, task
+}
+
+// remove takes a waiting task off its class. Callers must hold q.mu.
+func (q *PriorityQueue) remove(task *queuedTask) {
+	class := q.classes[task.priority]
+	for i, waiting := range class.tasks {
+		if waiting == task {
+			class.tasks = append(class.tasks[:i], class.tasks[i+1:]...)
+			break
+		}
+	}
+	if len(class.tasks) == 0 {
+		class.credit = 0
+	}
+}
+
+// release frees the key of a finished or dropped task and wakes callers
+// waiting on it. Callers must hold q.mu.
+func (q *PriorityQueue) release(task *queuedTask, err error) {
+	if q.keys[task.key] == task {
+		delete(q.keys, task.key)
+	}
+	task.err = err
+	close(task.done)
+}
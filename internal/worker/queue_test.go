@@ -0,0 +1,189 @@
+package worker
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestQueue(t *testing.T, cfg *config.QueueConfig) *PriorityQueue {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	q, err := NewPriorityQueue(cfg, "test-queue", log)
+	require.NoError(t, err)
+	return q
+}
+
+func TestNewPriorityQueue(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	q, err := NewPriorityQueue(nil, "defaults", log)
+	require.NoError(t, err)
+	assert.Equal(t, 4, q.concurrency)
+	assert.Equal(t, 2*time.Minute, q.maxWait)
+	assert.Equal(t, 6, q.classes[PriorityInteractive].weight)
+
+	invalid := []*config.QueueConfig{
+		{Concurrency: "0"},
+		{MaxWait: "soon"},
+		{InteractiveWeight: "-1"},
+		{RetryWeight: "none"},
+	}
+	for _, cfg := range invalid {
+		_, err := NewPriorityQueue(cfg, "invalid", log)
+		assert.Error(t, err)
+	}
+}
+
+func TestPriorityQueue_WeightedOrder(t *testing.T) {
+	q := newTestQueue(t, &config.QueueConfig{InteractiveWeight: "3", ImportWeight: "2", RetryWeight: "1"})
+
+	noop := func() error { return nil }
+	for i := 0; i < 10; i++ {
+		q.Enqueue(PriorityInteractive, "", noop)
+		q.Enqueue(PriorityImport, "", noop)
+		q.Enqueue(PriorityRetry, "", noop)
+	}
+
+	counts := make(map[Priority]int)
+	for i := 0; i < 6; i++ {
+		priority, task := q.next()
+		require.NotNil(t, task)
+		counts[priority]++
+	}
+
+	// Each class gets its share of a full round, so lower classes are never starved
+	assert.Equal(t, map[Priority]int{PriorityInteractive: 3, PriorityImport: 2, PriorityRetry: 1}, counts)
+}
+
+func TestPriorityQueue_EmptyClassesDoNotBlock(t *testing.T) {
+	q := newTestQueue(t, nil)
+
+	noop := func() error { return nil }
+	q.Enqueue(PriorityRetry, "", noop)
+	q.Enqueue(PriorityRetry, "", noop)
+
+	for i := 0; i < 2; i++ {
+		priority, task := q.next()
+		require.NotNil(t, task)
+		assert.Equal(t, PriorityRetry, priority)
+	}
+
+	_, task := q.next()
+	assert.Nil(t, task)
+}
+
+func TestPriorityQueue_StarvationGuard(t *testing.T) {
+	q := newTestQueue(t, &config.QueueConfig{MaxWait: "1m", RetryWeight: "1", InteractiveWeight: "100"})
+	start := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	q.now = func() time.Time { return start }
+
+	noop := func() error { return nil }
+	q.Enqueue(PriorityRetry, "old-retry", noop)
+
+	q.now = func() time.Time { return start.Add(61 * time.Second) }
+	q.Enqueue(PriorityInteractive, "", noop)
+	q.Enqueue(PriorityInteractive, "", noop)
+
+	priority, task := q.next()
+	require.NotNil(t, task)
+	assert.Equal(t, PriorityRetry, priority, "a task waiting longer than max wait runs first")
+	assert.Equal(t, "old-retry", task.key)
+}
+
+func TestPriorityQueue_DeduplicatesKeys(t *testing.T) {
+	q := newTestQueue(t, nil)
+
+	noop := func() error { return nil }
+	assert.True(t, q.Enqueue(PriorityImport, "article:1", noop))
+	assert.False(t, q.Enqueue(PriorityRetry, "article:1", noop))
+	assert.Equal(t, 1, q.Stats()["import"].Depth)
+	assert.Equal(t, 0, q.Stats()["retry"].Depth)
+	assert.Equal(t, int64(1), q.Stats()["retry"].Deduplicated)
+
+	// A running task still holds its key; once it finishes the key is free
+	_, task := q.next()
+	require.NotNil(t, task)
+	assert.False(t, q.Enqueue(PriorityRetry, "article:1", noop))
+	q.release(task, nil)
+	assert.True(t, q.Enqueue(PriorityRetry, "article:1", noop))
+	assert.Equal(t, int64(2), q.Stats()["retry"].Deduplicated)
+}
+
+func TestPriorityQueue_RunCollapsesWithQueuedTask(t *testing.T) {
+	t.Run("Replaces a waiting task", func(t *testing.T) {
+		q := newTestQueue(t, nil)
+
+		queuedRan := false
+		require.True(t, q.Enqueue(PriorityRetry, "article:1", func() error { queuedRan = true; return nil }))
+
+		ran := false
+		require.NoError(t, q.Run("article:1", func() error { ran = true; return nil }))
+		assert.True(t, ran)
+		assert.Equal(t, 0, q.Stats()["retry"].Depth, "the waiting task is taken off the queue")
+		assert.Equal(t, int64(1), q.Stats()["interactive"].Deduplicated)
+
+		_, task := q.next()
+		assert.Nil(t, task)
+		assert.False(t, queuedRan)
+	})
+
+	t.Run("Waits for a running task", func(t *testing.T) {
+		q := newTestQueue(t, &config.QueueConfig{Concurrency: "1"})
+
+		started := make(chan struct{})
+		finish := make(chan struct{})
+		require.True(t, q.Enqueue(PriorityRetry, "article:1", func() error {
+			close(started)
+			<-finish
+			return errors.New("fetch failed")
+		}))
+		require.NoError(t, q.Start())
+		defer q.Stop()
+		<-started
+
+		result := make(chan error, 1)
+		go func() {
+			result <- q.Run("article:1", func() error {
+				t.Error("a collapsed run must not run again")
+				return nil
+			})
+		}()
+
+		// The caller gets the running task's outcome
+		require.Eventually(t, func() bool { return q.Stats()["interactive"].Deduplicated == 1 }, time.Second, time.Millisecond)
+		close(finish)
+		assert.EqualError(t, <-result, "fetch failed")
+	})
+}
+
+func TestPriorityQueue_RunsTasks(t *testing.T) {
+	q := newTestQueue(t, &config.QueueConfig{Concurrency: "2"})
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	q.Enqueue(PriorityInteractive, "ok", func() error { defer wg.Done(); return nil })
+	q.Enqueue(PriorityImport, "fails", func() error { defer wg.Done(); return errors.New("extraction failed") })
+	q.Enqueue(PriorityRetry, "panics", func() error { defer wg.Done(); panic("boom") })
+
+	require.NoError(t, q.Start())
+	assert.Error(t, q.Start())
+	wg.Wait()
+	require.NoError(t, q.Stop())
+
+	stats := q.Stats()
+	assert.Equal(t, int64(1), stats["interactive"].Processed)
+	assert.Equal(t, int64(0), stats["interactive"].Failed)
+	assert.Equal(t, int64(1), stats["import"].Failed)
+	assert.Equal(t, int64(1), stats["retry"].Failed)
+
+	assert.False(t, q.Enqueue(PriorityInteractive, "late", func() error { return nil }), "a stopped queue accepts no tasks")
+}
@@ -2,9 +2,11 @@ package worker
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/internal/utils"
 	"github.com/dustin/articles-backend/pkg/logger"
 	"github.com/robfig/cron/v3"
 )
@@ -12,14 +14,23 @@ import (
 // RetryFunc defines the function signature for retry operations
 type RetryFunc func() error
 
+// ErrBusy is returned when a run is triggered while the operation is running
+var ErrBusy = utils.NewConflictError("worker is busy")
+
 // RetryWorker runs scheduled retry operations with configurable intervals
 type RetryWorker struct {
 	name          string
 	cron          *cron.Cron
 	retryFunc     RetryFunc
 	retryInterval time.Duration
+	schedule      string // Cron expression; overrides retryInterval when set
 	logger        *logger.Logger
 	entryID       cron.EntryID
+
+	mu        sync.Mutex
+	busy      bool // An operation is in progress
+	lastRunAt time.Time
+	lastError string
 }
 
 // NewRetryWorker creates a cron-scheduled worker with validation and defaults
@@ -43,19 +54,42 @@ func NewRetryWorker(cfg *config.WorkerConfig, name string, retryFunc RetryFunc,
 	}, nil
 }
 
+// NewScheduledWorker creates a worker that runs at the times of a standard
+// five-field cron expression, e.g. "0 3 * * *" for every night at 03:00
+func NewScheduledWorker(schedule string, name string, retryFunc RetryFunc, logger *logger.Logger) (*RetryWorker, error) {
+	if _, err := cron.ParseStandard(schedule); err != nil {
+		return nil, fmt.Errorf("invalid schedule '%s': %v", schedule, err)
+	}
+
+	return &RetryWorker{
+		name:      name,
+		cron:      cron.New(),
+		retryFunc: retryFunc,
+		schedule:  schedule,
+		logger:    logger.WithComponent("retry-worker"),
+	}, nil
+}
+
 // Start schedules and begins the retry worker
 func (w *RetryWorker) Start() error {
-	intervalStr := w.durationToCronExpression(w.retryInterval)
-	w.logger.Info(fmt.Sprintf("Starting retry worker: %s (every %v)", w.name, w.retryInterval))
+	if w.IsRunning() {
+		return fmt.Errorf("worker %s is already running", w.name)
+	}
 
-	entryID, err := w.cron.AddFunc(intervalStr, func() {
-		w.logger.Debug("Executing retry operation for worker: " + w.name)
+	intervalStr := w.schedule
+	if intervalStr == "" {
+		intervalStr = w.durationToCronExpression(w.retryInterval)
+		w.logger.Info(fmt.Sprintf("Starting retry worker: %s (every %v)", w.name, w.retryInterval))
+	} else {
+		w.logger.Info(fmt.Sprintf("Starting retry worker: %s (schedule %s)", w.name, w.schedule))
+	}
 
-		if err := w.retryFunc(); err != nil {
-			w.logger.Error("Retry operation failed for worker " + w.name + ": " + err.Error())
-		} else {
-			w.logger.Info("Retry operation completed successfully for worker: " + w.name)
+	entryID, err := w.cron.AddFunc(intervalStr, func() {
+		if !w.acquire() {
+			w.logger.Warn("Skipping scheduled run of busy worker: " + w.name)
+			return
 		}
+		w.run()
 	})
 
 	if err != nil {
@@ -78,6 +112,7 @@ func (w *RetryWorker) Stop() error {
 	// Remove the scheduled entry
 	if w.entryID > 0 {
 		w.cron.Remove(w.entryID)
+		w.entryID = 0
 	}
 
 	ctx := w.cron.Stop()
@@ -93,6 +128,72 @@ func (w *RetryWorker) IsRunning() bool {
 	return len(w.cron.Entries()) > 0
 }
 
+// Name returns the name the worker was created with
+func (w *RetryWorker) Name() string {
+	return w.name
+}
+
+// Schedule returns the cron expression the worker runs on
+func (w *RetryWorker) Schedule() string {
+	if w.schedule != "" {
+		return w.schedule
+	}
+	return w.durationToCronExpression(w.retryInterval)
+}
+
+// Trigger runs the operation once in the background, outside the schedule and
+// whether or not the worker is running. It fails with ErrBusy while the
+// operation is in progress.
+func (w *RetryWorker) Trigger() error {
+	if !w.acquire() {
+		return ErrBusy
+	}
+
+	w.logger.Info("Triggered run of worker: " + w.name)
+	go w.run()
+	return nil
+}
+
+// LastRun returns when the operation last finished, zero if it never ran, and
+// its error message if it failed
+func (w *RetryWorker) LastRun() (time.Time, string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastRunAt, w.lastError
+}
+
+// acquire marks the operation in progress, reporting false if it already was
+func (w *RetryWorker) acquire() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.busy {
+		return false
+	}
+	w.busy = true
+	return true
+}
+
+// run executes the operation acquired by acquire and records the outcome
+func (w *RetryWorker) run() {
+	w.logger.Debug("Executing retry operation for worker: " + w.name)
+
+	err := w.retryFunc()
+	if err != nil {
+		w.logger.Error("Retry operation failed for worker " + w.name + ": " + err.Error())
+	} else {
+		w.logger.Info("Retry operation completed successfully for worker: " + w.name)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.busy = false
+	w.lastRunAt = time.Now()
+	w.lastError = ""
+	if err != nil {
+		w.lastError = err.Error()
+	}
+}
+
 // durationToCronExpression converts duration to cron format with fallback
 func (w *RetryWorker) durationToCronExpression(duration time.Duration) string {
 	minutes := int(duration.Minutes())
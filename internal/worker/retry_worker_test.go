@@ -1,6 +1,7 @@
 package worker
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -155,3 +156,59 @@ func TestRetryFunc_Type(t *testing.T) {
 	err := fn()
 	assert.NoError(t, err)
 }
+
+func TestNewScheduledWorker(t *testing.T) {
+	mockFunc := func() error { return nil }
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	_, err = NewScheduledWorker("every night", "test-worker", mockFunc, log)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid schedule")
+
+	worker, err := NewScheduledWorker("0 3 * * *", "test-worker", mockFunc, log)
+	require.NoError(t, err)
+	assert.Equal(t, "0 3 * * *", worker.schedule)
+
+	require.NoError(t, worker.Start())
+	assert.True(t, worker.IsRunning())
+	require.NoError(t, worker.Stop())
+	assert.False(t, worker.IsRunning())
+}
+
+func TestRetryWorker_Trigger(t *testing.T) {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	calls := make(chan struct{}, 2)
+	worker, err := NewScheduledWorker("0 3 * * *", "test-worker", func() error {
+		calls <- struct{}{}
+		<-release
+		return errors.New("nothing to do")
+	}, log)
+	require.NoError(t, err)
+	assert.Equal(t, "test-worker", worker.Name())
+	assert.Equal(t, "0 3 * * *", worker.Schedule())
+
+	// Triggering works while the schedule is paused
+	require.NoError(t, worker.Trigger())
+	<-calls
+	assert.ErrorIs(t, worker.Trigger(), ErrBusy, "runs do not overlap")
+
+	close(release)
+	require.Eventually(t, func() bool {
+		lastRunAt, _ := worker.LastRun()
+		return !lastRunAt.IsZero()
+	}, time.Second, 10*time.Millisecond)
+	_, lastError := worker.LastRun()
+	assert.Equal(t, "nothing to do", lastError)
+
+	// Resuming a paused worker works, starting a running one does not
+	require.NoError(t, worker.Start())
+	assert.Error(t, worker.Start())
+	require.NoError(t, worker.Stop())
+	require.NoError(t, worker.Start())
+	assert.True(t, worker.IsRunning())
+	require.NoError(t, worker.Stop())
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"gorm.io/gorm"
+)
+
+// cancelKey stores the function releasing a statement's deadline
+const cancelKey = "database:cancel_statement"
+
+// StatementTimeouts bound how long a single statement may run; 0 disables a limit
+type StatementTimeouts struct {
+	Default time.Duration // Every statement, including background work
+	HTTP    time.Duration // Statements bound to a request context marked with WithStatementTimeout
+}
+
+// ParseStatementTimeouts reads the timeouts with their defaults
+func ParseStatementTimeouts(cfg *config.DatabaseConfig) (StatementTimeouts, error) {
+	timeouts := StatementTimeouts{Default: 30 * time.Second, HTTP: 5 * time.Second}
+
+	if cfg.StatementTimeout != "" {
+		timeout, err := time.ParseDuration(cfg.StatementTimeout)
+		if err != nil || timeout < 0 {
+			return timeouts, fmt.Errorf("invalid statement timeout '%s': must be a non-negative duration", cfg.StatementTimeout)
+		}
+		timeouts.Default = timeout
+	}
+
+	if cfg.HTTPStatementTimeout != "" {
+		timeout, err := time.ParseDuration(cfg.HTTPStatementTimeout)
+		if err != nil || timeout < 0 {
+			return timeouts, fmt.Errorf("invalid HTTP statement timeout '%s': must be a non-negative duration", cfg.HTTPStatementTimeout)
+		}
+		timeouts.HTTP = timeout
+	}
+
+	return timeouts, nil
+}
+
+type statementTimeoutKey struct{}
+
+// WithStatementTimeout marks ctx so statements run with it get at most timeout
+// each, in place of the default. Request handlers use it for a stricter limit.
+func WithStatementTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, statementTimeoutKey{}, timeout)
+}
+
+// RegisterStatementTimeouts gives every statement a deadline, so a runaway
+// query is cancelled and its connection returned to the pool. Statements get
+// the timeout marked on their context, or fallback; an earlier deadline of the
+// context still applies.
+//
+// Results of Row and Rows are read after the statement callback returns, so
+// their deadline cannot be released when the statement completes. They only
+// get a marked timeout, which ends with the request, and streaming reads such
+// as exports are otherwise left unbounded.
+func RegisterStatementTimeouts(db *gorm.DB, fallback time.Duration) error {
+	limit := func(tx *gorm.DB, timeout time.Duration) {
+		if timeout <= 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(tx.Statement.Context, timeout)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(cancelKey, cancel)
+	}
+
+	start := func(tx *gorm.DB) {
+		timeout, ok := tx.Statement.Context.Value(statementTimeoutKey{}).(time.Duration)
+		if !ok {
+			timeout = fallback
+		}
+		limit(tx, timeout)
+	}
+	startRow := func(tx *gorm.DB) {
+		if timeout, ok := tx.Statement.Context.Value(statementTimeoutKey{}).(time.Duration); ok {
+			limit(tx, timeout) // Released with the request context
+		}
+	}
+	finish := func(tx *gorm.DB) {
+		if cancel, ok := tx.InstanceGet(cancelKey); ok {
+			cancel.(context.CancelFunc)()
+		}
+	}
+
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("timeout:create", start); err != nil {
+		return err
+	}
+	if err := callbacks.Create().After("gorm:create").Register("timeout:create_done", finish); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("timeout:query", start); err != nil {
+		return err
+	}
+	if err := callbacks.Query().After("gorm:query").Register("timeout:query_done", finish); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("timeout:update", start); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("timeout:update_done", finish); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("timeout:delete", start); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("gorm:delete").Register("timeout:delete_done", finish); err != nil {
+		return err
+	}
+	if err := callbacks.Raw().Before("gorm:raw").Register("timeout:raw", start); err != nil {
+		return err
+	}
+	if err := callbacks.Raw().After("gorm:raw").Register("timeout:raw_done", finish); err != nil {
+		return err
+	}
+	return callbacks.Row().Before("gorm:row").Register("timeout:row", startRow)
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type record struct {
+	ID int
+}
+
+// statementContexts records the context each statement ran with
+type statementContexts struct {
+	query context.Context
+	row   context.Context
+}
+
+func newTimeoutDB(t *testing.T, fallback time.Duration) (*gorm.DB, *statementContexts) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	require.NoError(t, RegisterStatementTimeouts(db, fallback))
+
+	seen := &statementContexts{}
+	require.NoError(t, db.Callback().Query().Before("gorm:query").After("timeout:query").Register("test:query", func(tx *gorm.DB) {
+		seen.query = tx.Statement.Context
+	}))
+	require.NoError(t, db.Callback().Row().Before("gorm:row").After("timeout:row").Register("test:row", func(tx *gorm.DB) {
+		seen.row = tx.Statement.Context
+	}))
+	return db, seen
+}
+
+func TestStatementTimeouts(t *testing.T) {
+	t.Run("Statements get the default timeout", func(t *testing.T) {
+		db, seen := newTimeoutDB(t, 30*time.Second)
+
+		var records []record
+		db.Find(&records)
+
+		require.NotNil(t, seen.query)
+		deadline, ok := seen.query.Deadline()
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(30*time.Second), deadline, time.Second)
+		assert.Error(t, seen.query.Err(), "released once the statement completes")
+	})
+
+	t.Run("Marked contexts get their own timeout", func(t *testing.T) {
+		db, seen := newTimeoutDB(t, 30*time.Second)
+		ctx := WithStatementTimeout(context.Background(), 5*time.Second)
+
+		var records []record
+		db.WithContext(ctx).Find(&records)
+
+		deadline, ok := seen.query.Deadline()
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(5*time.Second), deadline, time.Second)
+	})
+
+	t.Run("An earlier request deadline still applies", func(t *testing.T) {
+		db, seen := newTimeoutDB(t, 30*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		var records []record
+		db.WithContext(ctx).Find(&records)
+
+		deadline, ok := seen.query.Deadline()
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Second), deadline, time.Second)
+	})
+
+	t.Run("Zero disables the limit", func(t *testing.T) {
+		db, seen := newTimeoutDB(t, 0)
+
+		var records []record
+		db.Find(&records)
+
+		_, ok := seen.query.Deadline()
+		assert.False(t, ok)
+	})
+
+	t.Run("Streamed rows are only limited on marked contexts", func(t *testing.T) {
+		db, seen := newTimeoutDB(t, 30*time.Second)
+
+		_, _ = db.Model(&record{}).Rows()
+		_, ok := seen.row.Deadline()
+		assert.False(t, ok)
+
+		ctx, cancel := context.WithCancel(WithStatementTimeout(context.Background(), 5*time.Second))
+		defer cancel()
+		_, _ = db.WithContext(ctx).Model(&record{}).Rows()
+		_, ok = seen.row.Deadline()
+		assert.True(t, ok)
+		assert.NoError(t, seen.row.Err(), "kept while the rows are read")
+	})
+}
+
+func TestParseStatementTimeouts(t *testing.T) {
+	timeouts, err := ParseStatementTimeouts(&config.DatabaseConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, StatementTimeouts{Default: 30 * time.Second, HTTP: 5 * time.Second}, timeouts)
+
+	timeouts, err = ParseStatementTimeouts(&config.DatabaseConfig{StatementTimeout: "0", HTTPStatementTimeout: "2s"})
+	require.NoError(t, err)
+	assert.Equal(t, StatementTimeouts{HTTP: 2 * time.Second}, timeouts)
+
+	for _, invalid := range []*config.DatabaseConfig{{StatementTimeout: "long"}, {HTTPStatementTimeout: "-1s"}} {
+		_, err := ParseStatementTimeouts(invalid)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,97 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// Event is something that happened in one domain that others may react to
+type Event interface {
+	Name() string
+}
+
+// Handler handles one published event
+type Handler func(event Event)
+
+// Publisher publishes events; domains depend on this rather than on the Bus
+type Publisher interface {
+	Publish(event Event)
+}
+
+// Bus delivers events to the handlers subscribed to their name. Delivery is
+// synchronous and in subscription order, so handlers doing slow work should
+// hand it off. A panicking handler is logged and does not stop the others.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	logger   *logger.Logger
+}
+
+// NewBus creates an event bus without subscribers
+func NewBus(log *logger.Logger) *Bus {
+	return &Bus{
+		handlers: make(map[string][]Handler),
+		logger:   log.WithComponent("events"),
+	}
+}
+
+// Subscribe registers handler for events with any of the given names
+func (b *Bus) Subscribe(handler Handler, names ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, name := range names {
+		b.handlers[name] = append(b.handlers[name], handler)
+	}
+}
+
+// Publish delivers the event to its subscribers
+func (b *Bus) Publish(event Event) {
+	b.mu.RLock()
+	handlers := b.handlers[event.Name()]
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		b.deliver(handler, event)
+	}
+}
+
+func (b *Bus) deliver(handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Error(fmt.Sprintf("Handler of event %s panicked: %v", event.Name(), r))
+		}
+	}()
+	handler(event)
+}
+
+// Names of rating events
+const (
+	RatingCreated   = "rating.created"
+	RatingUpdated   = "rating.updated"
+	RatingDeleted   = "rating.deleted"
+	ReactionAdded   = "reaction.added"
+	ReactionRemoved = "reaction.removed"
+)
+
+// RatingEvents lists the names of all rating events
+var RatingEvents = []string{RatingCreated, RatingUpdated, RatingDeleted, ReactionAdded, ReactionRemoved}
+
+// RatingChanged is published when a user's rating of, or reaction to, an
+// article changes
+type RatingChanged struct {
+	Type      string // One of RatingEvents
+	UserID    uuid.UUID
+	ArticleID uuid.UUID
+	Score     int    // The new score, 0 for deletions and reactions
+	Reaction  string // The rating reaction, or the reaction kind added or removed
+	Liked     bool   // Whether the change counts as the user liking the article
+}
+
+// Name returns the event's type
+func (e *RatingChanged) Name() string {
+	return e.Type
+}
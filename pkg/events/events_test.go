@@ -0,0 +1,49 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBus(t *testing.T) *Bus {
+	log, err := logger.NewLogger(&config.LoggingConfig{Level: "error", Format: "console"})
+	require.NoError(t, err)
+	return NewBus(log)
+}
+
+func TestBus(t *testing.T) {
+	t.Run("Delivers events to their subscribers in order", func(t *testing.T) {
+		bus := newTestBus(t)
+
+		var delivered []string
+		bus.Subscribe(func(event Event) { delivered = append(delivered, "first "+event.Name()) }, RatingEvents...)
+		bus.Subscribe(func(event Event) { delivered = append(delivered, "second "+event.Name()) }, RatingDeleted)
+
+		bus.Publish(&RatingChanged{Type: RatingCreated, UserID: uuid.New()})
+		bus.Publish(&RatingChanged{Type: RatingDeleted, UserID: uuid.New()})
+
+		assert.Equal(t, []string{"first rating.created", "first rating.deleted", "second rating.deleted"}, delivered)
+	})
+
+	t.Run("Events without subscribers are dropped", func(t *testing.T) {
+		bus := newTestBus(t)
+
+		assert.NotPanics(t, func() { bus.Publish(&RatingChanged{Type: ReactionAdded}) })
+	})
+
+	t.Run("A panicking handler does not stop the others", func(t *testing.T) {
+		bus := newTestBus(t)
+
+		delivered := 0
+		bus.Subscribe(func(Event) { panic("boom") }, RatingUpdated)
+		bus.Subscribe(func(Event) { delivered++ }, RatingUpdated)
+
+		assert.NotPanics(t, func() { bus.Publish(&RatingChanged{Type: RatingUpdated}) })
+		assert.Equal(t, 1, delivered)
+	})
+}
@@ -100,3 +100,10 @@ func (l *Logger) WithComponent(component string) *Logger {
 		logger: l.logger.With().Str("component", component).Logger(),
 	}
 }
+
+// WithField returns a logger instance adding the field to every entry
+func (l *Logger) WithField(key, value string) *Logger {
+	return &Logger{
+		logger: l.logger.With().Str(key, value).Logger(),
+	}
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"bytes"
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -206,6 +207,17 @@ func TestLogger_WithComponent(t *testing.T) {
 	assert.Contains(t, output, `"component":"test-component"`)
 }
 
+func TestLogger_WithField(t *testing.T) {
+	var buf bytes.Buffer
+	logger := &Logger{logger: zerolog.New(&buf).Level(zerolog.InfoLevel)}
+
+	logger.WithComponent("audit").WithField("ip", "192.0.2.1").Info("field message")
+
+	output := buf.String()
+	assert.Contains(t, output, `"component":"audit"`)
+	assert.Contains(t, output, `"ip":"192.0.2.1"`)
+}
+
 func TestLogger_AllLogLevels(t *testing.T) {
 	var buf bytes.Buffer
 	testLogger := zerolog.New(&buf).Level(zerolog.DebugLevel)
@@ -266,3 +278,18 @@ func TestLogger_TimestampAndService(t *testing.T) {
 	// Check for timestamp field (will be in RFC3339 format)
 	assert.Contains(t, output, `"time":"`)
 }
+
+func TestLogger_WithContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := &Logger{logger: zerolog.New(&buf)}
+
+	ctx := ContextWithRequestID(context.Background(), "req-123")
+	assert.Equal(t, "req-123", RequestIDFromContext(ctx))
+	logger.WithContext(ctx).Info("traced message")
+	assert.Contains(t, buf.String(), `"request_id":"req-123"`)
+
+	buf.Reset()
+	assert.Same(t, logger, logger.WithContext(context.Background()))
+	logger.WithContext(context.Background()).Info("untraced message")
+	assert.NotContains(t, buf.String(), "request_id")
+}
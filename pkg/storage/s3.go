@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+)
+
+// maxErrorBodySize bounds how much of an S3 error response is included in errors
+const maxErrorBodySize = 512
+
+// s3Storage talks to S3 or an S3-compatible service (MinIO, R2, ...) over its
+// REST API, signing requests with AWS Signature Version 4
+type s3Storage struct {
+	baseURL         string // Endpoint including the bucket, without trailing slash
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	client          *http.Client
+	logger          *logger.Logger
+	now             func() time.Time
+}
+
+func newS3Storage(cfg *config.StorageConfig, log *logger.Logger) (*s3Storage, error) {
+	if cfg.S3Bucket == "" {
+		return nil, fmt.Errorf("invalid S3 storage config: bucket is required")
+	}
+	if cfg.S3AccessKeyID == "" || cfg.S3SecretAccessKey == "" {
+		return nil, fmt.Errorf("invalid S3 storage config: access key ID and secret access key are required")
+	}
+
+	region := cfg.S3Region
+	if region == "" {
+		region = "us-east-1" // default
+	}
+
+	// Custom endpoints (MinIO, R2, ...) are addressed path-style; AWS itself
+	// uses virtual-hosted buckets
+	var baseURL string
+	if cfg.S3Endpoint != "" {
+		endpoint, err := url.Parse(cfg.S3Endpoint)
+		if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid S3 endpoint '%s': must be an absolute URL", cfg.S3Endpoint)
+		}
+		baseURL = strings.TrimSuffix(cfg.S3Endpoint, "/") + "/" + cfg.S3Bucket
+	} else {
+		baseURL = "https://" + cfg.S3Bucket + ".s3." + region + ".amazonaws.com"
+	}
+
+	return &s3Storage{
+		baseURL:         baseURL,
+		region:          region,
+		accessKeyID:     cfg.S3AccessKeyID,
+		secretAccessKey: cfg.S3SecretAccessKey,
+		client:          &http.Client{Timeout: 30 * time.Second},
+		logger:          log.WithComponent("s3-storage"),
+		now:             time.Now,
+	}, nil
+}
+
+func (s *s3Storage) Put(key string, data []byte, contentType string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	resp, err := s.do(http.MethodPut, key, data, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s.responseError(http.MethodPut, key, resp)
+	}
+	return nil
+}
+
+func (s *s3Storage) Get(key string) (*Object, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(http.MethodGet, key, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, s.responseError(http.MethodGet, key, resp)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return &Object{Data: data, ContentType: contentType}, nil
+}
+
+func (s *s3Storage) Delete(key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	resp, err := s.do(http.MethodDelete, key, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// S3 answers 204 whether or not the object existed
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return s.responseError(http.MethodDelete, key, resp)
+	}
+	return nil
+}
+
+func (s *s3Storage) do(method, key string, body []byte, contentType string) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.baseURL+"/"+encodeS3Path(key), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build S3 request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	signS3Request(req, body, s.accessKeyID, s.secretAccessKey, s.region, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		s.logger.Error("S3 " + method + " " + key + " failed: " + err.Error())
+		return nil, fmt.Errorf("S3 %s %s failed: %w", method, key, err)
+	}
+	return resp, nil
+}
+
+func (s *s3Storage) responseError(method, key string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	s.logger.Error("S3 " + method + " " + key + " returned " + resp.Status + ": " + string(body))
+	return fmt.Errorf("S3 %s %s returned %s", method, key, resp.Status)
+}
+
+// signS3Request adds AWS Signature Version 4 headers to req. Every header
+// already set on the request is signed along with host and the x-amz headers.
+func signS3Request(req *http.Request, body []byte, accessKeyID, secretAccessKey, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// Canonical headers are lowercase, sorted and include the host
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// encodeS3Path percent-encodes a key the way SigV4 expects, keeping slashes
+func encodeS3Path(key string) string {
+	var b strings.Builder
+	for _, c := range []byte(key) {
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
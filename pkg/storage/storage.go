@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dustin/articles-backend/config"
+	"github.com/dustin/articles-backend/pkg/logger"
+)
+
+// Supported storage backends
+const (
+	BackendNone       = "none"
+	BackendFilesystem = "filesystem"
+	BackendS3         = "s3"
+)
+
+// ErrNotFound is returned when no object exists under a key
+var ErrNotFound = errors.New("object not found")
+
+// Object is a stored blob and its media type
+type Object struct {
+	Data        []byte
+	ContentType string
+}
+
+// Storage stores blobs under slash-separated keys such as "articles/<id>.html"
+type Storage interface {
+	Put(key string, data []byte, contentType string) error
+	Get(key string) (*Object, error)
+	Delete(key string) error
+}
+
+// NewStorage creates the configured backend with validation and defaults.
+// It returns a nil Storage when object storage is disabled.
+func NewStorage(cfg *config.StorageConfig, log *logger.Logger) (Storage, error) {
+	backend := BackendNone
+	if cfg != nil && cfg.Backend != "" {
+		backend = strings.ToLower(cfg.Backend)
+	}
+
+	switch backend {
+	case BackendNone:
+		return nil, nil
+	case BackendFilesystem:
+		store, err := newFilesystemStorage(cfg, log)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case BackendS3:
+		store, err := newS3Storage(cfg, log)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("invalid storage backend '%s': must be one of none, filesystem, s3", cfg.Backend)
+	}
+}
+
+// validateKey rejects keys that could escape the storage root
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid storage key '%s'", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid storage key '%s'", key)
+		}
+	}
+	return nil
+}